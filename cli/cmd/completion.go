@@ -2,8 +2,10 @@ package cmd
 
 import (
 	"os"
+	"strings"
 
 	"github.com/spf13/cobra"
+	"github.com/wordsail/cli/internal/config"
 )
 
 // completionCmd represents the completion command
@@ -63,6 +65,43 @@ PowerShell:
 	},
 }
 
+// completeServerNames returns a ValidArgsFunction that completes server names
+// from the config for the first maxArgs positional arguments (0 for no limit).
+// Servers already given are not offered again. A missing or unreadable config
+// yields no completions.
+func completeServerNames(maxArgs int) func(*cobra.Command, []string, string) ([]string, cobra.ShellCompDirective) {
+	return func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		if maxArgs > 0 && len(args) >= maxArgs {
+			return nil, cobra.ShellCompDirectiveNoFileComp
+		}
+
+		// Completion does not run the root pre-run, so apply --config and
+		// --profile here
+		config.SetPathOverride(ConfigFile)
+		config.SetProfileOverride(Profile)
+		mgr, err := config.NewManager()
+		if err != nil || !mgr.ConfigExists() {
+			return nil, cobra.ShellCompDirectiveNoFileComp
+		}
+		cfg, err := mgr.Load()
+		if err != nil {
+			return nil, cobra.ShellCompDirectiveNoFileComp
+		}
+
+		given := make(map[string]bool, len(args))
+		for _, arg := range args {
+			given[arg] = true
+		}
+		var names []string
+		for _, server := range cfg.Servers {
+			if !given[server.Name] && strings.HasPrefix(server.Name, toComplete) {
+				names = append(names, server.Name)
+			}
+		}
+		return names, cobra.ShellCompDirectiveNoFileComp
+	}
+}
+
 func init() {
 	rootCmd.AddCommand(completionCmd)
 }
@@ -1,14 +1,22 @@
 package cmd
 
 import (
+	"encoding/json"
 	"fmt"
 	"os"
 	"os/exec"
+	"sort"
+	"strings"
 
+	"github.com/AlecAivazis/survey/v2"
 	"github.com/fatih/color"
 	"github.com/spf13/cobra"
 	"github.com/wordsail/cli/internal/config"
+	"github.com/wordsail/cli/internal/exit"
+	"github.com/wordsail/cli/internal/installer"
 	"github.com/wordsail/cli/internal/prompt"
+	"github.com/wordsail/cli/internal/state"
+	"github.com/wordsail/cli/internal/utils"
 	"gopkg.in/yaml.v3"
 )
 
@@ -34,13 +42,13 @@ var configShowCmd = &cobra.Command{
 		if !mgr.ConfigExists() {
 			color.Red("Configuration file not found at: %s", mgr.GetConfigPath())
 			fmt.Println("Run 'wordsail init' to create it.")
-			os.Exit(1)
+			os.Exit(exit.ConfigNotFound)
 		}
 
 		cfg, err := mgr.Load()
 		if err != nil {
 			color.Red("Error: Failed to load configuration: %v", err)
-			os.Exit(1)
+			os.Exit(exit.Validation)
 		}
 
 		// Marshal to YAML for pretty display
@@ -59,7 +67,17 @@ var configShowCmd = &cobra.Command{
 var configValidateCmd = &cobra.Command{
 	Use:   "validate",
 	Short: "Validate configuration file",
-	Long:  `Validate the wordsail configuration file for correctness and consistency.`,
+	Long: `Validate the wordsail configuration file for correctness and consistency.
+
+Also reports drift left behind by failed operations. With --fix, drift that
+can be repaired in the config is fixed after confirmation:
+  - a site's primary domain missing from its domain list is added back
+  - certificate dates on domains without SSL are cleared
+
+Examples:
+  wordsail config validate
+  wordsail config validate --fix
+  wordsail config validate --fix --force`,
 	Run: func(cmd *cobra.Command, args []string) {
 		mgr, err := config.NewManager()
 		if err != nil {
@@ -70,13 +88,13 @@ var configValidateCmd = &cobra.Command{
 		if !mgr.ConfigExists() {
 			color.Red("Configuration file not found at: %s", mgr.GetConfigPath())
 			fmt.Println("Run 'wordsail init' to create it.")
-			os.Exit(1)
+			os.Exit(exit.ConfigNotFound)
 		}
 
 		cfg, err := mgr.Load()
 		if err != nil {
 			color.Red("Error: Failed to load configuration: %v", err)
-			os.Exit(1)
+			os.Exit(exit.Validation)
 		}
 
 		validator := config.NewValidator()
@@ -85,7 +103,7 @@ var configValidateCmd = &cobra.Command{
 		fmt.Println("Validating configuration structure...")
 		if err := validator.ValidateStruct(cfg); err != nil {
 			color.Red("✗ Structure validation failed: %v", err)
-			os.Exit(1)
+			os.Exit(exit.Validation)
 		}
 		color.Green("✓ Structure validation passed")
 
@@ -93,10 +111,65 @@ var configValidateCmd = &cobra.Command{
 		fmt.Println("Validating business rules...")
 		if err := validator.ValidateBusinessRules(cfg); err != nil {
 			color.Red("✗ Business rules validation failed: %v", err)
-			os.Exit(1)
+			os.Exit(exit.Validation)
 		}
 		color.Green("✓ Business rules validation passed")
 
+		// Validate the global vars provisioning depends on
+		fmt.Println("Validating global vars...")
+		if err := validator.ValidateGlobalVars(cfg); err != nil {
+			color.Red("✗ Global vars validation failed: %v", err)
+			fmt.Println("Set it with 'wordsail config set global_vars.<name> <value>' or run 'wordsail init --force'.")
+			os.Exit(exit.Validation)
+		}
+		color.Green("✓ Global vars validation passed")
+
+		// Check for drift left behind by failed operations
+		fmt.Println("Checking for drift...")
+		fix, _ := cmd.Flags().GetBool("fix")
+		drift := config.FixDrift(cfg)
+		fixable := 0
+		for _, d := range drift {
+			switch {
+			case d.Fixed() && fix:
+				fixable++
+				color.Yellow("⚠ %s → %s", d, d.Fix)
+			case d.Fixed():
+				fixable++
+				color.Yellow("⚠ %s (fixable with --fix)", d)
+			default:
+				color.Yellow("⚠ %s; %s", d, d.Hint)
+			}
+		}
+		if len(drift) == 0 {
+			color.Green("✓ No drift found")
+		}
+		if fix && fixable > 0 {
+			confirm, err := prompt.Confirm(fmt.Sprintf("Write %d fix(es) to the configuration?", fixable), false, assumeYes(cmd))
+			if err != nil {
+				os.Exit(1)
+			}
+			if confirm {
+				if err := mgr.Save(cfg); err != nil {
+					color.Red("Error: Failed to save configuration: %v", err)
+					os.Exit(1)
+				}
+				color.Green("✓ Applied %d fix(es)", fixable)
+			} else {
+				fmt.Println("Fixes not written")
+			}
+		}
+
+		shared := config.SharedSiteIDs(cfg)
+		siteIDs := make([]string, 0, len(shared))
+		for siteID := range shared {
+			siteIDs = append(siteIDs, siteID)
+		}
+		sort.Strings(siteIDs)
+		for _, siteID := range siteIDs {
+			color.Yellow("⚠ Site ID '%s' is used on several servers (%s); pass --server to address it", siteID, strings.Join(shared[siteID], ", "))
+		}
+
 		// Validate Ansible environment
 		fmt.Println("Validating Ansible environment...")
 		if err := validator.ValidateAnsibleEnvironment(cfg); err != nil {
@@ -104,6 +177,9 @@ var configValidateCmd = &cobra.Command{
 			os.Exit(1)
 		}
 		color.Green("✓ Ansible environment validation passed")
+		if installer.IsInitialized() {
+			warnOutdatedPlaybooks()
+		}
 
 		fmt.Println()
 		color.Green("✓ Configuration is valid")
@@ -114,6 +190,11 @@ var configValidateCmd = &cobra.Command{
 			totalSites += len(server.Sites)
 		}
 		fmt.Printf("  Sites: %d\n", totalSites)
+		if version, err := config.AnsibleVersion(); err == nil {
+			fmt.Printf("  Ansible: ansible-core %s (minimum %s)\n", version, config.MinAnsibleVersion)
+		} else {
+			fmt.Println("  Ansible: version could not be detected")
+		}
 	},
 }
 
@@ -132,13 +213,13 @@ var configEditCmd = &cobra.Command{
 		if !mgr.ConfigExists() {
 			color.Red("Configuration file not found at: %s", mgr.GetConfigPath())
 			fmt.Println("Run 'wordsail init' to create it.")
-			os.Exit(1)
+			os.Exit(exit.ConfigNotFound)
 		}
 
 		cfg, err := mgr.Load()
 		if err != nil {
 			color.Red("Error: Failed to load configuration: %v", err)
-			os.Exit(1)
+			os.Exit(exit.Validation)
 		}
 
 		// If no preferred editor is set, prompt for one
@@ -170,9 +251,770 @@ var configEditCmd = &cobra.Command{
 	},
 }
 
+// configGetCmd represents the config get command
+var configGetCmd = &cobra.Command{
+	Use:   "get <path>",
+	Short: "Print a configuration value",
+	Long: `Print the value at a dotted path in the configuration, such as
+global_vars.certbot_email or ansible.path. Sections are printed as YAML.
+
+Examples:
+  wordsail config get global_vars.certbot_email
+  wordsail config get global_vars`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		mgr, err := config.NewManager()
+		if err != nil {
+			fail(cmd, "Failed to create config manager", err)
+		}
+
+		if !mgr.ConfigExists() {
+			fail(cmd, "Configuration file not found", exit.Errorf(exit.ConfigNotFound, "run 'wordsail init' first"))
+		}
+
+		cfg, err := mgr.Load()
+		if err != nil {
+			fail(cmd, "Failed to load configuration", exit.New(exit.Validation, err))
+		}
+
+		value, err := config.GetPath(cfg, args[0])
+		if err != nil {
+			fail(cmd, "Failed to read configuration value", err)
+		}
+
+		if isJSONOutput(cmd) {
+			outputSuccess(cmd, "config_get", map[string]interface{}{
+				"path":  args[0],
+				"value": value,
+			})
+			return
+		}
+
+		switch value.(type) {
+		case map[string]interface{}, []interface{}:
+			data, err := yaml.Marshal(value)
+			if err != nil {
+				fail(cmd, "Failed to marshal value", err)
+			}
+			fmt.Print(string(data))
+		default:
+			fmt.Println(value)
+		}
+	},
+}
+
+// configSetCmd represents the config set command
+var configSetCmd = &cobra.Command{
+	Use:   "set <path> <value>",
+	Short: "Set a configuration value",
+	Long: `Set the value at a dotted path in the configuration. The value is parsed
+as YAML, so "true" is a boolean and "20" a number; quote it to force a
+string. New global vars are created as needed.
+
+The configuration is validated before it is saved; values that would make
+it invalid are rejected. Servers and sites are managed with their own
+commands.
+
+Examples:
+  wordsail config set global_vars.certbot_email ops@example.com
+  wordsail config set global_vars.wordsail_ssh_key ~/.ssh/wordsail_ed25519.pub
+  wordsail config set global_vars.php_memory_limit 512M`,
+	Args: cobra.ExactArgs(2),
+	Run: func(cmd *cobra.Command, args []string) {
+		mgr, err := config.NewManager()
+		if err != nil {
+			fail(cmd, "Failed to create config manager", err)
+		}
+
+		if !mgr.ConfigExists() {
+			fail(cmd, "Configuration file not found", exit.Errorf(exit.ConfigNotFound, "run 'wordsail init' first"))
+		}
+
+		cfg, err := mgr.Load()
+		if err != nil {
+			fail(cmd, "Failed to load configuration", exit.New(exit.Validation, err))
+		}
+
+		updated, err := config.SetPath(cfg, args[0], args[1])
+		if err != nil {
+			fail(cmd, "Failed to set configuration value", err)
+		}
+
+		if err := mgr.Save(updated); err != nil {
+			fail(cmd, "Failed to save configuration", err)
+		}
+
+		value, _ := config.GetPath(updated, args[0])
+		outputSuccess(cmd, "config_set", map[string]interface{}{
+			"path":  args[0],
+			"value": value,
+		})
+	},
+}
+
+// configMigrateCmd represents the config migrate command
+var configMigrateCmd = &cobra.Command{
+	Use:   "migrate",
+	Short: "Upgrade the configuration to the current schema version",
+	Long: `Upgrade a configuration file written by an older version of WordSail to
+the current schema version. A copy of the original file is kept next to it.
+
+Other commands migrate the configuration automatically when they load it;
+this command lets you do it explicitly and see what changed.
+
+Examples:
+  wordsail config migrate`,
+	Run: func(cmd *cobra.Command, args []string) {
+		mgr, err := config.NewManager()
+		if err != nil {
+			fail(cmd, "Failed to create config manager", err)
+		}
+
+		if !mgr.ConfigExists() {
+			fail(cmd, "Configuration file not found", exit.Errorf(exit.ConfigNotFound, "run 'wordsail init' first"))
+		}
+
+		from, migrated, err := mgr.Migrate()
+		if err != nil {
+			fail(cmd, "Failed to migrate configuration", err)
+		}
+
+		if !migrated {
+			outputSuccess(cmd, "config_current", map[string]interface{}{
+				"version": from,
+			})
+			return
+		}
+
+		outputSuccess(cmd, "config_migrated", map[string]interface{}{
+			"from":   from,
+			"to":     config.CurrentVersion,
+			"backup": mgr.MigrationBackupPath(from),
+		})
+	},
+}
+
+// configRestoreCmd represents the config restore command
+var configRestoreCmd = &cobra.Command{
+	Use:   "restore [backup]",
+	Short: "Roll the configuration back to a local backup",
+	Long: `Replace the configuration with one of the local backups taken before each
+save. Backups live in ~/.wordsail/backups; the newest 10 are kept (set
+global_vars.config_backup_keep to change this, or 0 to turn them off).
+
+The current configuration is backed up before it is replaced, so a restore
+can be undone the same way. Without a backup name you are asked to pick one.
+
+Examples:
+  wordsail config restore --list
+  wordsail config restore
+  wordsail config restore wordsail-20261016-093012.123456789.yaml --force`,
+	Args: cobra.MaximumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		mgr, err := config.NewManager()
+		if err != nil {
+			fail(cmd, "Failed to create config manager", err)
+		}
+
+		backups, err := mgr.ListBackups()
+		if err != nil {
+			fail(cmd, "Failed to list backups", err)
+		}
+
+		list, _ := cmd.Flags().GetBool("list")
+		if list {
+			if isJSONOutput(cmd) {
+				if backups == nil {
+					backups = []config.ConfigBackup{}
+				}
+				data, _ := json.MarshalIndent(backups, "", "  ")
+				fmt.Println(string(data))
+				return
+			}
+			if len(backups) == 0 {
+				fmt.Println("No configuration backups found.")
+				return
+			}
+			fmt.Printf("\nConfiguration backups in %s:\n\n", mgr.BackupDir())
+			headers := []string{"BACKUP", "TAKEN"}
+			colWidths := []int{45, 20}
+			rows := make([][]string, 0, len(backups))
+			for _, b := range backups {
+				rows = append(rows, []string{b.Name, b.CreatedAt.Local().Format("2006-01-02 15:04:05")})
+			}
+			utils.PrintTableWithBorders(headers, rows, colWidths)
+			fmt.Println()
+			return
+		}
+
+		var backup string
+		if len(args) == 1 {
+			backup = args[0]
+		} else {
+			if isJSONOutput(cmd) {
+				fail(cmd, "Backup required", exit.Errorf(exit.Validation, "specify the backup to restore in JSON mode (see --list)"))
+			}
+			if len(backups) == 0 {
+				fail(cmd, "Nothing to restore", exit.Errorf(exit.Validation, "no backups found in %s", mgr.BackupDir()))
+			}
+			labels := make([]string, 0, len(backups))
+			for _, b := range backups {
+				labels = append(labels, fmt.Sprintf("%s (%s)", b.Name, b.CreatedAt.Local().Format("2006-01-02 15:04:05")))
+			}
+			var selected int
+			if err := survey.AskOne(&survey.Select{
+				Message: "Select backup to restore:",
+				Options: labels,
+			}, &selected); err != nil {
+				os.Exit(1)
+			}
+			backup = backups[selected].Name
+		}
+
+		if !assumeYes(cmd) {
+			if isJSONOutput(cmd) {
+				fail(cmd, "Confirmation required", exit.Errorf(exit.Validation, "use --force to restore a backup in JSON mode"))
+			}
+			confirm, err := prompt.Confirm(fmt.Sprintf("Replace the current configuration with %s?", backup), false, false)
+			if err != nil {
+				os.Exit(1)
+			}
+			if !confirm {
+				fmt.Println("Restore cancelled")
+				return
+			}
+		}
+
+		if err := mgr.Restore(backup); err != nil {
+			fail(cmd, "Failed to restore configuration", err)
+		}
+
+		outputSuccess(cmd, "config_restored", map[string]interface{}{
+			"backup": backup,
+			"config": mgr.GetConfigPath(),
+		})
+	},
+}
+
+// configDiffCmd represents the config diff command
+var configDiffCmd = &cobra.Command{
+	Use:   "diff [backup|profile]",
+	Short: "Show what changed since a backup, or how a profile differs",
+	Long: `Compare the configuration with a local backup or another profile, setting
+by setting. Servers, sites and domains are matched by name, so reordering
+them is not reported; database passwords are never shown.
+
+Without an argument the newest backup is used, which shows what the last
+save changed. A profile name compares against that profile's config.
+
+Examples:
+  wordsail config diff
+  wordsail config diff wordsail-20261016-093012.123456789.yaml
+  wordsail config diff staging
+  wordsail config diff --json`,
+	Args: cobra.MaximumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		mgr, err := config.NewManager()
+		if err != nil {
+			fail(cmd, "Failed to create config manager", err)
+		}
+
+		if !mgr.ConfigExists() {
+			fail(cmd, "Configuration file not found", exit.Errorf(exit.ConfigNotFound, "run 'wordsail init' first"))
+		}
+
+		cfg, err := mgr.Load()
+		if err != nil {
+			fail(cmd, "Failed to load configuration", exit.New(exit.Validation, err))
+		}
+
+		var path string
+		switch {
+		case len(args) == 0:
+			backups, err := mgr.ListBackups()
+			if err != nil {
+				fail(cmd, "Failed to list backups", err)
+			}
+			if len(backups) == 0 {
+				fail(cmd, "Nothing to compare", exit.Errorf(exit.Validation, "no backups found in %s", mgr.BackupDir()))
+			}
+			path = mgr.BackupPath(backups[0].Name)
+		case config.ProfileExists(args[0]):
+			path, _ = config.ProfilePath(args[0])
+		default:
+			path = mgr.BackupPath(args[0])
+			if _, err := os.Stat(path); err != nil {
+				fail(cmd, "Nothing to compare", exit.Errorf(exit.Validation, "'%s' is neither a profile nor a backup (see 'wordsail config restore --list')", args[0]))
+			}
+		}
+
+		other, err := config.NewManagerWithPath(path).Load()
+		if err != nil {
+			fail(cmd, fmt.Sprintf("Failed to load %s", path), exit.New(exit.Validation, err))
+		}
+
+		changes := config.Diff(other, cfg)
+
+		if isJSONOutput(cmd) {
+			outputSuccess(cmd, "config_diff", map[string]interface{}{
+				"from":    path,
+				"to":      mgr.GetConfigPath(),
+				"changes": changes,
+			})
+			return
+		}
+
+		if len(changes) == 0 {
+			color.Green("✓ No differences from %s", path)
+			return
+		}
+		fmt.Printf("\nChanges from %s to %s:\n\n", path, mgr.GetConfigPath())
+		for _, c := range changes {
+			printConfigChange(c)
+		}
+		fmt.Printf("\n%d change(s)\n", len(changes))
+	},
+}
+
+// printConfigChange prints one line of `config diff`: + for an added
+// object, - for a removed one and ~ for a changed setting
+func printConfigChange(c config.ConfigChange) {
+	var what []string
+	if c.Server != "" {
+		what = append(what, "server "+c.Server)
+	}
+	if c.SiteID != "" {
+		what = append(what, "site "+c.SiteID)
+	}
+	if c.Domain != "" {
+		what = append(what, "domain "+c.Domain)
+	}
+	subject := strings.Join(what, " / ")
+
+	switch c.Kind {
+	case config.ChangeAdded:
+		color.Green("  + %s", subject)
+	case config.ChangeRemoved:
+		color.Red("  - %s", subject)
+	default:
+		field := c.Field
+		if subject != "" {
+			field = subject + ": " + field
+		}
+		color.Yellow("  ~ %s: %s → %s", field, diffValue(c.From), diffValue(c.To))
+	}
+}
+
+// diffValue shows an unset setting as "none"
+func diffValue(v string) string {
+	if v == "" {
+		return "none"
+	}
+	return v
+}
+
+// configReconcileCmd represents the config reconcile command
+var configReconcileCmd = &cobra.Command{
+	Use:   "reconcile",
+	Short: "Apply state changes that failed to save",
+	Long: `Apply changes that were made on a server but could not be written to the
+configuration, such as a site that was created or a certificate that was
+issued while the config file was unwritable. These are kept in
+~/.wordsail/wordsail.pending.yaml until they are applied.
+
+Changes that still fail stay pending.
+
+Examples:
+  wordsail config reconcile
+  wordsail config reconcile --force`,
+	Run: func(cmd *cobra.Command, args []string) {
+		mgr, err := config.NewManager()
+		if err != nil {
+			fail(cmd, "Failed to create config manager", err)
+		}
+
+		if !mgr.ConfigExists() {
+			fail(cmd, "Configuration file not found", exit.Errorf(exit.ConfigNotFound, "run 'wordsail init' first"))
+		}
+
+		pending, err := state.LoadPending(pendingPath(mgr))
+		if err != nil {
+			fail(cmd, "Failed to read pending changes", err)
+		}
+
+		if len(pending.Changes) == 0 {
+			outputSuccess(cmd, "config_reconciled", map[string]interface{}{
+				"applied": 0,
+				"failed":  0,
+			})
+			return
+		}
+
+		if !assumeYes(cmd) {
+			if isJSONOutput(cmd) {
+				fail(cmd, "Confirmation required", exit.Errorf(exit.Validation, "use --force to apply pending changes in JSON mode"))
+			}
+
+			fmt.Printf("\n%d pending change(s):\n", len(pending.Changes))
+			for _, change := range pending.Changes {
+				fmt.Printf("  - %s\n", describePending(change))
+			}
+			fmt.Println()
+
+			confirm, err := prompt.Confirm("Apply these changes to the configuration?", true, false)
+			if err != nil {
+				os.Exit(1)
+			}
+			if !confirm {
+				fmt.Println("Reconcile cancelled")
+				return
+			}
+		}
+
+		stateMgr := state.NewManager(mgr)
+		applied, failed, err := stateMgr.Reconcile(pending)
+		for _, change := range failed {
+			outputWarning(cmd, "Could not apply %s: %s", describePending(change), change.Error)
+		}
+		if err != nil {
+			fail(cmd, "Failed to update pending changes", err)
+		}
+
+		outputSuccess(cmd, "config_reconciled", map[string]interface{}{
+			"applied": len(applied),
+			"failed":  len(failed),
+		})
+		if len(failed) > 0 {
+			os.Exit(1)
+		}
+	},
+}
+
+// describePending formats a pending change for display
+func describePending(change state.PendingChange) string {
+	switch change.Kind {
+	case state.PendingAddSite:
+		return fmt.Sprintf("add site '%s' to server '%s'", change.SiteID, change.Server)
+	case state.PendingDomainSSL:
+		return fmt.Sprintf("record SSL for %s on site '%s' (server '%s')", change.Domain, change.SiteID, change.Server)
+	default:
+		return fmt.Sprintf("%s on server '%s'", change.Kind, change.Server)
+	}
+}
+
+// configProfileCmd represents the config profile command
+var configProfileCmd = &cobra.Command{
+	Use:   "profile",
+	Short: "Manage config profiles",
+	Long: `Keep separate inventories, each with its own servers and global vars, as
+named profiles. The default profile is ~/.wordsail/wordsail.yaml; other
+profiles live in ~/.wordsail/profiles/<name>.yaml.
+
+The active profile is the --profile flag, then $WORDSAIL_PROFILE, then the
+one chosen with 'config profile use'.`,
+}
+
+// configProfileListCmd represents the config profile list command
+var configProfileListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List config profiles",
+	Run: func(cmd *cobra.Command, args []string) {
+		profiles, err := config.ListProfiles()
+		if err != nil {
+			fail(cmd, "Failed to list profiles", err)
+		}
+		active, err := config.ActiveProfile()
+		if err != nil {
+			fail(cmd, "Failed to read active profile", err)
+		}
+
+		if isJSONOutput(cmd) {
+			data, _ := json.MarshalIndent(map[string]interface{}{
+				"active":   active,
+				"profiles": profiles,
+			}, "", "  ")
+			fmt.Println(string(data))
+			return
+		}
+
+		if len(profiles) == 0 {
+			fmt.Println("No profiles found. Run 'wordsail init' to create the default profile.")
+			return
+		}
+		for _, name := range profiles {
+			path, _ := config.ProfilePath(name)
+			if name == active {
+				color.Green("* %s (%s)", name, path)
+			} else {
+				fmt.Printf("  %s (%s)\n", name, path)
+			}
+		}
+	},
+}
+
+// configProfileUseCmd represents the config profile use command
+var configProfileUseCmd = &cobra.Command{
+	Use:   "use <name>",
+	Short: "Make a profile the default",
+	Long: `Make a profile the one used when neither --profile nor $WORDSAIL_PROFILE
+is set.
+
+Examples:
+  wordsail config profile use staging
+  wordsail config profile use default`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		name := args[0]
+		if err := config.ValidateProfileName(name); err != nil {
+			fail(cmd, "Invalid profile", exit.New(exit.Validation, err))
+		}
+		if !config.ProfileExists(name) {
+			fail(cmd, "Profile not found", exit.Errorf(exit.Validation, "profile '%s' does not exist; create it with 'wordsail config profile create %s'", name, name))
+		}
+
+		if err := config.SetStoredProfile(name); err != nil {
+			fail(cmd, "Failed to switch profile", err)
+		}
+
+		if os.Getenv(config.ProfileEnv) != "" {
+			outputWarning(cmd, "$%s is set and takes precedence in this shell", config.ProfileEnv)
+		}
+		outputSuccess(cmd, "profile_selected", map[string]interface{}{
+			"profile": name,
+		})
+	},
+}
+
+// configProfileCreateCmd represents the config profile create command
+var configProfileCreateCmd = &cobra.Command{
+	Use:   "create <name>",
+	Short: "Create a config profile",
+	Long: `Create a profile with no servers. It starts with the Ansible settings and
+global vars of the active profile (or --from), which can then be changed
+with 'config set --profile <name>'.
+
+Examples:
+  wordsail config profile create staging
+  wordsail config profile create staging --from default --use`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		name := args[0]
+		if err := config.ValidateProfileName(name); err != nil {
+			fail(cmd, "Invalid profile", exit.New(exit.Validation, err))
+		}
+
+		from, _ := cmd.Flags().GetString("from")
+		if from == "" {
+			active, err := config.ActiveProfile()
+			if err != nil {
+				fail(cmd, "Failed to read active profile", err)
+			}
+			from = active
+		}
+
+		var base *config.Config
+		if config.ProfileExists(from) {
+			fromPath, _ := config.ProfilePath(from)
+			loaded, err := config.NewManagerWithPath(fromPath).Load()
+			if err != nil {
+				fail(cmd, fmt.Sprintf("Failed to load profile '%s'", from), err)
+			}
+			base = loaded
+		} else if cmd.Flags().Changed("from") {
+			fail(cmd, "Profile not found", exit.Errorf(exit.Validation, "profile '%s' does not exist", from))
+		}
+
+		path, err := config.CreateProfile(name, base)
+		if err != nil {
+			fail(cmd, "Failed to create profile", err)
+		}
+
+		use, _ := cmd.Flags().GetBool("use")
+		if use {
+			if err := config.SetStoredProfile(name); err != nil {
+				fail(cmd, "Failed to switch profile", err)
+			}
+		}
+
+		outputSuccess(cmd, "profile_created", map[string]interface{}{
+			"profile": name,
+			"path":    path,
+			"active":  use,
+		})
+	},
+}
+
+// configBackupRemoteCmd represents the config backup-remote command
+var configBackupRemoteCmd = &cobra.Command{
+	Use:   "backup-remote",
+	Short: "Back up the configuration file to a remote target",
+	Long: `Push the wordsail configuration file to a git repository or object store.
+
+The target is read from global_vars.config_backup_remote (or --target):
+  s3://bucket/prefix          uploaded with the aws CLI
+  rclone:remote:path          uploaded with rclone
+  git@host:org/repo.git       committed and pushed (also ssh://, https://....git, git:<url>)
+
+Set global_vars.auto_backup_config: true to back up automatically after any
+command that changes the configuration.
+
+Note: the file is uploaded as-is. It contains server credentials, so use a
+private repository or bucket with encryption at rest.
+
+Examples:
+  wordsail config backup-remote
+  wordsail config backup-remote --check
+  wordsail config backup-remote --target s3://my-bucket/wordsail`,
+	Run: func(cmd *cobra.Command, args []string) {
+		mgr, err := config.NewManager()
+		if err != nil {
+			fail(cmd, "Failed to create config manager", err)
+		}
+
+		if !mgr.ConfigExists() {
+			fail(cmd, "Configuration file not found", exit.Errorf(exit.ConfigNotFound, "run 'wordsail init' first"))
+		}
+
+		cfg, err := mgr.Load()
+		if err != nil {
+			fail(cmd, "Failed to load configuration", exit.New(exit.Validation, err))
+		}
+
+		targetStr, _ := cmd.Flags().GetString("target")
+		if targetStr == "" {
+			targetStr = config.RemoteBackupTarget(cfg)
+		}
+
+		target, err := config.ParseRemoteTarget(targetStr)
+		if err != nil {
+			fail(cmd, "Invalid backup target", exit.New(exit.Validation, err))
+		}
+
+		outputInfo(cmd, "Checking access to %s...\n", target)
+		if err := mgr.ValidateRemote(target); err != nil {
+			fail(cmd, "Backup target check failed", err)
+		}
+
+		checkOnly, _ := cmd.Flags().GetBool("check")
+		if checkOnly {
+			outputSuccess(cmd, "config_backup_checked", map[string]interface{}{
+				"target": target.URL,
+				"kind":   string(target.Kind),
+			})
+			return
+		}
+
+		if err := mgr.BackupToRemote(target); err != nil {
+			fail(cmd, "Backup failed", err)
+		}
+
+		outputSuccess(cmd, "config_backed_up", map[string]interface{}{
+			"target": target.URL,
+			"kind":   string(target.Kind),
+		})
+	},
+}
+
+// configFingerprint holds the config file hash taken before the command ran
+var configFingerprint string
+
+// recordConfigFingerprint remembers the current config contents so that
+// autoBackupConfig can tell whether the command changed them
+func recordConfigFingerprint() {
+	mgr, err := config.NewManager()
+	if err != nil {
+		return
+	}
+	configFingerprint = mgr.Fingerprint()
+}
+
+// autoBackupConfig pushes the config to the remote target after a command
+// changed it, when global_vars.auto_backup_config is enabled
+func autoBackupConfig(cmd *cobra.Command) {
+	if cmd == configBackupRemoteCmd {
+		return
+	}
+
+	mgr, err := config.NewManager()
+	if err != nil || !mgr.ConfigExists() {
+		return
+	}
+
+	cfg, err := mgr.Load()
+	if err != nil {
+		return
+	}
+
+	changed := mgr.Fingerprint() != configFingerprint
+	if !config.ShouldAutoBackup(cfg, changed) {
+		return
+	}
+
+	target, err := config.ParseRemoteTarget(config.RemoteBackupTarget(cfg))
+	if err != nil {
+		outputWarning(cmd, "Automatic config backup skipped: %v", err)
+		return
+	}
+
+	if err := mgr.BackupToRemote(target); err != nil {
+		outputWarning(cmd, "Automatic config backup failed: %v", err)
+		return
+	}
+
+	if !isJSONOutput(cmd) {
+		color.Green("✓ Configuration backed up to %s", target.URL)
+	}
+}
+
 func init() {
 	rootCmd.AddCommand(configCmd)
 	configCmd.AddCommand(configShowCmd)
 	configCmd.AddCommand(configValidateCmd)
 	configCmd.AddCommand(configEditCmd)
+	configCmd.AddCommand(configBackupRemoteCmd)
+	configCmd.AddCommand(configGetCmd)
+	configCmd.AddCommand(configSetCmd)
+	configCmd.AddCommand(configMigrateCmd)
+	configCmd.AddCommand(configRestoreCmd)
+	configCmd.AddCommand(configDiffCmd)
+	configCmd.AddCommand(configReconcileCmd)
+	configCmd.AddCommand(configProfileCmd)
+	configProfileCmd.AddCommand(configProfileListCmd)
+	configProfileCmd.AddCommand(configProfileUseCmd)
+	configProfileCmd.AddCommand(configProfileCreateCmd)
+
+	// config backup-remote flags
+	configBackupRemoteCmd.Flags().String("target", "", "Backup target (overrides global_vars.config_backup_remote)")
+	configBackupRemoteCmd.Flags().Bool("check", false, "Only validate the target and credentials")
+	configBackupRemoteCmd.Flags().Bool("json", false, "Output in JSON format")
+
+	// config validate flags
+	configValidateCmd.Flags().Bool("fix", false, "Repair drift left by failed operations")
+	configValidateCmd.Flags().BoolP("force", "f", false, "Write fixes without confirmation")
+
+	// config reconcile flags
+	configReconcileCmd.Flags().BoolP("force", "f", false, "Apply without confirmation")
+	configReconcileCmd.Flags().Bool("json", false, "Output in JSON format")
+
+	// config profile flags
+	configProfileListCmd.Flags().Bool("json", false, "Output in JSON format")
+	configProfileUseCmd.Flags().Bool("json", false, "Output in JSON format")
+	configProfileCreateCmd.Flags().String("from", "", "Profile to copy Ansible settings and global vars from (default: the active profile)")
+	configProfileCreateCmd.Flags().Bool("use", false, "Make the new profile the default")
+	configProfileCreateCmd.Flags().Bool("json", false, "Output in JSON format")
+
+	// config get/set flags
+	configGetCmd.Flags().Bool("json", false, "Output in JSON format")
+	configSetCmd.Flags().Bool("json", false, "Output in JSON format")
+
+	// config migrate flags
+	configMigrateCmd.Flags().Bool("json", false, "Output in JSON format")
+
+	// config restore flags
+	configRestoreCmd.Flags().Bool("list", false, "List available backups")
+	configRestoreCmd.Flags().BoolP("force", "f", false, "Skip confirmation prompt")
+	configRestoreCmd.Flags().Bool("json", false, "Output in JSON format")
+
+	// config diff flags
+	configDiffCmd.Flags().Bool("json", false, "Output in JSON format")
 }
@@ -1,15 +1,20 @@
 package cmd
 
 import (
+	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"os"
+	"path/filepath"
+	"strings"
 	"time"
 
-	"github.com/AlecAivazis/survey/v2"
 	"github.com/fatih/color"
 	"github.com/spf13/cobra"
 	"github.com/wordsail/cli/internal/ansible"
 	"github.com/wordsail/cli/internal/config"
+	"github.com/wordsail/cli/internal/exit"
 	"github.com/wordsail/cli/internal/prompt"
 	"github.com/wordsail/cli/internal/state"
 	"github.com/wordsail/cli/internal/utils"
@@ -35,23 +40,23 @@ Examples:
   wordsail domain add
 
   # Non-interactive mode (for automation/AI agents)
-  wordsail domain add --server myserver --site mysite --domain www.example.com --ssl`,
+  wordsail domain add --server myserver --site mysite --domain www.example.com --ssl
+
+  # Verify A and AAAA records first (AAAA is checked when the server has an IPv6 address)
+  wordsail domain add --server myserver --site mysite --domain www.example.com --aaaa-check`,
 	Run: func(cmd *cobra.Command, args []string) {
 		mgr, err := config.NewManager()
 		if err != nil {
-			outputError(cmd, "Failed to create config manager", err)
-			os.Exit(1)
+			fail(cmd, "Failed to create config manager", err)
 		}
 
 		if !mgr.ConfigExists() {
-			outputError(cmd, "Configuration file not found", fmt.Errorf("run 'wordsail init' first"))
-			os.Exit(1)
+			fail(cmd, "Configuration file not found", exit.Errorf(exit.ConfigNotFound, "run 'wordsail init' first"))
 		}
 
 		cfg, err := mgr.Load()
 		if err != nil {
-			outputError(cmd, "Failed to load configuration", err)
-			os.Exit(1)
+			fail(cmd, "Failed to load configuration", exit.New(exit.Validation, err))
 		}
 
 		var input *prompt.DomainAddInput
@@ -71,15 +76,13 @@ Examples:
 				IssueSSL:   issueSSL,
 			}
 		} else if serverName != "" || siteName != "" || domain != "" {
-			outputError(cmd, "Incomplete flags", fmt.Errorf("--server, --site, and --domain are all required for non-interactive mode"))
-			os.Exit(1)
+			fail(cmd, "Incomplete flags", exit.Errorf(exit.Validation, "--server, --site, and --domain are all required for non-interactive mode"))
 		} else {
 			// Interactive mode - get input from prompts
 			var err error
 			input, err = prompt.PromptDomainAdd(cfg.Servers)
 			if err != nil {
-				outputError(cmd, "Failed to get domain details", err)
-				os.Exit(1)
+				fail(cmd, "Failed to get domain details", err)
 			}
 		}
 
@@ -93,8 +96,29 @@ Examples:
 		}
 
 		if targetServer == nil {
-			color.Red("Error: Server '%s' not found", input.ServerName)
-			os.Exit(1)
+			fail(cmd, "Server not found", exit.Errorf(exit.Validation, "server '%s' does not exist", input.ServerName))
+		}
+
+		site, err := utils.ResolveSite(targetServer, input.SiteID)
+		if err != nil {
+			fail(cmd, "Site not found", exit.New(exit.Validation, err))
+		}
+		input.SiteID = site.SiteID
+
+		// Nginx cannot serve one domain from two sites, so refuse a domain
+		// that is already attached anywhere in the inventory
+		if conflictServer, conflictSite := utils.FindSiteByDomainAcrossServers(cfg.Servers, input.Domain); conflictSite != nil {
+			if conflictServer == targetServer && conflictSite.SiteID == site.SiteID {
+				fail(cmd, "Domain already attached", exit.Errorf(exit.Validation, "domain '%s' is already attached to site '%s'", input.Domain, site.SiteID))
+			}
+			fail(cmd, "Domain already in use", exit.Errorf(exit.Validation, "domain '%s' is already used by site '%s' on server '%s'",
+				input.Domain, conflictSite.SiteID, conflictServer.Name))
+		}
+
+		// Optional A/AAAA pre-check against the server's addresses
+		var dnsCheck *utils.DNSCheck
+		if aaaaCheck, _ := cmd.Flags().GetBool("aaaa-check"); aaaaCheck {
+			dnsCheck = checkDomainDNS(cmd, targetServer, input.Domain)
 		}
 
 		// Prepare extra vars for Ansible
@@ -105,20 +129,14 @@ Examples:
 		}
 
 		// Create Ansible executor
-		executor := ansible.NewExecutor(cfg.Ansible.Path)
-		executor.SetVerbose(Verbose)
-		executor.SetDryRun(DryRun)
+		executor := newExecutor(cmd, cfg)
 
 		// Execute domain_management.yml playbook
-		fmt.Println()
-		color.Cyan("═══════════════════════════════════════════════════════")
-		color.Cyan("  Adding domain: %s", input.Domain)
-		color.Cyan("═══════════════════════════════════════════════════════")
-		fmt.Println()
+		outputBanner(cmd, color.Cyan, fmt.Sprintf("Adding domain: %s", input.Domain))
 
-		if err := executor.ExecutePlaybook("playbooks/domain_management.yml", *targetServer, extraVars, cfg.GlobalVars); err != nil {
-			color.Red("\n✗ Domain addition failed: %v", err)
-			os.Exit(1)
+		stats, err := executor.ExecutePlaybook("playbooks/domain_management.yml", *targetServer, extraVars, cfg.GlobalVars)
+		if err != nil {
+			fail(cmd, "Domain addition failed", err)
 		}
 
 		// Add domain to configuration
@@ -129,18 +147,33 @@ Examples:
 
 		stateMgr := state.NewManager(mgr)
 		if err := stateMgr.AddDomainToSite(input.ServerName, input.SiteID, newDomain); err != nil {
-			color.Red("Warning: Failed to update configuration: %v", err)
+			outputWarning(cmd, "Failed to update configuration: %v", err)
 		}
 
-		color.Green("\n✓ Domain '%s' added successfully", input.Domain)
+		resultData := map[string]interface{}{
+			"domain":      input.Domain,
+			"server":      input.ServerName,
+			"site_id":     input.SiteID,
+			"ssl_enabled": false,
+			"changed":     stats.Changed,
+		}
+		if dnsCheck != nil {
+			resultData["dns_resolved_a"] = dnsCheck.ResolvedA
+			resultData["dns_a_matches"] = dnsCheck.AMatches
+			if dnsCheck.IPv6Checked() {
+				resultData["dns_resolved_aaaa"] = dnsCheck.ResolvedAAAA
+				resultData["dns_aaaa_matches"] = dnsCheck.AAAAMatches
+			}
+		}
+
+		if !isJSONOutput(cmd) {
+			fmt.Println()
+			outputSuccess(cmd, "domain_added", resultData)
+		}
 
 		// Issue SSL if requested
 		if input.IssueSSL {
-			fmt.Println()
-			color.Cyan("═══════════════════════════════════════════════════════")
-			color.Cyan("  Issuing SSL certificate for: %s", input.Domain)
-			color.Cyan("═══════════════════════════════════════════════════════")
-			fmt.Println()
+			outputBanner(cmd, color.Cyan, fmt.Sprintf("Issuing SSL certificate for: %s", input.Domain))
 
 			// Get certbot email from global vars
 			certbotEmail := "admin@example.com"
@@ -155,11 +188,12 @@ Examples:
 			}
 
 			sslResult, err := executor.ExecutePlaybookWithResult("playbooks/domain_management.yml", *targetServer, sslVars, cfg.GlobalVars)
+			recordSSLAttempt(cmd, mgr, input.Domain, err == nil, false)
 			if err != nil {
-				color.Red("\n✗ SSL certificate issuance failed: %v", err)
-				fmt.Println("The domain has been added but SSL is not configured.")
-				fmt.Println("You can issue SSL later with: wordsail domain ssl")
-				os.Exit(1)
+				outputError(cmd, "SSL certificate issuance failed", sslIssueError(sslResult, err))
+				outputInfo(cmd, "The domain has been added but SSL is not configured.\n")
+				outputInfo(cmd, "You can issue SSL later with: wordsail domain ssl\n")
+				os.Exit(exit.CodeOf(sslIssueError(sslResult, err)))
 			}
 
 			// Update domain with SSL info
@@ -167,8 +201,8 @@ Examples:
 			var expiresAt *time.Time
 
 			// Try to parse actual expiry from Ansible output
-			if sslResult.SSLInfo != nil && sslResult.SSLInfo.Expiry != "" {
-				expiresAt = utils.ParseSSLExpiry(sslResult.SSLInfo.Expiry)
+			if sslResult.SSLInfo != nil {
+				expiresAt = parseSSLExpiry(cmd, sslResult.SSLInfo.Expiry)
 			}
 
 			// Fallback to 90 days if parsing fails
@@ -184,15 +218,36 @@ Examples:
 				SSLExpiresAt: expiresAt,
 			}
 
-			if err := stateMgr.UpdateDomainSSL(input.ServerName, input.SiteID, input.Domain, sslDomain); err != nil {
-				color.Red("Warning: Failed to update SSL status in configuration: %v", err)
+			writeState(cmd, mgr, state.PendingChange{
+				Kind:      state.PendingDomainSSL,
+				Server:    input.ServerName,
+				SiteID:    input.SiteID,
+				Domain:    input.Domain,
+				DomainSSL: &sslDomain,
+			}, func() error {
+				return stateMgr.UpdateDomainSSL(input.ServerName, input.SiteID, input.Domain, sslDomain)
+			})
+
+			resultData["ssl_enabled"] = true
+			resultData["ssl_issued_at"] = now.Format(time.RFC3339)
+			resultData["ssl_expires_at"] = expiresAt.Format(time.RFC3339)
+
+			if isJSONOutput(cmd) {
+				outputSuccess(cmd, "domain_added", resultData)
+				return
 			}
 
-			color.Green("\n✓ SSL certificate issued successfully")
+			fmt.Println()
+			outputSuccess(cmd, "ssl_issued", resultData)
 			fmt.Println()
 			fmt.Printf("Domain URL:  https://%s\n", input.Domain)
 			fmt.Printf("Expires:     %s\n", expiresAt.Format("2006-01-02"))
 		} else {
+			if isJSONOutput(cmd) {
+				outputSuccess(cmd, "domain_added", resultData)
+				return
+			}
+
 			fmt.Println()
 			fmt.Printf("Domain URL:  http://%s\n", input.Domain)
 			fmt.Println()
@@ -201,6 +256,97 @@ Examples:
 	},
 }
 
+// checkDomainDNS compares the domain's A and AAAA records with the server's
+// addresses and warns when they disagree. Lookup failures are reported as a
+// warning and return nil so the domain can still be added.
+func checkDomainDNS(cmd *cobra.Command, server *models.Server, domain string) *utils.DNSCheck {
+	if server.IPv6 == "" {
+		outputWarning(cmd, "Server '%s' has no IPv6 address configured; checking A records only", server.Name)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	serverIP, err := serverIPv4(ctx, server)
+	if err != nil {
+		outputWarning(cmd, "DNS pre-check skipped: %v", err)
+		return nil
+	}
+
+	check, err := utils.CheckDomainDNS(ctx, utils.DefaultResolver, domain, serverIP, server.IPv6)
+	if err != nil {
+		outputWarning(cmd, "DNS pre-check skipped: %v", err)
+		return nil
+	}
+
+	if !isJSONOutput(cmd) {
+		fmt.Printf("DNS for %s:\n", domain)
+		fmt.Printf("  A:     %s (server %s) %s\n", describeRecords(check.ResolvedA), check.ExpectedIPv4, matchMark(check.AMatches))
+		if check.IPv6Checked() {
+			fmt.Printf("  AAAA:  %s (server %s) %s\n", describeRecords(check.ResolvedAAAA), check.ExpectedIPv6, matchMark(check.AAAAMatches))
+		}
+		fmt.Println()
+	}
+
+	if warning := check.Warning(); warning != "" {
+		outputWarning(cmd, "%s", warning)
+	} else if !check.Matches() {
+		outputWarning(cmd, "DNS for %s does not point to this server yet", domain)
+	}
+
+	return check
+}
+
+// serverIPv4 returns the server's IPv4 address, resolving its hostname when
+// it was registered without an IP
+func serverIPv4(ctx context.Context, server *models.Server) (string, error) {
+	if server.IP != "" {
+		return server.IP, nil
+	}
+	return utils.ResolveIPv4(ctx, utils.DefaultResolver, server.Hostname)
+}
+
+// dnsWaitInterval is the pause between lookups of --dns-wait
+const dnsWaitInterval = 10 * time.Second
+
+// waitForDomainDNS polls the domain's A records until they include the
+// server's IPv4 (--dns-wait), printing what every lookup returned
+func waitForDomainDNS(cmd *cobra.Command, server *models.Server, domain string, timeout time.Duration) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	serverIP, err := serverIPv4(ctx, server)
+	cancel()
+	if err != nil {
+		return err
+	}
+
+	outputInfo(cmd, "Waiting for %s to resolve to %s (timeout %s)...\n", domain, serverIP, timeout)
+	_, err = utils.WaitForDNS(utils.DefaultResolver, domain, serverIP, dnsWaitInterval, timeout, func(check *utils.DNSCheck, err error) {
+		stamp := time.Now().Format("15:04:05")
+		if err != nil {
+			outputInfo(cmd, "  %s lookup failed: %v\n", stamp, err)
+			return
+		}
+		outputInfo(cmd, "  %s %s %s\n", stamp, describeRecords(check.ResolvedA), matchMark(check.AMatches))
+	})
+	return err
+}
+
+// describeRecords formats resolved addresses for display
+func describeRecords(addrs []string) string {
+	if len(addrs) == 0 {
+		return "none"
+	}
+	return strings.Join(addrs, ", ")
+}
+
+// matchMark returns a check or cross for a DNS match result
+func matchMark(ok bool) string {
+	if ok {
+		return color.GreenString("✓")
+	}
+	return color.RedString("✗")
+}
+
 // domainRemoveCmd represents the domain remove command
 var domainRemoveCmd = &cobra.Command{
 	Use:     "remove",
@@ -213,23 +359,23 @@ Examples:
   wordsail domain remove
 
   # Non-interactive mode (for automation/AI agents)
-  wordsail domain remove --server myserver --site mysite --domain www.example.com --force`,
+  wordsail domain remove --server myserver --site mysite --domain www.example.com --force
+
+  # Detach every domain except the primary, reloading Nginx once
+  wordsail domain remove --server myserver --site mysite --all`,
 	Run: func(cmd *cobra.Command, args []string) {
 		mgr, err := config.NewManager()
 		if err != nil {
-			outputError(cmd, "Failed to create config manager", err)
-			os.Exit(1)
+			fail(cmd, "Failed to create config manager", err)
 		}
 
 		if !mgr.ConfigExists() {
-			outputError(cmd, "Configuration file not found", fmt.Errorf("run 'wordsail init' first"))
-			os.Exit(1)
+			fail(cmd, "Configuration file not found", exit.Errorf(exit.ConfigNotFound, "run 'wordsail init' first"))
 		}
 
 		cfg, err := mgr.Load()
 		if err != nil {
-			outputError(cmd, "Failed to load configuration", err)
-			os.Exit(1)
+			fail(cmd, "Failed to load configuration", exit.New(exit.Validation, err))
 		}
 
 		var input *prompt.DomainRemoveInput
@@ -239,6 +385,17 @@ Examples:
 		siteName, _ := cmd.Flags().GetString("site")
 		domain, _ := cmd.Flags().GetString("domain")
 
+		if all, _ := cmd.Flags().GetBool("all"); all {
+			if domain != "" {
+				fail(cmd, "Invalid flags", exit.Errorf(exit.Validation, "--all cannot be combined with --domain"))
+			}
+			removeAllDomains(cmd, mgr, cfg, serverName, siteName)
+			return
+		}
+		if includePrimary, _ := cmd.Flags().GetBool("include-primary"); includePrimary {
+			fail(cmd, "Invalid flags", exit.Errorf(exit.Validation, "--include-primary requires --all"))
+		}
+
 		if serverName != "" && siteName != "" && domain != "" {
 			// Non-interactive mode
 			input = &prompt.DomainRemoveInput{
@@ -247,15 +404,13 @@ Examples:
 				Domain:     domain,
 			}
 		} else if serverName != "" || siteName != "" || domain != "" {
-			outputError(cmd, "Incomplete flags", fmt.Errorf("--server, --site, and --domain are all required for non-interactive mode"))
-			os.Exit(1)
+			fail(cmd, "Incomplete flags", exit.Errorf(exit.Validation, "--server, --site, and --domain are all required for non-interactive mode"))
 		} else {
 			// Interactive mode - get input from prompts
 			var err error
 			input, err = prompt.PromptDomainRemove(cfg.Servers)
 			if err != nil {
-				outputError(cmd, "Failed to get domain details", err)
-				os.Exit(1)
+				fail(cmd, "Failed to get domain details", err)
 			}
 		}
 
@@ -269,24 +424,29 @@ Examples:
 		}
 
 		if targetServer == nil {
-			color.Red("Error: Server '%s' not found", input.ServerName)
-			os.Exit(1)
+			fail(cmd, "Server not found", exit.Errorf(exit.Validation, "server '%s' does not exist", input.ServerName))
 		}
 
+		site, err := utils.ResolveSite(targetServer, input.SiteID)
+		if err != nil {
+			fail(cmd, "Site not found", exit.New(exit.Validation, err))
+		}
+		input.SiteID = site.SiteID
+
 		// Final confirmation
-		color.Yellow("\n⚠️  WARNING: This will remove:")
-		fmt.Printf("  - Domain: %s\n", input.Domain)
-		fmt.Printf("  - Nginx configuration\n")
-		fmt.Printf("  - SSL certificate (if any)\n")
-		fmt.Println()
+		if !assumeYes(cmd) {
+			if isJSONOutput(cmd) {
+				fail(cmd, "Confirmation required", exit.Errorf(exit.Validation, "use --force to remove a domain in JSON mode"))
+			}
+
+			color.Yellow("\n⚠️  WARNING: This will remove:")
+			fmt.Printf("  - Domain: %s\n", input.Domain)
+			fmt.Printf("  - Nginx configuration\n")
+			fmt.Printf("  - SSL certificate (if any)\n")
+			fmt.Println()
 
-		force, _ := cmd.Flags().GetBool("force")
-		if !force {
-			var confirm bool
-			if err := survey.AskOne(&survey.Confirm{
-				Message: "Remove this domain?",
-				Default: false,
-			}, &confirm); err != nil {
+			confirm, err := prompt.Confirm("Remove this domain?", false, false)
+			if err != nil {
 				os.Exit(1)
 			}
 
@@ -296,39 +456,154 @@ Examples:
 			}
 		}
 
+		// Domains redirecting to this one lose their redirect
+		redirectSources := utils.RedirectSourcesFor(site, []string{input.Domain})
+
 		// Prepare extra vars for Ansible
 		extraVars := map[string]interface{}{
-			"operation": "remove_domain",
-			"domain":    input.Domain,
+			"operation":        "remove_domain",
+			"domain":           input.Domain,
+			"redirect_sources": redirectSources,
 		}
 
 		// Create Ansible executor
-		executor := ansible.NewExecutor(cfg.Ansible.Path)
-		executor.SetVerbose(Verbose)
-		executor.SetDryRun(DryRun)
+		executor := newExecutor(cmd, cfg)
 
 		// Execute domain_management.yml playbook
-		fmt.Println()
-		color.Cyan("═══════════════════════════════════════════════════════")
-		color.Cyan("  Removing domain: %s", input.Domain)
-		color.Cyan("═══════════════════════════════════════════════════════")
-		fmt.Println()
+		outputBanner(cmd, color.Cyan, fmt.Sprintf("Removing domain: %s", input.Domain))
 
-		if err := executor.ExecutePlaybook("playbooks/domain_management.yml", *targetServer, extraVars, cfg.GlobalVars); err != nil {
-			color.Red("\n✗ Domain removal failed: %v", err)
-			os.Exit(1)
+		stats, err := executor.ExecutePlaybook("playbooks/domain_management.yml", *targetServer, extraVars, cfg.GlobalVars)
+		if err != nil {
+			fail(cmd, "Domain removal failed", err)
 		}
 
 		// Remove domain from configuration
 		stateMgr := state.NewManager(mgr)
 		if err := stateMgr.RemoveDomainFromSite(input.ServerName, input.SiteID, input.Domain); err != nil {
-			color.Red("Warning: Failed to update configuration: %v", err)
+			outputWarning(cmd, "Failed to update configuration: %v", err)
 		}
-
-		color.Green("\n✓ Domain '%s' removed successfully", input.Domain)
+		clearRedirects(cmd, stateMgr, input.ServerName, input.SiteID, redirectSources)
+
+		outputInfo(cmd, "\n")
+		outputSuccess(cmd, "domain_removed", map[string]interface{}{
+			"domain":  input.Domain,
+			"server":  input.ServerName,
+			"site_id": input.SiteID,
+			"changed": stats.Changed,
+		})
 	},
 }
 
+// removeAllDomains detaches every non-primary domain (and, with
+// --include-primary, the primary too) from a site in one playbook run
+func removeAllDomains(cmd *cobra.Command, mgr *config.Manager, cfg *config.Config, serverName, siteID string) {
+	if serverName == "" || siteID == "" {
+		fail(cmd, "Missing required flags", exit.Errorf(exit.Validation, "--server and --site are required with --all"))
+	}
+
+	targetServer := utils.FindServerByName(cfg.Servers, serverName)
+	if targetServer == nil {
+		fail(cmd, "Server not found", exit.Errorf(exit.Validation, "server '%s' does not exist", serverName))
+	}
+	targetSite, err := utils.ResolveSite(targetServer, siteID)
+	if err != nil {
+		fail(cmd, "Site not found", exit.New(exit.Validation, err))
+	}
+	siteID = targetSite.SiteID
+
+	includePrimary, _ := cmd.Flags().GetBool("include-primary")
+	domains, err := utils.DomainsForBulkRemoval(targetSite, includePrimary)
+	if err != nil {
+		fail(cmd, "Nothing to remove", err)
+	}
+	removesPrimary := includePrimary && domains[len(domains)-1] == targetSite.PrimaryDomain
+
+	if !assumeYes(cmd) {
+		if isJSONOutput(cmd) {
+			fail(cmd, "Confirmation required", exit.Errorf(exit.Validation, "use --force to remove domains in JSON mode"))
+		}
+
+		color.Yellow("\n⚠️  WARNING: This will remove %d domain(s) from site '%s':", len(domains), siteID)
+		for _, d := range domains {
+			fmt.Printf("  - %s\n", d)
+		}
+		fmt.Println()
+
+		confirm, err := prompt.Confirm("Remove these domains?", false, false)
+		if err != nil {
+			os.Exit(1)
+		}
+		if !confirm {
+			fmt.Println("Domain removal cancelled")
+			return
+		}
+
+		// Removing the primary domain takes the site offline, so it must
+		// also be typed out
+		if removesPrimary {
+			color.Red("\n⚠️  The primary domain %s will be removed. The site will no longer be served.", targetSite.PrimaryDomain)
+			confirmed, err := prompt.ConfirmDestructive(fmt.Sprintf("Type %s to confirm:", targetSite.PrimaryDomain), targetSite.PrimaryDomain)
+			if err != nil {
+				os.Exit(1)
+			}
+			if !confirmed {
+				fmt.Println("Domain removal cancelled")
+				return
+			}
+		}
+	}
+
+	redirectSources := utils.RedirectSourcesFor(targetSite, domains)
+	extraVars := map[string]interface{}{
+		"operation":        "remove_domains",
+		"domains":          domains,
+		"redirect_sources": redirectSources,
+	}
+
+	executor := newExecutor(cmd, cfg)
+	outputBanner(cmd, color.Cyan, fmt.Sprintf("Removing %d domain(s) from: %s", len(domains), siteID))
+
+	if _, err := executor.ExecutePlaybook("playbooks/domain_management.yml", *targetServer, extraVars, cfg.GlobalVars); err != nil {
+		fail(cmd, "Domain removal failed", err)
+	}
+
+	// Update configuration per domain and report each one
+	stateMgr := state.NewManager(mgr)
+	summary := make([]map[string]interface{}, 0, len(domains))
+	outputInfo(cmd, "\n")
+	for _, d := range domains {
+		entry := map[string]interface{}{"domain": d, "removed": true}
+		if err := stateMgr.RemoveDomainFromSite(serverName, siteID, d); err != nil {
+			entry["error"] = err.Error()
+			outputInfo(cmd, "  %s %s (configuration not updated: %v)\n", color.YellowString("!"), d, err)
+		} else {
+			outputInfo(cmd, "  %s %s\n", color.GreenString("✓"), d)
+		}
+		summary = append(summary, entry)
+	}
+	clearRedirects(cmd, stateMgr, serverName, siteID, redirectSources)
+	outputInfo(cmd, "\n")
+
+	outputSuccess(cmd, "domains_removed", map[string]interface{}{
+		"server":          serverName,
+		"site_id":         siteID,
+		"domains":         summary,
+		"count":           len(domains),
+		"primary_removed": removesPrimary,
+	})
+}
+
+// clearRedirects forgets the redirects of domains whose target was removed
+func clearRedirects(cmd *cobra.Command, stateMgr *state.Manager, serverName, siteID string, sources []string) {
+	for _, source := range sources {
+		if err := stateMgr.SetDomainRedirect(serverName, siteID, source, ""); err != nil {
+			outputWarning(cmd, "Failed to clear redirect for %s: %v", source, err)
+			continue
+		}
+		outputInfo(cmd, "  Removed redirect from %s\n", source)
+	}
+}
+
 // domainSSLCmd represents the domain ssl command
 var domainSSLCmd = &cobra.Command{
 	Use:   "ssl",
@@ -340,23 +615,31 @@ Examples:
   wordsail domain ssl
 
   # Non-interactive mode (for automation/AI agents)
-  wordsail domain ssl --server myserver --site mysite --domain www.example.com --email admin@example.com`,
+  wordsail domain ssl --server myserver --site mysite --domain www.example.com --email admin@example.com
+
+  # Test the ACME challenge against Let's Encrypt staging first
+  wordsail domain ssl --server myserver --site mysite --domain www.example.com --staging
+
+  # Wait for a just-changed A record to reach the server, then issue
+  wordsail domain ssl --server myserver --site mysite --domain www.example.com --dns-wait --dns-wait-timeout 15m
+
+Issuance attempts are recorded in ~/.wordsail/ssl-history.json. Before requesting a
+production certificate, wordsail warns when a domain is close to Let's Encrypt's weekly
+limits (5 duplicate certificates, 50 per registered domain) and refuses once a limit
+is reached unless --force is given.`,
 	Run: func(cmd *cobra.Command, args []string) {
 		mgr, err := config.NewManager()
 		if err != nil {
-			outputError(cmd, "Failed to create config manager", err)
-			os.Exit(1)
+			fail(cmd, "Failed to create config manager", err)
 		}
 
 		if !mgr.ConfigExists() {
-			outputError(cmd, "Configuration file not found", fmt.Errorf("run 'wordsail init' first"))
-			os.Exit(1)
+			fail(cmd, "Configuration file not found", exit.Errorf(exit.ConfigNotFound, "run 'wordsail init' first"))
 		}
 
 		cfg, err := mgr.Load()
 		if err != nil {
-			outputError(cmd, "Failed to load configuration", err)
-			os.Exit(1)
+			fail(cmd, "Failed to load configuration", exit.New(exit.Validation, err))
 		}
 
 		// Get default certbot email from config
@@ -385,15 +668,13 @@ Examples:
 				CertbotEmail: email,
 			}
 		} else if serverName != "" || siteName != "" || domain != "" {
-			outputError(cmd, "Incomplete flags", fmt.Errorf("--server, --site, and --domain are all required for non-interactive mode"))
-			os.Exit(1)
+			fail(cmd, "Incomplete flags", exit.Errorf(exit.Validation, "--server, --site, and --domain are all required for non-interactive mode"))
 		} else {
 			// Interactive mode - get input from prompts
 			var err error
 			input, err = prompt.PromptDomainSSL(cfg.Servers, defaultEmail)
 			if err != nil {
-				outputError(cmd, "Failed to get SSL details", err)
-				os.Exit(1)
+				fail(cmd, "Failed to get SSL details", err)
 			}
 		}
 
@@ -407,98 +688,621 @@ Examples:
 		}
 
 		if targetServer == nil {
-			color.Red("Error: Server '%s' not found", input.ServerName)
-			os.Exit(1)
+			fail(cmd, "Server not found", exit.Errorf(exit.Validation, "server '%s' does not exist", input.ServerName))
 		}
 
-		// Prepare extra vars for Ansible
-		extraVars := map[string]interface{}{
-			"operation":     "issue_ssl",
-			"domain":        input.Domain,
-			"certbot_email": input.CertbotEmail,
+		site, err := utils.ResolveSite(targetServer, input.SiteID)
+		if err != nil {
+			fail(cmd, "Site not found", exit.New(exit.Validation, err))
+		}
+		input.SiteID = site.SiteID
+
+		// Check the locally tracked Let's Encrypt rate limits before
+		// requesting a production certificate
+		staging, _ := cmd.Flags().GetBool("staging")
+		if !staging {
+			force, _ := cmd.Flags().GetBool("force")
+			if err := checkSSLRateLimit(cmd, mgr, input.Domain, force); err != nil {
+				fail(cmd, "Let's Encrypt rate limit reached", err)
+			}
 		}
 
-		// Create Ansible executor
-		executor := ansible.NewExecutor(cfg.Ansible.Path)
-		executor.SetVerbose(Verbose)
-		executor.SetDryRun(DryRun)
+		if dnsWait, _ := cmd.Flags().GetBool("dns-wait"); dnsWait {
+			timeout, _ := cmd.Flags().GetDuration("dns-wait-timeout")
+			if err := waitForDomainDNS(cmd, targetServer, input.Domain, timeout); err != nil {
+				fail(cmd, "DNS did not propagate", err)
+			}
+		}
 
 		// Execute domain_management.yml playbook
-		fmt.Println()
-		color.Cyan("═══════════════════════════════════════════════════════")
-		color.Cyan("  Issuing SSL certificate for: %s", input.Domain)
-		color.Cyan("═══════════════════════════════════════════════════════")
-		fmt.Println()
+		if staging {
+			outputBanner(cmd, color.Cyan, fmt.Sprintf("Testing SSL issuance against Let's Encrypt staging for: %s", input.Domain))
+		} else {
+			outputBanner(cmd, color.Cyan, fmt.Sprintf("Issuing SSL certificate for: %s", input.Domain))
+		}
 
-		result, err := executor.ExecutePlaybookWithResult("playbooks/domain_management.yml", *targetServer, extraVars, cfg.GlobalVars)
+		sslDomain, err := issueDomainSSL(cmd, mgr, cfg, newExecutor(cmd, cfg), *targetServer, input.SiteID, input.Domain, input.CertbotEmail, staging)
 		if err != nil {
-			color.Red("\n✗ SSL certificate issuance failed: %v", err)
-			os.Exit(1)
+			fail(cmd, "SSL certificate issuance failed", err)
+		}
+
+		if staging {
+			outputSuccess(cmd, "ssl_staging_ok", map[string]interface{}{
+				"domain":  input.Domain,
+				"server":  input.ServerName,
+				"site_id": input.SiteID,
+			})
+			return
+		}
+		now, expiresAt := *sslDomain.SSLIssuedAt, sslDomain.SSLExpiresAt
+
+		if isJSONOutput(cmd) {
+			outputSuccess(cmd, "ssl_issued", map[string]interface{}{
+				"domain":         input.Domain,
+				"server":         input.ServerName,
+				"site_id":        input.SiteID,
+				"ssl_enabled":    true,
+				"ssl_issued_at":  now.Format(time.RFC3339),
+				"ssl_expires_at": expiresAt.Format(time.RFC3339),
+			})
+			return
 		}
 
-		// Update domain with SSL info
+		outputBanner(cmd, color.Green, "✓ SSL certificate issued successfully!")
+		fmt.Printf("Domain:      https://%s\n", input.Domain)
+		fmt.Printf("Issued:      %s\n", now.Format("2006-01-02"))
+		fmt.Printf("Expires:     %s\n", expiresAt.Format("2006-01-02"))
+		fmt.Printf("Auto-renew:  Certbot will auto-renew before expiration\n")
+	},
+}
+
+// checkSSLRateLimit checks the locally tracked Let's Encrypt rate limits
+// before a production certificate is requested for domain. It warns when a
+// limit is close and returns an error once one is reached, unless force.
+func checkSSLRateLimit(cmd *cobra.Command, mgr *config.Manager, domain string, force bool) error {
+	history, err := state.LoadSSLHistory(sslHistoryPath(mgr))
+	if err != nil {
+		outputWarning(cmd, "Could not read SSL history: %v", err)
+		return nil
+	}
+	status := history.Check(domain, time.Now())
+	switch {
+	case status.Level == state.RateLimitExceeded && !force:
+		return fmt.Errorf("%s; wait, test with --staging, or use --force to try anyway", status.Message)
+	case status.Level != state.RateLimitOK:
+		outputWarning(cmd, "Approaching Let's Encrypt rate limits: %s. Use --staging while testing.", status.Message)
+	}
+	return nil
+}
+
+// issueDomainSSL requests a certificate for a domain of a site and records
+// the attempt in the SSL history. A production certificate is also recorded
+// on the domain in the config; the returned domain carries its issue and
+// expiry dates.
+func issueDomainSSL(cmd *cobra.Command, mgr *config.Manager, cfg *config.Config, executor *ansible.Executor, server models.Server, siteID, domain, email string, staging bool) (models.Domain, error) {
+	extraVars := map[string]interface{}{
+		"operation":       "issue_ssl",
+		"domain":          domain,
+		"certbot_email":   email,
+		"certbot_staging": staging,
+	}
+
+	result, err := executor.ExecutePlaybookWithResult("playbooks/domain_management.yml", server, extraVars, cfg.GlobalVars)
+	recordSSLAttempt(cmd, mgr, domain, err == nil, staging)
+	if err != nil {
+		return models.Domain{}, sslIssueError(result, err)
+	}
+
+	now := time.Now()
+	var expiresAt *time.Time
+
+	// Try to parse actual expiry from Ansible output
+	if result.SSLInfo != nil {
+		expiresAt = parseSSLExpiry(cmd, result.SSLInfo.Expiry)
+	}
+
+	// Fallback to 90 days if parsing fails
+	if expiresAt == nil {
+		fallback := now.AddDate(0, 3, 0)
+		expiresAt = &fallback
+	}
+
+	sslDomain := models.Domain{
+		Domain:       domain,
+		SSLEnabled:   true,
+		SSLIssuedAt:  &now,
+		SSLExpiresAt: expiresAt,
+	}
+	if staging {
+		return sslDomain, nil
+	}
+
+	stateMgr := state.NewManager(mgr)
+	writeState(cmd, mgr, state.PendingChange{
+		Kind:      state.PendingDomainSSL,
+		Server:    server.Name,
+		SiteID:    siteID,
+		Domain:    domain,
+		DomainSSL: &sslDomain,
+	}, func() error {
+		return stateMgr.UpdateDomainSSL(server.Name, siteID, domain, sslDomain)
+	})
+	return sslDomain, nil
+}
+
+// sslHistoryPath returns the SSL issuance history file next to the config
+func sslHistoryPath(mgr *config.Manager) string {
+	return filepath.Join(mgr.GetConfigDir(), state.SSLHistoryFile)
+}
+
+// recordSSLAttempt adds a certificate request to the local SSL history
+func recordSSLAttempt(cmd *cobra.Command, mgr *config.Manager, domain string, success, staging bool) {
+	history, err := state.LoadSSLHistory(sslHistoryPath(mgr))
+	if err == nil {
 		now := time.Now()
-		var expiresAt *time.Time
+		history.Record(domain, now, success, staging)
+		err = history.Save(now)
+	}
+	if err != nil {
+		outputWarning(cmd, "Failed to update SSL history: %v", err)
+	}
+}
+
+// parseSSLExpiry parses the expiry reported by the SSL playbook. A value it
+// cannot parse is printed so the missing format can be reported.
+func parseSSLExpiry(cmd *cobra.Command, raw string) *time.Time {
+	if raw == "" {
+		return nil
+	}
+	expiresAt := utils.ParseSSLExpiry(raw)
+	if expiresAt == nil {
+		outputWarning(cmd, "Could not parse certificate expiry %q; please report this format", raw)
+	}
+	return expiresAt
+}
 
-		// Try to parse actual expiry from Ansible output
-		if result.SSLInfo != nil && result.SSLInfo.Expiry != "" {
-			expiresAt = utils.ParseSSLExpiry(result.SSLInfo.Expiry)
+// sslIssueError replaces a playbook failure with a readable message when
+// certbot hit a Let's Encrypt rate limit
+func sslIssueError(result *ansible.PlaybookResult, err error) error {
+	if result != nil {
+		if msg := state.ParseRateLimitError(result.Output); msg != "" {
+			return exit.New(exit.Playbook, errors.New(msg))
 		}
+	}
+	return err
+}
+
+// domainSetPrimaryCmd represents the domain set-primary command
+var domainSetPrimaryCmd = &cobra.Command{
+	Use:   "set-primary",
+	Short: "Make an attached domain the site's primary domain",
+	Long: `Make a domain that is already attached to a site its primary domain.
 
-		// Fallback to 90 days if parsing fails
-		if expiresAt == nil {
-			fallback := now.AddDate(0, 3, 0)
-			expiresAt = &fallback
+The new domain's document root is linked to the site's files and a WordPress
+search-replace rewrites the old domain to the new one in every site table
+(GUIDs are left alone). Take a backup first if you are unsure.
+
+Examples:
+  wordsail domain set-primary --server myserver --site mysite --domain www.example.com
+
+  # Skip the confirmation prompt
+  wordsail domain set-primary --server myserver --site mysite --domain www.example.com --force`,
+	Run: func(cmd *cobra.Command, args []string) {
+		mgr, err := config.NewManager()
+		if err != nil {
+			fail(cmd, "Failed to create config manager", err)
+		}
+
+		if !mgr.ConfigExists() {
+			fail(cmd, "Configuration file not found", exit.Errorf(exit.ConfigNotFound, "run 'wordsail init' first"))
 		}
 
-		sslDomain := models.Domain{
-			Domain:       input.Domain,
-			SSLEnabled:   true,
-			SSLIssuedAt:  &now,
-			SSLExpiresAt: expiresAt,
+		cfg, err := mgr.Load()
+		if err != nil {
+			fail(cmd, "Failed to load configuration", exit.New(exit.Validation, err))
+		}
+
+		serverName, _ := cmd.Flags().GetString("server")
+		siteID, _ := cmd.Flags().GetString("site")
+		domain, _ := cmd.Flags().GetString("domain")
+		if serverName == "" || siteID == "" || domain == "" {
+			fail(cmd, "Missing required flags", exit.Errorf(exit.Validation, "--server, --site and --domain are required"))
+		}
+
+		targetServer := utils.FindServerByName(cfg.Servers, serverName)
+		if targetServer == nil {
+			fail(cmd, "Server not found", exit.Errorf(exit.Validation, "server '%s' does not exist", serverName))
+		}
+		targetSite, err := utils.ResolveSite(targetServer, siteID)
+		if err != nil {
+			fail(cmd, "Site not found", exit.New(exit.Validation, err))
+		}
+		siteID = targetSite.SiteID
+		if err := utils.ValidatePrimaryDomainChange(targetSite, domain); err != nil {
+			fail(cmd, "Cannot change primary domain", exit.New(exit.Validation, err))
+		}
+		oldDomain := targetSite.PrimaryDomain
+
+		if !assumeYes(cmd) {
+			if isJSONOutput(cmd) {
+				fail(cmd, "Confirmation required", exit.Errorf(exit.Validation, "use --force to change the primary domain in JSON mode"))
+			}
+
+			color.Yellow("\n⚠️  A WordPress search-replace will rewrite %s to %s in the site's database.", oldDomain, domain)
+			confirm, err := prompt.Confirm(fmt.Sprintf("Make %s the primary domain of '%s'?", domain, siteID), false, false)
+			if err != nil {
+				os.Exit(1)
+			}
+			if !confirm {
+				fmt.Println("Primary domain change cancelled")
+				return
+			}
+		}
+
+		extraVars := map[string]interface{}{
+			"site_id":    siteID,
+			"old_domain": oldDomain,
+			"new_domain": domain,
+		}
+
+		executor := newExecutor(cmd, cfg)
+		outputBanner(cmd, color.Cyan, fmt.Sprintf("Changing primary domain: %s → %s", oldDomain, domain))
+
+		if _, err := executor.ExecutePlaybook("playbooks/set_primary_domain.yml", *targetServer, extraVars, cfg.GlobalVars); err != nil {
+			fail(cmd, "Failed to change primary domain", err)
 		}
 
 		stateMgr := state.NewManager(mgr)
-		if err := stateMgr.UpdateDomainSSL(input.ServerName, input.SiteID, input.Domain, sslDomain); err != nil {
-			color.Red("Warning: Failed to update configuration: %v", err)
+		if err := stateMgr.SetSitePrimaryDomain(serverName, siteID, domain); err != nil {
+			fail(cmd, "Primary domain changed but failed to update configuration", err)
 		}
 
+		outputSuccess(cmd, "primary_domain_set", map[string]interface{}{
+			"server":     serverName,
+			"site_id":    siteID,
+			"domain":     domain,
+			"old_domain": oldDomain,
+		})
+	},
+}
+
+// domainRedirectCmd represents the domain redirect command
+var domainRedirectCmd = &cobra.Command{
+	Use:   "redirect",
+	Short: "Redirect one domain of a site to another",
+	Long: `Configure a permanent (301) redirect between two domains of the same site,
+typically www to apex or apex to www. Let's Encrypt challenges are not
+redirected, so certificates for the redirecting domain keep renewing.
+
+Examples:
+  # Redirect www to the apex domain
+  wordsail domain redirect --server myserver --site mysite --from www.example.com --to example.com
+
+  # Stop redirecting
+  wordsail domain redirect --server myserver --site mysite --from www.example.com --remove`,
+	Run: func(cmd *cobra.Command, args []string) {
+		mgr, err := config.NewManager()
+		if err != nil {
+			fail(cmd, "Failed to create config manager", err)
+		}
+
+		if !mgr.ConfigExists() {
+			fail(cmd, "Configuration file not found", exit.Errorf(exit.ConfigNotFound, "run 'wordsail init' first"))
+		}
+
+		cfg, err := mgr.Load()
+		if err != nil {
+			fail(cmd, "Failed to load configuration", exit.New(exit.Validation, err))
+		}
+
+		serverName, _ := cmd.Flags().GetString("server")
+		siteID, _ := cmd.Flags().GetString("site")
+		from, _ := cmd.Flags().GetString("from")
+		to, _ := cmd.Flags().GetString("to")
+		remove, _ := cmd.Flags().GetBool("remove")
+
+		if serverName == "" || siteID == "" || from == "" {
+			fail(cmd, "Missing required flags", exit.Errorf(exit.Validation, "--server, --site and --from are required"))
+		}
+		if remove == (to != "") {
+			fail(cmd, "Invalid flags", exit.Errorf(exit.Validation, "use either --to or --remove"))
+		}
+
+		targetServer := utils.FindServerByName(cfg.Servers, serverName)
+		if targetServer == nil {
+			fail(cmd, "Server not found", exit.Errorf(exit.Validation, "server '%s' does not exist", serverName))
+		}
+		targetSite, err := utils.ResolveSite(targetServer, siteID)
+		if err != nil {
+			fail(cmd, "Site not found", exit.New(exit.Validation, err))
+		}
+		siteID = targetSite.SiteID
+
+		if remove {
+			current := ""
+			for _, d := range targetSite.Domains {
+				if d.Domain == from {
+					current = d.RedirectTo
+				}
+			}
+			if current == "" {
+				fail(cmd, "No redirect configured", exit.Errorf(exit.Validation, "%s does not redirect anywhere", from))
+			}
+		} else if err := utils.ValidateDomainRedirect(targetSite, from, to); err != nil {
+			fail(cmd, "Invalid redirect", exit.New(exit.Validation, err))
+		}
+
+		extraVars := map[string]interface{}{
+			"operation":   "redirect_domain",
+			"domain":      from,
+			"redirect_to": to,
+		}
+
+		executor := newExecutor(cmd, cfg)
+		if remove {
+			outputBanner(cmd, color.Cyan, fmt.Sprintf("Removing redirect from: %s", from))
+		} else {
+			outputBanner(cmd, color.Cyan, fmt.Sprintf("Redirecting %s → %s", from, to))
+		}
+
+		if _, err := executor.ExecutePlaybook("playbooks/domain_management.yml", *targetServer, extraVars, cfg.GlobalVars); err != nil {
+			fail(cmd, "Redirect configuration failed", err)
+		}
+
+		stateMgr := state.NewManager(mgr)
+		if err := stateMgr.SetDomainRedirect(serverName, siteID, from, to); err != nil {
+			outputWarning(cmd, "Failed to update configuration: %v", err)
+		}
+
+		outputSuccess(cmd, "domain_redirected", map[string]interface{}{
+			"server":  serverName,
+			"site_id": siteID,
+			"from":    from,
+			"to":      to,
+		})
+	},
+}
+
+// domainListCmd represents the domain list command
+var domainListCmd = &cobra.Command{
+	Use:         "list",
+	Short:       "List the domains of a site",
+	Annotations: map[string]string{csvAnnotation: ""},
+	Long: `List the domains attached to a site with their SSL status and redirects.
+
+Examples:
+  wordsail domain list --server myserver --site mysite
+  wordsail domain list --server myserver --site mysite --json
+
+  # Only domains whose stored SSL expiry has passed, re-checked on the server
+  wordsail domain list --server myserver --site mysite --stale-ssl --refresh`,
+	Run: func(cmd *cobra.Command, args []string) {
+		mgr, err := config.NewManager()
+		if err != nil {
+			fail(cmd, "Failed to create config manager", err)
+		}
+
+		if !mgr.ConfigExists() {
+			fail(cmd, "Configuration file not found", exit.Errorf(exit.ConfigNotFound, "run 'wordsail init' first"))
+		}
+
+		cfg, err := mgr.Load()
+		if err != nil {
+			fail(cmd, "Failed to load configuration", exit.New(exit.Validation, err))
+		}
+
+		serverName, _ := cmd.Flags().GetString("server")
+		siteID, _ := cmd.Flags().GetString("site")
+		if serverName == "" || siteID == "" {
+			fail(cmd, "Missing required flags", exit.Errorf(exit.Validation, "--server and --site are required"))
+		}
+
+		targetServer := utils.FindServerByName(cfg.Servers, serverName)
+		if targetServer == nil {
+			fail(cmd, "Server not found", exit.Errorf(exit.Validation, "server '%s' does not exist", serverName))
+		}
+		targetSite, err := utils.ResolveSite(targetServer, siteID)
+		if err != nil {
+			fail(cmd, "Site not found", exit.New(exit.Validation, err))
+		}
+		siteID = targetSite.SiteID
+
+		staleOnly, _ := cmd.Flags().GetBool("stale-ssl")
+		refresh, _ := cmd.Flags().GetBool("refresh")
+		if refresh && !staleOnly {
+			fail(cmd, "Invalid flags", exit.Errorf(exit.Validation, "--refresh requires --stale-ssl"))
+		}
+
+		domains := targetSite.Domains
+		if staleOnly {
+			domains = utils.StaleSSLDomains(targetSite.Domains, time.Now())
+			if refresh && len(domains) > 0 {
+				stale := make([]utils.StaleSSL, 0, len(domains))
+				for _, d := range domains {
+					stale = append(stale, utils.StaleSSL{Server: serverName, SiteID: siteID, Domain: d.Domain, ExpiresAt: *d.SSLExpiresAt})
+				}
+				if refreshStaleSSL(cmd, mgr, cfg, stale) > 0 {
+					if cfg, err = mgr.Load(); err != nil {
+						fail(cmd, "Failed to load configuration", exit.New(exit.Validation, err))
+					}
+					targetSite = utils.FindSiteBySiteID(utils.FindServerByName(cfg.Servers, serverName), siteID)
+					domains = utils.StaleSSLDomains(targetSite.Domains, time.Now())
+				}
+			}
+		}
+
+		if isJSONOutput(cmd) {
+			output, err := json.MarshalIndent(domains, "", "  ")
+			if err != nil {
+				fail(cmd, "Failed to marshal JSON", err)
+			}
+			fmt.Println(string(output))
+			return
+		}
+
+		headers := []string{"DOMAIN", "PRIMARY", "SSL", "SSL EXPIRES", "REDIRECTS TO"}
+		colWidths := []int{35, 8, 5, 12, 35}
+		rows := make([][]string, 0, len(domains))
+		for _, d := range domains {
+			primary := ""
+			if d.Domain == targetSite.PrimaryDomain {
+				primary = "yes"
+			}
+			ssl := "no"
+			expires := "-"
+			if d.SSLEnabled {
+				ssl = "yes"
+			}
+			if d.SSLExpiresAt != nil {
+				expires = d.SSLExpiresAt.Format("2006-01-02")
+			}
+			redirect := "-"
+			if d.RedirectTo != "" {
+				redirect = d.RedirectTo
+			}
+			rows = append(rows, []string{d.Domain, primary, ssl, expires, redirect})
+		}
+
+		if isCSVOutput() {
+			if err := utils.PrintCSV(headers, rows); err != nil {
+				fail(cmd, "Failed to write CSV", err)
+			}
+			return
+		}
+
+		if staleOnly && len(domains) == 0 {
+			fmt.Printf("No domains on site '%s' have a stale SSL expiry\n", siteID)
+			return
+		}
+		if len(domains) == 0 {
+			fmt.Printf("No domains on site '%s'\n", siteID)
+			return
+		}
+
+		fmt.Printf("\nDomains of site '%s' on '%s':\n\n", siteID, serverName)
+
+		utils.PrintTableWithBorders(headers, rows, colWidths)
 		fmt.Println()
-		color.Green("═══════════════════════════════════════════════════════")
-		color.Green("  ✓ SSL certificate issued successfully!")
-		color.Green("═══════════════════════════════════════════════════════")
-		fmt.Println()
-		fmt.Printf("Domain:      https://%s\n", input.Domain)
-		fmt.Printf("Issued:      %s\n", now.Format("2006-01-02"))
-		fmt.Printf("Expires:     %s\n", expiresAt.Format("2006-01-02"))
-		fmt.Printf("Auto-renew:  Certbot will auto-renew before expiration\n")
+		if staleOnly {
+			printStaleSSLHint(refresh)
+		}
 	},
 }
 
+// printStaleSSLHint explains what a stale SSL expiry means
+func printStaleSSLHint(refreshed bool) {
+	if refreshed {
+		fmt.Println("These still show a past expiry after checking the server; reissue them with: wordsail domain ssl")
+	} else {
+		fmt.Println("A past expiry usually means certbot renewed the certificate without the config being updated.")
+		fmt.Println("Re-run with --refresh to read the live expiry from the server.")
+	}
+	fmt.Println()
+}
+
+// refreshStaleSSL reads the live certificate expiry of each stale domain
+// from its server and stores it when the certificate has been renewed.
+// Domains that cannot be read are reported and left unchanged. Returns the
+// number of domains updated.
+func refreshStaleSSL(cmd *cobra.Command, mgr *config.Manager, cfg *config.Config, stale []utils.StaleSSL) int {
+	stateMgr := state.NewManager(mgr)
+	updated := 0
+	for _, entry := range stale {
+		server := utils.FindServerByName(cfg.Servers, entry.Server)
+		if server == nil {
+			continue
+		}
+		site := utils.FindSiteBySiteID(server, entry.SiteID)
+		if site == nil {
+			continue
+		}
+
+		expiresAt, err := utils.ReadCertExpiry(*server, entry.Domain)
+		if err != nil {
+			outputWarning(cmd, "%v", err)
+			continue
+		}
+		if !expiresAt.After(entry.ExpiresAt) {
+			continue
+		}
+
+		for _, d := range site.Domains {
+			if d.Domain != entry.Domain {
+				continue
+			}
+			d.SSLExpiresAt = expiresAt
+			if err := stateMgr.UpdateDomainSSL(entry.Server, entry.SiteID, entry.Domain, d); err != nil {
+				outputWarning(cmd, "Failed to update configuration: %v", err)
+				break
+			}
+			updated++
+			break
+		}
+	}
+	return updated
+}
+
 func init() {
 	rootCmd.AddCommand(domainCmd)
 	domainCmd.AddCommand(domainAddCmd)
 	domainCmd.AddCommand(domainRemoveCmd)
 	domainCmd.AddCommand(domainSSLCmd)
+	domainCmd.AddCommand(domainSetPrimaryCmd)
+	domainCmd.AddCommand(domainRedirectCmd)
+	domainCmd.AddCommand(domainListCmd)
 
 	// domain add flags (non-interactive mode)
 	domainAddCmd.Flags().String("server", "", "Server name")
-	domainAddCmd.Flags().String("site", "", "Site ID")
+	domainAddCmd.Flags().String("site", "", "Site ID or domain")
 	domainAddCmd.Flags().String("domain", "", "Domain to add")
 	domainAddCmd.Flags().Bool("ssl", false, "Issue SSL certificate for the domain")
+	domainAddCmd.Flags().Bool("aaaa-check", false, "Check that the domain's A and AAAA records point at the server before adding it")
 	domainAddCmd.Flags().Bool("json", false, "Output in JSON format")
 
 	// domain remove flags
 	domainRemoveCmd.Flags().String("server", "", "Server name")
-	domainRemoveCmd.Flags().String("site", "", "Site ID")
+	domainRemoveCmd.Flags().String("site", "", "Site ID or domain")
 	domainRemoveCmd.Flags().String("domain", "", "Domain to remove")
 	domainRemoveCmd.Flags().BoolP("force", "f", false, "Force removal without confirmation")
 	domainRemoveCmd.Flags().Bool("json", false, "Output in JSON format")
+	domainRemoveCmd.Flags().Bool("all", false, "Remove every non-primary domain from the site")
+	domainRemoveCmd.Flags().Bool("include-primary", false, "With --all, also remove the primary domain")
 
 	// domain ssl flags (non-interactive mode)
 	domainSSLCmd.Flags().String("server", "", "Server name")
-	domainSSLCmd.Flags().String("site", "", "Site ID")
+	domainSSLCmd.Flags().String("site", "", "Site ID or domain")
 	domainSSLCmd.Flags().String("domain", "", "Domain to issue SSL for")
 	domainSSLCmd.Flags().String("email", "", "Email for Let's Encrypt notifications")
+	domainSSLCmd.Flags().Bool("staging", false, "Test issuance against the Let's Encrypt staging environment without installing a certificate")
+	domainSSLCmd.Flags().BoolP("force", "f", false, "Request a certificate even if the tracked rate limit is reached")
+	domainSSLCmd.Flags().Bool("dns-wait", false, "Wait until the domain resolves to the server before requesting the certificate")
+	domainSSLCmd.Flags().Duration("dns-wait-timeout", 10*time.Minute, "How long --dns-wait polls before giving up")
 	domainSSLCmd.Flags().Bool("json", false, "Output in JSON format")
+
+	// domain set-primary flags
+	domainSetPrimaryCmd.Flags().String("server", "", "Server name")
+	domainSetPrimaryCmd.Flags().String("site", "", "Site ID or domain")
+	domainSetPrimaryCmd.Flags().String("domain", "", "Attached domain to make primary")
+	domainSetPrimaryCmd.Flags().BoolP("force", "f", false, "Skip confirmation prompt")
+	domainSetPrimaryCmd.Flags().Bool("json", false, "Output in JSON format")
+
+	// domain redirect flags
+	domainRedirectCmd.Flags().String("server", "", "Server name")
+	domainRedirectCmd.Flags().String("site", "", "Site ID or domain")
+	domainRedirectCmd.Flags().String("from", "", "Domain to redirect")
+	domainRedirectCmd.Flags().String("to", "", "Domain to redirect to (must belong to the same site)")
+	domainRedirectCmd.Flags().Bool("remove", false, "Remove the redirect from --from")
+	domainRedirectCmd.Flags().Bool("json", false, "Output in JSON format")
+
+	// Ansible variable overrides for the playbook-running domain commands
+	for _, c := range []*cobra.Command{domainAddCmd, domainRemoveCmd, domainSSLCmd, domainSetPrimaryCmd, domainRedirectCmd} {
+		addExtraVarFlags(c)
+	}
+
+	// domain list flags
+	domainListCmd.Flags().String("server", "", "Server name")
+	domainListCmd.Flags().String("site", "", "Site ID or domain")
+	domainListCmd.Flags().Bool("json", false, "Output in JSON format")
+	domainListCmd.Flags().Bool("stale-ssl", false, "Only show domains whose stored SSL expiry is in the past")
+	domainListCmd.Flags().Bool("refresh", false, "With --stale-ssl, read the live certificate expiry from the server and update the config")
 }
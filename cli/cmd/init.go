@@ -3,12 +3,15 @@ package cmd
 import (
 	"fmt"
 	"os"
+	"os/exec"
 
 	"github.com/fatih/color"
 	"github.com/spf13/cobra"
 	"github.com/wordsail/cli/internal/config"
+	"github.com/wordsail/cli/internal/exit"
 	"github.com/wordsail/cli/internal/installer"
 	"github.com/wordsail/cli/internal/prompt"
+	"github.com/wordsail/cli/internal/utils"
 )
 
 // initCmd represents the init command
@@ -21,7 +24,8 @@ This command will:
   1. Create ~/.wordsail/ directory structure
   2. Copy Ansible playbooks from the repository to ~/.wordsail/ansible/
   3. Create initial configuration file (wordsail.yaml)
-  4. Prompt for global settings (SSH key, certbot email)
+  4. Prompt for global settings (SSH key, certbot email), optionally
+     generating a dedicated ed25519 keypair
   5. Validate the installation
 
 Note: MySQL admin passwords are generated automatically per-server during provisioning.
@@ -35,15 +39,18 @@ Examples:
   # Non-interactive mode
   wordsail init --ssh-public-key ~/.ssh/id_rsa.pub --certbot-email admin@example.com
 
+  # Generate a dedicated ed25519 key (~/.ssh/wordsail_ed25519)
+  wordsail init --generate-key --certbot-email admin@example.com
+
   # Force overwrite existing configuration
-  wordsail init --force`,
+  wordsail init --force
+
+  # Refresh ~/.wordsail/ansible after upgrading the CLI (keeps the configuration)
+  wordsail init --reinstall-ansible`,
 	Run: func(cmd *cobra.Command, args []string) {
 		force, _ := cmd.Flags().GetBool("force")
 
-		color.Cyan("═══════════════════════════════════════════════════════")
-		color.Cyan("  WordSail Initialization")
-		color.Cyan("═══════════════════════════════════════════════════════")
-		fmt.Println()
+		outputBanner(cmd, color.Cyan, "WordSail Initialization")
 
 		mgr, err := config.NewManager()
 		if err != nil {
@@ -51,6 +58,14 @@ Examples:
 			os.Exit(1)
 		}
 
+		reinstallAnsible, _ := cmd.Flags().GetBool("reinstall-ansible")
+
+		// Refreshing the playbooks leaves an existing configuration alone
+		if reinstallAnsible && mgr.ConfigExists() && !force {
+			reinstallPlaybooks()
+			return
+		}
+
 		// Check if config already exists
 		if mgr.ConfigExists() && !force {
 			color.Yellow("Configuration file already exists at: %s", mgr.GetConfigPath())
@@ -58,6 +73,7 @@ Examples:
 			fmt.Println("Options:")
 			fmt.Printf("  • Edit the config:      %s %s\n", getEditor(), mgr.GetConfigPath())
 			fmt.Println("  • Overwrite config:     wordsail init --force")
+			fmt.Println("  • Refresh playbooks:    wordsail init --reinstall-ansible")
 			fmt.Println()
 			fmt.Println("Use --force to overwrite the existing configuration.")
 			os.Exit(1)
@@ -66,17 +82,21 @@ Examples:
 		// Check if ansible is already initialized
 		ansibleInitialized := installer.IsInitialized()
 
-		if !ansibleInitialized {
+		switch {
+		case !ansibleInitialized:
 			// Initialize ansible directory
 			fmt.Print("→ Copying Ansible playbooks... ")
-			if err := installer.Initialize(); err != nil {
+			if err := installer.Initialize(Version); err != nil {
 				color.Red("✗")
 				color.Red("\nError: %v", err)
 				os.Exit(1)
 			}
 			color.Green("✓")
-		} else {
+		case reinstallAnsible:
+			reinstallPlaybooks()
+		default:
 			fmt.Println("→ Ansible playbooks already installed ✓")
+			warnOutdatedPlaybooks()
 		}
 
 		// Get one-time setup values
@@ -85,18 +105,27 @@ Examples:
 		// Check for non-interactive mode
 		sshKey, _ := cmd.Flags().GetString("ssh-public-key")
 		certbotEmail, _ := cmd.Flags().GetString("certbot-email")
+		generateKey, _ := cmd.Flags().GetBool("generate-key")
+		interactive := false
+
+		if sshKey != "" && generateKey {
+			color.Red("Error: --ssh-public-key and --generate-key cannot be used together")
+			os.Exit(exit.Validation)
+		}
 
-		if sshKey != "" && certbotEmail != "" {
+		if (sshKey != "" || generateKey) && certbotEmail != "" {
 			// Non-interactive mode
 			initInput = &prompt.InitInput{
 				SSHPublicKey: sshKey,
 				CertbotEmail: certbotEmail,
+				GenerateKey:  generateKey,
 			}
-		} else if sshKey != "" || certbotEmail != "" {
+		} else if sshKey != "" || generateKey || certbotEmail != "" {
 			// Partial flags provided
-			color.Red("Error: All flags required for non-interactive mode: --ssh-public-key, --certbot-email")
-			os.Exit(1)
+			color.Red("Error: All flags required for non-interactive mode: --certbot-email and one of --ssh-public-key, --generate-key")
+			os.Exit(exit.Validation)
 		} else {
+			interactive = true
 			// Interactive mode - prompt for setup values
 			initInput, err = prompt.PromptInitSetup()
 			if err != nil {
@@ -105,6 +134,16 @@ Examples:
 			}
 		}
 
+		// Generate a dedicated keypair if requested
+		var sshPrivateKey string
+		if initInput.GenerateKey {
+			initInput.SSHPublicKey, sshPrivateKey, err = generateInitSSHKey(interactive)
+			if err != nil {
+				color.Red("\nError: %v", err)
+				os.Exit(1)
+			}
+		}
+
 		// Create configuration with the provided values
 		fmt.Print("→ Creating configuration file... ")
 
@@ -114,6 +153,9 @@ Examples:
 		// Set global vars from user input
 		cfg.GlobalVars["wordsail_ssh_key"] = initInput.SSHPublicKey
 		cfg.GlobalVars["certbot_email"] = initInput.CertbotEmail
+		if sshPrivateKey != "" {
+			cfg.GlobalVars[config.SSHPrivateKeyVar] = sshPrivateKey
+		}
 
 		if err := mgr.Save(cfg); err != nil {
 			color.Red("✗")
@@ -132,17 +174,19 @@ Examples:
 		}
 
 		// Success message
-		fmt.Println()
-		color.Green("═══════════════════════════════════════════════════════")
-		color.Green("  ✓ WordSail initialized successfully!")
-		color.Green("═══════════════════════════════════════════════════════")
-		fmt.Println()
+		if Quiet {
+			color.Green("✓ WordSail initialized successfully!")
+		}
+		outputBanner(cmd, color.Green, "✓ WordSail initialized successfully!")
 		fmt.Println("Installation paths:")
 		fmt.Printf("  • Ansible:       %s\n", installer.GetAnsibleDir())
 		fmt.Printf("  • Config:        %s\n", mgr.GetConfigPath())
 		fmt.Println()
 		fmt.Println("Configuration saved:")
 		fmt.Printf("  • SSH Public Key:    %s\n", initInput.SSHPublicKey)
+		if sshPrivateKey != "" {
+			fmt.Printf("  • SSH Private Key:   %s\n", sshPrivateKey)
+		}
 		fmt.Printf("  • Certbot Email:     %s\n", initInput.CertbotEmail)
 		fmt.Println()
 		fmt.Println("To edit your configuration later:")
@@ -156,6 +200,77 @@ Examples:
 	},
 }
 
+// generateInitSSHKey creates ~/.ssh/wordsail_ed25519 and returns the public and
+// private key paths. An existing key is only replaced after confirmation;
+// otherwise it is reused.
+func generateInitSSHKey(interactive bool) (string, string, error) {
+	keyPath, err := utils.DefaultSSHKeyPath()
+	if err != nil {
+		return "", "", err
+	}
+	pubPath := keyPath + ".pub"
+
+	overwrite := false
+	if utils.SSHKeyExists(keyPath) {
+		if interactive {
+			overwrite, err = prompt.PromptOverwriteSSHKey(keyPath)
+			if err != nil {
+				return "", "", err
+			}
+		}
+
+		if !overwrite {
+			if _, err := os.Stat(keyPath); err != nil {
+				return "", "", fmt.Errorf("incomplete SSH keypair at %s: remove %s or use --ssh-public-key", keyPath, pubPath)
+			}
+			if _, err := os.Stat(pubPath); err != nil {
+				return "", "", fmt.Errorf("incomplete SSH keypair at %s: remove it or use --ssh-public-key", keyPath)
+			}
+			fmt.Printf("→ Using existing SSH key %s ✓\n", keyPath)
+			return pubPath, keyPath, nil
+		}
+	}
+
+	fmt.Print("→ Generating ed25519 SSH key... ")
+	pubPath, err = utils.GenerateSSHKeyPair(keyPath, "wordsail", overwrite)
+	if err != nil {
+		color.Red("✗")
+		return "", "", err
+	}
+	color.Green("✓")
+
+	return pubPath, keyPath, nil
+}
+
+// reinstallPlaybooks replaces ~/.wordsail/ansible with a fresh copy, keeping
+// the old one alongside it
+func reinstallPlaybooks() {
+	fmt.Print("→ Reinstalling Ansible playbooks... ")
+	backup, err := installer.Reinstall(Version)
+	if err != nil {
+		color.Red("✗")
+		color.Red("\nError: %v", err)
+		os.Exit(1)
+	}
+	color.Green("✓")
+	if backup != "" {
+		fmt.Printf("  Previous playbooks moved to %s\n", backup)
+	}
+}
+
+// warnOutdatedPlaybooks warns when the installed playbooks came from an
+// older CLI than the one running
+func warnOutdatedPlaybooks() {
+	installed := installer.InstalledVersion()
+	if !installer.PlaybooksOutdated(installed, Version) {
+		return
+	}
+	if installed == "" {
+		installed = "an unknown version"
+	}
+	color.Yellow("⚠ Installed playbooks are from %s, older than this CLI (%s). Run 'wordsail init --reinstall-ansible' to refresh them.", installed, Version)
+}
+
 func validateInstallation() error {
 	// Check if ansible directory has required files
 	ansiblePath := installer.GetAnsibleDir()
@@ -175,7 +290,15 @@ func validateInstallation() error {
 		}
 	}
 
-	return nil
+	if _, err := exec.LookPath("ansible-playbook"); err != nil {
+		return fmt.Errorf("ansible-playbook not found in PATH. Please install Ansible")
+	}
+
+	version, err := config.AnsibleVersion()
+	if err != nil {
+		return err
+	}
+	return config.CheckAnsibleVersion(version)
 }
 
 // getEditor returns the user's preferred editor
@@ -196,4 +319,6 @@ func init() {
 	initCmd.Flags().BoolP("force", "f", false, "Force overwrite existing configuration")
 	initCmd.Flags().String("ssh-public-key", "", "Path to SSH public key for wordsail user")
 	initCmd.Flags().String("certbot-email", "", "Email for Let's Encrypt SSL certificates")
+	initCmd.Flags().Bool("generate-key", false, "Generate an ed25519 keypair at ~/.ssh/wordsail_ed25519")
+	initCmd.Flags().Bool("reinstall-ansible", false, "Back up ~/.wordsail/ansible and copy the playbooks again")
 }
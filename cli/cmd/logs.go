@@ -0,0 +1,89 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/spf13/cobra"
+	"github.com/wordsail/cli/internal/config"
+	"github.com/wordsail/cli/internal/exit"
+	"github.com/wordsail/cli/internal/utils"
+)
+
+// logsCmd represents the logs command
+var logsCmd = &cobra.Command{
+	Use:   "logs",
+	Short: "Show a site's Nginx or PHP logs",
+	Long: `Print the last lines of a site's log over SSH, or keep printing new lines
+with --follow until Ctrl-C.
+
+Log types:
+  error   Nginx error log of the domain (default)
+  access  Nginx access log of the domain
+  php     PHP errors of the site (debug.log)
+
+Nginx logs are kept per domain; --domain picks one of the site's domains
+instead of the primary one.
+
+Examples:
+  wordsail logs --server production-1 --site mysite
+  wordsail logs --server production-1 --site mysite --type access --lines 200
+  wordsail logs --server production-1 --site mysite --type php --follow
+  wordsail logs --server production-1 --site mysite --domain www.example.com -f`,
+	Run: func(cmd *cobra.Command, args []string) {
+		mgr, err := config.NewManager()
+		if err != nil {
+			fail(cmd, "Failed to create config manager", err)
+		}
+
+		if !mgr.ConfigExists() {
+			fail(cmd, "Configuration file not found", exit.Errorf(exit.ConfigNotFound, "run 'wordsail init' first"))
+		}
+
+		cfg, err := mgr.Load()
+		if err != nil {
+			fail(cmd, "Failed to load configuration", exit.New(exit.Validation, err))
+		}
+
+		server, site := requireServerSite(cmd, cfg)
+
+		logType, _ := cmd.Flags().GetString("type")
+		lines, _ := cmd.Flags().GetInt("lines")
+		if lines < 1 {
+			fail(cmd, "Invalid --lines", exit.Errorf(exit.Validation, "--lines must be at least 1"))
+		}
+		follow, _ := cmd.Flags().GetBool("follow")
+		domain, _ := cmd.Flags().GetString("domain")
+
+		path, err := utils.SiteLogPath(*site, logType, domain)
+		if err != nil {
+			fail(cmd, "Invalid flags", exit.New(exit.Validation, err))
+		}
+
+		ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+		defer stop()
+
+		if follow {
+			fmt.Fprintf(os.Stderr, "==> %s on %s (Ctrl-C to stop)\n", path, server.Name)
+		}
+		err = utils.StreamSSHCommandContext(ctx, *server, utils.TailLogCommand(path, lines, follow), nil, os.Stdout)
+		if err != nil && !errors.Is(err, context.Canceled) {
+			fail(cmd, fmt.Sprintf("Failed to read %s", path), err)
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(logsCmd)
+
+	logsCmd.Flags().String("server", "", "Server name")
+	logsCmd.Flags().String("site", "", "Site ID or domain")
+	logsCmd.Flags().String("type", utils.LogError, "Log to show: error, access or php")
+	logsCmd.Flags().String("domain", "", "Domain whose Nginx log to show (default the primary domain)")
+	logsCmd.Flags().IntP("lines", "n", 100, "Number of lines to print")
+	logsCmd.Flags().BoolP("follow", "f", false, "Keep printing new lines until interrupted")
+}
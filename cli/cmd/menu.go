@@ -0,0 +1,75 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+	"github.com/wordsail/cli/internal/config"
+	"github.com/wordsail/cli/internal/prompt"
+)
+
+// menuItem is an entry of the menu shown by a bare `wordsail`; args name the
+// command it runs
+type menuItem struct {
+	label string
+	args  []string
+}
+
+// menuItems are the main actions offered by the menu. Each command runs
+// its interactive flow, as if typed without flags.
+var menuItems = []menuItem{
+	{"Add a server", []string{"server", "add"}},
+	{"Provision a server", []string{"server", "provision"}},
+	{"Create a site", []string{"site", "create"}},
+	{"Add a domain to a site", []string{"domain", "add"}},
+	{"Issue an SSL certificate", []string{"domain", "ssl"}},
+	{"List servers", []string{"server", "list"}},
+	{"List sites", []string{"site", "list"}},
+	{"Show fleet status", []string{"status"}},
+}
+
+// runMenu shows the main menu and runs the chosen command. Without a
+// terminal (piped, scripted or JSON output) it prints the help instead.
+func runMenu(cmd *cobra.Command, args []string) {
+	if !prompt.Interactive() || jsonEventsEnabled() {
+		_ = cmd.Help()
+		return
+	}
+
+	// Until wordsail is set up every other action fails, so offer init first
+	items := menuItems
+	if mgr, err := config.NewManager(); err == nil && !mgr.ConfigExists() {
+		items = append([]menuItem{{"Set up WordSail (first run)", []string{"init"}}}, items...)
+	}
+
+	labels := make([]string, 0, len(items)+2)
+	for _, item := range items {
+		labels = append(labels, item.label)
+	}
+	labels = append(labels, "Show help", "Quit")
+
+	selected, err := prompt.PromptMainMenu(labels)
+	if err != nil {
+		os.Exit(1)
+	}
+	switch {
+	case selected == len(items):
+		_ = cmd.Help()
+		return
+	case selected > len(items):
+		return
+	}
+
+	item := items[selected]
+	target, _, err := cmd.Root().Find(item.args)
+	if err != nil {
+		fail(cmd, "Unknown menu command", err)
+	}
+	fmt.Println()
+	target.Run(target, nil)
+}
+
+func init() {
+	rootCmd.Run = runMenu
+}
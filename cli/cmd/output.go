@@ -3,24 +3,78 @@ package cmd
 import (
 	"encoding/json"
 	"fmt"
+	"os"
 
 	"github.com/fatih/color"
 	"github.com/spf13/cobra"
+	"github.com/wordsail/cli/internal/exit"
+	"github.com/wordsail/cli/internal/prompt"
+	"github.com/wordsail/cli/pkg/models"
+	"gopkg.in/yaml.v3"
 )
 
 // CommandResult represents a JSON response for command execution
 type CommandResult struct {
+	Event   string                 `json:"event,omitempty"`
 	Success bool                   `json:"success"`
 	Action  string                 `json:"action,omitempty"`
 	Message string                 `json:"message,omitempty"`
 	Error   string                 `json:"error,omitempty"`
 	Data    map[string]interface{} `json:"data,omitempty"`
+
+	// ExitCode is set on errors; see internal/exit
+	ExitCode int `json:"exit_code,omitempty"`
 }
 
 // isJSONOutput checks if the command should output JSON
 func isJSONOutput(cmd *cobra.Command) bool {
 	jsonFlag, _ := cmd.Flags().GetBool("json")
-	return jsonFlag
+	return jsonFlag || jsonEventsEnabled()
+}
+
+// printObject prints the object shown by a show/info command as YAML with
+// --output yaml, or as indented JSON otherwise
+func printObject(cmd *cobra.Command, v interface{}) {
+	if isYAMLOutput() {
+		output, err := yaml.Marshal(v)
+		if err != nil {
+			fail(cmd, "Failed to marshal YAML", err)
+		}
+		fmt.Print(string(output))
+		return
+	}
+	output, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		fail(cmd, "Failed to marshal JSON", err)
+	}
+	fmt.Println(string(output))
+}
+
+// withoutSecrets returns a copy of a site without its encrypted database
+// password, which JSON output already leaves out
+func withoutSecrets(site models.Site) models.Site {
+	site.Database.EncryptedPassword = ""
+	return site
+}
+
+// assumeYes reports whether confirmations are skipped: the global --yes or
+// the command's own --force, which is kept as an alias
+func assumeYes(cmd *cobra.Command) bool {
+	force, _ := cmd.Flags().GetBool("force")
+	return force || prompt.AssumeYes
+}
+
+// printResult prints a command result as indented JSON, or as a single
+// "result" event line when streaming JSON events
+func printResult(result CommandResult) {
+	if jsonEventsEnabled() {
+		result.Event = "result"
+		output, _ := json.Marshal(result)
+		fmt.Println(string(output))
+		return
+	}
+	output, _ := json.MarshalIndent(result, "", "  ")
+	fmt.Println(string(output))
 }
 
 // outputSuccess outputs a success message, either as JSON or human-readable
@@ -31,31 +85,146 @@ func outputSuccess(cmd *cobra.Command, action string, data map[string]interface{
 			Action:  action,
 			Data:    data,
 		}
-		output, _ := json.MarshalIndent(result, "", "  ")
-		fmt.Println(string(output))
+		printResult(result)
 	} else {
 		// Human-readable output
 		switch action {
 		case "server_added":
 			color.Green("✓ Server '%s' added successfully", data["name"])
 		case "server_provisioned":
-			color.Green("✓ Server '%s' provisioned successfully", data["name"])
+			color.Green("✓ Server '%s' provisioned successfully (%v changed)", data["name"], data["changed"])
+		case "servers_provisioned":
+			color.Green("✓ %d servers provisioned successfully", data["count"])
 		case "server_removed":
 			color.Green("✓ Server '%s' removed from inventory", data["name"])
 		case "server_updated":
 			color.Green("✓ Server '%s' updated successfully", data["name"])
+		case "inventory_exported":
+			color.Green("✓ Wrote %s inventory for %d server(s) to %s", data["format"], data["servers"], data["path"])
+		case "server_renamed":
+			color.Green("✓ Server '%s' renamed to '%s'", data["old_name"], data["new_name"])
+		case "server_rebooted":
+			color.Green("✓ Server '%s' rebooted (downtime %ds)", data["name"], data["downtime_seconds"])
+		case "servers_upgraded":
+			color.Green("✓ %d package(s) upgraded", data["total_upgraded"])
+		case "server_adopted":
+			color.Green("✓ %d site(s) adopted into server '%s'", data["count"], data["name"])
 		case "server_healthy":
 			color.Green("✓ Server '%s' is healthy", data["name"])
 		case "site_created":
 			color.Green("✓ WordPress site created successfully")
+		case "site_password_reset":
+			color.Green("✓ Password reset for '%s' on %s", data["user"], data["domain"])
+		case "site_db_password_rotated":
+			color.Green("✓ Database password rotated for %s (stored encrypted in the config)", data["domain"])
+		case "site_db_exported":
+			color.Green("✓ Database '%s' exported to %s (%s)", data["database"], data["path"], data["size"])
+		case "site_db_imported":
+			color.Green("✓ %s imported into database '%s' of %s", data["path"], data["database"], data["domain"])
+		case "site_migrated":
+			color.Green("✓ Site '%s' migrated from %s to %s", data["domain"], data["from_server"], data["to_server"])
+		case "site_template_saved":
+			color.Green("✓ Site template '%s' saved to %s", data["name"], data["path"])
 		case "site_deleted":
 			color.Green("✓ Site '%s' deleted successfully", data["domain"])
+		case "site_maintenance":
+			if data["maintenance"] == true {
+				color.Green("✓ Maintenance mode enabled for %s", data["domain"])
+			} else {
+				color.Green("✓ Maintenance mode disabled for %s", data["domain"])
+			}
+		case "site_enabled":
+			color.Green("✓ Site '%s' enabled; %s is served again", data["site_id"], data["domain"])
+		case "site_disabled":
+			color.Green("✓ Site '%s' disabled; run 'wordsail site enable' to serve it again", data["site_id"])
+		case "site_verified":
+			color.Green("✓ All %d domain(s) of site '%s' verified", data["count"], data["site_id"])
+		case "site_php_extension":
+			if data["enabled"] == true {
+				color.Green("✓ PHP extension %s enabled for site '%s'", data["extension"], data["site_id"])
+			} else {
+				color.Green("✓ PHP extension %s disabled for site '%s'", data["extension"], data["site_id"])
+			}
+		case "wp_updated":
+			if data["dry_run"] == true {
+				color.Green("✓ Update check complete")
+			} else {
+				color.Green("✓ WordPress updated on server '%s'", data["server"])
+			}
 		case "domain_added":
-			color.Green("✓ Domain '%s' added successfully", data["domain"])
+			color.Green("✓ Domain '%s' added successfully (%v changed)", data["domain"], data["changed"])
 		case "domain_removed":
-			color.Green("✓ Domain '%s' removed successfully", data["domain"])
+			color.Green("✓ Domain '%s' removed successfully (%v changed)", data["domain"], data["changed"])
+		case "domains_removed":
+			color.Green("✓ Removed %v domain(s) from site '%s'", data["count"], data["site_id"])
+		case "domain_redirected":
+			if data["to"] == "" {
+				color.Green("✓ Redirect removed from %s", data["from"])
+			} else {
+				color.Green("✓ %s now redirects to %s", data["from"], data["to"])
+			}
+		case "primary_domain_set":
+			color.Green("✓ %s is now the primary domain (was %s)", data["domain"], data["old_domain"])
+		case "ssl_staging_ok":
+			color.Green("✓ Staging issuance for %s succeeded; run again without --staging to install a certificate", data["domain"])
 		case "ssl_issued":
-			color.Green("✓ SSL certificate issued successfully")
+			color.Green("✓ SSL certificate issued successfully for %s", data["domain"])
+		case "ssl_bulk_issued":
+			switch {
+			case data["count"] == 0:
+				color.Green("✓ Every domain already has a certificate")
+			case data["dry_run"] == true:
+				color.Green("✓ %d certificate(s) would be issued", data["count"])
+			default:
+				color.Green("✓ %d certificate(s) issued", data["count"])
+			}
+		case "ssl_reconciled":
+			switch {
+			case data["count"] == 0:
+				color.Green("✓ Every stored certificate expiry matches the servers")
+			case data["dry_run"] == true:
+				color.Yellow("Dry run: %d expiry date(s) would be updated", data["count"])
+			default:
+				color.Green("✓ Updated %d stored certificate expiry date(s)", data["count"])
+			}
+		case "state_gc":
+			freed, _ := data["freed_bytes"].(int64)
+			switch {
+			case data["count"] == 0:
+				color.Green("✓ Nothing to clean up")
+			case data["dry_run"] == true:
+				color.Yellow("Dry run: %v file(s) would be removed, freeing %s", data["count"], formatBytes(freed))
+			default:
+				color.Green("✓ Removed %v file(s), freed %s", data["count"], formatBytes(freed))
+			}
+		case "config_current":
+			color.Green("✓ Configuration is already at version %s", data["version"])
+		case "config_migrated":
+			color.Green("✓ Configuration migrated from %s to %s (original kept at %s)", data["from"], data["to"], data["backup"])
+		case "profile_created":
+			color.Green("✓ Profile '%s' created at %s", data["profile"], data["path"])
+			if data["active"] == true {
+				color.Green("✓ Now using profile '%s'", data["profile"])
+			}
+		case "profile_selected":
+			color.Green("✓ Now using profile '%s'", data["profile"])
+		case "config_reconciled":
+			switch {
+			case data["applied"] == 0 && data["failed"] == 0:
+				color.Green("✓ No pending changes")
+			case data["failed"] == 0:
+				color.Green("✓ Applied %v pending change(s)", data["applied"])
+			default:
+				color.Yellow("Applied %v pending change(s); %v still pending", data["applied"], data["failed"])
+			}
+		case "config_restored":
+			color.Green("✓ Configuration restored from %s", data["backup"])
+		case "config_set":
+			color.Green("✓ %s = %v", data["path"], data["value"])
+		case "config_backed_up":
+			color.Green("✓ Configuration backed up to %s", data["target"])
+		case "config_backup_checked":
+			color.Green("✓ Backup target %s is reachable", data["target"])
 		default:
 			color.Green("✓ Operation completed successfully")
 		}
@@ -66,20 +235,53 @@ func outputSuccess(cmd *cobra.Command, action string, data map[string]interface{
 func outputError(cmd *cobra.Command, message string, err error) {
 	if isJSONOutput(cmd) {
 		result := CommandResult{
-			Success: false,
-			Message: message,
-			Error:   err.Error(),
+			Success:  false,
+			Message:  message,
+			Error:    err.Error(),
+			ExitCode: exit.CodeOf(err),
 		}
-		output, _ := json.MarshalIndent(result, "", "  ")
-		fmt.Println(string(output))
+		printResult(result)
 	} else {
 		color.Red("Error: %s: %v", message, err)
 	}
 }
 
+// fail outputs an error and exits with the code it carries (see internal/exit)
+func fail(cmd *cobra.Command, message string, err error) {
+	outputError(cmd, message, err)
+	os.Exit(exit.CodeOf(err))
+}
+
 // outputInfo outputs an informational message (only in non-JSON mode)
 func outputInfo(cmd *cobra.Command, format string, args ...interface{}) {
 	if !isJSONOutput(cmd) {
 		fmt.Printf(format, args...)
 	}
 }
+
+// outputWarning outputs a non-fatal warning. In JSON mode it goes to stderr so
+// stdout stays parseable.
+func outputWarning(cmd *cobra.Command, format string, args ...interface{}) {
+	if isJSONOutput(cmd) {
+		fmt.Fprintf(os.Stderr, "Warning: "+format+"\n", args...)
+	} else {
+		color.Red("Warning: "+format, args...)
+	}
+}
+
+// bannerRule is the separator line used by decorative banners
+const bannerRule = "═══════════════════════════════════════════════════════"
+
+// outputBanner prints a decorative banner (only in non-JSON, non-quiet mode)
+func outputBanner(cmd *cobra.Command, printFn func(format string, a ...interface{}), lines ...string) {
+	if isJSONOutput(cmd) || Quiet {
+		return
+	}
+	fmt.Println()
+	printFn("%s", bannerRule)
+	for _, line := range lines {
+		printFn("  %s", line)
+	}
+	printFn("%s", bannerRule)
+	fmt.Println()
+}
@@ -3,8 +3,15 @@ package cmd
 import (
 	"fmt"
 	"os"
+	"strings"
 
+	"github.com/fatih/color"
 	"github.com/spf13/cobra"
+	"github.com/wordsail/cli/internal/ansible"
+	"github.com/wordsail/cli/internal/config"
+	"github.com/wordsail/cli/internal/exit"
+	"github.com/wordsail/cli/internal/prompt"
+	"github.com/wordsail/cli/internal/utils"
 )
 
 var (
@@ -14,10 +21,43 @@ var (
 	BuildDate = "unknown"
 
 	// Global flags
-	Verbose bool
-	DryRun  bool
+	Verbose      bool
+	Quiet        bool
+	NoColor      bool
+	ForceColor   bool
+	NoPager      bool
+	ConfigFile   string
+	Profile      string
+	DryRun       bool
+	Diff         bool
+	OutputFormat string
+	AnsibleTags  string
+	SkipTags     string
+	ShowWarnings bool
+
+	// ExtraVarOverrides holds --extra-var and --extra-vars-file values for
+	// the commands that accept them
+	ExtraVarOverrides map[string]interface{}
 )
 
+// outputJSONStream is the --output value that enables NDJSON progress events
+const outputJSONStream = "json-stream"
+
+// outputCSV is the --output value that prints list commands as CSV
+const outputCSV = "csv"
+
+// csvAnnotation marks the commands that support --output csv
+const csvAnnotation = "wordsail/csv"
+
+// outputYAML is the --output value that prints a single object as YAML
+const outputYAML = "yaml"
+
+// yamlAnnotation marks the commands that support --output yaml
+const yamlAnnotation = "wordsail/yaml"
+
+// sshKeyPassphraseEnv unlocks encrypted SSH key files without a prompt
+const sshKeyPassphraseEnv = "WORDSAIL_SSH_KEY_PASSPHRASE"
+
 // rootCmd represents the base command
 var rootCmd = &cobra.Command{
 	Use:   "wordsail",
@@ -27,6 +67,9 @@ by wrapping Ansible playbooks with an intuitive, interactive interface.
 
 Manage servers, sites, and domains with ease while maintaining full
 visibility into your infrastructure state via ~/.wordsail/wordsail.yaml
+(or the file given with --config, or the profile chosen with --profile)
+
+Run without a command in a terminal to pick an action from a menu.
 
 Examples:
   # Initialize configuration
@@ -42,19 +85,158 @@ Examples:
   wordsail domain add
 
   # List all servers
-  wordsail server list --json`,
+  wordsail server list --json
+
+Exit codes:
+` + exitCodeHelp(),
+	PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+		// Disable color before anything is printed (https://no-color.org).
+		// Color is otherwise off when stdout is not a terminal, unless forced.
+		if NoColor && ForceColor {
+			return fmt.Errorf("--no-color and --force-color cannot be used together")
+		}
+		if ForceColor {
+			color.NoColor = false
+		} else if NoColor || os.Getenv("NO_COLOR") != "" {
+			color.NoColor = true
+		}
+		config.SetPathOverride(ConfigFile)
+		config.SetProfileOverride(Profile)
+		if Quiet && Verbose {
+			return fmt.Errorf("--quiet and --verbose cannot be used together")
+		}
+		if OutputFormat != "" && OutputFormat != outputJSONStream && OutputFormat != outputCSV && OutputFormat != outputYAML {
+			return fmt.Errorf("invalid --output value '%s' (supported: %s, %s, %s)", OutputFormat, outputJSONStream, outputCSV, outputYAML)
+		}
+		if isCSVOutput() {
+			if _, ok := cmd.Annotations[csvAnnotation]; !ok {
+				return fmt.Errorf("--output csv is only supported by list commands")
+			}
+			if jsonFlag, _ := cmd.Flags().GetBool("json"); jsonFlag {
+				return fmt.Errorf("--output csv cannot be combined with --json")
+			}
+		}
+		if isYAMLOutput() {
+			if _, ok := cmd.Annotations[yamlAnnotation]; !ok {
+				return fmt.Errorf("--output yaml is only supported by commands that show a single object")
+			}
+			if jsonFlag, _ := cmd.Flags().GetBool("json"); jsonFlag {
+				return fmt.Errorf("--output yaml cannot be combined with --json")
+			}
+		}
+		utils.PagerEnabled = !NoPager
+		recordConfigFingerprint()
+		if passphrase, ok := os.LookupEnv(sshKeyPassphraseEnv); ok {
+			// Scripts can unlock encrypted SSH keys without a prompt
+			utils.PassphrasePrompt = func(string) ([]byte, error) { return []byte(passphrase), nil }
+		} else if !isJSONOutput(cmd) {
+			// Encrypted SSH keys can be unlocked interactively
+			utils.PassphrasePrompt = prompt.SSHKeyPassphrase
+		}
+		if cmd.Flags().Changed("ansible-tags") {
+			if err := utils.ValidateAnsibleTags(AnsibleTags); err != nil {
+				return fmt.Errorf("invalid --ansible-tags: %w", err)
+			}
+		}
+		if cmd.Flags().Changed("ansible-skip-tags") {
+			if err := utils.ValidateAnsibleTags(SkipTags); err != nil {
+				return fmt.Errorf("invalid --ansible-skip-tags: %w", err)
+			}
+		}
+		if cmd.Flags().Lookup("extra-var") != nil {
+			pairs, _ := cmd.Flags().GetStringArray("extra-var")
+			file, _ := cmd.Flags().GetString("extra-vars-file")
+			vars, err := ansible.BuildExtraVars(file, pairs)
+			if err != nil {
+				return fmt.Errorf("invalid extra vars: %w", err)
+			}
+			ExtraVarOverrides = vars
+		}
+		return nil
+	},
+	PersistentPostRun: func(cmd *cobra.Command, args []string) {
+		autoBackupConfig(cmd)
+	},
 }
 
 // Execute adds all child commands to the root command and sets flags appropriately
 func Execute() {
 	if err := rootCmd.Execute(); err != nil {
+		// Commands exit on their own failures, so anything left is a usage
+		// error: an unknown command, a bad flag or an invalid global setting
 		fmt.Fprintln(os.Stderr, err)
-		os.Exit(1)
+		os.Exit(exit.Validation)
+	}
+}
+
+// addExtraVarFlags registers --extra-var and --extra-vars-file on a
+// playbook-running command
+func addExtraVarFlags(c *cobra.Command) {
+	c.Flags().StringArray("extra-var", nil, "Ansible variable as key=value, overriding config and global_vars (repeatable)")
+	c.Flags().String("extra-vars-file", "", "YAML or JSON file of Ansible variables, overriding config and global_vars")
+}
+
+// exitCodeHelp lists the exit codes for the help text
+func exitCodeHelp() string {
+	var b strings.Builder
+	for _, d := range exit.Descriptions {
+		fmt.Fprintf(&b, "  %d  %s\n", d.Code, d.Meaning)
+	}
+	return strings.TrimSuffix(b.String(), "\n")
+}
+
+// jsonEventsEnabled reports whether playbook progress should be emitted as
+// newline-delimited JSON events (--output json-stream or WORDSAIL_JSON_EVENTS=1)
+func jsonEventsEnabled() bool {
+	return OutputFormat == outputJSONStream || os.Getenv("WORDSAIL_JSON_EVENTS") == "1"
+}
+
+// isCSVOutput reports whether a list command should print CSV (--output csv)
+func isCSVOutput() bool {
+	return OutputFormat == outputCSV
+}
+
+// isYAMLOutput reports whether a show/info command should print YAML
+// (--output yaml)
+func isYAMLOutput() bool {
+	return OutputFormat == outputYAML
+}
+
+// newExecutor creates an Ansible executor configured from the global flags.
+// With --json the executor is quiet, so stdout holds only the JSON result.
+func newExecutor(cmd *cobra.Command, cfg *config.Config) *ansible.Executor {
+	executor := ansible.NewExecutor(cfg.Ansible.Path)
+	executor.SetVerbose(Verbose)
+	executor.SetSpinner(!Quiet)
+	executor.SetDryRun(DryRun)
+	executor.SetDiff(Diff)
+	executor.SetTags(AnsibleTags)
+	executor.SetSkipTags(SkipTags)
+	executor.SetShowWarnings(ShowWarnings)
+	executor.SetExtraVarOverrides(ExtraVarOverrides)
+	executor.SetPythonInterpreter(cfg.Ansible.PythonInterpreter)
+	executor.SetJSONEvents(jsonEventsEnabled())
+	if isJSONOutput(cmd) {
+		executor.SetQuiet(true)
 	}
+	return executor
 }
 
 func init() {
 	// Global flags
 	rootCmd.PersistentFlags().BoolVarP(&Verbose, "verbose", "v", false, "Enable verbose output")
+	rootCmd.PersistentFlags().BoolVarP(&prompt.AssumeYes, "yes", "y", false, "Answer yes to every confirmation prompt")
+	rootCmd.PersistentFlags().BoolVar(&prompt.AssumeYes, "assume-yes", false, "Alias for --yes")
+	rootCmd.PersistentFlags().BoolVarP(&Quiet, "quiet", "q", false, "Hide banners and playbook progress; print only results and errors")
+	rootCmd.PersistentFlags().BoolVar(&NoColor, "no-color", false, "Disable colored output (or set $NO_COLOR)")
+	rootCmd.PersistentFlags().BoolVar(&NoPager, "no-pager", false, "Print long tables directly instead of through $PAGER")
+	rootCmd.PersistentFlags().BoolVar(&ForceColor, "force-color", false, "Color output even when it is not a terminal, e.g. in CI logs")
+	rootCmd.PersistentFlags().StringVar(&ConfigFile, "config", "", "Config file to use (default ~/.wordsail/wordsail.yaml, or $WORDSAIL_CONFIG)")
+	rootCmd.PersistentFlags().StringVar(&Profile, "profile", "", "Config profile to use (or $WORDSAIL_PROFILE; see 'config profile')")
 	rootCmd.PersistentFlags().BoolVar(&DryRun, "dry-run", false, "Show what would be done without making changes")
+	rootCmd.PersistentFlags().BoolVar(&Diff, "diff", false, "Show the file changes made by playbook tasks (always on with --dry-run)")
+	rootCmd.PersistentFlags().StringVar(&AnsibleTags, "ansible-tags", "", "Only run playbook tasks with these comma-separated tags")
+	rootCmd.PersistentFlags().StringVar(&SkipTags, "ansible-skip-tags", "", "Skip playbook tasks with these comma-separated tags")
+	rootCmd.PersistentFlags().BoolVar(&ShowWarnings, "show-warnings", false, "Print the full text of Ansible warnings after a successful playbook run")
+	rootCmd.PersistentFlags().StringVar(&OutputFormat, "output", "", "Output format: json-stream (newline-delimited JSON progress events), csv (list commands) or yaml (show/info commands)")
 }
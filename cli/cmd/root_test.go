@@ -0,0 +1,154 @@
+package cmd
+
+import (
+	"encoding/json"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+	"github.com/wordsail/cli/internal/config"
+	"github.com/wordsail/cli/pkg/models"
+)
+
+// fakeAnsibleScript stands in for ansible-playbook: it prints a run with a
+// warning and a recap, failing the run when FAKE_ANSIBLE_FAIL is set
+const fakeAnsibleScript = `#!/bin/sh
+echo "PLAY [Test] ***"
+echo "TASK [nginx : Install nginx] ***"
+echo "[WARNING]: Module remote_tmp did not exist and was created"
+if [ -n "$FAKE_ANSIBLE_FAIL" ]; then
+  echo 'fatal: [203.0.113.10]: FAILED! => {"msg": "boom"}'
+  echo "PLAY RECAP ***"
+  echo "203.0.113.10 : ok=1 changed=0 unreachable=0 failed=1 skipped=0"
+  exit 2
+fi
+echo "ok: [203.0.113.10]"
+echo "PLAY RECAP ***"
+echo "203.0.113.10 : ok=2 changed=1 unreachable=0 failed=0 skipped=0"
+`
+
+// fakeAnsible puts fakeAnsibleScript on PATH as ansible-playbook and returns
+// a config whose Ansible directory holds test.yml
+func fakeAnsible(t *testing.T) *config.Config {
+	t.Helper()
+	bin := t.TempDir()
+	if err := os.WriteFile(filepath.Join(bin, "ansible-playbook"), []byte(fakeAnsibleScript), 0755); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("PATH", bin+string(os.PathListSeparator)+os.Getenv("PATH"))
+
+	ansibleDir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(ansibleDir, "test.yml"), []byte("- hosts: all\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return &config.Config{Ansible: config.AnsibleConfig{Path: ansibleDir}}
+}
+
+// captureOutput runs fn with stdout and stderr, colored output included,
+// redirected to pipes and returns what each received
+func captureOutput(t *testing.T, fn func()) (string, string) {
+	t.Helper()
+	read := func(target **os.File, colorTarget *io.Writer) func() string {
+		r, w, err := os.Pipe()
+		if err != nil {
+			t.Fatal(err)
+		}
+		original, originalColor := *target, *colorTarget
+		*target, *colorTarget = w, w
+		done := make(chan string)
+		go func() {
+			data, _ := io.ReadAll(r)
+			done <- string(data)
+		}()
+		return func() string {
+			w.Close()
+			*target, *colorTarget = original, originalColor
+			return <-done
+		}
+	}
+	stdout := read(&os.Stdout, &color.Output)
+	stderr := read(&os.Stderr, &color.Error)
+	fn()
+	return stdout(), stderr()
+}
+
+// jsonCommand returns a command with --json set
+func jsonCommand(t *testing.T) *cobra.Command {
+	t.Helper()
+	cmd := &cobra.Command{Use: "test"}
+	cmd.Flags().Bool("json", false, "")
+	if err := cmd.Flags().Set("json", "true"); err != nil {
+		t.Fatal(err)
+	}
+	return cmd
+}
+
+func TestNewExecutorJSONOutput(t *testing.T) {
+	server := models.Server{Name: "web1", Hostname: "203.0.113.10", IP: "203.0.113.10"}
+
+	for _, failing := range []bool{false, true} {
+		name := "success"
+		if failing {
+			name = "failure"
+			t.Setenv("FAKE_ANSIBLE_FAIL", "1")
+		}
+		t.Run(name, func(t *testing.T) {
+			cfg := fakeAnsible(t)
+			cmd := jsonCommand(t)
+
+			stdout, stderr := captureOutput(t, func() {
+				_, err := newExecutor(cmd, cfg).ExecutePlaybook("test.yml", server, nil, nil)
+				if err != nil {
+					outputError(cmd, "Playbook failed", err)
+					return
+				}
+				outputSuccess(cmd, "domain_added", map[string]interface{}{"domain": "example.com"})
+			})
+
+			if !json.Valid([]byte(stdout)) {
+				t.Fatalf("stdout is not JSON:\n%s", stdout)
+			}
+			var result CommandResult
+			if err := json.Unmarshal([]byte(stdout), &result); err != nil {
+				t.Fatal(err)
+			}
+			if result.Success == failing {
+				t.Errorf("result success = %v, want %v", result.Success, !failing)
+			}
+			if strings.Contains(stdout, "warning") || strings.Contains(stdout, "Completed") {
+				t.Errorf("stdout holds playbook output:\n%s", stdout)
+			}
+			if strings.Contains(stderr, "→") {
+				t.Errorf("progress lines printed in JSON mode:\n%s", stderr)
+			}
+		})
+	}
+}
+
+func TestPlaybookProgressOnStderr(t *testing.T) {
+	cfg := fakeAnsible(t)
+	cmd := &cobra.Command{Use: "test"}
+	cmd.Flags().Bool("json", false, "")
+	server := models.Server{Name: "web1", Hostname: "203.0.113.10", IP: "203.0.113.10"}
+
+	// Both pipes are not terminals, so progress is printed as task lines
+	stdout, stderr := captureOutput(t, func() {
+		if _, err := newExecutor(cmd, cfg).ExecutePlaybook("test.yml", server, nil, nil); err != nil {
+			t.Errorf("ExecutePlaybook() error = %v", err)
+		}
+	})
+
+	if !strings.Contains(stderr, "→ nginx : Install nginx\n") {
+		t.Errorf("stderr = %q, want the task lines", stderr)
+	}
+	if strings.Contains(stdout, "→") {
+		t.Errorf("stdout holds progress lines:\n%s", stdout)
+	}
+	if !strings.Contains(stdout, "Completed: 2 ok, 1 changed") {
+		t.Errorf("stdout = %q, want the run summary", stdout)
+	}
+}
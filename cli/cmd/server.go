@@ -1,15 +1,26 @@
 package cmd
 
 import (
+	"bytes"
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"os"
+	"os/exec"
+	"os/signal"
+	"path/filepath"
+	"sort"
+	"strings"
+	"syscall"
+	"time"
 
 	"github.com/AlecAivazis/survey/v2"
 	"github.com/fatih/color"
 	"github.com/spf13/cobra"
 	"github.com/wordsail/cli/internal/ansible"
 	"github.com/wordsail/cli/internal/config"
+	"github.com/wordsail/cli/internal/exit"
 	"github.com/wordsail/cli/internal/prompt"
 	"github.com/wordsail/cli/internal/state"
 	"github.com/wordsail/cli/internal/utils"
@@ -42,20 +53,17 @@ Examples:
 	Run: func(cmd *cobra.Command, args []string) {
 		mgr, err := config.NewManager()
 		if err != nil {
-			outputError(cmd, "Failed to create config manager", err)
-			os.Exit(1)
+			fail(cmd, "Failed to create config manager", err)
 		}
 
 		if !mgr.ConfigExists() {
-			outputError(cmd, "Configuration file not found", fmt.Errorf("run 'wordsail init' first"))
-			os.Exit(1)
+			fail(cmd, "Configuration file not found", exit.Errorf(exit.ConfigNotFound, "run 'wordsail init' first"))
 		}
 
 		// Load existing config
 		cfg, err := mgr.Load()
 		if err != nil {
-			outputError(cmd, "Failed to load configuration", err)
-			os.Exit(1)
+			fail(cmd, "Failed to load configuration", exit.New(exit.Validation, err))
 		}
 
 		var input *prompt.ServerInput
@@ -64,26 +72,40 @@ Examples:
 		name, _ := cmd.Flags().GetString("name")
 		ip, _ := cmd.Flags().GetString("ip")
 
+		ipv6, err := ipv6Flag(cmd)
+		if err != nil {
+			fail(cmd, "Invalid IPv6 address", exit.New(exit.Validation, err))
+		}
+
 		if name != "" && ip != "" {
 			// Non-interactive mode
+			if err := utils.ValidateHostnameOrIP(ip); err != nil {
+				fail(cmd, "Invalid --ip", exit.New(exit.Validation, err))
+			}
+
 			sshKey, _ := cmd.Flags().GetString("ssh-key")
 			sshUser, _ := cmd.Flags().GetString("ssh-user")
 			sshPort, _ := cmd.Flags().GetInt("ssh-port")
 
-			if sshKey == "" {
-				outputError(cmd, "Missing required flag", fmt.Errorf("--ssh-key is required in non-interactive mode"))
-				os.Exit(1)
+			useAgent, _ := cmd.Flags().GetBool("use-agent")
+			if sshKey == "" && !useAgent {
+				sshKey = config.SSHPrivateKeyPath(cfg)
+			}
+			if sshKey == "" && !useAgent {
+				fail(cmd, "Missing required flag", exit.Errorf(exit.Validation, "--ssh-key (or --use-agent) is required in non-interactive mode"))
 			}
 
 			input = &prompt.ServerInput{
 				Name:     name,
 				Hostname: ip,
-				IP:       ip,
 				SSHKey:   sshKey,
 				SSHUser:  sshUser,
 				SSHPort:  sshPort,
 			}
 
+			if utils.ValidateIP(ip) == nil {
+				input.IP = ip
+			}
 			if input.SSHUser == "" {
 				input.SSHUser = "root"
 			}
@@ -91,40 +113,51 @@ Examples:
 				input.SSHPort = 22
 			}
 		} else if name != "" || ip != "" {
-			outputError(cmd, "Incomplete flags", fmt.Errorf("both --name and --ip are required for non-interactive mode"))
-			os.Exit(1)
+			fail(cmd, "Incomplete flags", exit.Errorf(exit.Validation, "both --name and --ip are required for non-interactive mode"))
 		} else {
 			// Interactive mode - prompt for server details
-			input, err = prompt.PromptServerAdd()
+			input, err = prompt.PromptServerAdd(config.SSHPrivateKeyPath(cfg))
 			if err != nil {
-				outputError(cmd, "Failed to get server details", err)
-				os.Exit(1)
+				fail(cmd, "Failed to get server details", err)
 			}
 		}
 
 		// Check for duplicate server name
 		for _, server := range cfg.Servers {
 			if server.Name == input.Name {
-				outputError(cmd, "Server already exists", fmt.Errorf("server with name '%s' already exists", input.Name))
-				os.Exit(1)
+				fail(cmd, "Server already exists", exit.Errorf(exit.Validation, "server with name '%s' already exists", input.Name))
 			}
 		}
 
+		if ipv6 != "" {
+			input.IPv6 = ipv6
+		}
+
 		// Add server to config
 		newServer := input.ToServer()
+		if err := applySSHFlags(cmd, &newServer.SSH); err != nil {
+			fail(cmd, "Invalid SSH settings", exit.New(exit.Validation, err))
+		}
+		if err := checkSSHKey(cmd, newServer.SSH); err != nil {
+			fail(cmd, "Invalid SSH key", exit.New(exit.Validation, err))
+		}
 		cfg.Servers = append(cfg.Servers, newServer)
 
 		// Save config
 		if err := mgr.Save(cfg); err != nil {
-			outputError(cmd, "Failed to save configuration", err)
-			os.Exit(1)
+			fail(cmd, "Failed to save configuration", err)
 		}
 
-		outputSuccess(cmd, "server_added", map[string]interface{}{
-			"name":   input.Name,
-			"ip":     input.IP,
-			"status": "unprovisioned",
-		})
+		data := map[string]interface{}{
+			"name":     input.Name,
+			"hostname": input.Hostname,
+			"ip":       input.IP,
+			"status":   "unprovisioned",
+		}
+		if input.IPv6 != "" {
+			data["ipv6"] = input.IPv6
+		}
+		outputSuccess(cmd, "server_added", data)
 
 		if !isJSONOutput(cmd) {
 			fmt.Println()
@@ -136,9 +169,13 @@ Examples:
 
 // serverListCmd represents the server list command
 var serverListCmd = &cobra.Command{
-	Use:   "list",
-	Short: "List all servers",
-	Long:  `Display all servers in the configuration.`,
+	Use:         "list",
+	Short:       "List all servers",
+	Annotations: map[string]string{csvAnnotation: ""},
+	Long: `Display all servers in the configuration.
+
+With --stats, disk, memory and load are gathered from every server over SSH.
+Unreachable servers show n/a.`,
 	Run: func(cmd *cobra.Command, args []string) {
 		mgr, err := config.NewManager()
 		if err != nil {
@@ -148,19 +185,30 @@ var serverListCmd = &cobra.Command{
 
 		if !mgr.ConfigExists() {
 			color.Red("Configuration file not found. Run 'wordsail init' first.")
-			os.Exit(1)
+			os.Exit(exit.ConfigNotFound)
 		}
 
 		cfg, err := mgr.Load()
 		if err != nil {
 			color.Red("Error: Failed to load configuration: %v", err)
-			os.Exit(1)
+			os.Exit(exit.Validation)
+		}
+
+		// Gather resource usage over SSH; unreachable servers map to nil
+		showStats, _ := cmd.Flags().GetBool("stats")
+		var stats map[string]*utils.ServerMetrics
+		if showStats && len(cfg.Servers) > 0 {
+			stats = utils.CollectMetrics(cfg.Servers, serverStatsWorkers, utils.CollectServerMetrics)
 		}
 
 		// Check for JSON output
 		jsonOutput, _ := cmd.Flags().GetBool("json")
-		if jsonOutput {
-			output, err := json.MarshalIndent(cfg.Servers, "", "  ")
+		if jsonOutput && showStats {
+			withStats := make([]serverWithStats, len(cfg.Servers))
+			for i, server := range cfg.Servers {
+				withStats[i] = serverWithStats{Server: server, Stats: stats[server.Name]}
+			}
+			output, err := json.MarshalIndent(withStats, "", "  ")
 			if err != nil {
 				color.Red("Error: Failed to marshal JSON: %v", err)
 				os.Exit(1)
@@ -168,18 +216,21 @@ var serverListCmd = &cobra.Command{
 			fmt.Println(string(output))
 			return
 		}
-
-		if len(cfg.Servers) == 0 {
-			fmt.Println("No servers configured.")
-			fmt.Println("Add and provision a server with: wordsail server provision")
+		if jsonOutput {
+			output, err := json.MarshalIndent(cfg.Servers, "", "  ")
+			if err != nil {
+				color.Red("Error: Failed to marshal JSON: %v", err)
+				os.Exit(1)
+			}
+			fmt.Println(string(output))
 			return
 		}
 
-		fmt.Printf("\nServers (%d total):\n\n", len(cfg.Servers))
-
 		// Prepare table data
 		headers := []string{"NAME", "HOSTNAME", "IP", "SSH USER", "STATUS", "SITES"}
-		colWidths := []int{18, 28, 15, 12, 15, 6}
+		if showStats {
+			headers = append(headers, "DISK%", "MEM%", "LOAD")
+		}
 		rows := make([][]string, 0)
 
 		for _, server := range cfg.Servers {
@@ -195,21 +246,60 @@ var serverListCmd = &cobra.Command{
 				statusStr = server.Status
 			}
 
+			ip := server.IP
+			if ip == "" {
+				ip = "-"
+			}
+
 			row := []string{
 				server.Name,
 				server.Hostname,
-				server.IP,
+				ip,
 				server.SSH.User,
 				statusStr,
 				fmt.Sprintf("%d", len(server.Sites)),
 			}
+			if showStats {
+				if metrics := stats[server.Name]; metrics != nil {
+					row = append(row,
+						fmt.Sprintf("%d%%", metrics.DiskUsedPercent),
+						fmt.Sprintf("%d%%", metrics.MemUsedPercent()),
+						fmt.Sprintf("%.2f", metrics.Load1))
+				} else {
+					row = append(row, "n/a", "n/a", "n/a")
+				}
+			}
 			rows = append(rows, row)
 		}
 
-		utils.PrintTableWithBorders(headers, rows, colWidths)
+		if isCSVOutput() {
+			if err := utils.PrintCSV(headers, rows); err != nil {
+				color.Red("Error: %v", err)
+				os.Exit(1)
+			}
+			return
+		}
+
+		if len(cfg.Servers) == 0 {
+			fmt.Println("No servers configured.")
+			fmt.Println("Add and provision a server with: wordsail server provision")
+			return
+		}
+
+		fmt.Printf("\nServers (%d total):\n\n", len(cfg.Servers))
+		utils.PrintTable(headers, rows)
 	},
 }
 
+// serverStatsWorkers bounds how many servers are queried at once by list --stats
+const serverStatsWorkers = 8
+
+// serverWithStats is a server with its resource usage for list --stats --json
+type serverWithStats struct {
+	models.Server
+	Stats *utils.ServerMetrics `json:"stats"`
+}
+
 // serverRemoveCmd represents the server remove command
 var serverRemoveCmd = &cobra.Command{
 	Use:     "remove [name]",
@@ -221,8 +311,14 @@ If no name is provided, you will be prompted to select a server to remove.
 
 Note: This only removes the server from the WordSail inventory. The actual server
 and its resources will still exist in your cloud provider. You must manually
-delete the server from your cloud provider (AWS, DigitalOcean, etc.) if needed.`,
-	Args: cobra.MaximumNArgs(1),
+delete the server from your cloud provider (AWS, DigitalOcean, etc.) if needed.
+
+A server that still has sites is only removed with --force or after typing its
+name, since the sites keep running but disappear from the inventory. Delete
+them first with 'wordsail site delete'. With --json or --yes, removal of such a
+server is refused unless --force is given.`,
+	Args:              cobra.MaximumNArgs(1),
+	ValidArgsFunction: completeServerNames(1),
 	Run: func(cmd *cobra.Command, args []string) {
 		mgr, err := config.NewManager()
 		if err != nil {
@@ -232,13 +328,13 @@ delete the server from your cloud provider (AWS, DigitalOcean, etc.) if needed.`
 
 		if !mgr.ConfigExists() {
 			color.Red("Configuration file not found. Run 'wordsail init' first.")
-			os.Exit(1)
+			os.Exit(exit.ConfigNotFound)
 		}
 
 		cfg, err := mgr.Load()
 		if err != nil {
 			color.Red("Error: Failed to load configuration: %v", err)
-			os.Exit(1)
+			os.Exit(exit.Validation)
 		}
 
 		if len(cfg.Servers) == 0 {
@@ -258,7 +354,7 @@ delete the server from your cloud provider (AWS, DigitalOcean, etc.) if needed.`
 				if siteCount == 1 {
 					siteLabel = "site"
 				}
-				options[i] = fmt.Sprintf("%s (%s) - %d %s", server.Name, server.IP, siteCount, siteLabel)
+				options[i] = fmt.Sprintf("%s (%s) - %d %s", server.Name, server.Address(), siteCount, siteLabel)
 			}
 
 			var selected int
@@ -291,7 +387,21 @@ delete the server from your cloud provider (AWS, DigitalOcean, etc.) if needed.`
 
 		if !found {
 			color.Red("Error: Server '%s' not found", serverName)
-			os.Exit(1)
+			os.Exit(exit.Validation)
+		}
+
+		// Sites keep running on the box but vanish from the inventory, so a
+		// server with sites needs an explicit --force or its name typed out
+		force, _ := cmd.Flags().GetBool("force")
+		siteIDs := make([]string, len(removedServer.Sites))
+		for i, site := range removedServer.Sites {
+			siteIDs[i] = site.SiteID
+		}
+		guardSites := len(siteIDs) > 0 && !force
+		if guardSites && (isJSONOutput(cmd) || prompt.AssumeYes) {
+			fail(cmd, "Server has sites", exit.Errorf(exit.Validation,
+				"server '%s' still has %d site(s) (%s); delete them first with 'wordsail site delete' or pass --force",
+				serverName, len(siteIDs), strings.Join(siteIDs, ", ")))
 		}
 
 		// Show warning about cloud provider
@@ -301,19 +411,23 @@ delete the server from your cloud provider (AWS, DigitalOcean, etc.) if needed.`
 		fmt.Println("You must manually delete it from your cloud provider if needed.")
 		fmt.Println()
 
-		// Warn if server has sites
-		if len(removedServer.Sites) > 0 {
-			color.Yellow("This server has %d site(s) that will also be removed from the inventory.", len(removedServer.Sites))
+		if guardSites {
+			color.Red("⚠️  This server still has %d site(s): %s", len(siteIDs), strings.Join(siteIDs, ", "))
+			fmt.Println("They will keep running on the server but disappear from the inventory.")
+			fmt.Printf("Delete them first with: wordsail site delete --server %s --site <site>\n", serverName)
 			fmt.Println()
-		}
 
-		force, _ := cmd.Flags().GetBool("force")
-		if !force {
-			var confirm bool
-			if err := survey.AskOne(&survey.Confirm{
-				Message: fmt.Sprintf("Remove server '%s' from inventory?", serverName),
-				Default: false,
-			}, &confirm); err != nil {
+			confirm, err := prompt.ConfirmDestructive(fmt.Sprintf("Type %s to remove it anyway:", serverName), serverName)
+			if err != nil {
+				os.Exit(1)
+			}
+			if !confirm {
+				fmt.Println("Server removal cancelled")
+				return
+			}
+		} else if !assumeYes(cmd) {
+			confirm, err := prompt.Confirm(fmt.Sprintf("Remove server '%s' from inventory?", serverName), false, false)
+			if err != nil {
 				os.Exit(1)
 			}
 
@@ -337,12 +451,25 @@ delete the server from your cloud provider (AWS, DigitalOcean, etc.) if needed.`
 
 // serverProvisionCmd represents the server provision command
 var serverProvisionCmd = &cobra.Command{
-	Use:   "provision [name]",
+	Use:   "provision [name...]",
 	Short: "Provision a server",
 	Long: `Run the provision.yml playbook to set up a server with Nginx, PHP, MariaDB, and security hardening.
 
 If no name is provided, you will be prompted to add a new server and provision it immediately.
-If a name is provided, the existing server will be provisioned.
+If a name is provided, the existing server will be provisioned. With several names, the
+servers are provisioned one after another (or --parallel N at a time) and a summary is
+printed at the end.
+
+After provisioning, a smoke test creates a throwaway Nginx vhost, checks that it serves a
+static file and executes PHP, checks that the database accepts a connection, and removes
+the vhost again. A failed check fails the provision. Use --smoke-test=false (or
+--skip-test) to skip it.
+
+A provisioned server is skipped when nothing that decides the run (vars,
+--extra-var values, addresses, playbooks) changed since its last full
+provision; --force runs it anyway, while --yes only skips the confirmation.
+Runs limited with --ansible-tags or --ansible-skip-tags are never skipped and
+don't count as a full provision.
 
 Examples:
   # Interactive mode - add and provision new server
@@ -352,24 +479,39 @@ Examples:
   wordsail server provision myserver
 
   # Non-interactive mode - add and provision new server (for automation/AI agents)
-  wordsail server provision --name myserver --ip 1.2.3.4 --ssh-key ~/.ssh/id_rsa --force`,
-	Args: cobra.MaximumNArgs(1),
+  wordsail server provision --name myserver --ip 1.2.3.4 --ssh-key ~/.ssh/id_rsa --force
+
+  # Switch to the wordsail user and disable root SSH login afterwards
+  wordsail server provision myserver --disable-root-after
+
+  # Skip the post-provision smoke test
+  wordsail server provision myserver --smoke-test=false
+
+  # Re-run even when nothing changed since the last successful provision
+  wordsail server provision myserver --force
+
+  # Provision several existing servers, two at a time
+  wordsail server provision web1 web2 web3 --parallel 2 --yes`,
+	Args:              cobra.ArbitraryArgs,
+	ValidArgsFunction: completeServerNames(0),
 	Run: func(cmd *cobra.Command, args []string) {
 		mgr, err := config.NewManager()
 		if err != nil {
-			outputError(cmd, "Failed to create config manager", err)
-			os.Exit(1)
+			fail(cmd, "Failed to create config manager", err)
 		}
 
 		if !mgr.ConfigExists() {
-			outputError(cmd, "Configuration file not found", fmt.Errorf("run 'wordsail init' first"))
-			os.Exit(1)
+			fail(cmd, "Configuration file not found", exit.Errorf(exit.ConfigNotFound, "run 'wordsail init' first"))
 		}
 
 		cfg, err := mgr.Load()
 		if err != nil {
-			outputError(cmd, "Failed to load configuration", err)
-			os.Exit(1)
+			fail(cmd, "Failed to load configuration", exit.New(exit.Validation, err))
+		}
+
+		if len(args) > 1 {
+			provisionServers(cmd, mgr, cfg, args)
+			return
 		}
 
 		var targetServer *models.Server
@@ -379,6 +521,11 @@ Examples:
 		flagName, _ := cmd.Flags().GetString("name")
 		flagIP, _ := cmd.Flags().GetString("ip")
 
+		flagIPv6, err := ipv6Flag(cmd)
+		if err != nil {
+			fail(cmd, "Invalid IPv6 address", exit.New(exit.Validation, err))
+		}
+
 		if len(args) > 0 {
 			// Provision existing server by name argument
 			serverName = args[0]
@@ -392,33 +539,44 @@ Examples:
 			}
 
 			if targetServer == nil {
-				outputError(cmd, "Server not found", fmt.Errorf("server '%s' not found. Run 'wordsail server list' to see available servers", serverName))
-				os.Exit(1)
+				fail(cmd, "Server not found", exit.Errorf(exit.Validation, "server '%s' not found. Run 'wordsail server list' to see available servers", serverName))
+			}
+
+			if flagIPv6 != "" && flagIPv6 != targetServer.IPv6 {
+				targetServer.IPv6 = flagIPv6
+				if err := mgr.Save(cfg); err != nil {
+					fail(cmd, "Failed to save configuration", err)
+				}
 			}
 		} else if flagName != "" && flagIP != "" {
 			// Non-interactive mode: create new server from flags
+			if err := utils.ValidateHostnameOrIP(flagIP); err != nil {
+				fail(cmd, "Invalid --ip", exit.New(exit.Validation, err))
+			}
+
 			sshKey, _ := cmd.Flags().GetString("ssh-key")
 			sshUser, _ := cmd.Flags().GetString("ssh-user")
 			sshPort, _ := cmd.Flags().GetInt("ssh-port")
 
-			if sshKey == "" {
-				outputError(cmd, "Missing required flag", fmt.Errorf("--ssh-key is required in non-interactive mode"))
-				os.Exit(1)
+			useAgent, _ := cmd.Flags().GetBool("use-agent")
+			if sshKey == "" && !useAgent {
+				sshKey = config.SSHPrivateKeyPath(cfg)
+			}
+			if sshKey == "" && !useAgent {
+				fail(cmd, "Missing required flag", exit.Errorf(exit.Validation, "--ssh-key (or --use-agent) is required in non-interactive mode"))
 			}
 
 			// Check for duplicate server name
 			for _, server := range cfg.Servers {
 				if server.Name == flagName {
-					outputError(cmd, "Server already exists", fmt.Errorf("server with name '%s' already exists", flagName))
-					os.Exit(1)
+					fail(cmd, "Server already exists", exit.Errorf(exit.Validation, "server with name '%s' already exists", flagName))
 				}
 			}
 
 			// Create new server
 			newServer := models.Server{
-				Name:     flagName,
-				Hostname: flagIP,
-				IP:       flagIP,
+				Name: flagName,
+				IPv6: flagIPv6,
 				SSH: models.SSHConfig{
 					User:    sshUser,
 					Port:    sshPort,
@@ -427,13 +585,19 @@ Examples:
 				Status: "unprovisioned",
 				Sites:  []models.Site{},
 			}
+			newServer.SetAddress(flagIP)
+			if err := applySSHFlags(cmd, &newServer.SSH); err != nil {
+				fail(cmd, "Invalid SSH settings", exit.New(exit.Validation, err))
+			}
+			if err := checkSSHKey(cmd, newServer.SSH); err != nil {
+				fail(cmd, "Invalid SSH key", exit.New(exit.Validation, err))
+			}
 
 			cfg.Servers = append(cfg.Servers, newServer)
 
 			// Save config
 			if err := mgr.Save(cfg); err != nil {
-				outputError(cmd, "Failed to save configuration", err)
-				os.Exit(1)
+				fail(cmd, "Failed to save configuration", err)
 			}
 
 			outputInfo(cmd, "✓ Server '%s' added to configuration\n\n", flagName)
@@ -441,32 +605,38 @@ Examples:
 			serverName = flagName
 			targetServer = &cfg.Servers[len(cfg.Servers)-1]
 		} else if flagName != "" || flagIP != "" {
-			outputError(cmd, "Incomplete flags", fmt.Errorf("both --name and --ip are required for non-interactive mode"))
-			os.Exit(1)
+			fail(cmd, "Incomplete flags", exit.Errorf(exit.Validation, "both --name and --ip are required for non-interactive mode"))
 		} else {
 			// Interactive mode: prompt for server details
-			input, err := prompt.PromptServerAdd()
+			input, err := prompt.PromptServerAdd(config.SSHPrivateKeyPath(cfg))
 			if err != nil {
-				outputError(cmd, "Failed to get server details", err)
-				os.Exit(1)
+				fail(cmd, "Failed to get server details", err)
 			}
 
 			// Check for duplicate server name
 			for _, server := range cfg.Servers {
 				if server.Name == input.Name {
-					outputError(cmd, "Server already exists", fmt.Errorf("server with name '%s' already exists", input.Name))
-					os.Exit(1)
+					fail(cmd, "Server already exists", exit.Errorf(exit.Validation, "server with name '%s' already exists", input.Name))
 				}
 			}
 
+			if flagIPv6 != "" {
+				input.IPv6 = flagIPv6
+			}
+
 			// Add server to config
 			newServer := input.ToServer()
+			if err := applySSHFlags(cmd, &newServer.SSH); err != nil {
+				fail(cmd, "Invalid SSH settings", exit.New(exit.Validation, err))
+			}
+			if err := checkSSHKey(cmd, newServer.SSH); err != nil {
+				fail(cmd, "Invalid SSH key", exit.New(exit.Validation, err))
+			}
 			cfg.Servers = append(cfg.Servers, newServer)
 
 			// Save config
 			if err := mgr.Save(cfg); err != nil {
-				outputError(cmd, "Failed to save configuration", err)
-				os.Exit(1)
+				fail(cmd, "Failed to save configuration", err)
 			}
 
 			color.Green("✓ Server '%s' added to configuration", input.Name)
@@ -477,32 +647,63 @@ Examples:
 			targetServer = &cfg.Servers[len(cfg.Servers)-1]
 		}
 
-		// Check if already provisioned
-		if targetServer.Status == "provisioned" {
+		// Resolve database engine/version (flags override what the server was provisioned with)
+		dbEngine, dbVersion, err := resolveDatabaseSelection(cmd, *targetServer)
+		if err != nil {
+			fail(cmd, "Invalid database selection", exit.New(exit.Validation, err))
+		}
+
+		force, _ := cmd.Flags().GetBool("force")
+
+		// Check if already provisioned, and whether anything changed since
+		planned := *targetServer
+		planned.Database = models.DatabaseEngine{Engine: dbEngine, Version: dbVersion}
+		unchanged := targetServer.Status == "provisioned" && targetServer.ProvisionHash != "" &&
+			!provisionLimited() && provisionHash(cfg, planned) == targetServer.ProvisionHash
+
+		if unchanged && !force {
+			if isJSONOutput(cmd) {
+				outputSuccess(cmd, "provision_skipped", map[string]interface{}{
+					"name":   serverName,
+					"reason": "no configuration changes since last provision",
+				})
+				return
+			}
+
+			color.Yellow("No configuration changes since the last provision of '%s'", serverName)
+			confirm, err := prompt.Confirm("Provision again anyway?", false, false)
+			if err != nil {
+				os.Exit(1)
+			}
+			if !confirm {
+				fmt.Println("Provisioning skipped")
+				return
+			}
+		} else if targetServer.Status == "provisioned" {
 			color.Yellow("Warning: Server '%s' is already marked as provisioned", serverName)
 
 			skipCheck, _ := cmd.Flags().GetBool("skip-check")
-			if !skipCheck {
-				var confirm bool
-				if err := survey.AskOne(&survey.Confirm{
-					Message: "Provision again anyway?",
-					Default: false,
-				}, &confirm); err != nil {
-					os.Exit(1)
-				}
+			confirm, err := prompt.Confirm("Provision again anyway?", false, force || skipCheck)
+			if err != nil {
+				os.Exit(1)
+			}
 
-				if !confirm {
-					fmt.Println("Provisioning cancelled")
-					return
-				}
+			if !confirm {
+				fmt.Println("Provisioning cancelled")
+				return
 			}
 		}
 
+		retries, _ := cmd.Flags().GetInt("retries")
+		if retries < 0 {
+			fail(cmd, "Invalid flag", exit.Errorf(exit.Validation, "--retries must be 0 or greater"))
+		}
+
 		// Pre-flight SSH check
 		skipSSH, _ := cmd.Flags().GetBool("skip-ssh-check")
 		if !skipSSH {
 			fmt.Println("Checking SSH connectivity...")
-			if err := utils.TestSSHConnection(*targetServer); err != nil {
+			if err := testSSHWithRetries(cmd, *targetServer, retries); err != nil {
 				color.Red("✗ SSH connectivity check failed: %v", err)
 				fmt.Println()
 				fmt.Println("Please verify:")
@@ -511,35 +712,29 @@ Examples:
 				fmt.Println("  3. SSH user has access to the server")
 				fmt.Println()
 				fmt.Println("Use --skip-ssh-check to bypass this check (not recommended)")
-				os.Exit(1)
+				os.Exit(exit.SSH)
 			}
 			color.Green("✓ SSH connectivity check passed")
 			fmt.Println()
 		}
 
 		// Confirm provisioning
-		color.Cyan("About to provision server: %s (%s)", targetServer.Name, targetServer.IP)
+		color.Cyan("About to provision server: %s (%s)", targetServer.Name, targetServer.Address())
 		fmt.Println("This will:")
-		fmt.Println("  - Install Nginx, PHP 8.3, MariaDB")
+		fmt.Printf("  - Install Nginx, PHP 8.3, %s\n", describeDatabaseEngine(dbEngine, dbVersion))
 		fmt.Println("  - Configure security (UFW, Fail2ban, SSH hardening)")
 		fmt.Println("  - Set up Certbot for SSL certificates")
 		fmt.Println("  - Create wordsail user and environment")
 		fmt.Println()
 
-		force, _ := cmd.Flags().GetBool("force")
-		if !force {
-			var confirm bool
-			if err := survey.AskOne(&survey.Confirm{
-				Message: "Continue with provisioning?",
-				Default: true,
-			}, &confirm); err != nil {
-				os.Exit(1)
-			}
+		confirm, err := prompt.Confirm("Continue with provisioning?", true, force)
+		if err != nil {
+			os.Exit(1)
+		}
 
-			if !confirm {
-				fmt.Println("Provisioning cancelled")
-				return
-			}
+		if !confirm {
+			fmt.Println("Provisioning cancelled")
+			return
 		}
 
 		// Generate MySQL password for this server if not already set
@@ -547,78 +742,126 @@ Examples:
 		if mysqlPassword == "" {
 			mysqlPassword = prompt.GenerateSecurePassword(24)
 			targetServer.Credentials.MySQLWordsailbotPassword = mysqlPassword
-
-			// Update server in config with the new password
-			for i := range cfg.Servers {
-				if cfg.Servers[i].Name == serverName {
-					cfg.Servers[i].Credentials.MySQLWordsailbotPassword = mysqlPassword
-					break
-				}
-			}
-			if err := mgr.Save(cfg); err != nil {
-				outputError(cmd, "Failed to save MySQL password to config", err)
-				os.Exit(1)
-			}
 		}
 
-		// Validate required global vars are present
-		requiredVars := []string{"certbot_email", "wordsail_ssh_key"}
-		for _, varName := range requiredVars {
-			val, exists := cfg.GlobalVars[varName]
-			if !exists || val == nil || fmt.Sprintf("%v", val) == "" {
-				color.Red("✗ Missing required configuration: %s", varName)
-				fmt.Println()
-				fmt.Println("Please ensure your configuration has the following global_vars set:")
-				fmt.Println("  - certbot_email: Email for Let's Encrypt certificates")
-				fmt.Println("  - wordsail_ssh_key: Path to SSH public key for wordsail user")
-				fmt.Println()
-				fmt.Println("Run 'wordsail init --force' to reconfigure, or edit your config:")
-				fmt.Printf("  %s %s\n", getEditor(), mgr.GetConfigPath())
-				os.Exit(1)
+		// Update server in config with the password
+		for i := range cfg.Servers {
+			if cfg.Servers[i].Name == serverName {
+				cfg.Servers[i].Credentials.MySQLWordsailbotPassword = mysqlPassword
+				break
 			}
 		}
+		if err := mgr.Save(cfg); err != nil {
+			fail(cmd, "Failed to save server details to config", err)
+		}
 
-		// Create a copy of global vars and add the server-specific MySQL password
-		provisionVars := make(map[string]interface{})
-		for k, v := range cfg.GlobalVars {
-			provisionVars[k] = v
+		// The database selection is only recorded once the playbook has installed it
+		targetServer.Database = models.DatabaseEngine{
+			Engine:  dbEngine,
+			Version: dbVersion,
 		}
-		provisionVars["mysql_wordsailbot_password"] = mysqlPassword
+
+		// Validate required global vars are present
+		requireProvisionGlobalVars(mgr, cfg)
+
+		provisionVars := buildProvisionVars(cfg, *targetServer)
 
 		// Create Ansible executor
-		executor := ansible.NewExecutor(cfg.Ansible.Path)
-		executor.SetVerbose(Verbose)
-		executor.SetDryRun(DryRun)
+		executor := newExecutor(cmd, cfg)
+		executor.SetRetries(retries)
 
 		// Execute provision.yml playbook
-		fmt.Println()
-		color.Cyan("═══════════════════════════════════════════════════════")
-		color.Cyan("  Starting provisioning: %s", serverName)
-		color.Cyan("  Estimated time: 5-10 minutes")
-		color.Cyan("═══════════════════════════════════════════════════════")
-		fmt.Println()
+		outputBanner(cmd, color.Cyan,
+			fmt.Sprintf("Starting provisioning: %s", serverName),
+			"Estimated time: 5-10 minutes")
+
+		stateMgr := state.NewManager(mgr)
+		startedAt := time.Now()
+		stats, err := executor.ExecutePlaybook("provision.yml", *targetServer, nil, provisionVars)
+		if err != nil {
+			outputError(cmd, "Provisioning failed", err)
 
-		if err := executor.ExecutePlaybook("provision.yml", *targetServer, nil, provisionVars); err != nil {
-			color.Red("\n✗ Provisioning failed: %v", err)
+			recordProvisionRun(cmd, mgr, stateMgr, executor, serverName, startedAt, stats, err)
 
 			// Mark server as error
-			stateMgr := state.NewManager(mgr)
 			stateMgr.MarkServerError(serverName)
 
-			os.Exit(1)
+			os.Exit(exit.CodeOf(err))
 		}
+		recordProvisionRun(cmd, mgr, stateMgr, executor, serverName, startedAt, stats, nil)
 
 		// Update server status to provisioned
-		stateMgr := state.NewManager(mgr)
 		if err := stateMgr.MarkServerProvisioned(serverName); err != nil {
-			color.Red("Warning: Failed to update server status: %v", err)
+			outputWarning(cmd, "Failed to update server status: %v", err)
+		}
+		if !DryRun {
+			if err := stateMgr.SetServerDatabase(serverName, targetServer.Database); err != nil {
+				outputWarning(cmd, "Failed to record the database engine: %v", err)
+			}
 		}
 
-		fmt.Println()
-		color.Green("═══════════════════════════════════════════════════════")
-		color.Green("  ✓ Server '%s' provisioned successfully!", serverName)
-		color.Green("═══════════════════════════════════════════════════════")
-		fmt.Println()
+		// Verify the stack works end-to-end, not just that Ansible succeeded
+		smokeTested := false
+		if smokeTestEnabled(cmd) && !DryRun {
+			if err := smokeTestServer(cmd, *targetServer); err != nil {
+				outputError(cmd, "Provisioning completed but the smoke test failed", err)
+				stateMgr.MarkServerError(serverName)
+				os.Exit(exit.CodeOf(err))
+			}
+			smokeTested = true
+		}
+
+		// Optionally switch to the wordsail user and disable root SSH login
+		rootLoginDisabled := false
+		if disableRoot, _ := cmd.Flags().GetBool("disable-root-after"); disableRoot {
+			if DryRun {
+				outputInfo(cmd, "Dry run: skipping root login hardening\n")
+			} else {
+				outputInfo(cmd, "→ Verifying SSH login as %s before disabling root...\n", wordsailUser)
+				hardened, err := stateMgr.DisableRootLogin(serverName, wordsailUser, state.RootLoginSteps{
+					VerifyLogin: func(server models.Server) error {
+						return verifyNonRootLogin(server, sshTimeout(cmd))
+					},
+					DisableRoot: func(server models.Server) error {
+						_, err := executor.ExecutePlaybook("playbooks/disable_root_login.yml", server, nil, cfg.GlobalVars)
+						return err
+					},
+				})
+				if err != nil {
+					fail(cmd, "Failed to disable root login", err)
+				}
+				rootLoginDisabled = true
+				targetServer.SSH.User = hardened.SSH.User
+				targetServer.RootLoginDisabled = true
+				outputInfo(cmd, "✓ Root SSH login disabled; connecting as %s from now on\n", hardened.SSH.User)
+			}
+		}
+
+		// Remember the inputs so an unchanged re-run can be skipped
+		if !DryRun {
+			if err := recordProvisionHash(stateMgr, cfg, *targetServer); err != nil {
+				outputWarning(cmd, "Failed to record provisioning state: %v", err)
+			}
+		}
+
+		if isJSONOutput(cmd) {
+			outputSuccess(cmd, "server_provisioned", map[string]interface{}{
+				"name":                serverName,
+				"hostname":            targetServer.Hostname,
+				"ip":                  targetServer.IP,
+				"ipv6":                targetServer.IPv6,
+				"db_engine":           dbEngine,
+				"db_version":          dbVersion,
+				"ssh_user":            targetServer.SSH.User,
+				"root_login_disabled": rootLoginDisabled || targetServer.RootLoginDisabled,
+				"smoke_tested":        smokeTested,
+				"changed":             stats.Changed,
+				"config_location":     mgr.GetConfigPath(),
+			})
+			return
+		}
+
+		outputBanner(cmd, color.Green, fmt.Sprintf("✓ Server '%s' provisioned successfully! (%d changed)", serverName, stats.Changed))
 		fmt.Println("Server credentials:")
 		fmt.Printf("  MySQL wordsailbot password: %s\n", mysqlPassword)
 		fmt.Println()
@@ -629,95 +872,1183 @@ Examples:
 	},
 }
 
-// serverHealthCheckCmd represents the server health-check command
-var serverHealthCheckCmd = &cobra.Command{
-	Use:     "health-check [name]",
-	Aliases: []string{"check", "ping"},
-	Short:   "Check server connectivity and health",
-	Long: `Test SSH connectivity and verify services are running on a server.
-
-Examples:
-  # Check a specific server
-  wordsail server health-check myserver
-
-  # Interactively select a server to check
-  wordsail server health-check`,
-	Args: cobra.MaximumNArgs(1),
-	Run: func(cmd *cobra.Command, args []string) {
-		mgr, err := config.NewManager()
+// provisionServers provisions several existing servers, sequentially or with
+// bounded concurrency, and prints a per-server summary. Each server is marked
+// provisioned or error independently.
+func provisionServers(cmd *cobra.Command, mgr *config.Manager, cfg *config.Config, names []string) {
+	for _, flag := range []string{"name", "ip", "ipv6", "disable-root-after"} {
+		if cmd.Flags().Changed(flag) {
+			fail(cmd, "Invalid flags", exit.Errorf(exit.Validation, "--%s cannot be used when provisioning multiple servers", flag))
+		}
+	}
+
+	parallel, _ := cmd.Flags().GetInt("parallel")
+	if parallel < 1 {
+		fail(cmd, "Invalid flag", exit.Errorf(exit.Validation, "--parallel must be 1 or greater"))
+	}
+	retries, _ := cmd.Flags().GetInt("retries")
+	if retries < 0 {
+		fail(cmd, "Invalid flag", exit.Errorf(exit.Validation, "--retries must be 0 or greater"))
+	}
+	failFast, _ := cmd.Flags().GetBool("fail-fast")
+	skipCheck, _ := cmd.Flags().GetBool("skip-check")
+	skipSSH, _ := cmd.Flags().GetBool("skip-ssh-check")
+	smokeTest := smokeTestEnabled(cmd)
+	force, _ := cmd.Flags().GetBool("force")
+
+	// Resolve every server up front so nothing starts if one name is wrong
+	seen := make(map[string]bool, len(names))
+	var queue, alreadyProvisioned, unchanged []string
+	databases := make(map[string]models.DatabaseEngine, len(names))
+	for _, name := range names {
+		if seen[name] {
+			continue
+		}
+		seen[name] = true
+
+		server := utils.FindServerByName(cfg.Servers, name)
+		if server == nil {
+			fail(cmd, "Server not found", exit.Errorf(exit.Validation, "server '%s' not found. Run 'wordsail server list' to see available servers", name))
+		}
+		if server.Status == "provisioned" && !skipCheck {
+			alreadyProvisioned = append(alreadyProvisioned, name)
+			continue
+		}
+
+		dbEngine, dbVersion, err := resolveDatabaseSelection(cmd, *server)
 		if err != nil {
-			color.Red("Error: %v", err)
-			os.Exit(1)
+			fail(cmd, "Invalid database selection", exit.New(exit.Validation, err))
+		}
+		planned := *server
+		planned.Database = models.DatabaseEngine{Engine: dbEngine, Version: dbVersion}
+		if server.Status == "provisioned" && !force && server.ProvisionHash != "" && !provisionLimited() && provisionHash(cfg, planned) == server.ProvisionHash {
+			unchanged = append(unchanged, name)
+			continue
 		}
+		if server.Credentials.MySQLWordsailbotPassword == "" {
+			server.Credentials.MySQLWordsailbotPassword = prompt.GenerateSecurePassword(24)
+		}
+		databases[name] = planned.Database
+		queue = append(queue, name)
+	}
 
-		if !mgr.ConfigExists() {
-			color.Red("Configuration file not found. Run 'wordsail init' first.")
-			os.Exit(1)
+	if len(alreadyProvisioned) > 0 {
+		outputWarning(cmd, "Skipping already provisioned servers (use --skip-check to provision again): %s", strings.Join(alreadyProvisioned, ", "))
+	}
+	if len(unchanged) > 0 {
+		outputWarning(cmd, "Skipping servers with no configuration changes since their last provision (use --force to provision anyway): %s", strings.Join(unchanged, ", "))
+	}
+	if len(queue) == 0 {
+		outputInfo(cmd, "Nothing to provision\n")
+		return
+	}
+
+	requireProvisionGlobalVars(mgr, cfg)
+
+	if !assumeYes(cmd) {
+		if isJSONOutput(cmd) {
+			fail(cmd, "Confirmation required", exit.Errorf(exit.Validation, "use --yes to provision multiple servers in JSON mode (--force also re-runs servers with no changes)"))
 		}
 
-		cfg, err := mgr.Load()
+		mode := "sequentially"
+		if parallel > 1 {
+			mode = fmt.Sprintf("%d at a time", parallel)
+		}
+		confirm, err := prompt.Confirm(fmt.Sprintf("Provision %d servers (%s) %s?", len(queue), strings.Join(queue, ", "), mode), true, false)
 		if err != nil {
-			color.Red("Error: Failed to load configuration: %v", err)
 			os.Exit(1)
 		}
-
-		if len(cfg.Servers) == 0 {
-			fmt.Println("No servers configured.")
+		if !confirm {
+			fmt.Println("Provisioning cancelled")
 			return
 		}
+	}
 
-		var serverName string
+	// Persist generated passwords before any run starts; database selections
+	// are recorded as each run succeeds
+	if err := mgr.Save(cfg); err != nil {
+		fail(cmd, "Failed to save server details to config", err)
+	}
 
-		if len(args) == 0 {
-			// Interactive mode
-			options := make([]string, len(cfg.Servers))
-			for i, server := range cfg.Servers {
-				options[i] = fmt.Sprintf("%s (%s) - %s", server.Name, server.IP, server.Status)
-			}
+	outputBanner(cmd, color.Cyan,
+		fmt.Sprintf("Provisioning %d servers", len(queue)),
+		"Estimated time: 5-10 minutes per server")
 
-			var selected int
-			selectPrompt := &survey.Select{
-				Message: "Select a server to check:",
-				Options: options,
-			}
-			if err := survey.AskOne(selectPrompt, &selected); err != nil {
-				os.Exit(1)
+	stateMgr := state.NewManager(mgr)
+	results := stateMgr.ProvisionBatch(queue, parallel, failFast, func(name string) error {
+		server := *utils.FindServerByName(cfg.Servers, name)
+		server.Database = databases[name]
+
+		if !skipSSH {
+			if err := testSSHWithRetries(cmd, server, retries); err != nil {
+				return fmt.Errorf("SSH connectivity check failed: %w", err)
 			}
-			serverName = cfg.Servers[selected].Name
-		} else {
-			serverName = args[0]
 		}
 
-		// Find server
-		var targetServer *models.Server
-		for i := range cfg.Servers {
-			if cfg.Servers[i].Name == serverName {
-				targetServer = &cfg.Servers[i]
-				break
-			}
+		// Each run gets its own executor; the spinner is off when runs overlap
+		executor := newExecutor(cmd, cfg)
+		executor.SetRetries(retries)
+		if parallel > 1 {
+			executor.SetQuiet(true)
 		}
 
-		if targetServer == nil {
-			color.Red("Error: Server '%s' not found", serverName)
-			os.Exit(1)
+		outputInfo(cmd, "→ %s: provisioning...\n", name)
+		startedAt := time.Now()
+		stats, err := executor.ExecutePlaybook("provision.yml", server, nil, buildProvisionVars(cfg, server))
+		recordProvisionRun(cmd, mgr, stateMgr, executor, name, startedAt, stats, err)
+		if err != nil {
+			return err
 		}
 
-		fmt.Printf("\nChecking server: %s (%s)\n\n", targetServer.Name, targetServer.IP)
+		if DryRun {
+			return nil
+		}
+		if err := stateMgr.SetServerDatabase(name, server.Database); err != nil {
+			outputWarning(cmd, "%s: failed to record the database engine: %v", name, err)
+		}
+		if smokeTest {
+			if err := smokeTestServer(cmd, server); err != nil {
+				return err
+			}
+		}
+		if err := recordProvisionHash(stateMgr, cfg, server); err != nil {
+			outputWarning(cmd, "%s: failed to record provisioning state: %v", name, err)
+		}
+		return nil
+	})
+
+	// Summary
+	failed := 0
+	summary := make([]map[string]interface{}, len(results))
+	outputInfo(cmd, "\n")
+	for i, result := range results {
+		entry := map[string]interface{}{
+			"name":             result.Server,
+			"status":           result.Status,
+			"duration_seconds": int(result.Duration.Seconds()),
+		}
+
+		switch result.Status {
+		case state.BatchProvisioned:
+			outputInfo(cmd, "  %s %-20s provisioned in %s\n", color.GreenString("✓"), result.Server, result.Duration.Round(time.Second))
+		case state.BatchError:
+			failed++
+			entry["error"] = result.Err.Error()
+			outputInfo(cmd, "  %s %-20s failed: %v\n", color.RedString("✗"), result.Server, result.Err)
+		case state.BatchSkipped:
+			outputInfo(cmd, "  %s %-20s skipped (--fail-fast)\n", color.YellowString("-"), result.Server)
+		}
+		summary[i] = entry
+	}
+	outputInfo(cmd, "\n")
+
+	if failed > 0 {
+		if isJSONOutput(cmd) {
+			printResult(CommandResult{
+				Success:  false,
+				Action:   "servers_provisioned",
+				Message:  fmt.Sprintf("%d of %d servers failed to provision", failed, len(results)),
+				Error:    "provisioning failed",
+				Data:     map[string]interface{}{"servers": summary},
+				ExitCode: exit.Playbook,
+			})
+		} else {
+			color.Red("✗ %d of %d servers failed to provision", failed, len(results))
+		}
+		os.Exit(exit.Playbook)
+	}
+
+	outputSuccess(cmd, "servers_provisioned", map[string]interface{}{
+		"count":   len(results),
+		"servers": summary,
+	})
+}
+
+// resolveDatabaseSelection picks the database engine and version for a
+// provisioning run. Flags override what the server was provisioned with.
+func resolveDatabaseSelection(cmd *cobra.Command, server models.Server) (string, string, error) {
+	dbEngine, _ := cmd.Flags().GetString("db-engine")
+	dbVersion, _ := cmd.Flags().GetString("mariadb-version")
+	if dbEngine == "" {
+		dbEngine = server.Database.Engine
+	}
+	if dbEngine == "" {
+		dbEngine = ansible.DatabaseEngineMariaDB
+	}
+	if dbVersion == "" && dbEngine == server.Database.Engine {
+		dbVersion = server.Database.Version
+	}
+	if err := ansible.ValidateDatabaseEngine(dbEngine, dbVersion); err != nil {
+		return "", "", err
+	}
+	if server.Status == "provisioned" && server.Database.Engine != "" && server.Database.Engine != dbEngine {
+		return "", "", fmt.Errorf("server '%s' already runs %s; switching engines on a provisioned server is not supported", server.Name, server.Database.Engine)
+	}
+	return dbEngine, dbVersion, nil
+}
+
+// requireProvisionGlobalVars exits with instructions when global vars
+// needed by provision.yml are missing
+func requireProvisionGlobalVars(mgr *config.Manager, cfg *config.Config) {
+	for _, varName := range config.RequiredGlobalVars {
+		val, exists := cfg.GlobalVars[varName]
+		if !exists || val == nil || fmt.Sprintf("%v", val) == "" {
+			color.Red("✗ Missing required configuration: %s", varName)
+			fmt.Println()
+			fmt.Println("Please ensure your configuration has the following global_vars set:")
+			fmt.Println("  - certbot_email: Email for Let's Encrypt certificates")
+			fmt.Println("  - wordsail_ssh_key: Path to SSH public key for wordsail user")
+			fmt.Println()
+			fmt.Println("Run 'wordsail init --force' to reconfigure, or edit your config:")
+			fmt.Printf("  %s %s\n", getEditor(), mgr.GetConfigPath())
+			os.Exit(exit.Validation)
+		}
+	}
+}
+
+// buildProvisionVars copies the global vars and adds the server-specific
+// MySQL password and database selection
+func buildProvisionVars(cfg *config.Config, server models.Server) map[string]interface{} {
+	provisionVars := make(map[string]interface{})
+	for k, v := range cfg.GlobalVars {
+		provisionVars[k] = v
+	}
+	provisionVars["mysql_wordsailbot_password"] = server.Credentials.MySQLWordsailbotPassword
+	if server.RootLoginDisabled {
+		// Keep root login off when re-provisioning a hardened server
+		provisionVars["ssh_permit_root_login"] = "no"
+	}
+	for k, v := range ansible.DatabaseVars(server.Database.Engine, server.Database.Version) {
+		provisionVars[k] = v
+	}
+	return provisionVars
+}
+
+// provisionHash digests the inputs of a provisioning run so an unchanged
+// re-run can be detected. --extra-var and --extra-vars-file values are
+// included, as they win over the computed vars in the run itself. It returns
+// "" when the playbooks cannot be read, which never matches a stored hash.
+func provisionHash(cfg *config.Config, server models.Server) string {
+	version, err := state.PlaybookVersion(cfg.Ansible.Path)
+	if err != nil {
+		return ""
+	}
+	vars := buildProvisionVars(cfg, server)
+	for k, v := range ExtraVarOverrides {
+		vars[k] = v
+	}
+	return state.ProvisionHash(vars, server, version)
+}
+
+// provisionLimited reports whether --ansible-tags or --ansible-skip-tags
+// restrict a provisioning run to part of the playbook. Such runs are never
+// skipped as unchanged, and their hash is not recorded.
+func provisionLimited() bool {
+	return AnsibleTags != "" || SkipTags != ""
+}
+
+// recordProvisionHash remembers the inputs of a successful provisioning run
+// so an unchanged re-run can be skipped. A run limited by tags did not apply
+// the whole playbook, so it is not recorded.
+func recordProvisionHash(stateMgr *state.Manager, cfg *config.Config, server models.Server) error {
+	if provisionLimited() {
+		return nil
+	}
+	return stateMgr.RecordProvisionHash(server.Name, provisionHash(cfg, server))
+}
+
+// recordProvisionRun saves the output of a provisioning run to a log file
+// and adds the run and its recap counts to the server's history. Dry runs
+// are not recorded.
+func recordProvisionRun(cmd *cobra.Command, mgr *config.Manager, stateMgr *state.Manager, executor *ansible.Executor, serverName string, startedAt time.Time, stats *ansible.ExecutionResult, runErr error) {
+	if DryRun {
+		return
+	}
+	run := models.ProvisionRun{
+		StartedAt:       startedAt,
+		DurationSeconds: int(time.Since(startedAt).Seconds()),
+		Success:         runErr == nil,
+	}
+	if stats != nil {
+		run.Ok, run.Changed, run.Failed = stats.Ok, stats.Changed, stats.Failed
+	}
+	if runErr != nil {
+		run.Error = runErr.Error()
+	}
+
+	logPath, err := state.WriteProvisionLog(filepath.Join(mgr.GetConfigDir(), state.ProvisionLogDir), serverName, startedAt, executor.LastOutput())
+	if err != nil {
+		outputWarning(cmd, "%s: %v", serverName, err)
+	} else {
+		run.LogPath = logPath
+	}
+	if err := stateMgr.RecordProvisionRun(serverName, run); err != nil {
+		outputWarning(cmd, "%s: failed to record provisioning history: %v", serverName, err)
+	}
+}
+
+// wordsailUser is the non-root user created by the bootstrap role
+const wordsailUser = "wordsail"
+
+// verifyNonRootLogin checks SSH and passwordless sudo for the server's user
+func verifyNonRootLogin(server models.Server, timeout time.Duration) error {
+	if err := utils.TestSSHConnection(server, timeout); err != nil {
+		return err
+	}
+	return utils.TestSSHSudo(server, timeout)
+}
+
+// sshTimeout returns the command's --ssh-timeout, or the default for
+// commands without the flag
+func sshTimeout(cmd *cobra.Command) time.Duration {
+	timeout, err := cmd.Flags().GetDuration("ssh-timeout")
+	if err != nil {
+		return utils.DefaultSSHTimeout
+	}
+	if timeout <= 0 {
+		fail(cmd, "Invalid flags", exit.Errorf(exit.Validation, "--ssh-timeout must be positive"))
+	}
+	return timeout
+}
+
+// testSSHWithRetries runs the SSH pre-flight check, retrying transient
+// connection failures with the same backoff as playbook runs
+func testSSHWithRetries(cmd *cobra.Command, server models.Server, retries int) error {
+	for attempt := 0; ; attempt++ {
+		err := utils.TestSSHConnection(server, sshTimeout(cmd))
+		if err == nil || attempt >= retries || !ansible.IsConnectionError([]string{err.Error()}) {
+			return err
+		}
+
+		delay := ansible.RetryDelay(attempt)
+		outputInfo(cmd, "  SSH not reachable yet, retrying in %s (attempt %d/%d)...\n", delay, attempt+1, retries)
+		time.Sleep(delay)
+	}
+}
+
+// smokeTestEnabled reports whether provisioning ends with the smoke test:
+// --smoke-test, unless turned off or skipped with --skip-test, which is kept
+// as an alias
+func smokeTestEnabled(cmd *cobra.Command) bool {
+	smokeTest, _ := cmd.Flags().GetBool("smoke-test")
+	skipTest, _ := cmd.Flags().GetBool("skip-test")
+	return smokeTest && !skipTest
+}
+
+// smokeTestServer runs the post-provision smoke test and prints each check
+func smokeTestServer(cmd *cobra.Command, server models.Server) error {
+	outputInfo(cmd, "→ %s: running smoke test...\n", server.Name)
+	result := utils.RunSmokeTest(server)
+
+	for _, check := range result.Checks {
+		if check.Passed {
+			outputInfo(cmd, "  %s %s: %s\n", color.GreenString("✓"), server.Name, check.Name)
+		} else {
+			outputInfo(cmd, "  %s %s: %s (%s)\n", color.RedString("✗"), server.Name, check.Name, check.Detail)
+		}
+	}
+	if result.CleanupError != "" {
+		outputWarning(cmd, "%s: failed to remove smoke test vhost: %s", server.Name, result.CleanupError)
+	}
+
+	return result.Err()
+}
+
+// serverHealthCheckCmd represents the server health-check command
+var serverHealthCheckCmd = &cobra.Command{
+	Use:     "health-check [name]",
+	Aliases: []string{"check", "ping"},
+	Short:   "Check server connectivity and health",
+	Long: `Test SSH connectivity and verify services are running on a server.
+
+Examples:
+  # Check a specific server
+  wordsail server health-check myserver
+
+  # Interactively select a server to check
+  wordsail server health-check
+
+  # Keep checking SSH and services every 30 seconds until Ctrl-C
+  wordsail server health-check myserver --watch --interval 30s`,
+	Args:              cobra.MaximumNArgs(1),
+	ValidArgsFunction: completeServerNames(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		mgr, err := config.NewManager()
+		if err != nil {
+			color.Red("Error: %v", err)
+			os.Exit(1)
+		}
+
+		if !mgr.ConfigExists() {
+			color.Red("Configuration file not found. Run 'wordsail init' first.")
+			os.Exit(exit.ConfigNotFound)
+		}
+
+		cfg, err := mgr.Load()
+		if err != nil {
+			color.Red("Error: Failed to load configuration: %v", err)
+			os.Exit(exit.Validation)
+		}
+
+		watch, _ := cmd.Flags().GetBool("watch")
+		interval, _ := cmd.Flags().GetDuration("interval")
+		if watch && interval <= 0 {
+			color.Red("Error: --interval must be positive")
+			os.Exit(exit.Validation)
+		}
+		if watch && isJSONOutput(cmd) {
+			color.Red("Error: --watch cannot be combined with --json")
+			os.Exit(exit.Validation)
+		}
+
+		if len(cfg.Servers) == 0 {
+			fmt.Println("No servers configured.")
+			return
+		}
+
+		var serverName string
+
+		if len(args) == 0 {
+			// Interactive mode
+			options := make([]string, len(cfg.Servers))
+			for i, server := range cfg.Servers {
+				options[i] = fmt.Sprintf("%s (%s) - %s", server.Name, server.Address(), server.Status)
+			}
+
+			var selected int
+			selectPrompt := &survey.Select{
+				Message: "Select a server to check:",
+				Options: options,
+			}
+			if err := survey.AskOne(selectPrompt, &selected); err != nil {
+				os.Exit(1)
+			}
+			serverName = cfg.Servers[selected].Name
+		} else {
+			serverName = args[0]
+		}
+
+		// Find server
+		var targetServer *models.Server
+		for i := range cfg.Servers {
+			if cfg.Servers[i].Name == serverName {
+				targetServer = &cfg.Servers[i]
+				break
+			}
+		}
+
+		if targetServer == nil {
+			color.Red("Error: Server '%s' not found", serverName)
+			os.Exit(exit.Validation)
+		}
+
+		if watch {
+			watchServerHealth(*targetServer, sshTimeout(cmd), interval)
+			return
+		}
+
+		fmt.Printf("\nChecking server: %s (%s)\n\n", targetServer.Name, targetServer.Address())
+
+		// Test SSH connectivity
+		fmt.Print("SSH connectivity... ")
+		if err := utils.TestSSHConnection(*targetServer, sshTimeout(cmd)); err != nil {
+			color.Red("FAILED")
+			color.Red("  %v", err)
+			os.Exit(exit.SSH)
+		}
+		color.Green("OK")
+
+		fmt.Println()
+		color.Green("✓ Server '%s' is healthy", serverName)
+	},
+}
+
+// runHealthCheck tests SSH and, if it answers, the web stack services
+func runHealthCheck(server models.Server, timeout time.Duration) utils.HealthCheck {
+	check := utils.HealthCheck{At: time.Now()}
+	if check.SSHError = utils.TestSSHConnection(server, timeout); check.SSHError != nil {
+		return check
+	}
+	patterns := []string{"nginx", "php*-fpm", ansible.DatabaseServiceName(server.Database.Engine)}
+	check.Services, check.ServicesError = utils.CheckServices(server, patterns)
+	return check
+}
+
+// watchServerHealth re-runs the health check every interval, redrawing the
+// status each cycle, until interrupted. A summary is printed on exit.
+func watchServerHealth(server models.Server, timeout, interval time.Duration) {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	var history utils.HealthHistory
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		history.Record(runHealthCheck(server, timeout))
+		printHealthWatch(server, interval, &history)
+
+		select {
+		case <-ctx.Done():
+			printHealthSummary(server, &history)
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// printHealthWatch redraws the watch screen. The screen is only cleared on
+// a terminal (color output is disabled otherwise), so piped output keeps
+// every cycle.
+func printHealthWatch(server models.Server, interval time.Duration, history *utils.HealthHistory) {
+	if !color.NoColor {
+		fmt.Print("\033[H\033[2J")
+	} else {
+		fmt.Println()
+	}
+
+	check := history.Last
+	fmt.Printf("Watching server: %s (%s) every %s — Ctrl-C to stop\n", server.Name, server.Address(), interval)
+	fmt.Printf("Last check: %s\n\n", check.At.Format("2006-01-02 15:04:05"))
+
+	fmt.Print("SSH connectivity... ")
+	if check.SSHError != nil {
+		color.Red("FAILED")
+		color.Red("  %v", check.SSHError)
+	} else {
+		color.Green("OK")
+		switch {
+		case check.ServicesError != nil:
+			fmt.Printf("Services... %s\n", color.RedString("FAILED"))
+			color.Red("  %v", check.ServicesError)
+		default:
+			for _, service := range check.Services {
+				state := color.GreenString(service.State)
+				if !service.Active() {
+					state = color.RedString(service.State)
+				}
+				fmt.Printf("  %s: %s\n", service.Name, state)
+			}
+		}
+	}
+
+	fmt.Println()
+	status := color.GreenString("healthy")
+	if !check.Healthy() {
+		status = color.RedString("unhealthy")
+	}
+	fmt.Printf("Status: %s (%d/%d checks failed)\n", status, history.Failures, history.Checks)
+	if history.LastFailure != nil {
+		fmt.Printf("Last failure: %s\n", history.LastFailure.Format("2006-01-02 15:04:05"))
+	}
+}
+
+// printHealthSummary prints the totals for a watch session
+func printHealthSummary(server models.Server, history *utils.HealthHistory) {
+	fmt.Println()
+	fmt.Printf("Watched %s for %s: %d check(s), %d failed, %d state change(s)\n",
+		server.Name, time.Since(history.Started).Round(time.Second), history.Checks, history.Failures, history.Transitions)
+	if history.FirstFailure != nil {
+		fmt.Printf("Failures between %s and %s\n",
+			history.FirstFailure.Format("15:04:05"), history.LastFailure.Format("15:04:05"))
+	}
+	if history.Last.Healthy() {
+		color.Green("✓ Server '%s' is healthy", server.Name)
+	} else {
+		color.Red("✗ Server '%s' is unhealthy", server.Name)
+	}
+}
+
+// serverRebootCmd represents the server reboot command
+var serverRebootCmd = &cobra.Command{
+	Use:   "reboot [name]",
+	Short: "Reboot a server and wait for it to come back",
+	Long: `Reboot a server over SSH, wait until it accepts SSH connections again,
+and verify that Nginx, PHP-FPM and the database are running.
+
+The downtime between the server dropping off and SSH being reachable again
+is reported.
+
+Examples:
+  # Reboot a specific server (asks for confirmation)
+  wordsail server reboot myserver
+
+  # Reboot without confirmation, waiting up to 10 minutes
+  wordsail server reboot myserver --force --timeout 10m`,
+	Args:              cobra.MaximumNArgs(1),
+	ValidArgsFunction: completeServerNames(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		mgr, err := config.NewManager()
+		if err != nil {
+			fail(cmd, "Failed to create config manager", err)
+		}
+
+		if !mgr.ConfigExists() {
+			fail(cmd, "Configuration file not found", exit.Errorf(exit.ConfigNotFound, "run 'wordsail init' first"))
+		}
+
+		cfg, err := mgr.Load()
+		if err != nil {
+			fail(cmd, "Failed to load configuration", exit.New(exit.Validation, err))
+		}
+
+		if len(cfg.Servers) == 0 {
+			fail(cmd, "No servers configured", exit.Errorf(exit.Validation, "add a server first with: wordsail server add"))
+		}
+
+		var serverName string
+		if len(args) == 0 {
+			if isJSONOutput(cmd) {
+				fail(cmd, "Missing server name", exit.Errorf(exit.Validation, "server name is required in JSON mode"))
+			}
+
+			options := make([]string, len(cfg.Servers))
+			for i, server := range cfg.Servers {
+				options[i] = fmt.Sprintf("%s (%s) - %d sites", server.Name, server.Address(), len(server.Sites))
+			}
+
+			var selected int
+			selectPrompt := &survey.Select{
+				Message: "Select a server to reboot:",
+				Options: options,
+			}
+			if err := survey.AskOne(selectPrompt, &selected); err != nil {
+				os.Exit(1)
+			}
+			serverName = cfg.Servers[selected].Name
+		} else {
+			serverName = args[0]
+		}
+
+		targetServer := utils.FindServerByName(cfg.Servers, serverName)
+		if targetServer == nil {
+			fail(cmd, "Server not found", exit.Errorf(exit.Validation, "server '%s' not found", serverName))
+		}
+
+		timeout, _ := cmd.Flags().GetDuration("timeout")
+
+		if len(targetServer.Sites) > 0 {
+			domains := make([]string, len(targetServer.Sites))
+			for i, site := range targetServer.Sites {
+				domains[i] = site.PrimaryDomain
+			}
+			outputWarning(cmd, "⚠️  Server '%s' hosts %d site(s) that will be OFFLINE during the reboot: %s",
+				serverName, len(targetServer.Sites), strings.Join(domains, ", "))
+		}
+
+		if !assumeYes(cmd) {
+			if isJSONOutput(cmd) {
+				fail(cmd, "Confirmation required", exit.Errorf(exit.Validation, "use --force to reboot a server in JSON mode"))
+			}
+
+			confirm, err := prompt.Confirm(fmt.Sprintf("Reboot server '%s' (%s) now?", serverName, targetServer.Address()), false, false)
+			if err != nil || !confirm {
+				fmt.Println("Reboot cancelled")
+				return
+			}
+		}
+
+		probe := func() error { return utils.TestSSHConnection(*targetServer, utils.DefaultSSHTimeout) }
+
+		// Detach the reboot so the SSH session can close cleanly first
+		outputInfo(cmd, "→ Rebooting %s...\n", serverName)
+		if _, err := utils.RunSSHCommand(*targetServer, "sudo -n true && nohup sh -c 'sleep 2; sudo -n reboot' >/dev/null 2>&1 &"); err != nil {
+			fail(cmd, "Failed to issue reboot", err)
+		}
+
+		downAt, err := utils.WaitForReachability(probe, false, 2*time.Second, 2*time.Minute)
+		if err != nil {
+			fail(cmd, "Server did not go down", err)
+		}
+		outputInfo(cmd, "→ Server is down, waiting for SSH (timeout %s)...\n", timeout)
+
+		upAt, err := utils.WaitForReachability(probe, true, 5*time.Second, timeout)
+		if err != nil {
+			fail(cmd, "Server did not come back", err)
+		}
+		downtime := upAt.Sub(downAt).Round(time.Second)
+		outputInfo(cmd, "→ SSH is back after %s\n", downtime)
+
+		// Re-verify services
+		patterns := []string{"nginx", "php*-fpm", ansible.DatabaseServiceName(targetServer.Database.Engine)}
+		services, err := utils.CheckServices(*targetServer, patterns)
+		if err != nil {
+			fail(cmd, "Failed to check services", err)
+		}
+
+		allActive := true
+		serviceData := make(map[string]string, len(services))
+		for _, service := range services {
+			serviceData[service.Name] = service.State
+			if service.Active() {
+				outputInfo(cmd, "  %s: %s\n", service.Name, color.GreenString(service.State))
+			} else {
+				allActive = false
+				outputInfo(cmd, "  %s: %s\n", service.Name, color.RedString(service.State))
+			}
+		}
+
+		data := map[string]interface{}{
+			"name":             serverName,
+			"downtime_seconds": int(downtime.Seconds()),
+			"services":         serviceData,
+		}
+
+		if !allActive {
+			fail(cmd, "Server rebooted but some services are not running", fmt.Errorf("check the services listed above"))
+		}
+
+		outputSuccess(cmd, "server_rebooted", data)
+	},
+}
+
+// serverUpgradeCmd represents the server upgrade command
+var serverUpgradeCmd = &cobra.Command{
+	Use:   "upgrade [name]",
+	Short: "Apply OS package updates to a server",
+	Long: `Apply pending OS package updates (apt upgrade) to a server and report
+how many packages were upgraded and whether a reboot is required.
+
+Examples:
+  # Upgrade a specific server
+  wordsail server upgrade myserver
+
+  # Only apply security updates
+  wordsail server upgrade myserver --security-only
+
+  # Upgrade every configured server
+  wordsail server upgrade --all`,
+	Args:              cobra.MaximumNArgs(1),
+	ValidArgsFunction: completeServerNames(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		mgr, err := config.NewManager()
+		if err != nil {
+			fail(cmd, "Failed to create config manager", err)
+		}
+
+		if !mgr.ConfigExists() {
+			fail(cmd, "Configuration file not found", exit.Errorf(exit.ConfigNotFound, "run 'wordsail init' first"))
+		}
+
+		cfg, err := mgr.Load()
+		if err != nil {
+			fail(cmd, "Failed to load configuration", exit.New(exit.Validation, err))
+		}
+
+		if len(cfg.Servers) == 0 {
+			fail(cmd, "No servers configured", exit.Errorf(exit.Validation, "add a server first with: wordsail server add"))
+		}
+
+		all, _ := cmd.Flags().GetBool("all")
+		securityOnly, _ := cmd.Flags().GetBool("security-only")
+
+		var targets []models.Server
+		switch {
+		case all:
+			if len(args) > 0 {
+				fail(cmd, "Invalid arguments", exit.Errorf(exit.Validation, "--all cannot be combined with a server name"))
+			}
+			targets = cfg.Servers
+		case len(args) == 1:
+			server := utils.FindServerByName(cfg.Servers, args[0])
+			if server == nil {
+				fail(cmd, "Server not found", exit.Errorf(exit.Validation, "server '%s' not found", args[0]))
+			}
+			targets = []models.Server{*server}
+		default:
+			if isJSONOutput(cmd) {
+				fail(cmd, "Missing server name", exit.Errorf(exit.Validation, "server name or --all is required in JSON mode"))
+			}
+
+			options := make([]string, len(cfg.Servers))
+			for i, server := range cfg.Servers {
+				options[i] = fmt.Sprintf("%s (%s) - %s", server.Name, server.Address(), server.Status)
+			}
+
+			var selected int
+			selectPrompt := &survey.Select{
+				Message: "Select a server to upgrade:",
+				Options: options,
+			}
+			if err := survey.AskOne(selectPrompt, &selected); err != nil {
+				os.Exit(1)
+			}
+			targets = []models.Server{cfg.Servers[selected]}
+		}
+
+		executor := newExecutor(cmd, cfg)
+		extraVars := map[string]interface{}{
+			"security_only": securityOnly,
+		}
+
+		totalUpgraded := 0
+		var failed, rebootRequired []string
+		results := make([]map[string]interface{}, 0, len(targets))
+
+		// Keep going after a failure so one broken server doesn't block the rest
+		for _, server := range targets {
+			outputBanner(cmd, color.Cyan, fmt.Sprintf("Upgrading packages: %s", server.Name))
+
+			result, err := executor.ExecutePlaybookWithResult("playbooks/upgrade_packages.yml", server, extraVars, cfg.GlobalVars)
+			if err != nil {
+				outputWarning(cmd, "✗ %s: upgrade failed: %v", server.Name, err)
+				failed = append(failed, server.Name)
+				results = append(results, map[string]interface{}{
+					"name":    server.Name,
+					"success": false,
+					"error":   err.Error(),
+				})
+				continue
+			}
+
+			upgrade := result.Upgrade
+			if upgrade == nil {
+				upgrade = &ansible.UpgradeInfo{}
+			}
+			totalUpgraded += upgrade.Count
+			if upgrade.RebootRequired {
+				rebootRequired = append(rebootRequired, server.Name)
+			}
+
+			summary := fmt.Sprintf("  %s: %d package(s) upgraded", server.Name, upgrade.Count)
+			if upgrade.RebootRequired {
+				summary += color.YellowString(" (reboot required)")
+			}
+			outputInfo(cmd, "%s\n", summary)
+
+			results = append(results, map[string]interface{}{
+				"name":            server.Name,
+				"success":         true,
+				"upgraded":        upgrade.Count,
+				"reboot_required": upgrade.RebootRequired,
+			})
+		}
+
+		if len(targets) > 1 {
+			outputInfo(cmd, "\nTotal: %d package(s) upgraded across %d server(s)\n", totalUpgraded, len(targets)-len(failed))
+		}
+		for _, name := range rebootRequired {
+			outputInfo(cmd, "→ %s needs a reboot: wordsail server reboot %s\n", name, name)
+		}
+
+		if len(failed) > 0 {
+			fail(cmd, "Upgrade failed on some servers", fmt.Errorf("%s", strings.Join(failed, ", ")))
+		}
+
+		outputSuccess(cmd, "servers_upgraded", map[string]interface{}{
+			"servers":         results,
+			"total_upgraded":  totalUpgraded,
+			"reboot_required": rebootRequired,
+			"security_only":   securityOnly,
+		})
+	},
+}
+
+// serverSSHCmd represents the server ssh command
+var serverSSHCmd = &cobra.Command{
+	Use:   "ssh <name>",
+	Short: "Open a shell on a server",
+	Long: `Open an interactive shell on a server with the system ssh client, using the
+user, port, key and jump host stored in the configuration. With --site the
+shell starts in the site's directory (/sites/<domain>).
+
+The connection is checked first, so an unreachable server fails with a clear
+error instead of a hanging ssh. The shell's exit code is passed on.
+
+Examples:
+  wordsail server ssh production-1
+  wordsail server ssh production-1 --site mysite`,
+	Args:              cobra.ExactArgs(1),
+	ValidArgsFunction: completeServerNames(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		mgr, err := config.NewManager()
+		if err != nil {
+			fail(cmd, "Failed to create config manager", err)
+		}
+
+		if !mgr.ConfigExists() {
+			fail(cmd, "Configuration file not found", exit.Errorf(exit.ConfigNotFound, "run 'wordsail init' first"))
+		}
+
+		cfg, err := mgr.Load()
+		if err != nil {
+			fail(cmd, "Failed to load configuration", exit.New(exit.Validation, err))
+		}
 
-		// Test SSH connectivity
-		fmt.Print("SSH connectivity... ")
-		if err := utils.TestSSHConnection(*targetServer); err != nil {
-			color.Red("FAILED")
-			color.Red("  %v", err)
-			os.Exit(1)
+		server := utils.FindServerByName(cfg.Servers, args[0])
+		if server == nil {
+			fail(cmd, "Server not found", exit.Errorf(exit.Validation, "server '%s' does not exist", args[0]))
+		}
+
+		var site *models.Site
+		if siteID, _ := cmd.Flags().GetString("site"); siteID != "" {
+			site, err = utils.ResolveSite(server, siteID)
+			if err != nil {
+				fail(cmd, "Site not found", exit.New(exit.Validation, err))
+			}
+		}
+
+		sshArgs, err := utils.SSHShellArgs(*server, site)
+		if err != nil {
+			fail(cmd, "Invalid SSH configuration", exit.New(exit.Validation, err))
+		}
+		sshPath, err := exec.LookPath("ssh")
+		if err != nil {
+			fail(cmd, "ssh client not found", exit.Errorf(exit.Validation, "install OpenSSH to use 'server ssh'"))
+		}
+
+		// Also records the host key, so ssh doesn't ask about it
+		if err := utils.TestSSHConnection(*server, utils.DefaultSSHTimeout); err != nil {
+			fail(cmd, fmt.Sprintf("Server '%s' is not reachable at %s", server.Name, server.SSHAddress()), err)
+		}
+
+		shell := exec.Command(sshPath, sshArgs...)
+		shell.Stdin = os.Stdin
+		shell.Stdout = os.Stdout
+		shell.Stderr = os.Stderr
+		if err := shell.Run(); err != nil {
+			var exitErr *exec.ExitError
+			if errors.As(err, &exitErr) {
+				os.Exit(exitErr.ExitCode())
+			}
+			fail(cmd, "Failed to run ssh", exit.New(exit.SSH, err))
+		}
+	},
+}
+
+// serverShowCmd represents the server show command
+var serverShowCmd = &cobra.Command{
+	Use:   "show <name>",
+	Short: "Show server details",
+	Long: `Display the stored configuration for a server, including its database engine.
+
+Examples:
+  wordsail server show myserver
+  wordsail server show myserver --json
+  wordsail server show myserver --output yaml`,
+	Annotations:       map[string]string{yamlAnnotation: ""},
+	Args:              cobra.ExactArgs(1),
+	ValidArgsFunction: completeServerNames(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		mgr, err := config.NewManager()
+		if err != nil {
+			fail(cmd, "Failed to create config manager", err)
+		}
+
+		if !mgr.ConfigExists() {
+			fail(cmd, "Configuration file not found", exit.Errorf(exit.ConfigNotFound, "run 'wordsail init' first"))
+		}
+
+		cfg, err := mgr.Load()
+		if err != nil {
+			fail(cmd, "Failed to load configuration", exit.New(exit.Validation, err))
+		}
+
+		server := utils.FindServerByName(cfg.Servers, args[0])
+		if server == nil {
+			fail(cmd, "Server not found", exit.Errorf(exit.Validation, "server '%s' does not exist", args[0]))
+		}
+
+		if isJSONOutput(cmd) || isYAMLOutput() {
+			shown := *server
+			shown.Sites = make([]models.Site, len(server.Sites))
+			for i, site := range server.Sites {
+				shown.Sites[i] = withoutSecrets(site)
+			}
+			printObject(cmd, shown)
+			return
+		}
+
+		provisionedAt := "-"
+		if server.ProvisionedAt != nil {
+			provisionedAt = server.ProvisionedAt.Format("2006-01-02 15:04")
+		}
+
+		database := "-"
+		if server.Database.Engine != "" {
+			database = describeDatabaseEngine(server.Database.Engine, server.Database.Version)
 		}
-		color.Green("OK")
 
 		fmt.Println()
-		color.Green("✓ Server '%s' is healthy", serverName)
+		fmt.Printf("Name:         %s\n", server.Name)
+		fmt.Printf("Hostname:     %s\n", server.Hostname)
+		if server.IP != "" {
+			fmt.Printf("IP:           %s\n", server.IP)
+		}
+		if server.IPv6 != "" {
+			fmt.Printf("IPv6:         %s\n", server.IPv6)
+		}
+		sshAuth := "key: " + server.SSH.KeyFile
+		if server.SSH.UseAgent || server.SSH.KeyFile == "" {
+			sshAuth = "ssh-agent"
+		}
+		fmt.Printf("SSH:          %s@%s (%s)\n", server.SSH.User, server.SSHAddress(), sshAuth)
+		if server.SSH.HasJumpHost() {
+			fmt.Printf("Jump host:    %s@%s\n", server.SSH.JumpLogin(), server.SSH.JumpAddress())
+		}
+		fmt.Printf("Status:       %s\n", server.Status)
+		fmt.Printf("Provisioned:  %s\n", provisionedAt)
+		fmt.Printf("Database:     %s\n", database)
+		if server.PythonInterpreter != "" {
+			fmt.Printf("Python:       %s\n", server.PythonInterpreter)
+		}
+		fmt.Printf("Sites:        %d\n", len(server.Sites))
+		fmt.Println()
 	},
 }
 
+// serverUpdateFlags are the flags that switch server update to non-interactive mode
+var serverUpdateFlags = []string{"name", "ip", "ssh-key", "ssh-user", "ssh-port", "use-agent", "jump-host", "jump-user", "jump-port", "python-interpreter"}
+
+// serverUpdateFlagsChanged reports whether any server update flag was given
+func serverUpdateFlagsChanged(cmd *cobra.Command) bool {
+	for _, name := range serverUpdateFlags {
+		if cmd.Flags().Changed(name) {
+			return true
+		}
+	}
+	return false
+}
+
+// applyServerUpdateFlags applies the given server update flags to server
+func applyServerUpdateFlags(cmd *cobra.Command, servers []models.Server, server *models.Server) error {
+	if cmd.Flags().Changed("name") {
+		newName, _ := cmd.Flags().GetString("name")
+		if newName == "" {
+			return fmt.Errorf("--name cannot be empty")
+		}
+		if newName != server.Name {
+			if err := utils.ValidateServerName(newName); err != nil {
+				return err
+			}
+			if utils.FindServerByName(servers, newName) != nil {
+				return fmt.Errorf("server with name '%s' already exists", newName)
+			}
+		}
+		server.Name = newName
+	}
+	if cmd.Flags().Changed("ip") {
+		newIP, _ := cmd.Flags().GetString("ip")
+		if err := utils.ValidateHostnameOrIP(newIP); err != nil {
+			return err
+		}
+		server.SetAddress(newIP)
+	}
+	if cmd.Flags().Changed("ssh-key") {
+		server.SSH.KeyFile, _ = cmd.Flags().GetString("ssh-key")
+	}
+	if cmd.Flags().Changed("ssh-user") {
+		server.SSH.User, _ = cmd.Flags().GetString("ssh-user")
+	}
+	if cmd.Flags().Changed("ssh-port") {
+		port, _ := cmd.Flags().GetInt("ssh-port")
+		if err := utils.ValidatePort(port); err != nil {
+			return err
+		}
+		server.SSH.Port = port
+	}
+	if cmd.Flags().Changed("python-interpreter") {
+		interpreter, _ := cmd.Flags().GetString("python-interpreter")
+		if interpreter != "" {
+			if err := utils.ValidatePythonInterpreter(interpreter); err != nil {
+				return err
+			}
+		}
+		server.PythonInterpreter = interpreter
+	}
+	if err := applySSHFlags(cmd, &server.SSH); err != nil {
+		return err
+	}
+	if cmd.Flags().Changed("ssh-key") || cmd.Flags().Changed("use-agent") {
+		return checkSSHKey(cmd, server.SSH)
+	}
+	return nil
+}
+
+// applySSHFlags applies the --use-agent and jump host flags to the SSH config
+func applySSHFlags(cmd *cobra.Command, sshCfg *models.SSHConfig) error {
+	if cmd.Flags().Changed("use-agent") {
+		sshCfg.UseAgent, _ = cmd.Flags().GetBool("use-agent")
+	}
+	if sshCfg.KeyFile == "" && !sshCfg.UseAgent {
+		return fmt.Errorf("an SSH key file or --use-agent is required")
+	}
+	return applyJumpHostFlags(cmd, sshCfg)
+}
+
+// checkSSHKey fails early when the configured key file does not exist, and
+// warns when its permissions would make SSH refuse it. Agent-only configs
+// are not checked.
+func checkSSHKey(cmd *cobra.Command, sshCfg models.SSHConfig) error {
+	if sshCfg.UseAgent || sshCfg.KeyFile == "" {
+		return nil
+	}
+	warning, err := utils.CheckSSHKeyFile(sshCfg.KeyFile)
+	if err != nil {
+		return err
+	}
+	if warning != "" {
+		outputWarning(cmd, "%s\n", warning)
+	}
+	return nil
+}
+
+// applyJumpHostFlags copies any --jump-host/--jump-user/--jump-port flags that
+// were set onto the SSH config. An empty --jump-host removes the jump host.
+func applyJumpHostFlags(cmd *cobra.Command, sshCfg *models.SSHConfig) error {
+	if cmd.Flags().Changed("jump-host") {
+		jumpHost, _ := cmd.Flags().GetString("jump-host")
+		if strings.ContainsAny(jumpHost, " @/") || (strings.Contains(jumpHost, ":") && utils.ValidateIP(jumpHost) != nil) {
+			return fmt.Errorf("--jump-host must be a hostname or IP address; use --jump-user and --jump-port for the user and port")
+		}
+		sshCfg.JumpHost = jumpHost
+		if jumpHost == "" {
+			sshCfg.JumpUser = ""
+			sshCfg.JumpPort = 0
+		}
+	}
+	if cmd.Flags().Changed("jump-user") {
+		sshCfg.JumpUser, _ = cmd.Flags().GetString("jump-user")
+	}
+	if cmd.Flags().Changed("jump-port") {
+		jumpPort, _ := cmd.Flags().GetInt("jump-port")
+		if err := utils.ValidatePort(jumpPort); err != nil {
+			return err
+		}
+		sshCfg.JumpPort = jumpPort
+	}
+
+	if !sshCfg.HasJumpHost() && (sshCfg.JumpUser != "" || sshCfg.JumpPort != 0) {
+		return fmt.Errorf("--jump-user and --jump-port require --jump-host")
+	}
+	return nil
+}
+
+// ipv6Flag returns the --ipv6 value in canonical form, or an error if it is
+// not a valid IPv6 address
+func ipv6Flag(cmd *cobra.Command) (string, error) {
+	ipv6, _ := cmd.Flags().GetString("ipv6")
+	if ipv6 == "" {
+		return "", nil
+	}
+	if err := utils.ValidateIPv6(ipv6); err != nil {
+		return "", err
+	}
+	return utils.NormalizeIPv6(ipv6), nil
+}
+
+// describeDatabaseEngine returns a human-readable engine/version label
+func describeDatabaseEngine(engine, version string) string {
+	name := "MariaDB"
+	if engine == ansible.DatabaseEngineMySQL {
+		name = "MySQL"
+	}
+	if version == "" {
+		return name + " (distribution package)"
+	}
+	return fmt.Sprintf("%s %s", name, version)
+}
+
 // serverUpdateCmd represents the server update command
 var serverUpdateCmd = &cobra.Command{
 	Use:   "update [name]",
@@ -729,8 +2060,18 @@ Examples:
   wordsail server update myserver
 
   # Interactively select a server to update
-  wordsail server update`,
-	Args: cobra.MaximumNArgs(1),
+  wordsail server update
+
+  # Update specific fields without prompting
+  wordsail server update myserver --ssh-port 2222
+
+  # Route SSH through a bastion (or remove it with --jump-host "")
+  wordsail server update myserver --jump-host bastion.example.com --jump-user ops
+
+  # Use another Python on this server (or the config default with "")
+  wordsail server update myserver --python-interpreter /usr/bin/python3.11`,
+	Args:              cobra.MaximumNArgs(1),
+	ValidArgsFunction: completeServerNames(1),
 	Run: func(cmd *cobra.Command, args []string) {
 		mgr, err := config.NewManager()
 		if err != nil {
@@ -740,13 +2081,13 @@ Examples:
 
 		if !mgr.ConfigExists() {
 			color.Red("Configuration file not found. Run 'wordsail init' first.")
-			os.Exit(1)
+			os.Exit(exit.ConfigNotFound)
 		}
 
 		cfg, err := mgr.Load()
 		if err != nil {
 			color.Red("Error: Failed to load configuration: %v", err)
-			os.Exit(1)
+			os.Exit(exit.Validation)
 		}
 
 		if len(cfg.Servers) == 0 {
@@ -760,7 +2101,7 @@ Examples:
 			// Interactive mode
 			options := make([]string, len(cfg.Servers))
 			for i, server := range cfg.Servers {
-				options[i] = fmt.Sprintf("%s (%s)", server.Name, server.IP)
+				options[i] = fmt.Sprintf("%s (%s)", server.Name, server.Address())
 			}
 
 			var selected int
@@ -787,11 +2128,26 @@ Examples:
 
 		if serverIndex == -1 {
 			color.Red("Error: Server '%s' not found", serverName)
-			os.Exit(1)
+			os.Exit(exit.Validation)
 		}
 
 		server := &cfg.Servers[serverIndex]
 
+		// Non-interactive mode: apply only the flags that were given
+		if serverUpdateFlagsChanged(cmd) {
+			if err := applyServerUpdateFlags(cmd, cfg.Servers, server); err != nil {
+				color.Red("Error: %v", err)
+				os.Exit(exit.Validation)
+			}
+			if err := mgr.Save(cfg); err != nil {
+				color.Red("Error: Failed to save configuration: %v", err)
+				os.Exit(1)
+			}
+			renamePendingServer(cmd, mgr, serverName, server.Name)
+			color.Green("✓ Server '%s' updated successfully", server.Name)
+			return
+		}
+
 		fmt.Printf("\nUpdating server: %s\n", server.Name)
 		fmt.Println("Leave blank to keep current value.")
 
@@ -801,7 +2157,12 @@ Examples:
 			Message: "Server name:",
 			Default: server.Name,
 		}
-		if err := survey.AskOne(namePrompt, &newName); err != nil {
+		if err := survey.AskOne(namePrompt, &newName, survey.WithValidator(func(val interface{}) error {
+			if val == server.Name {
+				return nil
+			}
+			return utils.ValidateServerName(val)
+		})); err != nil {
 			os.Exit(1)
 		}
 
@@ -810,17 +2171,17 @@ Examples:
 			for _, s := range cfg.Servers {
 				if s.Name == newName {
 					color.Red("Error: Server with name '%s' already exists", newName)
-					os.Exit(1)
+					os.Exit(exit.Validation)
 				}
 			}
 		}
 
 		var newIP string
 		ipPrompt := &survey.Input{
-			Message: "IP address:",
-			Default: server.IP,
+			Message: "IP address or hostname:",
+			Default: server.Address(),
 		}
-		if err := survey.AskOne(ipPrompt, &newIP, survey.WithValidator(utils.ValidateIP)); err != nil {
+		if err := survey.AskOne(ipPrompt, &newIP, survey.WithValidator(utils.ValidateHostnameOrIP)); err != nil {
 			os.Exit(1)
 		}
 
@@ -866,11 +2227,8 @@ Examples:
 		fmt.Printf("  SSH Port: %d\n", port)
 		fmt.Println()
 
-		var confirm bool
-		if err := survey.AskOne(&survey.Confirm{
-			Message: "Save changes?",
-			Default: true,
-		}, &confirm); err != nil {
+		confirm, err := prompt.Confirm("Save changes?", true, false)
+		if err != nil {
 			os.Exit(1)
 		}
 
@@ -881,8 +2239,7 @@ Examples:
 
 		// Apply changes
 		server.Name = newName
-		server.Hostname = newIP
-		server.IP = newIP
+		server.SetAddress(newIP)
 		server.SSH.User = newSSHUser
 		server.SSH.KeyFile = newSSHKey
 		server.SSH.Port = port
@@ -893,10 +2250,429 @@ Examples:
 			os.Exit(1)
 		}
 
+		renamePendingServer(cmd, mgr, serverName, newName)
 		color.Green("✓ Server '%s' updated successfully", newName)
 	},
 }
 
+// serverRenameCmd represents the server rename command
+var serverRenameCmd = &cobra.Command{
+	Use:   "rename <old-name> <new-name>",
+	Short: "Rename a server",
+	Long: `Rename a server in the inventory. The new name must be unique and
+hostname-safe (letters, digits and hyphens). Pending changes recorded for the
+server are moved to the new name. Nothing changes on the server itself.
+
+Examples:
+  wordsail server rename web1 web-prod-1`,
+	Args:              cobra.ExactArgs(2),
+	ValidArgsFunction: completeServerNames(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		oldName, newName := args[0], args[1]
+
+		mgr, err := config.NewManager()
+		if err != nil {
+			fail(cmd, "Failed to create config manager", err)
+		}
+
+		if !mgr.ConfigExists() {
+			fail(cmd, "Configuration file not found", exit.Errorf(exit.ConfigNotFound, "run 'wordsail init' first"))
+		}
+
+		if oldName == newName {
+			fail(cmd, "Nothing to rename", exit.Errorf(exit.Validation, "server is already named '%s'", newName))
+		}
+
+		stateMgr := state.NewManager(mgr)
+		if err := stateMgr.RenameServer(oldName, newName); err != nil {
+			fail(cmd, "Failed to rename server", err)
+		}
+		renamePendingServer(cmd, mgr, oldName, newName)
+
+		outputSuccess(cmd, "server_renamed", map[string]interface{}{
+			"old_name": oldName,
+			"new_name": newName,
+		})
+	},
+}
+
+// renamePendingServer moves pending state changes to a server's new name
+func renamePendingServer(cmd *cobra.Command, mgr *config.Manager, oldName, newName string) {
+	if oldName == newName {
+		return
+	}
+	if err := state.RenamePendingServer(pendingPath(mgr), oldName, newName); err != nil {
+		outputWarning(cmd, "Failed to update pending changes for '%s': %v", oldName, err)
+	}
+}
+
+// serverAdoptCmd imports the sites of a server set up outside WordSail
+var serverAdoptCmd = &cobra.Command{
+	Use:   "adopt <name>",
+	Short: "Import the existing sites of a server into the inventory",
+	Long: `Scan a server in the inventory for sites that WordSail does not track yet
+and add them to the config. Sites are found from the directories under /sites,
+the enabled Nginx vhosts (server names, document root and PHP-FPM socket),
+wp-config.php (database name, user and host) and the Let's Encrypt
+certificates. The first administrator of each WordPress site is looked up
+with WP-CLI.
+
+Nothing is changed on the server. Sites whose ID or domain is already in the
+inventory, or whose files are not owned by a dedicated site user, are skipped.
+
+Examples:
+  # Only print what would be imported
+  wordsail server adopt legacy-1 --discover
+
+  # Import after confirmation
+  wordsail server adopt legacy-1`,
+	Args:              cobra.ExactArgs(1),
+	ValidArgsFunction: completeServerNames(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		mgr, err := config.NewManager()
+		if err != nil {
+			fail(cmd, "Failed to create config manager", err)
+		}
+
+		if !mgr.ConfigExists() {
+			fail(cmd, "Configuration file not found", exit.Errorf(exit.ConfigNotFound, "run 'wordsail init' first"))
+		}
+
+		cfg, err := mgr.Load()
+		if err != nil {
+			fail(cmd, "Failed to load configuration", exit.New(exit.Validation, err))
+		}
+
+		server := utils.FindServerByName(cfg.Servers, args[0])
+		if server == nil {
+			fail(cmd, "Server not found", exit.Errorf(exit.Validation, "server '%s' does not exist", args[0]))
+		}
+
+		discoverOnly, _ := cmd.Flags().GetBool("discover")
+		if !discoverOnly && isJSONOutput(cmd) && !assumeYes(cmd) {
+			fail(cmd, "Confirmation required", exit.Errorf(exit.Validation, "--force is required with --json"))
+		}
+
+		outputInfo(cmd, "Scanning %s for sites...\n", server.Name)
+		discovered, err := utils.DiscoverSites(*server)
+		if err != nil {
+			fail(cmd, "Failed to discover sites", exit.New(exit.SSH, err))
+		}
+
+		var adoptable []utils.DiscoveredSite
+		skipped := map[string]string{}
+		for _, site := range discovered {
+			if reason := adoptSkipReason(cfg.Servers, site); reason != "" {
+				skipped[site.PrimaryDomain] = reason
+				continue
+			}
+			if site.WordPress {
+				record := site.Site(time.Now())
+				if site.AdminUser, site.AdminEmail, err = utils.DiscoverAdmin(*server, record); err != nil {
+					outputWarning(cmd, "%s: could not look up the WordPress admin: %v", site.PrimaryDomain, err)
+				}
+			}
+			adoptable = append(adoptable, site)
+		}
+
+		if discoverOnly {
+			if isJSONOutput(cmd) {
+				output, err := json.MarshalIndent(map[string]interface{}{
+					"server":     server.Name,
+					"discovered": adoptable,
+					"skipped":    skipped,
+				}, "", "  ")
+				if err != nil {
+					fail(cmd, "Failed to marshal JSON", err)
+				}
+				fmt.Println(string(output))
+				return
+			}
+			printDiscoveredSites(adoptable, skipped)
+			return
+		}
+
+		if !isJSONOutput(cmd) {
+			printDiscoveredSites(adoptable, skipped)
+		}
+		if len(adoptable) == 0 {
+			outputSuccess(cmd, "server_adopted", map[string]interface{}{
+				"name":    server.Name,
+				"count":   0,
+				"sites":   []string{},
+				"skipped": skipped,
+			})
+			return
+		}
+
+		if !assumeYes(cmd) {
+			confirm, err := prompt.Confirm(fmt.Sprintf("Add %d site(s) to server '%s' in the inventory?", len(adoptable), server.Name), true, false)
+			if err != nil {
+				os.Exit(1)
+			}
+			if !confirm {
+				fmt.Println("Adoption cancelled")
+				return
+			}
+		}
+
+		stateMgr := state.NewManager(mgr)
+		now := time.Now()
+		adopted := make([]string, 0, len(adoptable))
+		for _, discoveredSite := range adoptable {
+			site := discoveredSite.Site(now)
+			writeState(cmd, mgr, state.PendingChange{
+				Kind:   state.PendingAddSite,
+				Server: server.Name,
+				SiteID: site.SiteID,
+				Site:   &site,
+			}, func() error {
+				return stateMgr.AddSiteToServer(server.Name, site)
+			})
+			adopted = append(adopted, site.SiteID)
+		}
+
+		outputSuccess(cmd, "server_adopted", map[string]interface{}{
+			"name":    server.Name,
+			"count":   len(adopted),
+			"sites":   adopted,
+			"skipped": skipped,
+		})
+	},
+}
+
+// adoptSkipReason explains why a discovered site can't be adopted, or
+// returns "" if it can
+func adoptSkipReason(servers []models.Server, site utils.DiscoveredSite) string {
+	if err := utils.ValidateSiteID(site.SiteID); err != nil {
+		return fmt.Sprintf("files are owned by '%s', not a usable site user (%v)", site.SiteID, err)
+	}
+	for _, server := range servers {
+		if utils.FindSiteBySiteID(&server, site.SiteID) != nil {
+			return fmt.Sprintf("site ID '%s' is already used on server '%s'", site.SiteID, server.Name)
+		}
+	}
+	for _, domain := range site.Domains {
+		if server, existing := utils.FindSiteByDomainAcrossServers(servers, domain); existing != nil {
+			return fmt.Sprintf("%s is already used by site '%s' on server '%s'", domain, existing.SiteID, server.Name)
+		}
+	}
+	return ""
+}
+
+// printDiscoveredSites lists the sites found by server adopt
+func printDiscoveredSites(sites []utils.DiscoveredSite, skipped map[string]string) {
+	fmt.Println()
+	if len(sites) == 0 {
+		fmt.Println("No new sites found.")
+	}
+	for _, site := range sites {
+		kind := "WordPress"
+		if !site.WordPress {
+			kind = "bare site"
+		}
+		fmt.Printf("%s (%s, %s)\n", color.CyanString(site.PrimaryDomain), site.SiteID, kind)
+		fmt.Printf("  Domains:  %s\n", strings.Join(site.Domains, ", "))
+		php := site.PHPVersion
+		if php == "" {
+			php = "unknown"
+		}
+		fmt.Printf("  PHP:      %s\n", php)
+		if site.WordPress {
+			fmt.Printf("  Database: %s (user %s, host %s)\n", site.DBName, site.DBUser, site.DBHost)
+			if site.AdminUser != "" {
+				fmt.Printf("  Admin:    %s <%s>\n", site.AdminUser, site.AdminEmail)
+			}
+		}
+		for _, domain := range site.Domains {
+			if expiresAt, ok := site.CertExpiry[domain]; ok {
+				fmt.Printf("  SSL:      %s (expires %s)\n", domain, expiresAt.Format("2006-01-02"))
+			}
+		}
+	}
+	if len(skipped) > 0 {
+		domains := make([]string, 0, len(skipped))
+		for domain := range skipped {
+			domains = append(domains, domain)
+		}
+		sort.Strings(domains)
+		fmt.Println()
+		for _, domain := range domains {
+			fmt.Printf("%s %s skipped: %s\n", color.YellowString("⚠"), domain, skipped[domain])
+		}
+	}
+	fmt.Println()
+}
+
+// serverHistoryCmd shows recent provisioning runs
+var serverHistoryCmd = &cobra.Command{
+	Use:   "history <name>",
+	Short: "Show recent provisioning runs of a server",
+	Long: fmt.Sprintf(`Show the last %d provisioning runs of a server, newest first, with
+their recap counts and the log file holding the playbook output. The log
+of an older run is deleted when the run drops out of the history.
+
+Examples:
+  wordsail server history myserver
+  wordsail server history myserver --json`, state.MaxProvisionHistory),
+	Args:              cobra.ExactArgs(1),
+	ValidArgsFunction: completeServerNames(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		mgr, err := config.NewManager()
+		if err != nil {
+			fail(cmd, "Failed to create config manager", err)
+		}
+
+		if !mgr.ConfigExists() {
+			fail(cmd, "Configuration file not found", exit.Errorf(exit.ConfigNotFound, "run 'wordsail init' first"))
+		}
+
+		cfg, err := mgr.Load()
+		if err != nil {
+			fail(cmd, "Failed to load configuration", exit.New(exit.Validation, err))
+		}
+
+		server := utils.FindServerByName(cfg.Servers, args[0])
+		if server == nil {
+			fail(cmd, "Server not found", exit.Errorf(exit.Validation, "server '%s' does not exist", args[0]))
+		}
+
+		runs := make([]models.ProvisionRun, 0, len(server.ProvisionHistory))
+		for i := len(server.ProvisionHistory) - 1; i >= 0; i-- {
+			runs = append(runs, server.ProvisionHistory[i])
+		}
+
+		if isJSONOutput(cmd) {
+			output, err := json.MarshalIndent(runs, "", "  ")
+			if err != nil {
+				fail(cmd, "Failed to marshal JSON", err)
+			}
+			fmt.Println(string(output))
+			return
+		}
+
+		if len(runs) == 0 {
+			fmt.Printf("No provisioning runs recorded for '%s'\n", server.Name)
+			return
+		}
+
+		fmt.Printf("\nProvisioning runs of '%s':\n\n", server.Name)
+		headers := []string{"STARTED", "RESULT", "OK", "CHANGED", "FAILED", "DURATION", "LOG"}
+		rows := make([][]string, 0, len(runs))
+		for _, run := range runs {
+			result := color.GreenString("success")
+			if !run.Success {
+				result = color.RedString("failed")
+			}
+			logPath := run.LogPath
+			if logPath == "" {
+				logPath = "-"
+			}
+			rows = append(rows, []string{
+				run.StartedAt.Local().Format("2006-01-02 15:04"),
+				result,
+				fmt.Sprintf("%d", run.Ok),
+				fmt.Sprintf("%d", run.Changed),
+				fmt.Sprintf("%d", run.Failed),
+				(time.Duration(run.DurationSeconds) * time.Second).String(),
+				logPath,
+			})
+		}
+		utils.PrintTable(headers, rows)
+
+		if latest := runs[0]; !latest.Success && latest.Error != "" {
+			fmt.Println()
+			color.Red("Last run failed: %s", latest.Error)
+		}
+		fmt.Println()
+	},
+}
+
+// serverExportInventoryCmd writes a static Ansible inventory
+var serverExportInventoryCmd = &cobra.Command{
+	Use:   "export-inventory [name...]",
+	Short: "Write an Ansible inventory for your servers",
+	Long: `Write a static Ansible inventory with the connection settings and global
+vars wordsail uses, for running your own ad-hoc Ansible against the servers.
+All servers are included unless names are given.
+
+Global vars that look like credentials (passwords, secrets, tokens and API
+keys) are written as REDACTED unless --include-secrets is given.
+
+Examples:
+  wordsail server export-inventory --file hosts.ini
+  ansible -i hosts.ini webservers -m ping
+
+  wordsail server export-inventory web1 web2 --format yaml --file hosts.yml`,
+	ValidArgsFunction: completeServerNames(0),
+	Run: func(cmd *cobra.Command, args []string) {
+		mgr, err := config.NewManager()
+		if err != nil {
+			fail(cmd, "Failed to create config manager", err)
+		}
+
+		if !mgr.ConfigExists() {
+			fail(cmd, "Configuration file not found", exit.Errorf(exit.ConfigNotFound, "run 'wordsail init' first"))
+		}
+
+		cfg, err := mgr.Load()
+		if err != nil {
+			fail(cmd, "Failed to load configuration", exit.New(exit.Validation, err))
+		}
+
+		servers := cfg.Servers
+		if len(args) > 0 {
+			servers = make([]models.Server, 0, len(args))
+			for _, name := range args {
+				server := utils.FindServerByName(cfg.Servers, name)
+				if server == nil {
+					fail(cmd, "Server not found", exit.Errorf(exit.Validation, "server '%s' does not exist", name))
+				}
+				servers = append(servers, *server)
+			}
+		}
+		if len(servers) == 0 {
+			fail(cmd, "No servers to export", exit.Errorf(exit.Validation, "add a server with 'wordsail server add' first"))
+		}
+
+		format, _ := cmd.Flags().GetString("format")
+		includeSecrets, _ := cmd.Flags().GetBool("include-secrets")
+		filePath, _ := cmd.Flags().GetString("file")
+
+		var buf bytes.Buffer
+		opts := ansible.ExportOptions{Format: format, IncludeSecrets: includeSecrets}
+		generator := ansible.NewInventoryGenerator()
+		generator.SetPythonInterpreter(cfg.Ansible.PythonInterpreter)
+		if err := generator.Export(&buf, servers, cfg.GlobalVars, opts); err != nil {
+			fail(cmd, "Failed to export inventory", exit.New(exit.Validation, err))
+		}
+
+		if filePath == "" {
+			fmt.Print(buf.String())
+			return
+		}
+
+		path, err := utils.ExpandPath(filePath)
+		if err != nil {
+			fail(cmd, "Invalid output path", exit.New(exit.Validation, err))
+		}
+		// Secrets stay readable by the owner only
+		perm := os.FileMode(0644)
+		if includeSecrets {
+			perm = 0600
+		}
+		if err := os.WriteFile(path, buf.Bytes(), perm); err != nil {
+			fail(cmd, "Failed to write inventory", err)
+		}
+
+		outputSuccess(cmd, "inventory_exported", map[string]interface{}{
+			"path":    path,
+			"format":  opts.Format,
+			"servers": len(servers),
+		})
+	},
+}
+
 func init() {
 	rootCmd.AddCommand(serverCmd)
 	serverCmd.AddCommand(serverAddCmd)
@@ -904,42 +2680,110 @@ func init() {
 	serverCmd.AddCommand(serverRemoveCmd)
 	serverCmd.AddCommand(serverProvisionCmd)
 	serverCmd.AddCommand(serverHealthCheckCmd)
+	serverCmd.AddCommand(serverAdoptCmd)
+	serverCmd.AddCommand(serverShowCmd)
+	serverCmd.AddCommand(serverSSHCmd)
 	serverCmd.AddCommand(serverUpdateCmd)
+	serverCmd.AddCommand(serverRenameCmd)
+	serverCmd.AddCommand(serverRebootCmd)
+	serverCmd.AddCommand(serverUpgradeCmd)
+	serverCmd.AddCommand(serverExportInventoryCmd)
+	serverCmd.AddCommand(serverHistoryCmd)
 
 	// server add flags (non-interactive mode)
 	serverAddCmd.Flags().String("name", "", "Server name")
-	serverAddCmd.Flags().String("ip", "", "Server IP address")
-	serverAddCmd.Flags().String("ssh-key", "", "Path to SSH private key")
+	serverAddCmd.Flags().String("ip", "", "Server IP address or hostname")
+	serverAddCmd.Flags().String("ipv6", "", "Server IPv6 address (enables AAAA-aware DNS checks)")
+	serverAddCmd.Flags().String("ssh-key", "", "Path to SSH private key (defaults to the key generated by init)")
 	serverAddCmd.Flags().String("ssh-user", "root", "SSH user")
 	serverAddCmd.Flags().Int("ssh-port", 22, "SSH port")
+	serverAddCmd.Flags().Bool("use-agent", false, "Authenticate with ssh-agent (SSH_AUTH_SOCK) instead of a key file")
+	serverAddCmd.Flags().String("jump-host", "", "Bastion host to tunnel SSH through")
+	serverAddCmd.Flags().String("jump-user", "", "Bastion SSH user (default: --ssh-user)")
+	serverAddCmd.Flags().Int("jump-port", 22, "Bastion SSH port")
 	serverAddCmd.Flags().Bool("json", false, "Output in JSON format")
 
 	// server list flags
+	serverListCmd.Flags().Bool("stats", false, "Show disk, memory and load usage (gathered over SSH)")
 	serverListCmd.Flags().Bool("json", false, "Output in JSON format")
 
 	// server remove flags
-	serverRemoveCmd.Flags().BoolP("force", "f", false, "Force removal without confirmation")
+	serverRemoveCmd.Flags().BoolP("force", "f", false, "Force removal without confirmation, even if the server still has sites")
 	serverRemoveCmd.Flags().Bool("json", false, "Output in JSON format")
 
 	// server provision flags
 	serverProvisionCmd.Flags().String("name", "", "Server name (for non-interactive mode)")
-	serverProvisionCmd.Flags().String("ip", "", "Server IP address")
-	serverProvisionCmd.Flags().String("ssh-key", "", "Path to SSH private key")
+	serverProvisionCmd.Flags().String("ip", "", "Server IP address or hostname")
+	serverProvisionCmd.Flags().String("ipv6", "", "Server IPv6 address (enables AAAA-aware DNS checks)")
+	serverProvisionCmd.Flags().String("ssh-key", "", "Path to SSH private key (defaults to the key generated by init)")
 	serverProvisionCmd.Flags().String("ssh-user", "root", "SSH user")
 	serverProvisionCmd.Flags().Int("ssh-port", 22, "SSH port")
-	serverProvisionCmd.Flags().BoolP("force", "f", false, "Skip confirmation prompt")
+	serverProvisionCmd.Flags().Bool("use-agent", false, "Authenticate with ssh-agent (SSH_AUTH_SOCK) instead of a key file")
+	serverProvisionCmd.Flags().String("jump-host", "", "Bastion host to tunnel SSH through")
+	serverProvisionCmd.Flags().String("jump-user", "", "Bastion SSH user (default: --ssh-user)")
+	serverProvisionCmd.Flags().Int("jump-port", 22, "Bastion SSH port")
+	serverProvisionCmd.Flags().BoolP("force", "f", false, "Skip confirmation prompts and provision even when nothing changed since the last run")
 	serverProvisionCmd.Flags().Bool("skip-ssh-check", false, "Skip SSH connectivity check")
 	serverProvisionCmd.Flags().Bool("skip-check", false, "Skip already-provisioned check")
+	serverProvisionCmd.Flags().Bool("smoke-test", true, "Verify Nginx, PHP and the database after provisioning and fail if a check fails")
+	serverProvisionCmd.Flags().Bool("skip-test", false, "Skip the post-provision smoke test (same as --smoke-test=false)")
+	serverProvisionCmd.Flags().Bool("disable-root-after", false, "After provisioning, verify login as the wordsail user, then disable root SSH login")
+	serverProvisionCmd.Flags().Int("parallel", 1, "Provision up to N servers at once when several names are given")
+	serverProvisionCmd.Flags().Bool("fail-fast", false, "Stop starting new servers after the first failure")
+	serverProvisionCmd.Flags().Int("retries", 0, "Retry transient SSH/connection failures up to N times with exponential backoff")
+	serverProvisionCmd.Flags().Duration("ssh-timeout", utils.DefaultSSHTimeout, "How long SSH checks wait to connect and for their command (e.g. 5s, 1m)")
+	serverProvisionCmd.Flags().String("db-engine", "", "Database engine: mariadb or mysql (default mariadb)")
+	serverProvisionCmd.Flags().String("mariadb-version", "", "MariaDB release series to install, e.g. 10.11 (default: distribution package)")
 	serverProvisionCmd.Flags().Bool("json", false, "Output in JSON format")
+	addExtraVarFlags(serverProvisionCmd)
+
+	// server adopt flags
+	serverAdoptCmd.Flags().Bool("discover", false, "Only print the sites found; don't change the config")
+	serverAdoptCmd.Flags().BoolP("force", "f", false, "Skip the confirmation prompt")
+	serverAdoptCmd.Flags().Bool("json", false, "Output in JSON format")
 
 	// server health-check flags
 	serverHealthCheckCmd.Flags().Bool("json", false, "Output in JSON format")
+	serverHealthCheckCmd.Flags().Duration("ssh-timeout", utils.DefaultSSHTimeout, "How long to wait for the SSH connection and test command")
+	serverHealthCheckCmd.Flags().Bool("watch", false, "Re-run the checks every --interval until interrupted")
+	serverHealthCheckCmd.Flags().Duration("interval", 10*time.Second, "Time between checks in --watch mode")
+
+	// server reboot flags
+	serverRebootCmd.Flags().BoolP("force", "f", false, "Reboot without confirmation")
+	serverRebootCmd.Flags().Duration("timeout", 5*time.Minute, "How long to wait for the server to come back")
+	serverRebootCmd.Flags().Bool("json", false, "Output in JSON format")
+
+	// server upgrade flags
+	serverUpgradeCmd.Flags().Bool("all", false, "Upgrade every configured server")
+	serverUpgradeCmd.Flags().Bool("security-only", false, "Only apply security updates")
+	serverUpgradeCmd.Flags().Bool("json", false, "Output in JSON format")
+
+	serverHistoryCmd.Flags().Bool("json", false, "Output in JSON format")
+
+	serverExportInventoryCmd.Flags().StringP("file", "f", "", "File to write the inventory to (default: stdout)")
+	serverExportInventoryCmd.Flags().String("format", ansible.ExportINI, "Inventory format: ini or yaml")
+	serverExportInventoryCmd.Flags().Bool("include-secrets", false, "Write credential-like global vars instead of REDACTED")
+	serverExportInventoryCmd.Flags().Bool("json", false, "Output in JSON format")
+
+	// server ssh flags
+	serverSSHCmd.Flags().String("site", "", "Site ID or domain whose directory the shell starts in")
+
+	// server show flags
+	serverShowCmd.Flags().Bool("json", false, "Output in JSON format")
 
 	// server update flags
 	serverUpdateCmd.Flags().String("name", "", "New server name")
-	serverUpdateCmd.Flags().String("ip", "", "New IP address")
+
+	// server rename flags
+	serverRenameCmd.Flags().Bool("json", false, "Output in JSON format")
+	serverUpdateCmd.Flags().String("ip", "", "New IP address or hostname")
 	serverUpdateCmd.Flags().String("ssh-key", "", "New SSH private key path")
 	serverUpdateCmd.Flags().String("ssh-user", "", "New SSH user")
 	serverUpdateCmd.Flags().Int("ssh-port", 0, "New SSH port")
+	serverUpdateCmd.Flags().Bool("use-agent", false, "Authenticate with ssh-agent (SSH_AUTH_SOCK) instead of a key file")
+	serverUpdateCmd.Flags().String("jump-host", "", "Bastion host to tunnel SSH through (empty to remove)")
+	serverUpdateCmd.Flags().String("jump-user", "", "Bastion SSH user")
+	serverUpdateCmd.Flags().Int("jump-port", 22, "Bastion SSH port")
+	serverUpdateCmd.Flags().String("python-interpreter", "", "Remote Python for Ansible on this server (empty to use ansible.python_interpreter)")
 	serverUpdateCmd.Flags().Bool("json", false, "Output in JSON format")
 }
@@ -0,0 +1,86 @@
+package cmd
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/wordsail/cli/internal/config"
+	"github.com/wordsail/cli/internal/state"
+	"github.com/wordsail/cli/pkg/models"
+)
+
+func TestProvisionHashIncludesExtraVarOverrides(t *testing.T) {
+	t.Cleanup(func() { ExtraVarOverrides = nil })
+	cfg := &config.Config{
+		Ansible:    config.AnsibleConfig{Path: t.TempDir()},
+		GlobalVars: map[string]interface{}{"php_version": "8.3"},
+	}
+	server := models.Server{Name: "web1", Hostname: "203.0.113.10", IP: "203.0.113.10"}
+
+	base := provisionHash(cfg, server)
+	if base == "" {
+		t.Fatal("provisionHash() = \"\"")
+	}
+
+	ExtraVarOverrides = map[string]interface{}{"php_version": "8.2"}
+	if got := provisionHash(cfg, server); got == base {
+		t.Error("an --extra-var override did not change the hash")
+	}
+
+	// An override that matches the computed value changes nothing
+	ExtraVarOverrides = map[string]interface{}{"php_version": "8.3"}
+	if got := provisionHash(cfg, server); got != base {
+		t.Error("an override equal to the computed value changed the hash")
+	}
+}
+
+func TestRecordProvisionHashSkipsLimitedRuns(t *testing.T) {
+	t.Cleanup(func() { AnsibleTags, SkipTags = "", "" })
+	mgr := config.NewManagerWithPath(filepath.Join(t.TempDir(), "wordsail.yaml"))
+	cfg := &config.Config{
+		Version: "1.0",
+		Ansible: config.AnsibleConfig{Path: t.TempDir()},
+		Servers: []models.Server{{Name: "web1", Hostname: "203.0.113.10", IP: "203.0.113.10"}},
+	}
+	if err := mgr.Save(cfg); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+	stateMgr := state.NewManager(mgr)
+
+	storedHash := func() string {
+		t.Helper()
+		loaded, err := mgr.Load()
+		if err != nil {
+			t.Fatalf("Load() error = %v", err)
+		}
+		return loaded.Servers[0].ProvisionHash
+	}
+
+	tests := []struct {
+		name     string
+		tags     string
+		skipTags string
+	}{
+		{name: "tags", tags: "nginx"},
+		{name: "skip tags", skipTags: "mariadb"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			AnsibleTags, SkipTags = tt.tags, tt.skipTags
+			if err := recordProvisionHash(stateMgr, cfg, cfg.Servers[0]); err != nil {
+				t.Fatalf("recordProvisionHash() error = %v", err)
+			}
+			if got := storedHash(); got != "" {
+				t.Errorf("a run limited by tags recorded hash %q", got)
+			}
+		})
+	}
+
+	AnsibleTags, SkipTags = "", ""
+	if err := recordProvisionHash(stateMgr, cfg, cfg.Servers[0]); err != nil {
+		t.Fatalf("recordProvisionHash() error = %v", err)
+	}
+	if got, want := storedHash(), provisionHash(cfg, cfg.Servers[0]); got != want {
+		t.Errorf("stored hash = %q, want %q", got, want)
+	}
+}
@@ -1,9 +1,12 @@
 package cmd
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
+	"io"
 	"os"
+	"strings"
 	"time"
 
 	"github.com/AlecAivazis/survey/v2"
@@ -11,6 +14,7 @@ import (
 	"github.com/spf13/cobra"
 	"github.com/wordsail/cli/internal/ansible"
 	"github.com/wordsail/cli/internal/config"
+	"github.com/wordsail/cli/internal/exit"
 	"github.com/wordsail/cli/internal/prompt"
 	"github.com/wordsail/cli/internal/state"
 	"github.com/wordsail/cli/internal/utils"
@@ -29,29 +33,119 @@ var siteCreateCmd = &cobra.Command{
 	Use:     "create",
 	Aliases: []string{"add"},
 	Short:   "Create a new WordPress site",
-	Long:    `Interactively create a new WordPress site on a provisioned server.`,
+	Long: `Interactively create a new WordPress site on a provisioned server.
+
+Additional WordPress users can be created alongside the admin with the
+repeatable --user flag (user:email:role). A password is generated for each
+user and printed once.
+
+Examples:
+  # Create client and developer accounts at the same time
+  wordsail site create --user client:client@example.com:editor \
+    --user dev:dev@agency.io:administrator
+
+  # Install WordPress in German
+  wordsail site create --admin-locale de_DE
+
+  # Run a legacy site on PHP 7.4 (installed on the server if needed)
+  wordsail site create --php-version 7.4
+
+  # Pass the admin password on stdin rather than the command line
+  # (or set $WORDSAIL_ADMIN_PASSWORD)
+  pass show wp/example | wordsail site create --non-interactive --server production-1 \
+    --domain example.com --admin-user admin --admin-email admin@example.com \
+    --admin-password-stdin
+
+  # Install the plugins, theme and options from a saved template
+  wordsail site create --template agency-starter
+
+  # Retry a failed or partial create over the existing site (files and
+  # database are kept; the config record is replaced)
+  wordsail site create --non-interactive --server production-1 --domain example.com \
+    --admin-user admin --admin-email admin@example.com --admin-password-stdin --force
+
+  # Set up Nginx, PHP and a database for a non-WordPress PHP app
+  wordsail site create --non-interactive --server production-1 \
+    --domain app.example.com --no-wordpress
+
+  # Wire a site up to a restored database (skips the WordPress install); the
+  # database password is read from stdin (or set $WORDSAIL_DB_PASSWORD)
+  pass show db/shop | wordsail site create --non-interactive --server production-1 \
+    --domain example.com --admin-user admin --admin-email admin@example.com \
+    --use-existing-db --db-name shop --db-user shop --db-password-stdin`,
 	Run: func(cmd *cobra.Command, args []string) {
 		mgr, err := config.NewManager()
 		if err != nil {
-			color.Red("Error: %v", err)
-			os.Exit(1)
+			fail(cmd, "Failed to create config manager", err)
 		}
 
 		if !mgr.ConfigExists() {
-			color.Red("Configuration file not found. Run 'wordsail init' first.")
-			os.Exit(1)
+			fail(cmd, "Configuration file not found", exit.Errorf(exit.ConfigNotFound, "run 'wordsail init' first"))
 		}
 
 		cfg, err := mgr.Load()
 		if err != nil {
-			color.Red("Error: Failed to load configuration: %v", err)
-			os.Exit(1)
+			fail(cmd, "Failed to load configuration", exit.New(exit.Validation, err))
+		}
+
+		// Load the template up front so a typo fails before anything runs
+		var siteTemplate *models.SiteTemplate
+		if templateName, _ := cmd.Flags().GetString("template"); templateName != "" {
+			siteTemplate, err = config.LoadSiteTemplate(templateName)
+			if err != nil {
+				fail(cmd, "Invalid --template", exit.New(exit.Validation, err))
+			}
+		}
+
+		phpVersion, _ := cmd.Flags().GetString("php-version")
+		if phpVersion != "" {
+			if err := utils.ValidatePHPVersion(phpVersion); err != nil {
+				fail(cmd, "Invalid --php-version", exit.New(exit.Validation, err))
+			}
 		}
 
 		// Check for non-interactive mode
 		nonInteractive, _ := cmd.Flags().GetBool("non-interactive")
 		var input *prompt.SiteInput
 
+		// Bare sites get Nginx, PHP and a database but no WordPress
+		noWordPress, _ := cmd.Flags().GetBool("no-wordpress")
+		if noWordPress {
+			for _, flag := range []string{"admin-user", "admin-email", "admin-password", "admin-password-stdin", "user", "admin-locale", "template", "use-existing-db"} {
+				if cmd.Flags().Changed(flag) {
+					fail(cmd, "Invalid flags", exit.Errorf(exit.Validation, "--%s cannot be used with --no-wordpress", flag))
+				}
+			}
+		}
+
+		// --force re-runs the playbook over a site that already exists
+		recreate, _ := cmd.Flags().GetBool("force")
+		if recreate && !nonInteractive {
+			fail(cmd, "Invalid flags", exit.Errorf(exit.Validation, "--force requires --non-interactive"))
+		}
+
+		// Optionally adopt an already-populated database instead of installing WordPress
+		useExistingDB, _ := cmd.Flags().GetBool("use-existing-db")
+		var existingDB ansible.ExistingDatabase
+		if useExistingDB {
+			if !nonInteractive {
+				fail(cmd, "Invalid flags", exit.Errorf(exit.Validation, "--use-existing-db requires --non-interactive"))
+			}
+			existingDB.Name, _ = cmd.Flags().GetString("db-name")
+			existingDB.User, _ = cmd.Flags().GetString("db-user")
+			adminStdin, _ := cmd.Flags().GetBool("admin-password-stdin")
+			dbStdin, _ := cmd.Flags().GetBool("db-password-stdin")
+			if adminStdin && dbStdin {
+				fail(cmd, "Invalid flags", exit.Errorf(exit.Validation, "--admin-password-stdin and --db-password-stdin cannot both read stdin"))
+			}
+			existingDB.Password = dbPasswordInput(cmd)
+			existingDB.Host, _ = cmd.Flags().GetString("db-host")
+			existingDB.Prefix, _ = cmd.Flags().GetString("db-prefix")
+			if err := existingDB.Validate(); err != nil {
+				fail(cmd, "Invalid database details", exit.New(exit.Validation, err))
+			}
+		}
+
 		if nonInteractive {
 			// Get values from flags
 			serverName, _ := cmd.Flags().GetString("server")
@@ -59,14 +153,25 @@ var siteCreateCmd = &cobra.Command{
 			siteID, _ := cmd.Flags().GetString("site-id")
 			adminUser, _ := cmd.Flags().GetString("admin-user")
 			adminEmail, _ := cmd.Flags().GetString("admin-email")
-			adminPassword, _ := cmd.Flags().GetString("admin-password")
+			adminPassword := ""
+			if !noWordPress {
+				adminPassword = adminPasswordInput(cmd)
+			}
 
-			// site-id is optional - will be auto-generated if not provided
-			if serverName == "" || domain == "" || adminUser == "" || adminEmail == "" || adminPassword == "" {
-				color.Red("Error: In non-interactive mode, required flags are missing")
-				fmt.Println("Required flags: --server, --domain, --admin-user, --admin-email, --admin-password")
-				fmt.Println("Optional flags: --site-id (auto-generated if not provided)")
-				os.Exit(1)
+			// site-id is optional - will be auto-generated if not provided.
+			// An adopted database already has its admin account, so no password is needed.
+			if noWordPress && (serverName == "" || domain == "") {
+				fail(cmd, "Missing required flags", exit.Errorf(exit.Validation, "--server and --domain are required in non-interactive mode"))
+			}
+			if !noWordPress && (serverName == "" || domain == "" || adminUser == "" || adminEmail == "" || (adminPassword == "" && !useExistingDB)) {
+				outputError(cmd, "Missing required flags", fmt.Errorf("--server, --domain, --admin-user, --admin-email and an admin password (--admin-password-stdin or $%s) are required in non-interactive mode", adminPasswordEnv))
+				outputInfo(cmd, "Optional flags: --site-id (auto-generated if not provided)\n")
+				os.Exit(exit.Validation)
+			}
+			if adminPassword != "" {
+				if err := utils.ValidatePasswordStrength(adminPassword); err != nil {
+					fail(cmd, "Invalid admin password", exit.New(exit.Validation, err))
+				}
 			}
 
 			// Auto-generate site ID if not provided
@@ -87,6 +192,10 @@ var siteCreateCmd = &cobra.Command{
 				}
 			}
 
+			if phpVersion == "" {
+				phpVersion = utils.DefaultPHPVersion
+			}
+
 			input = &prompt.SiteInput{
 				ServerName:    serverName,
 				Domain:        domain,
@@ -94,13 +203,32 @@ var siteCreateCmd = &cobra.Command{
 				AdminUser:     adminUser,
 				AdminEmail:    adminEmail,
 				AdminPassword: adminPassword,
+				PHPVersion:    phpVersion,
+				NoWordPress:   noWordPress,
 			}
 		} else {
+			if stdin, _ := cmd.Flags().GetBool("admin-password-stdin"); stdin {
+				fail(cmd, "Invalid flags", exit.Errorf(exit.Validation, "--admin-password-stdin requires --non-interactive"))
+			}
+
 			// Interactive prompts
-			input, err = prompt.PromptSiteCreate(cfg.Servers)
+			input, err = prompt.PromptSiteCreate(cfg.Servers, phpVersion, noWordPress)
 			if err != nil {
-				color.Red("Error: %v", err)
-				os.Exit(1)
+				fail(cmd, "Failed to get site details", err)
+			}
+		}
+
+		// Additional users and locale apply to both interactive and flag modes
+		userSpecs, _ := cmd.Flags().GetStringArray("user")
+		extraUsers, err := utils.ParseSiteUsers(userSpecs, input.AdminUser, input.AdminEmail)
+		if err != nil {
+			fail(cmd, "Invalid --user", exit.New(exit.Validation, err))
+		}
+
+		adminLocale, _ := cmd.Flags().GetString("admin-locale")
+		if adminLocale != "" {
+			if err := utils.ValidateWPLocale(adminLocale); err != nil {
+				fail(cmd, "Invalid --admin-locale", exit.New(exit.Validation, err))
 			}
 		}
 
@@ -114,14 +242,46 @@ var siteCreateCmd = &cobra.Command{
 		}
 
 		if targetServer == nil {
-			color.Red("Error: Server '%s' not found", input.ServerName)
-			os.Exit(1)
+			fail(cmd, "Server not found", exit.Errorf(exit.Validation, "server '%s' does not exist", input.ServerName))
+		}
+
+		// A site with this primary domain already on the server is only
+		// re-created with --force; its record is then replaced, not duplicated
+		existingSite := utils.FindSiteByDomain(targetServer, input.Domain)
+		if existingSite != nil && existingSite.PrimaryDomain == input.Domain {
+			if !recreate {
+				fail(cmd, "Site already exists", exit.Errorf(exit.Validation,
+					"site '%s' on server '%s' already uses %s; re-run with --force to re-create it (files and database are kept)",
+					existingSite.SiteID, targetServer.Name, input.Domain))
+			}
+			if cmd.Flags().Changed("site-id") && input.SiteID != existingSite.SiteID {
+				fail(cmd, "Invalid --site-id", exit.Errorf(exit.Validation,
+					"%s belongs to site '%s'; --force re-creates that site", input.Domain, existingSite.SiteID))
+			}
+			input.SiteID = existingSite.SiteID
+		} else {
+			existingSite = nil
+			recreate = false
+		}
+
+		// Fail fast if the domain is already used by any other site in the inventory
+		if conflictServer, conflictSite := utils.FindSiteByDomainAcrossServers(cfg.Servers, input.Domain); conflictSite != nil && conflictSite != existingSite {
+			fail(cmd, "Domain already in use", exit.Errorf(exit.Validation, "domain '%s' is already used by site '%s' on server '%s'",
+				input.Domain, conflictSite.SiteID, conflictServer.Name))
 		}
 
 		if targetServer.Status != "provisioned" {
-			color.Red("Error: Server '%s' is not provisioned", input.ServerName)
-			fmt.Println("Provision the server first: wordsail server provision", input.ServerName)
-			os.Exit(1)
+			outputError(cmd, "Server not provisioned", fmt.Errorf("server '%s' is not provisioned", input.ServerName))
+			outputInfo(cmd, "Provision the server first: wordsail server provision %s\n", input.ServerName)
+			os.Exit(exit.Validation)
+		}
+
+		// Pre-flight: the adopted database must be reachable from the server
+		if useExistingDB {
+			outputInfo(cmd, "Checking database '%s' on %s...\n", existingDB.Name, existingDB.HostOrDefault())
+			if _, err := utils.RunSSHCommand(*targetServer, existingDB.CheckCommand()); err != nil {
+				fail(cmd, "Database is not reachable from the server", err)
+			}
 		}
 
 		// Check for --no-ssl flag
@@ -129,11 +289,29 @@ var siteCreateCmd = &cobra.Command{
 
 		// Prepare extra vars for Ansible
 		extraVars := map[string]interface{}{
-			"domain":            input.Domain,
-			"site_id":           input.SiteID,
-			"wp_admin_user":     input.AdminUser,
-			"wp_admin_email":    input.AdminEmail,
-			"wp_admin_password": input.AdminPassword,
+			"domain":      input.Domain,
+			"site_id":     input.SiteID,
+			"php_version": input.PHPVersion,
+		}
+
+		// A bare site's database password is generated here, since there is no
+		// wp-config.php to keep it. It is stored encrypted, as with 'site db
+		// rotate-password', so 'site db export' and 'import' can use it;
+		// encrypting first stops a missing key before anything is created.
+		dbPassword := ""
+		encryptedDBPassword := ""
+		if input.NoWordPress {
+			dbPassword = prompt.GenerateSecurePassword(24)
+			encryptedDBPassword, err = config.EncryptSecret(dbPassword)
+			if err != nil {
+				fail(cmd, "Failed to encrypt the database password", err)
+			}
+			extraVars["install_wordpress"] = false
+			extraVars["wp_db_password"] = dbPassword
+		} else {
+			extraVars["wp_admin_user"] = input.AdminUser
+			extraVars["wp_admin_email"] = input.AdminEmail
+			extraVars["wp_admin_password"] = input.AdminPassword
 		}
 
 		// Add skip_ssl if --no-ssl flag is set
@@ -141,23 +319,55 @@ var siteCreateCmd = &cobra.Command{
 			extraVars["skip_ssl"] = true
 		}
 
+		if recreate {
+			extraVars["recreate"] = true
+		}
+
+		if adminLocale != "" {
+			extraVars["wp_admin_locale"] = adminLocale
+		}
+
+		if useExistingDB {
+			for k, v := range ansible.ExistingDatabaseVars(existingDB) {
+				extraVars[k] = v
+			}
+		}
+
+		// Generate a password for each additional user; they are shown once
+		userPasswords := make([]string, len(extraUsers))
+		if len(extraUsers) > 0 {
+			wpUsers := make([]map[string]interface{}, len(extraUsers))
+			for i, user := range extraUsers {
+				userPasswords[i] = prompt.GenerateSecurePassword(20)
+				wpUsers[i] = map[string]interface{}{
+					"login":    user.Login,
+					"email":    user.Email,
+					"role":     user.Role,
+					"password": userPasswords[i],
+				}
+			}
+			extraVars["wp_extra_users"] = wpUsers
+		}
+
 		// Create Ansible executor
-		executor := ansible.NewExecutor(cfg.Ansible.Path)
-		executor.SetVerbose(Verbose)
-		executor.SetDryRun(DryRun)
+		executor := newExecutor(cmd, cfg)
 
 		// Execute website.yml playbook
-		fmt.Println()
-		color.Cyan("═══════════════════════════════════════════════════════")
-		color.Cyan("  Creating WordPress site: %s", input.Domain)
-		color.Cyan("  Estimated time: 2-4 minutes")
-		color.Cyan("═══════════════════════════════════════════════════════")
-		fmt.Println()
+		siteKind := "WordPress site"
+		if input.NoWordPress {
+			siteKind = "bare site"
+		}
+		action := "Creating"
+		if recreate {
+			action = "Re-creating"
+		}
+		outputBanner(cmd, color.Cyan,
+			fmt.Sprintf("%s %s: %s", action, siteKind, input.Domain),
+			"Estimated time: 2-4 minutes")
 
 		result, err := executor.ExecutePlaybookWithResult("website.yml", *targetServer, extraVars, cfg.GlobalVars)
 		if err != nil {
-			color.Red("\n✗ Site creation failed: %v", err)
-			os.Exit(1)
+			fail(cmd, "Site creation failed", sslIssueError(result, err))
 		}
 
 		// Create site record
@@ -167,9 +377,10 @@ var siteCreateCmd = &cobra.Command{
 
 		// Check if SSL was issued
 		if result.SSLInfo != nil {
+			recordSSLAttempt(cmd, mgr, input.Domain, true, false)
 			sslEnabled = true
 			sslIssuedAt = &now
-			expiresAt := utils.ParseSSLExpiry(result.SSLInfo.Expiry)
+			expiresAt := parseSSLExpiry(cmd, result.SSLInfo.Expiry)
 			if expiresAt != nil {
 				sslExpiresAt = expiresAt
 			}
@@ -181,6 +392,7 @@ var siteCreateCmd = &cobra.Command{
 			CreatedAt:     now,
 			AdminUser:     input.AdminUser,
 			AdminEmail:    input.AdminEmail,
+			Users:         extraUsers,
 			Domains: []models.Domain{
 				{
 					Domain:       input.Domain,
@@ -190,27 +402,147 @@ var siteCreateCmd = &cobra.Command{
 				},
 			},
 			Database: models.Database{
-				Name: input.SiteID,
-				User: input.SiteID,
-				Host: "localhost",
+				Name:              input.SiteID,
+				User:              input.SiteID,
+				Host:              "localhost",
+				EncryptedPassword: encryptedDBPassword,
 			},
-			PHPVersion: "8.3",
+			PHPVersion:  input.PHPVersion,
+			NoWordPress: input.NoWordPress,
 			Metadata: models.Metadata{
 				BackupEnabled: false,
 			},
 		}
 
+		if useExistingDB {
+			newSite.Database = models.Database{
+				Name:    existingDB.Name,
+				User:    existingDB.User,
+				Host:    existingDB.HostOrDefault(),
+				Adopted: true,
+			}
+		}
+
+		// A re-created site keeps its history and the domains added since
+		if existingSite != nil {
+			newSite.CreatedAt = existingSite.CreatedAt
+			newSite.Metadata = existingSite.Metadata
+			for _, d := range existingSite.Domains {
+				if d.Domain != input.Domain {
+					newSite.Domains = append(newSite.Domains, d)
+				}
+			}
+		}
+
 		// Add site to server configuration
 		stateMgr := state.NewManager(mgr)
-		if err := stateMgr.AddSiteToServer(input.ServerName, newSite); err != nil {
-			color.Red("Warning: Failed to update configuration: %v", err)
+		writeState(cmd, mgr, state.PendingChange{
+			Kind:   state.PendingAddSite,
+			Server: input.ServerName,
+			SiteID: newSite.SiteID,
+			Site:   &newSite,
+		}, func() error {
+			return stateMgr.AddSiteToServer(input.ServerName, newSite)
+		})
+
+		// Apply the template with WP-CLI now that WordPress is installed. The
+		// site is kept if this fails.
+		templateApplied := false
+		if siteTemplate != nil {
+			if DryRun {
+				outputInfo(cmd, "Dry run: skipping template '%s'\n", siteTemplate.Name)
+			} else {
+				outputInfo(cmd, "→ Applying template '%s'...\n", siteTemplate.Name)
+				if err := utils.ApplySiteTemplate(*targetServer, newSite, *siteTemplate); err != nil {
+					outputWarning(cmd, "Site created, but template '%s' could not be applied: %v", siteTemplate.Name, err)
+				} else {
+					templateApplied = true
+				}
+			}
 		}
 
-		fmt.Println()
-		color.Green("═══════════════════════════════════════════════════════")
-		color.Green("  ✓ WordPress site created successfully!")
-		color.Green("═══════════════════════════════════════════════════════")
-		fmt.Println()
+		if isJSONOutput(cmd) {
+			scheme := "http"
+			if sslEnabled {
+				scheme = "https"
+			}
+			data := map[string]interface{}{
+				"server":      input.ServerName,
+				"site_id":     input.SiteID,
+				"domain":      input.Domain,
+				"site_url":    fmt.Sprintf("%s://%s", scheme, input.Domain),
+				"admin_url":   fmt.Sprintf("%s://%s/wp-admin", scheme, input.Domain),
+				"admin_user":  input.AdminUser,
+				"admin_email": input.AdminEmail,
+				"ssl_enabled": sslEnabled,
+				"php_version": input.PHPVersion,
+			}
+			if sslExpiresAt != nil {
+				data["ssl_expires_at"] = sslExpiresAt.Format(time.RFC3339)
+			}
+			if input.NoWordPress {
+				delete(data, "admin_url")
+				delete(data, "admin_user")
+				delete(data, "admin_email")
+				data["no_wordpress"] = true
+				data["document_root"] = utils.SitePath(newSite)
+				data["db_name"] = newSite.Database.Name
+				data["db_user"] = newSite.Database.User
+				data["db_password"] = dbPassword
+			}
+			if siteTemplate != nil {
+				data["template"] = siteTemplate.Name
+				data["template_applied"] = templateApplied
+			}
+			if len(extraUsers) > 0 {
+				users := make([]map[string]string, len(extraUsers))
+				for i, user := range extraUsers {
+					users[i] = map[string]string{
+						"login":    user.Login,
+						"email":    user.Email,
+						"role":     user.Role,
+						"password": userPasswords[i],
+					}
+				}
+				data["users"] = users
+			}
+			if result.DNSStatus != nil {
+				data["dns_resolved_ip"] = result.DNSStatus.ResolvedIP
+				data["dns_matches"] = result.DNSStatus.Matches
+				if result.DNSStatus.ServerIPv6 != "" {
+					data["dns_resolved_ipv6"] = result.DNSStatus.ResolvedIPv6
+					data["dns_ipv6_matches"] = result.DNSStatus.IPv6Matches
+				}
+			}
+			outputSuccess(cmd, "site_created", data)
+			return
+		}
+
+		if input.NoWordPress {
+			outputBanner(cmd, color.Green, "✓ Bare site created successfully!")
+			scheme := "http"
+			if sslEnabled {
+				scheme = "https"
+			}
+			fmt.Printf("Site URL:      %s://%s\n", scheme, input.Domain)
+			fmt.Printf("Document root: %s\n", utils.SitePath(newSite))
+			fmt.Printf("PHP Version:   %s\n", input.PHPVersion)
+			fmt.Println()
+			fmt.Println("Database:")
+			fmt.Printf("  Name:        %s\n", newSite.Database.Name)
+			fmt.Printf("  User:        %s\n", newSite.Database.User)
+			fmt.Printf("  Password:    %s\n", dbPassword)
+			fmt.Printf("(stored encrypted; 'wordsail site db info --server %s --site %s --show-password' prints it)\n", input.ServerName, input.SiteID)
+			fmt.Println()
+			if sslEnabled && sslExpiresAt != nil {
+				color.Green("✓ SSL certificate issued automatically (expires %s)", sslExpiresAt.Format("2006-01-02"))
+			} else if !sslEnabled {
+				color.Yellow("⚠️  SSL not issued. Run 'wordsail domain ssl' once DNS points to this server.")
+			}
+			return
+		}
+
+		outputBanner(cmd, color.Green, "✓ WordPress site created successfully!")
 
 		// Display appropriate URL based on SSL status
 		if sslEnabled {
@@ -222,8 +554,21 @@ var siteCreateCmd = &cobra.Command{
 		}
 		fmt.Printf("Admin User:    %s\n", input.AdminUser)
 		fmt.Printf("Admin Email:   %s\n", input.AdminEmail)
+		fmt.Printf("PHP Version:   %s\n", input.PHPVersion)
+		if templateApplied {
+			fmt.Printf("Template:      %s\n", siteTemplate.Name)
+		}
 		fmt.Println()
 
+		if len(extraUsers) > 0 {
+			fmt.Println("Additional users:")
+			for i, user := range extraUsers {
+				fmt.Printf("  %-15s %-30s %-14s %s\n", user.Login, user.Email, user.Role, userPasswords[i])
+			}
+			color.Yellow("⚠️  These passwords are shown only once. Save them securely!")
+			fmt.Println()
+		}
+
 		// Show SSL status and next steps
 		if sslEnabled {
 			color.Green("✓ SSL certificate issued automatically")
@@ -239,11 +584,19 @@ var siteCreateCmd = &cobra.Command{
 			fmt.Println()
 			fmt.Printf("   Domain '%s' resolves to: %s\n", input.Domain, result.DNSStatus.ResolvedIP)
 			fmt.Printf("   Server IP is: %s\n", result.DNSStatus.ServerIP)
+			if result.DNSStatus.ServerIPv6 != "" && !result.DNSStatus.IPv6Matches {
+				fmt.Printf("   AAAA record resolves to: %s (server IPv6 is %s)\n", result.DNSStatus.ResolvedIPv6, result.DNSStatus.ServerIPv6)
+			}
 			fmt.Println()
 			fmt.Println("   To enable HTTPS:")
 			fmt.Printf("   1. Update your DNS A record to point to %s\n", result.DNSStatus.ServerIP)
-			fmt.Printf("   2. Run: wordsail domain ssl --server %s --site %s --domain %s\n",
-				input.ServerName, input.SiteID, input.Domain)
+			step := 2
+			if result.DNSStatus.ServerIPv6 != "" && !result.DNSStatus.IPv6Matches {
+				fmt.Printf("   2. Update (or remove) your DNS AAAA record to point to %s\n", result.DNSStatus.ServerIPv6)
+				step = 3
+			}
+			fmt.Printf("   %d. Run: wordsail domain ssl --server %s --site %s --domain %s\n",
+				step, input.ServerName, input.SiteID, input.Domain)
 		} else if skipSSL {
 			fmt.Println("Next steps:")
 			fmt.Printf("  1. Add www subdomain: wordsail domain add\n")
@@ -256,17 +609,82 @@ var siteCreateCmd = &cobra.Command{
 	},
 }
 
-// SiteWithServer represents a site with its server name for JSON output
+// adminPasswordEnv supplies the admin password for non-interactive site
+// creation without putting it on the command line
+const adminPasswordEnv = "WORDSAIL_ADMIN_PASSWORD"
+
+// adminPasswordInput returns the admin password from --admin-password,
+// --admin-password-stdin or $WORDSAIL_ADMIN_PASSWORD, in that order
+func adminPasswordInput(cmd *cobra.Command) string {
+	password, _ := cmd.Flags().GetString("admin-password")
+	fromStdin, _ := cmd.Flags().GetBool("admin-password-stdin")
+	if fromStdin {
+		if password != "" {
+			fail(cmd, "Invalid flags", exit.Errorf(exit.Validation, "--admin-password and --admin-password-stdin cannot be combined"))
+		}
+		secret, err := utils.ReadSecret(os.Stdin)
+		if err != nil {
+			fail(cmd, "Failed to read admin password", exit.New(exit.Validation, err))
+		}
+		return secret
+	}
+	if password != "" {
+		return password
+	}
+	return os.Getenv(adminPasswordEnv)
+}
+
+// dbPasswordEnv supplies the password of an existing database for
+// --use-existing-db without putting it on the command line
+const dbPasswordEnv = "WORDSAIL_DB_PASSWORD"
+
+// dbPasswordInput returns the existing database password from --db-password,
+// --db-password-stdin or $WORDSAIL_DB_PASSWORD, in that order
+func dbPasswordInput(cmd *cobra.Command) string {
+	password, _ := cmd.Flags().GetString("db-password")
+	fromStdin, _ := cmd.Flags().GetBool("db-password-stdin")
+	if fromStdin {
+		if password != "" {
+			fail(cmd, "Invalid flags", exit.Errorf(exit.Validation, "--db-password and --db-password-stdin cannot be combined"))
+		}
+		secret, err := utils.ReadSecret(os.Stdin)
+		if err != nil {
+			fail(cmd, "Failed to read database password", exit.New(exit.Validation, err))
+		}
+		return secret
+	}
+	if password != "" {
+		return password
+	}
+	return os.Getenv(dbPasswordEnv)
+}
+
+// SiteWithServer represents a site with its server name for JSON and YAML output
 type SiteWithServer struct {
-	ServerName string       `json:"server_name"`
-	Site       models.Site  `json:"site"`
+	ServerName string       `json:"server_name" yaml:"server_name"`
+	Site       models.Site  `json:"site" yaml:"site"`
 }
 
 // siteListCmd represents the site list command
 var siteListCmd = &cobra.Command{
-	Use:   "list",
-	Short: "List all WordPress sites",
-	Long:  `Display all WordPress sites across all servers.`,
+	Use:         "list",
+	Short:       "List all WordPress sites",
+	Annotations: map[string]string{csvAnnotation: ""},
+	Long: `Display all WordPress sites across all servers.
+
+With --stale-ssl, list the domains whose stored SSL expiry has passed
+instead. Certbot renews certificates on the server without updating the
+config, so a past expiry usually means the stored date is out of date;
+add --refresh to read the live expiry and store it.
+
+--sort orders the list by domain, server, created or ssl-expiry (the
+soonest certificate expiry of each site). --expiring N only lists sites
+whose earliest certificate expires within N days, including ones that
+have already expired.
+
+--since and --before only list sites created on or after, or before, a
+date (2006-01-02 in local time, or RFC3339). Sites with no recorded
+creation date are left out when either is set.`,
 	Run: func(cmd *cobra.Command, args []string) {
 		mgr, err := config.NewManager()
 		if err != nil {
@@ -276,13 +694,13 @@ var siteListCmd = &cobra.Command{
 
 		if !mgr.ConfigExists() {
 			color.Red("Configuration file not found. Run 'wordsail init' first.")
-			os.Exit(1)
+			os.Exit(exit.ConfigNotFound)
 		}
 
 		cfg, err := mgr.Load()
 		if err != nil {
 			color.Red("Error: Failed to load configuration: %v", err)
-			os.Exit(1)
+			os.Exit(exit.Validation)
 		}
 
 		// Filter by server if specified
@@ -290,20 +708,81 @@ var siteListCmd = &cobra.Command{
 
 		// Check for JSON output
 		jsonOutput, _ := cmd.Flags().GetBool("json")
+
+		staleOnly, _ := cmd.Flags().GetBool("stale-ssl")
+		refresh, _ := cmd.Flags().GetBool("refresh")
+		if refresh && !staleOnly {
+			color.Red("Error: --refresh requires --stale-ssl")
+			os.Exit(exit.Validation)
+		}
+		if staleOnly && (cmd.Flags().Changed("sort") || cmd.Flags().Changed("expiring") ||
+			cmd.Flags().Changed("since") || cmd.Flags().Changed("before")) {
+			color.Red("Error: --sort, --expiring, --since and --before cannot be combined with --stale-ssl")
+			os.Exit(exit.Validation)
+		}
+		if staleOnly {
+			listStaleSSL(cmd, mgr, cfg, filterServer, refresh, jsonOutput)
+			return
+		}
+
+		sortKey, _ := cmd.Flags().GetString("sort")
+		expiringDays, _ := cmd.Flags().GetInt("expiring")
+		if expiringDays < 0 {
+			color.Red("Error: --expiring must be zero or more days")
+			os.Exit(exit.Validation)
+		}
+		expiringOnly := cmd.Flags().Changed("expiring")
+
+		var filters []string
+		if expiringOnly {
+			filters = append(filters, fmt.Sprintf("with a certificate expiring within %d day(s)", expiringDays))
+		}
+		sinceFlag, _ := cmd.Flags().GetString("since")
+		beforeFlag, _ := cmd.Flags().GetString("before")
+		var since, before time.Time
+		if sinceFlag != "" {
+			if since, err = utils.ParseDateFilter(sinceFlag); err != nil {
+				color.Red("Error: --since: %v", err)
+				os.Exit(exit.Validation)
+			}
+			filters = append(filters, "created since "+sinceFlag)
+		}
+		if beforeFlag != "" {
+			if before, err = utils.ParseDateFilter(beforeFlag); err != nil {
+				color.Red("Error: --before: %v", err)
+				os.Exit(exit.Validation)
+			}
+			filters = append(filters, "created before "+beforeFlag)
+		}
+		if !since.IsZero() && !before.IsZero() && !since.Before(before) {
+			color.Red("Error: --since must be earlier than --before")
+			os.Exit(exit.Validation)
+		}
+
+		sites := utils.ListServerSites(cfg.Servers, filterServer)
+		totalSites := len(sites)
+		if expiringOnly {
+			sites = utils.FilterExpiringSites(sites, expiringDays, time.Now())
+		}
+		if sinceFlag != "" || beforeFlag != "" {
+			sites = utils.FilterSitesCreated(sites, since, before)
+		}
+		if sortKey != "" {
+			if err := utils.SortServerSites(sites, sortKey); err != nil {
+				color.Red("Error: %v", err)
+				os.Exit(exit.Validation)
+			}
+		}
+
 		if jsonOutput {
-			sites := make([]SiteWithServer, 0)
-			for _, server := range cfg.Servers {
-				if filterServer != "" && server.Name != filterServer {
-					continue
-				}
-				for _, site := range server.Sites {
-					sites = append(sites, SiteWithServer{
-						ServerName: server.Name,
-						Site:       site,
-					})
-				}
+			result := make([]SiteWithServer, 0, len(sites))
+			for _, s := range sites {
+				result = append(result, SiteWithServer{
+					ServerName: s.Server,
+					Site:       s.Site,
+				})
 			}
-			output, err := json.MarshalIndent(sites, "", "  ")
+			output, err := json.MarshalIndent(result, "", "  ")
 			if err != nil {
 				color.Red("Error: Failed to marshal JSON: %v", err)
 				os.Exit(1)
@@ -312,13 +791,49 @@ var siteListCmd = &cobra.Command{
 			return
 		}
 
-		// Count total sites
-		totalSites := 0
-		for _, server := range cfg.Servers {
-			if filterServer != "" && server.Name != filterServer {
-				continue
+		// Prepare table data
+		headers := []string{"SERVER", "DOMAIN", "SITE ID", "STATUS", "CREATED", "SSL EXPIRES", "NOTES"}
+		rows := make([][]string, 0, len(sites))
+
+		for _, s := range sites {
+			site := s.Site
+
+			status := "live"
+			switch {
+			case !site.IsEnabled():
+				status = "disabled"
+			case site.MaintenanceMode:
+				status = "maintenance"
+			}
+
+			created := "-"
+			if !site.CreatedAt.IsZero() {
+				created = site.CreatedAt.Local().Format("2006-01-02")
+			}
+
+			expires := "-"
+			if expiry := utils.EarliestSSLExpiry(site); expiry != nil {
+				expires = expiry.Format("2006-01-02")
+			}
+
+			row := []string{
+				s.Server,
+				site.PrimaryDomain,
+				site.SiteID,
+				status,
+				created,
+				expires,
+				site.Notes,
+			}
+			rows = append(rows, row)
+		}
+
+		if isCSVOutput() {
+			if err := utils.PrintCSV(headers, rows); err != nil {
+				color.Red("Error: %v", err)
+				os.Exit(1)
 			}
-			totalSites += len(server.Sites)
+			return
 		}
 
 		if totalSites == 0 {
@@ -330,44 +845,74 @@ var siteListCmd = &cobra.Command{
 			}
 			return
 		}
+		if len(sites) == 0 {
+			fmt.Printf("No sites %s\n", strings.Join(filters, " and "))
+			return
+		}
 
 		// Display sites
-		if filterServer != "" {
+		switch {
+		case len(filters) > 0:
+			fmt.Printf("\nSites %s (%d of %d):\n\n", strings.Join(filters, " and "), len(sites), totalSites)
+		case filterServer != "":
 			fmt.Printf("\nSites on server '%s' (%d total):\n\n", filterServer, totalSites)
-		} else {
+		default:
 			fmt.Printf("\nAll sites (%d total):\n\n", totalSites)
 		}
 
-		// Prepare table data
-		headers := []string{"SERVER", "DOMAIN", "SITE ID", "NOTES"}
-		colWidths := []int{20, 35, 20, 40}
-		rows := make([][]string, 0)
-
-		for _, server := range cfg.Servers {
-			if filterServer != "" && server.Name != filterServer {
-				continue
-			}
-
-			for _, site := range server.Sites {
-				// Get notes (truncate if too long for display)
-				notesStr := site.Notes
-				if len(notesStr) > 38 {
-					notesStr = notesStr[:35] + "..."
-				}
+		utils.PrintTable(headers, rows)
+		fmt.Println()
+	},
+}
 
-				row := []string{
-					server.Name,
-					site.PrimaryDomain,
-					site.SiteID,
-					notesStr,
-				}
-				rows = append(rows, row)
+// listStaleSSL prints the domains whose stored SSL expiry has passed,
+// optionally reconciling them with the live certificates first
+func listStaleSSL(cmd *cobra.Command, mgr *config.Manager, cfg *config.Config, filterServer string, refresh, jsonOutput bool) {
+	stale := utils.FindStaleSSL(cfg.Servers, filterServer, time.Now())
+	if refresh && len(stale) > 0 {
+		if refreshStaleSSL(cmd, mgr, cfg, stale) > 0 {
+			reloaded, err := mgr.Load()
+			if err != nil {
+				color.Red("Error: Failed to load configuration: %v", err)
+				os.Exit(exit.Validation)
 			}
+			stale = utils.FindStaleSSL(reloaded.Servers, filterServer, time.Now())
 		}
+	}
 
-		utils.PrintTableWithBorders(headers, rows, colWidths)
-		fmt.Println()
-	},
+	if jsonOutput {
+		output, err := json.MarshalIndent(stale, "", "  ")
+		if err != nil {
+			color.Red("Error: Failed to marshal JSON: %v", err)
+			os.Exit(1)
+		}
+		fmt.Println(string(output))
+		return
+	}
+
+	headers := []string{"SERVER", "SITE ID", "DOMAIN", "STORED EXPIRY"}
+	rows := make([][]string, 0, len(stale))
+	for _, entry := range stale {
+		rows = append(rows, []string{entry.Server, entry.SiteID, entry.Domain, entry.ExpiresAt.Format("2006-01-02")})
+	}
+
+	if isCSVOutput() {
+		if err := utils.PrintCSV(headers, rows); err != nil {
+			color.Red("Error: %v", err)
+			os.Exit(1)
+		}
+		return
+	}
+
+	if len(stale) == 0 {
+		fmt.Println("No domains have a stale SSL expiry.")
+		return
+	}
+
+	fmt.Printf("\nDomains with a stale SSL expiry (%d total):\n\n", len(stale))
+	utils.PrintTable(headers, rows)
+	fmt.Println()
+	printStaleSSLHint(refresh)
 }
 
 // siteDeleteCmd represents the site delete command
@@ -379,19 +924,16 @@ var siteDeleteCmd = &cobra.Command{
 	Run: func(cmd *cobra.Command, args []string) {
 		mgr, err := config.NewManager()
 		if err != nil {
-			color.Red("Error: %v", err)
-			os.Exit(1)
+			fail(cmd, "Failed to create config manager", err)
 		}
 
 		if !mgr.ConfigExists() {
-			color.Red("Configuration file not found. Run 'wordsail init' first.")
-			os.Exit(1)
+			fail(cmd, "Configuration file not found", exit.Errorf(exit.ConfigNotFound, "run 'wordsail init' first"))
 		}
 
 		cfg, err := mgr.Load()
 		if err != nil {
-			color.Red("Error: Failed to load configuration: %v", err)
-			os.Exit(1)
+			fail(cmd, "Failed to load configuration", exit.New(exit.Validation, err))
 		}
 
 		// Get server and site from flags
@@ -417,10 +959,14 @@ var siteDeleteCmd = &cobra.Command{
 			}
 
 			if len(siteOptions) == 0 {
-				fmt.Println("No sites available to delete.")
+				outputInfo(cmd, "No sites available to delete.\n")
 				return
 			}
 
+			if isJSONOutput(cmd) {
+				fail(cmd, "Missing required flags", exit.Errorf(exit.Validation, "--server and --site are required in JSON mode"))
+			}
+
 			// Create selection options
 			optionStrings := make([]string, len(siteOptions))
 			for i, opt := range siteOptions {
@@ -443,49 +989,34 @@ var siteDeleteCmd = &cobra.Command{
 		}
 
 		// Find the server and site
-		var targetServer *models.Server
-		var targetSite *models.Site
-
-		for i := range cfg.Servers {
-			if cfg.Servers[i].Name == serverName {
-				targetServer = &cfg.Servers[i]
-				for j := range cfg.Servers[i].Sites {
-					if cfg.Servers[i].Sites[j].SiteID == siteName {
-						targetSite = &cfg.Servers[i].Sites[j]
-						break
-					}
-				}
-				break
-			}
-		}
-
+		targetServer := utils.FindServerByName(cfg.Servers, serverName)
 		if targetServer == nil {
-			color.Red("Error: Server '%s' not found", serverName)
-			os.Exit(1)
+			fail(cmd, "Server not found", exit.Errorf(exit.Validation, "server '%s' does not exist", serverName))
 		}
 
-		if targetSite == nil {
-			color.Red("Error: Site '%s' not found on server '%s'", siteName, serverName)
-			os.Exit(1)
+		targetSite, err := utils.ResolveSite(targetServer, siteName)
+		if err != nil {
+			fail(cmd, "Site not found", exit.New(exit.Validation, err))
 		}
+		siteName = targetSite.SiteID
 
-		// Show warning and confirm
-		color.Yellow("⚠️  WARNING: This will permanently delete:")
-		fmt.Printf("  - Site: %s (%s)\n", targetSite.PrimaryDomain, targetSite.SiteID)
-		fmt.Printf("  - Server: %s\n", serverName)
-		fmt.Printf("  - All files in /sites/%s\n", targetSite.PrimaryDomain)
-		fmt.Printf("  - Database: %s\n", targetSite.Database.Name)
-		fmt.Printf("  - Nginx configuration\n")
-		fmt.Printf("  - PHP-FPM pool\n")
-		fmt.Println()
+		if !assumeYes(cmd) {
+			if isJSONOutput(cmd) {
+				fail(cmd, "Confirmation required", exit.Errorf(exit.Validation, "use --force to delete a site in JSON mode"))
+			}
 
-		force, _ := cmd.Flags().GetBool("force")
-		if !force {
-			var confirm bool
-			if err := survey.AskOne(&survey.Confirm{
-				Message: "Are you absolutely sure you want to delete this site?",
-				Default: false,
-			}, &confirm); err != nil {
+			// Show warning and confirm
+			color.Yellow("⚠️  WARNING: This will permanently delete:")
+			fmt.Printf("  - Site: %s (%s)\n", targetSite.PrimaryDomain, targetSite.SiteID)
+			fmt.Printf("  - Server: %s\n", serverName)
+			fmt.Printf("  - All files in /sites/%s\n", targetSite.PrimaryDomain)
+			fmt.Printf("  - Database: %s\n", targetSite.Database.Name)
+			fmt.Printf("  - Nginx configuration\n")
+			fmt.Printf("  - PHP-FPM pool\n")
+			fmt.Println()
+
+			confirm, err := prompt.Confirm("Are you absolutely sure you want to delete this site?", false, false)
+			if err != nil {
 				os.Exit(1)
 			}
 
@@ -495,15 +1026,12 @@ var siteDeleteCmd = &cobra.Command{
 			}
 
 			// Double confirmation for safety
-			var doubleConfirm string
-			doublePrompt := &survey.Input{
-				Message: fmt.Sprintf("Type '%s' to confirm deletion:", targetSite.SiteID),
-			}
-			if err := survey.AskOne(doublePrompt, &doubleConfirm); err != nil {
+			confirmed, err := prompt.ConfirmDestructive(fmt.Sprintf("Type '%s' to confirm deletion:", targetSite.SiteID), targetSite.SiteID)
+			if err != nil {
 				os.Exit(1)
 			}
 
-			if doubleConfirm != targetSite.SiteID {
+			if !confirmed {
 				color.Red("Confirmation failed. Site deletion cancelled.")
 				return
 			}
@@ -515,64 +1043,2014 @@ var siteDeleteCmd = &cobra.Command{
 			"site_domain": targetSite.PrimaryDomain,
 			"db_host":     targetSite.Database.Host,
 		}
+		if targetSite.PHPVersion != "" {
+			extraVars["php_version"] = targetSite.PHPVersion
+		}
 
 		// Create Ansible executor
-		executor := ansible.NewExecutor(cfg.Ansible.Path)
-		executor.SetVerbose(Verbose)
-		executor.SetDryRun(DryRun)
+		executor := newExecutor(cmd, cfg)
 
 		// Execute delete_site tasks
-		fmt.Println()
-		color.Cyan("═══════════════════════════════════════════════════════")
-		color.Cyan("  Deleting site: %s", targetSite.PrimaryDomain)
-		color.Cyan("═══════════════════════════════════════════════════════")
-		fmt.Println()
+		outputBanner(cmd, color.Cyan, fmt.Sprintf("Deleting site: %s", targetSite.PrimaryDomain))
 
 		// Note: We need to create a playbook that includes the delete_site role
 		// For now, we'll use a direct approach
-		if err := executor.ExecutePlaybook("playbooks/delete_site.yml", *targetServer, extraVars, cfg.GlobalVars); err != nil {
-			color.Red("\n✗ Site deletion failed: %v", err)
-			color.Yellow("Note: You may need to manually clean up resources on the server")
-			os.Exit(1)
+		if _, err := executor.ExecutePlaybook("playbooks/delete_site.yml", *targetServer, extraVars, cfg.GlobalVars); err != nil {
+			outputError(cmd, "Site deletion failed", err)
+			outputInfo(cmd, "Note: You may need to manually clean up resources on the server\n")
+			os.Exit(exit.CodeOf(err))
 		}
 
 		// Remove site from configuration
+		domain := targetSite.PrimaryDomain
 		stateMgr := state.NewManager(mgr)
 		if err := stateMgr.RemoveSiteFromServer(serverName, siteName); err != nil {
-			color.Red("Warning: Failed to update configuration: %v", err)
+			outputWarning(cmd, "Failed to update configuration: %v", err)
 		}
 
-		fmt.Println()
-		color.Green("✓ Site '%s' deleted successfully", targetSite.PrimaryDomain)
+		outputInfo(cmd, "\n")
+		outputSuccess(cmd, "site_deleted", map[string]interface{}{
+			"server":  serverName,
+			"site_id": siteName,
+			"domain":  domain,
+		})
 	},
 }
 
-func init() {
-	rootCmd.AddCommand(siteCmd)
-	siteCmd.AddCommand(siteCreateCmd)
-	siteCmd.AddCommand(siteListCmd)
-	siteCmd.AddCommand(siteDeleteCmd)
+// siteUpdateWPCmd represents the site update-wp command
+var siteUpdateWPCmd = &cobra.Command{
+	Use:   "update-wp",
+	Short: "Update WordPress core, plugins and themes",
+	Long: `Update WordPress core, plugins and themes with WP-CLI over SSH, running as
+the site user. Versions before and after the update are printed.
 
-	// site create flags
-	siteCreateCmd.Flags().Bool("non-interactive", false, "Use flags instead of interactive prompts")
-	siteCreateCmd.Flags().String("server", "", "Target server name")
-	siteCreateCmd.Flags().String("domain", "", "Primary domain")
-	siteCreateCmd.Flags().String("site-id", "", "Site identifier (optional, auto-generated from domain if not provided)")
-	siteCreateCmd.Flags().String("admin-user", "", "WordPress admin username")
-	siteCreateCmd.Flags().String("admin-email", "", "WordPress admin email")
-	siteCreateCmd.Flags().String("admin-password", "", "WordPress admin password")
-	siteCreateCmd.Flags().Bool("no-ssl", false, "Skip automatic SSL certificate issuance")
+With --dry-run, available updates are listed without applying them.
 
-	// site create json flag
-	siteCreateCmd.Flags().Bool("json", false, "Output in JSON format")
+Examples:
+  # Update everything on one site
+  wordsail site update-wp --server production-1 --site mysite
 
-	// site list flags
-	siteListCmd.Flags().String("server", "", "Filter by server name")
-	siteListCmd.Flags().Bool("json", false, "Output in JSON format")
+  # List available plugin updates for every site on a server
+  wordsail site update-wp --server production-1 --all --plugins-only --dry-run
 
-	// site delete flags
-	siteDeleteCmd.Flags().String("server", "", "Server name")
-	siteDeleteCmd.Flags().String("site", "", "Site ID")
-	siteDeleteCmd.Flags().BoolP("force", "f", false, "Force deletion without confirmation")
-	siteDeleteCmd.Flags().Bool("json", false, "Output in JSON format")
+  # Only update WordPress core
+  wordsail site update-wp --server production-1 --site mysite --core-only`,
+	Run: func(cmd *cobra.Command, args []string) {
+		mgr, err := config.NewManager()
+		if err != nil {
+			fail(cmd, "Failed to create config manager", err)
+		}
+
+		if !mgr.ConfigExists() {
+			fail(cmd, "Configuration file not found", exit.Errorf(exit.ConfigNotFound, "run 'wordsail init' first"))
+		}
+
+		cfg, err := mgr.Load()
+		if err != nil {
+			fail(cmd, "Failed to load configuration", exit.New(exit.Validation, err))
+		}
+
+		serverName, _ := cmd.Flags().GetString("server")
+		siteID, _ := cmd.Flags().GetString("site")
+		all, _ := cmd.Flags().GetBool("all")
+		coreOnly, _ := cmd.Flags().GetBool("core-only")
+		pluginsOnly, _ := cmd.Flags().GetBool("plugins-only")
+
+		if coreOnly && pluginsOnly {
+			fail(cmd, "Invalid flags", exit.Errorf(exit.Validation, "--core-only and --plugins-only cannot be combined"))
+		}
+		components := []string{utils.WPComponentCore, utils.WPComponentPlugin, utils.WPComponentTheme}
+		if coreOnly {
+			components = []string{utils.WPComponentCore}
+		} else if pluginsOnly {
+			components = []string{utils.WPComponentPlugin}
+		}
+
+		if all && siteID != "" {
+			fail(cmd, "Invalid flags", exit.Errorf(exit.Validation, "--all cannot be combined with --site"))
+		}
+		if all && serverName == "" {
+			fail(cmd, "Missing required flags", exit.Errorf(exit.Validation, "--server is required with --all"))
+		}
+
+		// Select a site interactively when not given
+		if !all && (serverName == "" || siteID == "") {
+			if isJSONOutput(cmd) {
+				fail(cmd, "Missing required flags", exit.Errorf(exit.Validation, "--server and --site (or --all) are required in JSON mode"))
+			}
+
+			type siteOption struct {
+				serverName string
+				siteID     string
+			}
+			var options []siteOption
+			var labels []string
+			for _, server := range cfg.Servers {
+				if serverName != "" && server.Name != serverName {
+					continue
+				}
+				for _, site := range server.Sites {
+					if site.NoWordPress {
+						continue
+					}
+					options = append(options, siteOption{server.Name, site.SiteID})
+					labels = append(labels, fmt.Sprintf("%s on %s (%s)", site.PrimaryDomain, server.Name, site.SiteID))
+				}
+			}
+			if len(options) == 0 {
+				outputInfo(cmd, "No sites available to update.\n")
+				return
+			}
+
+			var selected int
+			if err := survey.AskOne(&survey.Select{
+				Message: "Select site to update:",
+				Options: labels,
+			}, &selected); err != nil {
+				os.Exit(1)
+			}
+			serverName = options[selected].serverName
+			siteID = options[selected].siteID
+		}
+
+		targetServer := utils.FindServerByName(cfg.Servers, serverName)
+		if targetServer == nil {
+			fail(cmd, "Server not found", exit.Errorf(exit.Validation, "server '%s' not found", serverName))
+		}
+
+		// Bare sites have no WordPress to update
+		var sites []models.Site
+		if all {
+			for _, site := range targetServer.Sites {
+				if !site.NoWordPress {
+					sites = append(sites, site)
+				}
+			}
+		} else {
+			site, err := utils.ResolveSite(targetServer, siteID)
+			if err != nil {
+				fail(cmd, "Site not found", exit.New(exit.Validation, err))
+			}
+			if site.NoWordPress {
+				fail(cmd, "Not a WordPress site", exit.Errorf(exit.Validation, "site '%s' was created with --no-wordpress", site.SiteID))
+			}
+			sites = []models.Site{*site}
+		}
+		if len(sites) == 0 {
+			outputInfo(cmd, "No sites on server '%s'.\n", serverName)
+			return
+		}
+
+		// Keep going after a failure so one broken site doesn't block the rest
+		var failed []string
+		results := make([]map[string]interface{}, 0, len(sites))
+		for _, site := range sites {
+			outputInfo(cmd, "\n%s (%s)\n", color.CyanString(site.PrimaryDomain), site.SiteID)
+
+			updates, err := updateSiteWordPress(*targetServer, site, components)
+			entry := map[string]interface{}{
+				"site_id": site.SiteID,
+				"domain":  site.PrimaryDomain,
+				"updates": updates,
+			}
+			if err != nil {
+				failed = append(failed, site.SiteID)
+				entry["error"] = err.Error()
+				outputInfo(cmd, "  %s %v\n", color.RedString("✗"), err)
+				results = append(results, entry)
+				continue
+			}
+
+			if len(updates) == 0 {
+				outputInfo(cmd, "  Everything is up to date\n")
+			}
+			for _, update := range updates {
+				outputInfo(cmd, "  %-7s %-30s %s → %s\n", update.Type, update.Name, update.Current, color.GreenString(update.New))
+			}
+			results = append(results, entry)
+		}
+		outputInfo(cmd, "\n")
+
+		if len(failed) > 0 {
+			fail(cmd, "WordPress update failed on some sites", fmt.Errorf("%s", strings.Join(failed, ", ")))
+		}
+
+		outputSuccess(cmd, "wp_updated", map[string]interface{}{
+			"server":  serverName,
+			"sites":   results,
+			"dry_run": DryRun,
+		})
+	},
+}
+
+// updateSiteWordPress lists available updates in dry-run mode, otherwise
+// applies them and returns the versions that changed
+func updateSiteWordPress(server models.Server, site models.Site, components []string) ([]utils.WPUpdate, error) {
+	if DryRun {
+		return utils.CheckWPUpdates(server, site, components)
+	}
+
+	before, err := utils.WPInstalledVersions(server, site)
+	if err != nil {
+		return nil, err
+	}
+	if err := utils.ApplyWPUpdates(server, site, components); err != nil {
+		return nil, err
+	}
+	after, err := utils.WPInstalledVersions(server, site)
+	if err != nil {
+		return nil, err
+	}
+	return utils.DiffWPVersions(before, after), nil
+}
+
+// siteMaintenanceCmd represents the site maintenance command
+var siteMaintenanceCmd = &cobra.Command{
+	Use:   "maintenance <on|off>",
+	Short: "Turn maintenance mode on or off",
+	Long: `Put a site into (or take it out of) maintenance mode.
+
+While maintenance mode is on, Nginx answers every request for the site's
+domains with a 503 and a static maintenance page. Let's Encrypt challenges are
+still served so certificates can renew.
+
+Examples:
+  # Show the maintenance page during a deploy
+  wordsail site maintenance on --server production-1 --site mysite
+
+  # Use a custom message
+  wordsail site maintenance on --server production-1 --site mysite --message "Back at 14:00 UTC"
+
+  # Bring the site back
+  wordsail site maintenance off --server production-1 --site mysite`,
+	Args:      cobra.MatchAll(cobra.ExactArgs(1), cobra.OnlyValidArgs),
+	ValidArgs: []string{"on", "off"},
+	Run: func(cmd *cobra.Command, args []string) {
+		mgr, err := config.NewManager()
+		if err != nil {
+			fail(cmd, "Failed to create config manager", err)
+		}
+
+		if !mgr.ConfigExists() {
+			fail(cmd, "Configuration file not found", exit.Errorf(exit.ConfigNotFound, "run 'wordsail init' first"))
+		}
+
+		cfg, err := mgr.Load()
+		if err != nil {
+			fail(cmd, "Failed to load configuration", exit.New(exit.Validation, err))
+		}
+
+		enable := args[0] == "on"
+		serverName, _ := cmd.Flags().GetString("server")
+		siteID, _ := cmd.Flags().GetString("site")
+		message, _ := cmd.Flags().GetString("message")
+
+		if serverName == "" || siteID == "" {
+			fail(cmd, "Missing required flags", exit.Errorf(exit.Validation, "--server and --site are required"))
+		}
+		if message != "" && !enable {
+			fail(cmd, "Invalid flags", exit.Errorf(exit.Validation, "--message can only be used with 'on'"))
+		}
+
+		server := utils.FindServerByName(cfg.Servers, serverName)
+		if server == nil {
+			fail(cmd, "Server not found", exit.Errorf(exit.Validation, "server '%s' does not exist", serverName))
+		}
+		site, err := utils.ResolveSite(server, siteID)
+		if err != nil {
+			fail(cmd, "Site not found", exit.New(exit.Validation, err))
+		}
+		siteID = site.SiteID
+
+		domains := make([]string, 0, len(site.Domains))
+		for _, d := range site.Domains {
+			domains = append(domains, d.Domain)
+		}
+		if len(domains) == 0 {
+			domains = []string{site.PrimaryDomain}
+		}
+
+		extraVars := map[string]interface{}{
+			"site_domain":       site.PrimaryDomain,
+			"domains":           domains,
+			"maintenance_state": args[0],
+		}
+		if message != "" {
+			extraVars["maintenance_message"] = message
+		}
+
+		executor := newExecutor(cmd, cfg)
+		outputBanner(cmd, color.Cyan, fmt.Sprintf("Turning maintenance mode %s for: %s", args[0], site.PrimaryDomain))
+
+		if _, err := executor.ExecutePlaybook("playbooks/maintenance.yml", *server, extraVars, cfg.GlobalVars); err != nil {
+			fail(cmd, "Failed to change maintenance mode", err)
+		}
+
+		stateMgr := state.NewManager(mgr)
+		if err := stateMgr.SetSiteMaintenance(serverName, siteID, enable); err != nil {
+			fail(cmd, "Maintenance mode changed but failed to update configuration", err)
+		}
+
+		outputSuccess(cmd, "site_maintenance", map[string]interface{}{
+			"server":      serverName,
+			"site_id":     siteID,
+			"domain":      site.PrimaryDomain,
+			"maintenance": enable,
+		})
+	},
+}
+
+// siteEnableCmd represents the site enable command
+var siteEnableCmd = &cobra.Command{
+	Use:   "enable",
+	Short: "Serve a disabled site again",
+	Long: `Link a disabled site's Nginx configs back into sites-enabled and reload
+Nginx, so its domains are served again.
+
+Examples:
+  wordsail site enable --server production-1 --site mysite`,
+	Run: func(cmd *cobra.Command, args []string) {
+		setSiteEnabled(cmd, true)
+	},
+}
+
+// siteDisableCmd represents the site disable command
+var siteDisableCmd = &cobra.Command{
+	Use:   "disable",
+	Short: "Take a site offline without deleting it",
+	Long: `Remove a site's Nginx configs from sites-enabled and reload Nginx, so none
+of its domains are served. Unlike maintenance mode no page is shown, and
+unlike delete the files, database, PHP-FPM pool and Nginx configs are kept;
+'site enable' brings the site back.
+
+Certificates cannot renew while the site is disabled.
+
+Examples:
+  wordsail site disable --server production-1 --site mysite
+  wordsail site disable --server production-1 --site mysite --force`,
+	Run: func(cmd *cobra.Command, args []string) {
+		setSiteEnabled(cmd, false)
+	},
+}
+
+// setSiteEnabled runs site enable or site disable
+func setSiteEnabled(cmd *cobra.Command, enable bool) {
+	mgr, err := config.NewManager()
+	if err != nil {
+		fail(cmd, "Failed to create config manager", err)
+	}
+
+	if !mgr.ConfigExists() {
+		fail(cmd, "Configuration file not found", exit.Errorf(exit.ConfigNotFound, "run 'wordsail init' first"))
+	}
+
+	cfg, err := mgr.Load()
+	if err != nil {
+		fail(cmd, "Failed to load configuration", exit.New(exit.Validation, err))
+	}
+
+	server, site := requireServerSite(cmd, cfg)
+
+	domains := make([]string, 0, len(site.Domains))
+	for _, d := range site.Domains {
+		domains = append(domains, d.Domain)
+	}
+	if len(domains) == 0 {
+		domains = []string{site.PrimaryDomain}
+	}
+
+	action, siteState := "Enabling", "enabled"
+	if !enable {
+		action, siteState = "Disabling", "disabled"
+
+		if !assumeYes(cmd) {
+			if isJSONOutput(cmd) {
+				fail(cmd, "Confirmation required", exit.Errorf(exit.Validation, "use --force to disable a site in JSON mode"))
+			}
+			color.Yellow("\n⚠️  %s will stop serving: %s", site.SiteID, strings.Join(domains, ", "))
+			confirm, err := prompt.Confirm("Disable this site?", false, false)
+			if err != nil {
+				os.Exit(1)
+			}
+			if !confirm {
+				fmt.Println("Site disable cancelled")
+				return
+			}
+		}
+	}
+
+	extraVars := map[string]interface{}{
+		"domains":    domains,
+		"site_state": siteState,
+	}
+
+	executor := newExecutor(cmd, cfg)
+	outputBanner(cmd, color.Cyan, fmt.Sprintf("%s site: %s", action, site.PrimaryDomain))
+
+	if _, err := executor.ExecutePlaybook("playbooks/site_state.yml", *server, extraVars, cfg.GlobalVars); err != nil {
+		fail(cmd, fmt.Sprintf("Failed to %s site", strings.TrimSuffix(siteState, "d")), err)
+	}
+
+	stateMgr := state.NewManager(mgr)
+	if err := stateMgr.SetSiteEnabled(server.Name, site.SiteID, enable); err != nil {
+		fail(cmd, fmt.Sprintf("Site %s but failed to update configuration", siteState), err)
+	}
+
+	outputSuccess(cmd, "site_"+siteState, map[string]interface{}{
+		"server":  server.Name,
+		"site_id": site.SiteID,
+		"domain":  site.PrimaryDomain,
+		"enabled": enable,
+	})
+}
+
+// siteInfoCmd represents the site info command
+var siteInfoCmd = &cobra.Command{
+	Use:   "info",
+	Short: "Show site details",
+	Long: `Display the stored configuration for a site.
+
+Examples:
+  wordsail site info --server production-1 --site mysite
+  wordsail site info --server production-1 --site mysite --json
+  wordsail site info --server production-1 --site mysite --output yaml`,
+	Annotations: map[string]string{yamlAnnotation: ""},
+	Run: func(cmd *cobra.Command, args []string) {
+		mgr, err := config.NewManager()
+		if err != nil {
+			fail(cmd, "Failed to create config manager", err)
+		}
+
+		if !mgr.ConfigExists() {
+			fail(cmd, "Configuration file not found", exit.Errorf(exit.ConfigNotFound, "run 'wordsail init' first"))
+		}
+
+		cfg, err := mgr.Load()
+		if err != nil {
+			fail(cmd, "Failed to load configuration", exit.New(exit.Validation, err))
+		}
+
+		serverName, _ := cmd.Flags().GetString("server")
+		siteID, _ := cmd.Flags().GetString("site")
+		if serverName == "" || siteID == "" {
+			fail(cmd, "Missing required flags", exit.Errorf(exit.Validation, "--server and --site are required"))
+		}
+
+		server := utils.FindServerByName(cfg.Servers, serverName)
+		if server == nil {
+			fail(cmd, "Server not found", exit.Errorf(exit.Validation, "server '%s' does not exist", serverName))
+		}
+		site, err := utils.ResolveSite(server, siteID)
+		if err != nil {
+			fail(cmd, "Site not found", exit.New(exit.Validation, err))
+		}
+
+		if isJSONOutput(cmd) || isYAMLOutput() {
+			printObject(cmd, SiteWithServer{ServerName: server.Name, Site: withoutSecrets(*site)})
+			return
+		}
+
+		status := "live"
+		switch {
+		case !site.IsEnabled():
+			status = color.RedString("disabled")
+		case site.MaintenanceMode:
+			status = color.YellowString("maintenance")
+		}
+
+		fmt.Println()
+		fmt.Printf("Site ID:      %s\n", site.SiteID)
+		fmt.Printf("Server:       %s\n", server.Name)
+		fmt.Printf("Domain:       %s\n", site.PrimaryDomain)
+		fmt.Printf("Status:       %s\n", status)
+		fmt.Printf("Created:      %s\n", site.CreatedAt.Format("2006-01-02 15:04"))
+		if site.NoWordPress {
+			fmt.Printf("WordPress:    no (bare site)\n")
+		} else {
+			fmt.Printf("Admin:        %s <%s>\n", site.AdminUser, site.AdminEmail)
+		}
+		fmt.Printf("Database:     %s@%s\n", site.Database.Name, site.Database.Host)
+		if site.PHPVersion != "" {
+			fmt.Printf("PHP:          %s\n", site.PHPVersion)
+		}
+		fmt.Printf("Domains:      %d\n", len(site.Domains))
+		for _, d := range site.Domains {
+			ssl := "no SSL"
+			if d.SSLEnabled {
+				ssl = "SSL"
+			}
+			fmt.Printf("  - %s (%s)\n", d.Domain, ssl)
+		}
+		if site.Notes != "" {
+			fmt.Printf("Notes:        %s\n", site.Notes)
+		}
+		fmt.Println()
+	},
+}
+
+// sitePHPExtCmd represents the site php-ext command
+var sitePHPExtCmd = &cobra.Command{
+	Use:   "php-ext",
+	Short: "Manage optional PHP extensions for a site",
+	Long: `Enable, disable and list optional PHP extensions such as imagick, intl,
+soap or redis that some plugins require.
+
+Extensions are installed server-wide for the site's PHP version; wordsail
+records which sites need each one so disabling it for one site leaves it
+loaded while another site still uses it.`,
+}
+
+// sitePHPExtEnableCmd represents the site php-ext enable command
+var sitePHPExtEnableCmd = &cobra.Command{
+	Use:   "enable <extension>",
+	Short: "Install and enable a PHP extension",
+	Long: `Install and enable a PHP extension, reload PHP-FPM and check that the
+module is loaded (php -m).
+
+Examples:
+  wordsail site php-ext enable imagick --server production-1 --site mysite`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		runSitePHPExt(cmd, args[0], true)
+	},
+}
+
+// sitePHPExtDisableCmd represents the site php-ext disable command
+var sitePHPExtDisableCmd = &cobra.Command{
+	Use:   "disable <extension>",
+	Short: "Disable a PHP extension",
+	Long: `Disable a PHP extension and reload PHP-FPM. The package stays installed.
+
+If another site on the server still has the extension enabled, only this
+site's record is updated and the module stays loaded.
+
+Examples:
+  wordsail site php-ext disable soap --server production-1 --site mysite`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		runSitePHPExt(cmd, args[0], false)
+	},
+}
+
+// runSitePHPExt enables or disables an extension for a site and verifies the
+// result on the server
+func runSitePHPExt(cmd *cobra.Command, extension string, enable bool) {
+	mgr, err := config.NewManager()
+	if err != nil {
+		fail(cmd, "Failed to create config manager", err)
+	}
+
+	if !mgr.ConfigExists() {
+		fail(cmd, "Configuration file not found", exit.Errorf(exit.ConfigNotFound, "run 'wordsail init' first"))
+	}
+
+	cfg, err := mgr.Load()
+	if err != nil {
+		fail(cmd, "Failed to load configuration", exit.New(exit.Validation, err))
+	}
+
+	if err := utils.ValidatePHPExtension(extension); err != nil {
+		fail(cmd, "Invalid extension", exit.New(exit.Validation, err))
+	}
+
+	server, site := requireServerSite(cmd, cfg)
+
+	extState := "enabled"
+	if !enable {
+		extState = "disabled"
+	}
+
+	// Another site still needs it: only this site's record changes
+	usedBy := utils.SitesUsingPHPExtension(server, extension, site.SiteID)
+	runPlaybook := enable || len(usedBy) == 0
+	if !runPlaybook {
+		outputInfo(cmd, "%s is still used by: %s; leaving it enabled on the server\n", extension, strings.Join(usedBy, ", "))
+	}
+
+	verified := false
+	if runPlaybook {
+		extraVars := map[string]interface{}{
+			"php_extension":       extension,
+			"php_extension_state": extState,
+		}
+		if site.PHPVersion != "" {
+			extraVars["php_version"] = site.PHPVersion
+		}
+
+		executor := newExecutor(cmd, cfg)
+		outputBanner(cmd, color.Cyan, fmt.Sprintf("Setting PHP extension %s to %s on: %s", extension, extState, server.Name))
+
+		if _, err := executor.ExecutePlaybook("playbooks/php_extension.yml", *server, extraVars, cfg.GlobalVars); err != nil {
+			fail(cmd, "Failed to change PHP extension", err)
+		}
+
+		if !DryRun {
+			modules, err := utils.LoadedPHPModules(*server, site.PHPVersion)
+			if err != nil {
+				fail(cmd, "Failed to verify PHP extension", err)
+			}
+			loaded := utils.HasPHPModule(modules, extension)
+			if enable && !loaded {
+				fail(cmd, "PHP extension verification failed", fmt.Errorf("php -m does not list %s after enabling it", extension))
+			}
+			if !enable && loaded {
+				fail(cmd, "PHP extension verification failed", fmt.Errorf("php -m still lists %s after disabling it", extension))
+			}
+			verified = true
+		}
+	}
+
+	stateMgr := state.NewManager(mgr)
+	if err := stateMgr.SetSitePHPExtension(server.Name, site.SiteID, extension, enable); err != nil {
+		fail(cmd, "PHP extension changed but failed to update configuration", err)
+	}
+
+	outputSuccess(cmd, "site_php_extension", map[string]interface{}{
+		"server":    server.Name,
+		"site_id":   site.SiteID,
+		"extension": extension,
+		"enabled":   enable,
+		"verified":  verified,
+	})
+}
+
+// phpExtStatus is one row of site php-ext list
+type phpExtStatus struct {
+	Extension string `json:"extension"`
+	Site      bool   `json:"enabled_for_site"`
+	Loaded    *bool  `json:"loaded"`
+}
+
+// sitePHPExtListCmd represents the site php-ext list command
+var sitePHPExtListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List available and enabled PHP extensions",
+	Long: `List the PHP extensions wordsail can manage, which ones the site has
+enabled, and which are currently loaded on the server.
+
+Examples:
+  wordsail site php-ext list --server production-1 --site mysite`,
+	Run: func(cmd *cobra.Command, args []string) {
+		mgr, err := config.NewManager()
+		if err != nil {
+			fail(cmd, "Failed to create config manager", err)
+		}
+
+		if !mgr.ConfigExists() {
+			fail(cmd, "Configuration file not found", exit.Errorf(exit.ConfigNotFound, "run 'wordsail init' first"))
+		}
+
+		cfg, err := mgr.Load()
+		if err != nil {
+			fail(cmd, "Failed to load configuration", exit.New(exit.Validation, err))
+		}
+
+		server, site := requireServerSite(cmd, cfg)
+
+		// Loaded state is best effort; the site's record is still useful offline
+		modules, loadErr := utils.LoadedPHPModules(*server, site.PHPVersion)
+		if loadErr != nil {
+			outputWarning(cmd, "Could not read loaded modules from the server: %v", loadErr)
+		}
+
+		statuses := make([]phpExtStatus, 0, len(utils.KnownPHPExtensions))
+		for _, ext := range utils.KnownPHPExtensions {
+			status := phpExtStatus{Extension: ext}
+			for _, e := range site.PHPExtensions {
+				if e == ext {
+					status.Site = true
+				}
+			}
+			if loadErr == nil {
+				loaded := utils.HasPHPModule(modules, ext)
+				status.Loaded = &loaded
+			}
+			statuses = append(statuses, status)
+		}
+
+		if isJSONOutput(cmd) {
+			output, err := json.MarshalIndent(statuses, "", "  ")
+			if err != nil {
+				fail(cmd, "Failed to marshal JSON", err)
+			}
+			fmt.Println(string(output))
+			return
+		}
+
+		fmt.Printf("\nPHP extensions for site '%s' on '%s':\n\n", site.SiteID, server.Name)
+
+		headers := []string{"EXTENSION", "SITE", "LOADED"}
+		colWidths := []int{15, 8, 8}
+		rows := make([][]string, 0, len(statuses))
+		for _, status := range statuses {
+			enabled := "-"
+			if status.Site {
+				enabled = color.GreenString("enabled")
+			}
+			loaded := "?"
+			if status.Loaded != nil {
+				loaded = "no"
+				if *status.Loaded {
+					loaded = "yes"
+				}
+			}
+			rows = append(rows, []string{status.Extension, enabled, loaded})
+		}
+
+		utils.PrintTableWithBorders(headers, rows, colWidths)
+		fmt.Println()
+	},
+}
+
+// siteVerifyCmd represents the site verify command
+var siteVerifyCmd = &cobra.Command{
+	Use:   "verify",
+	Short: "Check a live site's HTTP and SSL from this machine",
+	Long: `Request each of a site's domains from this machine, as a visitor would, and
+check the answer: the status code (redirects count as working; a 503 is
+expected in maintenance mode), and for domains with SSL that the served
+certificate is for the domain, has not expired, chains to a trusted root
+and expires when the config says it does.
+
+Unlike 'server health-check', which checks services over SSH, this only
+looks at what the outside world sees, e.g. to validate a deploy or a DNS
+or CDN change. Exits with 1 if any domain fails.
+
+Examples:
+  wordsail site verify --server production-1 --site mysite
+  wordsail site verify --server production-1 --site example.com --json`,
+	Run: func(cmd *cobra.Command, args []string) {
+		mgr, err := config.NewManager()
+		if err != nil {
+			fail(cmd, "Failed to create config manager", err)
+		}
+
+		if !mgr.ConfigExists() {
+			fail(cmd, "Configuration file not found", exit.Errorf(exit.ConfigNotFound, "run 'wordsail init' first"))
+		}
+
+		cfg, err := mgr.Load()
+		if err != nil {
+			fail(cmd, "Failed to load configuration", exit.New(exit.Validation, err))
+		}
+
+		server, site := requireServerSite(cmd, cfg)
+		if !site.IsEnabled() {
+			fail(cmd, "Site is disabled", exit.Errorf(exit.Validation, "site '%s' is disabled; run 'wordsail site enable' first", site.SiteID))
+		}
+		timeout, _ := cmd.Flags().GetDuration("timeout")
+		if timeout <= 0 {
+			fail(cmd, "Invalid --timeout", exit.Errorf(exit.Validation, "--timeout must be positive"))
+		}
+
+		outputInfo(cmd, "\nVerifying %s (%s on %s) from this machine:\n\n", site.PrimaryDomain, site.SiteID, server.Name)
+
+		// Configs written before domains were tracked only have the primary one
+		domains := site.Domains
+		if len(domains) == 0 {
+			domains = []models.Domain{{Domain: site.PrimaryDomain}}
+		}
+
+		client := utils.NewVerifyClient(timeout)
+		results := make([]utils.DomainVerification, 0, len(domains))
+		failed := 0
+		for _, d := range domains {
+			v := utils.VerifyDomain(context.Background(), client, d, site.MaintenanceMode, nil)
+			results = append(results, v)
+			if !v.Passed() {
+				failed++
+			}
+			if !isJSONOutput(cmd) {
+				printDomainVerification(v)
+			}
+		}
+		outputInfo(cmd, "\n")
+
+		if failed > 0 {
+			if isJSONOutput(cmd) {
+				printResult(CommandResult{
+					Success:  false,
+					Action:   "site_verified",
+					Message:  fmt.Sprintf("%d of %d domain(s) failed verification", failed, len(results)),
+					Error:    "site verification failed",
+					Data:     map[string]interface{}{"site_id": site.SiteID, "domains": results},
+					ExitCode: exit.General,
+				})
+			} else {
+				color.Red("✗ %d of %d domain(s) failed verification", failed, len(results))
+			}
+			os.Exit(exit.General)
+		}
+
+		outputSuccess(cmd, "site_verified", map[string]interface{}{
+			"site_id": site.SiteID,
+			"count":   len(results),
+			"domains": results,
+		})
+	},
+}
+
+// printDomainVerification prints the result line of one domain of
+// `site verify`, followed by its problems and warnings
+func printDomainVerification(v utils.DomainVerification) {
+	var parts []string
+	if v.StatusCode != 0 {
+		parts = append(parts, fmt.Sprintf("HTTP %d", v.StatusCode))
+	}
+	if v.Location != "" {
+		parts = append(parts, "→ "+v.Location)
+	}
+	if v.CertExpiresAt != nil {
+		parts = append(parts, "certificate expires "+v.CertExpiresAt.Format("2006-01-02"))
+	}
+	line := v.URL
+	if len(parts) > 0 {
+		line += "  " + strings.Join(parts, ", ")
+	}
+
+	if v.Passed() {
+		fmt.Printf("  %s %s\n", color.GreenString("✓"), line)
+	} else {
+		fmt.Printf("  %s %s\n", color.RedString("✗"), line)
+	}
+	for _, problem := range v.Problems {
+		color.Red("      %s", problem)
+	}
+	for _, warning := range v.Warnings {
+		color.Yellow("      %s", warning)
+	}
+}
+
+// requireServerSite resolves the --server and --site flags or exits
+func requireServerSite(cmd *cobra.Command, cfg *config.Config) (*models.Server, *models.Site) {
+	serverName, _ := cmd.Flags().GetString("server")
+	siteID, _ := cmd.Flags().GetString("site")
+	if serverName == "" || siteID == "" {
+		fail(cmd, "Missing required flags", exit.Errorf(exit.Validation, "--server and --site are required"))
+	}
+
+	server := utils.FindServerByName(cfg.Servers, serverName)
+	if server == nil {
+		fail(cmd, "Server not found", exit.Errorf(exit.Validation, "server '%s' does not exist", serverName))
+	}
+	site, err := utils.ResolveSite(server, siteID)
+	if err != nil {
+		fail(cmd, "Site not found", exit.New(exit.Validation, err))
+	}
+	return server, site
+}
+
+// siteResetPasswordCmd represents the site reset-password command
+var siteResetPasswordCmd = &cobra.Command{
+	Use:   "reset-password",
+	Short: "Reset a WordPress user's password",
+	Long: `Set a new, generated password for a WordPress user (the site's admin by
+default) with WP-CLI over SSH. The password is printed once and is never
+stored in the config file.
+
+Examples:
+  wordsail site reset-password --server production-1 --site mysite
+  wordsail site reset-password --server production-1 --site mysite --user client
+
+  # Reset a different account and record it as the site's admin user
+  wordsail site reset-password --server production-1 --site mysite --user owner --set-admin`,
+	Run: func(cmd *cobra.Command, args []string) {
+		mgr, err := config.NewManager()
+		if err != nil {
+			fail(cmd, "Failed to create config manager", err)
+		}
+
+		if !mgr.ConfigExists() {
+			fail(cmd, "Configuration file not found", exit.Errorf(exit.ConfigNotFound, "run 'wordsail init' first"))
+		}
+
+		cfg, err := mgr.Load()
+		if err != nil {
+			fail(cmd, "Failed to load configuration", exit.New(exit.Validation, err))
+		}
+
+		server, site := requireServerSite(cmd, cfg)
+		if site.NoWordPress {
+			fail(cmd, "Not a WordPress site", exit.Errorf(exit.Validation, "site '%s' was created with --no-wordpress", site.SiteID))
+		}
+
+		user, _ := cmd.Flags().GetString("user")
+		setAdmin, _ := cmd.Flags().GetBool("set-admin")
+		if user == "" {
+			if setAdmin {
+				fail(cmd, "Invalid flags", exit.Errorf(exit.Validation, "--set-admin requires --user"))
+			}
+			user = site.AdminUser
+		}
+		if err := utils.ValidateWPLogin(user); err != nil {
+			fail(cmd, "Invalid --user", exit.New(exit.Validation, err))
+		}
+
+		if !assumeYes(cmd) {
+			if isJSONOutput(cmd) {
+				fail(cmd, "Confirmation required", exit.Errorf(exit.Validation, "use --force to reset a password in JSON mode"))
+			}
+			confirm, err := prompt.Confirm(fmt.Sprintf("Reset the password of '%s' on %s? The current password stops working.", user, site.PrimaryDomain), false, false)
+			if err != nil {
+				os.Exit(1)
+			}
+			if !confirm {
+				outputInfo(cmd, "Cancelled.\n")
+				return
+			}
+		}
+
+		if DryRun {
+			outputInfo(cmd, "Dry run: would reset the password of '%s' on %s\n", user, site.PrimaryDomain)
+			return
+		}
+
+		password := prompt.GenerateSecurePassword(20)
+		if err := utils.ResetWPUserPassword(*server, *site, user, password); err != nil {
+			fail(cmd, "Failed to reset password", err)
+		}
+
+		if setAdmin && user != site.AdminUser {
+			stateMgr := state.NewManager(mgr)
+			if err := stateMgr.SetSiteAdminUser(server.Name, site.SiteID, user); err != nil {
+				outputWarning(cmd, "Failed to update configuration: %v", err)
+			}
+		}
+
+		if isJSONOutput(cmd) {
+			outputSuccess(cmd, "site_password_reset", map[string]interface{}{
+				"server":    server.Name,
+				"site_id":   site.SiteID,
+				"domain":    site.PrimaryDomain,
+				"user":      user,
+				"password":  password,
+				"admin_url": fmt.Sprintf("https://%s/wp-admin", site.PrimaryDomain),
+			})
+			return
+		}
+
+		outputBanner(cmd, color.Green, fmt.Sprintf("✓ Password reset for '%s' on %s", user, site.PrimaryDomain))
+		fmt.Printf("User:          %s\n", user)
+		fmt.Printf("Password:      %s\n", password)
+		fmt.Println()
+		color.Yellow("⚠️  This password is shown only once. Save it securely!")
+	},
+}
+
+// siteDBCmd represents the site db command
+var siteDBCmd = &cobra.Command{
+	Use:   "db",
+	Short: "Manage a site's database",
+	Long: `Show a site's database connection details, rotate its password, and export
+or import dumps over SSH.
+
+Rotated passwords are stored in the config file encrypted with
+~/.wordsail/secret.key. The key is not part of config backups; keep a copy
+of it to read stored passwords on another machine.`,
+}
+
+// siteDBInfoCmd represents the site db info command
+var siteDBInfoCmd = &cobra.Command{
+	Use:   "info",
+	Short: "Show a site's database connection details",
+	Long: `Show the database name, user and host of a site. The password is only
+known once it has been rotated with 'site db rotate-password', and is only
+printed with --show-password.
+
+Examples:
+  wordsail site db info --server production-1 --site mysite
+  wordsail site db info --server production-1 --site mysite --show-password --json
+  wordsail site db info --server production-1 --site mysite --output yaml`,
+	Annotations: map[string]string{yamlAnnotation: ""},
+	Run: func(cmd *cobra.Command, args []string) {
+		mgr, err := config.NewManager()
+		if err != nil {
+			fail(cmd, "Failed to create config manager", err)
+		}
+
+		if !mgr.ConfigExists() {
+			fail(cmd, "Configuration file not found", exit.Errorf(exit.ConfigNotFound, "run 'wordsail init' first"))
+		}
+
+		cfg, err := mgr.Load()
+		if err != nil {
+			fail(cmd, "Failed to load configuration", exit.New(exit.Validation, err))
+		}
+
+		server, site := requireServerSite(cmd, cfg)
+		db := site.Database
+		showPassword, _ := cmd.Flags().GetBool("show-password")
+
+		password := ""
+		if showPassword && db.EncryptedPassword != "" {
+			password, err = config.DecryptSecret(db.EncryptedPassword)
+			if err != nil {
+				fail(cmd, "Failed to decrypt the database password", err)
+			}
+		}
+
+		if isJSONOutput(cmd) || isYAMLOutput() {
+			data := map[string]interface{}{
+				"server":          server.Name,
+				"site_id":         site.SiteID,
+				"name":            db.Name,
+				"user":            db.User,
+				"host":            db.Host,
+				"adopted":         db.Adopted,
+				"password_stored": db.EncryptedPassword != "",
+			}
+			if db.PasswordRotatedAt != nil {
+				data["password_rotated_at"] = db.PasswordRotatedAt.Format(time.RFC3339)
+			}
+			if password != "" {
+				data["password"] = password
+			}
+			printObject(cmd, data)
+			return
+		}
+
+		fmt.Println()
+		fmt.Printf("Database:     %s\n", db.Name)
+		fmt.Printf("User:         %s\n", db.User)
+		fmt.Printf("Host:         %s\n", db.Host)
+		if db.Adopted {
+			fmt.Printf("Adopted:      yes\n")
+		}
+		switch {
+		case password != "":
+			fmt.Printf("Password:     %s\n", password)
+		case db.EncryptedPassword != "":
+			fmt.Printf("Password:     stored (use --show-password)\n")
+		default:
+			fmt.Printf("Password:     not stored (run 'wordsail site db rotate-password')\n")
+		}
+		if db.PasswordRotatedAt != nil {
+			fmt.Printf("Rotated:      %s\n", db.PasswordRotatedAt.Format("2006-01-02 15:04"))
+		}
+		fmt.Println()
+	},
+}
+
+// siteDBRotatePasswordCmd represents the site db rotate-password command
+var siteDBRotatePasswordCmd = &cobra.Command{
+	Use:   "rotate-password",
+	Short: "Generate a new database password for a site",
+	Long: `Generate a new database password for a site, change it in MariaDB with
+ALTER USER and update DB_PASSWORD in wp-config.php. The new password is
+stored encrypted in the config file.
+
+Use this after a suspected credential leak. The site cannot reach its
+database for the moment between the two changes.
+
+Examples:
+  wordsail site db rotate-password --server production-1 --site mysite
+  wordsail site db rotate-password --server production-1 --site mysite --force --json`,
+	Run: func(cmd *cobra.Command, args []string) {
+		mgr, err := config.NewManager()
+		if err != nil {
+			fail(cmd, "Failed to create config manager", err)
+		}
+
+		if !mgr.ConfigExists() {
+			fail(cmd, "Configuration file not found", exit.Errorf(exit.ConfigNotFound, "run 'wordsail init' first"))
+		}
+
+		cfg, err := mgr.Load()
+		if err != nil {
+			fail(cmd, "Failed to load configuration", exit.New(exit.Validation, err))
+		}
+
+		server, site := requireServerSite(cmd, cfg)
+
+		if !assumeYes(cmd) {
+			if isJSONOutput(cmd) {
+				fail(cmd, "Confirmation required", exit.Errorf(exit.Validation, "use --force to rotate a password in JSON mode"))
+			}
+			confirm, err := prompt.Confirm(fmt.Sprintf("Rotate the database password of %s? The current password stops working.", site.PrimaryDomain), false, false)
+			if err != nil {
+				os.Exit(1)
+			}
+			if !confirm {
+				outputInfo(cmd, "Cancelled.\n")
+				return
+			}
+		}
+
+		if DryRun {
+			outputInfo(cmd, "Dry run: would rotate the password of database user '%s' on %s\n", site.Database.User, site.PrimaryDomain)
+			return
+		}
+
+		// Encrypt first so a missing or unreadable key stops us before the
+		// password is changed on the server
+		password := prompt.GenerateSecurePassword(24)
+		encrypted, err := config.EncryptSecret(password)
+		if err != nil {
+			fail(cmd, "Failed to encrypt the new password", err)
+		}
+
+		extraVars := map[string]interface{}{
+			"site_domain":      site.PrimaryDomain,
+			"site_user":        site.SiteID,
+			"db_user":          site.Database.User,
+			"db_password":      password,
+			"db_host":          site.Database.Host,
+			"update_wp_config": !site.NoWordPress,
+		}
+
+		executor := newExecutor(cmd, cfg)
+		outputBanner(cmd, color.Cyan, fmt.Sprintf("Rotating database password for: %s", site.PrimaryDomain))
+
+		if _, err := executor.ExecutePlaybook("playbooks/rotate_db_password.yml", *server, extraVars, cfg.GlobalVars); err != nil {
+			// The database may already use the new password even though
+			// wp-config.php was not updated, so don't lose it
+			if !isJSONOutput(cmd) {
+				color.Yellow("⚠️  The database may already use the new password: %s", password)
+			}
+			fail(cmd, "Failed to rotate database password", err)
+		}
+
+		stateMgr := state.NewManager(mgr)
+		if err := stateMgr.SetSiteDatabasePassword(server.Name, site.SiteID, encrypted, time.Now()); err != nil {
+			outputWarning(cmd, "Password rotated but failed to update configuration: %v", err)
+			if !isJSONOutput(cmd) {
+				color.Yellow("⚠️  New password: %s", password)
+			}
+		}
+
+		outputSuccess(cmd, "site_db_password_rotated", map[string]interface{}{
+			"server":  server.Name,
+			"site_id": site.SiteID,
+			"domain":  site.PrimaryDomain,
+			"user":    site.Database.User,
+		})
+	},
+}
+
+// siteDBExportCmd represents the site db export command
+var siteDBExportCmd = &cobra.Command{
+	Use:   "export",
+	Short: "Download a gzipped dump of a site's database",
+	Long: `Run mysqldump on the server and stream the gzipped dump to a local file,
+without taking a full site backup.
+
+The database name, user and host come from the site record. The password is
+the one stored by 'site db rotate-password', or read from wp-config.php.
+
+Examples:
+  wordsail site db export --server production-1 --site mysite --file dump.sql.gz`,
+	Run: func(cmd *cobra.Command, args []string) {
+		mgr, err := config.NewManager()
+		if err != nil {
+			fail(cmd, "Failed to create config manager", err)
+		}
+
+		if !mgr.ConfigExists() {
+			fail(cmd, "Configuration file not found", exit.Errorf(exit.ConfigNotFound, "run 'wordsail init' first"))
+		}
+
+		cfg, err := mgr.Load()
+		if err != nil {
+			fail(cmd, "Failed to load configuration", exit.New(exit.Validation, err))
+		}
+
+		server, site := requireServerSite(cmd, cfg)
+
+		path, _ := cmd.Flags().GetString("file")
+		if path == "" {
+			path = fmt.Sprintf("%s-%s.sql.gz", site.SiteID, time.Now().Format("20060102-150405"))
+		}
+		force, _ := cmd.Flags().GetBool("force")
+		if _, err := os.Stat(path); err == nil && !force {
+			fail(cmd, "Output file exists", exit.Errorf(exit.Validation, "%s already exists (use --force to overwrite)", path))
+		}
+
+		if DryRun {
+			outputInfo(cmd, "Dry run: would export database '%s' of %s to %s\n", site.Database.Name, site.PrimaryDomain, path)
+			return
+		}
+
+		passwordLine, err := siteDBPasswordLine(*server, *site)
+		if err != nil {
+			fail(cmd, "Database password unavailable", err)
+		}
+
+		// Write to a temporary file so a failed dump never looks complete
+		partial := path + ".part"
+		file, err := os.OpenFile(partial, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0600)
+		if err != nil {
+			fail(cmd, "Failed to create output file", err)
+		}
+
+		outputInfo(cmd, "→ Exporting database '%s' from %s...\n", site.Database.Name, server.Name)
+		writer := &utils.ProgressWriter{W: file, OnProgress: transferProgress(cmd)}
+		err = utils.StreamSSHCommand(*server, utils.DBExportCommand(site.Database), strings.NewReader(passwordLine), writer)
+		endTransferProgress(cmd)
+		if closeErr := file.Close(); err == nil {
+			err = closeErr
+		}
+		if err != nil {
+			os.Remove(partial)
+			fail(cmd, "Database export failed", err)
+		}
+		if err := os.Rename(partial, path); err != nil {
+			os.Remove(partial)
+			fail(cmd, "Failed to write output file", err)
+		}
+
+		outputSuccess(cmd, "site_db_exported", map[string]interface{}{
+			"server":   server.Name,
+			"site_id":  site.SiteID,
+			"database": site.Database.Name,
+			"path":     path,
+			"bytes":    writer.Total(),
+			"size":     formatBytes(writer.Total()),
+		})
+	},
+}
+
+// siteDBImportCmd represents the site db import command
+var siteDBImportCmd = &cobra.Command{
+	Use:   "import",
+	Short: "Load a dump into a site's database",
+	Long: `Stream a local SQL dump (.sql or .sql.gz) to the server and load it into the
+site's database with the mysql client. Tables in the dump replace the
+site's existing tables.
+
+The database name, user and host come from the site record. The password is
+the one stored by 'site db rotate-password', or read from wp-config.php.
+
+Examples:
+  wordsail site db import --server staging --site mysite --input dump.sql.gz`,
+	Run: func(cmd *cobra.Command, args []string) {
+		mgr, err := config.NewManager()
+		if err != nil {
+			fail(cmd, "Failed to create config manager", err)
+		}
+
+		if !mgr.ConfigExists() {
+			fail(cmd, "Configuration file not found", exit.Errorf(exit.ConfigNotFound, "run 'wordsail init' first"))
+		}
+
+		cfg, err := mgr.Load()
+		if err != nil {
+			fail(cmd, "Failed to load configuration", exit.New(exit.Validation, err))
+		}
+
+		server, site := requireServerSite(cmd, cfg)
+
+		input, _ := cmd.Flags().GetString("input")
+		if input == "" {
+			fail(cmd, "Missing required flags", exit.Errorf(exit.Validation, "--input is required"))
+		}
+		info, err := os.Stat(input)
+		if err != nil {
+			fail(cmd, "Cannot read dump", exit.New(exit.Validation, err))
+		}
+		gzipped := strings.HasSuffix(input, ".gz")
+
+		if !assumeYes(cmd) {
+			if isJSONOutput(cmd) {
+				fail(cmd, "Confirmation required", exit.Errorf(exit.Validation, "use --force to import in JSON mode"))
+			}
+			confirm, err := prompt.Confirm(fmt.Sprintf("Import %s into database '%s' of %s? Tables in the dump replace the existing ones.", input, site.Database.Name, site.PrimaryDomain), false, false)
+			if err != nil {
+				os.Exit(1)
+			}
+			if !confirm {
+				outputInfo(cmd, "Cancelled.\n")
+				return
+			}
+		}
+
+		if DryRun {
+			outputInfo(cmd, "Dry run: would import %s (%s) into database '%s' of %s\n", input, formatBytes(info.Size()), site.Database.Name, site.PrimaryDomain)
+			return
+		}
+
+		passwordLine, err := siteDBPasswordLine(*server, *site)
+		if err != nil {
+			fail(cmd, "Database password unavailable", err)
+		}
+
+		file, err := os.Open(input)
+		if err != nil {
+			fail(cmd, "Cannot read dump", exit.New(exit.Validation, err))
+		}
+		defer file.Close()
+
+		outputInfo(cmd, "→ Importing %s (%s) into '%s' on %s...\n", input, formatBytes(info.Size()), site.Database.Name, server.Name)
+		reader := &utils.ProgressReader{R: file, OnProgress: transferProgress(cmd)}
+		stdin := io.MultiReader(strings.NewReader(passwordLine), reader)
+		err = utils.StreamSSHCommand(*server, utils.DBImportCommand(site.Database, gzipped), stdin, io.Discard)
+		endTransferProgress(cmd)
+		if err != nil {
+			fail(cmd, "Database import failed", err)
+		}
+
+		outputSuccess(cmd, "site_db_imported", map[string]interface{}{
+			"server":   server.Name,
+			"site_id":  site.SiteID,
+			"domain":   site.PrimaryDomain,
+			"database": site.Database.Name,
+			"path":     input,
+			"bytes":    reader.Total(),
+		})
+	},
+}
+
+// siteDBPasswordLine returns a site's database password, ready to pass to
+// DBExportCommand and DBImportCommand: the stored one when it has been
+// rotated, otherwise DB_PASSWORD from wp-config.php
+func siteDBPasswordLine(server models.Server, site models.Site) (string, error) {
+	var password string
+	var err error
+	switch {
+	case site.Database.EncryptedPassword != "":
+		password, err = config.DecryptSecret(site.Database.EncryptedPassword)
+		if err != nil {
+			return "", fmt.Errorf("failed to decrypt the database password: %w", err)
+		}
+	case site.NoWordPress:
+		return "", exit.Errorf(exit.Validation,
+			"the database password of site '%s' is unknown; run 'wordsail site db rotate-password' first", site.SiteID)
+	default:
+		password, err = utils.WPConfigDBPassword(server, site)
+		if err != nil {
+			return "", fmt.Errorf("failed to read the database password from wp-config.php: %w", err)
+		}
+	}
+
+	line, err := utils.DBPasswordLine(password)
+	if err != nil {
+		return "", exit.New(exit.Validation, err)
+	}
+	return line, nil
+}
+
+// transferProgress returns a callback that shows the bytes transferred so
+// far on stderr, at most a few times a second. It prints nothing for JSON
+// output or --quiet.
+func transferProgress(cmd *cobra.Command) func(int64) {
+	if isJSONOutput(cmd) || Quiet {
+		return nil
+	}
+	var last time.Time
+	return func(total int64) {
+		if time.Since(last) < 200*time.Millisecond {
+			return
+		}
+		last = time.Now()
+		fmt.Fprintf(os.Stderr, "\r  %s transferred", formatBytes(total))
+	}
+}
+
+// endTransferProgress finishes the line transferProgress writes to
+func endTransferProgress(cmd *cobra.Command) {
+	if !isJSONOutput(cmd) && !Quiet {
+		fmt.Fprintln(os.Stderr)
+	}
+}
+
+// siteMigrateCmd represents the site migrate command
+var siteMigrateCmd = &cobra.Command{
+	Use:   "migrate",
+	Short: "Move a site to another server",
+	Long: `Move a site from one provisioned server to another. The migration runs in
+steps:
+
+  provision       create the site on the target (Nginx, PHP-FPM, empty database)
+  files           stream the site files from the source to the target
+  database        stream a database dump from the source to the target
+  configure       point wp-config.php at the new database (WordPress only)
+  extras          add the site's other domains, redirects and PHP extensions
+  search-replace  replace the source IP with the target IP (WordPress only)
+  verify          check the site answers on the target
+  state           move the site record to the target server
+  cleanup         delete the site from the source (only with --delete-source)
+
+Files and the dump pass through this machine over SSH; the servers do not
+need to reach each other. Progress is saved after every step, so after a
+failure the same command resumes where it stopped (--restart starts over).
+
+The site gets a new database password on the target, stored encrypted as
+with 'site db rotate-password'. SSL certificates are not copied: point DNS
+at the target, then issue them with 'wordsail domain ssl'. Put the site in
+maintenance mode first if it must not take writes during the move.
+
+Without --delete-source the source copy is kept; run the same command with
+--delete-source once DNS has switched to remove it.
+
+wp-content/cache and *.log files are not copied; --no-default-excludes
+copies them too. --exclude (repeatable) leaves out more: "*" and "?" are
+wildcards, a leading "/" anchors to the site's files directory (the WordPress
+root), and a trailing "/" is ignored, so a pattern also matches files.
+
+Examples:
+  # Move a site and keep the source copy until DNS has switched
+  wordsail site migrate --from-server production-1 --to-server production-2 --site mysite
+
+  # Later, remove the source copy
+  wordsail site migrate --from-server production-1 --to-server production-2 --site mysite --delete-source
+
+  # Leave old uploads and backup archives behind
+  wordsail site migrate --from-server production-1 --to-server production-2 --site mysite \
+    --exclude /wp-content/uploads/2019/ --exclude '*.zip'
+
+  # Show the steps without changing anything
+  wordsail site migrate --from-server production-1 --to-server production-2 --site mysite --dry-run`,
+	Run: func(cmd *cobra.Command, args []string) {
+		mgr, err := config.NewManager()
+		if err != nil {
+			fail(cmd, "Failed to create config manager", err)
+		}
+
+		if !mgr.ConfigExists() {
+			fail(cmd, "Configuration file not found", exit.Errorf(exit.ConfigNotFound, "run 'wordsail init' first"))
+		}
+
+		cfg, err := mgr.Load()
+		if err != nil {
+			fail(cmd, "Failed to load configuration", exit.New(exit.Validation, err))
+		}
+
+		fromName, _ := cmd.Flags().GetString("from-server")
+		toName, _ := cmd.Flags().GetString("to-server")
+		siteArg, _ := cmd.Flags().GetString("site")
+		deleteSource, _ := cmd.Flags().GetBool("delete-source")
+		restart, _ := cmd.Flags().GetBool("restart")
+
+		if fromName == "" || toName == "" || siteArg == "" {
+			fail(cmd, "Missing required flags", exit.Errorf(exit.Validation, "--from-server, --to-server and --site are required"))
+		}
+		if fromName == toName {
+			fail(cmd, "Invalid flags", exit.Errorf(exit.Validation, "--from-server and --to-server must differ"))
+		}
+		excludes, _ := cmd.Flags().GetStringArray("exclude")
+		noDefaultExcludes, _ := cmd.Flags().GetBool("no-default-excludes")
+
+		fromServer := utils.FindServerByName(cfg.Servers, fromName)
+		if fromServer == nil {
+			fail(cmd, "Server not found", exit.Errorf(exit.Validation, "server '%s' does not exist", fromName))
+		}
+		toServer := utils.FindServerByName(cfg.Servers, toName)
+		if toServer == nil {
+			fail(cmd, "Server not found", exit.Errorf(exit.Validation, "server '%s' does not exist", toName))
+		}
+		if toServer.Status != "provisioned" {
+			fail(cmd, "Target server not provisioned", exit.Errorf(exit.Validation, "server '%s' is %s; run 'wordsail server provision %s' first", toName, toServer.Status, toName))
+		}
+
+		// A resumed migration may already have moved the site record
+		site, err := utils.ResolveSite(fromServer, siteArg)
+		if err != nil {
+			moved, movedErr := utils.ResolveSite(toServer, siteArg)
+			if movedErr != nil {
+				fail(cmd, "Site not found", exit.New(exit.Validation, err))
+			}
+			if progress, _ := state.LoadSiteMigration(migrationPath(mgr, moved.SiteID)); progress == nil || !progress.Done(state.MigrateStepState) {
+				fail(cmd, "Site not found", exit.New(exit.Validation, err))
+			}
+			site = moved
+		}
+		archiveCommand, err := utils.SiteArchiveCommand(*site, excludes, !noDefaultExcludes)
+		if err != nil {
+			fail(cmd, "Invalid --exclude", exit.New(exit.Validation, err))
+		}
+
+		progressPath := migrationPath(mgr, site.SiteID)
+		progress, err := state.LoadSiteMigration(progressPath)
+		if err != nil {
+			fail(cmd, "Failed to read migration progress", err)
+		}
+		if progress != nil && (progress.FromServer != fromName || progress.ToServer != toName) {
+			fail(cmd, "Another migration is in progress", exit.Errorf(exit.Validation,
+				"site '%s' is being migrated from '%s' to '%s'; resume that or pass --restart", site.SiteID, progress.FromServer, progress.ToServer))
+		}
+		if progress != nil && restart {
+			if progress.Done(state.MigrateStepState) {
+				fail(cmd, "Cannot restart", exit.Errorf(exit.Validation, "the site record has already moved to '%s'", toName))
+			}
+			if !DryRun {
+				if err := progress.Remove(); err != nil {
+					fail(cmd, "Failed to reset migration progress", err)
+				}
+			}
+			progress = nil
+		}
+
+		if progress == nil || !progress.Done(state.MigrateStepState) {
+			for _, existing := range toServer.Sites {
+				if existing.SiteID == site.SiteID || existing.PrimaryDomain == site.PrimaryDomain {
+					fail(cmd, "Site already on target", exit.Errorf(exit.Validation, "server '%s' already has site '%s' (%s)", toName, existing.SiteID, existing.PrimaryDomain))
+				}
+			}
+		}
+
+		// Steps that don't apply to this site are skipped
+		skipped := map[string]string{}
+		if site.NoWordPress {
+			skipped[state.MigrateStepConfigure] = "no WordPress"
+			skipped[state.MigrateStepSearchReplace] = "no WordPress"
+		} else if fromServer.IP == "" || toServer.IP == "" || fromServer.IP == toServer.IP {
+			skipped[state.MigrateStepSearchReplace] = "IP unchanged or unknown"
+		}
+		extras := utils.SiteExtrasPlaybooks(*site)
+		if len(extras) == 0 {
+			skipped[state.MigrateStepExtras] = "primary domain only"
+		}
+		if !deleteSource {
+			skipped[state.MigrateStepCleanup] = "source kept; pass --delete-source"
+		}
+
+		if DryRun {
+			outputInfo(cmd, "Dry run: would migrate %s (%s) from %s to %s\n", site.PrimaryDomain, site.SiteID, fromName, toName)
+			if progress != nil && progress.LastError != "" {
+				outputInfo(cmd, "Unfinished migration started %s; last error: %s\n", progress.StartedAt.Format("2006-01-02 15:04"), progress.LastError)
+			}
+			for _, step := range state.MigrateSteps {
+				switch {
+				case progress != nil && progress.Done(step):
+					outputInfo(cmd, "  %-15s done in an earlier run\n", step)
+				case skipped[step] != "":
+					outputInfo(cmd, "  %-15s skipped (%s)\n", step, skipped[step])
+				default:
+					outputInfo(cmd, "  %-15s would run\n", step)
+				}
+			}
+			return
+		}
+
+		if progress == nil && !assumeYes(cmd) {
+			if isJSONOutput(cmd) {
+				fail(cmd, "Confirmation required", exit.Errorf(exit.Validation, "use --force to migrate a site in JSON mode"))
+			}
+			message := fmt.Sprintf("Migrate %s from %s to %s?", site.PrimaryDomain, fromName, toName)
+			if deleteSource {
+				message = fmt.Sprintf("Migrate %s from %s to %s and DELETE it from %s afterwards?", site.PrimaryDomain, fromName, toName, fromName)
+			}
+			confirm, err := prompt.Confirm(message, false, false)
+			if err != nil {
+				os.Exit(1)
+			}
+			if !confirm {
+				outputInfo(cmd, "Cancelled.\n")
+				return
+			}
+		}
+
+		// The target database password is chosen once and kept in the
+		// progress file so a resumed run uses the same one
+		if progress == nil {
+			encrypted, err := config.EncryptSecret(prompt.GenerateSecurePassword(24))
+			if err != nil {
+				fail(cmd, "Failed to encrypt the new database password", err)
+			}
+			progress = state.NewSiteMigration(progressPath, site.SiteID, fromName, toName, encrypted)
+			if err := progress.Save(); err != nil {
+				fail(cmd, "Failed to save migration progress", err)
+			}
+		} else {
+			outputInfo(cmd, "Resuming migration of %s (started %s)\n", site.PrimaryDomain, progress.StartedAt.Format("2006-01-02 15:04"))
+		}
+		dbPassword, err := config.DecryptSecret(progress.DBPassword)
+		if err != nil {
+			fail(cmd, "Failed to decrypt the new database password", err)
+		}
+
+		phpVersion := site.PHPVersion
+		if phpVersion == "" {
+			phpVersion = utils.DefaultPHPVersion
+		}
+		targetSite := *site
+		targetSite.Database = models.Database{
+			Name: site.Database.Name,
+			User: site.Database.User,
+			Host: "localhost",
+		}
+
+		executor := newExecutor(cmd, cfg)
+		outputBanner(cmd, color.Cyan, fmt.Sprintf("Migrating %s: %s → %s", site.PrimaryDomain, fromName, toName))
+
+		runStep := func(step string, run func() error) {
+			if progress.Done(step) {
+				outputInfo(cmd, "✓ %s (done in an earlier run)\n", step)
+				return
+			}
+			if reason := skipped[step]; reason != "" {
+				outputInfo(cmd, "- %s skipped (%s)\n", step, reason)
+				return
+			}
+			outputInfo(cmd, "→ %s...\n", step)
+			if err := run(); err != nil {
+				if saveErr := progress.Fail(err); saveErr != nil {
+					outputWarning(cmd, "Failed to save migration progress: %v", saveErr)
+				}
+				outputInfo(cmd, "Fix the problem and run the same command again to resume from '%s'.\n", step)
+				fail(cmd, fmt.Sprintf("Migration step '%s' failed", step), err)
+			}
+			if err := progress.Complete(step); err != nil {
+				fail(cmd, "Failed to save migration progress", err)
+			}
+		}
+
+		runStep(state.MigrateStepProvision, func() error {
+			_, err := executor.ExecutePlaybook("website.yml", *toServer, map[string]interface{}{
+				"domain":            site.PrimaryDomain,
+				"site_id":           site.SiteID,
+				"php_version":       phpVersion,
+				"install_wordpress": false,
+				"skip_ssl":          true,
+				"wp_db_name":        targetSite.Database.Name,
+				"wp_db_user":        targetSite.Database.User,
+				"wp_db_password":    dbPassword,
+			}, cfg.GlobalVars)
+			return err
+		})
+
+		runStep(state.MigrateStepFiles, func() error {
+			err := utils.PipeSSHCommands(*fromServer, archiveCommand, strings.NewReader(""),
+				*toServer, utils.SiteExtractCommand(targetSite), strings.NewReader(""), transferProgress(cmd))
+			endTransferProgress(cmd)
+			return err
+		})
+
+		runStep(state.MigrateStepDatabase, func() error {
+			sourceLine, err := siteDBPasswordLine(*fromServer, *site)
+			if err != nil {
+				return err
+			}
+			targetLine, err := utils.DBPasswordLine(dbPassword)
+			if err != nil {
+				return err
+			}
+			err = utils.PipeSSHCommands(*fromServer, utils.DBExportCommand(site.Database), strings.NewReader(sourceLine),
+				*toServer, utils.DBImportCommand(targetSite.Database, true), strings.NewReader(targetLine), transferProgress(cmd))
+			endTransferProgress(cmd)
+			return err
+		})
+
+		// The copied wp-config.php still has the source credentials. With it
+		// fixed, website.yml adopts the imported database and adds the cron job.
+		runStep(state.MigrateStepConfigure, func() error {
+			if err := utils.SetWPConfigDB(*toServer, targetSite, targetSite.Database, dbPassword); err != nil {
+				return err
+			}
+			_, err := executor.ExecutePlaybook("website.yml", *toServer, map[string]interface{}{
+				"domain":          site.PrimaryDomain,
+				"site_id":         site.SiteID,
+				"php_version":     phpVersion,
+				"use_existing_db": true,
+				"skip_ssl":        true,
+				"wp_db_name":      targetSite.Database.Name,
+				"wp_db_user":      targetSite.Database.User,
+				"wp_db_password":  dbPassword,
+			}, cfg.GlobalVars)
+			return err
+		})
+
+		// website.yml only sets up the primary domain
+		runStep(state.MigrateStepExtras, func() error {
+			for _, run := range extras {
+				if _, err := executor.ExecutePlaybook(run.Playbook, *toServer, run.ExtraVars, cfg.GlobalVars); err != nil {
+					return err
+				}
+			}
+			return nil
+		})
+
+		runStep(state.MigrateStepSearchReplace, func() error {
+			output, err := utils.RunWPCLI(*toServer, targetSite, utils.SearchReplaceArgs(fromServer.IP, toServer.IP))
+			if err != nil {
+				return err
+			}
+			outputInfo(cmd, "  %s replacement(s) of %s\n", strings.TrimSpace(output), fromServer.IP)
+			return nil
+		})
+
+		runStep(state.MigrateStepVerify, func() error {
+			output, err := utils.RunSSHCommand(*toServer, utils.SiteResponseCommand(targetSite))
+			if err != nil {
+				return err
+			}
+			return utils.CheckSiteResponse(output)
+		})
+
+		runStep(state.MigrateStepState, func() error {
+			moved := targetSite
+			now := time.Now()
+			moved.Database.EncryptedPassword = progress.DBPassword
+			moved.Database.PasswordRotatedAt = &now
+			// Certificates stay on the source until issued on the target
+			moved.Domains = append([]models.Domain(nil), site.Domains...)
+			for i := range moved.Domains {
+				moved.Domains[i].SSLEnabled = false
+				moved.Domains[i].SSLIssuedAt = nil
+				moved.Domains[i].SSLExpiresAt = nil
+			}
+			return state.NewManager(mgr).MoveSite(fromName, toName, moved)
+		})
+
+		runStep(state.MigrateStepCleanup, func() error {
+			extraVars := map[string]interface{}{
+				"site_id":     site.SiteID,
+				"site_domain": site.PrimaryDomain,
+				"db_host":     site.Database.Host,
+			}
+			if site.PHPVersion != "" {
+				extraVars["php_version"] = site.PHPVersion
+			}
+			_, err := executor.ExecutePlaybook("playbooks/delete_site.yml", *fromServer, extraVars, cfg.GlobalVars)
+			return err
+		})
+
+		// Without --delete-source the progress file is kept so a later run
+		// can still remove the source copy
+		sourceDeleted := progress.Done(state.MigrateStepCleanup)
+		if sourceDeleted {
+			if err := progress.Remove(); err != nil {
+				outputWarning(cmd, "%v", err)
+			}
+		}
+
+		if isJSONOutput(cmd) {
+			outputSuccess(cmd, "site_migrated", map[string]interface{}{
+				"site_id":        site.SiteID,
+				"domain":         site.PrimaryDomain,
+				"from_server":    fromName,
+				"to_server":      toName,
+				"target_ip":      toServer.IP,
+				"source_deleted": sourceDeleted,
+			})
+			return
+		}
+
+		outputBanner(cmd, color.Green, fmt.Sprintf("✓ %s migrated to %s", site.PrimaryDomain, toName))
+		fmt.Println("Next steps:")
+		fmt.Printf("  1. Point DNS for %s at %s\n", site.PrimaryDomain, toServer.Address())
+		fmt.Printf("  2. Issue certificates: wordsail domain ssl --server %s --site %s --domain <domain>\n", toName, site.SiteID)
+		if !sourceDeleted {
+			fmt.Printf("  3. Remove the source copy: wordsail site migrate --from-server %s --to-server %s --site %s --delete-source\n", fromName, toName, site.SiteID)
+		}
+		if site.NoWordPress {
+			fmt.Println()
+			color.Yellow("⚠️  The database password changed. Update your application's configuration;")
+			color.Yellow("   see 'wordsail site db info --server %s --site %s --show-password'.", toName, site.SiteID)
+		}
+	},
+}
+
+// siteTemplateCmd represents the site template command
+var siteTemplateCmd = &cobra.Command{
+	Use:   "template",
+	Short: "Manage site templates",
+	Long: `Save the plugins, theme and WordPress options you install on every new site
+as a named template, then apply it with 'site create --template <name>'.
+
+Templates are stored in ~/.wordsail/templates/<name>.yaml:
+
+  plugins:
+    - wordfence
+    - wp-mail-smtp
+  theme: astra
+  options:
+    blogdescription: Just another client site
+    timezone_string: Europe/Berlin`,
+}
+
+// siteTemplateListCmd represents the site template list command
+var siteTemplateListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List site templates",
+	Run: func(cmd *cobra.Command, args []string) {
+		templates, err := config.ListSiteTemplates()
+		if err != nil {
+			fail(cmd, "Failed to list templates", err)
+		}
+
+		if isJSONOutput(cmd) {
+			data, _ := json.MarshalIndent(templates, "", "  ")
+			fmt.Println(string(data))
+			return
+		}
+
+		if len(templates) == 0 {
+			fmt.Println("No site templates found. Add one with 'wordsail site template add'.")
+			return
+		}
+
+		rows := make([][]string, len(templates))
+		for i, tmpl := range templates {
+			plugins := strings.Join(tmpl.Plugins, ", ")
+			if plugins == "" {
+				plugins = "-"
+			}
+			theme := tmpl.Theme
+			if theme == "" {
+				theme = "-"
+			}
+			rows[i] = []string{tmpl.Name, plugins, theme, fmt.Sprintf("%d", len(tmpl.Options))}
+		}
+		utils.PrintTable([]string{"NAME", "PLUGINS", "THEME", "OPTIONS"}, rows)
+	},
+}
+
+// siteTemplateAddCmd represents the site template add command
+var siteTemplateAddCmd = &cobra.Command{
+	Use:   "add <name>",
+	Short: "Save a site template",
+	Long: `Save a site template from flags. Plugins and the theme are installed from
+wordpress.org by slug (or from a zip URL) and activated; options are set
+with 'wp option update' after WordPress is installed.
+
+Examples:
+  wordsail site template add agency-starter \
+    --plugin wordfence --plugin wp-mail-smtp --theme astra \
+    --option timezone_string=Europe/Berlin
+
+  # Replace an existing template
+  wordsail site template add agency-starter --plugin wordfence --force`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		tmpl := models.SiteTemplate{Name: args[0]}
+		tmpl.Plugins, _ = cmd.Flags().GetStringArray("plugin")
+		tmpl.Theme, _ = cmd.Flags().GetString("theme")
+		force, _ := cmd.Flags().GetBool("force")
+
+		optionSpecs, _ := cmd.Flags().GetStringArray("option")
+		for _, spec := range optionSpecs {
+			name, value, ok := strings.Cut(spec, "=")
+			if !ok || strings.TrimSpace(name) == "" {
+				fail(cmd, "Invalid --option", exit.Errorf(exit.Validation, "'%s' is not name=value", spec))
+			}
+			if tmpl.Options == nil {
+				tmpl.Options = map[string]string{}
+			}
+			tmpl.Options[strings.TrimSpace(name)] = value
+		}
+
+		path, err := config.SaveSiteTemplate(tmpl, force)
+		if err != nil {
+			fail(cmd, "Failed to save template", exit.New(exit.Validation, err))
+		}
+
+		outputSuccess(cmd, "site_template_saved", map[string]interface{}{
+			"name":    tmpl.Name,
+			"path":    path,
+			"plugins": tmpl.Plugins,
+			"theme":   tmpl.Theme,
+			"options": tmpl.Options,
+		})
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(siteCmd)
+	siteCmd.AddCommand(siteCreateCmd)
+	siteCmd.AddCommand(siteListCmd)
+	siteCmd.AddCommand(siteDeleteCmd)
+	siteCmd.AddCommand(siteUpdateWPCmd)
+	siteCmd.AddCommand(siteMaintenanceCmd)
+	siteCmd.AddCommand(siteEnableCmd)
+	siteCmd.AddCommand(siteDisableCmd)
+	siteCmd.AddCommand(siteInfoCmd)
+	siteCmd.AddCommand(siteVerifyCmd)
+	siteCmd.AddCommand(sitePHPExtCmd)
+	sitePHPExtCmd.AddCommand(sitePHPExtEnableCmd)
+	sitePHPExtCmd.AddCommand(sitePHPExtDisableCmd)
+	sitePHPExtCmd.AddCommand(sitePHPExtListCmd)
+	siteCmd.AddCommand(siteResetPasswordCmd)
+	siteCmd.AddCommand(siteDBCmd)
+	siteDBCmd.AddCommand(siteDBInfoCmd)
+	siteDBCmd.AddCommand(siteDBRotatePasswordCmd)
+	siteDBCmd.AddCommand(siteDBExportCmd)
+	siteDBCmd.AddCommand(siteDBImportCmd)
+	siteCmd.AddCommand(siteMigrateCmd)
+	siteCmd.AddCommand(siteTemplateCmd)
+	siteTemplateCmd.AddCommand(siteTemplateListCmd)
+	siteTemplateCmd.AddCommand(siteTemplateAddCmd)
+
+	// site create flags
+	siteCreateCmd.Flags().Bool("non-interactive", false, "Use flags instead of interactive prompts")
+	siteCreateCmd.Flags().String("server", "", "Target server name")
+	siteCreateCmd.Flags().String("domain", "", "Primary domain")
+	siteCreateCmd.Flags().String("site-id", "", "Site identifier (optional, auto-generated from domain if not provided)")
+	siteCreateCmd.Flags().String("admin-user", "", "WordPress admin username")
+	siteCreateCmd.Flags().String("admin-email", "", "WordPress admin email")
+	siteCreateCmd.Flags().String("admin-password", "", "WordPress admin password (discouraged: visible in shell history and process lists)")
+	siteCreateCmd.Flags().Bool("admin-password-stdin", false, "Read the WordPress admin password from stdin")
+	siteCreateCmd.Flags().Bool("no-ssl", false, "Skip automatic SSL certificate issuance")
+	siteCreateCmd.Flags().StringArray("user", nil, "Additional WordPress user as user:email:role (repeatable)")
+	siteCreateCmd.Flags().Bool("use-existing-db", false, "Use an already-populated database instead of installing WordPress")
+	siteCreateCmd.Flags().String("db-name", "", "Existing database name (with --use-existing-db)")
+	siteCreateCmd.Flags().String("db-user", "", "Existing database user (with --use-existing-db)")
+	siteCreateCmd.Flags().String("db-password", "", "Existing database password (least safe: visible in shell history and process lists; prefer --db-password-stdin or $WORDSAIL_DB_PASSWORD)")
+	siteCreateCmd.Flags().Bool("db-password-stdin", false, "Read the existing database password from stdin (with --use-existing-db)")
+	siteCreateCmd.Flags().String("db-host", "", "Existing database host[:port] (default localhost)")
+	siteCreateCmd.Flags().String("db-prefix", "", "Existing table prefix (default wp_)")
+	siteCreateCmd.Flags().String("admin-locale", "", "WordPress site language, e.g. de_DE (default en_US)")
+	siteCreateCmd.Flags().String("php-version", "", "PHP version for the site: "+strings.Join(utils.SupportedPHPVersions, ", ")+" (default "+utils.DefaultPHPVersion+")")
+	siteCreateCmd.Flags().String("template", "", "Apply a saved site template (see 'site template list')")
+	siteCreateCmd.Flags().Bool("no-wordpress", false, "Create a bare site (Nginx, PHP and a database) without installing WordPress")
+
+	// site create json flag
+	siteCreateCmd.Flags().BoolP("force", "f", false, "Re-create a site whose domain already exists on the server (keeps its files and database)")
+	siteCreateCmd.Flags().Bool("json", false, "Output in JSON format")
+	addExtraVarFlags(siteCreateCmd)
+
+	// site list flags
+	siteListCmd.Flags().String("server", "", "Filter by server name")
+	siteListCmd.Flags().Bool("json", false, "Output in JSON format")
+	siteListCmd.Flags().Bool("stale-ssl", false, "Only list domains whose stored SSL expiry is in the past")
+	siteListCmd.Flags().Bool("refresh", false, "With --stale-ssl, read the live certificate expiry from the servers and update the config")
+	siteListCmd.Flags().String("sort", "", "Sort by "+strings.Join(utils.SiteSortKeys, ", "))
+	siteListCmd.Flags().Int("expiring", 0, "Only list sites whose earliest certificate expires within this many days")
+	siteListCmd.Flags().String("since", "", "Only list sites created on or after this date (2006-01-02 or RFC3339)")
+	siteListCmd.Flags().String("before", "", "Only list sites created before this date (2006-01-02 or RFC3339)")
+
+	// site delete flags
+	siteDeleteCmd.Flags().String("server", "", "Server name")
+	siteDeleteCmd.Flags().String("site", "", "Site ID or domain")
+	siteDeleteCmd.Flags().BoolP("force", "f", false, "Force deletion without confirmation")
+	siteDeleteCmd.Flags().Bool("json", false, "Output in JSON format")
+
+	// site update-wp flags
+	siteUpdateWPCmd.Flags().String("server", "", "Server name")
+	siteUpdateWPCmd.Flags().String("site", "", "Site ID or domain")
+	siteUpdateWPCmd.Flags().Bool("all", false, "Update every site on the server")
+	siteUpdateWPCmd.Flags().Bool("core-only", false, "Only update WordPress core")
+	siteUpdateWPCmd.Flags().Bool("plugins-only", false, "Only update plugins")
+	siteUpdateWPCmd.Flags().Bool("json", false, "Output in JSON format")
+
+	// site maintenance flags
+	siteMaintenanceCmd.Flags().String("server", "", "Server name")
+	siteMaintenanceCmd.Flags().String("site", "", "Site ID or domain")
+	siteMaintenanceCmd.Flags().String("message", "", "Text shown on the maintenance page")
+	siteMaintenanceCmd.Flags().Bool("json", false, "Output in JSON format")
+
+	// site enable/disable flags
+	for _, c := range []*cobra.Command{siteEnableCmd, siteDisableCmd} {
+		c.Flags().String("server", "", "Server name")
+		c.Flags().String("site", "", "Site ID or domain")
+		c.Flags().Bool("json", false, "Output in JSON format")
+	}
+	siteDisableCmd.Flags().BoolP("force", "f", false, "Disable without confirmation")
+
+	// site info flags
+	siteInfoCmd.Flags().String("server", "", "Server name")
+	siteInfoCmd.Flags().String("site", "", "Site ID or domain")
+	siteInfoCmd.Flags().Bool("json", false, "Output in JSON format")
+
+	// site verify flags
+	siteVerifyCmd.Flags().String("server", "", "Server name")
+	siteVerifyCmd.Flags().String("site", "", "Site ID or domain")
+	siteVerifyCmd.Flags().Duration("timeout", 15*time.Second, "Time to wait for each domain to answer")
+	siteVerifyCmd.Flags().Bool("json", false, "Output in JSON format")
+
+	// site php-ext flags
+	for _, c := range []*cobra.Command{sitePHPExtEnableCmd, sitePHPExtDisableCmd, sitePHPExtListCmd} {
+		c.Flags().String("server", "", "Server name")
+		c.Flags().String("site", "", "Site ID or domain")
+		c.Flags().Bool("json", false, "Output in JSON format")
+	}
+
+	// site reset-password flags
+	siteResetPasswordCmd.Flags().String("server", "", "Server name")
+	siteResetPasswordCmd.Flags().String("site", "", "Site ID or domain")
+	siteResetPasswordCmd.Flags().String("user", "", "WordPress login to reset (default the site's admin user)")
+	siteResetPasswordCmd.Flags().Bool("set-admin", false, "Record --user as the site's admin user")
+	siteResetPasswordCmd.Flags().BoolP("force", "f", false, "Reset without confirmation")
+	siteResetPasswordCmd.Flags().Bool("json", false, "Output in JSON format")
+
+	// site db flags
+	for _, c := range []*cobra.Command{siteDBInfoCmd, siteDBRotatePasswordCmd, siteDBExportCmd, siteDBImportCmd} {
+		c.Flags().String("server", "", "Server name")
+		c.Flags().String("site", "", "Site ID or domain")
+		c.Flags().Bool("json", false, "Output in JSON format")
+	}
+	siteDBInfoCmd.Flags().Bool("show-password", false, "Print the stored password")
+	siteDBRotatePasswordCmd.Flags().BoolP("force", "f", false, "Rotate without confirmation")
+	siteDBExportCmd.Flags().String("file", "", "Local file for the gzipped dump (default <site-id>-<timestamp>.sql.gz)")
+	siteDBExportCmd.Flags().BoolP("force", "f", false, "Overwrite an existing output file")
+	siteDBImportCmd.Flags().StringP("input", "i", "", "Local dump to import (.sql or .sql.gz)")
+	siteDBImportCmd.Flags().BoolP("force", "f", false, "Import without confirmation")
+
+	// site migrate flags
+	siteMigrateCmd.Flags().String("from-server", "", "Server the site is on")
+	siteMigrateCmd.Flags().String("to-server", "", "Provisioned server to move the site to")
+	siteMigrateCmd.Flags().String("site", "", "Site ID or domain")
+	siteMigrateCmd.Flags().Bool("delete-source", false, "Delete the site from the source server once it is migrated")
+	siteMigrateCmd.Flags().Bool("restart", false, "Discard the progress of an unfinished migration and start over")
+	siteMigrateCmd.Flags().StringArray("exclude", nil, "Pattern of site files not to copy (repeatable)")
+	siteMigrateCmd.Flags().Bool("no-default-excludes", false, "Also copy wp-content/cache and *.log files")
+	siteMigrateCmd.Flags().BoolP("force", "f", false, "Migrate without confirmation")
+	siteMigrateCmd.Flags().Bool("json", false, "Output in JSON format")
+
+	// site template flags
+	siteTemplateListCmd.Flags().Bool("json", false, "Output in JSON format")
+	siteTemplateAddCmd.Flags().StringArray("plugin", nil, "Plugin slug or zip URL to install and activate (repeatable)")
+	siteTemplateAddCmd.Flags().String("theme", "", "Theme slug or zip URL to install and activate")
+	siteTemplateAddCmd.Flags().StringArray("option", nil, "WordPress option to set, as name=value (repeatable)")
+	siteTemplateAddCmd.Flags().BoolP("force", "f", false, "Replace an existing template")
+	siteTemplateAddCmd.Flags().Bool("json", false, "Output in JSON format")
 }
@@ -0,0 +1,315 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+	"github.com/wordsail/cli/internal/ansible"
+	"github.com/wordsail/cli/internal/config"
+	"github.com/wordsail/cli/internal/exit"
+	"github.com/wordsail/cli/internal/state"
+	"github.com/wordsail/cli/internal/utils"
+	"github.com/wordsail/cli/pkg/models"
+)
+
+// sslCmd represents the ssl command
+var sslCmd = &cobra.Command{
+	Use:   "ssl",
+	Short: "Manage SSL certificates across sites",
+	Long:  `Work with the SSL certificates of many domains at once. For a single domain use 'wordsail domain ssl'.`,
+}
+
+// sslIssueCmd represents the ssl issue command
+var sslIssueCmd = &cobra.Command{
+	Use:   "issue",
+	Short: "Issue certificates for every domain without one",
+	Long: `Request a Let's Encrypt certificate for each domain that has none recorded,
+on all servers (--all-missing) or on one server (--server).
+
+Certificates are requested one at a time, and each one is recorded in the
+config as soon as it is issued. A failed domain doesn't stop the run; a report
+of the issued and failed domains is printed at the end. Domains of disabled
+sites are skipped.
+
+Let's Encrypt allows 50 certificates per registered domain per week. Use --limit
+to issue only the first N missing certificates and run again later for the rest.
+Domains at a locally tracked rate limit are failed unless --force is given.
+
+Examples:
+  wordsail ssl issue --all-missing
+  wordsail ssl issue --server production-1 --limit 10
+  wordsail ssl issue --all-missing --staging
+  wordsail ssl issue --all-missing --dry-run   # List the domains only`,
+	Run: func(cmd *cobra.Command, args []string) {
+		mgr, err := config.NewManager()
+		if err != nil {
+			fail(cmd, "Failed to create config manager", err)
+		}
+
+		if !mgr.ConfigExists() {
+			fail(cmd, "Configuration file not found", exit.Errorf(exit.ConfigNotFound, "run 'wordsail init' first"))
+		}
+
+		cfg, err := mgr.Load()
+		if err != nil {
+			fail(cmd, "Failed to load configuration", exit.New(exit.Validation, err))
+		}
+
+		allMissing, _ := cmd.Flags().GetBool("all-missing")
+		serverName, _ := cmd.Flags().GetString("server")
+		limit, _ := cmd.Flags().GetInt("limit")
+		staging, _ := cmd.Flags().GetBool("staging")
+		force, _ := cmd.Flags().GetBool("force")
+
+		if !allMissing && serverName == "" {
+			fail(cmd, "Missing required flags", exit.Errorf(exit.Validation, "--all-missing or --server is required"))
+		}
+		if limit < 0 {
+			fail(cmd, "Invalid --limit", exit.Errorf(exit.Validation, "--limit cannot be negative"))
+		}
+		if serverName != "" && utils.FindServerByName(cfg.Servers, serverName) == nil {
+			fail(cmd, "Server not found", exit.Errorf(exit.Validation, "server '%s' not found", serverName))
+		}
+
+		email, _ := cmd.Flags().GetString("email")
+		if email == "" {
+			email = "admin@example.com"
+			if configured, ok := cfg.GlobalVars["certbot_email"].(string); ok {
+				email = configured
+			}
+		}
+
+		targets := utils.FindMissingSSL(cfg.Servers, serverName)
+		if limit > 0 && len(targets) > limit {
+			outputInfo(cmd, "%d domain(s) need a certificate; issuing the first %d (--limit)\n", len(targets), limit)
+			targets = targets[:limit]
+		}
+		if len(targets) == 0 || DryRun {
+			for _, t := range targets {
+				outputInfo(cmd, "  %s (%s on %s)\n", t.Domain, t.SiteID, t.Server)
+			}
+			outputSuccess(cmd, "ssl_bulk_issued", map[string]interface{}{
+				"count":   len(targets),
+				"domains": targets,
+				"dry_run": DryRun,
+			})
+			return
+		}
+
+		executor := newExecutor(cmd, cfg)
+
+		// Sequential on purpose: parallel requests burn through Let's Encrypt
+		// rate limits, and keep going after a failure so one broken domain
+		// doesn't block the rest
+		var failed int
+		results := make([]map[string]interface{}, 0, len(targets))
+		for i, t := range targets {
+			outputBanner(cmd, color.Cyan, fmt.Sprintf("[%d/%d] Issuing SSL certificate for: %s", i+1, len(targets), t.Domain))
+
+			entry := map[string]interface{}{
+				"server":  t.Server,
+				"site_id": t.SiteID,
+				"domain":  t.Domain,
+			}
+			err := issueMissingSSL(cmd, mgr, cfg, executor, t, email, staging, force, entry)
+			if err != nil {
+				failed++
+				entry["error"] = err.Error()
+				outputWarning(cmd, "✗ %s: %v", t.Domain, err)
+			}
+			entry["success"] = err == nil
+			results = append(results, entry)
+		}
+
+		// Report
+		outputInfo(cmd, "\n")
+		for _, entry := range results {
+			if entry["success"] == true {
+				outputInfo(cmd, "  %s %s\n", color.GreenString("✓"), entry["domain"])
+			} else {
+				outputInfo(cmd, "  %s %s: %s\n", color.RedString("✗"), entry["domain"], entry["error"])
+			}
+		}
+		outputInfo(cmd, "\n")
+
+		if failed > 0 {
+			if isJSONOutput(cmd) {
+				printResult(CommandResult{
+					Success:  false,
+					Action:   "ssl_bulk_issued",
+					Message:  fmt.Sprintf("%d of %d certificates failed to issue", failed, len(results)),
+					Error:    "SSL certificate issuance failed",
+					Data:     map[string]interface{}{"domains": results},
+					ExitCode: exit.Playbook,
+				})
+			} else {
+				color.Red("✗ %d of %d certificates failed to issue", failed, len(results))
+			}
+			os.Exit(exit.Playbook)
+		}
+
+		outputSuccess(cmd, "ssl_bulk_issued", map[string]interface{}{
+			"count":   len(results),
+			"domains": results,
+			"staging": staging,
+		})
+	},
+}
+
+// issueMissingSSL issues the certificate of one domain of a bulk run,
+// adding its expiry to entry on success
+func issueMissingSSL(cmd *cobra.Command, mgr *config.Manager, cfg *config.Config, executor *ansible.Executor, t utils.MissingSSL, email string, staging, force bool, entry map[string]interface{}) error {
+	if !staging {
+		if err := checkSSLRateLimit(cmd, mgr, t.Domain, force); err != nil {
+			return err
+		}
+	}
+	server := utils.FindServerByName(cfg.Servers, t.Server)
+	sslDomain, err := issueDomainSSL(cmd, mgr, cfg, executor, *server, t.SiteID, t.Domain, email, staging)
+	if err != nil {
+		return err
+	}
+	entry["ssl_expires_at"] = sslDomain.SSLExpiresAt.Format(time.RFC3339)
+	return nil
+}
+
+// sslReconcileCmd represents the ssl reconcile command
+var sslReconcileCmd = &cobra.Command{
+	Use:   "reconcile",
+	Short: "Update stored certificate expiry dates from the servers",
+	Long: `Read the expiry of each SSL-enabled domain's live certificate over SSH
+(openssl on the server's Let's Encrypt certificate) and store it where the
+config has a different date, or none. Certbot renews certificates on the
+server without the config knowing, so stored dates drift; this brings
+'site list' and 'domain list' back in line.
+
+Servers that cannot be reached and certificates that cannot be read are
+reported and left as they are; the command then exits with 1.
+
+Examples:
+  wordsail ssl reconcile
+  wordsail ssl reconcile --server production-1
+  wordsail ssl reconcile --dry-run   # Only show what would change`,
+	Run: func(cmd *cobra.Command, args []string) {
+		mgr, err := config.NewManager()
+		if err != nil {
+			fail(cmd, "Failed to create config manager", err)
+		}
+
+		if !mgr.ConfigExists() {
+			fail(cmd, "Configuration file not found", exit.Errorf(exit.ConfigNotFound, "run 'wordsail init' first"))
+		}
+
+		cfg, err := mgr.Load()
+		if err != nil {
+			fail(cmd, "Failed to load configuration", exit.New(exit.Validation, err))
+		}
+
+		serverName, _ := cmd.Flags().GetString("server")
+		if serverName != "" && utils.FindServerByName(cfg.Servers, serverName) == nil {
+			fail(cmd, "Server not found", exit.Errorf(exit.Validation, "server '%s' does not exist", serverName))
+		}
+
+		stateMgr := state.NewManager(mgr)
+		changes := []utils.SSLExpiryChange{}
+		failed := 0
+		for _, server := range cfg.Servers {
+			if serverName != "" && server.Name != serverName {
+				continue
+			}
+			if err := utils.TestSSHConnection(server, sshTimeout(cmd)); err != nil {
+				outputWarning(cmd, "%s: skipped, server not reachable: %v", server.Name, err)
+				failed++
+				continue
+			}
+
+			outputInfo(cmd, "→ %s: reading certificates...\n", server.Name)
+			drift, errs := utils.SSLExpiryDrift(server, func(domain string) (*time.Time, error) {
+				return utils.ReadCertExpiry(server, domain)
+			})
+			for _, err := range errs {
+				outputWarning(cmd, "%s: %v", server.Name, err)
+			}
+			failed += len(errs)
+
+			for _, c := range drift {
+				if !DryRun {
+					if err := storeSSLExpiry(stateMgr, server, c); err != nil {
+						outputWarning(cmd, "%s: failed to update configuration: %v", c.Domain, err)
+						failed++
+						continue
+					}
+				}
+				from := "none"
+				if c.From != nil {
+					from = c.From.Format("2006-01-02")
+				}
+				outputInfo(cmd, "  %s (%s): %s → %s\n", c.Domain, c.SiteID, from, c.To.Format("2006-01-02"))
+				changes = append(changes, c)
+			}
+		}
+
+		if failed > 0 {
+			if isJSONOutput(cmd) {
+				printResult(CommandResult{
+					Success:  false,
+					Action:   "ssl_reconciled",
+					Message:  fmt.Sprintf("%d certificate(s) or server(s) could not be checked", failed),
+					Error:    "SSL reconcile incomplete",
+					Data:     map[string]interface{}{"changes": changes, "dry_run": DryRun},
+					ExitCode: exit.General,
+				})
+			} else {
+				verb := "Updated"
+				if DryRun {
+					verb = "Would update"
+				}
+				color.Red("✗ %s %d expiry date(s); %d certificate(s) or server(s) could not be checked", verb, len(changes), failed)
+			}
+			os.Exit(exit.General)
+		}
+
+		outputSuccess(cmd, "ssl_reconciled", map[string]interface{}{
+			"count":   len(changes),
+			"changes": changes,
+			"dry_run": DryRun,
+		})
+	},
+}
+
+// storeSSLExpiry records the live expiry of a reconciled domain, keeping
+// its other SSL fields
+func storeSSLExpiry(stateMgr *state.Manager, server models.Server, c utils.SSLExpiryChange) error {
+	site := utils.FindSiteBySiteID(&server, c.SiteID)
+	if site == nil {
+		return fmt.Errorf("site '%s' not found", c.SiteID)
+	}
+	for _, d := range site.Domains {
+		if d.Domain == c.Domain {
+			expiresAt := c.To
+			d.SSLExpiresAt = &expiresAt
+			return stateMgr.UpdateDomainSSL(c.Server, c.SiteID, c.Domain, d)
+		}
+	}
+	return fmt.Errorf("domain '%s' not found on site '%s'", c.Domain, c.SiteID)
+}
+
+func init() {
+	rootCmd.AddCommand(sslCmd)
+	sslCmd.AddCommand(sslIssueCmd)
+	sslCmd.AddCommand(sslReconcileCmd)
+
+	sslIssueCmd.Flags().Bool("all-missing", false, "Issue certificates for domains without one on every server")
+	sslIssueCmd.Flags().String("server", "", "Only issue certificates for domains on this server")
+	sslIssueCmd.Flags().Int("limit", 0, "Issue at most N certificates (0 for no limit)")
+	sslIssueCmd.Flags().String("email", "", "Email for Let's Encrypt notifications (default: certbot_email from config)")
+	sslIssueCmd.Flags().Bool("staging", false, "Use Let's Encrypt staging; nothing is recorded in the config")
+	sslIssueCmd.Flags().Bool("force", false, "Request certificates even for domains at a tracked rate limit")
+	sslIssueCmd.Flags().Bool("json", false, "Output in JSON format")
+
+	sslReconcileCmd.Flags().String("server", "", "Only reconcile domains on this server")
+	sslReconcileCmd.Flags().Bool("json", false, "Output in JSON format")
+}
@@ -0,0 +1,257 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/wordsail/cli/internal/ansible"
+	"github.com/wordsail/cli/internal/config"
+	"github.com/wordsail/cli/internal/exit"
+	"github.com/wordsail/cli/internal/prompt"
+	"github.com/wordsail/cli/internal/state"
+	"gopkg.in/yaml.v3"
+)
+
+// stateCmd represents the state command
+var stateCmd = &cobra.Command{
+	Use:   "state",
+	Short: "Maintain local wordsail state",
+	Long:  `Housekeeping for the files wordsail keeps on this machine.`,
+}
+
+// gcTargets lists the leftovers `state gc` cleans up
+func gcTargets(mgr *config.Manager) []state.GCTarget {
+	targets := []state.GCTarget{
+		{
+			Dir:     ansible.LegacyInventoryDir,
+			Pattern: ansible.InventoryFilePattern,
+			MaxAge:  time.Hour,
+			Reason:  "orphaned inventory file",
+		},
+		{
+			Dir:     mgr.GetConfigDir(),
+			Pattern: filepath.Base(mgr.TempPath()),
+			MaxAge:  time.Hour,
+			Reason:  "interrupted config write",
+		},
+	}
+
+	// Each run's private inventory directory; symlinks are skipped so a
+	// planted link cannot point the cleanup elsewhere
+	dirs, _ := filepath.Glob(filepath.Join(os.TempDir(), ansible.InventoryDirPattern))
+	for _, dir := range dirs {
+		if info, err := os.Lstat(dir); err != nil || !info.IsDir() {
+			continue
+		}
+		targets = append(targets, state.GCTarget{
+			Dir:       dir,
+			Pattern:   ansible.InventoryFilePattern,
+			MaxAge:    time.Hour,
+			Reason:    "orphaned inventory file",
+			RemoveDir: true,
+		})
+	}
+	return targets
+}
+
+// stateGCCmd represents the state gc command
+var stateGCCmd = &cobra.Command{
+	Use:   "gc",
+	Short: "Remove orphaned temp files",
+	Long: `Remove files left behind by interrupted operations:
+
+  - Ansible inventory files (` + ansible.InventoryDirPattern + `/` + ansible.InventoryFilePattern + ` in the
+    temp dir, or ` + ansible.LegacyInventoryDir + `/` + ansible.InventoryFilePattern + ` from older versions) older than an hour
+  - Temporary config files from interrupted writes, older than an hour
+
+Only regular files directly inside those locations are removed.
+
+Examples:
+  # Preview what would be removed
+  wordsail state gc --dry-run
+
+  # Remove without confirmation
+  wordsail state gc --force`,
+	Run: func(cmd *cobra.Command, args []string) {
+		mgr, err := config.NewManager()
+		if err != nil {
+			fail(cmd, "Failed to create config manager", err)
+		}
+
+		candidates, err := state.FindGarbage(gcTargets(mgr), time.Now())
+		if err != nil {
+			fail(cmd, "Failed to scan for orphaned files", err)
+		}
+
+		var total int64
+		for _, c := range candidates {
+			total += c.Size
+		}
+
+		if len(candidates) == 0 {
+			outputSuccess(cmd, "state_gc", map[string]interface{}{
+				"removed":     []state.GCCandidate{},
+				"count":       0,
+				"freed_bytes": int64(0),
+				"dry_run":     DryRun,
+			})
+			return
+		}
+
+		outputInfo(cmd, "\n")
+		for _, c := range candidates {
+			outputInfo(cmd, "  %-60s %8s  %s\n", c.Path, formatBytes(c.Size), c.Reason)
+		}
+		outputInfo(cmd, "\n")
+
+		if DryRun {
+			outputSuccess(cmd, "state_gc", map[string]interface{}{
+				"removed":     candidates,
+				"count":       len(candidates),
+				"freed_bytes": total,
+				"dry_run":     true,
+			})
+			return
+		}
+
+		if !assumeYes(cmd) {
+			if isJSONOutput(cmd) {
+				fail(cmd, "Confirmation required", exit.Errorf(exit.Validation, "use --force to remove files in JSON mode"))
+			}
+
+			confirm, err := prompt.Confirm(fmt.Sprintf("Remove %d file(s) (%s)?", len(candidates), formatBytes(total)), true, false)
+			if err != nil {
+				os.Exit(1)
+			}
+			if !confirm {
+				fmt.Println("Cleanup cancelled")
+				return
+			}
+		}
+
+		freed, errs := state.RemoveGarbage(candidates)
+		for _, err := range errs {
+			outputWarning(cmd, "%v", err)
+		}
+
+		outputSuccess(cmd, "state_gc", map[string]interface{}{
+			"removed":     candidates,
+			"count":       len(candidates) - len(errs),
+			"freed_bytes": freed,
+			"dry_run":     false,
+		})
+	},
+}
+
+// formatBytes renders a byte count with a binary unit, e.g. "1.5 KiB"
+func formatBytes(n int64) string {
+	const unit = 1024
+	if n < unit {
+		return fmt.Sprintf("%d B", n)
+	}
+	div, exp := int64(unit), 0
+	for m := n / unit; m >= unit; m /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %ciB", float64(n)/float64(div), "KMGTPE"[exp])
+}
+
+func init() {
+	rootCmd.AddCommand(stateCmd)
+	stateCmd.AddCommand(stateGCCmd)
+
+	// state gc flags
+	stateGCCmd.Flags().BoolP("force", "f", false, "Remove without confirmation")
+	stateGCCmd.Flags().Bool("json", false, "Output in JSON format")
+}
+
+// stateRetryDelay is how long writeState waits before retrying a failed write
+const stateRetryDelay = 500 * time.Millisecond
+
+// pendingPath returns the pending state changes file next to the config. It
+// is named after the config file so profiles sharing a directory keep their
+// own.
+func pendingPath(mgr *config.Manager) string {
+	base := filepath.Base(mgr.GetConfigPath())
+	return filepath.Join(mgr.GetConfigDir(), strings.TrimSuffix(base, filepath.Ext(base))+"."+state.PendingFile)
+}
+
+// migrationPath returns the progress file of a site migration. Like
+// pendingPath it is named after the config file so profiles keep their own.
+func migrationPath(mgr *config.Manager, siteID string) string {
+	base := filepath.Base(mgr.GetConfigPath())
+	return filepath.Join(mgr.GetConfigDir(), state.MigrationsDir, strings.TrimSuffix(base, filepath.Ext(base))+"."+siteID+".yaml")
+}
+
+// writeState records a change made on a server, retrying the write once. A
+// change that still cannot be saved is kept for `config reconcile`, and the
+// entry to add by hand is printed so the server and config can be brought
+// back in line either way.
+func writeState(cmd *cobra.Command, mgr *config.Manager, change state.PendingChange, write func() error) {
+	err := write()
+	if err == nil {
+		return
+	}
+	time.Sleep(stateRetryDelay)
+	if err = write(); err == nil {
+		return
+	}
+
+	outputWarning(cmd, "Failed to update configuration: %v", err)
+
+	change.Error = err.Error()
+	change.RecordedAt = time.Now()
+	if pendingErr := state.RecordPending(pendingPath(mgr), change); pendingErr != nil {
+		outputWarning(cmd, "Failed to save the pending change: %v", pendingErr)
+	} else {
+		outputWarning(cmd, "The change was saved to %s; run 'wordsail config reconcile' to apply it", pendingPath(mgr))
+	}
+
+	out := os.Stdout
+	if isJSONOutput(cmd) {
+		out = os.Stderr
+	}
+	snippet, where := pendingSnippet(change)
+	if snippet == "" {
+		return
+	}
+	fmt.Fprintf(out, "To record it by hand, %s in %s:\n%s\n", where, mgr.GetConfigPath(), snippet)
+}
+
+// pendingSnippet renders a pending change as a JSON snippet using the config
+// file's keys, along with where it belongs
+func pendingSnippet(change state.PendingChange) (string, string) {
+	var value interface{}
+	var where string
+	switch change.Kind {
+	case state.PendingAddSite:
+		value = change.Site
+		where = fmt.Sprintf("add this to the sites of server '%s'", change.Server)
+	case state.PendingDomainSSL:
+		value = change.DomainSSL
+		where = fmt.Sprintf("replace domain '%s' of site '%s' on server '%s' with this", change.Domain, change.SiteID, change.Server)
+	default:
+		return "", ""
+	}
+
+	// Round-trip through YAML so the keys match the config file
+	data, err := yaml.Marshal(value)
+	if err != nil {
+		return "", ""
+	}
+	var generic interface{}
+	if err := yaml.Unmarshal(data, &generic); err != nil {
+		return "", ""
+	}
+	snippet, err := json.MarshalIndent(generic, "", "  ")
+	if err != nil {
+		return "", ""
+	}
+	return string(snippet), where
+}
@@ -0,0 +1,143 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+	"github.com/wordsail/cli/internal/config"
+	"github.com/wordsail/cli/internal/exit"
+	"github.com/wordsail/cli/internal/utils"
+	"github.com/wordsail/cli/pkg/models"
+)
+
+// statusProbeTimeout is the default SSH probe timeout for status; short so
+// one dead server doesn't hold up the summary
+const statusProbeTimeout = 5 * time.Second
+
+// statusCmd represents the status command
+var statusCmd = &cobra.Command{
+	Use:   "status",
+	Short: "Summarize the health of every server and site",
+	Long: `Print an at-a-glance summary of the fleet: servers by status, sites and
+domains, certificates expiring soon (from the recorded expiry dates) and
+servers that don't answer a quick SSH probe. Servers are probed concurrently.
+
+Examples:
+  wordsail status
+  wordsail status --expiring 30 --ssh-timeout 3s
+  wordsail status --no-probe --json`,
+	Run: func(cmd *cobra.Command, args []string) {
+		mgr, err := config.NewManager()
+		if err != nil {
+			fail(cmd, "Failed to create config manager", err)
+		}
+
+		if !mgr.ConfigExists() {
+			fail(cmd, "Configuration file not found", exit.Errorf(exit.ConfigNotFound, "run 'wordsail init' first"))
+		}
+
+		cfg, err := mgr.Load()
+		if err != nil {
+			fail(cmd, "Failed to load configuration", exit.New(exit.Validation, err))
+		}
+
+		days, _ := cmd.Flags().GetInt("expiring")
+		if days < 0 {
+			fail(cmd, "Invalid flags", exit.Errorf(exit.Validation, "--expiring must not be negative"))
+		}
+		noProbe, _ := cmd.Flags().GetBool("no-probe")
+		timeout := sshTimeout(cmd)
+
+		status := utils.SummarizeFleet(cfg.Servers, days, time.Now())
+		if !noProbe && len(cfg.Servers) > 0 {
+			status.Probed = true
+			status.Unreachable = utils.ProbeServers(cfg.Servers, serverStatsWorkers, func(server models.Server) error {
+				return utils.TestSSHConnection(server, timeout)
+			})
+		}
+
+		if isJSONOutput(cmd) {
+			output, err := json.MarshalIndent(status, "", "  ")
+			if err != nil {
+				fail(cmd, "Failed to marshal JSON", err)
+			}
+			fmt.Println(string(output))
+			return
+		}
+
+		if status.Servers == 0 {
+			fmt.Println("No servers configured.")
+			fmt.Println("Add and provision a server with: wordsail server provision")
+			return
+		}
+
+		fmt.Println()
+		fmt.Printf("Servers:      %d (%s)\n", status.Servers, formatStatusCounts(status.ServersByStatus))
+		fmt.Printf("Sites:        %d (%d domain(s))\n", status.Sites, status.Domains)
+		if status.Probed {
+			reachable := status.Servers - len(status.Unreachable)
+			line := fmt.Sprintf("%d/%d", reachable, status.Servers)
+			if len(status.Unreachable) > 0 {
+				line = color.RedString(line)
+			}
+			fmt.Printf("Reachable:    %s\n", line)
+		}
+		certs := fmt.Sprintf("%d expiring within %d days", len(status.ExpiringCerts), status.ExpiryDays)
+		if len(status.ExpiringCerts) > 0 {
+			certs = color.YellowString(certs)
+		}
+		fmt.Printf("Certificates: %s\n", certs)
+		fmt.Println()
+
+		if status.Healthy() {
+			color.Green("✓ No problems found")
+			return
+		}
+
+		for _, server := range cfg.Servers {
+			if server.Status == "error" {
+				fmt.Printf("  %s server %s is in error state (last provision failed)\n", color.RedString("✗"), server.Name)
+			}
+		}
+		for _, u := range status.Unreachable {
+			fmt.Printf("  %s server %s unreachable: %s\n", color.RedString("✗"), u.Name, u.Error)
+		}
+		for _, c := range status.ExpiringCerts {
+			when := fmt.Sprintf("expires in %d days", c.DaysLeft)
+			if c.DaysLeft < 0 {
+				when = fmt.Sprintf("expired %d days ago", -c.DaysLeft)
+			}
+			fmt.Printf("  %s %s (%s/%s) certificate %s (%s)\n", color.YellowString("⚠"),
+				c.Domain, c.Server, c.SiteID, when, c.ExpiresAt.Format("2006-01-02"))
+		}
+		fmt.Println()
+	},
+}
+
+// formatStatusCounts renders server counts as "3 provisioned, 1 error"
+func formatStatusCounts(counts map[string]int) string {
+	names := make([]string, 0, len(counts))
+	for name := range counts {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	parts := make([]string, len(names))
+	for i, name := range names {
+		parts[i] = fmt.Sprintf("%d %s", counts[name], name)
+	}
+	return strings.Join(parts, ", ")
+}
+
+func init() {
+	rootCmd.AddCommand(statusCmd)
+
+	statusCmd.Flags().Int("expiring", 14, "Flag certificates expiring within this many days")
+	statusCmd.Flags().Duration("ssh-timeout", statusProbeTimeout, "How long each server's SSH probe may take")
+	statusCmd.Flags().Bool("no-probe", false, "Skip the SSH probe and summarize the config only")
+	statusCmd.Flags().Bool("json", false, "Output in JSON format")
+}
@@ -0,0 +1,157 @@
+package ansible
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/wordsail/cli/internal/utils"
+)
+
+// Database engines supported by the database role
+const (
+	DatabaseEngineMariaDB = "mariadb"
+	DatabaseEngineMySQL   = "mysql"
+)
+
+// supportedDatabaseVersions lists the versions that can be requested for each engine.
+// An empty version always means "use the distribution package".
+var supportedDatabaseVersions = map[string][]string{
+	DatabaseEngineMariaDB: {"10.6", "10.11", "11.4"},
+	DatabaseEngineMySQL:   {},
+}
+
+// SupportedDatabaseEngines returns the engines accepted by --db-engine
+func SupportedDatabaseEngines() []string {
+	return []string{DatabaseEngineMariaDB, DatabaseEngineMySQL}
+}
+
+// SupportedDatabaseVersions returns the pinnable versions for an engine
+func SupportedDatabaseVersions(engine string) []string {
+	return supportedDatabaseVersions[engine]
+}
+
+// ValidateDatabaseEngine checks that the engine/version combination is supported
+func ValidateDatabaseEngine(engine, version string) error {
+	versions, ok := supportedDatabaseVersions[engine]
+	if !ok {
+		return fmt.Errorf("unsupported database engine '%s' (supported: %s)",
+			engine, strings.Join(SupportedDatabaseEngines(), ", "))
+	}
+
+	if version == "" {
+		return nil
+	}
+
+	if len(versions) == 0 {
+		return fmt.Errorf("version pinning is not supported for %s; the distribution package is installed", engine)
+	}
+
+	for _, v := range versions {
+		if v == version {
+			return nil
+		}
+	}
+
+	return fmt.Errorf("unsupported %s version '%s' (supported: %s)",
+		engine, version, strings.Join(versions, ", "))
+}
+
+// DatabaseVars maps an engine/version selection to the database role variables
+func DatabaseVars(engine, version string) map[string]interface{} {
+	if engine == "" {
+		engine = DatabaseEngineMariaDB
+	}
+
+	vars := map[string]interface{}{
+		"db_engine": engine,
+	}
+	if engine == DatabaseEngineMariaDB {
+		vars["mariadb_version"] = version
+	}
+
+	return vars
+}
+
+// DatabaseServiceName returns the systemd unit the database role installs
+// for an engine (matching db_service_name in the role defaults)
+func DatabaseServiceName(engine string) string {
+	if engine == DatabaseEngineMySQL {
+		return "mysql"
+	}
+	return "mariadb"
+}
+
+// ExistingDatabase describes an already-populated database that a new site
+// is wired up to instead of creating a fresh one (e.g. after a manual restore)
+type ExistingDatabase struct {
+	Name     string
+	User     string
+	Password string
+	Host     string
+	Prefix   string
+}
+
+var (
+	mysqlIdentifierPattern = regexp.MustCompile(`^[A-Za-z0-9_$]+$`)
+	tablePrefixPattern     = regexp.MustCompile(`^[A-Za-z0-9_]+$`)
+)
+
+// Validate checks the database details before anything touches the server
+func (db ExistingDatabase) Validate() error {
+	if db.Name == "" || db.User == "" || db.Password == "" {
+		return fmt.Errorf("--db-name, --db-user and a database password (--db-password-stdin or $WORDSAIL_DB_PASSWORD) are required with --use-existing-db")
+	}
+	if len(db.Name) > 64 || !mysqlIdentifierPattern.MatchString(db.Name) {
+		return fmt.Errorf("invalid database name '%s' (letters, numbers, _ and $; max 64 characters)", db.Name)
+	}
+	if len(db.User) > 32 || !mysqlIdentifierPattern.MatchString(db.User) {
+		return fmt.Errorf("invalid database user '%s' (letters, numbers, _ and $; max 32 characters)", db.User)
+	}
+	if db.Prefix != "" && !tablePrefixPattern.MatchString(db.Prefix) {
+		return fmt.Errorf("invalid table prefix '%s' (letters, numbers and _ only)", db.Prefix)
+	}
+	return nil
+}
+
+// HostOrDefault returns the database host, defaulting to localhost
+func (db ExistingDatabase) HostOrDefault() string {
+	if db.Host != "" {
+		return db.Host
+	}
+	return "localhost"
+}
+
+// PrefixOrDefault returns the table prefix, defaulting to WordPress's wp_
+func (db ExistingDatabase) PrefixOrDefault() string {
+	if db.Prefix != "" {
+		return db.Prefix
+	}
+	return "wp_"
+}
+
+// CheckCommand returns a shell command that succeeds when the database is
+// reachable with these credentials. The password is passed via MYSQL_PWD so
+// it doesn't show up in the process list.
+func (db ExistingDatabase) CheckCommand() string {
+	host, port, found := strings.Cut(db.HostOrDefault(), ":")
+	portArg := ""
+	if found {
+		portArg = " -P " + utils.ShellQuote(port)
+	}
+	return fmt.Sprintf("MYSQL_PWD=%s mysql -h %s%s -u %s -e 'SELECT 1' %s",
+		utils.ShellQuote(db.Password), utils.ShellQuote(host), portArg, utils.ShellQuote(db.User), utils.ShellQuote(db.Name))
+}
+
+// ExistingDatabaseVars maps an existing database to the website playbook
+// variables. use_existing_db skips database creation and the WordPress install.
+func ExistingDatabaseVars(db ExistingDatabase) map[string]interface{} {
+	return map[string]interface{}{
+		"use_existing_db": true,
+		"wp_db_name":      db.Name,
+		"wp_db_user":      db.User,
+		"wp_db_password":  db.Password,
+		"wp_db_host":      db.HostOrDefault(),
+		"wp_db_prefix":    db.PrefixOrDefault(),
+	}
+}
@@ -0,0 +1,129 @@
+package ansible
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestValidateDatabaseEngine(t *testing.T) {
+	tests := []struct {
+		name    string
+		engine  string
+		version string
+		wantErr bool
+	}{
+		{"mariadb distro default", "mariadb", "", false},
+		{"mariadb 10.6", "mariadb", "10.6", false},
+		{"mariadb 10.11", "mariadb", "10.11", false},
+		{"mariadb 11.4", "mariadb", "11.4", false},
+		{"mysql distro default", "mysql", "", false},
+		{"invalid - unknown mariadb version", "mariadb", "5.5", true},
+		{"invalid - mysql with pinned version", "mysql", "10.11", true},
+		{"invalid - unknown engine", "postgres", "", true},
+		{"invalid - empty engine", "", "", true},
+		{"invalid - engine case", "MariaDB", "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateDatabaseEngine(tt.engine, tt.version)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ValidateDatabaseEngine(%q, %q) error = %v, wantErr %v", tt.engine, tt.version, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestDatabaseVars(t *testing.T) {
+	tests := []struct {
+		name    string
+		engine  string
+		version string
+		want    map[string]interface{}
+	}{
+		{"default engine", "", "", map[string]interface{}{"db_engine": "mariadb", "mariadb_version": ""}},
+		{"mariadb pinned", "mariadb", "10.11", map[string]interface{}{"db_engine": "mariadb", "mariadb_version": "10.11"}},
+		{"mysql", "mysql", "", map[string]interface{}{"db_engine": "mysql"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := DatabaseVars(tt.engine, tt.version)
+			if len(got) != len(tt.want) {
+				t.Fatalf("DatabaseVars() = %v, want %v", got, tt.want)
+			}
+			for k, v := range tt.want {
+				if got[k] != v {
+					t.Errorf("DatabaseVars()[%q] = %v, want %v", k, got[k], v)
+				}
+			}
+		})
+	}
+}
+
+func TestExistingDatabaseValidate(t *testing.T) {
+	valid := ExistingDatabase{Name: "shop_db", User: "shop", Password: "s3cret"}
+
+	tests := []struct {
+		name    string
+		modify  func(db *ExistingDatabase)
+		wantErr bool
+	}{
+		{"valid", func(db *ExistingDatabase) {}, false},
+		{"valid with prefix", func(db *ExistingDatabase) { db.Prefix = "wp2_" }, false},
+		{"missing name", func(db *ExistingDatabase) { db.Name = "" }, true},
+		{"missing user", func(db *ExistingDatabase) { db.User = "" }, true},
+		{"missing password", func(db *ExistingDatabase) { db.Password = "" }, true},
+		{"name with dash", func(db *ExistingDatabase) { db.Name = "shop-db" }, true},
+		{"name too long", func(db *ExistingDatabase) { db.Name = strings.Repeat("a", 65) }, true},
+		{"user too long", func(db *ExistingDatabase) { db.User = strings.Repeat("u", 33) }, true},
+		{"user with quote", func(db *ExistingDatabase) { db.User = "shop'" }, true},
+		{"invalid prefix", func(db *ExistingDatabase) { db.Prefix = "wp-" }, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db := valid
+			tt.modify(&db)
+			err := db.Validate()
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestExistingDatabaseVars(t *testing.T) {
+	vars := ExistingDatabaseVars(ExistingDatabase{Name: "shop_db", User: "shop", Password: "s3cret"})
+
+	want := map[string]interface{}{
+		"use_existing_db": true,
+		"wp_db_name":      "shop_db",
+		"wp_db_user":      "shop",
+		"wp_db_password":  "s3cret",
+		"wp_db_host":      "localhost",
+		"wp_db_prefix":    "wp_",
+	}
+	if !reflect.DeepEqual(vars, want) {
+		t.Errorf("ExistingDatabaseVars() = %v, want %v", vars, want)
+	}
+
+	vars = ExistingDatabaseVars(ExistingDatabase{Name: "a", User: "b", Password: "c", Host: "db.internal:3307", Prefix: "x_"})
+	if vars["wp_db_host"] != "db.internal:3307" || vars["wp_db_prefix"] != "x_" {
+		t.Errorf("expected explicit host and prefix to be kept, got %v", vars)
+	}
+}
+
+func TestExistingDatabaseCheckCommand(t *testing.T) {
+	db := ExistingDatabase{Name: "shop_db", User: "shop", Password: "it's"}
+	want := `MYSQL_PWD='it'\''s' mysql -h 'localhost' -u 'shop' -e 'SELECT 1' 'shop_db'`
+	if got := db.CheckCommand(); got != want {
+		t.Errorf("CheckCommand() = %q, want %q", got, want)
+	}
+
+	db.Host = "10.0.0.5:3307"
+	if got := db.CheckCommand(); !strings.Contains(got, "-h '10.0.0.5' -P '3307'") {
+		t.Errorf("CheckCommand() = %q, expected host and port split", got)
+	}
+}
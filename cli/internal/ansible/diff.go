@@ -0,0 +1,97 @@
+package ansible
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/fatih/color"
+)
+
+// FileDiff is one before/after diff printed by a task run with --diff
+type FileDiff struct {
+	Task  string
+	Path  string
+	Lines []string
+}
+
+// diffHeaderPattern matches the "--- before: /path" and "+++ after: /path"
+// lines that open a diff
+var diffHeaderPattern = regexp.MustCompile(`^(---|\+\+\+) (before|after)(?::\s*(.*))?$`)
+
+// diffTaskPattern matches the task heading a diff belongs to
+var diffTaskPattern = regexp.MustCompile(`^TASK \[(.+?)\]`)
+
+// diffEndPattern matches the task result or heading that follows a diff
+var diffEndPattern = regexp.MustCompile(`^(ok|changed|skipping|fatal|failed): |^(TASK|RUNNING HANDLER|PLAY) \[|^PLAY RECAP`)
+
+// parseDiffs collects the diffs Ansible prints between a task heading and
+// its result when run with --diff
+func parseDiffs(output []string) []FileDiff {
+	var diffs []FileDiff
+	var current *FileDiff
+	task := ""
+
+	flush := func() {
+		if current != nil && len(current.Lines) > 0 {
+			diffs = append(diffs, *current)
+		}
+		current = nil
+	}
+
+	for _, line := range output {
+		if matches := diffHeaderPattern.FindStringSubmatch(line); matches != nil {
+			if matches[1] == "---" {
+				flush()
+				current = &FileDiff{Task: task, Path: matches[3]}
+			} else if current != nil && current.Path == "" {
+				current.Path = matches[3]
+			}
+			if current != nil {
+				current.Lines = append(current.Lines, line)
+			}
+			continue
+		}
+
+		if m := diffTaskPattern.FindStringSubmatch(line); m != nil {
+			task = m[1]
+		}
+		if current == nil {
+			continue
+		}
+		if line == "" || diffEndPattern.MatchString(line) {
+			flush()
+			continue
+		}
+		current.Lines = append(current.Lines, line)
+	}
+	flush()
+	return diffs
+}
+
+// printDiffs prints collected diffs with added and removed lines colored
+func printDiffs(diffs []FileDiff) {
+	for _, diff := range diffs {
+		path := diff.Path
+		if path == "" {
+			path = "(state)"
+		}
+		fmt.Println()
+		color.Cyan("%s  [%s]", path, diff.Task)
+		for _, line := range diff.Lines {
+			switch {
+			case strings.HasPrefix(line, "+++"), strings.HasPrefix(line, "---"):
+				color.New(color.Bold).Println(line)
+			case strings.HasPrefix(line, "@@"):
+				color.Cyan(line)
+			case strings.HasPrefix(line, "+"):
+				color.Green(line)
+			case strings.HasPrefix(line, "-"):
+				color.Red(line)
+			default:
+				fmt.Println(line)
+			}
+		}
+	}
+	fmt.Println()
+}
@@ -0,0 +1,68 @@
+package ansible
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseDiffs(t *testing.T) {
+	output := []string{
+		"PLAY [Provision] ***",
+		"",
+		"TASK [nginx : Write nginx.conf] ***",
+		"--- before: /etc/nginx/nginx.conf",
+		"+++ after: /root/.ansible/tmp/source",
+		"@@ -1,2 +1,2 @@",
+		"-worker_processes 1;",
+		"+worker_processes auto;",
+		" events {}",
+		"",
+		"changed: [web1]",
+		"",
+		"TASK [security : Ensure fail2ban is running] ***",
+		"--- before",
+		"+++ after",
+		"@@ -1 +1 @@",
+		`-"state": "stopped"`,
+		`+"state": "started"`,
+		"changed: [web1]",
+		"",
+		"TASK [php : Install packages] ***",
+		"ok: [web1]",
+		"",
+		"PLAY RECAP ***",
+		"web1 : ok=3 changed=2 unreachable=0 failed=0",
+	}
+
+	diffs := parseDiffs(output)
+	if len(diffs) != 2 {
+		t.Fatalf("parseDiffs() found %d diff(s), want 2: %+v", len(diffs), diffs)
+	}
+
+	want := FileDiff{
+		Task: "nginx : Write nginx.conf",
+		Path: "/etc/nginx/nginx.conf",
+		Lines: []string{
+			"--- before: /etc/nginx/nginx.conf",
+			"+++ after: /root/.ansible/tmp/source",
+			"@@ -1,2 +1,2 @@",
+			"-worker_processes 1;",
+			"+worker_processes auto;",
+			" events {}",
+		},
+	}
+	if !reflect.DeepEqual(diffs[0], want) {
+		t.Errorf("diffs[0] = %+v, want %+v", diffs[0], want)
+	}
+
+	if diffs[1].Task != "security : Ensure fail2ban is running" || diffs[1].Path != "" {
+		t.Errorf("diffs[1] = %+v, want a state diff for the fail2ban task", diffs[1])
+	}
+	if len(diffs[1].Lines) != 5 {
+		t.Errorf("diffs[1] has %d line(s), want 5", len(diffs[1].Lines))
+	}
+
+	if got := parseDiffs([]string{"TASK [x] ***", "ok: [web1]"}); len(got) != 0 {
+		t.Errorf("parseDiffs() without diffs = %+v", got)
+	}
+}
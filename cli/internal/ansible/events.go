@@ -0,0 +1,37 @@
+package ansible
+
+import (
+	"encoding/json"
+	"sync"
+	"time"
+)
+
+// eventsMu keeps events from concurrent executors on separate lines
+var eventsMu sync.Mutex
+
+// emitEvent writes a single newline-delimited JSON event when JSON events are enabled.
+// Every event carries an "event" type and an RFC 3339 "time" field, plus the
+// "server" of the current run so events from parallel runs can be told apart.
+func (e *Executor) emitEvent(eventType string, fields map[string]interface{}) {
+	if !e.jsonEvents || e.events == nil {
+		return
+	}
+
+	event := make(map[string]interface{}, len(fields)+2)
+	for k, v := range fields {
+		event[k] = v
+	}
+	event["event"] = eventType
+	event["time"] = time.Now().UTC().Format(time.RFC3339)
+	if e.eventServer != "" {
+		event["server"] = e.eventServer
+	}
+
+	line, err := json.Marshal(event)
+	if err != nil {
+		return
+	}
+	eventsMu.Lock()
+	defer eventsMu.Unlock()
+	e.events.Write(append(line, '\n'))
+}
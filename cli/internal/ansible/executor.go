@@ -9,28 +9,41 @@ import (
 	"os/exec"
 	"path/filepath"
 	"regexp"
+	"strconv"
 	"strings"
 	"sync"
 	"time"
 
 	"github.com/briandowns/spinner"
 	"github.com/fatih/color"
+	"github.com/wordsail/cli/internal/exit"
+	"github.com/wordsail/cli/internal/utils"
 	"github.com/wordsail/cli/pkg/models"
+	"golang.org/x/term"
 )
 
 // ExecutionResult holds the parsed results from Ansible output
 type ExecutionResult struct {
-	Ok      int
-	Changed int
-	Failed  int
+	Ok          int
+	Changed     int
+	Failed      int
+	Unreachable int
 }
 
+// unreachableMessage explains a run that failed because Ansible could not
+// connect, as opposed to a task failing on the server
+const unreachableMessage = "server unreachable—check SSH/firewall"
+
 // PlaybookResult holds the complete result from playbook execution
 type PlaybookResult struct {
 	Success   bool
+	Stats     ExecutionResult
 	Output    []string
 	DNSStatus *DNSStatus
 	SSLInfo   *SSLInfo
+	Upgrade   *UpgradeInfo
+	Diffs     []FileDiff
+	Warnings  []string
 }
 
 // DNSStatus holds DNS check results parsed from Ansible output
@@ -39,6 +52,11 @@ type DNSStatus struct {
 	ResolvedIP string
 	ServerIP   string
 	Matches    bool
+
+	// Set only when the server has an IPv6 address configured
+	ResolvedIPv6 string
+	ServerIPv6   string
+	IPv6Matches  bool
 }
 
 // SSLInfo holds SSL issuance results parsed from Ansible output
@@ -47,13 +65,41 @@ type SSLInfo struct {
 	Expiry string
 }
 
-// Executor handles Ansible playbook execution
+// UpgradeInfo holds package upgrade results parsed from Ansible output
+type UpgradeInfo struct {
+	Count          int
+	RebootRequired bool
+}
+
+// Executor handles Ansible playbook execution. An Executor runs one playbook
+// at a time; use a separate Executor per server for concurrent runs.
 type Executor struct {
 	ansiblePath  string
 	invGenerator *InventoryGenerator
 	verbose      bool
 	dryRun       bool
+	diff         bool
+	jsonEvents   bool
+	quiet        bool
+	noSpinner    bool
+	showWarnings bool
+	eventServer  string
+	tags         string
+	skipTags     string
+	retries      int
+	overrides    map[string]interface{}
+	events       io.Writer
 	spinner      *spinner.Spinner
+
+	// progress receives the spinner, or the task lines that replace it
+	// when progress is not a terminal. It is stderr, so stdout keeps only
+	// results when it is piped.
+	progress     io.Writer
+	lineProgress bool
+	lastStatus   string
+
+	// lastOutput holds the output of the most recent playbook run
+	lastOutput []string
 }
 
 // NewExecutor creates a new Ansible executor
@@ -63,6 +109,8 @@ func NewExecutor(ansiblePath string) *Executor {
 		invGenerator: NewInventoryGenerator(),
 		verbose:      false,
 		dryRun:       false,
+		events:       os.Stdout,
+		progress:     os.Stderr,
 	}
 }
 
@@ -76,40 +124,172 @@ func (e *Executor) SetDryRun(dryRun bool) {
 	e.dryRun = dryRun
 }
 
+// SetDiff enables --diff in Ansible and prints the collected file diffs
+// after the run. Dry runs always collect diffs.
+func (e *Executor) SetDiff(diff bool) {
+	e.diff = diff
+}
+
+// showDiffs reports whether runs pass --diff to Ansible
+func (e *Executor) showDiffs() bool {
+	return e.diff || e.dryRun
+}
+
+// SetTags restricts the run to tasks with the given comma-separated tags
+func (e *Executor) SetTags(tags string) {
+	e.tags = tags
+}
+
+// SetSkipTags skips tasks with the given comma-separated tags
+func (e *Executor) SetSkipTags(skipTags string) {
+	e.skipTags = skipTags
+}
+
+// SetRetries sets how many times a run is retried after a transient
+// connection failure (e.g. a VM that is still booting)
+func (e *Executor) SetRetries(retries int) {
+	e.retries = retries
+}
+
+// SetJSONEvents enables newline-delimited JSON progress events on stdout.
+// The spinner and colored summaries are disabled in this mode.
+func (e *Executor) SetJSONEvents(enabled bool) {
+	e.jsonEvents = enabled
+}
+
+// SetQuiet disables the spinner and the colored run summary so several
+// executors can run side by side without garbling the terminal. Failures are
+// still returned as errors.
+func (e *Executor) SetQuiet(quiet bool) {
+	e.quiet = quiet
+}
+
+// SetExtraVarOverrides sets variables that take precedence over every
+// other variable of a run (from --extra-var and --extra-vars-file)
+func (e *Executor) SetExtraVarOverrides(vars map[string]interface{}) {
+	e.overrides = vars
+}
+
+// SetShowWarnings prints the full text of Ansible warnings after a
+// successful run instead of only their count
+func (e *Executor) SetShowWarnings(show bool) {
+	e.showWarnings = show
+}
+
+// SetPythonInterpreter sets the remote Python for servers without their own
+// python_interpreter (the config's ansible.python_interpreter)
+func (e *Executor) SetPythonInterpreter(path string) {
+	e.invGenerator.SetPythonInterpreter(path)
+}
+
+// SetSpinner enables or disables the progress spinner. Unlike SetQuiet, the
+// final recap line and failure details are still printed.
+func (e *Executor) SetSpinner(enabled bool) {
+	e.noSpinner = !enabled
+}
+
+// LastOutput returns the output lines of the most recent playbook run,
+// after any retries
+func (e *Executor) LastOutput() []string {
+	return e.lastOutput
+}
+
 // ExecutePlaybook runs an ansible-playbook command with the given parameters
-func (e *Executor) ExecutePlaybook(playbookName string, server models.Server, extraVars map[string]interface{}, globalVars map[string]interface{}) error {
-	// Expand home directory in ansible path if needed
-	ansiblePath := e.ansiblePath
-	if strings.HasPrefix(ansiblePath, "~") {
-		homeDir, err := os.UserHomeDir()
+// and returns the recap counts. The counts are nil only when the playbook
+// could not be started.
+func (e *Executor) ExecutePlaybook(playbookName string, server models.Server, extraVars map[string]interface{}, globalVars map[string]interface{}) (*ExecutionResult, error) {
+	// Verbose mode streams the full Ansible output instead of the spinner
+	if e.verbose && !e.jsonEvents && !e.quiet {
+		return e.executeVerbose(playbookName, server, extraVars, globalVars)
+	}
+
+	result, err := e.ExecutePlaybookWithResult(playbookName, server, extraVars, globalVars)
+	if result == nil {
+		return nil, err
+	}
+	return &result.Stats, err
+}
+
+// playbookRun holds the raw outcome of a single ansible-playbook invocation
+type playbookRun struct {
+	result      *PlaybookResult
+	stats       ExecutionResult
+	errorOutput []string
+	currentTask string
+	cmdErr      error
+}
+
+// ExecutePlaybookWithResult runs a playbook and returns parsed results.
+// Transient connection failures are retried with exponential backoff (see SetRetries).
+func (e *Executor) ExecutePlaybookWithResult(playbookName string, server models.Server, extraVars map[string]interface{}, globalVars map[string]interface{}) (*PlaybookResult, error) {
+	e.eventServer = server.Name
+	if e.jsonEvents {
+		e.emitEvent("start", map[string]interface{}{
+			"playbook": playbookName,
+			"server":   server.Name,
+		})
+	}
+
+	e.startSpinner()
+	for attempt := 0; ; attempt++ {
+		run, err := e.runPlaybook(playbookName, server, extraVars, globalVars)
 		if err != nil {
-			return fmt.Errorf("failed to expand home directory: %w", err)
+			e.stopSpinner()
+			return nil, exit.New(exit.Playbook, err)
+		}
+
+		output := make([]string, 0, len(run.result.Output)+len(run.errorOutput))
+		output = append(output, run.result.Output...)
+		output = append(output, run.errorOutput...)
+
+		if run.result.Success || attempt >= e.retries || !IsConnectionError(output) {
+			e.stopSpinner()
+			e.lastOutput = output
+			result, err := e.reportRun(run)
+			return result, playbookError(err, output)
 		}
-		ansiblePath = filepath.Join(homeDir, ansiblePath[1:])
+
+		delay := RetryDelay(attempt)
+		e.setStatus(fmt.Sprintf("Connection failed, retrying in %s (attempt %d/%d)", delay, attempt+1, e.retries))
+		e.emitEvent("retry", map[string]interface{}{
+			"attempt":       attempt + 1,
+			"max_retries":   e.retries,
+			"delay_seconds": int(delay.Seconds()),
+		})
+		time.Sleep(delay)
 	}
+}
 
-	// Generate inventory
-	inventoryPath, err := e.invGenerator.Generate(server, fmt.Sprintf("wordsail %s", playbookName), globalVars)
+// buildCommand prepares the ansible-playbook command for a playbook run.
+// The returned cleanup function removes the generated inventory.
+func (e *Executor) buildCommand(playbookName string, server models.Server, extraVars map[string]interface{}, globalVars map[string]interface{}) (*exec.Cmd, []string, func(), error) {
+	ansiblePath, err := utils.ExpandPath(e.ansiblePath)
 	if err != nil {
-		return fmt.Errorf("failed to generate inventory: %w", err)
+		return nil, nil, nil, err
 	}
-	defer e.invGenerator.Cleanup(inventoryPath)
 
 	// Build playbook path
 	playbookPath := filepath.Join(ansiblePath, playbookName)
 
 	// Check if playbook exists
 	if _, err := os.Stat(playbookPath); os.IsNotExist(err) {
-		return fmt.Errorf("playbook not found: %s", playbookPath)
+		return nil, nil, nil, fmt.Errorf("playbook not found: %s", playbookPath)
 	}
 
+	// Generate inventory
+	inventoryPath, err := e.invGenerator.Generate(server, fmt.Sprintf("wordsail %s", playbookName), globalVars)
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("failed to generate inventory: %w", err)
+	}
+	cleanup := func() { e.invGenerator.Cleanup(inventoryPath) }
+
 	// Build command arguments
 	args := []string{
 		playbookPath,
 		"-i", inventoryPath,
 	}
 
-	// Add verbose flag if enabled (only for ansible, not our spinner mode)
+	// Add verbose flag if enabled
 	if e.verbose {
 		args = append(args, "-vv")
 	}
@@ -118,9 +298,21 @@ func (e *Executor) ExecutePlaybook(playbookName string, server models.Server, ex
 	if e.dryRun {
 		args = append(args, "--check")
 	}
+	if e.showDiffs() {
+		args = append(args, "--diff")
+	}
+
+	// Restrict the run to (or exclude) tagged roles and tasks
+	if e.tags != "" {
+		args = append(args, "--tags", e.tags)
+	}
+	if e.skipTags != "" {
+		args = append(args, "--skip-tags", e.skipTags)
+	}
 
 	// Merge globalVars and extraVars for --extra-vars (highest precedence)
-	// This ensures CLI-provided values override group_vars/all.yml
+	// This ensures CLI-provided values override group_vars/all.yml. User
+	// overrides come last so they win over what the command computed.
 	allVars := make(map[string]interface{})
 	for k, v := range globalVars {
 		allVars[k] = v
@@ -128,12 +320,16 @@ func (e *Executor) ExecutePlaybook(playbookName string, server models.Server, ex
 	for k, v := range extraVars {
 		allVars[k] = v
 	}
+	for k, v := range e.overrides {
+		allVars[k] = v
+	}
 
 	// Add extra vars if any exist
 	if len(allVars) > 0 {
 		varsJSON, err := json.Marshal(allVars)
 		if err != nil {
-			return fmt.Errorf("failed to marshal extra vars: %w", err)
+			cleanup()
+			return nil, nil, nil, fmt.Errorf("failed to marshal extra vars: %w", err)
 		}
 		args = append(args, "--extra-vars", string(varsJSON))
 	}
@@ -141,157 +337,82 @@ func (e *Executor) ExecutePlaybook(playbookName string, server models.Server, ex
 	// Create command
 	cmd := exec.Command("ansible-playbook", args...)
 	cmd.Dir = ansiblePath
-
-	// Set environment variables
 	cmd.Env = os.Environ()
 
+	return cmd, args, cleanup, nil
+}
+
+// executeVerbose runs the playbook and streams the full Ansible output
+func (e *Executor) executeVerbose(playbookName string, server models.Server, extraVars map[string]interface{}, globalVars map[string]interface{}) (*ExecutionResult, error) {
+	for attempt := 0; ; attempt++ {
+		lines, err := e.runVerbose(playbookName, server, extraVars, globalVars)
+		if err == nil || attempt >= e.retries || !IsConnectionError(lines) {
+			e.lastOutput = lines
+			if lines == nil {
+				return nil, playbookError(err, lines)
+			}
+			stats := parseRecap(lines)
+			if err != nil && stats.Unreachable > 0 {
+				color.Red("✗ %s", unreachableMessage)
+				err = fmt.Errorf("%s: %w", unreachableMessage, err)
+			}
+			if err == nil {
+				e.printWarnings(parseWarnings(lines))
+			}
+			return &stats, playbookError(err, lines)
+		}
+
+		delay := RetryDelay(attempt)
+		color.Yellow("\nConnection failed, retrying in %s (attempt %d/%d)...", delay, attempt+1, e.retries)
+		time.Sleep(delay)
+	}
+}
+
+// runVerbose performs a single streamed run and returns the output lines
+func (e *Executor) runVerbose(playbookName string, server models.Server, extraVars map[string]interface{}, globalVars map[string]interface{}) ([]string, error) {
+	cmd, args, cleanup, err := e.buildCommand(playbookName, server, extraVars, globalVars)
+	if err != nil {
+		return nil, err
+	}
+	defer cleanup()
+
 	// Create pipes for stdout and stderr
 	stdout, err := cmd.StdoutPipe()
 	if err != nil {
-		return fmt.Errorf("failed to create stdout pipe: %w", err)
+		return nil, fmt.Errorf("failed to create stdout pipe: %w", err)
 	}
 
 	stderr, err := cmd.StderrPipe()
 	if err != nil {
-		return fmt.Errorf("failed to create stderr pipe: %w", err)
-	}
-
-	// Use spinner mode (quiet) by default, verbose mode shows full output
-	if e.verbose {
-		// Verbose mode: show full Ansible output
-		fmt.Printf("\n")
-		color.Cyan("Running: ansible-playbook %s", strings.Join(args, " "))
-		fmt.Printf("\n")
-
-		if err := cmd.Start(); err != nil {
-			return fmt.Errorf("failed to start ansible-playbook: %w", err)
-		}
-
-		done := make(chan bool)
-		go func() {
-			e.streamOutput(stdout, false)
-			done <- true
-		}()
-		go func() {
-			e.streamOutput(stderr, true)
-		}()
-		<-done
-
-		if err := cmd.Wait(); err != nil {
-			return fmt.Errorf("ansible-playbook failed: %w", err)
-		}
-		return nil
+		return nil, fmt.Errorf("failed to create stderr pipe: %w", err)
 	}
 
-	// Spinner mode (default): show spinner with current task
-	return e.executeWithSpinner(cmd, stdout, stderr)
-}
-
-// executeWithSpinner runs the command with a spinner showing current task
-func (e *Executor) executeWithSpinner(cmd *exec.Cmd, stdout, stderr io.ReadCloser) error {
-	// Initialize spinner
-	e.spinner = spinner.New(spinner.CharSets[14], 100*time.Millisecond)
-	e.spinner.Suffix = " Starting..."
-	e.spinner.Start()
+	fmt.Printf("\n")
+	color.Cyan("Running: ansible-playbook %s", strings.Join(args, " "))
+	fmt.Printf("\n")
 
 	if err := cmd.Start(); err != nil {
-		e.spinner.Stop()
-		return fmt.Errorf("failed to start ansible-playbook: %w", err)
+		return nil, fmt.Errorf("failed to start ansible-playbook: %w", err)
 	}
 
-	// Buffers to store output
-	var outputBuffer []string
-	var errorBuffer []string
-	var result ExecutionResult
-	var currentTask string
-	var failed bool
-	var mu sync.Mutex
-
-	// Regex patterns
-	taskPattern := regexp.MustCompile(`^TASK \[(.+?)\]`)
-	playPattern := regexp.MustCompile(`^PLAY \[(.+?)\]`)
-	recapPattern := regexp.MustCompile(`ok=(\d+)\s+changed=(\d+).*failed=(\d+)`)
-	failedPattern := regexp.MustCompile(`(FAILED!|fatal:)`)
-
-	done := make(chan bool, 2)
-
-	// Process stdout
+	var stdoutLines, stderrLines []string
+	var wg sync.WaitGroup
+	wg.Add(2)
 	go func() {
-		scanner := bufio.NewScanner(stdout)
-		for scanner.Scan() {
-			line := scanner.Text()
-			mu.Lock()
-			outputBuffer = append(outputBuffer, line)
-
-			// Check for task name
-			if matches := taskPattern.FindStringSubmatch(line); len(matches) > 1 {
-				currentTask = matches[1]
-				e.spinner.Suffix = " " + currentTask
-			} else if matches := playPattern.FindStringSubmatch(line); len(matches) > 1 {
-				e.spinner.Suffix = " " + matches[1]
-			}
-
-			// Check for failures
-			if failedPattern.MatchString(line) {
-				failed = true
-			}
-
-			// Parse recap
-			if matches := recapPattern.FindStringSubmatch(line); len(matches) > 3 {
-				fmt.Sscanf(matches[1], "%d", &result.Ok)
-				fmt.Sscanf(matches[2], "%d", &result.Changed)
-				fmt.Sscanf(matches[3], "%d", &result.Failed)
-			}
-			mu.Unlock()
-		}
-		done <- true
+		defer wg.Done()
+		stdoutLines = e.streamOutput(stdout, false)
 	}()
-
-	// Process stderr
 	go func() {
-		scanner := bufio.NewScanner(stderr)
-		for scanner.Scan() {
-			line := scanner.Text()
-			mu.Lock()
-			errorBuffer = append(errorBuffer, line)
-			if failedPattern.MatchString(line) {
-				failed = true
-			}
-			mu.Unlock()
-		}
-		done <- true
+		defer wg.Done()
+		stderrLines = e.streamOutput(stderr, true)
 	}()
+	wg.Wait()
 
-	// Wait for both streams
-	<-done
-	<-done
-
-	// Wait for command to finish
-	cmdErr := cmd.Wait()
-	e.spinner.Stop()
-
-	// Show results
-	if cmdErr != nil || failed || result.Failed > 0 {
-		// Show failure
-		color.Red("✗ Task failed: %s\n", currentTask)
-		fmt.Println()
-
-		// Show relevant error output (last 20 lines or lines containing errors)
-		mu.Lock()
-		e.printErrorContext(outputBuffer, errorBuffer)
-		mu.Unlock()
-
-		fmt.Println()
-		color.Red("Failed: %d ok, %d changed, %d failed", result.Ok, result.Changed, result.Failed)
-		if cmdErr != nil {
-			return fmt.Errorf("ansible-playbook failed")
-		}
-		return fmt.Errorf("playbook completed with failures")
+	lines := append(stdoutLines, stderrLines...)
+	if err := cmd.Wait(); err != nil {
+		return lines, fmt.Errorf("ansible-playbook failed: %w", err)
 	}
-
-	// Show success
-	color.Green("✓ Completed: %d ok, %d changed, %d failed", result.Ok, result.Changed, result.Failed)
-	return nil
+	return lines, nil
 }
 
 // printErrorContext prints relevant lines from the output when an error occurs
@@ -302,7 +423,7 @@ func (e *Executor) printErrorContext(outputBuffer, errorBuffer []string) {
 	}
 
 	// Find and print lines around the failure
-	failedPattern := regexp.MustCompile(`(?i)(FAILED|fatal:|TASK \[)`)
+	failedPattern := regexp.MustCompile(`(?i)(FAILED|fatal:|UNREACHABLE!|TASK \[)`)
 	inErrorContext := false
 	contextLines := 0
 	maxContextLines := 15
@@ -314,7 +435,7 @@ func (e *Executor) printErrorContext(outputBuffer, errorBuffer []string) {
 		}
 
 		if inErrorContext {
-			if strings.Contains(line, "FAILED") || strings.Contains(line, "fatal:") {
+			if strings.Contains(line, "FAILED") || strings.Contains(line, "fatal:") || strings.Contains(line, "UNREACHABLE!") {
 				color.Red(line)
 			} else if strings.Contains(line, "TASK [") {
 				color.Cyan(line)
@@ -329,72 +450,61 @@ func (e *Executor) printErrorContext(outputBuffer, errorBuffer []string) {
 	}
 }
 
-// ExecutePlaybookWithResult runs a playbook and returns parsed results
-func (e *Executor) ExecutePlaybookWithResult(playbookName string, server models.Server, extraVars map[string]interface{}, globalVars map[string]interface{}) (*PlaybookResult, error) {
-	// Expand home directory in ansible path if needed
-	ansiblePath := e.ansiblePath
-	if strings.HasPrefix(ansiblePath, "~") {
-		homeDir, err := os.UserHomeDir()
-		if err != nil {
-			return nil, fmt.Errorf("failed to expand home directory: %w", err)
+// setStatus updates the spinner suffix with the current play or task, or
+// prints it on its own line when progress is not a terminal
+func (e *Executor) setStatus(status string) {
+	if e.lineProgress {
+		if status != e.lastStatus {
+			fmt.Fprintf(e.progress, "→ %s\n", status)
+			e.lastStatus = status
 		}
-		ansiblePath = filepath.Join(homeDir, ansiblePath[1:])
+		return
 	}
-
-	// Generate inventory
-	inventoryPath, err := e.invGenerator.Generate(server, fmt.Sprintf("wordsail %s", playbookName), globalVars)
-	if err != nil {
-		return nil, fmt.Errorf("failed to generate inventory: %w", err)
-	}
-	defer e.invGenerator.Cleanup(inventoryPath)
-
-	// Build playbook path
-	playbookPath := filepath.Join(ansiblePath, playbookName)
-
-	// Check if playbook exists
-	if _, err := os.Stat(playbookPath); os.IsNotExist(err) {
-		return nil, fmt.Errorf("playbook not found: %s", playbookPath)
-	}
-
-	// Build command arguments
-	args := []string{
-		playbookPath,
-		"-i", inventoryPath,
+	if e.spinner != nil {
+		e.spinner.Suffix = " " + status
 	}
+}
 
-	// Add verbose flag if enabled
-	if e.verbose {
-		args = append(args, "-vv")
-	}
+// startSpinner shows the progress spinner on stderr (not used when quiet or
+// streaming JSON events). When stderr is not a terminal, such as a CI log,
+// each task is printed on a line instead.
+func (e *Executor) startSpinner() {
+	e.spinner = nil
+	e.lineProgress = false
+	e.lastStatus = ""
+	if e.jsonEvents || e.quiet || e.noSpinner {
+		return
+	}
+	if !isTerminal(e.progress) {
+		e.lineProgress = true
+		return
+	}
+	e.spinner = spinner.New(spinner.CharSets[14], 100*time.Millisecond, spinner.WithWriter(e.progress))
+	e.spinner.Suffix = " Starting..."
+	e.spinner.Start()
+}
 
-	// Add dry-run flag if enabled
-	if e.dryRun {
-		args = append(args, "--check")
-	}
+// isTerminal reports whether w is a terminal
+func isTerminal(w io.Writer) bool {
+	f, ok := w.(*os.File)
+	return ok && term.IsTerminal(int(f.Fd()))
+}
 
-	// Merge globalVars and extraVars for --extra-vars (highest precedence)
-	// This ensures CLI-provided values override group_vars/all.yml
-	allVars := make(map[string]interface{})
-	for k, v := range globalVars {
-		allVars[k] = v
-	}
-	for k, v := range extraVars {
-		allVars[k] = v
+// stopSpinner stops the progress spinner if it is running
+func (e *Executor) stopSpinner() {
+	if e.spinner != nil {
+		e.spinner.Stop()
 	}
+}
 
-	// Add extra vars if any exist
-	if len(allVars) > 0 {
-		varsJSON, err := json.Marshal(allVars)
-		if err != nil {
-			return nil, fmt.Errorf("failed to marshal extra vars: %w", err)
-		}
-		args = append(args, "--extra-vars", string(varsJSON))
+// runPlaybook performs a single ansible-playbook invocation, updating the
+// spinner (or emitting JSON events) as tasks stream past
+func (e *Executor) runPlaybook(playbookName string, server models.Server, extraVars map[string]interface{}, globalVars map[string]interface{}) (*playbookRun, error) {
+	cmd, _, cleanup, err := e.buildCommand(playbookName, server, extraVars, globalVars)
+	if err != nil {
+		return nil, err
 	}
-
-	// Create command
-	cmd := exec.Command("ansible-playbook", args...)
-	cmd.Dir = ansiblePath
-	cmd.Env = os.Environ()
+	defer cleanup()
 
 	// Create pipes for stdout and stderr
 	stdout, err := cmd.StdoutPipe()
@@ -407,19 +517,7 @@ func (e *Executor) ExecutePlaybookWithResult(playbookName string, server models.
 		return nil, fmt.Errorf("failed to create stderr pipe: %w", err)
 	}
 
-	// Execute with result capture
-	return e.executeWithSpinnerAndResult(cmd, stdout, stderr)
-}
-
-// executeWithSpinnerAndResult runs the command with spinner and returns parsed results
-func (e *Executor) executeWithSpinnerAndResult(cmd *exec.Cmd, stdout, stderr io.ReadCloser) (*PlaybookResult, error) {
-	// Initialize spinner
-	e.spinner = spinner.New(spinner.CharSets[14], 100*time.Millisecond)
-	e.spinner.Suffix = " Starting..."
-	e.spinner.Start()
-
 	if err := cmd.Start(); err != nil {
-		e.spinner.Stop()
 		return nil, fmt.Errorf("failed to start ansible-playbook: %w", err)
 	}
 
@@ -428,14 +526,14 @@ func (e *Executor) executeWithSpinnerAndResult(cmd *exec.Cmd, stdout, stderr io.
 	var errorBuffer []string
 	var result ExecutionResult
 	var currentTask string
-	var failed bool
+	var failed, unreachable bool
 	var mu sync.Mutex
 
 	// Regex patterns
 	taskPattern := regexp.MustCompile(`^TASK \[(.+?)\]`)
 	playPattern := regexp.MustCompile(`^PLAY \[(.+?)\]`)
-	recapPattern := regexp.MustCompile(`ok=(\d+)\s+changed=(\d+).*failed=(\d+)`)
 	failedPattern := regexp.MustCompile(`(FAILED!|fatal:)`)
+	unreachablePattern := regexp.MustCompile(`UNREACHABLE!`)
 
 	done := make(chan bool, 2)
 
@@ -447,21 +545,50 @@ func (e *Executor) executeWithSpinnerAndResult(cmd *exec.Cmd, stdout, stderr io.
 			mu.Lock()
 			outputBuffer = append(outputBuffer, line)
 
+			// Check for task name
 			if matches := taskPattern.FindStringSubmatch(line); len(matches) > 1 {
 				currentTask = matches[1]
-				e.spinner.Suffix = " " + currentTask
+				e.setStatus(currentTask)
+				e.emitEvent("task", map[string]interface{}{"name": currentTask})
 			} else if matches := playPattern.FindStringSubmatch(line); len(matches) > 1 {
-				e.spinner.Suffix = " " + matches[1]
+				e.setStatus(matches[1])
+				e.emitEvent("play", map[string]interface{}{"name": matches[1]})
 			}
 
-			if failedPattern.MatchString(line) {
+			// Check for failures; unreachable hosts are reported separately
+			if unreachablePattern.MatchString(line) {
+				failed, unreachable = true, true
+				e.emitEvent("unreachable", map[string]interface{}{"task": currentTask, "message": line})
+			} else if failedPattern.MatchString(line) {
 				failed = true
+				e.emitEvent("failed", map[string]interface{}{"task": currentTask, "message": line})
 			}
 
-			if matches := recapPattern.FindStringSubmatch(line); len(matches) > 3 {
-				fmt.Sscanf(matches[1], "%d", &result.Ok)
-				fmt.Sscanf(matches[2], "%d", &result.Changed)
-				fmt.Sscanf(matches[3], "%d", &result.Failed)
+			// Emit parsed markers as they stream past
+			if dns := parseDNSStatus([]string{line}); dns != nil {
+				fields := map[string]interface{}{
+					"domain":      dns.Domain,
+					"resolved_ip": dns.ResolvedIP,
+					"server_ip":   dns.ServerIP,
+					"matches":     dns.Matches,
+				}
+				if dns.ServerIPv6 != "" {
+					fields["resolved_ipv6"] = dns.ResolvedIPv6
+					fields["server_ipv6"] = dns.ServerIPv6
+					fields["ipv6_matches"] = dns.IPv6Matches
+				}
+				e.emitEvent("dns", fields)
+			}
+			if ssl := parseSSLInfo([]string{line}); ssl != nil {
+				e.emitEvent("ssl", map[string]interface{}{"domain": ssl.Domain, "expiry": ssl.Expiry})
+			}
+			if upgrade := parseUpgradeInfo([]string{line}); upgrade != nil {
+				e.emitEvent("upgraded", map[string]interface{}{"count": upgrade.Count, "reboot_required": upgrade.RebootRequired})
+			}
+
+			// Parse recap
+			if stats, ok := parseRecapLine(line); ok {
+				result = stats
 			}
 			mu.Unlock()
 		}
@@ -475,61 +602,218 @@ func (e *Executor) executeWithSpinnerAndResult(cmd *exec.Cmd, stdout, stderr io.
 			line := scanner.Text()
 			mu.Lock()
 			errorBuffer = append(errorBuffer, line)
-			if failedPattern.MatchString(line) {
+			if unreachablePattern.MatchString(line) {
+				failed, unreachable = true, true
+			} else if failedPattern.MatchString(line) {
 				failed = true
 			}
+			e.emitEvent("stderr", map[string]interface{}{"message": line})
 			mu.Unlock()
 		}
 		done <- true
 	}()
 
+	// Wait for both streams
 	<-done
 	<-done
 
+	// Wait for command to finish
 	cmdErr := cmd.Wait()
-	e.spinner.Stop()
+
+	// The recap may be the only sign of an unreachable host
+	if unreachable && result.Unreachable == 0 {
+		result.Unreachable = 1
+	}
 
 	// Parse results
 	playbookResult := &PlaybookResult{
-		Success: cmdErr == nil && !failed && result.Failed == 0,
+		Success: cmdErr == nil && !failed && result.Failed == 0 && result.Unreachable == 0,
 		Output:  outputBuffer,
 	}
 
 	// Parse DNS status and SSL info from output
 	playbookResult.DNSStatus = parseDNSStatus(outputBuffer)
 	playbookResult.SSLInfo = parseSSLInfo(outputBuffer)
+	playbookResult.Upgrade = parseUpgradeInfo(outputBuffer)
+	if e.showDiffs() {
+		playbookResult.Diffs = parseDiffs(outputBuffer)
+	}
+	playbookResult.Warnings = parseWarnings(append(append([]string{}, outputBuffer...), errorBuffer...))
+
+	return &playbookRun{
+		result:      playbookResult,
+		stats:       result,
+		errorOutput: errorBuffer,
+		currentTask: currentTask,
+		cmdErr:      cmdErr,
+	}, nil
+}
+
+// reportRun prints the outcome of a run (or emits the recap event) and
+// converts failures into an error
+func (e *Executor) reportRun(run *playbookRun) (*PlaybookResult, error) {
+	stats := run.stats
+	run.result.Stats = stats
+
+	if e.jsonEvents {
+		for _, diff := range run.result.Diffs {
+			e.emitEvent("diff", map[string]interface{}{
+				"task": diff.Task,
+				"path": diff.Path,
+				"diff": strings.Join(diff.Lines, "\n"),
+			})
+		}
+		for _, warning := range run.result.Warnings {
+			e.emitEvent("warning", map[string]interface{}{"message": warning})
+		}
+		e.emitEvent("recap", map[string]interface{}{
+			"ok":          stats.Ok,
+			"changed":     stats.Changed,
+			"failed":      stats.Failed,
+			"unreachable": stats.Unreachable,
+			"success":     run.result.Success,
+		})
+	}
 
 	// Show results
-	if cmdErr != nil || failed || result.Failed > 0 {
-		color.Red("✗ Task failed: %s\n", currentTask)
-		fmt.Println()
-		mu.Lock()
-		e.printErrorContext(outputBuffer, errorBuffer)
-		mu.Unlock()
-		fmt.Println()
-		color.Red("Failed: %d ok, %d changed, %d failed", result.Ok, result.Changed, result.Failed)
-		if cmdErr != nil {
-			return playbookResult, fmt.Errorf("ansible-playbook failed")
+	if !run.result.Success && stats.Unreachable > 0 {
+		if !e.jsonEvents && !e.quiet {
+			color.Red("✗ %s (task: %s)\n", unreachableMessage, run.currentTask)
+			fmt.Println()
+			e.printErrorContext(run.result.Output, run.errorOutput)
+		}
+		return run.result, fmt.Errorf("%s", unreachableMessage)
+	}
+	if !run.result.Success {
+		if !e.jsonEvents && !e.quiet {
+			color.Red("✗ Task failed: %s\n", run.currentTask)
+			fmt.Println()
+			e.printErrorContext(run.result.Output, run.errorOutput)
+			fmt.Println()
+			color.Red("Failed: %d ok, %d changed, %d failed", stats.Ok, stats.Changed, stats.Failed)
+		}
+		if e.quiet && run.currentTask != "" {
+			return run.result, fmt.Errorf("task '%s' failed", run.currentTask)
+		}
+		if run.cmdErr != nil {
+			return run.result, fmt.Errorf("ansible-playbook failed")
+		}
+		return run.result, fmt.Errorf("playbook completed with failures")
+	}
+
+	if !e.jsonEvents && !e.quiet {
+		if e.showDiffs() && len(run.result.Diffs) > 0 {
+			printDiffs(run.result.Diffs)
+		}
+		if e.dryRun {
+			color.Green("✓ Check complete: %d task(s) would change, %d file diff(s)", stats.Changed, len(run.result.Diffs))
+		} else {
+			color.Green("✓ Completed: %d ok, %d changed, %d failed", stats.Ok, stats.Changed, stats.Failed)
+		}
+		e.printWarnings(run.result.Warnings)
+	}
+	return run.result, nil
+}
+
+// printWarnings notes the Ansible warnings of a successful run, in full
+// with --show-warnings
+func (e *Executor) printWarnings(warnings []string) {
+	if len(warnings) == 0 {
+		return
+	}
+	if e.showWarnings {
+		color.Yellow("⚠ %d warning(s):", len(warnings))
+		for _, warning := range warnings {
+			fmt.Printf("  %s\n", warning)
 		}
-		return playbookResult, fmt.Errorf("playbook completed with failures")
+		return
 	}
+	hint := "use --show-warnings or --verbose for details"
+	if e.verbose {
+		hint = "use --show-warnings to list them"
+	}
+	color.Yellow("⚠ %d warning(s) (%s)", len(warnings), hint)
+}
 
-	color.Green("✓ Completed: %d ok, %d changed, %d failed", result.Ok, result.Changed, result.Failed)
-	return playbookResult, nil
+// playbookError tags a failed run with the playbook exit code, or the SSH
+// exit code when Ansible could not reach the server
+func playbookError(err error, output []string) error {
+	if err == nil {
+		return nil
+	}
+	if IsConnectionError(output) {
+		return exit.New(exit.SSH, err)
+	}
+	return exit.New(exit.Playbook, err)
+}
+
+// recapPattern matches a host line of the PLAY RECAP
+var recapPattern = regexp.MustCompile(`ok=(\d+)\s+changed=(\d+).*failed=(\d+)`)
+
+// recapUnreachablePattern matches the unreachable count of a recap line
+var recapUnreachablePattern = regexp.MustCompile(`unreachable=(\d+)`)
+
+// parseRecapLine parses the counts from a PLAY RECAP host line
+func parseRecapLine(line string) (ExecutionResult, bool) {
+	matches := recapPattern.FindStringSubmatch(line)
+	if len(matches) <= 3 {
+		return ExecutionResult{}, false
+	}
+	var stats ExecutionResult
+	fmt.Sscanf(matches[1], "%d", &stats.Ok)
+	fmt.Sscanf(matches[2], "%d", &stats.Changed)
+	fmt.Sscanf(matches[3], "%d", &stats.Failed)
+	if matches := recapUnreachablePattern.FindStringSubmatch(line); len(matches) > 1 {
+		fmt.Sscanf(matches[1], "%d", &stats.Unreachable)
+	}
+	return stats, true
+}
+
+// parseRecap returns the counts from the last PLAY RECAP line in output
+func parseRecap(output []string) ExecutionResult {
+	var stats ExecutionResult
+	for _, line := range output {
+		if parsed, ok := parseRecapLine(line); ok {
+			stats = parsed
+		}
+	}
+	return stats
+}
+
+// warningPattern matches Ansible warning and deprecation lines
+var warningPattern = regexp.MustCompile(`\[(WARNING|DEPRECATION WARNING)\]`)
+
+// parseWarnings collects the distinct warning lines from Ansible output
+func parseWarnings(output []string) []string {
+	var warnings []string
+	seen := make(map[string]bool)
+	for _, line := range output {
+		line = strings.TrimSpace(line)
+		if !warningPattern.MatchString(line) || seen[line] {
+			continue
+		}
+		seen[line] = true
+		warnings = append(warnings, line)
+	}
+	return warnings
 }
 
 // parseDNSStatus parses DNS_STATUS line from Ansible output
 func parseDNSStatus(output []string) *DNSStatus {
 	// Pattern: DNS_STATUS: domain=example.com resolved_ip=1.2.3.4 server_ip=5.6.7.8 matches=true
-	dnsPattern := regexp.MustCompile(`DNS_STATUS:\s*domain=(\S+)\s+resolved_ip=(\S+)\s+server_ip=(\S+)\s+matches=(\S+)`)
+	// optionally followed by: resolved_ipv6=2001:db8::1 server_ipv6=2001:db8::1 ipv6_matches=true
+	dnsPattern := regexp.MustCompile(`DNS_STATUS:\s*domain=(\S+)\s+resolved_ip=(\S+)\s+server_ip=(\S+)\s+matches=([^\s"]+)(?:\s+resolved_ipv6=(\S+)\s+server_ipv6=(\S+)\s+ipv6_matches=([^\s"]+))?`)
 
 	for _, line := range output {
-		if matches := dnsPattern.FindStringSubmatch(line); len(matches) > 4 {
+		if matches := dnsPattern.FindStringSubmatch(line); len(matches) > 7 {
 			return &DNSStatus{
-				Domain:     matches[1],
-				ResolvedIP: matches[2],
-				ServerIP:   matches[3],
-				Matches:    matches[4] == "True" || matches[4] == "true",
+				Domain:       matches[1],
+				ResolvedIP:   matches[2],
+				ServerIP:     matches[3],
+				Matches:      matches[4] == "True" || matches[4] == "true",
+				ResolvedIPv6: matches[5],
+				ServerIPv6:   matches[6],
+				IPv6Matches:  matches[7] == "True" || matches[7] == "true",
 			}
 		}
 	}
@@ -552,11 +836,13 @@ func parseSSLInfo(output []string) *SSLInfo {
 	return nil
 }
 
-// streamOutput reads and prints output with color coding
-func (e *Executor) streamOutput(reader io.Reader, isError bool) {
+// streamOutput reads and prints output with color coding and returns the lines read
+func (e *Executor) streamOutput(reader io.Reader, isError bool) []string {
+	var lines []string
 	scanner := bufio.NewScanner(reader)
 	for scanner.Scan() {
 		line := scanner.Text()
+		lines = append(lines, line)
 
 		// Color code based on content
 		if isError {
@@ -575,4 +861,22 @@ func (e *Executor) streamOutput(reader io.Reader, isError bool) {
 			fmt.Println(line)
 		}
 	}
+	return lines
+}
+
+// parseUpgradeInfo parses UPGRADED line from Ansible output
+func parseUpgradeInfo(output []string) *UpgradeInfo {
+	// Pattern: UPGRADED: count=12 reboot_required=True
+	upgradePattern := regexp.MustCompile(`UPGRADED:\s*count=(\d+)\s+reboot_required=([^\s"]+)`)
+
+	for _, line := range output {
+		if matches := upgradePattern.FindStringSubmatch(line); len(matches) > 2 {
+			count, _ := strconv.Atoi(matches[1])
+			return &UpgradeInfo{
+				Count:          count,
+				RebootRequired: matches[2] == "True" || matches[2] == "true",
+			}
+		}
+	}
+	return nil
 }
@@ -0,0 +1,208 @@
+package ansible
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+)
+
+func TestParseDNSStatus(t *testing.T) {
+	tests := []struct {
+		name   string
+		output []string
+		want   *DNSStatus
+	}{
+		{
+			name:   "IPv4 only",
+			output: []string{`    "msg": "DNS_STATUS: domain=example.com resolved_ip=1.2.3.4 server_ip=1.2.3.4 matches=True"`},
+			want:   &DNSStatus{Domain: "example.com", ResolvedIP: "1.2.3.4", ServerIP: "1.2.3.4", Matches: true},
+		},
+		{
+			name:   "IPv4 mismatch",
+			output: []string{"DNS_STATUS: domain=example.com resolved_ip=5.6.7.8 server_ip=1.2.3.4 matches=False"},
+			want:   &DNSStatus{Domain: "example.com", ResolvedIP: "5.6.7.8", ServerIP: "1.2.3.4", Matches: false},
+		},
+		{
+			name:   "dual stack, AAAA matches",
+			output: []string{`"msg": "DNS_STATUS: domain=example.com resolved_ip=1.2.3.4 server_ip=1.2.3.4 matches=True resolved_ipv6=2001:db8::1 server_ipv6=2001:db8::1 ipv6_matches=True"`},
+			want: &DNSStatus{
+				Domain: "example.com", ResolvedIP: "1.2.3.4", ServerIP: "1.2.3.4", Matches: true,
+				ResolvedIPv6: "2001:db8::1", ServerIPv6: "2001:db8::1", IPv6Matches: true,
+			},
+		},
+		{
+			name:   "dual stack, stale AAAA fails overall match",
+			output: []string{"DNS_STATUS: domain=example.com resolved_ip=1.2.3.4 server_ip=1.2.3.4 matches=False resolved_ipv6=2001:db8::99 server_ipv6=2001:db8::1 ipv6_matches=False"},
+			want: &DNSStatus{
+				Domain: "example.com", ResolvedIP: "1.2.3.4", ServerIP: "1.2.3.4", Matches: false,
+				ResolvedIPv6: "2001:db8::99", ServerIPv6: "2001:db8::1", IPv6Matches: false,
+			},
+		},
+		{
+			name:   "dual stack, no AAAA record",
+			output: []string{"DNS_STATUS: domain=example.com resolved_ip=1.2.3.4 server_ip=1.2.3.4 matches=True resolved_ipv6=none server_ipv6=2001:db8::1 ipv6_matches=True"},
+			want: &DNSStatus{
+				Domain: "example.com", ResolvedIP: "1.2.3.4", ServerIP: "1.2.3.4", Matches: true,
+				ResolvedIPv6: "none", ServerIPv6: "2001:db8::1", IPv6Matches: true,
+			},
+		},
+		{
+			name:   "no marker",
+			output: []string{"TASK [Resolve domain DNS]", "ok: [1.2.3.4]"},
+			want:   nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := parseDNSStatus(tt.output)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("parseDNSStatus() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseUpgradeInfo(t *testing.T) {
+	tests := []struct {
+		name   string
+		output []string
+		want   *UpgradeInfo
+	}{
+		{
+			name:   "packages upgraded, reboot required",
+			output: []string{`    "msg": "UPGRADED: count=12 reboot_required=True"`},
+			want:   &UpgradeInfo{Count: 12, RebootRequired: true},
+		},
+		{
+			name:   "nothing to upgrade",
+			output: []string{"UPGRADED: count=0 reboot_required=False"},
+			want:   &UpgradeInfo{Count: 0, RebootRequired: false},
+		},
+		{
+			name:   "no marker",
+			output: []string{"TASK [Upgrade packages]", "changed: [1.2.3.4]"},
+			want:   nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := parseUpgradeInfo(tt.output)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("parseUpgradeInfo() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseRecap(t *testing.T) {
+	tests := []struct {
+		name   string
+		output []string
+		want   ExecutionResult
+	}{
+		{
+			name: "recap line",
+			output: []string{
+				"PLAY RECAP *********",
+				"203.0.113.10               : ok=42   changed=7    unreachable=0    failed=1    skipped=3",
+			},
+			want: ExecutionResult{Ok: 42, Changed: 7, Failed: 1},
+		},
+		{
+			name: "unreachable host",
+			output: []string{
+				"fatal: [203.0.113.10]: UNREACHABLE! => {\"changed\": false, \"unreachable\": true}",
+				"PLAY RECAP *********",
+				"203.0.113.10               : ok=0    changed=0    unreachable=1    failed=0    skipped=0",
+			},
+			want: ExecutionResult{Unreachable: 1},
+		},
+		{
+			name:   "no recap",
+			output: []string{"fatal: [203.0.113.10]: UNREACHABLE!"},
+			want:   ExecutionResult{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := parseRecap(tt.output); got != tt.want {
+				t.Errorf("parseRecap() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseWarnings(t *testing.T) {
+	output := []string{
+		"TASK [nginx : Install nginx] ***",
+		"[WARNING]: Module remote_tmp /root/.ansible/tmp did not exist and was created",
+		"ok: [203.0.113.10]",
+		"[DEPRECATION WARNING]: community.general.yarn has been deprecated.",
+		"[WARNING]: Module remote_tmp /root/.ansible/tmp did not exist and was created",
+		"fatal: [203.0.113.10]: FAILED! => {\"msg\": \"WARNING: not an Ansible warning\"}",
+	}
+	want := []string{
+		"[WARNING]: Module remote_tmp /root/.ansible/tmp did not exist and was created",
+		"[DEPRECATION WARNING]: community.general.yarn has been deprecated.",
+	}
+	if got := parseWarnings(output); !reflect.DeepEqual(got, want) {
+		t.Errorf("parseWarnings() = %q, want %q", got, want)
+	}
+	if got := parseWarnings([]string{"ok: [203.0.113.10]"}); got != nil {
+		t.Errorf("parseWarnings() without warnings = %q, want nil", got)
+	}
+}
+
+func TestLineProgressWithoutTerminal(t *testing.T) {
+	var out bytes.Buffer
+	e := NewExecutor("/tmp/ansible")
+	e.progress = &out
+
+	e.startSpinner()
+	e.setStatus("Install nginx")
+	e.setStatus("Install nginx")
+	e.setStatus("Reload nginx")
+	e.stopSpinner()
+
+	if e.spinner != nil {
+		t.Error("startSpinner() started a spinner on a non-terminal writer")
+	}
+	if got, want := out.String(), "→ Install nginx\n→ Reload nginx\n"; got != want {
+		t.Errorf("progress output = %q, want %q", got, want)
+	}
+
+	// Disabled progress prints nothing, even without a terminal
+	out.Reset()
+	e.SetQuiet(true)
+	e.startSpinner()
+	e.setStatus("Install nginx")
+	if out.Len() != 0 {
+		t.Errorf("quiet progress output = %q, want none", out.String())
+	}
+}
+
+func TestStartSpinnerDisabled(t *testing.T) {
+	tests := []struct {
+		name      string
+		configure func(*Executor)
+	}{
+		{"spinner disabled", func(e *Executor) { e.SetSpinner(false) }},
+		{"quiet", func(e *Executor) { e.SetQuiet(true) }},
+		{"json events", func(e *Executor) { e.SetJSONEvents(true) }},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := NewExecutor("/tmp/ansible")
+			tt.configure(e)
+			e.startSpinner()
+			defer e.stopSpinner()
+			if e.spinner != nil {
+				t.Error("startSpinner() started a spinner")
+			}
+		})
+	}
+}
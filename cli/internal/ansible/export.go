@@ -0,0 +1,156 @@
+package ansible
+
+import (
+	"fmt"
+	"io"
+	"regexp"
+	"sort"
+	"time"
+
+	"github.com/wordsail/cli/pkg/models"
+	"gopkg.in/yaml.v3"
+)
+
+// Export formats
+const (
+	ExportINI  = "ini"
+	ExportYAML = "yaml"
+)
+
+// RedactedValue replaces secret global vars in exported inventories
+const RedactedValue = "REDACTED"
+
+// secretVarPattern matches global var names whose values are credentials
+var secretVarPattern = regexp.MustCompile(`(?i)(password|passwd|secret|token|api_?key)`)
+
+// IsSecretVar reports whether a global var holds a credential that exported
+// inventories leave out unless asked to include it
+func IsSecretVar(name string) bool {
+	return secretVarPattern.MatchString(name)
+}
+
+// ExportOptions controls Export
+type ExportOptions struct {
+	Format         string
+	IncludeSecrets bool
+}
+
+// Export writes a static inventory with every given server in the
+// webservers group, for running Ansible by hand. Unlike Generate it writes
+// to w and nothing is cleaned up afterwards.
+func (ig *InventoryGenerator) Export(w io.Writer, servers []models.Server, globalVars map[string]interface{}, opts ExportOptions) error {
+	varsMap := inventoryVars(globalVars)
+	if !opts.IncludeSecrets {
+		for key := range varsMap {
+			if IsSecretVar(key) {
+				varsMap[key] = RedactedValue
+			}
+		}
+	}
+	varsMap["ansible_python_interpreter"] = ig.defaultPythonInterpreter()
+
+	hosts := make([]exportHost, 0, len(servers))
+	for _, server := range servers {
+		server, err := expandServerKey(server)
+		if err != nil {
+			return fmt.Errorf("server '%s': %w", server.Name, err)
+		}
+		vars := hostVars(server)
+		// Servers with their own interpreter override the group default
+		if interpreter := ig.pythonInterpreterFor(server); interpreter != varsMap["ansible_python_interpreter"] {
+			vars["ansible_python_interpreter"] = interpreter
+		}
+		hosts = append(hosts, exportHost{name: server.Name, vars: vars})
+	}
+
+	switch opts.Format {
+	case ExportINI, "":
+		return writeINIInventory(w, hosts, varsMap)
+	case ExportYAML:
+		return writeYAMLInventory(w, hosts, varsMap)
+	default:
+		return fmt.Errorf("unknown inventory format '%s' (supported: %s, %s)", opts.Format, ExportINI, ExportYAML)
+	}
+}
+
+// exportHost is a server's inventory name and connection vars
+type exportHost struct {
+	name string
+	vars map[string]string
+}
+
+// hostVars returns the per-server vars the generated inventories set
+func hostVars(server models.Server) map[string]string {
+	vars := map[string]string{
+		"ansible_host": server.Address(),
+		"ansible_user": server.SSH.User,
+		"ansible_port": fmt.Sprintf("%d", server.SSH.Port),
+	}
+	if server.SSH.KeyFile != "" && !server.SSH.UseAgent {
+		vars["ansible_ssh_private_key_file"] = server.SSH.KeyFile
+	}
+	if args := sshCommonArgs(server.SSH); args != "" {
+		vars["ansible_ssh_common_args"] = args
+	}
+	if server.IPv6 != "" {
+		vars["server_ipv6"] = server.IPv6
+	}
+	return vars
+}
+
+func writeINIInventory(w io.Writer, hosts []exportHost, vars map[string]string) error {
+	fmt.Fprintf(w, "# Exported by wordsail\n# Generated: %s\n\n[webservers]\n", time.Now().Format(time.RFC3339))
+	for _, host := range hosts {
+		fmt.Fprint(w, host.name)
+		for _, key := range sortedKeys(host.vars) {
+			value := host.vars[key]
+			if key == "ansible_ssh_common_args" {
+				value = "'" + value + "'"
+			}
+			fmt.Fprintf(w, " %s=%s", key, value)
+		}
+		fmt.Fprintln(w)
+	}
+
+	fmt.Fprint(w, "\n[webservers:vars]\n")
+	for _, key := range sortedKeys(vars) {
+		if _, err := fmt.Fprintf(w, "%s=%s\n", key, vars[key]); err != nil {
+			return fmt.Errorf("failed to write inventory: %w", err)
+		}
+	}
+	return nil
+}
+
+func writeYAMLInventory(w io.Writer, hosts []exportHost, vars map[string]string) error {
+	hostMap := make(map[string]map[string]string, len(hosts))
+	for _, host := range hosts {
+		hostMap[host.name] = host.vars
+	}
+	inventory := map[string]interface{}{
+		"all": map[string]interface{}{
+			"children": map[string]interface{}{
+				"webservers": map[string]interface{}{
+					"hosts": hostMap,
+					"vars":  vars,
+				},
+			},
+		},
+	}
+
+	fmt.Fprintf(w, "# Exported by wordsail\n# Generated: %s\n", time.Now().Format(time.RFC3339))
+	encoder := yaml.NewEncoder(w)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(inventory); err != nil {
+		return fmt.Errorf("failed to write inventory: %w", err)
+	}
+	return encoder.Close()
+}
+
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,125 @@
+package ansible
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/wordsail/cli/pkg/models"
+	"gopkg.in/yaml.v3"
+)
+
+func exportServers() []models.Server {
+	return []models.Server{
+		{
+			Name: "web1",
+			IP:   "203.0.113.10",
+			IPv6: "2001:db8::10",
+			SSH:  models.SSHConfig{User: "root", Port: 22, KeyFile: "/keys/id"},
+		},
+		{
+			Name:     "private",
+			Hostname: "10.0.1.5",
+			SSH:      models.SSHConfig{User: "ops", Port: 2222, UseAgent: true, JumpHost: "bastion.example.com"},
+		},
+	}
+}
+
+func TestExportINI(t *testing.T) {
+	globalVars := map[string]interface{}{
+		"certbot_email":     "admin@example.com",
+		"smtp_password":     "hunter2",
+		"cloudflare_apikey": "abc",
+	}
+
+	var buf bytes.Buffer
+	if err := NewInventoryGenerator().Export(&buf, exportServers(), globalVars, ExportOptions{}); err != nil {
+		t.Fatalf("Export() error = %v", err)
+	}
+	out := buf.String()
+
+	for _, want := range []string{
+		"[webservers]\n",
+		"web1 ansible_host=203.0.113.10 ansible_port=22 ansible_ssh_private_key_file=/keys/id ansible_user=root server_ipv6=2001:db8::10\n",
+		`private ansible_host=10.0.1.5 ansible_port=2222 ansible_ssh_common_args='-o ProxyCommand="ssh -W [%h]:%p -q -p 22 ops@bastion.example.com"' ansible_user=ops` + "\n",
+		"[webservers:vars]\n",
+		"certbot_email=admin@example.com\n",
+		"smtp_password=REDACTED\n",
+		"cloudflare_apikey=REDACTED\n",
+		"ansible_python_interpreter=/usr/bin/python3\n",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("inventory is missing %q:\n%s", want, out)
+		}
+	}
+
+	buf.Reset()
+	if err := NewInventoryGenerator().Export(&buf, exportServers(), globalVars, ExportOptions{IncludeSecrets: true}); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(buf.String(), "smtp_password=hunter2\n") {
+		t.Errorf("--include-secrets should keep secret values:\n%s", buf.String())
+	}
+}
+
+func TestExportYAML(t *testing.T) {
+	var buf bytes.Buffer
+	opts := ExportOptions{Format: ExportYAML}
+	if err := NewInventoryGenerator().Export(&buf, exportServers(), map[string]interface{}{"api_token": "x"}, opts); err != nil {
+		t.Fatalf("Export() error = %v", err)
+	}
+
+	var inventory struct {
+		All struct {
+			Children struct {
+				Webservers struct {
+					Hosts map[string]map[string]string `yaml:"hosts"`
+					Vars  map[string]string            `yaml:"vars"`
+				} `yaml:"webservers"`
+			} `yaml:"children"`
+		} `yaml:"all"`
+	}
+	if err := yaml.Unmarshal(buf.Bytes(), &inventory); err != nil {
+		t.Fatalf("exported YAML does not parse: %v\n%s", err, buf.String())
+	}
+	group := inventory.All.Children.Webservers
+	if got := group.Hosts["web1"]["ansible_host"]; got != "203.0.113.10" {
+		t.Errorf("web1 ansible_host = %q", got)
+	}
+	if got := group.Hosts["private"]["ansible_port"]; got != "2222" {
+		t.Errorf("private ansible_port = %q", got)
+	}
+	if got := group.Vars["api_token"]; got != RedactedValue {
+		t.Errorf("api_token = %q, want it redacted", got)
+	}
+}
+
+func TestExportUnknownFormat(t *testing.T) {
+	err := NewInventoryGenerator().Export(&bytes.Buffer{}, exportServers(), nil, ExportOptions{Format: "toml"})
+	if err == nil {
+		t.Error("Export() with an unknown format should fail")
+	}
+}
+
+func TestExportPythonInterpreter(t *testing.T) {
+	servers := exportServers()
+	servers[1].PythonInterpreter = "/opt/venv/bin/python"
+
+	ig := NewInventoryGenerator()
+	ig.SetPythonInterpreter("/usr/bin/python3.11")
+	var buf bytes.Buffer
+	if err := ig.Export(&buf, servers, nil, ExportOptions{}); err != nil {
+		t.Fatalf("Export() error = %v", err)
+	}
+	out := buf.String()
+
+	if !strings.Contains(out, "ansible_python_interpreter=/usr/bin/python3.11\n") {
+		t.Errorf("group vars should use the configured interpreter:\n%s", out)
+	}
+	if !strings.Contains(out, " ansible_python_interpreter=/opt/venv/bin/python ") {
+		t.Errorf("the private host should override the interpreter:\n%s", out)
+	}
+	if strings.Count(out, "ansible_python_interpreter=") != 2 {
+		t.Errorf("only the overriding host should set its own interpreter:\n%s", out)
+	}
+}
@@ -0,0 +1,81 @@
+package ansible
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// extraVarNamePattern matches a valid Ansible variable name
+var extraVarNamePattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// ParseExtraVar splits a key=value pair from --extra-var. Values of true or
+// false become bools and whole numbers become ints; anything else is kept
+// as a string.
+func ParseExtraVar(pair string) (string, interface{}, error) {
+	key, value, ok := strings.Cut(pair, "=")
+	if !ok {
+		return "", nil, fmt.Errorf("%q is not in key=value form", pair)
+	}
+	key = strings.TrimSpace(key)
+	if !extraVarNamePattern.MatchString(key) {
+		return "", nil, fmt.Errorf("%q is not a valid variable name", key)
+	}
+
+	switch strings.ToLower(value) {
+	case "true":
+		return key, true, nil
+	case "false":
+		return key, false, nil
+	}
+	if n, err := strconv.Atoi(value); err == nil {
+		return key, n, nil
+	}
+	return key, value, nil
+}
+
+// LoadExtraVarsFile reads a YAML (or JSON) file holding a mapping of
+// variables
+func LoadExtraVarsFile(path string) (map[string]interface{}, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	vars := make(map[string]interface{})
+	if err := yaml.Unmarshal(data, &vars); err != nil {
+		return nil, fmt.Errorf("%s: expected a mapping of variables: %w", path, err)
+	}
+	for key := range vars {
+		if !extraVarNamePattern.MatchString(key) {
+			return nil, fmt.Errorf("%s: %q is not a valid variable name", path, key)
+		}
+	}
+	return vars, nil
+}
+
+// BuildExtraVars merges the variables from file (if set) with key=value
+// pairs, the pairs taking precedence
+func BuildExtraVars(file string, pairs []string) (map[string]interface{}, error) {
+	vars := make(map[string]interface{})
+	if file != "" {
+		loaded, err := LoadExtraVarsFile(file)
+		if err != nil {
+			return nil, err
+		}
+		for key, value := range loaded {
+			vars[key] = value
+		}
+	}
+	for _, pair := range pairs {
+		key, value, err := ParseExtraVar(pair)
+		if err != nil {
+			return nil, err
+		}
+		vars[key] = value
+	}
+	return vars, nil
+}
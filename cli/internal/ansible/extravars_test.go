@@ -0,0 +1,107 @@
+package ansible
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/wordsail/cli/pkg/models"
+)
+
+func TestParseExtraVar(t *testing.T) {
+	tests := []struct {
+		pair    string
+		key     string
+		value   interface{}
+		wantErr bool
+	}{
+		{pair: "php_memory_limit=512M", key: "php_memory_limit", value: "512M"},
+		{pair: "nginx_worker_connections=2048", key: "nginx_worker_connections", value: 2048},
+		{pair: "enable_redis=true", key: "enable_redis", value: true},
+		{pair: "enable_redis=False", key: "enable_redis", value: false},
+		{pair: "motd=a=b", key: "motd", value: "a=b"},
+		{pair: "empty=", key: "empty", value: ""},
+		{pair: "novalue", wantErr: true},
+		{pair: "bad-name=1", wantErr: true},
+		{pair: "=1", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.pair, func(t *testing.T) {
+			key, value, err := ParseExtraVar(tt.pair)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseExtraVar() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if key != tt.key || !reflect.DeepEqual(value, tt.value) {
+				t.Errorf("ParseExtraVar() = %q, %#v, want %q, %#v", key, value, tt.key, tt.value)
+			}
+		})
+	}
+}
+
+func TestBuildExtraVars(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "vars.yml")
+	content := "php_memory_limit: 256M\nphp_max_children: 10\nallowed:\n  - a\n  - b\n"
+	if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	vars, err := BuildExtraVars(path, []string{"php_memory_limit=512M"})
+	if err != nil {
+		t.Fatalf("BuildExtraVars() error = %v", err)
+	}
+	want := map[string]interface{}{
+		"php_memory_limit": "512M", // the flag wins over the file
+		"php_max_children": 10,
+		"allowed":          []interface{}{"a", "b"},
+	}
+	if !reflect.DeepEqual(vars, want) {
+		t.Errorf("BuildExtraVars() = %#v, want %#v", vars, want)
+	}
+
+	if err := os.WriteFile(path, []byte("- not\n- a mapping\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := BuildExtraVars(path, nil); err == nil {
+		t.Error("BuildExtraVars() with a list file should fail")
+	}
+	if _, err := BuildExtraVars(filepath.Join(t.TempDir(), "missing.yml"), nil); err == nil {
+		t.Error("BuildExtraVars() with a missing file should fail")
+	}
+}
+
+func TestBuildCommandExtraVarOverrides(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "site.yml"), []byte("---\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	e := NewExecutor(dir)
+	e.SetExtraVarOverrides(map[string]interface{}{"php_memory_limit": "512M"})
+	server := models.Server{Name: "prod", IP: "203.0.113.10", SSH: models.SSHConfig{User: "root", Port: 22}}
+
+	_, args, cleanup, err := e.buildCommand("site.yml", server,
+		map[string]interface{}{"php_memory_limit": "128M", "site_id": "examplecom"},
+		map[string]interface{}{"php_memory_limit": "256M", "admin_email": "ops@example.com"})
+	if err != nil {
+		t.Fatalf("buildCommand() error = %v", err)
+	}
+	defer cleanup()
+
+	var vars map[string]interface{}
+	for i, arg := range args {
+		if arg == "--extra-vars" && i+1 < len(args) {
+			if err := json.Unmarshal([]byte(args[i+1]), &vars); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+	want := map[string]interface{}{"php_memory_limit": "512M", "site_id": "examplecom", "admin_email": "ops@example.com"}
+	if !reflect.DeepEqual(vars, want) {
+		t.Errorf("--extra-vars = %v, want %v", vars, want)
+	}
+}
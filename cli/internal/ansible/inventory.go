@@ -1,112 +1,243 @@
 package ansible
 
 import (
+	"bytes"
 	_ "embed"
 	"fmt"
 	"os"
 	"path/filepath"
-	"strings"
 	"text/template"
 	"time"
 
+	"github.com/wordsail/cli/internal/config"
+	"github.com/wordsail/cli/internal/utils"
 	"github.com/wordsail/cli/pkg/models"
 )
 
 //go:embed inventory.tmpl
 var inventoryTemplate string
 
-// InventoryData holds the data for inventory template
+// InventoryTemplateFile is the file in ~/.wordsail that replaces the
+// embedded inventory template when it exists
+const InventoryTemplateFile = "inventory.tmpl"
+
+// InventoryData holds the data for inventory template. Its fields are what a
+// custom inventory.tmpl can use.
 type InventoryData struct {
-	Timestamp         string
-	Server            models.Server
-	Command           string
+	// Timestamp is when the inventory was generated (RFC 3339)
+	Timestamp string
+	// Server is the target server as stored in the config
+	Server models.Server
+	// Command is the playbook being run
+	Command string
+	// PythonInterpreter is the remote Python used by Ansible
 	PythonInterpreter string
-	GlobalVars        map[string]string
+	// SSHCommonArgs routes SSH through the jump host; empty without one
+	SSHCommonArgs string
+	// GlobalVars are the config's global vars with paths expanded
+	GlobalVars map[string]string
 }
 
+// Each run writes its inventory file (0600) to a private directory (0700)
+// created in the system temp dir, and removes both afterwards. Directories
+// matching InventoryDirPattern that stay behind come from runs that were
+// interrupted; the file inside matches InventoryFilePattern. Older versions
+// wrote the files directly to LegacyInventoryDir.
+const (
+	InventoryDirPattern  = "wordsail-inv-*"
+	InventoryFilePattern = "wordsail-*.ini"
+	LegacyInventoryDir   = "/tmp"
+)
+
+// DefaultPythonInterpreter is the remote Python used when neither the
+// config nor the server sets one
+const DefaultPythonInterpreter = "/usr/bin/python3"
+
 // InventoryGenerator generates Ansible inventory files
 type InventoryGenerator struct {
+	// outputDir holds the inventory files when set; empty creates a
+	// private directory for each run
 	outputDir string
+
+	// pythonInterpreter is the config's ansible.python_interpreter
+	pythonInterpreter string
+
+	// templatePath is a custom template used instead of the embedded one
+	// when the file exists
+	templatePath string
 }
 
-// NewInventoryGenerator creates a new inventory generator
+// NewInventoryGenerator creates a new inventory generator that uses
+// ~/.wordsail/inventory.tmpl when present
 func NewInventoryGenerator() *InventoryGenerator {
-	return &InventoryGenerator{
-		outputDir: "/tmp",
+	ig := &InventoryGenerator{}
+	if home, err := os.UserHomeDir(); err == nil {
+		ig.templatePath = filepath.Join(home, config.DefaultConfigDir, InventoryTemplateFile)
 	}
+	return ig
 }
 
-// Generate creates an inventory file for the given server
-func (ig *InventoryGenerator) Generate(server models.Server, command string, globalVars map[string]interface{}) (string, error) {
-	// Convert globalVars to string map
-	varsMap := make(map[string]string)
-	for key, val := range globalVars {
-		varsMap[key] = fmt.Sprintf("%v", val)
-	}
+// SetPythonInterpreter sets the remote Python used for servers without
+// their own python_interpreter. Empty means DefaultPythonInterpreter.
+func (ig *InventoryGenerator) SetPythonInterpreter(path string) {
+	ig.pythonInterpreter = path
+}
 
-	// Expand environment variables in values
-	for key, val := range varsMap {
-		varsMap[key] = os.ExpandEnv(val)
+// defaultPythonInterpreter returns the configured remote Python, or
+// DefaultPythonInterpreter
+func (ig *InventoryGenerator) defaultPythonInterpreter() string {
+	if ig.pythonInterpreter != "" {
+		return ig.pythonInterpreter
 	}
+	return DefaultPythonInterpreter
+}
 
-	// Get home directory once for reuse
-	homeDir, err := os.UserHomeDir()
-	if err != nil {
-		homeDir = "" // Will skip home expansion if we can't get it
+// pythonInterpreterFor returns the remote Python for a server: its own
+// python_interpreter, else the configured default
+func (ig *InventoryGenerator) pythonInterpreterFor(server models.Server) string {
+	if server.PythonInterpreter != "" {
+		return server.PythonInterpreter
 	}
+	return ig.defaultPythonInterpreter()
+}
 
-	// Expand home directory in global vars (especially wordsail_ssh_key)
-	for key, val := range varsMap {
-		if strings.HasPrefix(val, "~") && homeDir != "" {
-			varsMap[key] = filepath.Join(homeDir, val[1:])
+// loadTemplate parses the custom template if there is one, otherwise the
+// embedded template
+func (ig *InventoryGenerator) loadTemplate() (*template.Template, error) {
+	if ig.templatePath != "" {
+		data, err := os.ReadFile(ig.templatePath)
+		if err == nil {
+			tmpl, err := template.New("inventory").Parse(string(data))
+			if err != nil {
+				return nil, fmt.Errorf("invalid inventory template %s: %w", ig.templatePath, err)
+			}
+			return tmpl, nil
+		}
+		if !os.IsNotExist(err) {
+			return nil, fmt.Errorf("failed to read inventory template: %w", err)
 		}
 	}
 
-	// Expand home directory in SSH key file
-	sshKeyFile := server.SSH.KeyFile
-	if strings.HasPrefix(sshKeyFile, "~") && homeDir != "" {
-		sshKeyFile = filepath.Join(homeDir, sshKeyFile[1:])
+	tmpl, err := template.New("inventory").Parse(inventoryTemplate)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse inventory template: %w", err)
+	}
+	return tmpl, nil
+}
+
+// Generate creates an inventory file for the given server
+func (ig *InventoryGenerator) Generate(server models.Server, command string, globalVars map[string]interface{}) (string, error) {
+	varsMap := inventoryVars(globalVars)
+	server, err := expandServerKey(server)
+	if err != nil {
+		return "", err
 	}
-	server.SSH.KeyFile = sshKeyFile
 
 	// Prepare template data
 	data := InventoryData{
 		Timestamp:         time.Now().Format(time.RFC3339),
 		Server:            server,
 		Command:           command,
-		PythonInterpreter: "/usr/bin/python3",
+		PythonInterpreter: ig.pythonInterpreterFor(server),
+		SSHCommonArgs:     sshCommonArgs(server.SSH),
 		GlobalVars:        varsMap,
 	}
 
-	// Parse template
-	tmpl, err := template.New("inventory").Parse(inventoryTemplate)
+	// Render first so a broken custom template leaves no file behind
+	tmpl, err := ig.loadTemplate()
 	if err != nil {
-		return "", fmt.Errorf("failed to parse inventory template: %w", err)
+		return "", err
+	}
+	var rendered bytes.Buffer
+	if err := tmpl.Execute(&rendered, data); err != nil {
+		return "", fmt.Errorf("failed to execute inventory template: %w", err)
 	}
 
-	// Generate unique filename
-	timestamp := time.Now().Format("20060102-150405")
-	outputPath := filepath.Join(ig.outputDir, fmt.Sprintf("wordsail-%s-%s.ini", server.Name, timestamp))
+	dir := ig.outputDir
+	if dir == "" {
+		dir, err = os.MkdirTemp("", InventoryDirPattern)
+		if err != nil {
+			return "", fmt.Errorf("failed to create inventory directory: %w", err)
+		}
+	}
 
-	// Create output file
-	f, err := os.Create(outputPath)
+	// Generate a unique filename; the random suffix keeps concurrent runs
+	// against the same server (or a renamed one) from sharing a file.
+	// CreateTemp makes the file readable by the current user only.
+	timestamp := time.Now().Format("20060102-150405")
+	f, err := os.CreateTemp(dir, fmt.Sprintf("wordsail-%s-%s-*.ini", server.Name, timestamp))
 	if err != nil {
+		if dir != ig.outputDir {
+			os.Remove(dir)
+		}
 		return "", fmt.Errorf("failed to create inventory file: %w", err)
 	}
-	defer f.Close()
+	outputPath := f.Name()
 
-	// Execute template
-	if err := tmpl.Execute(f, data); err != nil {
-		return "", fmt.Errorf("failed to execute inventory template: %w", err)
+	_, err = f.Write(rendered.Bytes())
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		ig.Cleanup(outputPath)
+		return "", fmt.Errorf("failed to write inventory file: %w", err)
 	}
 
 	return outputPath, nil
 }
 
-// Cleanup removes a generated inventory file
+// inventoryVars converts global vars to strings, expanding environment
+// variables and home directories in values (especially wordsail_ssh_key).
+// Values that are not paths, such as a "~name" that is no user, keep their
+// variables expanded.
+func inventoryVars(globalVars map[string]interface{}) map[string]string {
+	varsMap := make(map[string]string, len(globalVars))
+	for key, val := range globalVars {
+		str := fmt.Sprintf("%v", val)
+		expanded, err := utils.ExpandPath(str)
+		if err != nil {
+			expanded = os.ExpandEnv(str)
+		}
+		varsMap[key] = expanded
+	}
+	return varsMap
+}
+
+// expandServerKey returns the server with its SSH key path expanded
+func expandServerKey(server models.Server) (models.Server, error) {
+	sshKeyFile, err := utils.ExpandPath(server.SSH.KeyFile)
+	if err != nil {
+		return server, fmt.Errorf("invalid SSH key path: %w", err)
+	}
+	server.SSH.KeyFile = sshKeyFile
+	return server, nil
+}
+
+// sshCommonArgs returns extra ssh arguments for routing through a jump host.
+// ProxyCommand is used instead of ProxyJump because options given on the
+// command line (like the identity file) do not apply to ProxyJump hosts.
+// The target is bracketed so IPv6 literals are not split at their colons.
+func sshCommonArgs(sshCfg models.SSHConfig) string {
+	if !sshCfg.HasJumpHost() {
+		return ""
+	}
+	identity := ""
+	if sshCfg.KeyFile != "" && !sshCfg.UseAgent {
+		identity = fmt.Sprintf(" -i %s", sshCfg.KeyFile)
+	}
+	return fmt.Sprintf(`-o ProxyCommand="ssh -W [%%h]:%%p -q%s -p %d %s@%s"`,
+		identity, sshCfg.JumpPortOrDefault(), sshCfg.JumpLogin(), sshCfg.JumpHost)
+}
+
+// Cleanup removes a generated inventory file, along with the private
+// directory it was written to
 func (ig *InventoryGenerator) Cleanup(inventoryPath string) error {
 	if inventoryPath == "" {
 		return nil
 	}
+	dir := filepath.Dir(inventoryPath)
+	if matched, _ := filepath.Match(InventoryDirPattern, filepath.Base(dir)); ig.outputDir == "" && matched {
+		return os.RemoveAll(dir)
+	}
 	return os.Remove(inventoryPath)
 }
@@ -0,0 +1,306 @@
+package ansible
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/wordsail/cli/pkg/models"
+)
+
+func TestSSHCommonArgs(t *testing.T) {
+	tests := []struct {
+		name string
+		ssh  models.SSHConfig
+		want string
+	}{
+		{
+			name: "no jump host",
+			ssh:  models.SSHConfig{User: "root", Port: 22, KeyFile: "/keys/id"},
+			want: "",
+		},
+		{
+			name: "jump host with defaults",
+			ssh:  models.SSHConfig{User: "root", Port: 22, KeyFile: "/keys/id", JumpHost: "bastion.example.com"},
+			want: `-o ProxyCommand="ssh -W [%h]:%p -q -i /keys/id -p 22 root@bastion.example.com"`,
+		},
+		{
+			name: "jump host with user and port",
+			ssh:  models.SSHConfig{User: "root", Port: 22, KeyFile: "/keys/id", JumpHost: "10.0.0.1", JumpUser: "ops", JumpPort: 2222},
+			want: `-o ProxyCommand="ssh -W [%h]:%p -q -i /keys/id -p 2222 ops@10.0.0.1"`,
+		},
+		{
+			name: "jump host with ssh-agent",
+			ssh:  models.SSHConfig{User: "root", Port: 22, KeyFile: "/keys/id", UseAgent: true, JumpHost: "bastion.example.com"},
+			want: `-o ProxyCommand="ssh -W [%h]:%p -q -p 22 root@bastion.example.com"`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := sshCommonArgs(tt.ssh); got != tt.want {
+				t.Errorf("sshCommonArgs() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGenerateJumpHost(t *testing.T) {
+	ig := &InventoryGenerator{outputDir: t.TempDir()}
+	server := models.Server{
+		Name: "private",
+		IP:   "10.0.1.5",
+		SSH:  models.SSHConfig{User: "root", Port: 22, KeyFile: "/keys/id", JumpHost: "bastion.example.com", JumpUser: "ops"},
+	}
+
+	path, err := ig.Generate(server, "test", nil)
+	if err != nil {
+		t.Fatalf("Generate() error = %v", err)
+	}
+	content, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("failed to read inventory: %v", err)
+	}
+
+	want := `ansible_ssh_common_args='-o ProxyCommand="ssh -W [%h]:%p -q -i /keys/id -p 22 ops@bastion.example.com"'`
+	if !strings.Contains(string(content), want) {
+		t.Errorf("inventory missing %q:\n%s", want, content)
+	}
+}
+
+func TestGenerateUseAgent(t *testing.T) {
+	ig := &InventoryGenerator{outputDir: t.TempDir()}
+	server := models.Server{
+		Name: "agent",
+		IP:   "203.0.113.10",
+		SSH:  models.SSHConfig{User: "root", Port: 22, UseAgent: true},
+	}
+
+	path, err := ig.Generate(server, "test", nil)
+	if err != nil {
+		t.Fatalf("Generate() error = %v", err)
+	}
+	content, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("failed to read inventory: %v", err)
+	}
+
+	if strings.Contains(string(content), "ansible_ssh_private_key_file") {
+		t.Errorf("inventory should not set a key file when using ssh-agent:\n%s", content)
+	}
+}
+
+func TestGenerateHostnameOnly(t *testing.T) {
+	ig := &InventoryGenerator{outputDir: t.TempDir()}
+	server := models.Server{
+		Name:     "dns",
+		Hostname: "web1.example.com",
+		SSH:      models.SSHConfig{User: "root", Port: 22, KeyFile: "/keys/id"},
+	}
+
+	path, err := ig.Generate(server, "test", nil)
+	if err != nil {
+		t.Fatalf("Generate() error = %v", err)
+	}
+	content, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("failed to read inventory: %v", err)
+	}
+
+	if !strings.Contains(string(content), "[webservers]\nweb1.example.com\n") {
+		t.Errorf("inventory should target the hostname when no IP is set:\n%s", content)
+	}
+}
+
+func TestGenerateIPv6Literal(t *testing.T) {
+	ig := &InventoryGenerator{outputDir: t.TempDir()}
+	server := models.Server{
+		Name:     "v6",
+		Hostname: "2001:db8::10",
+		IP:       "2001:db8::10",
+		SSH:      models.SSHConfig{User: "root", Port: 2222, KeyFile: "/keys/id"},
+	}
+
+	path, err := ig.Generate(server, "provision", nil)
+	if err != nil {
+		t.Fatalf("Generate() error = %v", err)
+	}
+	content, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("failed to read inventory: %v", err)
+	}
+
+	// Ansible parses a bare IPv6 host; the port must not be appended to it
+	for _, want := range []string{"[webservers]\n2001:db8::10\n", "ansible_port=2222\n"} {
+		if !strings.Contains(string(content), want) {
+			t.Errorf("inventory missing %q:\n%s", want, content)
+		}
+	}
+}
+
+func TestGenerateCustomTemplate(t *testing.T) {
+	dir := t.TempDir()
+	templatePath := filepath.Join(dir, InventoryTemplateFile)
+	custom := "[webservers]\n{{ .Server.Address }} ansible_become_method=doas\n{{ range $k, $v := .GlobalVars }}{{ $k }}={{ $v }}\n{{ end }}"
+	if err := os.WriteFile(templatePath, []byte(custom), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	ig := &InventoryGenerator{outputDir: dir, templatePath: templatePath}
+	server := models.Server{Name: "web1", IP: "203.0.113.10", SSH: models.SSHConfig{User: "root", Port: 22}}
+	path, err := ig.Generate(server, "provision", map[string]interface{}{"certbot_email": "a@example.com"})
+	if err != nil {
+		t.Fatalf("Generate() error = %v", err)
+	}
+	content, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "[webservers]\n203.0.113.10 ansible_become_method=doas\ncertbot_email=a@example.com\n"
+	if string(content) != want {
+		t.Errorf("inventory = %q, want %q", content, want)
+	}
+}
+
+func TestGenerateInvalidCustomTemplate(t *testing.T) {
+	tests := []struct {
+		name     string
+		template string
+		wantErr  string
+	}{
+		{"does not parse", "{{ .Server.Name ", "invalid inventory template"},
+		{"unknown field", "{{ .Server.Nope }}", "failed to execute inventory template"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			templatePath := filepath.Join(dir, InventoryTemplateFile)
+			if err := os.WriteFile(templatePath, []byte(tt.template), 0644); err != nil {
+				t.Fatal(err)
+			}
+
+			outputDir := t.TempDir()
+			ig := &InventoryGenerator{outputDir: outputDir, templatePath: templatePath}
+			_, err := ig.Generate(models.Server{Name: "web1", IP: "203.0.113.10"}, "provision", nil)
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("Generate() error = %v, want %q", err, tt.wantErr)
+			}
+			if entries, _ := os.ReadDir(outputDir); len(entries) != 0 {
+				t.Errorf("a failed template left %d file(s) behind", len(entries))
+			}
+		})
+	}
+}
+
+func TestGenerateMissingCustomTemplateUsesEmbedded(t *testing.T) {
+	ig := &InventoryGenerator{outputDir: t.TempDir(), templatePath: filepath.Join(t.TempDir(), InventoryTemplateFile)}
+	path, err := ig.Generate(models.Server{Name: "web1", IP: "203.0.113.10", SSH: models.SSHConfig{User: "root", Port: 22}}, "provision", nil)
+	if err != nil {
+		t.Fatalf("Generate() error = %v", err)
+	}
+	content, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(string(content), "# Auto-generated by wordsail") {
+		t.Errorf("inventory should come from the embedded template:\n%s", content)
+	}
+}
+
+func TestGeneratePythonInterpreter(t *testing.T) {
+	tests := []struct {
+		name       string
+		configured string
+		server     string
+		want       string
+	}{
+		{name: "default", want: DefaultPythonInterpreter},
+		{name: "from config", configured: "/usr/bin/python3.11", want: "/usr/bin/python3.11"},
+		{name: "server override", configured: "/usr/bin/python3.11", server: "/opt/venv/bin/python", want: "/opt/venv/bin/python"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ig := &InventoryGenerator{outputDir: t.TempDir()}
+			ig.SetPythonInterpreter(tt.configured)
+			server := models.Server{
+				Name:              "web1",
+				IP:                "203.0.113.10",
+				SSH:               models.SSHConfig{User: "root", Port: 22, KeyFile: "/keys/id"},
+				PythonInterpreter: tt.server,
+			}
+
+			path, err := ig.Generate(server, "provision", nil)
+			if err != nil {
+				t.Fatalf("Generate() error = %v", err)
+			}
+			content, err := os.ReadFile(path)
+			if err != nil {
+				t.Fatalf("failed to read inventory: %v", err)
+			}
+			if want := "ansible_python_interpreter=" + tt.want + "\n"; !strings.Contains(string(content), want) {
+				t.Errorf("inventory missing %q:\n%s", want, content)
+			}
+		})
+	}
+}
+
+func TestGeneratePrivateDir(t *testing.T) {
+	tmp := t.TempDir()
+	t.Setenv("TMPDIR", tmp)
+
+	ig := &InventoryGenerator{}
+	path, err := ig.Generate(models.Server{Name: "web1", IP: "203.0.113.10", SSH: models.SSHConfig{User: "root", Port: 22}}, "provision", nil)
+	if err != nil {
+		t.Fatalf("Generate() error = %v", err)
+	}
+
+	dir := filepath.Dir(path)
+	if filepath.Dir(dir) != tmp {
+		t.Fatalf("inventory %s should be in a directory of its own under %s", path, tmp)
+	}
+	if matched, _ := filepath.Match(InventoryDirPattern, filepath.Base(dir)); !matched {
+		t.Errorf("directory %s does not match %s", dir, InventoryDirPattern)
+	}
+	if matched, _ := filepath.Match(InventoryFilePattern, filepath.Base(path)); !matched {
+		t.Errorf("file %s does not match %s", path, InventoryFilePattern)
+	}
+	for p, want := range map[string]os.FileMode{dir: 0700, path: 0600} {
+		info, err := os.Stat(p)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if perm := info.Mode().Perm(); perm != want {
+			t.Errorf("%s mode = %o, want %o", p, perm, want)
+		}
+	}
+
+	if err := ig.Cleanup(path); err != nil {
+		t.Fatalf("Cleanup() error = %v", err)
+	}
+	if _, err := os.Stat(dir); !os.IsNotExist(err) {
+		t.Errorf("Cleanup() should remove %s", dir)
+	}
+}
+
+func TestCleanupKeepsOutputDir(t *testing.T) {
+	outputDir := t.TempDir()
+	ig := &InventoryGenerator{outputDir: outputDir}
+	path, err := ig.Generate(models.Server{Name: "web1", IP: "203.0.113.10", SSH: models.SSHConfig{User: "root", Port: 22}}, "provision", nil)
+	if err != nil {
+		t.Fatalf("Generate() error = %v", err)
+	}
+	if filepath.Dir(path) != outputDir {
+		t.Errorf("inventory %s should be written to %s", path, outputDir)
+	}
+	if err := ig.Cleanup(path); err != nil {
+		t.Fatalf("Cleanup() error = %v", err)
+	}
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Errorf("Cleanup() should remove %s", path)
+	}
+	if _, err := os.Stat(outputDir); err != nil {
+		t.Errorf("Cleanup() should keep the configured output dir: %v", err)
+	}
+}
@@ -0,0 +1,38 @@
+package ansible
+
+import (
+	"regexp"
+	"time"
+)
+
+// Retry backoff bounds for transient connection failures
+const (
+	retryBaseDelay = 5 * time.Second
+	retryMaxDelay  = 60 * time.Second
+)
+
+// connectionErrorPattern matches SSH/network failures that are worth retrying,
+// e.g. a freshly-booted VM that is not yet accepting SSH connections
+var connectionErrorPattern = regexp.MustCompile(`(?i)(UNREACHABLE!|connection refused|connection timed out|operation timed out|i/o timeout|no route to host|connection reset by peer|connection closed by|kex_exchange_identification|timed out waiting for connection)`)
+
+// IsConnectionError reports whether the output contains a transient connection failure
+func IsConnectionError(lines []string) bool {
+	for _, line := range lines {
+		if connectionErrorPattern.MatchString(line) {
+			return true
+		}
+	}
+	return false
+}
+
+// RetryDelay returns the exponential backoff delay before retry number attempt (0-based)
+func RetryDelay(attempt int) time.Duration {
+	delay := retryBaseDelay
+	for i := 0; i < attempt; i++ {
+		delay *= 2
+		if delay >= retryMaxDelay {
+			return retryMaxDelay
+		}
+	}
+	return delay
+}
@@ -0,0 +1,52 @@
+package ansible
+
+import (
+	"testing"
+	"time"
+)
+
+func TestIsConnectionError(t *testing.T) {
+	tests := []struct {
+		name  string
+		lines []string
+		want  bool
+	}{
+		{"unreachable host", []string{`fatal: [1.2.3.4]: UNREACHABLE! => {"changed": false, "unreachable": true}`}, true},
+		{"connection refused", []string{"ssh: connect to host 1.2.3.4 port 22: Connection refused"}, true},
+		{"connection timed out", []string{"ssh: connect to host 1.2.3.4 port 22: Connection timed out"}, true},
+		{"go dial timeout", []string{"failed to connect: dial tcp 1.2.3.4:22: i/o timeout"}, true},
+		{"no route", []string{"connect to host 1.2.3.4 port 22: No route to host"}, true},
+		{"kex reset", []string{"kex_exchange_identification: read: Connection reset by peer"}, true},
+		{"task failure", []string{`fatal: [1.2.3.4]: FAILED! => {"msg": "No package matching 'foo'"}`}, false},
+		{"auth failure", []string{"Permission denied (publickey)."}, false},
+		{"empty", nil, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := IsConnectionError(tt.lines); got != tt.want {
+				t.Errorf("IsConnectionError() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRetryDelay(t *testing.T) {
+	tests := []struct {
+		attempt int
+		want    time.Duration
+	}{
+		{0, 5 * time.Second},
+		{1, 10 * time.Second},
+		{2, 20 * time.Second},
+		{3, 40 * time.Second},
+		{4, 60 * time.Second},
+		{10, 60 * time.Second},
+	}
+
+	for _, tt := range tests {
+		if got := RetryDelay(tt.attempt); got != tt.want {
+			t.Errorf("RetryDelay(%d) = %v, want %v", tt.attempt, got, tt.want)
+		}
+	}
+}
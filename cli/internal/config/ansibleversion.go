@@ -0,0 +1,66 @@
+package config
+
+import (
+	"fmt"
+	"os/exec"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// MinAnsibleVersion is the oldest ansible-core release the playbooks support
+const MinAnsibleVersion = "2.12"
+
+var ansibleVersionPattern = regexp.MustCompile(`(\d+)\.(\d+)(?:\.(\d+))?`)
+
+// AnsibleVersion runs `ansible-playbook --version` and returns the detected
+// ansible-core version
+func AnsibleVersion() (string, error) {
+	out, err := exec.Command("ansible-playbook", "--version").CombinedOutput()
+	if err != nil {
+		return "", fmt.Errorf("failed to run ansible-playbook --version: %w", err)
+	}
+	return ParseAnsibleVersion(string(out))
+}
+
+// ParseAnsibleVersion extracts the version from `ansible-playbook --version`
+// output. Modern releases print "ansible-playbook [core 2.15.3]", older ones
+// "ansible-playbook 2.9.27".
+func ParseAnsibleVersion(output string) (string, error) {
+	firstLine := strings.TrimSpace(strings.SplitN(output, "\n", 2)[0])
+	match := ansibleVersionPattern.FindString(firstLine)
+	if match == "" {
+		return "", fmt.Errorf("could not parse Ansible version from %q", firstLine)
+	}
+	return match, nil
+}
+
+// CheckAnsibleVersion returns an error if version is older than MinAnsibleVersion
+func CheckAnsibleVersion(version string) error {
+	if compareVersions(version, MinAnsibleVersion) < 0 {
+		return fmt.Errorf("ansible-core %s is older than the minimum supported version %s. Please upgrade Ansible", version, MinAnsibleVersion)
+	}
+	return nil
+}
+
+// compareVersions compares dotted numeric versions, treating missing parts as 0
+func compareVersions(a, b string) int {
+	pa := strings.Split(a, ".")
+	pb := strings.Split(b, ".")
+	for i := 0; i < len(pa) || i < len(pb); i++ {
+		var na, nb int
+		if i < len(pa) {
+			na, _ = strconv.Atoi(pa[i])
+		}
+		if i < len(pb) {
+			nb, _ = strconv.Atoi(pb[i])
+		}
+		if na != nb {
+			if na < nb {
+				return -1
+			}
+			return 1
+		}
+	}
+	return 0
+}
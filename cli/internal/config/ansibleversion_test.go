@@ -0,0 +1,53 @@
+package config
+
+import "testing"
+
+func TestParseAnsibleVersion(t *testing.T) {
+	tests := []struct {
+		name    string
+		output  string
+		want    string
+		wantErr bool
+	}{
+		{"core release", "ansible-playbook [core 2.15.3]\n  config file = None\n  python version = 3.11.4", "2.15.3", false},
+		{"core pre-release", "ansible-playbook [core 2.17.0rc1]\n", "2.17.0", false},
+		{"legacy release", "ansible-playbook 2.9.27\n  config file = /etc/ansible/ansible.cfg", "2.9.27", false},
+		{"major.minor only", "ansible-playbook 2.12\n", "2.12", false},
+		{"version only on a later line", "ansible-playbook\n  python version = 3.11.4", "", true},
+		{"empty", "", "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseAnsibleVersion(tt.output)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseAnsibleVersion() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("ParseAnsibleVersion() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCheckAnsibleVersion(t *testing.T) {
+	tests := []struct {
+		version string
+		wantErr bool
+	}{
+		{"2.9.27", true},
+		{"2.11.12", true},
+		{"2.12", false},
+		{"2.12.0", false},
+		{"2.15.3", false},
+		{"3.0", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.version, func(t *testing.T) {
+			if err := CheckAnsibleVersion(tt.version); (err != nil) != tt.wantErr {
+				t.Errorf("CheckAnsibleVersion(%q) error = %v, wantErr %v", tt.version, err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,163 @@
+package config
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// BackupKeepVar is the global var holding how many local config backups to keep
+const BackupKeepVar = "config_backup_keep"
+
+// DefaultBackupKeep is the number of local config backups kept when
+// config_backup_keep is not set
+const DefaultBackupKeep = 10
+
+const backupTimeFormat = "20060102-150405.000000000"
+
+// ConfigBackup is a copy of the config file taken before it was overwritten
+type ConfigBackup struct {
+	Name      string    `json:"name"`
+	Path      string    `json:"path"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// BackupDir returns the directory local config backups are written to
+func (m *Manager) BackupDir() string {
+	return filepath.Join(m.GetConfigDir(), "backups")
+}
+
+// BackupKeep returns how many local config backups to keep. Zero or less
+// disables them.
+func BackupKeep(cfg *Config) int {
+	if cfg == nil {
+		return DefaultBackupKeep
+	}
+	switch v := cfg.GlobalVars[BackupKeepVar].(type) {
+	case int:
+		return v
+	case string:
+		if n, err := strconv.Atoi(strings.TrimSpace(v)); err == nil {
+			return n
+		}
+	}
+	return DefaultBackupKeep
+}
+
+// backupPrefix is the config file name without its extension, e.g. "wordsail"
+func (m *Manager) backupPrefix() string {
+	base := filepath.Base(m.configPath)
+	return strings.TrimSuffix(base, filepath.Ext(base)) + "-"
+}
+
+// backupCurrent copies the config file into the backup directory before it is
+// replaced by data, then prunes old backups. Nothing is copied when there is
+// no config file yet or it already holds data.
+func (m *Manager) backupCurrent(data []byte, keep int) error {
+	if keep <= 0 {
+		return nil
+	}
+
+	current, err := os.ReadFile(m.configPath)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read config file for backup: %w", err)
+	}
+	if bytes.Equal(current, data) {
+		return nil
+	}
+
+	if err := os.MkdirAll(m.BackupDir(), 0700); err != nil {
+		return fmt.Errorf("failed to create backup directory: %w", err)
+	}
+
+	name := m.backupPrefix() + time.Now().UTC().Format(backupTimeFormat) + ".yaml"
+	if err := os.WriteFile(filepath.Join(m.BackupDir(), name), current, 0600); err != nil {
+		return fmt.Errorf("failed to write config backup: %w", err)
+	}
+
+	return m.pruneBackups(keep)
+}
+
+// ListBackups returns the local config backups, newest first
+func (m *Manager) ListBackups() ([]ConfigBackup, error) {
+	entries, err := os.ReadDir(m.BackupDir())
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read backup directory: %w", err)
+	}
+
+	prefix := m.backupPrefix()
+	var backups []ConfigBackup
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || !strings.HasPrefix(name, prefix) || !strings.HasSuffix(name, ".yaml") {
+			continue
+		}
+		stamp := strings.TrimSuffix(strings.TrimPrefix(name, prefix), ".yaml")
+		createdAt, err := time.Parse(backupTimeFormat, stamp)
+		if err != nil {
+			continue
+		}
+		backups = append(backups, ConfigBackup{
+			Name:      name,
+			Path:      filepath.Join(m.BackupDir(), name),
+			CreatedAt: createdAt,
+		})
+	}
+
+	sort.Slice(backups, func(i, j int) bool {
+		return backups[i].CreatedAt.After(backups[j].CreatedAt)
+	})
+	return backups, nil
+}
+
+// pruneBackups removes all but the newest keep backups
+func (m *Manager) pruneBackups(keep int) error {
+	backups, err := m.ListBackups()
+	if err != nil {
+		return err
+	}
+	for i := keep; i < len(backups); i++ {
+		if err := os.Remove(backups[i].Path); err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("failed to remove old config backup: %w", err)
+		}
+	}
+	return nil
+}
+
+// BackupPath returns the file of a backup given by name (in the backup
+// directory) or by path
+func (m *Manager) BackupPath(backup string) string {
+	if !strings.ContainsRune(backup, filepath.Separator) {
+		return filepath.Join(m.BackupDir(), backup)
+	}
+	return backup
+}
+
+// Restore replaces the config file with a backup, given by name or path. The
+// current config is backed up first, so a restore can itself be undone.
+func (m *Manager) Restore(backup string) error {
+	data, err := os.ReadFile(m.BackupPath(backup))
+	if err != nil {
+		return fmt.Errorf("failed to read backup: %w", err)
+	}
+
+	var restored Config
+	if err := yaml.Unmarshal(data, &restored); err != nil {
+		return fmt.Errorf("backup is not a valid config file: %w", err)
+	}
+
+	return m.write(data, BackupKeep(&restored))
+}
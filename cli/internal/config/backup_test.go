@@ -0,0 +1,125 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"gopkg.in/yaml.v3"
+)
+
+func TestSaveCreatesBackup(t *testing.T) {
+	mgr := NewManagerWithPath(filepath.Join(t.TempDir(), "wordsail.yaml"))
+
+	cfg := &Config{Version: CurrentVersion, GlobalVars: map[string]interface{}{"certbot_email": "a@example.com"}}
+	if err := mgr.Save(cfg); err != nil {
+		t.Fatal(err)
+	}
+	backups, err := mgr.ListBackups()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(backups) != 0 {
+		t.Fatalf("first save created %d backup(s), want 0", len(backups))
+	}
+	original, err := os.ReadFile(mgr.GetConfigPath())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Saving unchanged content is not worth a backup
+	if err := mgr.Save(cfg); err != nil {
+		t.Fatal(err)
+	}
+	if backups, _ := mgr.ListBackups(); len(backups) != 0 {
+		t.Fatalf("unchanged save created %d backup(s), want 0", len(backups))
+	}
+
+	cfg.GlobalVars["certbot_email"] = "b@example.com"
+	if err := mgr.Save(cfg); err != nil {
+		t.Fatal(err)
+	}
+	backups, err = mgr.ListBackups()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(backups) != 1 {
+		t.Fatalf("got %d backup(s), want 1", len(backups))
+	}
+	data, err := os.ReadFile(backups[0].Path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != string(original) {
+		t.Errorf("backup holds %q, want the previous config %q", data, original)
+	}
+
+	if err := mgr.Restore(backups[0].Name); err != nil {
+		t.Fatalf("Restore() error = %v", err)
+	}
+	restored, err := mgr.Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := restored.GlobalVars["certbot_email"]; got != "a@example.com" {
+		t.Errorf("after restore certbot_email = %v, want a@example.com", got)
+	}
+	if backups, _ := mgr.ListBackups(); len(backups) != 2 {
+		t.Errorf("restore should back up the config it replaces, got %d backup(s)", len(backups))
+	}
+}
+
+func TestSavePrunesBackups(t *testing.T) {
+	mgr := NewManagerWithPath(filepath.Join(t.TempDir(), "wordsail.yaml"))
+
+	cfg := &Config{Version: CurrentVersion, GlobalVars: map[string]interface{}{BackupKeepVar: 3}}
+	for i := 0; i < 8; i++ {
+		cfg.GlobalVars["counter"] = i
+		if err := mgr.Save(cfg); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	backups, err := mgr.ListBackups()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(backups) != 3 {
+		t.Fatalf("got %d backup(s), want 3", len(backups))
+	}
+
+	// The newest backup holds the config from just before the last save
+	newest, err := os.ReadFile(backups[0].Path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var prev Config
+	if err := yaml.Unmarshal(newest, &prev); err != nil {
+		t.Fatal(err)
+	}
+	if got := prev.GlobalVars["counter"]; got != 6 {
+		t.Errorf("newest backup counter = %v, want 6", got)
+	}
+}
+
+func TestBackupKeep(t *testing.T) {
+	tests := []struct {
+		name string
+		vars map[string]interface{}
+		want int
+	}{
+		{"unset", nil, DefaultBackupKeep},
+		{"int", map[string]interface{}{BackupKeepVar: 5}, 5},
+		{"string", map[string]interface{}{BackupKeepVar: "7"}, 7},
+		{"disabled", map[string]interface{}{BackupKeepVar: 0}, 0},
+		{"invalid", map[string]interface{}{BackupKeepVar: "lots"}, DefaultBackupKeep},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := BackupKeep(&Config{GlobalVars: tt.vars}); got != tt.want {
+				t.Errorf("BackupKeep() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
@@ -41,7 +41,7 @@ func DefaultConfig() *Config {
 	}
 
 	return &Config{
-		Version: "1.0",
+		Version: CurrentVersion,
 		Ansible: AnsibleConfig{
 			Path:              ansiblePath,
 			RolesPath:         "./roles",
@@ -58,3 +58,16 @@ func DefaultConfig() *Config {
 		},
 	}
 }
+
+// SSHPrivateKeyVar is the global var holding the private half of a key
+// generated by `wordsail init --generate-key`
+const SSHPrivateKeyVar = "wordsail_ssh_private_key"
+
+// SSHPrivateKeyPath returns the generated private key path, if any
+func SSHPrivateKeyPath(cfg *Config) string {
+	if cfg == nil {
+		return ""
+	}
+	path, _ := cfg.GlobalVars[SSHPrivateKeyVar].(string)
+	return path
+}
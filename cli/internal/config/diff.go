@@ -0,0 +1,227 @@
+package config
+
+import (
+	"fmt"
+	"reflect"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/wordsail/cli/pkg/models"
+)
+
+// Kinds of ConfigChange
+const (
+	ChangeAdded   = "added"
+	ChangeRemoved = "removed"
+	ChangeChanged = "changed"
+)
+
+// secretValue replaces the values of password fields in a diff
+const secretValue = "(secret)"
+
+// ConfigChange is one difference between two configs. Server, SiteID and
+// Domain say what changed; Field is the YAML path of a changed setting
+// within it (empty for an added or removed object).
+type ConfigChange struct {
+	Kind   string `json:"kind"`
+	Server string `json:"server,omitempty"`
+	SiteID string `json:"site_id,omitempty"`
+	Domain string `json:"domain,omitempty"`
+	Field  string `json:"field,omitempty"`
+	From   string `json:"from,omitempty"`
+	To     string `json:"to,omitempty"`
+}
+
+// Diff compares two configs setting by setting. Servers are matched by
+// name, sites by site ID and domains by name, so a reordered list is not a
+// change; a renamed object shows as removed and added.
+func Diff(from, to *Config) []ConfigChange {
+	changes := []ConfigChange{}
+	emit := func(base ConfigChange) func(field, a, b string) {
+		return func(field, a, b string) {
+			c := base
+			c.Kind, c.Field, c.From, c.To = ChangeChanged, field, a, b
+			changes = append(changes, c)
+		}
+	}
+
+	diffValues("", reflect.ValueOf(*from), reflect.ValueOf(*to), []string{"servers"}, emit(ConfigChange{}))
+
+	for _, pair := range matchByKey(from.Servers, to.Servers, func(s models.Server) string { return s.Name }) {
+		base := ConfigChange{Server: pair.key}
+		switch {
+		case pair.from == nil:
+			changes = append(changes, added(base))
+			continue
+		case pair.to == nil:
+			changes = append(changes, removed(base))
+			continue
+		}
+		diffValues("", reflect.ValueOf(*pair.from), reflect.ValueOf(*pair.to), []string{"name", "sites"}, emit(base))
+
+		for _, sitePair := range matchByKey(pair.from.Sites, pair.to.Sites, func(s models.Site) string { return s.SiteID }) {
+			base := ConfigChange{Server: pair.key, SiteID: sitePair.key}
+			switch {
+			case sitePair.from == nil:
+				changes = append(changes, added(base))
+				continue
+			case sitePair.to == nil:
+				changes = append(changes, removed(base))
+				continue
+			}
+			diffValues("", reflect.ValueOf(*sitePair.from), reflect.ValueOf(*sitePair.to), []string{"site_id", "domains"}, emit(base))
+
+			for _, domainPair := range matchByKey(sitePair.from.Domains, sitePair.to.Domains, func(d models.Domain) string { return d.Domain }) {
+				base := ConfigChange{Server: pair.key, SiteID: sitePair.key, Domain: domainPair.key}
+				switch {
+				case domainPair.from == nil:
+					changes = append(changes, added(base))
+				case domainPair.to == nil:
+					changes = append(changes, removed(base))
+				default:
+					diffValues("", reflect.ValueOf(*domainPair.from), reflect.ValueOf(*domainPair.to), []string{"domain"}, emit(base))
+				}
+			}
+		}
+	}
+	return changes
+}
+
+func added(c ConfigChange) ConfigChange {
+	c.Kind = ChangeAdded
+	return c
+}
+
+func removed(c ConfigChange) ConfigChange {
+	c.Kind = ChangeRemoved
+	return c
+}
+
+// keyedPair holds the entries with the same key in two lists; either side
+// is nil when the entry exists in only one of them
+type keyedPair[T any] struct {
+	key      string
+	from, to *T
+}
+
+// matchByKey pairs the entries of two lists by key, in the order of to
+// followed by the entries only in from
+func matchByKey[T any](from, to []T, key func(T) string) []keyedPair[T] {
+	fromByKey := make(map[string]*T, len(from))
+	for i := range from {
+		fromByKey[key(from[i])] = &from[i]
+	}
+
+	pairs := make([]keyedPair[T], 0, len(to))
+	seen := make(map[string]bool, len(to))
+	for i := range to {
+		k := key(to[i])
+		seen[k] = true
+		pairs = append(pairs, keyedPair[T]{key: k, from: fromByKey[k], to: &to[i]})
+	}
+	for i := range from {
+		if k := key(from[i]); !seen[k] {
+			pairs = append(pairs, keyedPair[T]{key: k, from: &from[i]})
+		}
+	}
+	return pairs
+}
+
+// diffValues compares two values of the same type field by field and
+// reports each differing setting by its YAML path under path. Fields of a
+// struct named in skip are left to the caller.
+func diffValues(path string, a, b reflect.Value, skip []string, emit func(field, from, to string)) {
+	switch {
+	case a.Kind() == reflect.Struct && a.Type() != reflect.TypeOf(time.Time{}):
+		for i := 0; i < a.NumField(); i++ {
+			field := a.Type().Field(i)
+			name := yamlName(field)
+			if !field.IsExported() || name == "-" || slices.Contains(skip, name) {
+				continue
+			}
+			diffValues(joinPath(path, name), a.Field(i), b.Field(i), nil, emit)
+		}
+	case a.Kind() == reflect.Map:
+		keys := map[string]reflect.Value{}
+		for _, m := range []reflect.Value{a, b} {
+			for _, k := range m.MapKeys() {
+				keys[fmt.Sprint(k.Interface())] = k
+			}
+		}
+		names := make([]string, 0, len(keys))
+		for name := range keys {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			from, to := formatValue(a.MapIndex(keys[name])), formatValue(b.MapIndex(keys[name]))
+			if from != to {
+				emitSetting(joinPath(path, name), from, to, emit)
+			}
+		}
+	default:
+		if reflect.DeepEqual(a.Interface(), b.Interface()) {
+			return
+		}
+		emitSetting(path, formatValue(a), formatValue(b), emit)
+	}
+}
+
+// emitSetting reports a changed setting, hiding the values of passwords
+func emitSetting(path, from, to string, emit func(field, from, to string)) {
+	if strings.Contains(path, "password") {
+		from, to = secretValue, secretValue
+	}
+	emit(path, from, to)
+}
+
+// joinPath appends a YAML key to a dotted path
+func joinPath(path, name string) string {
+	if path == "" {
+		return name
+	}
+	return path + "." + name
+}
+
+// yamlName returns the YAML key of a struct field
+func yamlName(field reflect.StructField) string {
+	name, _, _ := strings.Cut(field.Tag.Get("yaml"), ",")
+	if name == "" {
+		return strings.ToLower(field.Name)
+	}
+	return name
+}
+
+// formatValue renders a setting for display. Unset values (nil pointers,
+// missing map keys) are empty; lists of records are summarized by length.
+func formatValue(v reflect.Value) string {
+	if !v.IsValid() {
+		return ""
+	}
+	for v.Kind() == reflect.Pointer || v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			return ""
+		}
+		v = v.Elem()
+	}
+	switch value := v.Interface().(type) {
+	case time.Time:
+		if value.IsZero() {
+			return ""
+		}
+		return value.Format(time.RFC3339)
+	}
+	if v.Kind() == reflect.Slice {
+		if v.Type().Elem().Kind() == reflect.Struct {
+			return fmt.Sprintf("%d entries", v.Len())
+		}
+		items := make([]string, v.Len())
+		for i := range items {
+			items[i] = formatValue(v.Index(i))
+		}
+		return strings.Join(items, ", ")
+	}
+	return fmt.Sprint(v.Interface())
+}
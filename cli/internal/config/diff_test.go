@@ -0,0 +1,76 @@
+package config
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/wordsail/cli/pkg/models"
+)
+
+func TestDiff(t *testing.T) {
+	expires := time.Date(2026, 12, 1, 0, 0, 0, 0, time.UTC)
+
+	from := &Config{
+		Version:    "1.0",
+		GlobalVars: map[string]interface{}{"certbot_email": "old@example.com", "db_password": "a"},
+		Servers: []models.Server{
+			{Name: "old", Status: "provisioned"},
+			{
+				Name:   "web1",
+				Status: "unprovisioned",
+				SSH:    models.SSHConfig{User: "root", Port: 22},
+				Sites: []models.Site{
+					{SiteID: "gone", PrimaryDomain: "gone.com"},
+					{SiteID: "blog", PrimaryDomain: "blog.com", PHPVersion: "8.2", Domains: []models.Domain{
+						{Domain: "blog.com"},
+						{Domain: "old.blog.com"},
+					}},
+				},
+			},
+		},
+	}
+	to := &Config{
+		Version:    "1.0",
+		GlobalVars: map[string]interface{}{"certbot_email": "new@example.com", "db_password": "b", "config_backup_keep": 5},
+		Servers: []models.Server{
+			{
+				Name:   "web1",
+				Status: "provisioned",
+				SSH:    models.SSHConfig{User: "root", Port: 2222},
+				Sites: []models.Site{
+					{SiteID: "blog", PrimaryDomain: "blog.com", PHPVersion: "8.3", Domains: []models.Domain{
+						{Domain: "blog.com", SSLEnabled: true, SSLExpiresAt: &expires},
+						{Domain: "www.blog.com"},
+					}},
+					{SiteID: "shop", PrimaryDomain: "shop.com"},
+				},
+			},
+			{Name: "web2", Status: "unprovisioned"},
+		},
+	}
+
+	want := []ConfigChange{
+		{Kind: ChangeChanged, Field: "global_vars.certbot_email", From: "old@example.com", To: "new@example.com"},
+		{Kind: ChangeChanged, Field: "global_vars.config_backup_keep", To: "5"},
+		{Kind: ChangeChanged, Field: "global_vars.db_password", From: secretValue, To: secretValue},
+		{Kind: ChangeChanged, Server: "web1", Field: "ssh.port", From: "22", To: "2222"},
+		{Kind: ChangeChanged, Server: "web1", Field: "status", From: "unprovisioned", To: "provisioned"},
+		{Kind: ChangeChanged, Server: "web1", SiteID: "blog", Field: "php_version", From: "8.2", To: "8.3"},
+		{Kind: ChangeChanged, Server: "web1", SiteID: "blog", Domain: "blog.com", Field: "ssl_enabled", From: "false", To: "true"},
+		{Kind: ChangeChanged, Server: "web1", SiteID: "blog", Domain: "blog.com", Field: "ssl_expires_at", To: "2026-12-01T00:00:00Z"},
+		{Kind: ChangeAdded, Server: "web1", SiteID: "blog", Domain: "www.blog.com"},
+		{Kind: ChangeRemoved, Server: "web1", SiteID: "blog", Domain: "old.blog.com"},
+		{Kind: ChangeAdded, Server: "web1", SiteID: "shop"},
+		{Kind: ChangeRemoved, Server: "web1", SiteID: "gone"},
+		{Kind: ChangeAdded, Server: "web2"},
+		{Kind: ChangeRemoved, Server: "old"},
+	}
+	if got := Diff(from, to); !reflect.DeepEqual(got, want) {
+		t.Errorf("Diff() =\n%+v\nwant\n%+v", got, want)
+	}
+
+	if got := Diff(to, to); len(got) != 0 {
+		t.Errorf("Diff() of identical configs = %+v, want none", got)
+	}
+}
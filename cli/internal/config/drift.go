@@ -0,0 +1,105 @@
+package config
+
+import (
+	"fmt"
+
+	"github.com/wordsail/cli/pkg/models"
+)
+
+// Drift is an inconsistency left in the config, typically by a failed operation
+type Drift struct {
+	Server  string `json:"server"`
+	SiteID  string `json:"site_id,omitempty"`
+	Domain  string `json:"domain,omitempty"`
+	Problem string `json:"problem"`
+
+	// Fix describes the repair FixDrift made; it is empty when the problem
+	// needs attention on the server rather than in the config
+	Fix string `json:"fix,omitempty"`
+
+	// Hint suggests how to resolve drift that FixDrift cannot repair
+	Hint string `json:"hint,omitempty"`
+}
+
+// FixDrift repairs the drift it can in place and returns everything it
+// found, including problems it could only report
+func FixDrift(cfg *Config) []Drift {
+	drift := []Drift{}
+	for i := range cfg.Servers {
+		server := &cfg.Servers[i]
+
+		if server.Status == "error" {
+			drift = append(drift, Drift{
+				Server:  server.Name,
+				Problem: "server is in the error state",
+				Hint:    fmt.Sprintf("re-run 'wordsail server provision %s'", server.Name),
+			})
+		}
+
+		for j := range server.Sites {
+			site := &server.Sites[j]
+
+			if site.PrimaryDomain != "" && !hasDomain(site.Domains, site.PrimaryDomain) {
+				site.Domains = append([]models.Domain{{Domain: site.PrimaryDomain}}, site.Domains...)
+				drift = append(drift, Drift{
+					Server:  server.Name,
+					SiteID:  site.SiteID,
+					Domain:  site.PrimaryDomain,
+					Problem: "primary domain is missing from the domain list",
+					Fix:     "added it to the domain list",
+				})
+			}
+
+			for k := range site.Domains {
+				d := &site.Domains[k]
+				switch {
+				case !d.SSLEnabled && (d.SSLIssuedAt != nil || d.SSLExpiresAt != nil):
+					d.SSLIssuedAt = nil
+					d.SSLExpiresAt = nil
+					drift = append(drift, Drift{
+						Server:  server.Name,
+						SiteID:  site.SiteID,
+						Domain:  d.Domain,
+						Problem: "SSL is disabled but certificate dates are recorded",
+						Fix:     "cleared the certificate dates",
+					})
+				case d.SSLEnabled && d.SSLExpiresAt == nil:
+					drift = append(drift, Drift{
+						Server:  server.Name,
+						SiteID:  site.SiteID,
+						Domain:  d.Domain,
+						Problem: "SSL is enabled but no certificate expiry is recorded",
+						Hint:    "re-run 'wordsail domain ssl' for this domain",
+					})
+				}
+			}
+		}
+	}
+	return drift
+}
+
+// Fixed reports whether FixDrift changed the config for this entry
+func (d Drift) Fixed() bool {
+	return d.Fix != ""
+}
+
+// String formats the drift for display
+func (d Drift) String() string {
+	where := fmt.Sprintf("server '%s'", d.Server)
+	if d.SiteID != "" {
+		where = fmt.Sprintf("site '%s' on %s", d.SiteID, where)
+	}
+	if d.Domain != "" {
+		where = fmt.Sprintf("%s (%s)", where, d.Domain)
+	}
+	return fmt.Sprintf("%s: %s", where, d.Problem)
+}
+
+func hasDomain(domains []models.Domain, domain string) bool {
+	for _, d := range domains {
+		if d.Domain == domain {
+			return true
+		}
+	}
+	return false
+}
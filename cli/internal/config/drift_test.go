@@ -0,0 +1,74 @@
+package config
+
+import (
+	"testing"
+	"time"
+
+	"github.com/wordsail/cli/pkg/models"
+)
+
+func TestFixDrift(t *testing.T) {
+	issued := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	expires := issued.AddDate(0, 3, 0)
+
+	cfg := &Config{
+		Servers: []models.Server{
+			{
+				Name:   "web1",
+				Status: "error",
+				Sites: []models.Site{
+					{
+						SiteID:        "blog",
+						PrimaryDomain: "blog.example.com",
+						Domains: []models.Domain{
+							{Domain: "www.blog.example.com", SSLIssuedAt: &issued, SSLExpiresAt: &expires},
+							{Domain: "old.example.com", SSLEnabled: true},
+						},
+					},
+					{
+						SiteID:        "shop",
+						PrimaryDomain: "shop.example.com",
+						Domains: []models.Domain{
+							{Domain: "shop.example.com", SSLEnabled: true, SSLIssuedAt: &issued, SSLExpiresAt: &expires},
+						},
+					},
+				},
+			},
+		},
+	}
+
+	drift := FixDrift(cfg)
+
+	var fixed, reported int
+	for _, d := range drift {
+		if d.Fixed() {
+			fixed++
+		} else {
+			reported++
+			if d.Hint == "" {
+				t.Errorf("unfixable drift %q has no hint", d)
+			}
+		}
+	}
+	if fixed != 2 || reported != 2 {
+		t.Fatalf("got %d fixed and %d reported drift, want 2 and 2: %v", fixed, reported, drift)
+	}
+
+	blog := cfg.Servers[0].Sites[0]
+	if len(blog.Domains) != 3 || blog.Domains[0].Domain != "blog.example.com" {
+		t.Errorf("primary domain was not added first: %+v", blog.Domains)
+	}
+	www := blog.Domains[1]
+	if www.SSLIssuedAt != nil || www.SSLExpiresAt != nil {
+		t.Errorf("certificate dates were not cleared on a domain without SSL: %+v", www)
+	}
+
+	shop := cfg.Servers[0].Sites[1]
+	if len(shop.Domains) != 1 || shop.Domains[0].SSLExpiresAt == nil {
+		t.Errorf("a consistent site was changed: %+v", shop.Domains)
+	}
+
+	if again := FixDrift(cfg); len(again) != reported {
+		t.Errorf("second pass found %d drift, want only the %d unfixable entries", len(again), reported)
+	}
+}
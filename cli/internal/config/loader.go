@@ -1,6 +1,8 @@
 package config
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
 	"fmt"
 	"os"
 	"path/filepath"
@@ -11,21 +13,48 @@ import (
 const (
 	DefaultConfigDir  = ".wordsail"
 	DefaultConfigFile = "wordsail.yaml"
+
+	// ConfigPathEnv names an alternate config file
+	ConfigPathEnv = "WORDSAIL_CONFIG"
 )
 
+// pathOverride is the config file chosen on the command line
+var pathOverride string
+
+// SetPathOverride makes NewManager use path instead of the default config
+// file. An empty path clears the override.
+func SetPathOverride(path string) {
+	pathOverride = path
+}
+
 // Manager handles loading and saving configuration
 type Manager struct {
 	configPath string
 }
 
-// NewManager creates a new config manager with the default config path
+// NewManager creates a new config manager. The config file is the path set
+// with SetPathOverride (the --config flag), then $WORDSAIL_CONFIG, then the
+// active profile's file (~/.wordsail/wordsail.yaml for the default profile).
 func NewManager() (*Manager, error) {
-	homeDir, err := os.UserHomeDir()
-	if err != nil {
-		return nil, fmt.Errorf("failed to get user home directory: %w", err)
+	for _, path := range []string{pathOverride, os.Getenv(ConfigPathEnv)} {
+		if path == "" {
+			continue
+		}
+		absPath, err := filepath.Abs(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to resolve config path %s: %w", path, err)
+		}
+		return &Manager{configPath: absPath}, nil
 	}
 
-	configPath := filepath.Join(homeDir, DefaultConfigDir, DefaultConfigFile)
+	profile, err := ActiveProfile()
+	if err != nil {
+		return nil, err
+	}
+	configPath, err := ProfilePath(profile)
+	if err != nil {
+		return nil, err
+	}
 	return &Manager{configPath: configPath}, nil
 }
 
@@ -39,6 +68,12 @@ func (m *Manager) GetConfigPath() string {
 	return m.configPath
 }
 
+// TempPath returns the temporary file Save writes before renaming it over
+// the config file
+func (m *Manager) TempPath() string {
+	return m.configPath + ".tmp"
+}
+
 // GetConfigDir returns the directory containing the config file
 func (m *Manager) GetConfigDir() string {
 	return filepath.Dir(m.configPath)
@@ -50,13 +85,40 @@ func (m *Manager) ConfigExists() bool {
 	return err == nil
 }
 
-// Load reads and parses the configuration file
+// Fingerprint returns a hash of the config file contents, or "" if it cannot be read.
+// It is used to detect whether a command changed the configuration.
+func (m *Manager) Fingerprint() string {
+	data, err := os.ReadFile(m.configPath)
+	if err != nil {
+		return ""
+	}
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// MigrationBackupPath returns where the original config file is kept when it
+// is migrated from the given schema version
+func (m *Manager) MigrationBackupPath(version string) string {
+	return m.configPath + ".v" + version + ".bak"
+}
+
+// Load reads and parses the configuration file. Files written by an older
+// schema version are migrated to CurrentVersion and saved back.
 func (m *Manager) Load() (*Config, error) {
 	data, err := os.ReadFile(m.configPath)
 	if err != nil {
 		return nil, fmt.Errorf("failed to read config file: %w", err)
 	}
 
+	needsMigration, _, err := NeedsMigration(data)
+	if err != nil {
+		return nil, err
+	}
+	if needsMigration {
+		config, _, err := m.migrate(data)
+		return config, err
+	}
+
 	var config Config
 	if err := yaml.Unmarshal(data, &config); err != nil {
 		return nil, fmt.Errorf("failed to parse config file: %w", err)
@@ -65,22 +127,87 @@ func (m *Manager) Load() (*Config, error) {
 	return &config, nil
 }
 
-// Save writes the configuration to disk using atomic writes
+// Migrate upgrades the config file to CurrentVersion if it was written by an
+// older schema version. It returns the version the file was at and whether it
+// was changed.
+func (m *Manager) Migrate() (string, bool, error) {
+	data, err := os.ReadFile(m.configPath)
+	if err != nil {
+		return "", false, fmt.Errorf("failed to read config file: %w", err)
+	}
+
+	needsMigration, version, err := NeedsMigration(data)
+	if err != nil || !needsMigration {
+		return version, false, err
+	}
+
+	_, from, err := m.migrate(data)
+	if err != nil {
+		return from, false, err
+	}
+	return from, true, nil
+}
+
+// migrate runs the schema migrations on raw config data, keeps a copy of the
+// original file and saves the upgraded config
+func (m *Manager) migrate(data []byte) (*Config, string, error) {
+	doc := map[string]interface{}{}
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, "", fmt.Errorf("failed to parse config file: %w", err)
+	}
+
+	from, err := Migrate(doc)
+	if err != nil {
+		return nil, from, err
+	}
+
+	migrated, err := yaml.Marshal(doc)
+	if err != nil {
+		return nil, from, fmt.Errorf("failed to marshal migrated config: %w", err)
+	}
+
+	var config Config
+	if err := yaml.Unmarshal(migrated, &config); err != nil {
+		return nil, from, fmt.Errorf("failed to parse migrated config: %w", err)
+	}
+
+	if err := os.WriteFile(m.MigrationBackupPath(from), data, 0600); err != nil {
+		return nil, from, fmt.Errorf("failed to back up config before migration: %w", err)
+	}
+
+	if err := m.Save(&config); err != nil {
+		return nil, from, err
+	}
+
+	return &config, from, nil
+}
+
+// Save writes the configuration to disk using atomic writes. The previous
+// file is copied to the backup directory first.
 func (m *Manager) Save(config *Config) error {
+	// Marshal config to YAML
+	data, err := yaml.Marshal(config)
+	if err != nil {
+		return fmt.Errorf("failed to marshal config: %w", err)
+	}
+
+	return m.write(data, BackupKeep(config))
+}
+
+// write backs up the current config file and atomically replaces it with data
+func (m *Manager) write(data []byte, keep int) error {
 	// Ensure config directory exists
 	configDir := m.GetConfigDir()
 	if err := os.MkdirAll(configDir, 0755); err != nil {
 		return fmt.Errorf("failed to create config directory: %w", err)
 	}
 
-	// Marshal config to YAML
-	data, err := yaml.Marshal(config)
-	if err != nil {
-		return fmt.Errorf("failed to marshal config: %w", err)
+	if err := m.backupCurrent(data, keep); err != nil {
+		return err
 	}
 
 	// Write to temporary file
-	tmpPath := m.configPath + ".tmp"
+	tmpPath := m.TempPath()
 	if err := os.WriteFile(tmpPath, data, 0600); err != nil {
 		return fmt.Errorf("failed to write temp config file: %w", err)
 	}
@@ -0,0 +1,48 @@
+package config
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestNewManagerPath(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv(ProfileEnv, "")
+	t.Cleanup(func() { SetPathOverride("") })
+
+	tests := []struct {
+		name     string
+		override string
+		env      string
+		want     string
+	}{
+		{"default", "", "", filepath.Join(home, DefaultConfigDir, DefaultConfigFile)},
+		{"environment", "", "/srv/staging.yaml", "/srv/staging.yaml"},
+		{"flag wins over environment", "/srv/prod.yaml", "/srv/staging.yaml", "/srv/prod.yaml"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv(ConfigPathEnv, tt.env)
+			SetPathOverride(tt.override)
+
+			mgr, err := NewManager()
+			if err != nil {
+				t.Fatalf("NewManager() error = %v", err)
+			}
+			if got := mgr.GetConfigPath(); got != tt.want {
+				t.Errorf("GetConfigPath() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+
+	SetPathOverride("staging.yaml")
+	mgr, err := NewManager()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !filepath.IsAbs(mgr.GetConfigPath()) {
+		t.Errorf("relative --config path should be made absolute, got %q", mgr.GetConfigPath())
+	}
+}
@@ -0,0 +1,127 @@
+package config
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// CurrentVersion is the config schema version written by this build
+const CurrentVersion = "1.0"
+
+// legacyVersion is assumed for config files written before the version
+// field was set
+const legacyVersion = "0.9"
+
+// migration upgrades a decoded config document from one schema version to the next
+type migration struct {
+	from    string
+	to      string
+	migrate func(doc map[string]interface{}) error
+}
+
+// migrations are applied in order, each starting from the version the
+// previous one produced. Append new entries when the schema changes.
+var migrations = []migration{
+	{from: "0.9", to: "1.0", migrate: migrateSystemNameToSiteID},
+}
+
+// Migrate upgrades a decoded config document to CurrentVersion in place.
+// It returns the version the document started at.
+func Migrate(doc map[string]interface{}) (string, error) {
+	from := versionString(doc["version"])
+	version := from
+	for version != CurrentVersion {
+		step, ok := findMigration(version)
+		if !ok {
+			return from, fmt.Errorf("unsupported config version '%s' (this build supports up to %s)", version, CurrentVersion)
+		}
+		if err := step.migrate(doc); err != nil {
+			return from, fmt.Errorf("failed to migrate config from %s to %s: %w", step.from, step.to, err)
+		}
+		version = step.to
+		doc["version"] = version
+	}
+
+	return from, nil
+}
+
+// NeedsMigration reports whether config data was written by an older schema version
+func NeedsMigration(data []byte) (bool, string, error) {
+	var doc struct {
+		Version interface{} `yaml:"version"`
+	}
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return false, "", fmt.Errorf("failed to parse config file: %w", err)
+	}
+	version := versionString(doc.Version)
+	return version != CurrentVersion, version, nil
+}
+
+// versionString normalises a decoded version field. An unquoted "1.0" decodes
+// as a float, so whole numbers get their ".0" back.
+func versionString(v interface{}) string {
+	switch v := v.(type) {
+	case nil:
+		return legacyVersion
+	case string:
+		if v == "" {
+			return legacyVersion
+		}
+		return v
+	case int:
+		return strconv.Itoa(v) + ".0"
+	case float64:
+		s := strconv.FormatFloat(v, 'f', -1, 64)
+		if !strings.Contains(s, ".") {
+			s += ".0"
+		}
+		return s
+	default:
+		return fmt.Sprintf("%v", v)
+	}
+}
+
+func findMigration(version string) (migration, bool) {
+	for _, m := range migrations {
+		if m.from == version {
+			return m, true
+		}
+	}
+	return migration{}, false
+}
+
+// migrateSystemNameToSiteID renames the pre-1.0 "system_name" site field to "site_id"
+func migrateSystemNameToSiteID(doc map[string]interface{}) error {
+	servers, ok := doc["servers"].([]interface{})
+	if !ok {
+		return nil
+	}
+	for _, s := range servers {
+		server, ok := s.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		sites, ok := server["sites"].([]interface{})
+		if !ok {
+			continue
+		}
+		for _, st := range sites {
+			site, ok := st.(map[string]interface{})
+			if !ok {
+				continue
+			}
+			name, ok := site["system_name"]
+			if !ok {
+				continue
+			}
+			if id, ok := site["site_id"]; !ok || id == nil || id == "" {
+				site["site_id"] = name
+			}
+			delete(site, "system_name")
+		}
+	}
+	return nil
+}
@@ -0,0 +1,109 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const configV09 = `version: "0.9"
+ansible:
+  path: /opt/wordsail/ansible
+global_vars:
+  certbot_email: ops@example.com
+servers:
+  - name: web1
+    hostname: web1.example.com
+    ip: 203.0.113.10
+    ssh:
+      user: admin
+      port: 22
+      key_file: ~/.ssh/id_ed25519
+    sites:
+      - system_name: blog
+        primary_domain: blog.example.com
+        admin_user: admin
+        admin_email: admin@example.com
+`
+
+func writeConfig(t *testing.T, contents string) *Manager {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "wordsail.yaml")
+	if err := os.WriteFile(path, []byte(contents), 0600); err != nil {
+		t.Fatal(err)
+	}
+	return NewManagerWithPath(path)
+}
+
+func TestLoadMigratesV09(t *testing.T) {
+	mgr := writeConfig(t, configV09)
+
+	cfg, err := mgr.Load()
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if cfg.Version != CurrentVersion {
+		t.Errorf("Version = %q, want %q", cfg.Version, CurrentVersion)
+	}
+	if got := cfg.Servers[0].Sites[0].SiteID; got != "blog" {
+		t.Errorf("SiteID = %q, want %q", got, "blog")
+	}
+	if got := cfg.GlobalVars["certbot_email"]; got != "ops@example.com" {
+		t.Errorf("certbot_email = %v, want ops@example.com", got)
+	}
+
+	saved, err := os.ReadFile(mgr.GetConfigPath())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(saved), "system_name") {
+		t.Errorf("migrated config still contains system_name:\n%s", saved)
+	}
+	if !strings.Contains(string(saved), `version: "1.0"`) {
+		t.Errorf("migrated config was not saved at version 1.0:\n%s", saved)
+	}
+
+	backup, err := os.ReadFile(mgr.MigrationBackupPath("0.9"))
+	if err != nil {
+		t.Fatalf("expected a backup of the original config: %v", err)
+	}
+	if string(backup) != configV09 {
+		t.Error("backup does not match the original config")
+	}
+
+	from, migrated, err := mgr.Migrate()
+	if err != nil {
+		t.Fatalf("Migrate() error = %v", err)
+	}
+	if migrated || from != CurrentVersion {
+		t.Errorf("Migrate() on a current config = (%q, %v), want (%q, false)", from, migrated, CurrentVersion)
+	}
+}
+
+func TestMigrate(t *testing.T) {
+	tests := []struct {
+		name     string
+		contents string
+		wantFrom string
+		wantErr  bool
+	}{
+		{"missing version is legacy", "ansible:\n  path: /opt/ansible\n", "0.9", false},
+		{"unquoted 0.9", "version: 0.9\n", "0.9", false},
+		{"unquoted current", "version: 1.0\n", "1.0", false},
+		{"newer than supported", "version: \"2.0\"\n", "2.0", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mgr := writeConfig(t, tt.contents)
+			from, _, err := mgr.Migrate()
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Migrate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if from != tt.wantFrom {
+				t.Errorf("Migrate() from = %q, want %q", from, tt.wantFrom)
+			}
+		})
+	}
+}
@@ -0,0 +1,157 @@
+package config
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Dotted paths address config values by their YAML keys, e.g.
+// "global_vars.certbot_email" or "ansible.python_interpreter". Servers and
+// sites are managed by their own commands and cannot be reached this way.
+
+// GetPath returns the value at a dotted path
+func GetPath(cfg *Config, path string) (interface{}, error) {
+	segments, err := splitPath(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var root yaml.Node
+	if err := root.Encode(cfg); err != nil {
+		return nil, fmt.Errorf("failed to encode config: %w", err)
+	}
+
+	node := &root
+	for i, key := range segments {
+		if node.Kind != yaml.MappingNode {
+			return nil, fmt.Errorf("'%s' is not a section", strings.Join(segments[:i], "."))
+		}
+		node = mappingValue(node, key)
+		if node == nil {
+			return nil, fmt.Errorf("'%s' is not set", strings.Join(segments[:i+1], "."))
+		}
+	}
+
+	var value interface{}
+	if err := node.Decode(&value); err != nil {
+		return nil, fmt.Errorf("failed to decode '%s': %w", path, err)
+	}
+	return value, nil
+}
+
+// SetPath returns a copy of cfg with the value at a dotted path replaced by
+// raw, parsed as YAML (so "true" is a boolean and "22" a number). Missing
+// keys are created. The result must decode into Config without unknown
+// fields and pass struct and business rule validation, otherwise an error
+// is returned and cfg is unchanged.
+func SetPath(cfg *Config, path, raw string) (*Config, error) {
+	segments, err := splitPath(path)
+	if err != nil {
+		return nil, err
+	}
+
+	value, err := parseValue(raw)
+	if err != nil {
+		return nil, err
+	}
+
+	var root yaml.Node
+	if err := root.Encode(cfg); err != nil {
+		return nil, fmt.Errorf("failed to encode config: %w", err)
+	}
+
+	node := &root
+	for i, key := range segments {
+		if node.Kind != yaml.MappingNode {
+			return nil, fmt.Errorf("'%s' is not a section", strings.Join(segments[:i], "."))
+		}
+
+		if i == len(segments)-1 {
+			if existing := mappingValue(node, key); existing != nil {
+				*existing = *value
+			} else {
+				node.Content = append(node.Content, &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: key}, value)
+			}
+			break
+		}
+
+		next := mappingValue(node, key)
+		if next == nil || (next.Kind == yaml.ScalarNode && next.Tag == "!!null") {
+			// Create the section; strict decoding below rejects unknown ones
+			section := &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"}
+			if next == nil {
+				node.Content = append(node.Content, &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: key}, section)
+			} else {
+				*next = *section
+			}
+			next = mappingValue(node, key)
+		}
+		node = next
+	}
+
+	data, err := yaml.Marshal(&root)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode config: %w", err)
+	}
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	decoder.KnownFields(true)
+
+	var updated Config
+	if err := decoder.Decode(&updated); err != nil {
+		return nil, fmt.Errorf("invalid value for '%s': %w", path, err)
+	}
+
+	validator := NewValidator()
+	if err := validator.ValidateStruct(&updated); err != nil {
+		return nil, err
+	}
+	if err := validator.ValidateBusinessRules(&updated); err != nil {
+		return nil, err
+	}
+
+	return &updated, nil
+}
+
+// splitPath splits a dotted path and rejects paths into servers
+func splitPath(path string) ([]string, error) {
+	segments := strings.Split(path, ".")
+	for _, s := range segments {
+		if s == "" {
+			return nil, fmt.Errorf("invalid path '%s'", path)
+		}
+	}
+	if segments[0] == "servers" {
+		return nil, fmt.Errorf("servers and sites cannot be changed with config set; use the server, site and domain commands")
+	}
+	return segments, nil
+}
+
+// parseValue parses a command line value as a YAML node. An empty value is
+// the empty string.
+func parseValue(raw string) (*yaml.Node, error) {
+	if strings.TrimSpace(raw) == "" {
+		return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: raw}, nil
+	}
+
+	var doc yaml.Node
+	if err := yaml.Unmarshal([]byte(raw), &doc); err != nil {
+		return nil, fmt.Errorf("invalid value: %w", err)
+	}
+	if len(doc.Content) == 0 {
+		return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: raw}, nil
+	}
+	return doc.Content[0], nil
+}
+
+// mappingValue returns the value node for key in a mapping node, or nil
+func mappingValue(mapping *yaml.Node, key string) *yaml.Node {
+	for i := 0; i+1 < len(mapping.Content); i += 2 {
+		if mapping.Content[i].Value == key {
+			return mapping.Content[i+1]
+		}
+	}
+	return nil
+}
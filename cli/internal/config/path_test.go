@@ -0,0 +1,126 @@
+package config
+
+import (
+	"strings"
+	"testing"
+)
+
+func testConfig() *Config {
+	return &Config{
+		Version: "1.0",
+		Ansible: AnsibleConfig{Path: "/opt/wordsail/ansible", PythonInterpreter: "/usr/bin/python3"},
+		GlobalVars: map[string]interface{}{
+			"certbot_email":    "ops@example.com",
+			"wordsail_ssh_key": "~/.ssh/wordsail_ed25519.pub",
+		},
+	}
+}
+
+func TestGetPath(t *testing.T) {
+	cfg := testConfig()
+
+	tests := []struct {
+		path    string
+		want    interface{}
+		wantErr bool
+	}{
+		{"global_vars.certbot_email", "ops@example.com", false},
+		{"ansible.path", "/opt/wordsail/ansible", false},
+		{"version", "1.0", false},
+		{"ansible.python_interpreter", "/usr/bin/python3", false},
+		{"backup.enabled", nil, true}, // omitted while unset
+		{"global_vars.missing", nil, true},
+		{"global_vars.certbot_email.deeper", nil, true},
+		{"servers", nil, true},
+		{"global_vars..x", nil, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			got, err := GetPath(cfg, tt.path)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("GetPath(%q) error = %v, wantErr %v", tt.path, err, tt.wantErr)
+			}
+			if !tt.wantErr && got != tt.want {
+				t.Errorf("GetPath(%q) = %#v, want %#v", tt.path, got, tt.want)
+			}
+		})
+	}
+
+	section, err := GetPath(cfg, "global_vars")
+	if err != nil {
+		t.Fatalf("GetPath(global_vars) error = %v", err)
+	}
+	if m, ok := section.(map[string]interface{}); !ok || len(m) != 2 {
+		t.Errorf("GetPath(global_vars) = %#v, want the whole map", section)
+	}
+}
+
+func TestSetPath(t *testing.T) {
+	tests := []struct {
+		name    string
+		path    string
+		value   string
+		check   func(*Config) bool
+		wantErr string
+	}{
+		{
+			name:  "update global var",
+			path:  "global_vars.certbot_email",
+			value: "admin@example.com",
+			check: func(c *Config) bool { return c.GlobalVars["certbot_email"] == "admin@example.com" },
+		},
+		{
+			name:  "add global var with a typed value",
+			path:  "global_vars.php_max_children",
+			value: "20",
+			check: func(c *Config) bool { return c.GlobalVars["php_max_children"] == 20 },
+		},
+		{
+			name:  "struct field",
+			path:  "ansible.python_interpreter",
+			value: "/usr/bin/python3.12",
+			check: func(c *Config) bool { return c.Ansible.PythonInterpreter == "/usr/bin/python3.12" },
+		},
+		{
+			name:  "omitted field is created",
+			path:  "preferred_editor",
+			value: "vim",
+			check: func(c *Config) bool { return c.PreferredEditor == "vim" },
+		},
+		{
+			name:  "boolean",
+			path:  "backup.enabled",
+			value: "true",
+			check: func(c *Config) bool { return c.Backup.Enabled },
+		},
+		{name: "wrong type", path: "backup.enabled", value: "sometimes", wantErr: "invalid value"},
+		{name: "unknown field", path: "ansible.colour", value: "blue", wantErr: "not found"},
+		{name: "unknown section", path: "extras.colour", value: "blue", wantErr: "not found"},
+		{name: "required field emptied", path: "ansible.path", value: "", wantErr: "validation failed"},
+		{name: "servers", path: "servers", value: "[]", wantErr: "server, site and domain commands"},
+		{name: "into a scalar", path: "version.major", value: "2", wantErr: "not a section"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := testConfig()
+			updated, err := SetPath(cfg, tt.path, tt.value)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("SetPath() error = %v, want it to contain %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("SetPath() error = %v", err)
+			}
+			if !tt.check(updated) {
+				t.Errorf("SetPath(%q, %q) did not apply: %+v", tt.path, tt.value, updated)
+			}
+			if cfg.GlobalVars["certbot_email"] != "ops@example.com" || cfg.PreferredEditor != "" {
+				t.Error("SetPath() modified the original config")
+			}
+		})
+	}
+}
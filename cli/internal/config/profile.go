@@ -0,0 +1,190 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+const (
+	// DefaultProfile is the profile stored in ~/.wordsail/wordsail.yaml
+	DefaultProfile = "default"
+
+	// ProfileEnv selects a profile when --profile is not given
+	ProfileEnv = "WORDSAIL_PROFILE"
+
+	profilesDir       = "profiles"
+	activeProfileFile = "active-profile"
+)
+
+var profileNamePattern = regexp.MustCompile(`^[a-zA-Z0-9][a-zA-Z0-9_-]*$`)
+
+// profileOverride is the profile chosen on the command line
+var profileOverride string
+
+// SetProfileOverride makes NewManager use the named profile. An empty name
+// clears the override.
+func SetProfileOverride(name string) {
+	profileOverride = name
+}
+
+// ValidateProfileName checks that a profile name is usable as a file name
+func ValidateProfileName(name string) error {
+	if !profileNamePattern.MatchString(name) {
+		return fmt.Errorf("invalid profile name '%s': use letters, numbers, '-' and '_'", name)
+	}
+	return nil
+}
+
+// configHome returns ~/.wordsail
+func configHome() (string, error) {
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to get user home directory: %w", err)
+	}
+	return filepath.Join(homeDir, DefaultConfigDir), nil
+}
+
+// ProfilePath returns the config file for a profile. The default profile is
+// the original ~/.wordsail/wordsail.yaml, so configs from before profiles
+// existed become the default profile without being moved.
+func ProfilePath(name string) (string, error) {
+	if err := ValidateProfileName(name); err != nil {
+		return "", err
+	}
+	home, err := configHome()
+	if err != nil {
+		return "", err
+	}
+	if name == DefaultProfile {
+		return filepath.Join(home, DefaultConfigFile), nil
+	}
+	return filepath.Join(home, profilesDir, name+".yaml"), nil
+}
+
+// ActiveProfile returns the profile in use: the --profile flag, then
+// $WORDSAIL_PROFILE, then the one stored by `config profile use`
+func ActiveProfile() (string, error) {
+	if profileOverride != "" {
+		return profileOverride, nil
+	}
+	if env := strings.TrimSpace(os.Getenv(ProfileEnv)); env != "" {
+		return env, nil
+	}
+	return StoredProfile()
+}
+
+// StoredProfile returns the profile saved by SetStoredProfile, or the
+// default profile
+func StoredProfile() (string, error) {
+	home, err := configHome()
+	if err != nil {
+		return "", err
+	}
+	data, err := os.ReadFile(filepath.Join(home, activeProfileFile))
+	if os.IsNotExist(err) {
+		return DefaultProfile, nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to read active profile: %w", err)
+	}
+	name := strings.TrimSpace(string(data))
+	if name == "" {
+		return DefaultProfile, nil
+	}
+	return name, nil
+}
+
+// SetStoredProfile saves the profile used when neither --profile nor
+// $WORDSAIL_PROFILE is set
+func SetStoredProfile(name string) error {
+	if err := ValidateProfileName(name); err != nil {
+		return err
+	}
+	home, err := configHome()
+	if err != nil {
+		return err
+	}
+	path := filepath.Join(home, activeProfileFile)
+	if name == DefaultProfile {
+		if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("failed to reset active profile: %w", err)
+		}
+		return nil
+	}
+	if err := os.MkdirAll(home, 0755); err != nil {
+		return fmt.Errorf("failed to create config directory: %w", err)
+	}
+	if err := os.WriteFile(path, []byte(name+"\n"), 0644); err != nil {
+		return fmt.Errorf("failed to save active profile: %w", err)
+	}
+	return nil
+}
+
+// ProfileExists reports whether a profile's config file exists
+func ProfileExists(name string) bool {
+	path, err := ProfilePath(name)
+	if err != nil {
+		return false
+	}
+	_, err = os.Stat(path)
+	return err == nil
+}
+
+// ListProfiles returns the profiles that have a config file, sorted by name
+func ListProfiles() ([]string, error) {
+	profiles := []string{}
+	if ProfileExists(DefaultProfile) {
+		profiles = append(profiles, DefaultProfile)
+	}
+
+	home, err := configHome()
+	if err != nil {
+		return nil, err
+	}
+	entries, err := os.ReadDir(filepath.Join(home, profilesDir))
+	if os.IsNotExist(err) {
+		return profiles, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read profiles directory: %w", err)
+	}
+	for _, entry := range entries {
+		name := strings.TrimSuffix(entry.Name(), ".yaml")
+		if entry.IsDir() || name == entry.Name() || name == DefaultProfile || ValidateProfileName(name) != nil {
+			continue
+		}
+		profiles = append(profiles, name)
+	}
+	sort.Strings(profiles)
+	return profiles, nil
+}
+
+// CreateProfile writes a new profile that starts with the Ansible settings
+// and global vars of base but no servers
+func CreateProfile(name string, base *Config) (string, error) {
+	if ProfileExists(name) {
+		return "", fmt.Errorf("profile '%s' already exists", name)
+	}
+	path, err := ProfilePath(name)
+	if err != nil {
+		return "", err
+	}
+
+	cfg := DefaultConfig()
+	if base != nil {
+		cfg.Ansible = base.Ansible
+		cfg.GlobalVars = make(map[string]interface{}, len(base.GlobalVars))
+		for k, v := range base.GlobalVars {
+			cfg.GlobalVars[k] = v
+		}
+	}
+
+	if err := NewManagerWithPath(path).Save(cfg); err != nil {
+		return "", err
+	}
+	return path, nil
+}
@@ -0,0 +1,106 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/wordsail/cli/pkg/models"
+)
+
+func TestProfiles(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv(ConfigPathEnv, "")
+	t.Setenv(ProfileEnv, "")
+	t.Cleanup(func() { SetProfileOverride("") })
+
+	// An existing config becomes the default profile in place
+	defaultPath := filepath.Join(home, DefaultConfigDir, DefaultConfigFile)
+	existing := &Config{
+		Version:    CurrentVersion,
+		Ansible:    AnsibleConfig{Path: "/opt/ansible"},
+		GlobalVars: map[string]interface{}{"certbot_email": "ops@example.com"},
+		Servers:    []models.Server{{Name: "prod"}},
+	}
+	if err := NewManagerWithPath(defaultPath).Save(existing); err != nil {
+		t.Fatal(err)
+	}
+
+	mgr, err := NewManager()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if mgr.GetConfigPath() != defaultPath {
+		t.Errorf("default profile path = %q, want %q", mgr.GetConfigPath(), defaultPath)
+	}
+
+	stagingPath, err := CreateProfile("staging", existing)
+	if err != nil {
+		t.Fatalf("CreateProfile() error = %v", err)
+	}
+	if _, err := CreateProfile("staging", existing); err == nil {
+		t.Error("creating an existing profile should fail")
+	}
+	staging, err := NewManagerWithPath(stagingPath).Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(staging.Servers) != 0 || staging.GlobalVars["certbot_email"] != "ops@example.com" || staging.Ansible.Path != "/opt/ansible" {
+		t.Errorf("new profile should copy settings but not servers: %+v", staging)
+	}
+
+	profiles, err := ListProfiles()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"default", "staging"}; !reflect.DeepEqual(profiles, want) {
+		t.Errorf("ListProfiles() = %v, want %v", profiles, want)
+	}
+
+	// Stored default < environment < flag
+	if err := SetStoredProfile("staging"); err != nil {
+		t.Fatal(err)
+	}
+	assertProfile(t, "staging", stagingPath)
+
+	t.Setenv(ProfileEnv, "default")
+	assertProfile(t, "default", defaultPath)
+
+	SetProfileOverride("staging")
+	assertProfile(t, "staging", stagingPath)
+
+	SetProfileOverride("")
+	t.Setenv(ProfileEnv, "")
+	if err := SetStoredProfile(DefaultProfile); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(filepath.Join(home, DefaultConfigDir, activeProfileFile)); !os.IsNotExist(err) {
+		t.Error("switching back to the default profile should remove the stored choice")
+	}
+	assertProfile(t, "default", defaultPath)
+
+	SetProfileOverride("../escape")
+	if _, err := NewManager(); err == nil {
+		t.Error("an invalid profile name should be rejected")
+	}
+}
+
+func assertProfile(t *testing.T, wantName, wantPath string) {
+	t.Helper()
+	name, err := ActiveProfile()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if name != wantName {
+		t.Errorf("ActiveProfile() = %q, want %q", name, wantName)
+	}
+	mgr, err := NewManager()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if mgr.GetConfigPath() != wantPath {
+		t.Errorf("NewManager() path = %q, want %q", mgr.GetConfigPath(), wantPath)
+	}
+}
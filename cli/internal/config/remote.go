@@ -0,0 +1,209 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// Global vars that control remote config backups
+const (
+	RemoteBackupVar = "config_backup_remote"
+	AutoBackupVar   = "auto_backup_config"
+)
+
+// RemoteKind identifies the type of remote backup target
+type RemoteKind string
+
+const (
+	RemoteGit    RemoteKind = "git"
+	RemoteS3     RemoteKind = "s3"
+	RemoteRclone RemoteKind = "rclone"
+)
+
+// RemoteTarget describes where the configuration is backed up to
+type RemoteTarget struct {
+	Kind RemoteKind
+	// URL is the git repository URL, the s3:// URL, or the rclone remote:path
+	URL string
+}
+
+// ParseRemoteTarget parses a backup target. Supported forms:
+//
+//	s3://bucket/prefix
+//	rclone:remote:path
+//	git:<repository-url>, git@host:org/repo.git, ssh://... or https://....git
+func ParseRemoteTarget(target string) (*RemoteTarget, error) {
+	target = strings.TrimSpace(target)
+	if target == "" {
+		return nil, fmt.Errorf("backup target is empty; set global_vars.%s", RemoteBackupVar)
+	}
+
+	switch {
+	case strings.HasPrefix(target, "s3://"):
+		bucket := strings.TrimPrefix(target, "s3://")
+		if bucket == "" || strings.HasPrefix(bucket, "/") {
+			return nil, fmt.Errorf("invalid s3 target '%s' (expected s3://bucket/prefix)", target)
+		}
+		return &RemoteTarget{Kind: RemoteS3, URL: strings.TrimSuffix(target, "/")}, nil
+
+	case strings.HasPrefix(target, "rclone:"):
+		remote := strings.TrimPrefix(target, "rclone:")
+		name, _, found := strings.Cut(remote, ":")
+		if !found || name == "" {
+			return nil, fmt.Errorf("invalid rclone target '%s' (expected rclone:remote:path)", target)
+		}
+		return &RemoteTarget{Kind: RemoteRclone, URL: strings.TrimSuffix(remote, "/")}, nil
+
+	case strings.HasPrefix(target, "git:"):
+		url := strings.TrimPrefix(target, "git:")
+		if url == "" {
+			return nil, fmt.Errorf("invalid git target '%s' (expected git:<repository-url>)", target)
+		}
+		return &RemoteTarget{Kind: RemoteGit, URL: url}, nil
+
+	case strings.HasPrefix(target, "git@"),
+		strings.HasPrefix(target, "ssh://"),
+		strings.HasPrefix(target, "https://") && strings.HasSuffix(target, ".git"):
+		return &RemoteTarget{Kind: RemoteGit, URL: target}, nil
+	}
+
+	return nil, fmt.Errorf("unsupported backup target '%s' (use s3://, rclone: or a git repository URL)", target)
+}
+
+// String returns a human-readable description of the target
+func (t *RemoteTarget) String() string {
+	return fmt.Sprintf("%s (%s)", t.URL, t.Kind)
+}
+
+// RemoteBackupTarget returns the configured backup target, if any
+func RemoteBackupTarget(cfg *Config) string {
+	if cfg == nil {
+		return ""
+	}
+	target, _ := cfg.GlobalVars[RemoteBackupVar].(string)
+	return strings.TrimSpace(target)
+}
+
+// ShouldAutoBackup reports whether the config should be pushed to the remote
+// after a mutating command: auto_backup_config must be enabled, a target must
+// be configured, and the config file must actually have changed.
+func ShouldAutoBackup(cfg *Config, configChanged bool) bool {
+	if cfg == nil || !configChanged || RemoteBackupTarget(cfg) == "" {
+		return false
+	}
+
+	switch v := cfg.GlobalVars[AutoBackupVar].(type) {
+	case bool:
+		return v
+	case string:
+		switch strings.ToLower(strings.TrimSpace(v)) {
+		case "true", "yes", "1":
+			return true
+		}
+	}
+	return false
+}
+
+// ValidateRemote checks that the tooling and credentials for a target work
+func (m *Manager) ValidateRemote(target *RemoteTarget) error {
+	switch target.Kind {
+	case RemoteS3:
+		if _, err := exec.LookPath("aws"); err != nil {
+			return fmt.Errorf("aws CLI not found in PATH (required for s3:// targets)")
+		}
+		if out, err := exec.Command("aws", "sts", "get-caller-identity").CombinedOutput(); err != nil {
+			return fmt.Errorf("aws credentials check failed: %s", strings.TrimSpace(string(out)))
+		}
+	case RemoteRclone:
+		if _, err := exec.LookPath("rclone"); err != nil {
+			return fmt.Errorf("rclone not found in PATH (required for rclone: targets)")
+		}
+		name, _, _ := strings.Cut(target.URL, ":")
+		out, err := exec.Command("rclone", "listremotes").Output()
+		if err != nil {
+			return fmt.Errorf("failed to list rclone remotes: %w", err)
+		}
+		if !strings.Contains(string(out), name+":") {
+			return fmt.Errorf("rclone remote '%s' is not configured (run 'rclone config')", name)
+		}
+	case RemoteGit:
+		if _, err := exec.LookPath("git"); err != nil {
+			return fmt.Errorf("git not found in PATH (required for git targets)")
+		}
+		if out, err := exec.Command("git", "ls-remote", "--heads", target.URL).CombinedOutput(); err != nil {
+			return fmt.Errorf("cannot access git repository: %s", strings.TrimSpace(string(out)))
+		}
+	default:
+		return fmt.Errorf("unsupported backup target kind: %s", target.Kind)
+	}
+	return nil
+}
+
+// BackupToRemote pushes the current config file to the target
+func (m *Manager) BackupToRemote(target *RemoteTarget) error {
+	if !m.ConfigExists() {
+		return fmt.Errorf("config file not found at %s", m.configPath)
+	}
+
+	fileName := filepath.Base(m.configPath)
+
+	switch target.Kind {
+	case RemoteS3:
+		return runBackupCommand("", "aws", "s3", "cp", "--only-show-errors", m.configPath, target.URL+"/"+fileName)
+	case RemoteRclone:
+		return runBackupCommand("", "rclone", "copyto", m.configPath, target.URL+"/"+fileName)
+	case RemoteGit:
+		return m.backupToGit(target, fileName)
+	}
+	return fmt.Errorf("unsupported backup target kind: %s", target.Kind)
+}
+
+// backupToGit commits the config file to a local clone and pushes it
+func (m *Manager) backupToGit(target *RemoteTarget, fileName string) error {
+	repoDir := filepath.Join(m.GetConfigDir(), "backup-repo")
+
+	if _, err := os.Stat(filepath.Join(repoDir, ".git")); os.IsNotExist(err) {
+		if err := runBackupCommand("", "git", "clone", "--quiet", target.URL, repoDir); err != nil {
+			return err
+		}
+	} else if err := runBackupCommand(repoDir, "git", "pull", "--quiet", "--ff-only"); err != nil {
+		return err
+	}
+
+	data, err := os.ReadFile(m.configPath)
+	if err != nil {
+		return fmt.Errorf("failed to read config file: %w", err)
+	}
+	if err := os.WriteFile(filepath.Join(repoDir, fileName), data, 0600); err != nil {
+		return fmt.Errorf("failed to copy config into backup repository: %w", err)
+	}
+
+	if err := runBackupCommand(repoDir, "git", "add", fileName); err != nil {
+		return err
+	}
+
+	// Nothing to commit when the file is unchanged since the last backup
+	if err := exec.Command("git", "-C", repoDir, "diff", "--cached", "--quiet").Run(); err == nil {
+		return nil
+	}
+
+	message := fmt.Sprintf("Backup %s (%s)", fileName, time.Now().Format(time.RFC3339))
+	if err := runBackupCommand(repoDir, "git", "commit", "--quiet", "-m", message); err != nil {
+		return err
+	}
+	return runBackupCommand(repoDir, "git", "push", "--quiet")
+}
+
+// runBackupCommand runs a backup tool and includes its output in errors
+func runBackupCommand(dir string, name string, args ...string) error {
+	cmd := exec.Command(name, args...)
+	cmd.Dir = dir
+	if out, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("%s %s failed: %s", name, args[0], strings.TrimSpace(string(out)))
+	}
+	return nil
+}
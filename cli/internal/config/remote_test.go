@@ -0,0 +1,77 @@
+package config
+
+import (
+	"testing"
+)
+
+func TestParseRemoteTarget(t *testing.T) {
+	tests := []struct {
+		name     string
+		target   string
+		wantKind RemoteKind
+		wantURL  string
+		wantErr  bool
+	}{
+		{"s3 bucket", "s3://my-bucket", RemoteS3, "s3://my-bucket", false},
+		{"s3 bucket with prefix", "s3://my-bucket/wordsail/", RemoteS3, "s3://my-bucket/wordsail", false},
+		{"rclone remote", "rclone:b2:backups/wordsail", RemoteRclone, "b2:backups/wordsail", false},
+		{"explicit git", "git:https://example.com/me/config", RemoteGit, "https://example.com/me/config", false},
+		{"git scp-style", "git@github.com:me/config.git", RemoteGit, "git@github.com:me/config.git", false},
+		{"git ssh url", "ssh://git@example.com/me/config.git", RemoteGit, "ssh://git@example.com/me/config.git", false},
+		{"git https url", "https://github.com/me/config.git", RemoteGit, "https://github.com/me/config.git", false},
+		{"trimmed whitespace", "  s3://bucket  ", RemoteS3, "s3://bucket", false},
+		{"invalid - empty", "", "", "", true},
+		{"invalid - s3 without bucket", "s3://", "", "", true},
+		{"invalid - rclone without path separator", "rclone:b2", "", "", true},
+		{"invalid - rclone without remote name", "rclone::path", "", "", true},
+		{"invalid - empty git", "git:", "", "", true},
+		{"invalid - plain https", "https://example.com/backup", "", "", true},
+		{"invalid - local path", "/tmp/backup", "", "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseRemoteTarget(tt.target)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseRemoteTarget(%q) error = %v, wantErr %v", tt.target, err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if got.Kind != tt.wantKind || got.URL != tt.wantURL {
+				t.Errorf("ParseRemoteTarget(%q) = {%s %s}, want {%s %s}", tt.target, got.Kind, got.URL, tt.wantKind, tt.wantURL)
+			}
+		})
+	}
+}
+
+func TestShouldAutoBackup(t *testing.T) {
+	withVars := func(vars map[string]interface{}) *Config {
+		return &Config{GlobalVars: vars}
+	}
+
+	tests := []struct {
+		name    string
+		cfg     *Config
+		changed bool
+		want    bool
+	}{
+		{"enabled bool and target", withVars(map[string]interface{}{AutoBackupVar: true, RemoteBackupVar: "s3://b"}), true, true},
+		{"enabled string and target", withVars(map[string]interface{}{AutoBackupVar: "yes", RemoteBackupVar: "s3://b"}), true, true},
+		{"config unchanged", withVars(map[string]interface{}{AutoBackupVar: true, RemoteBackupVar: "s3://b"}), false, false},
+		{"disabled", withVars(map[string]interface{}{AutoBackupVar: false, RemoteBackupVar: "s3://b"}), true, false},
+		{"disabled string", withVars(map[string]interface{}{AutoBackupVar: "no", RemoteBackupVar: "s3://b"}), true, false},
+		{"flag missing", withVars(map[string]interface{}{RemoteBackupVar: "s3://b"}), true, false},
+		{"target missing", withVars(map[string]interface{}{AutoBackupVar: true}), true, false},
+		{"target blank", withVars(map[string]interface{}{AutoBackupVar: true, RemoteBackupVar: "  "}), true, false},
+		{"nil config", nil, true, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ShouldAutoBackup(tt.cfg, tt.changed); got != tt.want {
+				t.Errorf("ShouldAutoBackup() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,123 @@
+package config
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// secretKeyFile holds the key that encrypts secrets stored in the config.
+// It lives next to the config but is not part of config backups, so a
+// backed-up config does not carry readable credentials.
+const secretKeyFile = "secret.key"
+
+// encryptedPrefix marks a value written by EncryptSecret
+const encryptedPrefix = "enc:v1:"
+
+// SecretKeyPath returns ~/.wordsail/secret.key
+func SecretKeyPath() (string, error) {
+	home, err := configHome()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(home, secretKeyFile), nil
+}
+
+// loadSecretKey reads the secret key, creating it on first use when create
+// is set
+func loadSecretKey(create bool) ([]byte, error) {
+	path, err := SecretKeyPath()
+	if err != nil {
+		return nil, err
+	}
+
+	key, err := os.ReadFile(path)
+	if err == nil {
+		if len(key) != 32 {
+			return nil, fmt.Errorf("secret key %s is corrupt (expected 32 bytes, got %d)", path, len(key))
+		}
+		return key, nil
+	}
+	if !os.IsNotExist(err) {
+		return nil, fmt.Errorf("failed to read secret key: %w", err)
+	}
+	if !create {
+		return nil, fmt.Errorf("secret key %s not found; stored secrets cannot be decrypted without it", path)
+	}
+
+	key = make([]byte, 32)
+	if _, err := rand.Read(key); err != nil {
+		return nil, fmt.Errorf("failed to generate secret key: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return nil, fmt.Errorf("failed to create config directory: %w", err)
+	}
+	if err := os.WriteFile(path, key, 0600); err != nil {
+		return nil, fmt.Errorf("failed to write secret key: %w", err)
+	}
+	return key, nil
+}
+
+func secretCipher(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// EncryptSecret encrypts a value for storing in the config with AES-GCM,
+// creating the secret key if needed
+func EncryptSecret(plaintext string) (string, error) {
+	key, err := loadSecretKey(true)
+	if err != nil {
+		return "", err
+	}
+	aead, err := secretCipher(key)
+	if err != nil {
+		return "", fmt.Errorf("failed to initialise cipher: %w", err)
+	}
+
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", fmt.Errorf("failed to generate nonce: %w", err)
+	}
+	sealed := aead.Seal(nonce, nonce, []byte(plaintext), nil)
+	return encryptedPrefix + base64.StdEncoding.EncodeToString(sealed), nil
+}
+
+// DecryptSecret reverses EncryptSecret
+func DecryptSecret(value string) (string, error) {
+	encoded, ok := strings.CutPrefix(value, encryptedPrefix)
+	if !ok {
+		return "", fmt.Errorf("value is not an encrypted secret")
+	}
+	sealed, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		return "", fmt.Errorf("encrypted secret is corrupt: %w", err)
+	}
+
+	key, err := loadSecretKey(false)
+	if err != nil {
+		return "", err
+	}
+	aead, err := secretCipher(key)
+	if err != nil {
+		return "", fmt.Errorf("failed to initialise cipher: %w", err)
+	}
+	if len(sealed) < aead.NonceSize() {
+		return "", fmt.Errorf("encrypted secret is corrupt")
+	}
+
+	nonce, ciphertext := sealed[:aead.NonceSize()], sealed[aead.NonceSize():]
+	plaintext, err := aead.Open(nil, nonce, ciphertext, nil)
+	if err != nil {
+		return "", fmt.Errorf("failed to decrypt secret (was %s replaced?)", secretKeyFile)
+	}
+	return string(plaintext), nil
+}
@@ -0,0 +1,58 @@
+package config
+
+import (
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestEncryptSecret(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+
+	if _, err := DecryptSecret(encryptedPrefix + "AAAA"); err == nil {
+		t.Error("DecryptSecret() without a key should fail")
+	}
+
+	encrypted, err := EncryptSecret("s3cret pass")
+	if err != nil {
+		t.Fatalf("EncryptSecret() error = %v", err)
+	}
+	if !strings.HasPrefix(encrypted, encryptedPrefix) || strings.Contains(encrypted, "s3cret") {
+		t.Errorf("EncryptSecret() = %q", encrypted)
+	}
+
+	path, _ := SecretKeyPath()
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatalf("secret key not created: %v", err)
+	}
+	if info.Mode().Perm() != 0600 {
+		t.Errorf("secret key mode = %v, want 0600", info.Mode().Perm())
+	}
+
+	again, err := EncryptSecret("s3cret pass")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if again == encrypted {
+		t.Error("EncryptSecret() should use a fresh nonce each time")
+	}
+
+	for _, value := range []string{encrypted, again} {
+		if got, err := DecryptSecret(value); err != nil || got != "s3cret pass" {
+			t.Errorf("DecryptSecret() = %q, %v", got, err)
+		}
+	}
+
+	if _, err := DecryptSecret("plaintext"); err == nil {
+		t.Error("DecryptSecret() should reject values without the prefix")
+	}
+
+	// A different key cannot open the value
+	if err := os.WriteFile(path, []byte(strings.Repeat("k", 32)), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := DecryptSecret(encrypted); err == nil {
+		t.Error("DecryptSecret() with the wrong key should fail")
+	}
+}
@@ -0,0 +1,119 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/wordsail/cli/pkg/models"
+	"gopkg.in/yaml.v3"
+)
+
+// siteTemplatesDir holds one <name>.yaml file per site template
+const siteTemplatesDir = "templates"
+
+// ValidateSiteTemplateName checks that a template name is usable as a file name
+func ValidateSiteTemplateName(name string) error {
+	if !profileNamePattern.MatchString(name) {
+		return fmt.Errorf("invalid template name '%s': use letters, numbers, '-' and '_'", name)
+	}
+	return nil
+}
+
+// SiteTemplatePath returns ~/.wordsail/templates/<name>.yaml
+func SiteTemplatePath(name string) (string, error) {
+	if err := ValidateSiteTemplateName(name); err != nil {
+		return "", err
+	}
+	home, err := configHome()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(home, siteTemplatesDir, name+".yaml"), nil
+}
+
+// LoadSiteTemplate reads a named site template
+func LoadSiteTemplate(name string) (*models.SiteTemplate, error) {
+	path, err := SiteTemplatePath(name)
+	if err != nil {
+		return nil, err
+	}
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil, fmt.Errorf("template '%s' not found (see 'wordsail site template list')", name)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read template '%s': %w", name, err)
+	}
+
+	tmpl := &models.SiteTemplate{}
+	if err := yaml.Unmarshal(data, tmpl); err != nil {
+		return nil, fmt.Errorf("failed to parse template %s: %w", path, err)
+	}
+	tmpl.Name = name
+	return tmpl, nil
+}
+
+// SaveSiteTemplate writes a site template, refusing to replace an existing
+// one unless overwrite is set. It returns the file written.
+func SaveSiteTemplate(tmpl models.SiteTemplate, overwrite bool) (string, error) {
+	path, err := SiteTemplatePath(tmpl.Name)
+	if err != nil {
+		return "", err
+	}
+	if len(tmpl.Plugins) == 0 && tmpl.Theme == "" && len(tmpl.Options) == 0 {
+		return "", fmt.Errorf("template '%s' has no plugins, theme or options", tmpl.Name)
+	}
+	if _, err := os.Stat(path); err == nil && !overwrite {
+		return "", fmt.Errorf("template '%s' already exists", tmpl.Name)
+	}
+
+	data, err := yaml.Marshal(tmpl)
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal template: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return "", fmt.Errorf("failed to create templates directory: %w", err)
+	}
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		return "", fmt.Errorf("failed to write template: %w", err)
+	}
+	return path, nil
+}
+
+// ListSiteTemplates returns every site template, sorted by name
+func ListSiteTemplates() ([]models.SiteTemplate, error) {
+	home, err := configHome()
+	if err != nil {
+		return nil, err
+	}
+	entries, err := os.ReadDir(filepath.Join(home, siteTemplatesDir))
+	if os.IsNotExist(err) {
+		return []models.SiteTemplate{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read templates directory: %w", err)
+	}
+
+	var names []string
+	for _, entry := range entries {
+		name := strings.TrimSuffix(entry.Name(), ".yaml")
+		if entry.IsDir() || name == entry.Name() || ValidateSiteTemplateName(name) != nil {
+			continue
+		}
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	templates := make([]models.SiteTemplate, 0, len(names))
+	for _, name := range names {
+		tmpl, err := LoadSiteTemplate(name)
+		if err != nil {
+			return nil, err
+		}
+		templates = append(templates, *tmpl)
+	}
+	return templates, nil
+}
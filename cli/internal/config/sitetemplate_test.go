@@ -0,0 +1,81 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/wordsail/cli/pkg/models"
+)
+
+func TestSiteTemplates(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+
+	if templates, err := ListSiteTemplates(); err != nil || len(templates) != 0 {
+		t.Fatalf("ListSiteTemplates() with no directory = %v, %v", templates, err)
+	}
+
+	starter := models.SiteTemplate{
+		Name:    "starter",
+		Plugins: []string{"wordfence", "wp-mail-smtp"},
+		Theme:   "astra",
+		Options: map[string]string{"timezone_string": "Europe/Berlin"},
+	}
+	path, err := SaveSiteTemplate(starter, false)
+	if err != nil {
+		t.Fatalf("SaveSiteTemplate() error = %v", err)
+	}
+	if want := filepath.Join(home, DefaultConfigDir, "templates", "starter.yaml"); path != want {
+		t.Errorf("template path = %q, want %q", path, want)
+	}
+
+	loaded, err := LoadSiteTemplate("starter")
+	if err != nil {
+		t.Fatalf("LoadSiteTemplate() error = %v", err)
+	}
+	if !reflect.DeepEqual(*loaded, starter) {
+		t.Errorf("LoadSiteTemplate() = %+v, want %+v", *loaded, starter)
+	}
+
+	if _, err := SaveSiteTemplate(starter, false); err == nil {
+		t.Error("saving over an existing template without overwrite should fail")
+	}
+	if _, err := SaveSiteTemplate(models.SiteTemplate{Name: "starter", Theme: "blocksy"}, true); err != nil {
+		t.Errorf("SaveSiteTemplate() with overwrite error = %v", err)
+	}
+	if _, err := SaveSiteTemplate(models.SiteTemplate{Name: "empty"}, false); err == nil {
+		t.Error("saving an empty template should fail")
+	}
+	if _, err := SaveSiteTemplate(models.SiteTemplate{Name: "../escape", Theme: "astra"}, false); err == nil {
+		t.Error("saving a template with a path in its name should fail")
+	}
+	if _, err := LoadSiteTemplate("missing"); err == nil {
+		t.Error("LoadSiteTemplate() of a missing template should fail")
+	}
+
+	// Files that are not templates are skipped
+	dir := filepath.Dir(path)
+	if err := os.WriteFile(filepath.Join(dir, "notes.txt"), []byte("x"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := SaveSiteTemplate(models.SiteTemplate{Name: "blog", Plugins: []string{"akismet"}}, false); err != nil {
+		t.Fatal(err)
+	}
+
+	templates, err := ListSiteTemplates()
+	if err != nil {
+		t.Fatalf("ListSiteTemplates() error = %v", err)
+	}
+	var names []string
+	for _, tmpl := range templates {
+		names = append(names, tmpl.Name)
+	}
+	if !reflect.DeepEqual(names, []string{"blog", "starter"}) {
+		t.Errorf("ListSiteTemplates() names = %v, want [blog starter]", names)
+	}
+	if templates[1].Theme != "blocksy" {
+		t.Errorf("overwritten template theme = %q, want blocksy", templates[1].Theme)
+	}
+}
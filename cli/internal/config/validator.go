@@ -7,8 +7,12 @@ import (
 	"path/filepath"
 
 	"github.com/go-playground/validator/v10"
+	"github.com/wordsail/cli/internal/utils"
 )
 
+// RequiredGlobalVars are the global vars provision.yml cannot run without
+var RequiredGlobalVars = []string{"certbot_email", "wordsail_ssh_key"}
+
 // Validator handles configuration validation
 type Validator struct {
 	validate *validator.Validate
@@ -40,6 +44,34 @@ func (v *Validator) ValidateBusinessRules(config *Config) error {
 		serverNames[server.Name] = true
 	}
 
+	// Check unique site IDs on each server; they become the Linux user and
+	// database names, so two sites cannot share one
+	for _, server := range config.Servers {
+		siteDomains := make(map[string]string)
+		for _, site := range server.Sites {
+			if existing, exists := siteDomains[site.SiteID]; exists {
+				return fmt.Errorf("duplicate site ID '%s' on server '%s': used by %s and %s",
+					site.SiteID, server.Name, existing, site.PrimaryDomain)
+			}
+			siteDomains[site.SiteID] = site.PrimaryDomain
+		}
+	}
+
+	// Interpreters are written unquoted into the generated inventories
+	if config.Ansible.PythonInterpreter != "" {
+		if err := utils.ValidatePythonInterpreter(config.Ansible.PythonInterpreter); err != nil {
+			return fmt.Errorf("ansible.python_interpreter: %w", err)
+		}
+	}
+	for _, server := range config.Servers {
+		if server.PythonInterpreter == "" {
+			continue
+		}
+		if err := utils.ValidatePythonInterpreter(server.PythonInterpreter); err != nil {
+			return fmt.Errorf("server '%s': %w", server.Name, err)
+		}
+	}
+
 	// Check unique domains across all servers
 	domains := make(map[string]string)
 	for _, server := range config.Servers {
@@ -57,6 +89,61 @@ func (v *Validator) ValidateBusinessRules(config *Config) error {
 	return nil
 }
 
+// ValidateGlobalVars checks that the required global vars are set and usable:
+// certbot_email must be a valid address and wordsail_ssh_key a readable file
+func (v *Validator) ValidateGlobalVars(config *Config) error {
+	for _, name := range RequiredGlobalVars {
+		val, exists := config.GlobalVars[name]
+		if !exists || val == nil || fmt.Sprintf("%v", val) == "" {
+			return fmt.Errorf("missing required global var: %s", name)
+		}
+	}
+
+	email := fmt.Sprintf("%v", config.GlobalVars["certbot_email"])
+	if err := utils.ValidateEmail(email); err != nil {
+		return fmt.Errorf("global var certbot_email '%s': %w", email, err)
+	}
+
+	keyPath, err := utils.ExpandPath(fmt.Sprintf("%v", config.GlobalVars["wordsail_ssh_key"]))
+	if err != nil {
+		return fmt.Errorf("global var wordsail_ssh_key: %w", err)
+	}
+	f, err := os.Open(keyPath)
+	if err != nil {
+		return fmt.Errorf("global var wordsail_ssh_key: cannot read %s: %w", keyPath, err)
+	}
+	defer f.Close()
+	if info, err := f.Stat(); err == nil && info.IsDir() {
+		return fmt.Errorf("global var wordsail_ssh_key: %s is a directory, not a public key file", keyPath)
+	}
+
+	return nil
+}
+
+// SharedSiteIDs returns the site IDs used on more than one server, mapped to
+// those servers. This is allowed but makes site IDs ambiguous without --server.
+func SharedSiteIDs(config *Config) map[string][]string {
+	servers := make(map[string][]string)
+	for _, server := range config.Servers {
+		seen := make(map[string]bool)
+		for _, site := range server.Sites {
+			if seen[site.SiteID] {
+				continue
+			}
+			seen[site.SiteID] = true
+			servers[site.SiteID] = append(servers[site.SiteID], server.Name)
+		}
+	}
+
+	shared := make(map[string][]string)
+	for siteID, names := range servers {
+		if len(names) > 1 {
+			shared[siteID] = names
+		}
+	}
+	return shared
+}
+
 // ValidateAnsibleEnvironment checks if Ansible and required files exist
 func (v *Validator) ValidateAnsibleEnvironment(config *Config) error {
 	// Check if ansible-playbook exists in PATH
@@ -64,14 +151,17 @@ func (v *Validator) ValidateAnsibleEnvironment(config *Config) error {
 		return fmt.Errorf("ansible-playbook not found in PATH. Please install Ansible")
 	}
 
-	// Expand home directory if path starts with ~
-	ansiblePath := config.Ansible.Path
-	if len(ansiblePath) > 0 && ansiblePath[0] == '~' {
-		homeDir, err := os.UserHomeDir()
-		if err != nil {
-			return fmt.Errorf("failed to expand home directory: %w", err)
+	// Old releases fail part-way through provisioning, so reject them upfront.
+	// A version we cannot detect is left for ansible-playbook to complain about.
+	if version, err := AnsibleVersion(); err == nil {
+		if err := CheckAnsibleVersion(version); err != nil {
+			return err
 		}
-		ansiblePath = filepath.Join(homeDir, ansiblePath[1:])
+	}
+
+	ansiblePath, err := utils.ExpandPath(config.Ansible.Path)
+	if err != nil {
+		return err
 	}
 
 	// Check if ansible path exists
@@ -105,6 +195,10 @@ func (v *Validator) Validate(config *Config) error {
 		return err
 	}
 
+	if err := v.ValidateGlobalVars(config); err != nil {
+		return err
+	}
+
 	if err := v.ValidateAnsibleEnvironment(config); err != nil {
 		return err
 	}
@@ -0,0 +1,130 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/wordsail/cli/pkg/models"
+)
+
+func siteFixture(siteID, domain string) models.Site {
+	return models.Site{
+		SiteID:        siteID,
+		PrimaryDomain: domain,
+		Domains:       []models.Domain{{Domain: domain}},
+	}
+}
+
+func TestValidateBusinessRulesDuplicateSiteID(t *testing.T) {
+	cfg := &Config{
+		Version: CurrentVersion,
+		Servers: []models.Server{
+			{
+				Name: "web1",
+				Sites: []models.Site{
+					siteFixture("blog", "blog.example.com"),
+					siteFixture("shop", "shop.example.com"),
+					siteFixture("blog", "news.example.com"),
+				},
+			},
+		},
+	}
+
+	err := NewValidator().ValidateBusinessRules(cfg)
+	if err == nil {
+		t.Fatal("expected an error for a duplicate site ID")
+	}
+	for _, want := range []string{"'blog'", "'web1'", "blog.example.com", "news.example.com"} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("error %q does not mention %s", err, want)
+		}
+	}
+}
+
+func TestValidateBusinessRulesSiteIDOnSeveralServers(t *testing.T) {
+	cfg := &Config{
+		Version: CurrentVersion,
+		Servers: []models.Server{
+			{Name: "web1", Sites: []models.Site{siteFixture("blog", "blog.example.com")}},
+			{Name: "web2", Sites: []models.Site{siteFixture("blog", "blog.example.org"), siteFixture("shop", "shop.example.org")}},
+		},
+	}
+
+	if err := NewValidator().ValidateBusinessRules(cfg); err != nil {
+		t.Fatalf("site IDs may repeat across servers, got %v", err)
+	}
+
+	want := map[string][]string{"blog": {"web1", "web2"}}
+	if got := SharedSiteIDs(cfg); !reflect.DeepEqual(got, want) {
+		t.Errorf("SharedSiteIDs() = %v, want %v", got, want)
+	}
+}
+
+func TestValidateGlobalVars(t *testing.T) {
+	dir := t.TempDir()
+	keyFile := filepath.Join(dir, "wordsail.pub")
+	if err := os.WriteFile(keyFile, []byte("ssh-ed25519 AAAA wordsail\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		vars    map[string]interface{}
+		wantErr string
+	}{
+		{"valid", map[string]interface{}{"certbot_email": "ops@example.com", "wordsail_ssh_key": keyFile}, ""},
+		{"missing email", map[string]interface{}{"wordsail_ssh_key": keyFile}, "certbot_email"},
+		{"empty key", map[string]interface{}{"certbot_email": "ops@example.com", "wordsail_ssh_key": ""}, "wordsail_ssh_key"},
+		{"invalid email", map[string]interface{}{"certbot_email": "ops", "wordsail_ssh_key": keyFile}, "invalid email"},
+		{"missing key file", map[string]interface{}{"certbot_email": "ops@example.com", "wordsail_ssh_key": filepath.Join(dir, "missing.pub")}, "cannot read"},
+		{"key is a directory", map[string]interface{}{"certbot_email": "ops@example.com", "wordsail_ssh_key": dir}, "directory"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := NewValidator().ValidateGlobalVars(&Config{GlobalVars: tt.vars})
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("ValidateGlobalVars() error = %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("ValidateGlobalVars() error = %v, want one mentioning %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestSiteAdminRequiredUnlessNoWordPress(t *testing.T) {
+	validate := NewValidator().validate
+
+	db := models.Database{Name: "db", User: "db", Host: "localhost"}
+
+	wordpress := siteFixture("blog", "blog.example.com")
+	wordpress.Database = db
+	if err := validate.Struct(wordpress); err == nil {
+		t.Error("a WordPress site without an admin user should fail validation")
+	}
+
+	wordpress.AdminUser = "admin"
+	wordpress.AdminEmail = "admin@example.com"
+	if err := validate.Struct(wordpress); err != nil {
+		t.Errorf("WordPress site validation error = %v", err)
+	}
+
+	bare := siteFixture("app", "app.example.com")
+	bare.Database = db
+	bare.NoWordPress = true
+	if err := validate.Struct(bare); err != nil {
+		t.Errorf("bare site validation error = %v", err)
+	}
+
+	bare.AdminEmail = "not-an-email"
+	if err := validate.Struct(bare); err == nil {
+		t.Error("an invalid admin email should fail validation on a bare site too")
+	}
+}
@@ -0,0 +1,72 @@
+// Package exit defines the process exit codes wordsail uses, so scripts can
+// tell why a command failed.
+package exit
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Exit codes
+const (
+	OK             = 0
+	General        = 1 // any failure without a more specific code
+	ConfigNotFound = 2 // no configuration file; run 'wordsail init'
+	Validation     = 3 // invalid flags, arguments, names or configuration
+	SSH            = 4 // the server could not be reached or logged in to over SSH
+	Playbook       = 5 // an Ansible playbook failed
+)
+
+// Descriptions lists the exit codes with their meaning, in order, for help
+// text
+var Descriptions = []struct {
+	Code    int
+	Meaning string
+}{
+	{OK, "success"},
+	{General, "other error"},
+	{ConfigNotFound, "configuration file not found"},
+	{Validation, "invalid flags, arguments or configuration"},
+	{SSH, "SSH connection or login failed"},
+	{Playbook, "Ansible playbook failed"},
+}
+
+// Error is an error carrying the exit code the process should end with
+type Error struct {
+	Code int
+	Err  error
+}
+
+func (e *Error) Error() string {
+	return e.Err.Error()
+}
+
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// New tags err with an exit code. A nil error stays nil.
+func New(code int, err error) error {
+	if err == nil {
+		return nil
+	}
+	return &Error{Code: code, Err: err}
+}
+
+// Errorf formats an error tagged with an exit code
+func Errorf(code int, format string, args ...interface{}) error {
+	return &Error{Code: code, Err: fmt.Errorf(format, args...)}
+}
+
+// CodeOf returns the exit code for err: the code of the outermost Error in
+// its chain, OK for nil and General otherwise
+func CodeOf(err error) int {
+	if err == nil {
+		return OK
+	}
+	var e *Error
+	if errors.As(err, &e) {
+		return e.Code
+	}
+	return General
+}
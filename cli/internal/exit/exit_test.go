@@ -0,0 +1,45 @@
+package exit
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+)
+
+func TestCodeOf(t *testing.T) {
+	base := errors.New("boom")
+	tests := []struct {
+		name string
+		err  error
+		want int
+	}{
+		{"nil", nil, OK},
+		{"plain error", base, General},
+		{"tagged", New(SSH, base), SSH},
+		{"wrapped tag", fmt.Errorf("provisioning: %w", New(Playbook, base)), Playbook},
+		{"outermost tag wins", New(Validation, New(SSH, base)), Validation},
+		{"errorf", Errorf(ConfigNotFound, "run '%s' first", "wordsail init"), ConfigNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := CodeOf(tt.err); got != tt.want {
+				t.Errorf("CodeOf() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNewKeepsMessageAndChain(t *testing.T) {
+	base := errors.New("boom")
+	err := New(Playbook, base)
+	if err.Error() != "boom" {
+		t.Errorf("Error() = %q, want %q", err.Error(), "boom")
+	}
+	if !errors.Is(err, base) {
+		t.Error("tagged error should wrap the original")
+	}
+	if New(Playbook, nil) != nil {
+		t.Error("New(code, nil) should be nil")
+	}
+}
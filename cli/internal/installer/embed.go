@@ -0,0 +1,33 @@
+package installer
+
+import (
+	"embed"
+	"io/fs"
+)
+
+// embeddedFiles holds a copy of the repository's ansible directory so a
+// standalone binary, including one built with plain `go install`, can install
+// its own playbooks. go:embed cannot reach outside the module, so the copy in
+// embedded/ansible is checked in; `make embed-ansible` refreshes it and CI
+// fails when it differs from ../ansible.
+//
+//go:embed all:embedded
+var embeddedFiles embed.FS
+
+const embeddedAnsibleDir = "embedded/ansible"
+
+// EmbeddedAnsible returns the playbooks compiled into the binary, if any
+func EmbeddedAnsible() (fs.FS, bool) {
+	return embeddedAnsible(embeddedFiles)
+}
+
+func embeddedAnsible(files fs.FS) (fs.FS, bool) {
+	if _, err := fs.Stat(files, embeddedAnsibleDir+"/provision.yml"); err != nil {
+		return nil, false
+	}
+	sub, err := fs.Sub(files, embeddedAnsibleDir)
+	if err != nil {
+		return nil, false
+	}
+	return sub, true
+}
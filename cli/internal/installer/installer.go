@@ -2,14 +2,20 @@ package installer
 
 import (
 	"fmt"
-	"io"
+	"io/fs"
 	"os"
 	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
 )
 
 const (
 	wordsailDir = ".wordsail"
 	ansibleDir  = "ansible"
+
+	// VersionMarkerFile records which CLI version installed the playbooks
+	VersionMarkerFile = ".wordsail-version"
 )
 
 // GetWordsailDir returns the path to ~/.wordsail/
@@ -74,10 +80,10 @@ func DetectAnsibleSource() (string, error) {
 	return "", fmt.Errorf("could not find ansible source directory")
 }
 
-// Initialize sets up the ~/.wordsail directory and copies ansible files
-func Initialize() error {
+// Initialize sets up the ~/.wordsail directory and copies ansible files,
+// recording the CLI version that installed them
+func Initialize(version string) error {
 	wordsailPath := GetWordsailDir()
-	ansiblePath := GetAnsibleDir()
 
 	// Create ~/.wordsail/ directory
 	if err := os.MkdirAll(wordsailPath, 0755); err != nil {
@@ -85,92 +91,131 @@ func Initialize() error {
 	}
 
 	// Find ansible source
-	ansibleSource, err := DetectAnsibleSource()
+	ansibleSource, err := ansibleSourceFS()
 	if err != nil {
 		return fmt.Errorf("failed to locate ansible directory: %w", err)
 	}
 
-	// Check if ansible directory already exists
-	if _, err := os.Stat(ansiblePath); err == nil {
-		return fmt.Errorf("ansible directory already exists at %s", ansiblePath)
-	}
+	return install(ansibleSource, GetAnsibleDir(), version)
+}
 
-	// Copy ansible directory
-	if err := copyDir(ansibleSource, ansiblePath); err != nil {
-		return fmt.Errorf("failed to copy ansible files: %w", err)
+// Reinstall replaces the installed ansible files with a fresh copy from
+// source. The previous copy is moved aside and its path returned; it is put
+// back if the copy fails. The config file lives outside the ansible
+// directory and is not touched.
+func Reinstall(version string) (string, error) {
+	ansibleSource, err := ansibleSourceFS()
+	if err != nil {
+		return "", fmt.Errorf("failed to locate ansible directory: %w", err)
 	}
 
-	return nil
+	return reinstall(ansibleSource, GetAnsibleDir(), version, time.Now())
 }
 
-// copyDir recursively copies a directory
-func copyDir(src, dst string) error {
-	// Get source directory info
-	srcInfo, err := os.Stat(src)
-	if err != nil {
-		return err
+// ansibleSourceFS returns the playbooks to install. A directory found by
+// DetectAnsibleSource wins so development checkouts and system installs can
+// override the copy embedded in the binary.
+func ansibleSourceFS() (fs.FS, error) {
+	if dir, err := DetectAnsibleSource(); err == nil {
+		return os.DirFS(dir), nil
 	}
-
-	// Create destination directory
-	if err := os.MkdirAll(dst, srcInfo.Mode()); err != nil {
-		return err
+	if embedded, ok := EmbeddedAnsible(); ok {
+		return embedded, nil
 	}
+	return nil, fmt.Errorf("no ansible directory found and this binary was built without embedded playbooks; rebuild it from a checkout with `make build`, or copy the repository's ansible/ directory next to the binary")
+}
 
-	// Read source directory
-	entries, err := os.ReadDir(src)
-	if err != nil {
-		return err
+// install copies source to dest, which must not exist yet, and writes the
+// version marker
+func install(source fs.FS, dest, version string) error {
+	// Check if ansible directory already exists
+	if _, err := os.Stat(dest); err == nil {
+		return fmt.Errorf("ansible directory already exists at %s", dest)
 	}
 
-	// Copy each entry
-	for _, entry := range entries {
-		srcPath := filepath.Join(src, entry.Name())
-		dstPath := filepath.Join(dst, entry.Name())
+	// Copy ansible directory
+	if err := os.CopyFS(dest, source); err != nil {
+		return fmt.Errorf("failed to copy ansible files: %w", err)
+	}
 
-		if entry.IsDir() {
-			// Recursively copy subdirectory
-			if err := copyDir(srcPath, dstPath); err != nil {
-				return err
-			}
-		} else {
-			// Copy file
-			if err := copyFile(srcPath, dstPath); err != nil {
-				return err
-			}
-		}
+	if err := os.WriteFile(filepath.Join(dest, VersionMarkerFile), []byte(version+"\n"), 0644); err != nil {
+		return fmt.Errorf("failed to write version marker: %w", err)
 	}
 
 	return nil
 }
 
-// copyFile copies a single file
-func copyFile(src, dst string) error {
-	// Open source file
-	srcFile, err := os.Open(src)
-	if err != nil {
-		return err
+func reinstall(source fs.FS, dest, version string, now time.Time) (string, error) {
+	backup := ""
+	if _, err := os.Stat(dest); err == nil {
+		backup = dest + ".bak-" + now.Format("20060102-150405")
+		if err := os.Rename(dest, backup); err != nil {
+			return "", fmt.Errorf("failed to back up %s: %w", dest, err)
+		}
 	}
-	defer srcFile.Close()
 
-	// Get source file info
-	srcInfo, err := srcFile.Stat()
-	if err != nil {
-		return err
+	if err := install(source, dest, version); err != nil {
+		if backup != "" {
+			os.RemoveAll(dest)
+			if restoreErr := os.Rename(backup, dest); restoreErr != nil {
+				return "", fmt.Errorf("%v (previous files left at %s)", err, backup)
+			}
+		}
+		return "", err
 	}
 
-	// Create destination file
-	dstFile, err := os.OpenFile(dst, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, srcInfo.Mode())
+	return backup, nil
+}
+
+// InstalledVersion returns the CLI version that installed the playbooks in
+// ~/.wordsail/ansible, or "" if it is unknown
+func InstalledVersion() string {
+	data, err := os.ReadFile(filepath.Join(GetAnsibleDir(), VersionMarkerFile))
 	if err != nil {
-		return err
+		return ""
 	}
-	defer dstFile.Close()
+	return strings.TrimSpace(string(data))
+}
 
-	// Copy content
-	if _, err := io.Copy(dstFile, srcFile); err != nil {
-		return err
+// PlaybooksOutdated reports whether playbooks installed by one CLI version
+// are older than the running CLI. Development builds are never compared; a
+// missing marker means the playbooks predate it and are outdated.
+func PlaybooksOutdated(installed, cli string) bool {
+	cliParts, ok := parseVersion(cli)
+	if !ok {
+		return false
 	}
+	installedParts, ok := parseVersion(installed)
+	if !ok {
+		return true
+	}
+	for i := range cliParts {
+		if installedParts[i] != cliParts[i] {
+			return installedParts[i] < cliParts[i]
+		}
+	}
+	return false
+}
 
-	return nil
+// parseVersion parses "v1.2.3" or "1.2.3" (with any pre-release suffix ignored)
+func parseVersion(v string) ([3]int, bool) {
+	var parts [3]int
+	v = strings.TrimPrefix(strings.TrimSpace(v), "v")
+	if i := strings.IndexAny(v, "-+"); i >= 0 {
+		v = v[:i]
+	}
+	fields := strings.Split(v, ".")
+	if len(fields) == 0 || len(fields) > 3 {
+		return parts, false
+	}
+	for i, f := range fields {
+		n, err := strconv.Atoi(f)
+		if err != nil {
+			return parts, false
+		}
+		parts[i] = n
+	}
+	return parts, true
 }
 
 // GetAnsiblePath returns the path to use for ansible playbooks
@@ -0,0 +1,144 @@
+package installer
+
+import (
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"testing/fstest"
+	"time"
+)
+
+func writeFile(t *testing.T, path, contents string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(contents), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func readFile(t *testing.T, path string) string {
+	t.Helper()
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(data)
+}
+
+func TestReinstall(t *testing.T) {
+	dir := t.TempDir()
+	source := filepath.Join(dir, "source")
+	dest := filepath.Join(dir, "ansible")
+	writeFile(t, filepath.Join(source, "provision.yml"), "new provision")
+	writeFile(t, filepath.Join(source, "roles", "nginx", "tasks", "main.yml"), "new role")
+
+	// An existing install without a version marker
+	writeFile(t, filepath.Join(dest, "provision.yml"), "old provision")
+	writeFile(t, filepath.Join(dest, "stale.yml"), "removed upstream")
+
+	now := time.Date(2026, 10, 16, 9, 30, 0, 0, time.UTC)
+	backup, err := reinstall(os.DirFS(source), dest, "v1.4.0", now)
+	if err != nil {
+		t.Fatalf("reinstall() error = %v", err)
+	}
+
+	if want := dest + ".bak-20261016-093000"; backup != want {
+		t.Errorf("backup = %q, want %q", backup, want)
+	}
+	if got := readFile(t, filepath.Join(backup, "provision.yml")); got != "old provision" {
+		t.Errorf("backup provision.yml = %q, want the old copy", got)
+	}
+	if got := readFile(t, filepath.Join(dest, "provision.yml")); got != "new provision" {
+		t.Errorf("provision.yml = %q, want the new copy", got)
+	}
+	if got := readFile(t, filepath.Join(dest, "roles", "nginx", "tasks", "main.yml")); got != "new role" {
+		t.Errorf("role file = %q, want the new copy", got)
+	}
+	if _, err := os.Stat(filepath.Join(dest, "stale.yml")); !os.IsNotExist(err) {
+		t.Error("files removed upstream should not survive a reinstall")
+	}
+	if got := strings.TrimSpace(readFile(t, filepath.Join(dest, VersionMarkerFile))); got != "v1.4.0" {
+		t.Errorf("version marker = %q, want v1.4.0", got)
+	}
+}
+
+func TestReinstallRestoresOnFailure(t *testing.T) {
+	dir := t.TempDir()
+	dest := filepath.Join(dir, "ansible")
+	writeFile(t, filepath.Join(dest, "provision.yml"), "old provision")
+
+	if _, err := reinstall(os.DirFS(filepath.Join(dir, "missing")), dest, "v1.4.0", time.Now()); err == nil {
+		t.Fatal("expected an error for a missing source")
+	}
+	if got := readFile(t, filepath.Join(dest, "provision.yml")); got != "old provision" {
+		t.Errorf("provision.yml = %q, want the previous install put back", got)
+	}
+}
+
+func TestInstallEmbedded(t *testing.T) {
+	files := fstest.MapFS{
+		"embedded/README.md":                                   {Data: []byte("placeholder")},
+		"embedded/ansible/provision.yml":                       {Data: []byte("provision"), Mode: 0444},
+		"embedded/ansible/roles/database/templates/.my.cnf.j2": {Data: []byte("[client]"), Mode: 0444},
+	}
+
+	embedded, ok := embeddedAnsible(files)
+	if !ok {
+		t.Fatal("embeddedAnsible() found no playbooks")
+	}
+
+	dest := filepath.Join(t.TempDir(), "ansible")
+	if err := install(embedded, dest, "v1.4.0"); err != nil {
+		t.Fatalf("install() error = %v", err)
+	}
+	if got := readFile(t, filepath.Join(dest, "roles", "database", "templates", ".my.cnf.j2")); got != "[client]" {
+		t.Errorf("dotfile = %q, want it extracted", got)
+	}
+	if _, err := os.Stat(filepath.Join(dest, "README.md")); !os.IsNotExist(err) {
+		t.Error("only the ansible directory should be extracted")
+	}
+
+	if _, ok := embeddedAnsible(fstest.MapFS{"embedded/README.md": {Data: []byte("placeholder")}}); ok {
+		t.Error("a build without embedded playbooks should report none")
+	}
+}
+
+func TestEmbeddedAnsibleCheckedIn(t *testing.T) {
+	embedded, ok := EmbeddedAnsible()
+	if !ok {
+		t.Fatal("the binary embeds no playbooks; run 'make embed-ansible' and commit the copy")
+	}
+	if _, err := fs.Stat(embedded, "website.yml"); err != nil {
+		t.Errorf("embedded playbooks are incomplete: %v", err)
+	}
+}
+
+func TestPlaybooksOutdated(t *testing.T) {
+	tests := []struct {
+		name      string
+		installed string
+		cli       string
+		want      bool
+	}{
+		{"same version", "v1.4.0", "v1.4.0", false},
+		{"older patch", "v1.4.0", "v1.4.1", true},
+		{"older minor without prefix", "1.3.9", "v1.4.0", true},
+		{"newer install", "v1.5.0", "v1.4.0", false},
+		{"missing marker", "", "v1.4.0", true},
+		{"pre-release suffix", "v1.4.0-rc1", "v1.4.0", false},
+		{"development build", "v1.4.0", "dev", false},
+		{"development install", "dev", "v1.4.0", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := PlaybooksOutdated(tt.installed, tt.cli); got != tt.want {
+				t.Errorf("PlaybooksOutdated(%q, %q) = %v, want %v", tt.installed, tt.cli, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,50 @@
+package prompt
+
+import (
+	"strings"
+
+	"github.com/AlecAivazis/survey/v2"
+)
+
+// AssumeYes answers every confirmation with yes without prompting (--yes)
+var AssumeYes bool
+
+// askOne shows a survey prompt; tests replace it to answer without a terminal
+var askOne = survey.AskOne
+
+// Confirm asks a yes/no question. With force (a command's --force) or
+// AssumeYes set the answer is yes and nothing is shown.
+func Confirm(message string, defaultYes bool, force bool) (bool, error) {
+	if force {
+		return true, nil
+	}
+	return askConfirm(&survey.Confirm{Message: message, Default: defaultYes})
+}
+
+// ConfirmDestructive asks for typedToken (a site ID, server name or domain)
+// to be typed out before an action that cannot be undone. Anything else,
+// including an empty answer, declines. With AssumeYes set the answer is yes
+// and nothing is shown.
+func ConfirmDestructive(message, typedToken string) (bool, error) {
+	if AssumeYes {
+		return true, nil
+	}
+	var typed string
+	if err := askOne(&survey.Input{Message: message}, &typed); err != nil {
+		return false, err
+	}
+	return strings.TrimSpace(typed) == typedToken, nil
+}
+
+// askConfirm shows a yes/no prompt, answering yes without showing it when
+// AssumeYes is set. It is used directly by prompts that carry help text.
+func askConfirm(prompt *survey.Confirm) (bool, error) {
+	if AssumeYes {
+		return true, nil
+	}
+	var response bool
+	if err := askOne(prompt, &response); err != nil {
+		return false, err
+	}
+	return response, nil
+}
@@ -0,0 +1,98 @@
+package prompt
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/AlecAivazis/survey/v2"
+)
+
+// answer replaces askOne for a test with one that records the prompt and
+// answers with value (or err)
+func answer(t *testing.T, value interface{}, err error) *int {
+	t.Helper()
+	asked := new(int)
+	original := askOne
+	askOne = func(p survey.Prompt, response interface{}, _ ...survey.AskOpt) error {
+		*asked++
+		if err != nil {
+			return err
+		}
+		switch r := response.(type) {
+		case *bool:
+			*r = value.(bool)
+		case *string:
+			*r = value.(string)
+		}
+		return nil
+	}
+	t.Cleanup(func() { askOne = original })
+	return asked
+}
+
+func TestConfirm(t *testing.T) {
+	tests := []struct {
+		name      string
+		force     bool
+		assumeYes bool
+		reply     bool
+		want      bool
+		wantAsked int
+	}{
+		{name: "yes", reply: true, want: true, wantAsked: 1},
+		{name: "no", reply: false, want: false, wantAsked: 1},
+		{name: "force", force: true, want: true},
+		{name: "assume yes", assumeYes: true, want: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			AssumeYes = tt.assumeYes
+			defer func() { AssumeYes = false }()
+			asked := answer(t, tt.reply, nil)
+
+			got, err := Confirm("Continue?", false, tt.force)
+			if err != nil {
+				t.Fatalf("Confirm() error = %v", err)
+			}
+			if got != tt.want || *asked != tt.wantAsked {
+				t.Errorf("Confirm() = %v after %d prompt(s), want %v after %d", got, *asked, tt.want, tt.wantAsked)
+			}
+		})
+	}
+
+	answer(t, false, errors.New("interrupt"))
+	if got, err := Confirm("Continue?", true, false); err == nil || got {
+		t.Errorf("Confirm() on an interrupted prompt = %v, %v; want false and an error", got, err)
+	}
+}
+
+func TestConfirmDestructive(t *testing.T) {
+	tests := []struct {
+		name      string
+		typed     string
+		assumeYes bool
+		want      bool
+		wantAsked int
+	}{
+		{name: "token typed", typed: "examplecom", want: true, wantAsked: 1},
+		{name: "surrounding spaces", typed: " examplecom ", want: true, wantAsked: 1},
+		{name: "wrong token", typed: "example.com", want: false, wantAsked: 1},
+		{name: "empty answer", typed: "", want: false, wantAsked: 1},
+		{name: "assume yes", assumeYes: true, want: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			AssumeYes = tt.assumeYes
+			defer func() { AssumeYes = false }()
+			asked := answer(t, tt.typed, nil)
+
+			got, err := ConfirmDestructive("Type 'examplecom' to confirm deletion:", "examplecom")
+			if err != nil {
+				t.Fatalf("ConfirmDestructive() error = %v", err)
+			}
+			if got != tt.want || *asked != tt.wantAsked {
+				t.Errorf("ConfirmDestructive() = %v after %d prompt(s), want %v after %d", got, *asked, tt.want, tt.wantAsked)
+			}
+		})
+	}
+}
@@ -82,26 +82,21 @@ func PromptDomainAdd(servers []models.Server) (*DomainAddInput, error) {
 		Message: "Domain name to add:",
 		Help:    "Enter the domain (e.g., www.example.com)",
 	}
-	if err := survey.AskOne(domainPrompt, &input.Domain, survey.WithValidator(survey.Required), survey.WithValidator(utils.ValidateDomain)); err != nil {
+	if err := survey.AskOne(domainPrompt, &input.Domain, survey.WithValidator(survey.Required), survey.WithValidator(utils.ValidateDomain), survey.WithValidator(domainAvailable(servers))); err != nil {
 		return nil, err
 	}
 
-	// Check if domain already exists
-	for _, domain := range siteOptions[selectedIndex].Site.Domains {
-		if domain.Domain == input.Domain {
-			return nil, fmt.Errorf("domain '%s' already exists on this site", input.Domain)
-		}
-	}
-
 	// Ask about SSL
 	sslPrompt := &survey.Confirm{
 		Message: "Issue SSL certificate for this domain?",
 		Default: true,
 		Help:    "Automatically obtain a Let's Encrypt SSL certificate",
 	}
-	if err := survey.AskOne(sslPrompt, &input.IssueSSL); err != nil {
+	issueSSL, err := askConfirm(sslPrompt)
+	if err != nil {
 		return nil, err
 	}
+	input.IssueSSL = issueSSL
 
 	return input, nil
 }
@@ -172,11 +167,8 @@ func PromptDomainRemove(servers []models.Server) (*DomainRemoveInput, error) {
 		fmt.Println("This may break the WordPress installation.")
 		fmt.Println()
 
-		var confirm bool
-		if err := survey.AskOne(&survey.Confirm{
-			Message: "Are you sure you want to remove the primary domain?",
-			Default: false,
-		}, &confirm); err != nil {
+		confirm, err := Confirm("Are you sure you want to remove the primary domain?", false, false)
+		if err != nil {
 			return nil, err
 		}
 
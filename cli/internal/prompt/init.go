@@ -13,8 +13,11 @@ import (
 type InitInput struct {
 	SSHPublicKey string
 	CertbotEmail string
+	GenerateKey  bool
 }
 
+const generateKeyOption = "Generate a new ed25519 key"
+
 // PromptInitSetup prompts for initial setup configuration
 func PromptInitSetup() (*InitInput, error) {
 	input := &InitInput{}
@@ -26,25 +29,34 @@ func PromptInitSetup() (*InitInput, error) {
 	// SSH public key selection
 	sshPubKeys, err := findSSHPublicKeys()
 	if err != nil || len(sshPubKeys) == 0 {
-		// Fallback to manual input if no keys found
-		homeDir, _ := os.UserHomeDir()
-		defaultKeyPath := filepath.Join(homeDir, ".ssh", "id_rsa.pub")
-
-		keyPrompt := &survey.Input{
-			Message: "SSH public key file (for wordsail user):",
-			Default: defaultKeyPath,
-			Help:    "Path to the SSH public key that will be authorized for the wordsail user on servers",
+		// Offer to generate a key before falling back to manual input
+		generatePrompt := &survey.Confirm{
+			Message: "No SSH public keys found. Generate a new ed25519 key?",
+			Default: true,
+			Help:    "Creates ~/.ssh/wordsail_ed25519 and ~/.ssh/wordsail_ed25519.pub",
 		}
-		if err := survey.AskOne(keyPrompt, &input.SSHPublicKey, survey.WithValidator(survey.Required)); err != nil {
+		generateKey, err := askConfirm(generatePrompt)
+		if err != nil {
 			return nil, err
 		}
-	} else if len(sshPubKeys) == 1 {
-		// Auto-select when only one key is available
-		input.SSHPublicKey = sshPubKeys[0]
-		fmt.Printf("Using SSH public key: %s\n", sshPubKeys[0])
+		input.GenerateKey = generateKey
+
+		if !input.GenerateKey {
+			homeDir, _ := os.UserHomeDir()
+			defaultKeyPath := filepath.Join(homeDir, ".ssh", "id_rsa.pub")
+
+			keyPrompt := &survey.Input{
+				Message: "SSH public key file (for wordsail user):",
+				Default: defaultKeyPath,
+				Help:    "Path to the SSH public key that will be authorized for the wordsail user on servers",
+			}
+			if err := survey.AskOne(keyPrompt, &input.SSHPublicKey, survey.WithValidator(survey.Required)); err != nil {
+				return nil, err
+			}
+		}
 	} else {
 		// Show picker with available keys
-		options := append(sshPubKeys, "Enter path manually")
+		options := append(sshPubKeys, generateKeyOption, "Enter path manually")
 		keyPrompt := &survey.Select{
 			Message: "SSH public key (for wordsail user):",
 			Options: options,
@@ -55,7 +67,9 @@ func PromptInitSetup() (*InitInput, error) {
 			return nil, err
 		}
 
-		if selectedKey == "Enter path manually" {
+		if selectedKey == generateKeyOption {
+			input.GenerateKey = true
+		} else if selectedKey == "Enter path manually" {
 			homeDir, _ := os.UserHomeDir()
 			defaultKeyPath := filepath.Join(homeDir, ".ssh", "id_rsa.pub")
 			keyPrompt := &survey.Input{
@@ -82,6 +96,16 @@ func PromptInitSetup() (*InitInput, error) {
 	return input, nil
 }
 
+// PromptOverwriteSSHKey asks before replacing an existing key file
+func PromptOverwriteSSHKey(path string) (bool, error) {
+	overwritePrompt := &survey.Confirm{
+		Message: fmt.Sprintf("SSH key %s already exists. Overwrite it?", path),
+		Default: false,
+		Help:    "Answering no keeps the existing key and uses it for WordSail",
+	}
+	return askConfirm(overwritePrompt)
+}
+
 // findSSHPublicKeys looks for public SSH keys in ~/.ssh/
 func findSSHPublicKeys() ([]string, error) {
 	homeDir, err := os.UserHomeDir()
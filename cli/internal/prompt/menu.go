@@ -0,0 +1,29 @@
+package prompt
+
+import (
+	"os"
+
+	"github.com/AlecAivazis/survey/v2"
+	"golang.org/x/term"
+)
+
+// Interactive reports whether stdin and stdout are both terminals, so a
+// prompt can be shown and answered
+func Interactive() bool {
+	return term.IsTerminal(int(os.Stdin.Fd())) && term.IsTerminal(int(os.Stdout.Fd()))
+}
+
+// PromptMainMenu asks what to do when wordsail runs without a command and
+// returns the index of the chosen option
+func PromptMainMenu(options []string) (int, error) {
+	var selected int
+	prompt := &survey.Select{
+		Message:  "What would you like to do?",
+		Options:  options,
+		PageSize: len(options),
+	}
+	if err := askOne(prompt, &selected); err != nil {
+		return 0, err
+	}
+	return selected, nil
+}
@@ -16,13 +16,15 @@ type ServerInput struct {
 	Name     string
 	Hostname string
 	IP       string
+	IPv6     string
 	SSHUser  string
 	SSHPort  int
 	SSHKey   string
 }
 
-// PromptServerAdd prompts for server details
-func PromptServerAdd() (*ServerInput, error) {
+// PromptServerAdd prompts for server details. defaultKey, when set, is
+// preselected as the SSH private key (e.g. the key generated by init).
+func PromptServerAdd(defaultKey string) (*ServerInput, error) {
 	input := &ServerInput{}
 
 	// Server name
@@ -34,24 +36,41 @@ func PromptServerAdd() (*ServerInput, error) {
 		return nil, err
 	}
 
-	// IP address
+	// IP address or hostname
+	var address string
 	ipPrompt := &survey.Input{
-		Message: "IP address:",
-		Help:    "The server's IP address from your cloud provider",
+		Message: "IP address or hostname:",
+		Help:    "The server's IP address or DNS name from your cloud provider (e.g., 203.0.113.10 or server1.example.com)",
 	}
-	if err := survey.AskOne(ipPrompt, &input.IP, survey.WithValidator(survey.Required), survey.WithValidator(utils.ValidateIP)); err != nil {
+	if err := survey.AskOne(ipPrompt, &address, survey.WithValidator(survey.Required), survey.WithValidator(utils.ValidateHostnameOrIP)); err != nil {
 		return nil, err
 	}
 
-	// Set hostname to IP by default
-	input.Hostname = input.IP
+	// A hostname is stored without an IP; connections resolve it when needed
+	input.Hostname = address
+	if utils.ValidateIP(address) == nil {
+		input.IP = address
+	}
+
+	// IPv6 address (optional)
+	ipv6Prompt := &survey.Input{
+		Message: "IPv6 address (optional):",
+		Help:    "The server's IPv6 address, if it has one. Leave blank for IPv4 only",
+	}
+	if err := survey.AskOne(ipv6Prompt, &input.IPv6, survey.WithValidator(optionalIPv6)); err != nil {
+		return nil, err
+	}
+	input.IPv6 = utils.NormalizeIPv6(input.IPv6)
 
 	// SSH key selection
 	sshKeys, err := findSSHKeys()
 	if err != nil || len(sshKeys) == 0 {
 		// Fallback to manual input if no keys found
-		homeDir, _ := os.UserHomeDir()
-		defaultKeyPath := filepath.Join(homeDir, ".ssh", "id_rsa")
+		defaultKeyPath := defaultKey
+		if defaultKeyPath == "" {
+			homeDir, _ := os.UserHomeDir()
+			defaultKeyPath = filepath.Join(homeDir, ".ssh", "id_rsa")
+		}
 
 		keyPrompt := &survey.Input{
 			Message: "SSH private key file:",
@@ -72,6 +91,11 @@ func PromptServerAdd() (*ServerInput, error) {
 			Options: sshKeys,
 			Help:    "Select the SSH key to use for authentication",
 		}
+		for _, key := range sshKeys {
+			if key == defaultKey {
+				keyPrompt.Default = defaultKey
+			}
+		}
 		var selectedKey string
 		if err := survey.AskOne(keyPrompt, &selectedKey); err != nil {
 			return nil, err
@@ -165,6 +189,7 @@ func (si *ServerInput) ToServer() models.Server {
 		Name:     si.Name,
 		Hostname: si.Hostname,
 		IP:       si.IP,
+		IPv6:     si.IPv6,
 		SSH: models.SSHConfig{
 			User:    si.SSHUser,
 			Port:    si.SSHPort,
@@ -175,21 +200,43 @@ func (si *ServerInput) ToServer() models.Server {
 	}
 }
 
+// SSHKeyPassphrase asks for the passphrase of an encrypted SSH key
+func SSHKeyPassphrase(keyFile string) ([]byte, error) {
+	var passphrase string
+	passphrasePrompt := &survey.Password{
+		Message: fmt.Sprintf("Passphrase for %s:", keyFile),
+	}
+	if err := survey.AskOne(passphrasePrompt, &passphrase); err != nil {
+		return nil, err
+	}
+	return []byte(passphrase), nil
+}
+
+// optionalIPv6 accepts an empty answer or a valid IPv6 address
+func optionalIPv6(val interface{}) error {
+	if str, ok := val.(string); ok && str == "" {
+		return nil
+	}
+	return utils.ValidateIPv6(val)
+}
+
 func confirmServerAdd(input *ServerInput) error {
 	fmt.Println("\nServer Configuration:")
 	fmt.Printf("  Name:     %s\n", input.Name)
-	fmt.Printf("  IP:       %s\n", input.IP)
+	if input.IP != "" {
+		fmt.Printf("  IP:       %s\n", input.IP)
+	} else {
+		fmt.Printf("  Hostname: %s\n", input.Hostname)
+	}
+	if input.IPv6 != "" {
+		fmt.Printf("  IPv6:     %s\n", input.IPv6)
+	}
 	fmt.Printf("  SSH Key:  %s\n", input.SSHKey)
 	fmt.Printf("  SSH User: %s\n", input.SSHUser)
 	fmt.Printf("  SSH Port: %d\n", input.SSHPort)
 
-	var confirm bool
-	confirmPrompt := &survey.Confirm{
-		Message: "Proceed with provisioning?",
-		Default: true,
-	}
-
-	if err := survey.AskOne(confirmPrompt, &confirm); err != nil {
+	confirm, err := Confirm("Proceed with provisioning?", true, false)
+	if err != nil {
 		return err
 	}
 
@@ -20,11 +20,17 @@ type SiteInput struct {
 	AdminUser     string
 	AdminEmail    string
 	AdminPassword string
+	PHPVersion    string
+
+	// NoWordPress creates a bare site without WordPress or an admin account
+	NoWordPress bool
 }
 
-// PromptSiteCreate prompts for site creation details
-func PromptSiteCreate(servers []models.Server) (*SiteInput, error) {
-	input := &SiteInput{}
+// PromptSiteCreate prompts for site creation details. The PHP version is
+// only asked for when phpVersion is empty, and the WordPress admin account
+// is skipped for bare sites.
+func PromptSiteCreate(servers []models.Server, phpVersion string, noWordPress bool) (*SiteInput, error) {
+	input := &SiteInput{PHPVersion: phpVersion, NoWordPress: noWordPress}
 
 	if len(servers) == 0 {
 		return nil, fmt.Errorf("no servers available. Add a server first with: wordsail server add")
@@ -45,7 +51,7 @@ func PromptSiteCreate(servers []models.Server) (*SiteInput, error) {
 	// 1. Select server
 	serverOptions := make([]string, len(provisionedServers))
 	for i, s := range provisionedServers {
-		serverOptions[i] = fmt.Sprintf("%s (%s) - %d sites", s.Name, s.IP, len(s.Sites))
+		serverOptions[i] = fmt.Sprintf("%s (%s) - %d sites", s.Name, s.Address(), len(s.Sites))
 	}
 
 	var serverIndex int
@@ -64,7 +70,7 @@ func PromptSiteCreate(servers []models.Server) (*SiteInput, error) {
 		Message: "Primary domain name:",
 		Help:    "The main domain for this WordPress site (e.g., example.com)",
 	}
-	if err := survey.AskOne(domainPrompt, &input.Domain, survey.WithValidator(survey.Required), survey.WithValidator(utils.ValidateDomain)); err != nil {
+	if err := survey.AskOne(domainPrompt, &input.Domain, survey.WithValidator(survey.Required), survey.WithValidator(utils.ValidateDomain), survey.WithValidator(domainAvailable(servers))); err != nil {
 		return nil, err
 	}
 
@@ -72,6 +78,36 @@ func PromptSiteCreate(servers []models.Server) (*SiteInput, error) {
 	selectedServer := provisionedServers[serverIndex]
 	input.SiteID = generateUniqueSiteID(input.Domain, selectedServer.Sites)
 
+	// 4-6. WordPress admin account
+	if !input.NoWordPress {
+		if err := promptSiteAdmin(input); err != nil {
+			return nil, err
+		}
+	}
+
+	// 7. PHP version
+	if input.PHPVersion == "" {
+		phpPrompt := &survey.Select{
+			Message: "PHP version:",
+			Options: utils.SupportedPHPVersions,
+			Default: utils.DefaultPHPVersion,
+			Help:    "Versions other than the server default are installed when the site is created",
+		}
+		if err := survey.AskOne(phpPrompt, &input.PHPVersion); err != nil {
+			return nil, err
+		}
+	}
+
+	// 8. Confirmation
+	if err := confirmSiteCreation(input); err != nil {
+		return nil, err
+	}
+
+	return input, nil
+}
+
+// promptSiteAdmin asks for the WordPress admin account of a new site
+func promptSiteAdmin(input *SiteInput) error {
 	// 4. WordPress admin user
 	adminUserPrompt := &survey.Input{
 		Message: "WordPress admin username:",
@@ -79,7 +115,7 @@ func PromptSiteCreate(servers []models.Server) (*SiteInput, error) {
 		Help:    "Username for WordPress admin account",
 	}
 	if err := survey.AskOne(adminUserPrompt, &input.AdminUser, survey.WithValidator(survey.Required)); err != nil {
-		return nil, err
+		return err
 	}
 
 	// 5. WordPress admin email
@@ -88,18 +124,18 @@ func PromptSiteCreate(servers []models.Server) (*SiteInput, error) {
 		Help:    "Email address for WordPress admin account",
 	}
 	if err := survey.AskOne(adminEmailPrompt, &input.AdminEmail, survey.WithValidator(survey.Required), survey.WithValidator(utils.ValidateEmail)); err != nil {
-		return nil, err
+		return err
 	}
 
 	// 6. WordPress admin password (with option to generate)
-	var useGeneratedPassword bool
 	generatePrompt := &survey.Confirm{
 		Message: "Generate secure password?",
 		Default: true,
 		Help:    "Auto-generate a strong password or enter your own",
 	}
-	if err := survey.AskOne(generatePrompt, &useGeneratedPassword); err != nil {
-		return nil, err
+	useGeneratedPassword, err := askConfirm(generatePrompt)
+	if err != nil {
+		return err
 	}
 
 	if useGeneratedPassword {
@@ -109,16 +145,12 @@ func PromptSiteCreate(servers []models.Server) (*SiteInput, error) {
 		fmt.Printf("⚠️  IMPORTANT: Save this password securely!\n")
 		fmt.Printf("\n")
 
-		var acknowledged bool
-		ackPrompt := &survey.Confirm{
-			Message: "Have you saved the password?",
-			Default: false,
-		}
-		if err := survey.AskOne(ackPrompt, &acknowledged); err != nil {
-			return nil, err
+		acknowledged, err := Confirm("Have you saved the password?", false, false)
+		if err != nil {
+			return err
 		}
 		if !acknowledged {
-			return nil, fmt.Errorf("please save the password before continuing")
+			return fmt.Errorf("please save the password before continuing")
 		}
 	} else {
 		passwordPrompt := &survey.Password{
@@ -126,16 +158,11 @@ func PromptSiteCreate(servers []models.Server) (*SiteInput, error) {
 			Help:    "Min 12 chars with uppercase, lowercase, number, and special character",
 		}
 		if err := survey.AskOne(passwordPrompt, &input.AdminPassword, survey.WithValidator(survey.Required), survey.WithValidator(utils.ValidatePasswordStrength)); err != nil {
-			return nil, err
+			return err
 		}
 	}
 
-	// 7. Confirmation
-	if err := confirmSiteCreation(input); err != nil {
-		return nil, err
-	}
-
-	return input, nil
+	return nil
 }
 
 // confirmSiteCreation shows a summary and asks for confirmation
@@ -147,18 +174,18 @@ func confirmSiteCreation(input *SiteInput) error {
 	fmt.Printf("  Server:       %s\n", input.ServerName)
 	fmt.Printf("  Domain:       %s\n", input.Domain)
 	fmt.Printf("  Site ID:      %s\n", input.SiteID)
-	fmt.Printf("  Admin User:   %s\n", input.AdminUser)
-	fmt.Printf("  Admin Email:  %s\n", input.AdminEmail)
+	if input.NoWordPress {
+		fmt.Printf("  WordPress:    no (bare site)\n")
+	} else {
+		fmt.Printf("  Admin User:   %s\n", input.AdminUser)
+		fmt.Printf("  Admin Email:  %s\n", input.AdminEmail)
+	}
+	fmt.Printf("  PHP Version:  %s\n", input.PHPVersion)
 	fmt.Println("═══════════════════════════════════════════════════")
 	fmt.Println()
 
-	var confirm bool
-	confirmPrompt := &survey.Confirm{
-		Message: "Create this WordPress site?",
-		Default: true,
-	}
-
-	if err := survey.AskOne(confirmPrompt, &confirm); err != nil {
+	confirm, err := Confirm("Create this WordPress site?", true, false)
+	if err != nil {
 		return err
 	}
 
@@ -234,20 +261,66 @@ func GenerateSiteID(domain string, existingSites []models.Site) string {
 	return generateUniqueSiteID(domain, existingSites)
 }
 
+// Character classes used in generated passwords. Every password gets at
+// least one of each, so it passes utils.ValidatePasswordStrength.
+var passwordClasses = []string{
+	"abcdefghijklmnopqrstuvwxyz",
+	"ABCDEFGHIJKLMNOPQRSTUVWXYZ",
+	"0123456789",
+	"!@#$%^&*",
+}
+
+// minPasswordLength is the shortest password ValidatePasswordStrength accepts
+const minPasswordLength = 12
+
 // GenerateSecurePassword generates a cryptographically secure random password
+// with at least one lowercase letter, uppercase letter, digit and special
+// character. Lengths below 12 are raised to 12.
 func GenerateSecurePassword(length int) string {
-	const charset = "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789!@#$%^&*"
-	password := make([]byte, length)
+	if length < minPasswordLength {
+		length = minPasswordLength
+	}
+	charset := strings.Join(passwordClasses, "")
 
-	for i := range password {
-		num, err := rand.Int(rand.Reader, big.NewInt(int64(len(charset))))
-		if err != nil {
-			// Fallback to a simple method if crypto/rand fails
-			password[i] = charset[i%len(charset)]
-		} else {
-			password[i] = charset[num.Int64()]
-		}
+	password := make([]byte, 0, length)
+	for _, class := range passwordClasses {
+		password = append(password, class[randomIndex(len(class))])
+	}
+	for len(password) < length {
+		password = append(password, charset[randomIndex(len(charset))])
+	}
+
+	// Shuffle so the guaranteed characters are not always first
+	for i := len(password) - 1; i > 0; i-- {
+		j := randomIndex(i + 1)
+		password[i], password[j] = password[j], password[i]
 	}
 
 	return string(password)
 }
+
+// randomIndex returns a uniformly random index below n from crypto/rand
+func randomIndex(n int) int {
+	num, err := rand.Int(rand.Reader, big.NewInt(int64(n)))
+	if err != nil {
+		// crypto/rand does not fail on supported platforms; a predictable
+		// password would be worse than stopping
+		panic(fmt.Sprintf("failed to generate random password: %v", err))
+	}
+	return int(num.Int64())
+}
+
+// domainAvailable returns a survey validator that rejects domains already
+// used by a site on any server
+func domainAvailable(servers []models.Server) survey.Validator {
+	return func(val interface{}) error {
+		domain, ok := val.(string)
+		if !ok {
+			return fmt.Errorf("invalid domain type")
+		}
+		if server, site := utils.FindSiteByDomainAcrossServers(servers, domain); site != nil {
+			return fmt.Errorf("domain is already used by site '%s' on server '%s'", site.SiteID, server.Name)
+		}
+		return nil
+	}
+}
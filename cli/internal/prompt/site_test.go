@@ -0,0 +1,52 @@
+package prompt
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/wordsail/cli/internal/utils"
+	"github.com/wordsail/cli/pkg/models"
+)
+
+func TestGenerateSecurePassword(t *testing.T) {
+	seen := make(map[string]bool)
+	for i := 0; i < 1000; i++ {
+		password := GenerateSecurePassword(20)
+		if len(password) != 20 {
+			t.Fatalf("GenerateSecurePassword(20) = %q, length %d", password, len(password))
+		}
+		if err := utils.ValidatePasswordStrength(password); err != nil {
+			t.Fatalf("GenerateSecurePassword(20) = %q fails the strength check: %v", password, err)
+		}
+		seen[password] = true
+	}
+	if len(seen) != 1000 {
+		t.Errorf("1000 generated passwords had only %d distinct values", len(seen))
+	}
+
+	if got := GenerateSecurePassword(4); len(got) != minPasswordLength {
+		t.Errorf("GenerateSecurePassword(4) length = %d, want %d", len(got), minPasswordLength)
+	}
+}
+
+func TestDomainAvailable(t *testing.T) {
+	servers := []models.Server{
+		{Name: "web1", Sites: []models.Site{
+			{SiteID: "blog", PrimaryDomain: "blog.com", Domains: []models.Domain{{Domain: "blog.com"}, {Domain: "www.blog.com"}}},
+		}},
+		{Name: "web2", Sites: []models.Site{
+			{SiteID: "shop", PrimaryDomain: "shop.com", Domains: []models.Domain{{Domain: "shop.com"}}},
+		}},
+	}
+	validate := domainAvailable(servers)
+
+	if err := validate("new.com"); err != nil {
+		t.Errorf("domainAvailable(new.com) = %v, want nil", err)
+	}
+	for domain, owner := range map[string]string{"WWW.blog.com": "'blog' on server 'web1'", "shop.com": "'shop' on server 'web2'"} {
+		err := validate(domain)
+		if err == nil || !strings.Contains(err.Error(), owner) {
+			t.Errorf("domainAvailable(%s) = %v, want an error naming site %s", domain, err, owner)
+		}
+	}
+}
@@ -0,0 +1,82 @@
+package state
+
+import (
+	"fmt"
+	"sync"
+	"time"
+)
+
+// Batch provisioning outcomes
+const (
+	BatchProvisioned = "provisioned"
+	BatchError       = "error"
+	BatchSkipped     = "skipped"
+)
+
+// BatchResult records the outcome of provisioning one server in a batch
+type BatchResult struct {
+	Server   string
+	Status   string
+	Err      error
+	Duration time.Duration
+}
+
+// ProvisionFunc provisions a single server by name
+type ProvisionFunc func(serverName string) error
+
+// ProvisionBatch provisions servers with at most parallel runs in flight and
+// marks each one provisioned or error as soon as its own run finishes.
+// Failures don't stop the batch unless failFast is set, in which case servers
+// that have not started yet are skipped. Results are returned in input order.
+func (m *Manager) ProvisionBatch(serverNames []string, parallel int, failFast bool, provision ProvisionFunc) []BatchResult {
+	if parallel < 1 {
+		parallel = 1
+	}
+
+	results := make([]BatchResult, len(serverNames))
+	sem := make(chan struct{}, parallel)
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	failed := false
+
+	for i, name := range serverNames {
+		sem <- struct{}{}
+
+		mu.Lock()
+		stop := failFast && failed
+		mu.Unlock()
+		if stop {
+			<-sem
+			results[i] = BatchResult{Server: name, Status: BatchSkipped}
+			continue
+		}
+
+		wg.Add(1)
+		go func(i int, name string) {
+			defer wg.Done()
+			defer func() { <-sem }()
+
+			start := time.Now()
+			result := BatchResult{Server: name, Status: BatchProvisioned}
+			if err := provision(name); err != nil {
+				result.Status = BatchError
+				result.Err = err
+				m.MarkServerError(name)
+			} else if err := m.MarkServerProvisioned(name); err != nil {
+				result.Status = BatchError
+				result.Err = fmt.Errorf("provisioned but failed to update state: %w", err)
+			}
+			result.Duration = time.Since(start)
+			results[i] = result
+
+			if result.Status == BatchError {
+				mu.Lock()
+				failed = true
+				mu.Unlock()
+			}
+		}(i, name)
+	}
+
+	wg.Wait()
+	return results
+}
@@ -0,0 +1,145 @@
+package state
+
+import (
+	"errors"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/wordsail/cli/internal/config"
+	"github.com/wordsail/cli/pkg/models"
+)
+
+func newBatchTestManager(t *testing.T, names ...string) (*Manager, *config.Manager) {
+	t.Helper()
+	cfgMgr := config.NewManagerWithPath(filepath.Join(t.TempDir(), "wordsail.yaml"))
+	cfg := &config.Config{Version: "1.0"}
+	for _, name := range names {
+		cfg.Servers = append(cfg.Servers, models.Server{
+			Name:   name,
+			IP:     "203.0.113.10",
+			SSH:    models.SSHConfig{User: "root", Port: 22, KeyFile: "/keys/id"},
+			Status: "unprovisioned",
+		})
+	}
+	if err := cfgMgr.Save(cfg); err != nil {
+		t.Fatalf("failed to save config: %v", err)
+	}
+	return NewManager(cfgMgr), cfgMgr
+}
+
+func serverStatuses(t *testing.T, cfgMgr *config.Manager) map[string]string {
+	t.Helper()
+	cfg, err := cfgMgr.Load()
+	if err != nil {
+		t.Fatalf("failed to load config: %v", err)
+	}
+	statuses := make(map[string]string, len(cfg.Servers))
+	for _, server := range cfg.Servers {
+		statuses[server.Name] = server.Status
+	}
+	return statuses
+}
+
+func TestProvisionBatchMixedOutcomes(t *testing.T) {
+	names := []string{"web1", "web2", "web3", "web4", "web5"}
+	failing := map[string]bool{"web2": true, "web4": true}
+
+	for _, parallel := range []int{1, 3} {
+		mgr, cfgMgr := newBatchTestManager(t, names...)
+
+		results := mgr.ProvisionBatch(names, parallel, false, func(name string) error {
+			time.Sleep(5 * time.Millisecond)
+			if failing[name] {
+				return errors.New("playbook failed")
+			}
+			return nil
+		})
+
+		if len(results) != len(names) {
+			t.Fatalf("parallel=%d: got %d results, want %d", parallel, len(results), len(names))
+		}
+
+		statuses := serverStatuses(t, cfgMgr)
+		for i, result := range results {
+			if result.Server != names[i] {
+				t.Errorf("parallel=%d: result %d is %q, want %q (input order)", parallel, i, result.Server, names[i])
+			}
+
+			want := BatchProvisioned
+			if failing[result.Server] {
+				want = BatchError
+			}
+			if result.Status != want {
+				t.Errorf("parallel=%d: %s status = %q, want %q", parallel, result.Server, result.Status, want)
+			}
+			if (result.Err != nil) != failing[result.Server] {
+				t.Errorf("parallel=%d: %s err = %v", parallel, result.Server, result.Err)
+			}
+			if statuses[result.Server] != want {
+				t.Errorf("parallel=%d: %s state = %q, want %q", parallel, result.Server, statuses[result.Server], want)
+			}
+		}
+	}
+}
+
+func TestProvisionBatchFailFast(t *testing.T) {
+	names := []string{"web1", "web2", "web3"}
+	mgr, cfgMgr := newBatchTestManager(t, names...)
+
+	var calls []string
+	results := mgr.ProvisionBatch(names, 1, true, func(name string) error {
+		calls = append(calls, name)
+		if name == "web1" {
+			return errors.New("ssh unreachable")
+		}
+		return nil
+	})
+
+	if len(calls) != 1 || calls[0] != "web1" {
+		t.Errorf("expected only web1 to run, got %v", calls)
+	}
+
+	wantStatus := []string{BatchError, BatchSkipped, BatchSkipped}
+	for i, result := range results {
+		if result.Status != wantStatus[i] {
+			t.Errorf("%s status = %q, want %q", result.Server, result.Status, wantStatus[i])
+		}
+	}
+
+	statuses := serverStatuses(t, cfgMgr)
+	want := map[string]string{"web1": "error", "web2": "unprovisioned", "web3": "unprovisioned"}
+	for name, status := range want {
+		if statuses[name] != status {
+			t.Errorf("%s state = %q, want %q", name, statuses[name], status)
+		}
+	}
+}
+
+func TestProvisionBatchBoundsConcurrency(t *testing.T) {
+	names := []string{"a1", "a2", "a3", "a4", "a5", "a6"}
+	mgr, _ := newBatchTestManager(t, names...)
+
+	var mu sync.Mutex
+	running, peak := 0, 0
+	mgr.ProvisionBatch(names, 2, false, func(name string) error {
+		mu.Lock()
+		running++
+		if running > peak {
+			peak = running
+		}
+		mu.Unlock()
+
+		time.Sleep(10 * time.Millisecond)
+
+		mu.Lock()
+		running--
+		mu.Unlock()
+		return nil
+	})
+
+	if peak > 2 {
+		t.Errorf("peak concurrency = %d, want at most 2", peak)
+	}
+}
@@ -0,0 +1,98 @@
+package state
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// GCTarget describes files that `state gc` may remove: regular files
+// directly inside Dir whose name matches Pattern and that are older than
+// MaxAge. Subdirectories and symlinks are never touched. With RemoveDir set,
+// Dir itself is removed once the files in it are gone and it is empty.
+type GCTarget struct {
+	Dir       string
+	Pattern   string
+	MaxAge    time.Duration
+	Reason    string
+	RemoveDir bool
+}
+
+// GCCandidate is a file selected for removal
+type GCCandidate struct {
+	Path    string    `json:"path"`
+	Size    int64     `json:"size"`
+	ModTime time.Time `json:"mod_time"`
+	Reason  string    `json:"reason"`
+
+	// removeDir removes the file's directory too when it is left empty
+	removeDir bool
+}
+
+// FindGarbage returns the files matched by the targets that are older than
+// their target's MaxAge at now, sorted by path
+func FindGarbage(targets []GCTarget, now time.Time) ([]GCCandidate, error) {
+	var candidates []GCCandidate
+	seen := make(map[string]bool)
+
+	for _, target := range targets {
+		if target.Dir == "" || target.Pattern == "" || strings.ContainsRune(target.Pattern, filepath.Separator) {
+			return nil, fmt.Errorf("invalid gc target %q in %q", target.Pattern, target.Dir)
+		}
+
+		matches, err := filepath.Glob(filepath.Join(target.Dir, target.Pattern))
+		if err != nil {
+			return nil, fmt.Errorf("invalid gc pattern %q: %w", target.Pattern, err)
+		}
+
+		for _, path := range matches {
+			if seen[path] {
+				continue
+			}
+			info, err := os.Lstat(path)
+			if err != nil || !info.Mode().IsRegular() {
+				continue
+			}
+			if now.Sub(info.ModTime()) < target.MaxAge {
+				continue
+			}
+			seen[path] = true
+			candidates = append(candidates, GCCandidate{
+				Path:    path,
+				Size:    info.Size(),
+				ModTime: info.ModTime(),
+				Reason:  target.Reason,
+
+				removeDir: target.RemoveDir,
+			})
+		}
+	}
+
+	sort.Slice(candidates, func(i, j int) bool { return candidates[i].Path < candidates[j].Path })
+	return candidates, nil
+}
+
+// RemoveGarbage deletes the candidates and returns the bytes freed. Files
+// that fail to delete are reported in the returned errors; the rest are
+// still removed.
+func RemoveGarbage(candidates []GCCandidate) (int64, []error) {
+	var freed int64
+	var errs []error
+	for _, c := range candidates {
+		if err := os.Remove(c.Path); err != nil {
+			if !os.IsNotExist(err) {
+				errs = append(errs, fmt.Errorf("failed to remove %s: %w", c.Path, err))
+			}
+			continue
+		}
+		freed += c.Size
+		if c.removeDir {
+			// Fails harmlessly when other files are still there
+			os.Remove(filepath.Dir(c.Path))
+		}
+	}
+	return freed, errs
+}
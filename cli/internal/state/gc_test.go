@@ -0,0 +1,102 @@
+package state
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func writeAgedFile(t *testing.T, path string, age time.Duration, now time.Time) {
+	t.Helper()
+	if err := os.WriteFile(path, []byte("data"), 0600); err != nil {
+		t.Fatalf("failed to write %s: %v", path, err)
+	}
+	modTime := now.Add(-age)
+	if err := os.Chtimes(path, modTime, modTime); err != nil {
+		t.Fatalf("failed to set mtime on %s: %v", path, err)
+	}
+}
+
+func TestFindGarbageSelectsByAge(t *testing.T) {
+	dir := t.TempDir()
+	now := time.Now()
+
+	writeAgedFile(t, filepath.Join(dir, "wordsail-web1-old.ini"), 2*time.Hour, now)
+	writeAgedFile(t, filepath.Join(dir, "wordsail-web2-fresh.ini"), 10*time.Minute, now)
+	writeAgedFile(t, filepath.Join(dir, "other-old.ini"), 5*time.Hour, now)
+	writeAgedFile(t, filepath.Join(dir, "wordsail-notes.txt"), 5*time.Hour, now)
+
+	// Directories and symlinks matching the pattern are left alone
+	if err := os.Mkdir(filepath.Join(dir, "wordsail-dir.ini"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	outside := filepath.Join(t.TempDir(), "target")
+	writeAgedFile(t, outside, 5*time.Hour, now)
+	if err := os.Symlink(outside, filepath.Join(dir, "wordsail-link.ini")); err != nil {
+		t.Fatal(err)
+	}
+
+	targets := []GCTarget{{Dir: dir, Pattern: "wordsail-*.ini", MaxAge: time.Hour, Reason: "inventory"}}
+	got, err := FindGarbage(targets, now)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(got) != 1 || filepath.Base(got[0].Path) != "wordsail-web1-old.ini" {
+		t.Fatalf("FindGarbage() = %+v, want only wordsail-web1-old.ini", got)
+	}
+	if got[0].Size != 4 || got[0].Reason != "inventory" {
+		t.Errorf("candidate = %+v", got[0])
+	}
+
+	freed, errs := RemoveGarbage(got)
+	if len(errs) != 0 || freed != 4 {
+		t.Errorf("RemoveGarbage() = %d, %v", freed, errs)
+	}
+	if _, err := os.Stat(got[0].Path); !os.IsNotExist(err) {
+		t.Errorf("expected %s to be removed", got[0].Path)
+	}
+	if _, err := os.Stat(outside); err != nil {
+		t.Errorf("symlink target should be untouched: %v", err)
+	}
+}
+
+func TestFindGarbageRejectsNestedPatterns(t *testing.T) {
+	targets := []GCTarget{{Dir: t.TempDir(), Pattern: "../*.ini", MaxAge: time.Hour}}
+	if _, err := FindGarbage(targets, time.Now()); err == nil {
+		t.Error("expected error for a pattern containing a path separator")
+	}
+}
+
+func TestRemoveGarbageRemovesEmptyDir(t *testing.T) {
+	now := time.Now()
+	orphan := filepath.Join(t.TempDir(), "wordsail-inv-1")
+	busy := filepath.Join(t.TempDir(), "wordsail-inv-2")
+	for _, dir := range []string{orphan, busy} {
+		if err := os.Mkdir(dir, 0700); err != nil {
+			t.Fatal(err)
+		}
+		writeAgedFile(t, filepath.Join(dir, "wordsail-web1.ini"), 2*time.Hour, now)
+	}
+	writeAgedFile(t, filepath.Join(busy, "notes.txt"), 2*time.Hour, now)
+
+	targets := []GCTarget{
+		{Dir: orphan, Pattern: "wordsail-*.ini", MaxAge: time.Hour, RemoveDir: true},
+		{Dir: busy, Pattern: "wordsail-*.ini", MaxAge: time.Hour, RemoveDir: true},
+	}
+	got, err := FindGarbage(targets, now)
+	if err != nil || len(got) != 2 {
+		t.Fatalf("FindGarbage() = %+v, %v", got, err)
+	}
+	if _, errs := RemoveGarbage(got); len(errs) != 0 {
+		t.Fatalf("RemoveGarbage() errors = %v", errs)
+	}
+
+	if _, err := os.Stat(orphan); !os.IsNotExist(err) {
+		t.Errorf("expected the emptied %s to be removed", orphan)
+	}
+	if _, err := os.Stat(filepath.Join(busy, "notes.txt")); err != nil {
+		t.Errorf("a directory with other files should be kept: %v", err)
+	}
+}
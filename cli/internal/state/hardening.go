@@ -0,0 +1,58 @@
+package state
+
+import (
+	"fmt"
+
+	"github.com/wordsail/cli/pkg/models"
+)
+
+// RootLoginSteps are the remote actions used to disable root SSH login.
+// They are injected so the ordering can be exercised without a server.
+type RootLoginSteps struct {
+	// VerifyLogin checks that the server accepts SSH (with sudo) using the given config
+	VerifyLogin func(server models.Server) error
+	// DisableRoot reconfigures sshd; it connects as the non-root user
+	DisableRoot func(server models.Server) error
+}
+
+// DisableRootLogin switches a server over to a non-root SSH user and disables
+// root login. The non-root login is verified before anything is changed on
+// the server, and the stored SSH user is only updated once sshd has been
+// reconfigured, so a failure at any step leaves a working login in the config.
+func (m *Manager) DisableRootLogin(serverName, user string, steps RootLoginSteps) (*models.Server, error) {
+	cfg, err := m.configManager.Load()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load config: %w", err)
+	}
+
+	var server *models.Server
+	for i := range cfg.Servers {
+		if cfg.Servers[i].Name == serverName {
+			server = &cfg.Servers[i]
+			break
+		}
+	}
+	if server == nil {
+		return nil, fmt.Errorf("server not found: %s", serverName)
+	}
+
+	candidate := *server
+	candidate.SSH.User = user
+
+	if err := steps.VerifyLogin(candidate); err != nil {
+		return nil, fmt.Errorf("cannot log in as %s, root login left enabled: %w", user, err)
+	}
+
+	if err := steps.DisableRoot(candidate); err != nil {
+		return nil, fmt.Errorf("failed to disable root login: %w", err)
+	}
+
+	server.SSH.User = user
+	server.RootLoginDisabled = true
+
+	if err := m.configManager.Save(cfg); err != nil {
+		return nil, fmt.Errorf("root login was disabled but the config could not be saved (set ssh.user to %s manually): %w", user, err)
+	}
+
+	return server, nil
+}
@@ -0,0 +1,119 @@
+package state
+
+import (
+	"errors"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/wordsail/cli/internal/config"
+	"github.com/wordsail/cli/pkg/models"
+)
+
+func newTestManager(t *testing.T) (*Manager, *config.Manager) {
+	t.Helper()
+	cfgMgr := config.NewManagerWithPath(filepath.Join(t.TempDir(), "wordsail.yaml"))
+	cfg := &config.Config{
+		Version: "1.0",
+		Servers: []models.Server{{
+			Name:   "prod",
+			IP:     "203.0.113.10",
+			SSH:    models.SSHConfig{User: "root", Port: 22, KeyFile: "/keys/id"},
+			Status: "provisioned",
+		}},
+	}
+	if err := cfgMgr.Save(cfg); err != nil {
+		t.Fatalf("failed to save config: %v", err)
+	}
+	return NewManager(cfgMgr), cfgMgr
+}
+
+func TestDisableRootLogin(t *testing.T) {
+	errVerify := errors.New("permission denied")
+	errDisable := errors.New("playbook failed")
+
+	tests := []struct {
+		name         string
+		verifyErr    error
+		disableErr   error
+		wantErr      error
+		wantCalls    []string
+		wantUser     string
+		wantDisabled bool
+	}{
+		{
+			name:         "verified then disabled",
+			wantCalls:    []string{"verify:wordsail", "disable:wordsail"},
+			wantUser:     "wordsail",
+			wantDisabled: true,
+		},
+		{
+			name:      "verify fails, root untouched",
+			verifyErr: errVerify,
+			wantErr:   errVerify,
+			wantCalls: []string{"verify:wordsail"},
+			wantUser:  "root",
+		},
+		{
+			name:       "disable fails, user unchanged",
+			disableErr: errDisable,
+			wantErr:    errDisable,
+			wantCalls:  []string{"verify:wordsail", "disable:wordsail"},
+			wantUser:   "root",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stateMgr, cfgMgr := newTestManager(t)
+
+			var calls []string
+			steps := RootLoginSteps{
+				VerifyLogin: func(s models.Server) error {
+					calls = append(calls, "verify:"+s.SSH.User)
+					return tt.verifyErr
+				},
+				DisableRoot: func(s models.Server) error {
+					calls = append(calls, "disable:"+s.SSH.User)
+					return tt.disableErr
+				},
+			}
+
+			_, err := stateMgr.DisableRootLogin("prod", "wordsail", steps)
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("DisableRootLogin() error = %v, want %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(calls, tt.wantCalls) {
+				t.Errorf("calls = %v, want %v", calls, tt.wantCalls)
+			}
+
+			cfg, err := cfgMgr.Load()
+			if err != nil {
+				t.Fatalf("failed to load config: %v", err)
+			}
+			server := cfg.Servers[0]
+			if server.SSH.User != tt.wantUser {
+				t.Errorf("stored SSH user = %s, want %s", server.SSH.User, tt.wantUser)
+			}
+			if server.RootLoginDisabled != tt.wantDisabled {
+				t.Errorf("RootLoginDisabled = %v, want %v", server.RootLoginDisabled, tt.wantDisabled)
+			}
+		})
+	}
+}
+
+func TestDisableRootLoginUnknownServer(t *testing.T) {
+	stateMgr, _ := newTestManager(t)
+	called := false
+	steps := RootLoginSteps{
+		VerifyLogin: func(models.Server) error { called = true; return nil },
+		DisableRoot: func(models.Server) error { called = true; return nil },
+	}
+
+	if _, err := stateMgr.DisableRootLogin("missing", "wordsail", steps); err == nil {
+		t.Error("DisableRootLogin() expected error for unknown server")
+	}
+	if called {
+		t.Error("no remote step should run for an unknown server")
+	}
+}
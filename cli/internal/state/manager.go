@@ -2,15 +2,20 @@ package state
 
 import (
 	"fmt"
+	"sort"
+	"sync"
 	"time"
 
 	"github.com/wordsail/cli/internal/config"
+	"github.com/wordsail/cli/internal/utils"
 	"github.com/wordsail/cli/pkg/models"
 )
 
-// Manager handles state updates to the configuration
+// Manager handles state updates to the configuration. Updates are
+// serialized so concurrent provisioning runs don't overwrite each other.
 type Manager struct {
 	configManager *config.Manager
+	mu            sync.Mutex
 }
 
 // NewManager creates a new state manager
@@ -22,6 +27,9 @@ func NewManager(configManager *config.Manager) *Manager {
 
 // MarkServerProvisioned updates a server's status to provisioned
 func (m *Manager) MarkServerProvisioned(serverName string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
 	// Load current config
 	cfg, err := m.configManager.Load()
 	if err != nil {
@@ -54,6 +62,9 @@ func (m *Manager) MarkServerProvisioned(serverName string) error {
 
 // MarkServerError updates a server's status to error
 func (m *Manager) MarkServerError(serverName string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
 	// Load current config
 	cfg, err := m.configManager.Load()
 	if err != nil {
@@ -82,6 +93,36 @@ func (m *Manager) MarkServerError(serverName string) error {
 	return nil
 }
 
+// SetServerDatabase records the database engine and version a server was
+// provisioned with
+func (m *Manager) SetServerDatabase(serverName string, database models.DatabaseEngine) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	cfg, err := m.configManager.Load()
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+
+	found := false
+	for i := range cfg.Servers {
+		if cfg.Servers[i].Name == serverName {
+			cfg.Servers[i].Database = database
+			found = true
+			break
+		}
+	}
+
+	if !found {
+		return fmt.Errorf("server not found: %s", serverName)
+	}
+
+	if err := m.configManager.Save(cfg); err != nil {
+		return fmt.Errorf("failed to save config: %w", err)
+	}
+	return nil
+}
+
 // GetServer retrieves a server by name
 func (m *Manager) GetServer(serverName string) (*models.Server, error) {
 	cfg, err := m.configManager.Load()
@@ -98,8 +139,13 @@ func (m *Manager) GetServer(serverName string) (*models.Server, error) {
 	return nil, fmt.Errorf("server not found: %s", serverName)
 }
 
-// AddSiteToServer adds a site to a server's configuration
+// AddSiteToServer adds a site to a server's configuration. A site with the
+// same site ID is replaced rather than duplicated, so retried or re-created
+// sites (and replayed pending changes) leave a single record.
 func (m *Manager) AddSiteToServer(serverName string, site models.Site) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
 	cfg, err := m.configManager.Load()
 	if err != nil {
 		return fmt.Errorf("failed to load config: %w", err)
@@ -108,7 +154,11 @@ func (m *Manager) AddSiteToServer(serverName string, site models.Site) error {
 	found := false
 	for i := range cfg.Servers {
 		if cfg.Servers[i].Name == serverName {
-			cfg.Servers[i].Sites = append(cfg.Servers[i].Sites, site)
+			if j := utils.FindSiteIndexBySiteID(&cfg.Servers[i], site.SiteID); j >= 0 {
+				cfg.Servers[i].Sites[j] = site
+			} else {
+				cfg.Servers[i].Sites = append(cfg.Servers[i].Sites, site)
+			}
 			found = true
 			break
 		}
@@ -127,6 +177,9 @@ func (m *Manager) AddSiteToServer(serverName string, site models.Site) error {
 
 // RemoveSiteFromServer removes a site from a server's configuration
 func (m *Manager) RemoveSiteFromServer(serverName string, siteID string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
 	cfg, err := m.configManager.Load()
 	if err != nil {
 		return fmt.Errorf("failed to load config: %w", err)
@@ -162,6 +215,9 @@ func (m *Manager) RemoveSiteFromServer(serverName string, siteID string) error {
 
 // AddDomainToSite adds a domain to a site's configuration
 func (m *Manager) AddDomainToSite(serverName string, siteID string, domain models.Domain) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
 	cfg, err := m.configManager.Load()
 	if err != nil {
 		return fmt.Errorf("failed to load config: %w", err)
@@ -194,6 +250,9 @@ func (m *Manager) AddDomainToSite(serverName string, siteID string, domain model
 
 // RemoveDomainFromSite removes a domain from a site's configuration
 func (m *Manager) RemoveDomainFromSite(serverName string, siteID string, domainName string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
 	cfg, err := m.configManager.Load()
 	if err != nil {
 		return fmt.Errorf("failed to load config: %w", err)
@@ -234,6 +293,9 @@ func (m *Manager) RemoveDomainFromSite(serverName string, siteID string, domainN
 
 // UpdateDomainSSL updates a domain's SSL information
 func (m *Manager) UpdateDomainSSL(serverName string, siteID string, domainName string, updatedDomain models.Domain) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
 	cfg, err := m.configManager.Load()
 	if err != nil {
 		return fmt.Errorf("failed to load config: %w", err)
@@ -246,7 +308,11 @@ func (m *Manager) UpdateDomainSSL(serverName string, siteID string, domainName s
 				if cfg.Servers[i].Sites[j].SiteID == siteID {
 					for k := range cfg.Servers[i].Sites[j].Domains {
 						if cfg.Servers[i].Sites[j].Domains[k].Domain == domainName {
-							cfg.Servers[i].Sites[j].Domains[k] = updatedDomain
+							// Only the SSL fields change; anything else (like a redirect) is kept
+							d := &cfg.Servers[i].Sites[j].Domains[k]
+							d.SSLEnabled = updatedDomain.SSLEnabled
+							d.SSLIssuedAt = updatedDomain.SSLIssuedAt
+							d.SSLExpiresAt = updatedDomain.SSLExpiresAt
 							found = true
 							break
 						}
@@ -268,3 +334,320 @@ func (m *Manager) UpdateDomainSSL(serverName string, siteID string, domainName s
 
 	return nil
 }
+
+// SetSiteMaintenance records whether a site is in maintenance mode
+func (m *Manager) SetSiteMaintenance(serverName string, siteID string, enabled bool) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	cfg, err := m.configManager.Load()
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+
+	found := false
+	for i := range cfg.Servers {
+		if cfg.Servers[i].Name == serverName {
+			for j := range cfg.Servers[i].Sites {
+				if cfg.Servers[i].Sites[j].SiteID == siteID {
+					cfg.Servers[i].Sites[j].MaintenanceMode = enabled
+					found = true
+					break
+				}
+			}
+			break
+		}
+	}
+
+	if !found {
+		return fmt.Errorf("site '%s' not found on server '%s'", siteID, serverName)
+	}
+
+	if err := m.configManager.Save(cfg); err != nil {
+		return fmt.Errorf("failed to save config: %w", err)
+	}
+
+	return nil
+}
+
+// SetSiteEnabled records whether a site's Nginx vhosts are enabled. Enabled
+// sites drop the field so the config only marks the disabled ones.
+func (m *Manager) SetSiteEnabled(serverName string, siteID string, enabled bool) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	cfg, err := m.configManager.Load()
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+
+	found := false
+	for i := range cfg.Servers {
+		if cfg.Servers[i].Name == serverName {
+			for j := range cfg.Servers[i].Sites {
+				if cfg.Servers[i].Sites[j].SiteID == siteID {
+					cfg.Servers[i].Sites[j].Enabled = nil
+					if !enabled {
+						cfg.Servers[i].Sites[j].Enabled = &enabled
+					}
+					found = true
+					break
+				}
+			}
+			break
+		}
+	}
+
+	if !found {
+		return fmt.Errorf("site '%s' not found on server '%s'", siteID, serverName)
+	}
+
+	if err := m.configManager.Save(cfg); err != nil {
+		return fmt.Errorf("failed to save config: %w", err)
+	}
+
+	return nil
+}
+
+// SetSitePHPExtension records an optional PHP extension as enabled or
+// disabled for a site. The list is kept sorted and free of duplicates.
+func (m *Manager) SetSitePHPExtension(serverName string, siteID string, extension string, enabled bool) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	cfg, err := m.configManager.Load()
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+
+	found := false
+	for i := range cfg.Servers {
+		if cfg.Servers[i].Name == serverName {
+			for j := range cfg.Servers[i].Sites {
+				if cfg.Servers[i].Sites[j].SiteID == siteID {
+					site := &cfg.Servers[i].Sites[j]
+					extensions := make([]string, 0, len(site.PHPExtensions)+1)
+					for _, ext := range site.PHPExtensions {
+						if ext != extension {
+							extensions = append(extensions, ext)
+						}
+					}
+					if enabled {
+						extensions = append(extensions, extension)
+					}
+					sort.Strings(extensions)
+					site.PHPExtensions = extensions
+					found = true
+					break
+				}
+			}
+			break
+		}
+	}
+
+	if !found {
+		return fmt.Errorf("site '%s' not found on server '%s'", siteID, serverName)
+	}
+
+	if err := m.configManager.Save(cfg); err != nil {
+		return fmt.Errorf("failed to save config: %w", err)
+	}
+
+	return nil
+}
+
+// SetSitePrimaryDomain makes an attached domain the site's primary domain
+func (m *Manager) SetSitePrimaryDomain(serverName string, siteID string, domain string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	cfg, err := m.configManager.Load()
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+
+	found := false
+	for i := range cfg.Servers {
+		if cfg.Servers[i].Name == serverName {
+			for j := range cfg.Servers[i].Sites {
+				if cfg.Servers[i].Sites[j].SiteID == siteID {
+					for _, d := range cfg.Servers[i].Sites[j].Domains {
+						if d.Domain == domain {
+							cfg.Servers[i].Sites[j].PrimaryDomain = domain
+							found = true
+							break
+						}
+					}
+					break
+				}
+			}
+			break
+		}
+	}
+
+	if !found {
+		return fmt.Errorf("domain '%s' not found on site '%s' on server '%s'", domain, siteID, serverName)
+	}
+
+	if err := m.configManager.Save(cfg); err != nil {
+		return fmt.Errorf("failed to save config: %w", err)
+	}
+
+	return nil
+}
+
+// SetSiteAdminUser records the WordPress login of a site's admin account
+func (m *Manager) SetSiteAdminUser(serverName string, siteID string, adminUser string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	cfg, err := m.configManager.Load()
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+
+	found := false
+	for i := range cfg.Servers {
+		if cfg.Servers[i].Name == serverName {
+			for j := range cfg.Servers[i].Sites {
+				if cfg.Servers[i].Sites[j].SiteID == siteID {
+					cfg.Servers[i].Sites[j].AdminUser = adminUser
+					found = true
+					break
+				}
+			}
+			break
+		}
+	}
+
+	if !found {
+		return fmt.Errorf("site '%s' not found on server '%s'", siteID, serverName)
+	}
+
+	if err := m.configManager.Save(cfg); err != nil {
+		return fmt.Errorf("failed to save config: %w", err)
+	}
+
+	return nil
+}
+
+// SetSiteDatabasePassword stores a site's rotated database password, already
+// encrypted with config.EncryptSecret
+func (m *Manager) SetSiteDatabasePassword(serverName string, siteID string, encryptedPassword string, rotatedAt time.Time) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	cfg, err := m.configManager.Load()
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+
+	found := false
+	for i := range cfg.Servers {
+		if cfg.Servers[i].Name == serverName {
+			for j := range cfg.Servers[i].Sites {
+				if cfg.Servers[i].Sites[j].SiteID == siteID {
+					cfg.Servers[i].Sites[j].Database.EncryptedPassword = encryptedPassword
+					cfg.Servers[i].Sites[j].Database.PasswordRotatedAt = &rotatedAt
+					found = true
+					break
+				}
+			}
+			break
+		}
+	}
+
+	if !found {
+		return fmt.Errorf("site '%s' not found on server '%s'", siteID, serverName)
+	}
+
+	if err := m.configManager.Save(cfg); err != nil {
+		return fmt.Errorf("failed to save config: %w", err)
+	}
+
+	return nil
+}
+
+// SetDomainRedirect records (or, with an empty target, clears) a domain's
+// redirect target
+func (m *Manager) SetDomainRedirect(serverName string, siteID string, domainName string, redirectTo string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	cfg, err := m.configManager.Load()
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+
+	found := false
+	for i := range cfg.Servers {
+		if cfg.Servers[i].Name == serverName {
+			for j := range cfg.Servers[i].Sites {
+				if cfg.Servers[i].Sites[j].SiteID == siteID {
+					for k := range cfg.Servers[i].Sites[j].Domains {
+						if cfg.Servers[i].Sites[j].Domains[k].Domain == domainName {
+							cfg.Servers[i].Sites[j].Domains[k].RedirectTo = redirectTo
+							found = true
+							break
+						}
+					}
+					break
+				}
+			}
+			break
+		}
+	}
+
+	if !found {
+		return fmt.Errorf("domain '%s' not found on site '%s' on server '%s'", domainName, siteID, serverName)
+	}
+
+	if err := m.configManager.Save(cfg); err != nil {
+		return fmt.Errorf("failed to save config: %w", err)
+	}
+
+	return nil
+}
+
+// RenameServer changes a server's name. The new name must be hostname-safe
+// and not already in use.
+func (m *Manager) RenameServer(oldName string, newName string) error {
+	if err := utils.ValidateServerName(newName); err != nil {
+		return err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	cfg, err := m.configManager.Load()
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+
+	found := false
+	for i := range cfg.Servers {
+		switch cfg.Servers[i].Name {
+		case newName:
+			return fmt.Errorf("server with name '%s' already exists", newName)
+		case oldName:
+			found = true
+		}
+	}
+
+	if !found {
+		return fmt.Errorf("server not found: %s", oldName)
+	}
+
+	for i := range cfg.Servers {
+		if cfg.Servers[i].Name == oldName {
+			cfg.Servers[i].Name = newName
+			break
+		}
+	}
+
+	if err := m.configManager.Save(cfg); err != nil {
+		return fmt.Errorf("failed to save config: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,84 @@
+package state
+
+import (
+	"testing"
+
+	"github.com/wordsail/cli/pkg/models"
+)
+
+func TestAddSiteToServerReplacesSameSiteID(t *testing.T) {
+	stateMgr, _ := newTestManager(t)
+
+	first := models.Site{SiteID: "examplecom", PrimaryDomain: "example.com", PHPVersion: "8.2"}
+	second := models.Site{SiteID: "examplecom", PrimaryDomain: "example.com", PHPVersion: "8.3"}
+	other := models.Site{SiteID: "blog", PrimaryDomain: "blog.example.com"}
+
+	for _, site := range []models.Site{first, other, second} {
+		if err := stateMgr.AddSiteToServer("prod", site); err != nil {
+			t.Fatalf("AddSiteToServer(%s) error = %v", site.SiteID, err)
+		}
+	}
+
+	server, err := stateMgr.GetServer("prod")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(server.Sites) != 2 {
+		t.Fatalf("sites = %+v, want examplecom and blog once each", server.Sites)
+	}
+	if server.Sites[0].SiteID != "examplecom" || server.Sites[0].PHPVersion != "8.3" {
+		t.Errorf("first site = %+v, want the replaced examplecom record in place", server.Sites[0])
+	}
+
+	if err := stateMgr.AddSiteToServer("missing", other); err == nil {
+		t.Error("AddSiteToServer() to an unknown server should fail")
+	}
+}
+
+func TestSetSiteEnabled(t *testing.T) {
+	stateMgr, _ := newTestManager(t)
+	if err := stateMgr.AddSiteToServer("prod", models.Site{SiteID: "examplecom", PrimaryDomain: "example.com"}); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, enabled := range []bool{false, true} {
+		if err := stateMgr.SetSiteEnabled("prod", "examplecom", enabled); err != nil {
+			t.Fatalf("SetSiteEnabled(%v) error = %v", enabled, err)
+		}
+		server, err := stateMgr.GetServer("prod")
+		if err != nil {
+			t.Fatal(err)
+		}
+		site := server.Sites[0]
+		if site.IsEnabled() != enabled {
+			t.Errorf("after SetSiteEnabled(%v) IsEnabled() = %v", enabled, site.IsEnabled())
+		}
+		if enabled && site.Enabled != nil {
+			t.Errorf("enabled site keeps Enabled = %v, want it dropped from the config", *site.Enabled)
+		}
+	}
+
+	if err := stateMgr.SetSiteEnabled("prod", "missing", false); err == nil {
+		t.Error("SetSiteEnabled() on an unknown site should fail")
+	}
+}
+
+func TestSetServerDatabase(t *testing.T) {
+	stateMgr, _ := newTestManager(t)
+
+	database := models.DatabaseEngine{Engine: "mariadb", Version: "10.11"}
+	if err := stateMgr.SetServerDatabase("prod", database); err != nil {
+		t.Fatalf("SetServerDatabase() error = %v", err)
+	}
+	server, err := stateMgr.GetServer("prod")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if server.Database != database {
+		t.Errorf("Database = %+v, want %+v", server.Database, database)
+	}
+
+	if err := stateMgr.SetServerDatabase("missing", database); err == nil {
+		t.Error("SetServerDatabase() on an unknown server should fail")
+	}
+}
@@ -0,0 +1,190 @@
+package state
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/wordsail/cli/pkg/models"
+	"gopkg.in/yaml.v3"
+)
+
+// MigrationsDir is the directory, next to the config file, holding one
+// progress file per unfinished site migration
+const MigrationsDir = "migrations"
+
+// Site migration steps, in the order they run
+const (
+	MigrateStepProvision     = "provision"
+	MigrateStepFiles         = "files"
+	MigrateStepDatabase      = "database"
+	MigrateStepConfigure     = "configure"
+	MigrateStepExtras        = "extras"
+	MigrateStepSearchReplace = "search-replace"
+	MigrateStepVerify        = "verify"
+	MigrateStepState         = "state"
+	MigrateStepCleanup       = "cleanup"
+)
+
+// MigrateSteps lists the site migration steps in order
+var MigrateSteps = []string{
+	MigrateStepProvision,
+	MigrateStepFiles,
+	MigrateStepDatabase,
+	MigrateStepConfigure,
+	MigrateStepExtras,
+	MigrateStepSearchReplace,
+	MigrateStepVerify,
+	MigrateStepState,
+	MigrateStepCleanup,
+}
+
+// SiteMigration records how far a site migration got, so a failed run can
+// be resumed without repeating the steps that finished
+type SiteMigration struct {
+	path       string
+	SiteID     string    `yaml:"site_id"`
+	FromServer string    `yaml:"from_server"`
+	ToServer   string    `yaml:"to_server"`
+	StartedAt  time.Time `yaml:"started_at"`
+	Completed  []string  `yaml:"completed,omitempty"`
+
+	// DBPassword is the target database password, encrypted with
+	// config.EncryptSecret, so a resumed run uses the same one
+	DBPassword string `yaml:"db_password"`
+	LastError  string `yaml:"last_error,omitempty"`
+}
+
+// LoadSiteMigration reads a migration progress file. It returns nil when
+// there is no unfinished migration.
+func LoadSiteMigration(path string) (*SiteMigration, error) {
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read migration progress: %w", err)
+	}
+	m := &SiteMigration{path: path}
+	if err := yaml.Unmarshal(data, m); err != nil {
+		return nil, fmt.Errorf("failed to parse migration progress %s: %w", path, err)
+	}
+	return m, nil
+}
+
+// NewSiteMigration starts a migration progress file at path
+func NewSiteMigration(path, siteID, fromServer, toServer, encryptedDBPassword string) *SiteMigration {
+	return &SiteMigration{
+		path:       path,
+		SiteID:     siteID,
+		FromServer: fromServer,
+		ToServer:   toServer,
+		StartedAt:  time.Now(),
+		DBPassword: encryptedDBPassword,
+	}
+}
+
+// Done reports whether a step has already completed
+func (m *SiteMigration) Done(step string) bool {
+	for _, done := range m.Completed {
+		if done == step {
+			return true
+		}
+	}
+	return false
+}
+
+// Complete marks a step as done and saves the progress
+func (m *SiteMigration) Complete(step string) error {
+	if !m.Done(step) {
+		m.Completed = append(m.Completed, step)
+	}
+	m.LastError = ""
+	return m.Save()
+}
+
+// Fail records the error that stopped the migration
+func (m *SiteMigration) Fail(err error) error {
+	m.LastError = err.Error()
+	return m.Save()
+}
+
+// Save writes the progress file
+func (m *SiteMigration) Save() error {
+	data, err := yaml.Marshal(m)
+	if err != nil {
+		return fmt.Errorf("failed to marshal migration progress: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(m.path), 0700); err != nil {
+		return fmt.Errorf("failed to create migrations directory: %w", err)
+	}
+	if err := os.WriteFile(m.path, data, 0600); err != nil {
+		return fmt.Errorf("failed to write migration progress: %w", err)
+	}
+	return nil
+}
+
+// Remove deletes the progress file once the migration has finished
+func (m *SiteMigration) Remove() error {
+	if err := os.Remove(m.path); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to remove migration progress: %w", err)
+	}
+	return nil
+}
+
+// MoveSite replaces a site's record on one server with the given record on
+// another in a single config write, so the site is never listed on both or
+// neither
+func (m *Manager) MoveSite(fromServer string, toServer string, site models.Site) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	cfg, err := m.configManager.Load()
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+
+	from, to := -1, -1
+	for i := range cfg.Servers {
+		switch cfg.Servers[i].Name {
+		case fromServer:
+			from = i
+		case toServer:
+			to = i
+		}
+	}
+	if from < 0 {
+		return fmt.Errorf("server not found: %s", fromServer)
+	}
+	if to < 0 {
+		return fmt.Errorf("server not found: %s", toServer)
+	}
+	for _, existing := range cfg.Servers[to].Sites {
+		if existing.SiteID == site.SiteID {
+			return fmt.Errorf("site '%s' already exists on server '%s'", site.SiteID, toServer)
+		}
+	}
+
+	found := false
+	remaining := make([]models.Site, 0, len(cfg.Servers[from].Sites))
+	for _, existing := range cfg.Servers[from].Sites {
+		if existing.SiteID == site.SiteID {
+			found = true
+			continue
+		}
+		remaining = append(remaining, existing)
+	}
+	if !found {
+		return fmt.Errorf("site '%s' not found on server '%s'", site.SiteID, fromServer)
+	}
+
+	cfg.Servers[from].Sites = remaining
+	cfg.Servers[to].Sites = append(cfg.Servers[to].Sites, site)
+
+	if err := m.configManager.Save(cfg); err != nil {
+		return fmt.Errorf("failed to save config: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,98 @@
+package state
+
+import (
+	"errors"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/wordsail/cli/pkg/models"
+)
+
+func TestSiteMigrationProgress(t *testing.T) {
+	path := filepath.Join(t.TempDir(), MigrationsDir, "examplecom.yaml")
+
+	if m, err := LoadSiteMigration(path); err != nil || m != nil {
+		t.Fatalf("LoadSiteMigration() without a file = %v, %v", m, err)
+	}
+
+	m := NewSiteMigration(path, "examplecom", "prod", "prod-2", "enc:v1:x")
+	if err := m.Complete(MigrateStepProvision); err != nil {
+		t.Fatalf("Complete() error = %v", err)
+	}
+	if err := m.Fail(errors.New("rsync died")); err != nil {
+		t.Fatal(err)
+	}
+
+	loaded, err := LoadSiteMigration(path)
+	if err != nil || loaded == nil {
+		t.Fatalf("LoadSiteMigration() = %v, %v", loaded, err)
+	}
+	if !loaded.Done(MigrateStepProvision) || loaded.Done(MigrateStepFiles) {
+		t.Errorf("completed steps = %v", loaded.Completed)
+	}
+	if loaded.FromServer != "prod" || loaded.ToServer != "prod-2" || loaded.DBPassword != "enc:v1:x" || loaded.LastError != "rsync died" {
+		t.Errorf("loaded migration = %+v", loaded)
+	}
+
+	if err := loaded.Complete(MigrateStepFiles); err != nil {
+		t.Fatal(err)
+	}
+	if err := loaded.Complete(MigrateStepFiles); err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{MigrateStepProvision, MigrateStepFiles}; !reflect.DeepEqual(loaded.Completed, want) || loaded.LastError != "" {
+		t.Errorf("after Complete() completed = %v, last error = %q", loaded.Completed, loaded.LastError)
+	}
+
+	if err := loaded.Remove(); err != nil {
+		t.Fatal(err)
+	}
+	if m, _ := LoadSiteMigration(path); m != nil {
+		t.Error("progress file should be gone after Remove()")
+	}
+}
+
+func TestMoveSite(t *testing.T) {
+	stateMgr, cfgMgr := newTestManager(t)
+	cfg, err := cfgMgr.Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	site := models.Site{
+		SiteID:        "examplecom",
+		PrimaryDomain: "example.com",
+		AdminUser:     "admin",
+		AdminEmail:    "admin@example.com",
+		CreatedAt:     time.Now(),
+		Database:      models.Database{Name: "examplecom", User: "examplecom", Host: "localhost"},
+	}
+	cfg.Servers[0].Sites = []models.Site{site, {SiteID: "othercom", PrimaryDomain: "other.com"}}
+	cfg.Servers = append(cfg.Servers, models.Server{Name: "prod-2", IP: "203.0.113.11", Status: "provisioned"})
+	if err := cfgMgr.Save(cfg); err != nil {
+		t.Fatal(err)
+	}
+
+	moved := site
+	moved.Database.EncryptedPassword = "enc:v1:new"
+	if err := stateMgr.MoveSite("prod", "prod-2", moved); err != nil {
+		t.Fatalf("MoveSite() error = %v", err)
+	}
+
+	from, _ := stateMgr.GetServer("prod")
+	to, _ := stateMgr.GetServer("prod-2")
+	if len(from.Sites) != 1 || from.Sites[0].SiteID != "othercom" {
+		t.Errorf("source sites = %+v", from.Sites)
+	}
+	if len(to.Sites) != 1 || to.Sites[0].Database.EncryptedPassword != "enc:v1:new" {
+		t.Errorf("target sites = %+v", to.Sites)
+	}
+
+	if err := stateMgr.MoveSite("prod", "prod-2", moved); err == nil {
+		t.Error("moving a site that is already on the target should fail")
+	}
+	if err := stateMgr.MoveSite("prod-2", "missing", moved); err == nil {
+		t.Error("moving to an unknown server should fail")
+	}
+}
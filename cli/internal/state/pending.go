@@ -0,0 +1,152 @@
+package state
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/wordsail/cli/pkg/models"
+	"gopkg.in/yaml.v3"
+)
+
+// PendingFile is the suffix of the file, next to the config file and named
+// after it, holding state changes that could not be written after the server
+// was already changed
+const PendingFile = "pending.yaml"
+
+// Kinds of pending change
+const (
+	PendingAddSite   = "add_site"
+	PendingDomainSSL = "domain_ssl"
+)
+
+// PendingChange is a config update that failed to save. It is applied later
+// by config reconcile.
+type PendingChange struct {
+	Kind       string         `yaml:"kind" json:"kind"`
+	Server     string         `yaml:"server" json:"server"`
+	SiteID     string         `yaml:"site_id,omitempty" json:"site_id,omitempty"`
+	Domain     string         `yaml:"domain,omitempty" json:"domain,omitempty"`
+	Site       *models.Site   `yaml:"site,omitempty" json:"site,omitempty"`
+	DomainSSL  *models.Domain `yaml:"domain_ssl,omitempty" json:"domain_ssl,omitempty"`
+	Error      string         `yaml:"error" json:"error"`
+	RecordedAt time.Time      `yaml:"recorded_at" json:"recorded_at"`
+}
+
+// PendingChanges is the contents of the pending file
+type PendingChanges struct {
+	path    string
+	Changes []PendingChange `yaml:"changes"`
+}
+
+// LoadPending reads the pending file. A missing file has no changes.
+func LoadPending(path string) (*PendingChanges, error) {
+	p := &PendingChanges{path: path}
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return p, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read pending changes: %w", err)
+	}
+	if err := yaml.Unmarshal(data, p); err != nil {
+		return nil, fmt.Errorf("failed to parse pending changes %s: %w", path, err)
+	}
+	return p, nil
+}
+
+// Save writes the pending file, removing it once there is nothing left
+func (p *PendingChanges) Save() error {
+	if len(p.Changes) == 0 {
+		if err := os.Remove(p.path); err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("failed to remove pending changes: %w", err)
+		}
+		return nil
+	}
+
+	data, err := yaml.Marshal(p)
+	if err != nil {
+		return fmt.Errorf("failed to marshal pending changes: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(p.path), 0755); err != nil {
+		return fmt.Errorf("failed to create pending changes directory: %w", err)
+	}
+	if err := os.WriteFile(p.path, data, 0600); err != nil {
+		return fmt.Errorf("failed to write pending changes: %w", err)
+	}
+	return nil
+}
+
+// RecordPending appends a change to the pending file
+func RecordPending(path string, change PendingChange) error {
+	p, err := LoadPending(path)
+	if err != nil {
+		return err
+	}
+	p.Changes = append(p.Changes, change)
+	return p.Save()
+}
+
+// ApplyPending writes a pending change to the config
+func (m *Manager) ApplyPending(change PendingChange) error {
+	switch change.Kind {
+	case PendingAddSite:
+		if change.Site == nil {
+			return fmt.Errorf("pending %s change for server '%s' has no site", change.Kind, change.Server)
+		}
+		// The site may have been added by hand since
+		server, err := m.GetServer(change.Server)
+		if err != nil {
+			return err
+		}
+		for _, site := range server.Sites {
+			if site.SiteID == change.Site.SiteID {
+				return nil
+			}
+		}
+		return m.AddSiteToServer(change.Server, *change.Site)
+	case PendingDomainSSL:
+		if change.DomainSSL == nil {
+			return fmt.Errorf("pending %s change for '%s' has no SSL details", change.Kind, change.Domain)
+		}
+		return m.UpdateDomainSSL(change.Server, change.SiteID, change.Domain, *change.DomainSSL)
+	default:
+		return fmt.Errorf("unknown pending change kind '%s'", change.Kind)
+	}
+}
+
+// Reconcile applies every pending change in order. Changes that still fail
+// are kept in the file; the applied and remaining changes are returned.
+func (m *Manager) Reconcile(pending *PendingChanges) (applied []PendingChange, failed []PendingChange, err error) {
+	for _, change := range pending.Changes {
+		if applyErr := m.ApplyPending(change); applyErr != nil {
+			change.Error = applyErr.Error()
+			failed = append(failed, change)
+			continue
+		}
+		applied = append(applied, change)
+	}
+
+	pending.Changes = failed
+	return applied, failed, pending.Save()
+}
+
+// RenamePendingServer points pending changes for a renamed server at its new name
+func RenamePendingServer(path string, oldName string, newName string) error {
+	p, err := LoadPending(path)
+	if err != nil {
+		return err
+	}
+	changed := false
+	for i := range p.Changes {
+		if p.Changes[i].Server == oldName {
+			p.Changes[i].Server = newName
+			changed = true
+		}
+	}
+	if !changed {
+		return nil
+	}
+	return p.Save()
+}
@@ -0,0 +1,88 @@
+package state
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/wordsail/cli/pkg/models"
+)
+
+func TestReconcile(t *testing.T) {
+	m, cfgMgr := newTestManager(t)
+	path := filepath.Join(t.TempDir(), PendingFile)
+
+	expires := time.Date(2026, 12, 1, 0, 0, 0, 0, time.UTC)
+	site := models.Site{
+		SiteID:        "blog",
+		PrimaryDomain: "blog.example.com",
+		Domains:       []models.Domain{{Domain: "blog.example.com"}},
+	}
+	changes := []PendingChange{
+		{Kind: PendingAddSite, Server: "prod", SiteID: "blog", Site: &site},
+		{Kind: PendingDomainSSL, Server: "prod", SiteID: "blog", Domain: "blog.example.com",
+			DomainSSL: &models.Domain{Domain: "blog.example.com", SSLEnabled: true, SSLExpiresAt: &expires}},
+		{Kind: PendingDomainSSL, Server: "prod", SiteID: "shop", Domain: "shop.example.com",
+			DomainSSL: &models.Domain{Domain: "shop.example.com", SSLEnabled: true}},
+	}
+	for _, change := range changes {
+		if err := RecordPending(path, change); err != nil {
+			t.Fatalf("RecordPending() error = %v", err)
+		}
+	}
+
+	pending, err := LoadPending(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(pending.Changes) != 3 {
+		t.Fatalf("loaded %d pending change(s), want 3", len(pending.Changes))
+	}
+
+	applied, failed, err := m.Reconcile(pending)
+	if err != nil {
+		t.Fatalf("Reconcile() error = %v", err)
+	}
+	if len(applied) != 2 || len(failed) != 1 {
+		t.Fatalf("Reconcile() applied %d and failed %d, want 2 and 1", len(applied), len(failed))
+	}
+	if failed[0].Error == "" {
+		t.Error("failed change should carry its error")
+	}
+
+	cfg, err := cfgMgr.Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	sites := cfg.Servers[0].Sites
+	if len(sites) != 1 || !sites[0].Domains[0].SSLEnabled {
+		t.Fatalf("pending changes not applied: %+v", sites)
+	}
+
+	remaining, err := LoadPending(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(remaining.Changes) != 1 || remaining.Changes[0].SiteID != "shop" {
+		t.Fatalf("remaining pending changes = %+v, want only the shop change", remaining.Changes)
+	}
+
+	// Re-applying an added site is a no-op
+	if err := m.ApplyPending(changes[0]); err != nil {
+		t.Fatalf("ApplyPending() on an existing site error = %v", err)
+	}
+	cfg, _ = cfgMgr.Load()
+	if len(cfg.Servers[0].Sites) != 1 {
+		t.Errorf("site was added twice")
+	}
+
+	// Once nothing is pending the file is removed
+	remaining.Changes = nil
+	if err := remaining.Save(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Errorf("pending file should be removed when empty, stat error = %v", err)
+	}
+}
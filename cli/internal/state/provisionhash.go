@@ -0,0 +1,118 @@
+package state
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+
+	"github.com/wordsail/cli/internal/utils"
+	"github.com/wordsail/cli/pkg/models"
+)
+
+// provisionSources are the parts of the Ansible directory that a
+// provisioning run reads
+var provisionSources = []string{"provision.yml", "ansible.cfg", "group_vars", "roles"}
+
+// ProvisionHash returns a digest of everything that decides the outcome of a
+// provisioning run: the extra vars, the server's addresses and the playbook
+// version. Vars are compared by their string form, as the inventory writes
+// them, so 8 and "8" hash the same.
+func ProvisionHash(vars map[string]interface{}, server models.Server, playbookVersion string) string {
+	normalized := make(map[string]string, len(vars))
+	for key, val := range vars {
+		normalized[key] = fmt.Sprintf("%v", val)
+	}
+
+	// encoding/json writes map keys in sorted order, so the digest does not
+	// depend on map iteration order
+	data, _ := json.Marshal(struct {
+		Vars     map[string]string `json:"vars"`
+		Address  string            `json:"address"`
+		IPv6     string            `json:"ipv6"`
+		Playbook string            `json:"playbook"`
+	}{normalized, server.Address(), server.IPv6, playbookVersion})
+
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// PlaybookVersion returns a digest of provision.yml and the roles and vars it
+// uses under ansiblePath, so edits to the playbooks count as a change
+func PlaybookVersion(ansiblePath string) (string, error) {
+	ansiblePath, err := utils.ExpandPath(ansiblePath)
+	if err != nil {
+		return "", err
+	}
+
+	h := sha256.New()
+	for _, source := range provisionSources {
+		root := filepath.Join(ansiblePath, source)
+		err := filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+			if err != nil {
+				if os.IsNotExist(err) && path == root {
+					return nil
+				}
+				return err
+			}
+			if !d.Type().IsRegular() {
+				return nil
+			}
+
+			rel, err := filepath.Rel(ansiblePath, path)
+			if err != nil {
+				return err
+			}
+			f, err := os.Open(path)
+			if err != nil {
+				return err
+			}
+			defer f.Close()
+
+			fmt.Fprintf(h, "%s\x00", filepath.ToSlash(rel))
+			if _, err := io.Copy(h, f); err != nil {
+				return err
+			}
+			h.Write([]byte{0})
+			return nil
+		})
+		if err != nil {
+			return "", fmt.Errorf("failed to read playbooks: %w", err)
+		}
+	}
+
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// RecordProvisionHash stores the inputs digest of a successful provisioning run
+func (m *Manager) RecordProvisionHash(serverName, hash string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	cfg, err := m.configManager.Load()
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+
+	found := false
+	for i := range cfg.Servers {
+		if cfg.Servers[i].Name == serverName {
+			cfg.Servers[i].ProvisionHash = hash
+			found = true
+			break
+		}
+	}
+
+	if !found {
+		return fmt.Errorf("server not found: %s", serverName)
+	}
+
+	if err := m.configManager.Save(cfg); err != nil {
+		return fmt.Errorf("failed to save config: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,98 @@
+package state
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/wordsail/cli/pkg/models"
+)
+
+func TestProvisionHashStable(t *testing.T) {
+	server := models.Server{Name: "web1", Hostname: "203.0.113.10", IP: "203.0.113.10"}
+
+	a := map[string]interface{}{"certbot_email": "ops@example.com", "php_version": "8.3", "swap_size_mb": 2048}
+	b := map[string]interface{}{"swap_size_mb": "2048", "php_version": "8.3", "certbot_email": "ops@example.com"}
+
+	first := ProvisionHash(a, server, "v1")
+	for i := 0; i < 20; i++ {
+		if got := ProvisionHash(a, server, "v1"); got != first {
+			t.Fatalf("hash changed between runs: %s != %s", got, first)
+		}
+	}
+	if got := ProvisionHash(b, server, "v1"); got != first {
+		t.Errorf("equivalent vars hashed differently: %s != %s", got, first)
+	}
+
+	// Connection details do not change what gets provisioned
+	other := server
+	other.Name = "renamed"
+	other.SSH = models.SSHConfig{User: "wordsail", Port: 2222}
+	if got := ProvisionHash(a, other, "v1"); got != first {
+		t.Errorf("SSH settings should not affect the hash")
+	}
+}
+
+func TestProvisionHashDetectsChanges(t *testing.T) {
+	server := models.Server{Name: "web1", Hostname: "203.0.113.10", IP: "203.0.113.10"}
+	vars := map[string]interface{}{"certbot_email": "ops@example.com", "php_version": "8.3"}
+	base := ProvisionHash(vars, server, "v1")
+
+	changedVar := map[string]interface{}{"certbot_email": "admin@example.com", "php_version": "8.3"}
+	addedVar := map[string]interface{}{"certbot_email": "ops@example.com", "php_version": "8.3", "redis": true}
+	withIPv6 := server
+	withIPv6.IPv6 = "2001:db8::10"
+	moved := server
+	moved.SetAddress("198.51.100.7")
+
+	tests := map[string]string{
+		"global var differs": ProvisionHash(changedVar, server, "v1"),
+		"global var added":   ProvisionHash(addedVar, server, "v1"),
+		"ipv6 added":         ProvisionHash(vars, withIPv6, "v1"),
+		"address changed":    ProvisionHash(vars, moved, "v1"),
+		"playbooks changed":  ProvisionHash(vars, server, "v2"),
+	}
+	for name, got := range tests {
+		if got == base {
+			t.Errorf("%s: hash unchanged", name)
+		}
+	}
+}
+
+func TestPlaybookVersion(t *testing.T) {
+	dir := t.TempDir()
+	write := func(rel, content string) {
+		path := filepath.Join(dir, rel)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write("provision.yml", "- hosts: all\n")
+	write("roles/nginx/tasks/main.yml", "- name: install nginx\n")
+
+	version := func() string {
+		v, err := PlaybookVersion(dir)
+		if err != nil {
+			t.Fatalf("PlaybookVersion() error = %v", err)
+		}
+		return v
+	}
+
+	base := version()
+	if version() != base {
+		t.Fatal("PlaybookVersion() is not stable")
+	}
+
+	write("playbooks/site.yml", "- hosts: all\n")
+	if version() != base {
+		t.Error("files outside the provisioning sources should not change the version")
+	}
+
+	write("roles/nginx/tasks/main.yml", "- name: install nginx mainline\n")
+	if version() == base {
+		t.Error("a role change should change the version")
+	}
+}
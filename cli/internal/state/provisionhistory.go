@@ -0,0 +1,81 @@
+package state
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/wordsail/cli/pkg/models"
+)
+
+// MaxProvisionHistory is how many provisioning runs are kept per server
+const MaxProvisionHistory = 10
+
+// ProvisionLogDir is the directory, next to the config file, that holds the
+// playbook output of provisioning runs
+const ProvisionLogDir = "logs"
+
+// RecordProvisionRun appends a run to the server's provisioning history,
+// dropping the oldest runs beyond MaxProvisionHistory and deleting their
+// log files
+func (m *Manager) RecordProvisionRun(serverName string, run models.ProvisionRun) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	cfg, err := m.configManager.Load()
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+
+	found := false
+	var dropped []models.ProvisionRun
+	for i := range cfg.Servers {
+		if cfg.Servers[i].Name == serverName {
+			history := append(cfg.Servers[i].ProvisionHistory, run)
+			if len(history) > MaxProvisionHistory {
+				dropped = history[:len(history)-MaxProvisionHistory]
+				history = history[len(history)-MaxProvisionHistory:]
+			}
+			cfg.Servers[i].ProvisionHistory = history
+			found = true
+			break
+		}
+	}
+
+	if !found {
+		return fmt.Errorf("server not found: %s", serverName)
+	}
+
+	if err := m.configManager.Save(cfg); err != nil {
+		return fmt.Errorf("failed to save config: %w", err)
+	}
+
+	for _, old := range dropped {
+		if old.LogPath == "" {
+			continue
+		}
+		if err := os.Remove(old.LogPath); err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("failed to remove old provisioning log: %w", err)
+		}
+	}
+	return nil
+}
+
+// WriteProvisionLog saves the playbook output of a provisioning run under
+// dir and returns the file's path
+func WriteProvisionLog(dir, serverName string, startedAt time.Time, output []string) (string, error) {
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return "", fmt.Errorf("failed to create log directory: %w", err)
+	}
+	path := filepath.Join(dir, fmt.Sprintf("provision-%s-%s.log", serverName, startedAt.Format("20060102-150405")))
+	content := strings.Join(output, "\n")
+	if content != "" {
+		content += "\n"
+	}
+	if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+		return "", fmt.Errorf("failed to write provisioning log: %w", err)
+	}
+	return path, nil
+}
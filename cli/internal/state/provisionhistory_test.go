@@ -0,0 +1,86 @@
+package state
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/wordsail/cli/pkg/models"
+)
+
+func TestRecordProvisionRun(t *testing.T) {
+	stateMgr, _ := newTestManager(t)
+	start := time.Date(2026, 3, 10, 12, 0, 0, 0, time.UTC)
+
+	for i := 0; i < MaxProvisionHistory+3; i++ {
+		run := models.ProvisionRun{StartedAt: start.Add(time.Duration(i) * time.Hour), Success: true, Ok: i}
+		if err := stateMgr.RecordProvisionRun("prod", run); err != nil {
+			t.Fatalf("RecordProvisionRun() error = %v", err)
+		}
+	}
+
+	server, err := stateMgr.GetServer("prod")
+	if err != nil {
+		t.Fatal(err)
+	}
+	history := server.ProvisionHistory
+	if len(history) != MaxProvisionHistory {
+		t.Fatalf("history has %d runs, want %d", len(history), MaxProvisionHistory)
+	}
+	if history[0].Ok != 3 || history[len(history)-1].Ok != MaxProvisionHistory+2 {
+		t.Errorf("history should keep the newest runs, got oldest ok=%d newest ok=%d", history[0].Ok, history[len(history)-1].Ok)
+	}
+
+	if err := stateMgr.RecordProvisionRun("missing", models.ProvisionRun{}); err == nil {
+		t.Error("RecordProvisionRun() for an unknown server should fail")
+	}
+}
+
+func TestRecordProvisionRunRemovesDroppedLogs(t *testing.T) {
+	stateMgr, _ := newTestManager(t)
+	dir := filepath.Join(t.TempDir(), ProvisionLogDir)
+	start := time.Date(2026, 3, 10, 12, 0, 0, 0, time.UTC)
+
+	var paths []string
+	for i := 0; i < MaxProvisionHistory+2; i++ {
+		started := start.Add(time.Duration(i) * time.Hour)
+		path, err := WriteProvisionLog(dir, "prod", started, []string{"PLAY [all]"})
+		if err != nil {
+			t.Fatal(err)
+		}
+		paths = append(paths, path)
+		if err := stateMgr.RecordProvisionRun("prod", models.ProvisionRun{StartedAt: started, LogPath: path}); err != nil {
+			t.Fatalf("RecordProvisionRun() error = %v", err)
+		}
+	}
+
+	for i, path := range paths {
+		_, err := os.Stat(path)
+		if dropped := i < 2; dropped && !os.IsNotExist(err) {
+			t.Errorf("log of dropped run %d still exists (stat error %v)", i, err)
+		} else if !dropped && err != nil {
+			t.Errorf("log of kept run %d: %v", i, err)
+		}
+	}
+}
+
+func TestWriteProvisionLog(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), ProvisionLogDir)
+	started := time.Date(2026, 3, 10, 12, 30, 0, 0, time.UTC)
+
+	path, err := WriteProvisionLog(dir, "prod", started, []string{"PLAY [all]", "ok: [prod]"})
+	if err != nil {
+		t.Fatalf("WriteProvisionLog() error = %v", err)
+	}
+	if want := filepath.Join(dir, "provision-prod-20260310-123000.log"); path != want {
+		t.Errorf("log path = %s, want %s", path, want)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != "PLAY [all]\nok: [prod]\n" {
+		t.Errorf("log content = %q", data)
+	}
+}
@@ -0,0 +1,81 @@
+package state
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/wordsail/cli/pkg/models"
+)
+
+func TestRenameServer(t *testing.T) {
+	stateMgr, _ := newTestManager(t)
+
+	if err := stateMgr.RenameServer("prod", "prod-eu-1"); err != nil {
+		t.Fatalf("RenameServer() error = %v", err)
+	}
+	if _, err := stateMgr.GetServer("prod"); err == nil {
+		t.Error("old name should no longer resolve")
+	}
+	server, err := stateMgr.GetServer("prod-eu-1")
+	if err != nil {
+		t.Fatalf("GetServer() error = %v", err)
+	}
+	if server.IP != "203.0.113.10" {
+		t.Errorf("renamed server IP = %s, want 203.0.113.10", server.IP)
+	}
+}
+
+func TestRenameServerRejects(t *testing.T) {
+	stateMgr, cfgMgr := newTestManager(t)
+	cfg, err := cfgMgr.Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	cfg.Servers = append(cfg.Servers, models.Server{Name: "staging", IP: "203.0.113.11"})
+	if err := cfgMgr.Save(cfg); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		oldName string
+		newName string
+	}{
+		{"unknown server", "missing", "web1"},
+		{"name in use", "prod", "staging"},
+		{"unsafe name", "prod", "prod_1"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := stateMgr.RenameServer(tt.oldName, tt.newName); err == nil {
+				t.Errorf("RenameServer(%q, %q) expected error", tt.oldName, tt.newName)
+			}
+		})
+	}
+	if _, err := stateMgr.GetServer("prod"); err != nil {
+		t.Errorf("failed renames should leave 'prod' in place: %v", err)
+	}
+}
+
+func TestRenamePendingServer(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "wordsail.pending.yaml")
+	for _, server := range []string{"prod", "staging"} {
+		if err := RecordPending(path, PendingChange{Kind: PendingDomainSSL, Server: server, Domain: "example.com"}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if err := RenamePendingServer(path, "prod", "prod-eu-1"); err != nil {
+		t.Fatalf("RenamePendingServer() error = %v", err)
+	}
+	pending, err := LoadPending(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := pending.Changes[0].Server; got != "prod-eu-1" {
+		t.Errorf("first change server = %s, want prod-eu-1", got)
+	}
+	if got := pending.Changes[1].Server; got != "staging" {
+		t.Errorf("second change server = %s, want staging", got)
+	}
+}
@@ -0,0 +1,201 @@
+package state
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// SSLHistoryFile is the file, next to the config file, that records
+// certificate issuance attempts
+const SSLHistoryFile = "ssl-history.json"
+
+// Let's Encrypt production rate limits tracked on this machine. Both are
+// counted over a sliding week.
+const (
+	DuplicateCertLimit      = 5  // certificates for the exact same domain
+	CertsPerRegisteredLimit = 50 // certificates per registered domain
+	RateLimitWindow         = 7 * 24 * time.Hour
+)
+
+// Rate limit levels returned by SSLHistory.Check
+const (
+	RateLimitOK       = "ok"
+	RateLimitWarn     = "warn"
+	RateLimitExceeded = "exceeded"
+)
+
+// SSLAttempt is one certificate issuance attempt
+type SSLAttempt struct {
+	Domain           string    `json:"domain"`
+	RegisteredDomain string    `json:"registered_domain"`
+	Time             time.Time `json:"time"`
+	Success          bool      `json:"success"`
+	Staging          bool      `json:"staging,omitempty"`
+}
+
+// SSLHistory is the local record of issuance attempts
+type SSLHistory struct {
+	path     string
+	Attempts []SSLAttempt `json:"attempts"`
+}
+
+// RateLimitStatus describes how close a domain is to the tracked limits
+type RateLimitStatus struct {
+	Level           string `json:"level"`
+	DuplicateCount  int    `json:"duplicate_count"`
+	RegisteredCount int    `json:"registered_count"`
+	Message         string `json:"message,omitempty"`
+}
+
+// LoadSSLHistory reads the history file. A missing file is an empty history.
+func LoadSSLHistory(path string) (*SSLHistory, error) {
+	h := &SSLHistory{path: path}
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return h, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read SSL history: %w", err)
+	}
+	if err := json.Unmarshal(data, h); err != nil {
+		return nil, fmt.Errorf("failed to parse SSL history %s: %w", path, err)
+	}
+	return h, nil
+}
+
+// Record adds an attempt for domain
+func (h *SSLHistory) Record(domain string, at time.Time, success, staging bool) {
+	h.Attempts = append(h.Attempts, SSLAttempt{
+		Domain:           domain,
+		RegisteredDomain: RegisteredDomain(domain),
+		Time:             at,
+		Success:          success,
+		Staging:          staging,
+	})
+}
+
+// Save writes the history, dropping attempts older than the rate limit window
+func (h *SSLHistory) Save(now time.Time) error {
+	kept := h.Attempts[:0]
+	for _, a := range h.Attempts {
+		if now.Sub(a.Time) < RateLimitWindow {
+			kept = append(kept, a)
+		}
+	}
+	h.Attempts = kept
+
+	data, err := json.MarshalIndent(h, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal SSL history: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(h.path), 0755); err != nil {
+		return fmt.Errorf("failed to create SSL history directory: %w", err)
+	}
+	if err := os.WriteFile(h.path, data, 0600); err != nil {
+		return fmt.Errorf("failed to write SSL history: %w", err)
+	}
+	return nil
+}
+
+// Check counts successful production certificates issued within the rate
+// limit window for domain and its registered domain. One certificate short
+// of the duplicate limit, or at 80% of the registered domain limit, is a
+// warning; reaching either limit means the next request would fail.
+func (h *SSLHistory) Check(domain string, now time.Time) RateLimitStatus {
+	registered := RegisteredDomain(domain)
+	status := RateLimitStatus{Level: RateLimitOK}
+
+	for _, a := range h.Attempts {
+		if !a.Success || a.Staging || now.Sub(a.Time) >= RateLimitWindow {
+			continue
+		}
+		if a.Domain == domain {
+			status.DuplicateCount++
+		}
+		if a.RegisteredDomain == registered {
+			status.RegisteredCount++
+		}
+	}
+
+	switch {
+	case status.DuplicateCount >= DuplicateCertLimit:
+		status.Level = RateLimitExceeded
+		status.Message = fmt.Sprintf("%d certificates were issued for %s in the last 7 days (limit %d)",
+			status.DuplicateCount, domain, DuplicateCertLimit)
+	case status.RegisteredCount >= CertsPerRegisteredLimit:
+		status.Level = RateLimitExceeded
+		status.Message = fmt.Sprintf("%d certificates were issued under %s in the last 7 days (limit %d)",
+			status.RegisteredCount, registered, CertsPerRegisteredLimit)
+	case status.DuplicateCount >= DuplicateCertLimit-1:
+		status.Level = RateLimitWarn
+		status.Message = fmt.Sprintf("%d of %d duplicate certificates for %s used in the last 7 days",
+			status.DuplicateCount, DuplicateCertLimit, domain)
+	case status.RegisteredCount*5 >= CertsPerRegisteredLimit*4:
+		status.Level = RateLimitWarn
+		status.Message = fmt.Sprintf("%d of %d certificates under %s used in the last 7 days",
+			status.RegisteredCount, CertsPerRegisteredLimit, registered)
+	}
+	return status
+}
+
+// multiPartSuffixes are common public suffixes with two labels. Without a
+// full public suffix list, RegisteredDomain only knows these.
+var multiPartSuffixes = map[string]bool{
+	"co.uk": true, "org.uk": true, "ac.uk": true, "gov.uk": true,
+	"com.au": true, "net.au": true, "org.au": true,
+	"co.nz": true, "co.jp": true, "co.za": true, "co.in": true,
+	"com.br": true, "com.mx": true, "com.tr": true, "com.cn": true,
+}
+
+// RegisteredDomain returns the registrable part of a domain, e.g.
+// "example.com" for "www.shop.example.com" and "example.co.uk" for
+// "www.example.co.uk"
+func RegisteredDomain(domain string) string {
+	labels := strings.Split(strings.TrimSuffix(strings.ToLower(domain), "."), ".")
+	n := 2
+	if len(labels) >= 3 && multiPartSuffixes[strings.Join(labels[len(labels)-2:], ".")] {
+		n = 3
+	}
+	if len(labels) <= n {
+		return strings.Join(labels, ".")
+	}
+	return strings.Join(labels[len(labels)-n:], ".")
+}
+
+var (
+	rateLimitPattern  = regexp.MustCompile(`(?i)too many (certificates|failed authorizations|new orders|registrations)[^\n]*`)
+	retryAfterPattern = regexp.MustCompile(`(?i)retry after ([0-9]{4}-[0-9]{2}-[0-9]{2}[ T][0-9:]+( UTC)?)`)
+)
+
+// ParseRateLimitError looks for a Let's Encrypt rate limit error in certbot
+// output and returns a readable message, or "" if there is none
+func ParseRateLimitError(output []string) string {
+	for _, line := range output {
+		if !strings.Contains(line, "rateLimited") && !rateLimitPattern.MatchString(line) {
+			continue
+		}
+
+		reason := "too many certificate requests"
+		if m := rateLimitPattern.FindString(line); m != "" {
+			reason = m
+			for _, sep := range []string{", retry after", ": see", `"`, `\n`} {
+				if i := strings.Index(strings.ToLower(reason), sep); i > 0 {
+					reason = reason[:i]
+				}
+			}
+			reason = strings.TrimRight(strings.TrimSpace(reason), ".,:;")
+		}
+
+		msg := "Let's Encrypt rate limit hit: " + reason
+		if m := retryAfterPattern.FindStringSubmatch(line); m != nil {
+			msg += fmt.Sprintf(" (retry after %s)", m[1])
+		}
+		return msg + ". Use --staging while testing."
+	}
+	return ""
+}
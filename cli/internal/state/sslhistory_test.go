@@ -0,0 +1,138 @@
+package state
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRegisteredDomain(t *testing.T) {
+	tests := map[string]string{
+		"example.com":          "example.com",
+		"www.example.com":      "example.com",
+		"a.b.shop.example.com": "example.com",
+		"www.example.co.uk":    "example.co.uk",
+		"example.co.uk":        "example.co.uk",
+		"WWW.Example.COM.":     "example.com",
+	}
+	for domain, want := range tests {
+		if got := RegisteredDomain(domain); got != want {
+			t.Errorf("RegisteredDomain(%q) = %q, want %q", domain, got, want)
+		}
+	}
+}
+
+func TestSSLHistoryCheck(t *testing.T) {
+	now := time.Date(2026, 3, 10, 12, 0, 0, 0, time.UTC)
+
+	issue := func(h *SSLHistory, domain string, n int, age time.Duration) {
+		for i := 0; i < n; i++ {
+			h.Record(domain, now.Add(-age), true, false)
+		}
+	}
+
+	tests := []struct {
+		name      string
+		setup     func(h *SSLHistory)
+		domain    string
+		wantLevel string
+	}{
+		{"empty history", func(h *SSLHistory) {}, "example.com", RateLimitOK},
+		{"few duplicates", func(h *SSLHistory) { issue(h, "example.com", 3, time.Hour) }, "example.com", RateLimitOK},
+		{"one below duplicate limit", func(h *SSLHistory) { issue(h, "example.com", 4, time.Hour) }, "example.com", RateLimitWarn},
+		{"duplicate limit reached", func(h *SSLHistory) { issue(h, "example.com", 5, time.Hour) }, "example.com", RateLimitExceeded},
+		{"old attempts expire", func(h *SSLHistory) { issue(h, "example.com", 5, 8*24*time.Hour) }, "example.com", RateLimitOK},
+		{
+			"failures and staging do not count",
+			func(h *SSLHistory) {
+				for i := 0; i < 5; i++ {
+					h.Record("example.com", now, false, false)
+					h.Record("example.com", now, true, true)
+				}
+			},
+			"example.com", RateLimitOK,
+		},
+		{
+			"registered domain nearing limit",
+			func(h *SSLHistory) {
+				for i := 0; i < 40; i++ {
+					issue(h, strings.Repeat("a", i+1)+".example.com", 1, time.Hour)
+				}
+			},
+			"new.example.com", RateLimitWarn,
+		},
+		{
+			"registered domain limit reached",
+			func(h *SSLHistory) {
+				for i := 0; i < 50; i++ {
+					issue(h, strings.Repeat("a", i+1)+".example.com", 1, time.Hour)
+				}
+			},
+			"new.example.com", RateLimitExceeded,
+		},
+		{"other registered domain unaffected", func(h *SSLHistory) { issue(h, "example.com", 5, time.Hour) }, "example.org", RateLimitOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := &SSLHistory{}
+			tt.setup(h)
+			got := h.Check(tt.domain, now)
+			if got.Level != tt.wantLevel {
+				t.Errorf("Check(%q) = %+v, want level %q", tt.domain, got, tt.wantLevel)
+			}
+			if got.Level != RateLimitOK && got.Message == "" {
+				t.Error("expected a message for a non-ok level")
+			}
+		})
+	}
+}
+
+func TestSSLHistorySaveAndLoad(t *testing.T) {
+	path := filepath.Join(t.TempDir(), SSLHistoryFile)
+	now := time.Now()
+
+	h, err := LoadSSLHistory(path)
+	if err != nil || len(h.Attempts) != 0 {
+		t.Fatalf("LoadSSLHistory(missing) = %+v, %v", h, err)
+	}
+
+	h.Record("example.com", now.Add(-time.Hour), true, false)
+	h.Record("example.com", now.Add(-10*24*time.Hour), true, false)
+	if err := h.Save(now); err != nil {
+		t.Fatalf("Save() error: %v", err)
+	}
+
+	loaded, err := LoadSSLHistory(path)
+	if err != nil {
+		t.Fatalf("LoadSSLHistory() error: %v", err)
+	}
+	if len(loaded.Attempts) != 1 {
+		t.Errorf("expected attempts outside the window to be pruned, got %d", len(loaded.Attempts))
+	}
+	if loaded.Attempts[0].RegisteredDomain != "example.com" {
+		t.Errorf("registered domain = %q", loaded.Attempts[0].RegisteredDomain)
+	}
+}
+
+func TestParseRateLimitError(t *testing.T) {
+	output := []string{
+		"TASK [libs : Run Certbot to obtain SSL certificate]",
+		`fatal: [web1]: FAILED! => {"stderr": "An unexpected error occurred:\nError creating new order :: too many certificates (5) already issued for this exact set of domains in the last 168h0m0s, retry after 2026-03-12 08:15:00 UTC: see https://letsencrypt.org/docs/rate-limits/#new-certificates-per-exact-set-of-hostnames"}`,
+	}
+
+	got := ParseRateLimitError(output)
+	if strings.Count(got, "retry after") != 1 {
+		t.Errorf("ParseRateLimitError() = %q, want the retry time once", got)
+	}
+	for _, want := range []string{"rate limit", "too many certificates (5) already issued", "retry after 2026-03-12 08:15:00 UTC", "--staging"} {
+		if !strings.Contains(got, want) {
+			t.Errorf("ParseRateLimitError() = %q, missing %q", got, want)
+		}
+	}
+
+	if got := ParseRateLimitError([]string{"fatal: DNS problem: NXDOMAIN looking up A for example.com"}); got != "" {
+		t.Errorf("expected no rate limit message, got %q", got)
+	}
+}
@@ -0,0 +1,262 @@
+package utils
+
+import (
+	"bufio"
+	"fmt"
+	"path"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/wordsail/cli/pkg/models"
+)
+
+// discoverSitesScript prints one tab-separated record per line:
+//
+//	SITE      <home> <owner of home/files>
+//	WPCONFIG  <home> <DB_* or $table_prefix line of wp-config.php>
+//	NGINX     <vhost file> <server_name, root or fastcgi_pass line>
+//	CERT      <certificate name> <openssl -enddate output>
+//
+// It runs as root so wp-config.php and /etc/letsencrypt are readable.
+const discoverSitesScript = `for d in /sites/*/; do
+  [ -d "$d" ] || continue
+  d=${d%/}
+  printf 'SITE\t%s\t%s\n' "$d" "$(stat -c %U "$d/files" 2>/dev/null)"
+  [ -f "$d/files/wp-config.php" ] || continue
+  grep -E "DB_(NAME|USER|HOST)|table_prefix" "$d/files/wp-config.php" | while IFS= read -r line; do
+    printf 'WPCONFIG\t%s\t%s\n' "$d" "$line"
+  done
+done
+for f in /etc/nginx/sites-enabled/*; do
+  [ -e "$f" ] || continue
+  grep -hE '^\s*(server_name|root|fastcgi_pass)\s' "$f" | while IFS= read -r line; do
+    printf 'NGINX\t%s\t%s\n' "$f" "$line"
+  done
+done
+for c in /etc/letsencrypt/live/*/cert.pem; do
+  [ -f "$c" ] || continue
+  printf 'CERT\t%s\t%s\n' "$(basename "$(dirname "$c")")" "$(openssl x509 -enddate -noout -in "$c")"
+done
+true`
+
+// DiscoveredSite is a site found on a server that WordSail did not create
+// (or whose record was lost)
+type DiscoveredSite struct {
+	SiteID        string               `json:"site_id"`
+	PrimaryDomain string               `json:"primary_domain"`
+	Domains       []string             `json:"domains"`
+	PHPVersion    string               `json:"php_version,omitempty"`
+	WordPress     bool                 `json:"wordpress"`
+	DBName        string               `json:"db_name,omitempty"`
+	DBUser        string               `json:"db_user,omitempty"`
+	DBHost        string               `json:"db_host,omitempty"`
+	TablePrefix   string               `json:"table_prefix,omitempty"`
+	AdminUser     string               `json:"admin_user,omitempty"`
+	AdminEmail    string               `json:"admin_email,omitempty"`
+	CertExpiry    map[string]time.Time `json:"cert_expiry,omitempty"`
+}
+
+var (
+	wpConfigDefinePattern = regexp.MustCompile(`define\(\s*['"](DB_NAME|DB_USER|DB_HOST)['"]\s*,\s*['"]([^'"]*)['"]`)
+	wpConfigPrefixPattern = regexp.MustCompile(`\$table_prefix\s*=\s*['"]([^'"]*)['"]`)
+	fastcgiSocketPattern  = regexp.MustCompile(`php(\d+\.\d+)-(\w+)\.sock`)
+)
+
+// DiscoverSites scans a server's /sites directories, enabled Nginx vhosts
+// and Let's Encrypt certificates for existing sites. Nothing is changed on
+// the server.
+func DiscoverSites(server models.Server) ([]DiscoveredSite, error) {
+	output, err := RunSSHCommand(server, "sudo -n sh -c "+ShellQuote(discoverSitesScript))
+	if err != nil {
+		return nil, fmt.Errorf("failed to scan server: %s", firstLine(output, err))
+	}
+	return parseDiscoveredSites(output), nil
+}
+
+// vhost is the part of an enabled Nginx config that ties it to a site
+type vhost struct {
+	names   []string
+	root    string
+	version string
+	siteID  string
+}
+
+// parseDiscoveredSites turns the output of discoverSitesScript into sites,
+// sorted by primary domain. A vhost belongs to a site when its root is the
+// site's document root or it passes PHP to the site's FPM pool.
+func parseDiscoveredSites(output string) []DiscoveredSite {
+	sites := map[string]*DiscoveredSite{} // by home directory
+	var homes []string
+	vhosts := map[string]*vhost{}
+	var vhostFiles []string
+	certs := map[string]time.Time{}
+
+	scanner := bufio.NewScanner(strings.NewReader(output))
+	for scanner.Scan() {
+		fields := strings.SplitN(scanner.Text(), "\t", 3)
+		if len(fields) < 3 {
+			continue
+		}
+		kind, key, value := fields[0], fields[1], strings.TrimSpace(fields[2])
+
+		switch kind {
+		case "SITE":
+			// Directories without a files/ owner hold only logs (e.g. of an
+			// additional domain)
+			if value == "" || value == "root" {
+				continue
+			}
+			sites[key] = &DiscoveredSite{SiteID: value, PrimaryDomain: path.Base(key)}
+			homes = append(homes, key)
+		case "WPCONFIG":
+			site := sites[key]
+			if site == nil {
+				continue
+			}
+			if m := wpConfigDefinePattern.FindStringSubmatch(value); m != nil {
+				site.WordPress = true
+				switch m[1] {
+				case "DB_NAME":
+					site.DBName = m[2]
+				case "DB_USER":
+					site.DBUser = m[2]
+				case "DB_HOST":
+					site.DBHost = m[2]
+				}
+			} else if m := wpConfigPrefixPattern.FindStringSubmatch(value); m != nil {
+				site.TablePrefix = m[1]
+			}
+		case "NGINX":
+			v := vhosts[key]
+			if v == nil {
+				v = &vhost{}
+				vhosts[key] = v
+				vhostFiles = append(vhostFiles, key)
+			}
+			directive := strings.Fields(strings.TrimSuffix(strings.SplitN(value, "#", 2)[0], ";"))
+			if len(directive) < 2 {
+				continue
+			}
+			switch directive[0] {
+			case "server_name":
+				for _, name := range directive[1:] {
+					name = strings.TrimSuffix(name, ";")
+					if name != "_" && !strings.ContainsAny(name, "*~") {
+						v.names = append(v.names, name)
+					}
+				}
+			case "root":
+				if v.root == "" {
+					v.root = strings.TrimSuffix(strings.TrimSuffix(directive[1], ";"), "/")
+				}
+			case "fastcgi_pass":
+				if m := fastcgiSocketPattern.FindStringSubmatch(directive[1]); m != nil && v.siteID == "" {
+					v.version, v.siteID = m[1], m[2]
+				}
+			}
+		case "CERT":
+			if expiresAt := ParseSSLExpiry(value); expiresAt != nil {
+				certs[key] = *expiresAt
+			}
+		}
+	}
+
+	var discovered []DiscoveredSite
+	for _, home := range homes {
+		site := sites[home]
+		domains := []string{site.PrimaryDomain}
+		for _, file := range vhostFiles {
+			v := vhosts[file]
+			if v.root != home+"/files" && v.siteID != site.SiteID {
+				continue
+			}
+			if v.version != "" && (site.PHPVersion == "" || v.root == home+"/files") {
+				site.PHPVersion = v.version
+			}
+			domains = append(domains, v.names...)
+		}
+		site.Domains = uniqueStrings(domains)
+
+		for _, domain := range site.Domains {
+			if expiresAt, ok := certs[domain]; ok {
+				if site.CertExpiry == nil {
+					site.CertExpiry = map[string]time.Time{}
+				}
+				site.CertExpiry[domain] = expiresAt
+			}
+		}
+		discovered = append(discovered, *site)
+	}
+
+	sort.Slice(discovered, func(i, j int) bool {
+		return discovered[i].PrimaryDomain < discovered[j].PrimaryDomain
+	})
+	return discovered
+}
+
+// uniqueStrings removes repeated values, keeping the first occurrence
+func uniqueStrings(values []string) []string {
+	seen := make(map[string]bool, len(values))
+	unique := make([]string, 0, len(values))
+	for _, v := range values {
+		if !seen[v] {
+			seen[v] = true
+			unique = append(unique, v)
+		}
+	}
+	return unique
+}
+
+// DiscoverAdmin looks up the first administrator of a WordPress site with
+// WP-CLI, for the site record's admin user and email
+func DiscoverAdmin(server models.Server, site models.Site) (string, string, error) {
+	output, err := RunWPCLI(server, site, "user list --role=administrator --fields=user_login,user_email --format=csv --orderby=ID")
+	if err != nil {
+		return "", "", err
+	}
+	lines := strings.Split(strings.TrimSpace(output), "\n")
+	if len(lines) < 2 {
+		return "", "", fmt.Errorf("no administrator found")
+	}
+	login, email, _ := strings.Cut(strings.TrimSpace(lines[1]), ",")
+	return login, email, nil
+}
+
+// Site builds the site record for a discovered site. Domains with a live
+// certificate are marked SSL-enabled with its expiry.
+func (d DiscoveredSite) Site(now time.Time) models.Site {
+	site := models.Site{
+		SiteID:        d.SiteID,
+		PrimaryDomain: d.PrimaryDomain,
+		CreatedAt:     now,
+		AdminUser:     d.AdminUser,
+		AdminEmail:    d.AdminEmail,
+		PHPVersion:    d.PHPVersion,
+		NoWordPress:   !d.WordPress,
+		Database: models.Database{
+			Name:    d.DBName,
+			User:    d.DBUser,
+			Host:    d.DBHost,
+			Adopted: true,
+		},
+	}
+	if site.Database.Name == "" {
+		// Bare sites follow the site create naming
+		site.Database.Name, site.Database.User = d.SiteID, d.SiteID
+	}
+	if site.Database.Host == "" {
+		site.Database.Host = "localhost"
+	}
+	for _, domain := range d.Domains {
+		entry := models.Domain{Domain: domain}
+		if expiresAt, ok := d.CertExpiry[domain]; ok {
+			expiresAt := expiresAt
+			entry.SSLEnabled = true
+			entry.SSLExpiresAt = &expiresAt
+		}
+		site.Domains = append(site.Domains, entry)
+	}
+	return site
+}
@@ -0,0 +1,92 @@
+package utils
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseDiscoveredSites(t *testing.T) {
+	output := strings.Join([]string{
+		"SITE\t/sites/example.com\texamplecom",
+		"WPCONFIG\t/sites/example.com\tdefine( 'DB_NAME', 'examplecom' );",
+		"WPCONFIG\t/sites/example.com\tdefine( 'DB_USER', 'examplecom' );",
+		"WPCONFIG\t/sites/example.com\tdefine( 'DB_HOST', 'localhost' );",
+		"WPCONFIG\t/sites/example.com\t$table_prefix = 'abcd_';",
+		"SITE\t/sites/www.example.com\t", // logs only
+		"SITE\t/sites/app.example.org\tappexampleorg",
+		"NGINX\t/etc/nginx/sites-enabled/example.com\t\tserver_name example.com; # managed",
+		"NGINX\t/etc/nginx/sites-enabled/example.com\t\troot /sites/example.com/files/;",
+		"NGINX\t/etc/nginx/sites-enabled/example.com\t\tfastcgi_pass unix:/run/php/php8.2-examplecom.sock;",
+		"NGINX\t/etc/nginx/sites-enabled/www.example.com\t\tserver_name www.example.com;",
+		"NGINX\t/etc/nginx/sites-enabled/www.example.com\t\troot /sites/www.example.com/files/;",
+		"NGINX\t/etc/nginx/sites-enabled/www.example.com\t\tfastcgi_pass unix:/run/php/php8.2-examplecom.sock;",
+		"NGINX\t/etc/nginx/sites-enabled/app.example.org\t\tserver_name app.example.org _;",
+		"NGINX\t/etc/nginx/sites-enabled/app.example.org\t\troot /sites/app.example.org/files;",
+		"NGINX\t/etc/nginx/sites-enabled/app.example.org\t\tfastcgi_pass unix:/run/php/php8.3-appexampleorg.sock;",
+		"NGINX\t/etc/nginx/sites-enabled/default\t\tserver_name _;",
+		"NGINX\t/etc/nginx/sites-enabled/default\t\troot /var/www/html;",
+		"CERT\texample.com\tnotAfter=Mar 15 12:00:00 2030 GMT",
+		"CERT\tunrelated.net\tnotAfter=Mar 15 12:00:00 2030 GMT",
+	}, "\n")
+
+	sites := parseDiscoveredSites(output)
+	if len(sites) != 2 {
+		t.Fatalf("parseDiscoveredSites() = %+v, want app.example.org and example.com", sites)
+	}
+
+	app := sites[0]
+	if app.PrimaryDomain != "app.example.org" || app.SiteID != "appexampleorg" || app.WordPress || app.PHPVersion != "8.3" {
+		t.Errorf("bare site = %+v", app)
+	}
+	if !reflect.DeepEqual(app.Domains, []string{"app.example.org"}) {
+		t.Errorf("bare site domains = %v", app.Domains)
+	}
+
+	wp := sites[1]
+	if wp.SiteID != "examplecom" || !wp.WordPress || wp.PHPVersion != "8.2" {
+		t.Errorf("WordPress site = %+v", wp)
+	}
+	if wp.DBName != "examplecom" || wp.DBUser != "examplecom" || wp.DBHost != "localhost" || wp.TablePrefix != "abcd_" {
+		t.Errorf("WordPress site database = %s/%s@%s prefix %s", wp.DBUser, wp.DBName, wp.DBHost, wp.TablePrefix)
+	}
+	if !reflect.DeepEqual(wp.Domains, []string{"example.com", "www.example.com"}) {
+		t.Errorf("WordPress site domains = %v, want the alias attached through the FPM pool", wp.Domains)
+	}
+	if _, ok := wp.CertExpiry["example.com"]; !ok || len(wp.CertExpiry) != 1 {
+		t.Errorf("CertExpiry = %v, want example.com only", wp.CertExpiry)
+	}
+}
+
+func TestDiscoveredSiteRecord(t *testing.T) {
+	now := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+	expiry := now.AddDate(0, 2, 0)
+
+	site := DiscoveredSite{
+		SiteID:        "examplecom",
+		PrimaryDomain: "example.com",
+		Domains:       []string{"example.com", "www.example.com"},
+		PHPVersion:    "8.2",
+		WordPress:     true,
+		DBName:        "wp_example",
+		DBUser:        "wp_example",
+		AdminUser:     "admin",
+		CertExpiry:    map[string]time.Time{"example.com": expiry},
+	}.Site(now)
+
+	if site.NoWordPress || site.AdminUser != "admin" || site.PHPVersion != "8.2" || !site.CreatedAt.Equal(now) {
+		t.Errorf("Site() = %+v", site)
+	}
+	if site.Database.Name != "wp_example" || site.Database.Host != "localhost" || !site.Database.Adopted {
+		t.Errorf("Site().Database = %+v", site.Database)
+	}
+	if len(site.Domains) != 2 || !site.Domains[0].SSLEnabled || !site.Domains[0].SSLExpiresAt.Equal(expiry) || site.Domains[1].SSLEnabled {
+		t.Errorf("Site().Domains = %+v", site.Domains)
+	}
+
+	bare := DiscoveredSite{SiteID: "app", PrimaryDomain: "app.example.org", Domains: []string{"app.example.org"}}.Site(now)
+	if !bare.NoWordPress || bare.Database.Name != "app" || bare.Database.User != "app" {
+		t.Errorf("bare Site() = %+v", bare)
+	}
+}
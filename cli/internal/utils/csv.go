@@ -0,0 +1,37 @@
+package utils
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"os"
+)
+
+// PrintCSV prints a table as CSV on stdout, for list commands run with
+// --output csv
+func PrintCSV(headers []string, rows [][]string) error {
+	return WriteCSV(os.Stdout, headers, rows)
+}
+
+// WriteCSV writes a header line and rows as CSV. Cells are written as plain
+// text, so any ANSI color codes are removed.
+func WriteCSV(w io.Writer, headers []string, rows [][]string) error {
+	writer := csv.NewWriter(w)
+	if err := writer.Write(headers); err != nil {
+		return fmt.Errorf("failed to write CSV: %w", err)
+	}
+	for _, row := range rows {
+		plain := make([]string, len(row))
+		for i, cell := range row {
+			plain[i] = stripANSI(cell)
+		}
+		if err := writer.Write(plain); err != nil {
+			return fmt.Errorf("failed to write CSV: %w", err)
+		}
+	}
+	writer.Flush()
+	if err := writer.Error(); err != nil {
+		return fmt.Errorf("failed to write CSV: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,24 @@
+package utils
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestWriteCSV(t *testing.T) {
+	var buf bytes.Buffer
+	rows := [][]string{
+		{"web1", "\033[32mprovisioned\033[0m", "2"},
+		{"web2", "notes, with a comma", `say "hi"`},
+	}
+	if err := WriteCSV(&buf, []string{"NAME", "STATUS", "SITES"}, rows); err != nil {
+		t.Fatalf("WriteCSV() error = %v", err)
+	}
+
+	want := "NAME,STATUS,SITES\n" +
+		"web1,provisioned,2\n" +
+		"web2,\"notes, with a comma\",\"say \"\"hi\"\"\"\n"
+	if got := buf.String(); got != want {
+		t.Errorf("WriteCSV() =\n%s\nwant\n%s", got, want)
+	}
+}
@@ -0,0 +1,120 @@
+package utils
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net"
+	"strings"
+
+	"github.com/wordsail/cli/pkg/models"
+)
+
+// The remote side of an export or import reads the database password from
+// the first line of stdin into a private option file, so it never appears
+// in a command line on the server. Anything after that line is the dump.
+const dbPasswordPreamble = `set -eo pipefail; umask 077; f=$(mktemp); trap 'rm -f "$f"' EXIT; ` +
+	`IFS= read -r pw; printf '[client]\npassword="%s"\n' "$pw" > "$f"; `
+
+// dbConnectionArgs returns the mysql client options for a site database,
+// taking the credentials from its record
+func dbConnectionArgs(db models.Database) string {
+	host, port := db.Host, ""
+	if h, p, err := net.SplitHostPort(db.Host); err == nil {
+		host, port = h, p
+	}
+	if host == "" {
+		host = "localhost"
+	}
+
+	args := fmt.Sprintf(`--defaults-extra-file="$f" -h %s`, ShellQuote(host))
+	if port != "" {
+		args += " -P " + ShellQuote(port)
+	}
+	return args + " -u " + ShellQuote(db.User)
+}
+
+// DBExportCommand builds the remote command that writes a gzipped
+// mysqldump of the site database to stdout
+func DBExportCommand(db models.Database) string {
+	script := dbPasswordPreamble + fmt.Sprintf(
+		"mysqldump %s --single-transaction --quick --routines --triggers --no-tablespaces %s | gzip -c",
+		dbConnectionArgs(db), ShellQuote(db.Name))
+	return "bash -c " + ShellQuote(script)
+}
+
+// DBImportCommand builds the remote command that loads a dump read from
+// stdin into the site database. gzipped dumps are decompressed on the server.
+func DBImportCommand(db models.Database, gzipped bool) string {
+	source := "cat"
+	if gzipped {
+		source = "gunzip -c"
+	}
+	script := dbPasswordPreamble + fmt.Sprintf("%s | mysql %s %s",
+		source, dbConnectionArgs(db), ShellQuote(db.Name))
+	return "bash -c " + ShellQuote(script)
+}
+
+// DBPasswordLine is the stdin line DBExportCommand and DBImportCommand read
+// the password from, escaped for a double-quoted option file value
+func DBPasswordLine(password string) (string, error) {
+	if strings.ContainsAny(password, "\r\n") {
+		return "", fmt.Errorf("database password must not contain line breaks")
+	}
+	escaped := strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(password)
+	return escaped + "\n", nil
+}
+
+// WPConfigDBPassword reads DB_PASSWORD from a site's wp-config.php. Only
+// stdout is read, so PHP notices on stderr don't end up in the password.
+func WPConfigDBPassword(server models.Server, site models.Site) (string, error) {
+	var stdout bytes.Buffer
+	if err := StreamSSHCommand(server, WPCLICommand(site, "config get DB_PASSWORD"), strings.NewReader(""), &stdout); err != nil {
+		return "", fmt.Errorf("wp config: %w", err)
+	}
+	return strings.TrimRight(stdout.String(), "\r\n"), nil
+}
+
+// ProgressWriter counts the bytes written through it and reports the
+// running total to OnProgress
+type ProgressWriter struct {
+	W          io.Writer
+	OnProgress func(total int64)
+	total      int64
+}
+
+func (p *ProgressWriter) Write(b []byte) (int, error) {
+	n, err := p.W.Write(b)
+	p.total += int64(n)
+	if p.OnProgress != nil {
+		p.OnProgress(p.total)
+	}
+	return n, err
+}
+
+// Total returns the number of bytes written so far
+func (p *ProgressWriter) Total() int64 {
+	return p.total
+}
+
+// ProgressReader counts the bytes read through it and reports the running
+// total to OnProgress
+type ProgressReader struct {
+	R          io.Reader
+	OnProgress func(total int64)
+	total      int64
+}
+
+func (p *ProgressReader) Read(b []byte) (int, error) {
+	n, err := p.R.Read(b)
+	p.total += int64(n)
+	if p.OnProgress != nil {
+		p.OnProgress(p.total)
+	}
+	return n, err
+}
+
+// Total returns the number of bytes read so far
+func (p *ProgressReader) Total() int64 {
+	return p.total
+}
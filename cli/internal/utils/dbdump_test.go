@@ -0,0 +1,103 @@
+package utils
+
+import (
+	"bytes"
+	"os/exec"
+	"strings"
+	"testing"
+
+	"github.com/wordsail/cli/pkg/models"
+	"golang.org/x/crypto/ssh"
+)
+
+func TestDBCommands(t *testing.T) {
+	db := models.Database{Name: "examplecom", User: "examplecom", Host: "localhost"}
+
+	export := DBExportCommand(db)
+	for _, want := range []string{"mysqldump --defaults-extra-file=", "-h '\\''localhost'\\''", "-u '\\''examplecom'\\''", "| gzip -c"} {
+		if !strings.Contains(export, want) {
+			t.Errorf("DBExportCommand() = %s, missing %q", export, want)
+		}
+	}
+	if strings.Contains(export, " -P ") {
+		t.Errorf("DBExportCommand() should not set a port for %q: %s", db.Host, export)
+	}
+
+	db.Host = "10.0.0.5:3307"
+	if imp := DBImportCommand(db, true); !strings.Contains(imp, "gunzip -c | mysql") || !strings.Contains(imp, "-P '\\''3307'\\''") {
+		t.Errorf("DBImportCommand(gzipped) = %s", imp)
+	}
+	if imp := DBImportCommand(db, false); !strings.Contains(imp, "cat | mysql") {
+		t.Errorf("DBImportCommand(plain) = %s", imp)
+	}
+}
+
+func TestDBPasswordPreamble(t *testing.T) {
+	if _, err := exec.LookPath("bash"); err != nil {
+		t.Skip("bash not available")
+	}
+
+	line, err := DBPasswordLine(`p"a\ss`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if line != `p\"a\\ss`+"\n" {
+		t.Errorf("DBPasswordLine() = %q", line)
+	}
+
+	// The option file gets the password; the rest of stdin is left for the dump
+	cmd := exec.Command("bash", "-c", dbPasswordPreamble+`cat "$f"; echo ---; cat`)
+	cmd.Stdin = strings.NewReader(line + "CREATE TABLE t;\n")
+	var out bytes.Buffer
+	cmd.Stdout = &out
+	if err := cmd.Run(); err != nil {
+		t.Fatalf("preamble failed: %v", err)
+	}
+	want := "[client]\npassword=\"p\\\"a\\\\ss\"\n---\nCREATE TABLE t;\n"
+	if out.String() != want {
+		t.Errorf("preamble output = %q, want %q", out.String(), want)
+	}
+
+	if _, err := DBPasswordLine("a\nb"); err == nil {
+		t.Error("DBPasswordLine() should reject line breaks")
+	}
+}
+
+func TestProgressCounters(t *testing.T) {
+	var seen int64
+	var buf bytes.Buffer
+	w := &ProgressWriter{W: &buf, OnProgress: func(total int64) { seen = total }}
+	w.Write([]byte("hello"))
+	w.Write([]byte(" world"))
+	if w.Total() != 11 || seen != 11 || buf.String() != "hello world" {
+		t.Errorf("ProgressWriter total = %d, reported %d", w.Total(), seen)
+	}
+
+	r := &ProgressReader{R: strings.NewReader("abcdef")}
+	p := make([]byte, 4)
+	r.Read(p)
+	r.Read(p)
+	if r.Total() != 6 {
+		t.Errorf("ProgressReader total = %d, want 6", r.Total())
+	}
+}
+
+func TestWPConfigDBPasswordIgnoresStderr(t *testing.T) {
+	server := testSSHServer(t, func(command string, channel ssh.Channel) uint32 {
+		if !strings.Contains(command, "config get DB_PASSWORD") {
+			t.Errorf("command = %q", command)
+		}
+		channel.Stderr().Write([]byte("PHP Deprecated:  Creation of dynamic property in wp-settings.php\n"))
+		channel.Write([]byte("s3cret pass\n"))
+		return 0
+	})
+	site := models.Site{SiteID: "examplecom", PrimaryDomain: "example.com"}
+
+	got, err := WPConfigDBPassword(server, site)
+	if err != nil {
+		t.Fatalf("WPConfigDBPassword() error = %v", err)
+	}
+	if got != "s3cret pass" {
+		t.Errorf("WPConfigDBPassword() = %q, want %q", got, "s3cret pass")
+	}
+}
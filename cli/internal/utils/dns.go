@@ -0,0 +1,153 @@
+package utils
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"strings"
+	"time"
+)
+
+// Resolver looks up the addresses of a host. *net.Resolver satisfies it;
+// tests substitute a fake.
+type Resolver interface {
+	LookupIPAddr(ctx context.Context, host string) ([]net.IPAddr, error)
+}
+
+// DefaultResolver is the system resolver used for DNS pre-checks
+var DefaultResolver Resolver = net.DefaultResolver
+
+// DNSCheck holds the A and AAAA records of a domain compared against the
+// addresses the server is expected to answer on
+type DNSCheck struct {
+	Domain       string
+	ResolvedA    []string
+	ResolvedAAAA []string
+	ExpectedIPv4 string
+	ExpectedIPv6 string
+	AMatches     bool
+	AAAAMatches  bool
+}
+
+// IPv6Checked reports whether AAAA records were compared (the server has an IPv6)
+func (c *DNSCheck) IPv6Checked() bool {
+	return c.ExpectedIPv6 != ""
+}
+
+// Matches reports whether every checked record family points at the server
+func (c *DNSCheck) Matches() bool {
+	if !c.AMatches {
+		return false
+	}
+	return !c.IPv6Checked() || c.AAAAMatches
+}
+
+// PartialMatch reports whether exactly one of A/AAAA points at the server
+func (c *DNSCheck) PartialMatch() bool {
+	return c.IPv6Checked() && c.AMatches != c.AAAAMatches
+}
+
+// Warning describes a partial match, or returns "" when there is nothing to warn about
+func (c *DNSCheck) Warning() string {
+	if !c.PartialMatch() {
+		return ""
+	}
+	if c.AMatches {
+		if len(c.ResolvedAAAA) == 0 {
+			return fmt.Sprintf("%s has no AAAA record; IPv6 visitors cannot reach %s", c.Domain, c.ExpectedIPv6)
+		}
+		return fmt.Sprintf("%s AAAA record points to %s, not %s; IPv6 visitors will reach the wrong server", c.Domain, joinOrNone(c.ResolvedAAAA), c.ExpectedIPv6)
+	}
+	return fmt.Sprintf("%s A record points to %s, not %s; only IPv6 visitors reach this server", c.Domain, joinOrNone(c.ResolvedA), c.ExpectedIPv4)
+}
+
+// CheckDomainDNS resolves domain and compares its A records with ipv4 and,
+// when ipv6 is set, its AAAA records with ipv6. A domain that does not
+// exist yields empty record lists rather than an error.
+func CheckDomainDNS(ctx context.Context, resolver Resolver, domain, ipv4, ipv6 string) (*DNSCheck, error) {
+	check := &DNSCheck{
+		Domain:       domain,
+		ResolvedA:    []string{},
+		ResolvedAAAA: []string{},
+		ExpectedIPv4: ipv4,
+		ExpectedIPv6: ipv6,
+	}
+
+	addrs, err := resolver.LookupIPAddr(ctx, domain)
+	if err != nil {
+		var dnsErr *net.DNSError
+		if !errors.As(err, &dnsErr) || !dnsErr.IsNotFound {
+			return nil, fmt.Errorf("DNS lookup for %s failed: %w", domain, err)
+		}
+	}
+
+	wantV4 := net.ParseIP(ipv4)
+	wantV6 := net.ParseIP(ipv6)
+	for _, addr := range addrs {
+		if v4 := addr.IP.To4(); v4 != nil {
+			check.ResolvedA = append(check.ResolvedA, v4.String())
+			if wantV4 != nil && v4.Equal(wantV4) {
+				check.AMatches = true
+			}
+			continue
+		}
+		check.ResolvedAAAA = append(check.ResolvedAAAA, addr.IP.String())
+		if wantV6 != nil && addr.IP.Equal(wantV6) {
+			check.AAAAMatches = true
+		}
+	}
+
+	return check, nil
+}
+
+// ResolveIPv4 returns the first IPv4 address host resolves to. Servers
+// registered by hostname have no stored IP, so checks that need one resolve
+// it when they run.
+func ResolveIPv4(ctx context.Context, resolver Resolver, host string) (string, error) {
+	addrs, err := resolver.LookupIPAddr(ctx, host)
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve %s: %w", host, err)
+	}
+	for _, addr := range addrs {
+		if v4 := addr.IP.To4(); v4 != nil {
+			return v4.String(), nil
+		}
+	}
+	return "", fmt.Errorf("%s has no IPv4 address", host)
+}
+
+// WaitForDNS checks the A records of domain every interval until they
+// include ipv4 or timeout elapses. report, when set, is called with the
+// result of every check; failed lookups are retried.
+func WaitForDNS(resolver Resolver, domain, ipv4 string, interval, timeout time.Duration, report func(*DNSCheck, error)) (*DNSCheck, error) {
+	deadline := time.Now().Add(timeout)
+	for {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		check, err := CheckDomainDNS(ctx, resolver, domain, ipv4, "")
+		cancel()
+		if report != nil {
+			report(check, err)
+		}
+		if err == nil && check.AMatches {
+			return check, nil
+		}
+		if time.Now().Add(interval).After(deadline) {
+			if err != nil {
+				return nil, fmt.Errorf("%s does not resolve to %s after %s: %w", domain, ipv4, timeout, err)
+			}
+			if len(check.ResolvedA) == 0 {
+				return check, fmt.Errorf("%s has no A record after %s", domain, timeout)
+			}
+			return check, fmt.Errorf("%s resolves to %s, not %s, after %s", domain, strings.Join(check.ResolvedA, ", "), ipv4, timeout)
+		}
+		time.Sleep(interval)
+	}
+}
+
+func joinOrNone(addrs []string) string {
+	if len(addrs) == 0 {
+		return "none"
+	}
+	return strings.Join(addrs, ", ")
+}
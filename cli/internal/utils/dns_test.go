@@ -0,0 +1,170 @@
+package utils
+
+import (
+	"context"
+	"net"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+// fakeResolver answers lookups from a fixed table
+type fakeResolver map[string][]string
+
+func (f fakeResolver) LookupIPAddr(ctx context.Context, host string) ([]net.IPAddr, error) {
+	ips, ok := f[host]
+	if !ok {
+		return nil, &net.DNSError{Err: "no such host", Name: host, IsNotFound: true}
+	}
+	addrs := make([]net.IPAddr, len(ips))
+	for i, ip := range ips {
+		addrs[i] = net.IPAddr{IP: net.ParseIP(ip)}
+	}
+	return addrs, nil
+}
+
+func TestCheckDomainDNS(t *testing.T) {
+	resolver := fakeResolver{
+		"both.example.com":     {"203.0.113.10", "2001:db8::10"},
+		"a-only.example.com":   {"203.0.113.10"},
+		"stale-v6.example.com": {"203.0.113.10", "2001:db8::99"},
+		"stale-v4.example.com": {"198.51.100.1", "2001:db8::10"},
+	}
+
+	tests := []struct {
+		name        string
+		domain      string
+		ipv6        string
+		wantA       bool
+		wantAAAA    bool
+		wantMatches bool
+		wantPartial bool
+		wantWarning string
+	}{
+		{"both match", "both.example.com", "2001:db8::10", true, true, true, false, ""},
+		{"A match, AAAA mismatch", "stale-v6.example.com", "2001:db8::10", true, false, false, true, "AAAA record points to 2001:db8::99"},
+		{"A mismatch, AAAA match", "stale-v4.example.com", "2001:db8::10", false, true, false, true, "A record points to 198.51.100.1"},
+		{"A match, no AAAA", "a-only.example.com", "2001:db8::10", true, false, false, true, "has no AAAA record"},
+		{"IPv4-only server ignores AAAA", "stale-v6.example.com", "", true, false, true, false, ""},
+		{"unresolved domain", "missing.example.com", "2001:db8::10", false, false, false, false, ""},
+		{"expected IPv6 in long form", "both.example.com", "2001:0db8:0000:0000:0000:0000:0000:0010", true, true, true, false, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			check, err := CheckDomainDNS(context.Background(), resolver, tt.domain, "203.0.113.10", tt.ipv6)
+			if err != nil {
+				t.Fatalf("CheckDomainDNS() error = %v", err)
+			}
+			if check.AMatches != tt.wantA {
+				t.Errorf("AMatches = %v, want %v", check.AMatches, tt.wantA)
+			}
+			if check.AAAAMatches != tt.wantAAAA {
+				t.Errorf("AAAAMatches = %v, want %v", check.AAAAMatches, tt.wantAAAA)
+			}
+			if check.Matches() != tt.wantMatches {
+				t.Errorf("Matches() = %v, want %v", check.Matches(), tt.wantMatches)
+			}
+			if check.PartialMatch() != tt.wantPartial {
+				t.Errorf("PartialMatch() = %v, want %v", check.PartialMatch(), tt.wantPartial)
+			}
+			warning := check.Warning()
+			if tt.wantWarning == "" && warning != "" {
+				t.Errorf("Warning() = %q, want none", warning)
+			}
+			if !strings.Contains(warning, tt.wantWarning) {
+				t.Errorf("Warning() = %q, want it to contain %q", warning, tt.wantWarning)
+			}
+		})
+	}
+}
+
+func TestCheckDomainDNSRecords(t *testing.T) {
+	resolver := fakeResolver{"both.example.com": {"203.0.113.10", "2001:db8::10"}}
+
+	check, err := CheckDomainDNS(context.Background(), resolver, "both.example.com", "203.0.113.10", "2001:db8::10")
+	if err != nil {
+		t.Fatalf("CheckDomainDNS() error = %v", err)
+	}
+	if !reflect.DeepEqual(check.ResolvedA, []string{"203.0.113.10"}) {
+		t.Errorf("ResolvedA = %v", check.ResolvedA)
+	}
+	if !reflect.DeepEqual(check.ResolvedAAAA, []string{"2001:db8::10"}) {
+		t.Errorf("ResolvedAAAA = %v", check.ResolvedAAAA)
+	}
+}
+
+func TestResolveIPv4(t *testing.T) {
+	resolver := fakeResolver{
+		"web1.example.com": {"2001:db8::10", "203.0.113.10"},
+		"v6.example.com":   {"2001:db8::10"},
+	}
+
+	if got, err := ResolveIPv4(context.Background(), resolver, "web1.example.com"); err != nil || got != "203.0.113.10" {
+		t.Errorf("ResolveIPv4(web1) = %q, %v; want 203.0.113.10", got, err)
+	}
+	if _, err := ResolveIPv4(context.Background(), resolver, "v6.example.com"); err == nil {
+		t.Error("expected an error for a host with no IPv4 address")
+	}
+	if _, err := ResolveIPv4(context.Background(), resolver, "missing.example.com"); err == nil {
+		t.Error("expected an error for a host that does not resolve")
+	}
+}
+
+// propagatingResolver answers with old until it has been asked after times
+type propagatingResolver struct {
+	old, new string
+	after    int
+	calls    int
+}
+
+func (p *propagatingResolver) LookupIPAddr(ctx context.Context, host string) ([]net.IPAddr, error) {
+	p.calls++
+	ip := p.old
+	if p.calls > p.after {
+		ip = p.new
+	}
+	if ip == "" {
+		return nil, &net.DNSError{Err: "no such host", Name: host, IsNotFound: true}
+	}
+	return []net.IPAddr{{IP: net.ParseIP(ip)}}, nil
+}
+
+func TestWaitForDNS(t *testing.T) {
+	tests := []struct {
+		name      string
+		resolver  *propagatingResolver
+		wantErr   bool
+		wantCalls int
+	}{
+		{"already resolves", &propagatingResolver{new: "203.0.113.10"}, false, 1},
+		{"propagates", &propagatingResolver{old: "198.51.100.1", new: "203.0.113.10", after: 2}, false, 3},
+		{"new record appears", &propagatingResolver{new: "203.0.113.10", after: 1}, false, 2},
+		{"never propagates", &propagatingResolver{old: "198.51.100.1", new: "198.51.100.1"}, true, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			reported := 0
+			check, err := WaitForDNS(tt.resolver, "example.com", "203.0.113.10", time.Millisecond, 20*time.Millisecond, func(*DNSCheck, error) {
+				reported++
+			})
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("WaitForDNS() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if reported != tt.resolver.calls {
+				t.Errorf("report called %d times for %d lookups", reported, tt.resolver.calls)
+			}
+			if tt.wantErr {
+				if !strings.Contains(err.Error(), "198.51.100.1") {
+					t.Errorf("error = %v, want the address the domain resolves to", err)
+				}
+				return
+			}
+			if tt.resolver.calls != tt.wantCalls || !check.AMatches {
+				t.Errorf("WaitForDNS() = %+v after %d lookups, want a match after %d", check, tt.resolver.calls, tt.wantCalls)
+			}
+		})
+	}
+}
@@ -0,0 +1,121 @@
+package utils
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/wordsail/cli/pkg/models"
+)
+
+// ExpiringCert is a domain whose recorded certificate expires soon
+type ExpiringCert struct {
+	Server    string    `json:"server"`
+	SiteID    string    `json:"site_id"`
+	Domain    string    `json:"domain"`
+	ExpiresAt time.Time `json:"expires_at"`
+	DaysLeft  int       `json:"days_left"`
+}
+
+// UnreachableServer is a server that failed the SSH probe
+type UnreachableServer struct {
+	Name  string `json:"name"`
+	Error string `json:"error"`
+}
+
+// FleetStatus summarizes every configured server and site
+type FleetStatus struct {
+	Servers         int                 `json:"servers"`
+	ServersByStatus map[string]int      `json:"servers_by_status"`
+	Sites           int                 `json:"sites"`
+	Domains         int                 `json:"domains"`
+	ExpiryDays      int                 `json:"expiry_days"`
+	ExpiringCerts   []ExpiringCert      `json:"expiring_certs"`
+	Probed          bool                `json:"probed"`
+	Unreachable     []UnreachableServer `json:"unreachable"`
+}
+
+// Healthy reports whether the summary shows nothing that needs attention
+func (f FleetStatus) Healthy() bool {
+	return len(f.ExpiringCerts) == 0 && len(f.Unreachable) == 0 && f.ServersByStatus["error"] == 0
+}
+
+// SummarizeFleet counts servers, sites and domains and lists certificates
+// expiring within days of now, soonest first. Expired certificates are
+// included with a negative DaysLeft.
+func SummarizeFleet(servers []models.Server, days int, now time.Time) FleetStatus {
+	status := FleetStatus{
+		Servers:         len(servers),
+		ServersByStatus: map[string]int{},
+		ExpiryDays:      days,
+		ExpiringCerts:   []ExpiringCert{},
+		Unreachable:     []UnreachableServer{},
+	}
+	cutoff := now.AddDate(0, 0, days)
+
+	for _, server := range servers {
+		status.ServersByStatus[server.Status]++
+		status.Sites += len(server.Sites)
+		for _, site := range server.Sites {
+			status.Domains += len(site.Domains)
+			for _, d := range site.Domains {
+				if !d.SSLEnabled || d.SSLExpiresAt == nil || d.SSLExpiresAt.After(cutoff) {
+					continue
+				}
+				status.ExpiringCerts = append(status.ExpiringCerts, ExpiringCert{
+					Server:    server.Name,
+					SiteID:    site.SiteID,
+					Domain:    d.Domain,
+					ExpiresAt: *d.SSLExpiresAt,
+					DaysLeft:  int(d.SSLExpiresAt.Sub(now).Hours() / 24),
+				})
+			}
+		}
+	}
+
+	sort.SliceStable(status.ExpiringCerts, func(i, j int) bool {
+		return status.ExpiringCerts[i].ExpiresAt.Before(status.ExpiringCerts[j].ExpiresAt)
+	})
+	return status
+}
+
+// ProbeServers runs probe against every server using a pool of workers and
+// returns the servers that failed, in config order
+func ProbeServers(servers []models.Server, workers int, probe func(models.Server) error) []UnreachableServer {
+	if workers < 1 {
+		workers = 1
+	}
+
+	failures := make(map[string]error, len(servers))
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+
+	jobs := make(chan models.Server)
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for server := range jobs {
+				if err := probe(server); err != nil {
+					mu.Lock()
+					failures[server.Name] = err
+					mu.Unlock()
+				}
+			}
+		}()
+	}
+
+	for _, server := range servers {
+		jobs <- server
+	}
+	close(jobs)
+	wg.Wait()
+
+	unreachable := []UnreachableServer{}
+	for _, server := range servers {
+		if err, ok := failures[server.Name]; ok {
+			unreachable = append(unreachable, UnreachableServer{Name: server.Name, Error: err.Error()})
+		}
+	}
+	return unreachable
+}
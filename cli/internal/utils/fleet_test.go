@@ -0,0 +1,82 @@
+package utils
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/wordsail/cli/pkg/models"
+)
+
+func TestSummarizeFleet(t *testing.T) {
+	now := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+	at := func(days int) *time.Time {
+		t := now.AddDate(0, 0, days)
+		return &t
+	}
+
+	servers := []models.Server{
+		{
+			Name:   "web1",
+			Status: "provisioned",
+			Sites: []models.Site{
+				{SiteID: "a", Domains: []models.Domain{
+					{Domain: "a.com", SSLEnabled: true, SSLExpiresAt: at(10)},
+					{Domain: "www.a.com", SSLEnabled: true, SSLExpiresAt: at(60)},
+				}},
+				{SiteID: "b", Domains: []models.Domain{
+					{Domain: "b.com", SSLEnabled: true, SSLExpiresAt: at(-2)},
+					{Domain: "nossl.b.com", SSLExpiresAt: at(1)},
+				}},
+			},
+		},
+		{Name: "web2", Status: "error"},
+		{Name: "web3", Status: "provisioned"},
+	}
+
+	status := SummarizeFleet(servers, 14, now)
+	if status.Servers != 3 || status.Sites != 2 || status.Domains != 4 {
+		t.Errorf("counts = %d servers, %d sites, %d domains", status.Servers, status.Sites, status.Domains)
+	}
+	if status.ServersByStatus["provisioned"] != 2 || status.ServersByStatus["error"] != 1 {
+		t.Errorf("ServersByStatus = %v", status.ServersByStatus)
+	}
+	if len(status.ExpiringCerts) != 2 {
+		t.Fatalf("ExpiringCerts = %+v, want b.com and a.com", status.ExpiringCerts)
+	}
+	if first := status.ExpiringCerts[0]; first.Domain != "b.com" || first.DaysLeft != -2 || first.Server != "web1" {
+		t.Errorf("first expiring cert = %+v", first)
+	}
+	if second := status.ExpiringCerts[1]; second.Domain != "a.com" || second.DaysLeft != 10 {
+		t.Errorf("second expiring cert = %+v", second)
+	}
+	if status.Healthy() {
+		t.Error("a fleet with expiring certs and an errored server is not healthy")
+	}
+
+	if !SummarizeFleet(servers[2:], 14, now).Healthy() {
+		t.Error("a provisioned server without sites should be healthy")
+	}
+}
+
+func TestProbeServers(t *testing.T) {
+	servers := []models.Server{{Name: "a"}, {Name: "b"}, {Name: "c"}, {Name: "d"}}
+	probe := func(server models.Server) error {
+		if server.Name == "b" || server.Name == "d" {
+			return errors.New("connection refused")
+		}
+		return nil
+	}
+
+	unreachable := ProbeServers(servers, 3, probe)
+	if len(unreachable) != 2 || unreachable[0].Name != "b" || unreachable[1].Name != "d" {
+		t.Fatalf("ProbeServers() = %+v, want b and d in config order", unreachable)
+	}
+	if unreachable[0].Error != "connection refused" {
+		t.Errorf("error = %q", unreachable[0].Error)
+	}
+
+	if got := ProbeServers(nil, 0, probe); len(got) != 0 {
+		t.Errorf("ProbeServers() with no servers = %+v", got)
+	}
+}
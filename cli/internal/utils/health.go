@@ -0,0 +1,57 @@
+package utils
+
+import (
+	"time"
+)
+
+// HealthCheck is the outcome of one health-check pass against a server
+type HealthCheck struct {
+	At            time.Time
+	SSHError      error
+	Services      []ServiceState
+	ServicesError error
+}
+
+// Healthy reports whether SSH answered and every service is active
+func (h HealthCheck) Healthy() bool {
+	if h.SSHError != nil || h.ServicesError != nil {
+		return false
+	}
+	for _, service := range h.Services {
+		if !service.Active() {
+			return false
+		}
+	}
+	return true
+}
+
+// HealthHistory accumulates repeated health checks for watch mode
+type HealthHistory struct {
+	Started      time.Time
+	Checks       int
+	Failures     int
+	Transitions  int // healthy <-> unhealthy changes between consecutive checks
+	FirstFailure *time.Time
+	LastFailure  *time.Time
+	Last         *HealthCheck
+}
+
+// Record adds a check to the history
+func (h *HealthHistory) Record(check HealthCheck) {
+	if h.Checks == 0 {
+		h.Started = check.At
+	}
+	if h.Last != nil && h.Last.Healthy() != check.Healthy() {
+		h.Transitions++
+	}
+	h.Checks++
+	if !check.Healthy() {
+		h.Failures++
+		at := check.At
+		if h.FirstFailure == nil {
+			h.FirstFailure = &at
+		}
+		h.LastFailure = &at
+	}
+	h.Last = &check
+}
@@ -0,0 +1,57 @@
+package utils
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestHealthCheckHealthy(t *testing.T) {
+	active := []ServiceState{{"nginx", "active"}, {"mariadb", "active"}}
+
+	tests := []struct {
+		name  string
+		check HealthCheck
+		want  bool
+	}{
+		{"all active", HealthCheck{Services: active}, true},
+		{"ssh failed", HealthCheck{SSHError: errors.New("timeout")}, false},
+		{"services unknown", HealthCheck{ServicesError: errors.New("exit 1")}, false},
+		{"service failed", HealthCheck{Services: []ServiceState{{"nginx", "active"}, {"php8.3-fpm", "failed"}}}, false},
+		{"service missing", HealthCheck{Services: []ServiceState{{"mysql", "missing"}}}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.check.Healthy(); got != tt.want {
+				t.Errorf("Healthy() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestHealthHistoryRecord(t *testing.T) {
+	start := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+	down := errors.New("connection refused")
+
+	var history HealthHistory
+	for i, sshErr := range []error{nil, down, down, nil, nil} {
+		history.Record(HealthCheck{At: start.Add(time.Duration(i) * time.Minute), SSHError: sshErr})
+	}
+
+	if history.Checks != 5 || history.Failures != 2 || history.Transitions != 2 {
+		t.Errorf("history = %d checks, %d failures, %d transitions; want 5, 2, 2",
+			history.Checks, history.Failures, history.Transitions)
+	}
+	if !history.Started.Equal(start) {
+		t.Errorf("Started = %v, want %v", history.Started, start)
+	}
+	if history.FirstFailure == nil || !history.FirstFailure.Equal(start.Add(time.Minute)) {
+		t.Errorf("FirstFailure = %v", history.FirstFailure)
+	}
+	if history.LastFailure == nil || !history.LastFailure.Equal(start.Add(2*time.Minute)) {
+		t.Errorf("LastFailure = %v", history.LastFailure)
+	}
+	if history.Last == nil || !history.Last.Healthy() {
+		t.Errorf("Last = %+v, want the final healthy check", history.Last)
+	}
+}
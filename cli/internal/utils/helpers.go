@@ -1,6 +1,8 @@
 package utils
 
 import (
+	"fmt"
+	"strings"
 	"time"
 
 	"github.com/wordsail/cli/pkg/models"
@@ -75,6 +77,82 @@ func FindSiteByDomain(server *models.Server, domain string) *models.Site {
 	return nil
 }
 
+// ResolveSite finds a site on a server by site ID or by any of its domains,
+// so --site accepts either. A reference that is one site's ID and another
+// site's domain is ambiguous and returns an error naming both. When nothing
+// matches, the error suggests sites whose ID or domain contains the reference.
+func ResolveSite(server *models.Server, ref string) (*models.Site, error) {
+	if server == nil {
+		return nil, fmt.Errorf("site '%s' not found", ref)
+	}
+	byID := FindSiteBySiteID(server, ref)
+	byDomain := FindSiteByDomain(server, strings.ToLower(ref))
+	if byDomain == nil {
+		byDomain = FindSiteByDomain(server, ref)
+	}
+
+	switch {
+	case byID != nil && byDomain != nil && byID != byDomain:
+		return nil, fmt.Errorf("site '%s' on server '%s' is ambiguous: it matches site ID '%s' (%s) and a domain of site ID '%s' (%s); use the site ID",
+			ref, server.Name, byID.SiteID, byID.PrimaryDomain, byDomain.SiteID, byDomain.PrimaryDomain)
+	case byID != nil:
+		return byID, nil
+	case byDomain != nil:
+		return byDomain, nil
+	}
+
+	err := fmt.Errorf("site '%s' not found on server '%s'", ref, server.Name)
+	if suggestions := siteSuggestions(server, ref); len(suggestions) > 0 {
+		err = fmt.Errorf("%w (did you mean %s?)", err, strings.Join(suggestions, ", "))
+	}
+	return nil, err
+}
+
+// siteSuggestions lists sites whose ID or a domain contains ref, or is
+// contained in it, formatted as "site-id (primary.domain)"
+func siteSuggestions(server *models.Server, ref string) []string {
+	ref = strings.ToLower(strings.TrimSpace(ref))
+	if ref == "" {
+		return nil
+	}
+	related := func(s string) bool {
+		s = strings.ToLower(s)
+		return s != "" && (strings.Contains(s, ref) || strings.Contains(ref, s))
+	}
+
+	var suggestions []string
+	for _, site := range server.Sites {
+		match := related(site.SiteID) || related(site.PrimaryDomain)
+		for _, d := range site.Domains {
+			match = match || related(d.Domain)
+		}
+		if match {
+			suggestions = append(suggestions, fmt.Sprintf("'%s' (%s)", site.SiteID, site.PrimaryDomain))
+		}
+	}
+	return suggestions
+}
+
+// FindSiteByDomainAcrossServers finds the site using a domain (primary or additional)
+// on any server. Domains are compared case-insensitively.
+// Returns nil, nil if no site uses the domain
+func FindSiteByDomainAcrossServers(servers []models.Server, domain string) (*models.Server, *models.Site) {
+	for i := range servers {
+		for j := range servers[i].Sites {
+			site := &servers[i].Sites[j]
+			if strings.EqualFold(site.PrimaryDomain, domain) {
+				return &servers[i], site
+			}
+			for _, d := range site.Domains {
+				if strings.EqualFold(d.Domain, domain) {
+					return &servers[i], site
+				}
+			}
+		}
+	}
+	return nil, nil
+}
+
 // GetProvisionedServers returns only servers with status "provisioned"
 func GetProvisionedServers(servers []models.Server) []models.Server {
 	result := make([]models.Server, 0)
@@ -97,16 +175,22 @@ func SiteExists(server *models.Server, siteID string) bool {
 }
 
 // ParseSSLExpiry parses SSL certificate expiry date from openssl output format
-// Input format: "Mar 15 12:00:00 2024 GMT" or similar
+// Input format: "Mar 15 12:00:00 2024 GMT" or similar, optionally prefixed
+// with "notAfter=", or an ISO-8601 timestamp or date
 // Returns nil if parsing fails
 func ParseSSLExpiry(expiryStr string) *time.Time {
-	// Try common formats from openssl x509 -enddate output
+	expiryStr = strings.TrimSpace(expiryStr)
+	expiryStr = strings.TrimSpace(strings.TrimPrefix(expiryStr, "notAfter="))
+
+	// Try common formats from openssl x509 -enddate output, then ISO-8601
 	formats := []string{
 		"Jan 2 15:04:05 2006 MST",
 		"Jan  2 15:04:05 2006 MST",
 		"Jan 02 15:04:05 2006 MST",
 		"2 Jan 2006 15:04:05 MST",
 		"02 Jan 2006 15:04:05 MST",
+		time.RFC3339,
+		"2006-01-02",
 	}
 
 	for _, format := range formats {
@@ -117,3 +201,91 @@ func ParseSSLExpiry(expiryStr string) *time.Time {
 
 	return nil
 }
+
+// DomainsForBulkRemoval returns the domains of a site to detach with
+// `domain remove --all`. The primary domain is only included (last, so the
+// aliases go first) when includePrimary is set.
+func DomainsForBulkRemoval(site *models.Site, includePrimary bool) ([]string, error) {
+	var domains []string
+	hasPrimary := false
+	for _, d := range site.Domains {
+		if d.Domain == site.PrimaryDomain {
+			hasPrimary = true
+			continue
+		}
+		domains = append(domains, d.Domain)
+	}
+
+	if includePrimary && hasPrimary {
+		domains = append(domains, site.PrimaryDomain)
+	}
+
+	if len(domains) == 0 {
+		if includePrimary {
+			return nil, fmt.Errorf("site '%s' has no domains", site.SiteID)
+		}
+		return nil, fmt.Errorf("site '%s' has no domains besides its primary domain %s", site.SiteID, site.PrimaryDomain)
+	}
+	return domains, nil
+}
+
+// ValidatePrimaryDomainChange checks that domain can become the primary
+// domain of site: it must already be attached and not be the primary
+func ValidatePrimaryDomainChange(site *models.Site, domain string) error {
+	if strings.EqualFold(site.PrimaryDomain, domain) {
+		return fmt.Errorf("%s is already the primary domain of site '%s'", domain, site.SiteID)
+	}
+	for _, d := range site.Domains {
+		if d.Domain == domain {
+			return nil
+		}
+	}
+	return fmt.Errorf("domain '%s' is not attached to site '%s'; add it with 'wordsail domain add' first", domain, site.SiteID)
+}
+
+// ValidateDomainRedirect checks that from can redirect to to: both must be
+// attached to the site, and the target must not redirect itself (which
+// would create a chain or a loop)
+func ValidateDomainRedirect(site *models.Site, from, to string) error {
+	if from == to {
+		return fmt.Errorf("a domain cannot redirect to itself")
+	}
+
+	var source, target *models.Domain
+	for i := range site.Domains {
+		switch site.Domains[i].Domain {
+		case from:
+			source = &site.Domains[i]
+		case to:
+			target = &site.Domains[i]
+		}
+	}
+
+	if source == nil {
+		return fmt.Errorf("domain '%s' is not attached to site '%s'", from, site.SiteID)
+	}
+	if target == nil {
+		return fmt.Errorf("domain '%s' is not attached to site '%s'", to, site.SiteID)
+	}
+	if target.RedirectTo != "" {
+		return fmt.Errorf("%s already redirects to %s; remove that redirect first", to, target.RedirectTo)
+	}
+	return nil
+}
+
+// RedirectSourcesFor returns the domains of a site that redirect to one of
+// the removed domains and are not removed themselves
+func RedirectSourcesFor(site *models.Site, removed []string) []string {
+	gone := make(map[string]bool, len(removed))
+	for _, d := range removed {
+		gone[d] = true
+	}
+
+	var sources []string
+	for _, d := range site.Domains {
+		if d.RedirectTo != "" && gone[d.RedirectTo] && !gone[d.Domain] {
+			sources = append(sources, d.Domain)
+		}
+	}
+	return sources
+}
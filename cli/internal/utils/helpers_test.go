@@ -1,6 +1,8 @@
 package utils
 
 import (
+	"reflect"
+	"strings"
 	"testing"
 
 	"github.com/wordsail/cli/pkg/models"
@@ -189,6 +191,142 @@ func TestFindSiteByDomain(t *testing.T) {
 	}
 }
 
+func TestResolveSite(t *testing.T) {
+	server := &models.Server{
+		Name: "web1",
+		Sites: []models.Site{
+			{
+				SiteID:        "shop",
+				PrimaryDomain: "shop.example.com",
+				Domains: []models.Domain{
+					{Domain: "shop.example.com"},
+					{Domain: "www.shop.example.com"},
+				},
+			},
+			{
+				SiteID:        "blog",
+				PrimaryDomain: "blog.example.com",
+				Domains: []models.Domain{
+					{Domain: "blog.example.com"},
+					{Domain: "shop"},
+				},
+			},
+			{
+				SiteID:        "docs",
+				PrimaryDomain: "docs.example.com",
+				Domains: []models.Domain{
+					{Domain: "docs.example.com"},
+				},
+			},
+		},
+	}
+
+	tests := []struct {
+		name       string
+		server     *models.Server
+		ref        string
+		wantSiteID string
+		wantErr    string
+	}{
+		{"by site ID", server, "docs", "docs", ""},
+		{"by primary domain", server, "blog.example.com", "blog", ""},
+		{"by additional domain", server, "www.shop.example.com", "shop", ""},
+		{"domain is case-insensitive", server, "Docs.Example.com", "docs", ""},
+		{"ID and domain of different sites", server, "shop", "", "ambiguous"},
+		{"not found with suggestion", server, "docs.example.org", "", "did you mean 'docs' (docs.example.com)"},
+		{"not found", server, "missing.net", "", "site 'missing.net' not found on server 'web1'"},
+		{"nil server", nil, "docs", "", "not found"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			site, err := ResolveSite(tt.server, tt.ref)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("ResolveSite() error = %v, want it to contain %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ResolveSite() error = %v", err)
+			}
+			if site.SiteID != tt.wantSiteID {
+				t.Errorf("ResolveSite() siteID = %v, want %v", site.SiteID, tt.wantSiteID)
+			}
+		})
+	}
+}
+
+func TestFindSiteByDomainAcrossServers(t *testing.T) {
+	servers := []models.Server{
+		{
+			Name: "server1",
+			Sites: []models.Site{
+				{
+					SiteID:        "site1",
+					PrimaryDomain: "primary.com",
+					Domains: []models.Domain{
+						{Domain: "primary.com"},
+						{Domain: "www.primary.com"},
+					},
+				},
+			},
+		},
+		{
+			Name: "server2",
+			Sites: []models.Site{
+				{
+					SiteID:        "site2",
+					PrimaryDomain: "other.com",
+					Domains: []models.Domain{
+						{Domain: "other.com"},
+						{Domain: "shop.other.com"},
+					},
+				},
+				{
+					SiteID:        "site3",
+					PrimaryDomain: "third.com",
+				},
+			},
+		},
+	}
+
+	tests := []struct {
+		name       string
+		servers    []models.Server
+		domain     string
+		wantNil    bool
+		wantServer string
+		wantSiteID string
+	}{
+		{"primary domain on first server", servers, "primary.com", false, "server1", "site1"},
+		{"additional domain on first server", servers, "www.primary.com", false, "server1", "site1"},
+		{"additional domain on second server", servers, "shop.other.com", false, "server2", "site2"},
+		{"primary domain without domain list", servers, "third.com", false, "server2", "site3"},
+		{"case-insensitive match", servers, "WWW.Primary.COM", false, "server1", "site1"},
+		{"not found", servers, "unknown.com", true, "", ""},
+		{"empty servers", nil, "primary.com", true, "", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server, site := FindSiteByDomainAcrossServers(tt.servers, tt.domain)
+			if tt.wantNil {
+				if server != nil || site != nil {
+					t.Errorf("FindSiteByDomainAcrossServers() = %v, %v, want nil", server, site)
+				}
+				return
+			}
+			if server == nil || site == nil {
+				t.Fatalf("FindSiteByDomainAcrossServers() = nil, want site")
+			}
+			if server.Name != tt.wantServer || site.SiteID != tt.wantSiteID {
+				t.Errorf("FindSiteByDomainAcrossServers() = %s/%s, want %s/%s", server.Name, site.SiteID, tt.wantServer, tt.wantSiteID)
+			}
+		})
+	}
+}
+
 func TestGetProvisionedServers(t *testing.T) {
 	servers := []models.Server{
 		{Name: "server1", Status: "provisioned"},
@@ -307,9 +445,54 @@ func TestParseSSLExpiry(t *testing.T) {
 			wantMonth: 12,
 			wantDay:   25,
 		},
+		{
+			name:      "notAfter prefix",
+			input:     "notAfter=Mar 15 12:00:00 2024 GMT",
+			wantNil:   false,
+			wantYear:  2024,
+			wantMonth: 3,
+			wantDay:   15,
+		},
+		{
+			name:      "surrounding whitespace",
+			input:     "  notAfter= Jan  5 08:30:00 2025 GMT\n",
+			wantNil:   false,
+			wantYear:  2025,
+			wantMonth: 1,
+			wantDay:   5,
+		},
+		{
+			name:      "RFC3339",
+			input:     "2024-06-13T23:59:59Z",
+			wantNil:   false,
+			wantYear:  2024,
+			wantMonth: 6,
+			wantDay:   13,
+		},
+		{
+			name:      "RFC3339 with offset",
+			input:     "2024-06-13T10:00:00+02:00",
+			wantNil:   false,
+			wantYear:  2024,
+			wantMonth: 6,
+			wantDay:   13,
+		},
+		{
+			name:      "ISO date",
+			input:     "2024-03-15",
+			wantNil:   false,
+			wantYear:  2024,
+			wantMonth: 3,
+			wantDay:   15,
+		},
 		{
 			name:    "invalid format",
-			input:   "2024-03-15",
+			input:   "15/03/2024",
+			wantNil: true,
+		},
+		{
+			name:    "prefix only",
+			input:   "notAfter=",
 			wantNil: true,
 		},
 		{
@@ -349,3 +532,124 @@ func TestParseSSLExpiry(t *testing.T) {
 		})
 	}
 }
+
+func TestDomainsForBulkRemoval(t *testing.T) {
+	site := &models.Site{
+		SiteID:        "example",
+		PrimaryDomain: "example.com",
+		Domains: []models.Domain{
+			{Domain: "example.com"},
+			{Domain: "www.example.com"},
+			{Domain: "shop.example.com"},
+		},
+	}
+
+	got, err := DomainsForBulkRemoval(site, false)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !reflect.DeepEqual(got, []string{"www.example.com", "shop.example.com"}) {
+		t.Errorf("without primary = %v", got)
+	}
+
+	got, err = DomainsForBulkRemoval(site, true)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !reflect.DeepEqual(got, []string{"www.example.com", "shop.example.com", "example.com"}) {
+		t.Errorf("with primary = %v, want primary last", got)
+	}
+
+	primaryOnly := &models.Site{SiteID: "solo", PrimaryDomain: "solo.com", Domains: []models.Domain{{Domain: "solo.com"}}}
+	if _, err := DomainsForBulkRemoval(primaryOnly, false); err == nil {
+		t.Error("expected error when only the primary domain exists")
+	}
+	if got, err := DomainsForBulkRemoval(primaryOnly, true); err != nil || !reflect.DeepEqual(got, []string{"solo.com"}) {
+		t.Errorf("primary only with include = %v, %v", got, err)
+	}
+
+	empty := &models.Site{SiteID: "empty", PrimaryDomain: "empty.com"}
+	if _, err := DomainsForBulkRemoval(empty, true); err == nil {
+		t.Error("expected error for a site without domains")
+	}
+}
+
+func TestValidatePrimaryDomainChange(t *testing.T) {
+	site := &models.Site{
+		SiteID:        "example",
+		PrimaryDomain: "example.com",
+		Domains:       []models.Domain{{Domain: "example.com"}, {Domain: "www.example.com"}},
+	}
+
+	tests := []struct {
+		domain  string
+		wantErr bool
+	}{
+		{"www.example.com", false},
+		{"example.com", true},
+		{"other.com", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.domain, func(t *testing.T) {
+			err := ValidatePrimaryDomainChange(site, tt.domain)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ValidatePrimaryDomainChange(%q) error = %v, wantErr %v", tt.domain, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestValidateDomainRedirect(t *testing.T) {
+	site := &models.Site{
+		SiteID:        "example",
+		PrimaryDomain: "example.com",
+		Domains: []models.Domain{
+			{Domain: "example.com"},
+			{Domain: "www.example.com"},
+			{Domain: "old.example.com", RedirectTo: "example.com"},
+		},
+	}
+
+	tests := []struct {
+		name    string
+		from    string
+		to      string
+		wantErr bool
+	}{
+		{"www to apex", "www.example.com", "example.com", false},
+		{"apex to www", "example.com", "www.example.com", false},
+		{"to itself", "example.com", "example.com", true},
+		{"unknown source", "shop.example.com", "example.com", true},
+		{"unknown target", "www.example.com", "example.org", true},
+		{"chained target", "www.example.com", "old.example.com", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateDomainRedirect(site, tt.from, tt.to)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ValidateDomainRedirect(%q, %q) error = %v, wantErr %v", tt.from, tt.to, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestRedirectSourcesFor(t *testing.T) {
+	site := &models.Site{
+		Domains: []models.Domain{
+			{Domain: "example.com"},
+			{Domain: "www.example.com", RedirectTo: "example.com"},
+			{Domain: "shop.example.com", RedirectTo: "store.example.com"},
+			{Domain: "store.example.com"},
+		},
+	}
+
+	if got := RedirectSourcesFor(site, []string{"example.com"}); !reflect.DeepEqual(got, []string{"www.example.com"}) {
+		t.Errorf("RedirectSourcesFor(example.com) = %v", got)
+	}
+	// A source removed together with its target needs no cleanup
+	if got := RedirectSourcesFor(site, []string{"shop.example.com", "store.example.com"}); len(got) != 0 {
+		t.Errorf("RedirectSourcesFor(shop, store) = %v, want none", got)
+	}
+}
@@ -0,0 +1,60 @@
+package utils
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/wordsail/cli/pkg/models"
+)
+
+// Log types shown by `wordsail logs`
+const (
+	LogError  = "error"
+	LogAccess = "access"
+	LogPHP    = "php"
+)
+
+// LogTypes lists the accepted --type values
+var LogTypes = []string{LogError, LogAccess, LogPHP}
+
+// SiteLogPath returns the log file of a site on its server. Nginx writes an
+// access and error log per domain (to /sites/<domain>/logs); PHP-FPM writes
+// the site's PHP errors to debug.log in the primary domain's home. domain
+// selects one of the site's domains and defaults to the primary one.
+func SiteLogPath(site models.Site, logType, domain string) (string, error) {
+	if domain == "" {
+		domain = site.PrimaryDomain
+	}
+	attached := domain == site.PrimaryDomain
+	for _, d := range site.Domains {
+		if d.Domain == domain {
+			attached = true
+		}
+	}
+	if !attached {
+		return "", fmt.Errorf("domain '%s' is not attached to site '%s'", domain, site.SiteID)
+	}
+
+	switch logType {
+	case LogError, LogAccess:
+		return fmt.Sprintf("/sites/%s/logs/%s.log", domain, logType), nil
+	case LogPHP:
+		if domain != site.PrimaryDomain {
+			return "", fmt.Errorf("the PHP log belongs to the primary domain %s", site.PrimaryDomain)
+		}
+		return siteHome(site) + "/logs/debug.log", nil
+	default:
+		return "", fmt.Errorf("unknown log type '%s' (supported: %s)", logType, strings.Join(LogTypes, ", "))
+	}
+}
+
+// TailLogCommand builds the remote command printing the last lines of a log
+// file. With follow it keeps printing new lines, across log rotation, until
+// the connection is closed.
+func TailLogCommand(path string, lines int, follow bool) string {
+	flag := ""
+	if follow {
+		flag = " -F"
+	}
+	return fmt.Sprintf("sudo -n tail -n %d%s -- %s", lines, flag, ShellQuote(path))
+}
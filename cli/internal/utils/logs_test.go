@@ -0,0 +1,52 @@
+package utils
+
+import (
+	"testing"
+
+	"github.com/wordsail/cli/pkg/models"
+)
+
+func TestSiteLogPath(t *testing.T) {
+	site := models.Site{
+		SiteID:        "examplecom",
+		PrimaryDomain: "example.com",
+		Domains:       []models.Domain{{Domain: "example.com"}, {Domain: "www.example.com"}},
+	}
+
+	tests := []struct {
+		name    string
+		logType string
+		domain  string
+		want    string
+		wantErr bool
+	}{
+		{name: "error log", logType: LogError, want: "/sites/example.com/logs/error.log"},
+		{name: "access log", logType: LogAccess, want: "/sites/example.com/logs/access.log"},
+		{name: "php log", logType: LogPHP, want: "/sites/example.com/logs/debug.log"},
+		{name: "other domain", logType: LogAccess, domain: "www.example.com", want: "/sites/www.example.com/logs/access.log"},
+		{name: "php log of other domain", logType: LogPHP, domain: "www.example.com", wantErr: true},
+		{name: "unattached domain", logType: LogError, domain: "example.org", wantErr: true},
+		{name: "unknown type", logType: "slow", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := SiteLogPath(site, tt.logType, tt.domain)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("SiteLogPath() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("SiteLogPath() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTailLogCommand(t *testing.T) {
+	path := "/sites/example.com/logs/error.log"
+	if got, want := TailLogCommand(path, 100, false), "sudo -n tail -n 100 -- '/sites/example.com/logs/error.log'"; got != want {
+		t.Errorf("TailLogCommand() = %q, want %q", got, want)
+	}
+	if got, want := TailLogCommand(path, 20, true), "sudo -n tail -n 20 -F -- '/sites/example.com/logs/error.log'"; got != want {
+		t.Errorf("TailLogCommand() with follow = %q, want %q", got, want)
+	}
+}
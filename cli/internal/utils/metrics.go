@@ -0,0 +1,134 @@
+package utils
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/wordsail/cli/pkg/models"
+)
+
+// metricsCommand prints the root filesystem usage, memory and load average
+// in a format parseServerMetrics understands
+const metricsCommand = `df -hP / | tail -n 1; free -m | awk '/^Mem:/'; cat /proc/loadavg`
+
+// ServerMetrics holds basic resource usage gathered over SSH
+type ServerMetrics struct {
+	DiskUsedPercent int     `json:"disk_used_percent"`
+	MemTotalMB      int     `json:"mem_total_mb"`
+	MemUsedMB       int     `json:"mem_used_mb"`
+	Load1           float64 `json:"load_1"`
+	Load5           float64 `json:"load_5"`
+	Load15          float64 `json:"load_15"`
+}
+
+// MemUsedPercent returns memory usage as a percentage of the total
+func (m ServerMetrics) MemUsedPercent() int {
+	if m.MemTotalMB == 0 {
+		return 0
+	}
+	return m.MemUsedMB * 100 / m.MemTotalMB
+}
+
+// CollectServerMetrics runs the metrics one-liner on a server
+func CollectServerMetrics(server models.Server) (*ServerMetrics, error) {
+	output, err := RunSSHCommand(server, metricsCommand)
+	if err != nil {
+		return nil, err
+	}
+	return parseServerMetrics(output)
+}
+
+// CollectMetrics gathers metrics for all servers using a pool of workers.
+// Servers that fail (e.g. unreachable) map to a nil entry.
+func CollectMetrics(servers []models.Server, workers int, collect func(models.Server) (*ServerMetrics, error)) map[string]*ServerMetrics {
+	if workers < 1 {
+		workers = 1
+	}
+
+	results := make(map[string]*ServerMetrics, len(servers))
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+
+	jobs := make(chan models.Server)
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for server := range jobs {
+				metrics, err := collect(server)
+				if err != nil {
+					metrics = nil
+				}
+				mu.Lock()
+				results[server.Name] = metrics
+				mu.Unlock()
+			}
+		}()
+	}
+
+	for _, server := range servers {
+		jobs <- server
+	}
+	close(jobs)
+	wg.Wait()
+
+	return results
+}
+
+// parseServerMetrics parses the output of metricsCommand:
+//
+//	/dev/vda1  25G  9.1G  15G  38% /
+//	Mem:  3931  1203  312  20  2415  2455
+//	0.15 0.10 0.05 1/123 4567
+func parseServerMetrics(output string) (*ServerMetrics, error) {
+	var metrics ServerMetrics
+	var haveDisk, haveMem, haveLoad bool
+
+	for _, line := range strings.Split(output, "\n") {
+		fields := strings.Fields(line)
+		switch {
+		case len(fields) == 0:
+			continue
+
+		case fields[0] == "Mem:" && len(fields) >= 3:
+			total, err1 := strconv.Atoi(fields[1])
+			used, err2 := strconv.Atoi(fields[2])
+			if err1 != nil || err2 != nil {
+				return nil, fmt.Errorf("unexpected memory line: %q", line)
+			}
+			metrics.MemTotalMB, metrics.MemUsedMB = total, used
+			haveMem = true
+
+		case len(fields) >= 6 && strings.HasSuffix(fields[4], "%"):
+			percent, err := strconv.Atoi(strings.TrimSuffix(fields[4], "%"))
+			if err != nil {
+				return nil, fmt.Errorf("unexpected disk line: %q", line)
+			}
+			metrics.DiskUsedPercent = percent
+			haveDisk = true
+
+		case len(fields) >= 3 && !haveLoad:
+			loads := make([]float64, 3)
+			valid := true
+			for i := range loads {
+				value, err := strconv.ParseFloat(fields[i], 64)
+				if err != nil {
+					valid = false
+					break
+				}
+				loads[i] = value
+			}
+			if valid {
+				metrics.Load1, metrics.Load5, metrics.Load15 = loads[0], loads[1], loads[2]
+				haveLoad = true
+			}
+		}
+	}
+
+	if !haveDisk || !haveMem || !haveLoad {
+		return nil, fmt.Errorf("incomplete metrics output")
+	}
+	return &metrics, nil
+}
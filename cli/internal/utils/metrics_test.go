@@ -0,0 +1,65 @@
+package utils
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/wordsail/cli/pkg/models"
+)
+
+func TestParseServerMetrics(t *testing.T) {
+	output := `/dev/vda1        25G  9.1G   15G  38% /
+Mem:           3931        1203         312          20        2415        2455
+0.15 0.10 0.05 1/123 4567
+`
+	got, err := parseServerMetrics(output)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	want := ServerMetrics{DiskUsedPercent: 38, MemTotalMB: 3931, MemUsedMB: 1203, Load1: 0.15, Load5: 0.10, Load15: 0.05}
+	if *got != want {
+		t.Errorf("parseServerMetrics() = %+v, want %+v", *got, want)
+	}
+	if got.MemUsedPercent() != 30 {
+		t.Errorf("MemUsedPercent() = %d, want 30", got.MemUsedPercent())
+	}
+}
+
+func TestParseServerMetricsIncomplete(t *testing.T) {
+	tests := map[string]string{
+		"empty":        "",
+		"missing load": "/dev/vda1 25G 9.1G 15G 38% /\nMem: 3931 1203 312 20 2415 2455\n",
+		"missing mem":  "/dev/vda1 25G 9.1G 15G 38% /\n0.15 0.10 0.05 1/123 4567\n",
+		"bad memory":   "/dev/vda1 25G 9.1G 15G 38% /\nMem: lots some\n0.15 0.10 0.05 1/123 4567\n",
+	}
+
+	for name, output := range tests {
+		t.Run(name, func(t *testing.T) {
+			if _, err := parseServerMetrics(output); err == nil {
+				t.Errorf("expected error for %q", output)
+			}
+		})
+	}
+}
+
+func TestCollectMetrics(t *testing.T) {
+	servers := []models.Server{{Name: "web1"}, {Name: "web2"}, {Name: "down"}}
+
+	results := CollectMetrics(servers, 2, func(server models.Server) (*ServerMetrics, error) {
+		if server.Name == "down" {
+			return nil, errors.New("connection refused")
+		}
+		return &ServerMetrics{DiskUsedPercent: len(server.Name)}, nil
+	})
+
+	if len(results) != 3 {
+		t.Fatalf("got %d results, want 3", len(results))
+	}
+	if results["web1"] == nil || results["web2"] == nil {
+		t.Errorf("expected metrics for reachable servers, got %+v", results)
+	}
+	if m, ok := results["down"]; !ok || m != nil {
+		t.Errorf("expected nil metrics for unreachable server, got %+v", m)
+	}
+}
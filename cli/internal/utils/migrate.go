@@ -0,0 +1,162 @@
+package utils
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/wordsail/cli/pkg/models"
+)
+
+// siteHome returns the directory holding a site's files directory
+func siteHome(site models.Site) string {
+	return fmt.Sprintf("/sites/%s", site.PrimaryDomain)
+}
+
+// SiteArchiveCommand builds the remote command that writes a gzipped tar of
+// a site's files to stdout, leaving out the exclude patterns (see
+// TarExcludeArgs; a leading "/" anchors to the site's files directory) and,
+// with useDefaults, DefaultRsyncExcludes
+func SiteArchiveCommand(site models.Site, excludes []string, useDefaults bool) (string, error) {
+	args, err := TarExcludeArgs("./files", excludes, useDefaults)
+	if err != nil {
+		return "", err
+	}
+	command := "sudo -n tar -czf - -C " + ShellQuote(siteHome(site))
+	for _, arg := range args {
+		command += " " + ShellQuote(arg)
+	}
+	return command + " ./files", nil
+}
+
+// SiteExtractCommand builds the remote command that unpacks a tar from
+// SiteArchiveCommand into a site's home and hands the files to the site
+// user, whose uid differs between servers
+func SiteExtractCommand(site models.Site) string {
+	home := ShellQuote(siteHome(site))
+	owner := ShellQuote(site.SiteID + ":" + site.SiteID)
+	return fmt.Sprintf("sudo -n tar -xzf - -C %s && sudo -n chown -R %s %s/files", home, owner, home)
+}
+
+// PlaybookRun is a playbook and the extra vars to run it with
+type PlaybookRun struct {
+	Playbook  string
+	ExtraVars map[string]interface{}
+}
+
+// SiteExtrasPlaybooks returns the playbook runs that set up on a new server
+// what website.yml does not: the site's other domains, then its redirects,
+// then its PHP extensions. Each run is safe to repeat.
+func SiteExtrasPlaybooks(site models.Site) []PlaybookRun {
+	var runs []PlaybookRun
+	for _, domain := range site.Domains {
+		if domain.Domain == site.PrimaryDomain {
+			continue
+		}
+		runs = append(runs, PlaybookRun{Playbook: "playbooks/domain_management.yml", ExtraVars: map[string]interface{}{
+			"operation": "add_domain",
+			"domain":    domain.Domain,
+			"site_id":   site.SiteID,
+		}})
+	}
+	for _, domain := range site.Domains {
+		if domain.RedirectTo == "" {
+			continue
+		}
+		runs = append(runs, PlaybookRun{Playbook: "playbooks/domain_management.yml", ExtraVars: map[string]interface{}{
+			"operation":   "redirect_domain",
+			"domain":      domain.Domain,
+			"redirect_to": domain.RedirectTo,
+		}})
+	}
+	for _, extension := range site.PHPExtensions {
+		extraVars := map[string]interface{}{
+			"php_extension":       extension,
+			"php_extension_state": "enabled",
+		}
+		if site.PHPVersion != "" {
+			extraVars["php_version"] = site.PHPVersion
+		}
+		runs = append(runs, PlaybookRun{Playbook: "playbooks/php_extension.yml", ExtraVars: extraVars})
+	}
+	return runs
+}
+
+// PipeSSHCommands streams the stdout of a command on one server into the
+// stdin of a command on another, through this machine. prefix is sent to
+// the destination ahead of the stream. onProgress, if set, receives the
+// number of bytes streamed so far.
+func PipeSSHCommands(src models.Server, srcCommand string, srcStdin io.Reader,
+	dst models.Server, dstCommand string, prefix io.Reader, onProgress func(int64)) error {
+	reader, writer := io.Pipe()
+
+	srcDone := make(chan error, 1)
+	go func() {
+		err := StreamSSHCommand(src, srcCommand, srcStdin, writer)
+		writer.CloseWithError(err)
+		srcDone <- err
+	}()
+
+	stdin := io.MultiReader(prefix, &ProgressReader{R: reader, OnProgress: onProgress})
+	dstErr := StreamSSHCommand(dst, dstCommand, stdin, io.Discard)
+	// Unblock the source if the destination gave up early
+	reader.CloseWithError(fmt.Errorf("destination closed"))
+	srcErr := <-srcDone
+
+	switch {
+	case srcErr != nil && dstErr != nil:
+		// One failure usually causes the other; report both
+		return fmt.Errorf("%s: %v (%s: %v)", dst.Name, dstErr, src.Name, srcErr)
+	case srcErr != nil:
+		return fmt.Errorf("%s: %w", src.Name, srcErr)
+	case dstErr != nil:
+		return fmt.Errorf("%s: %w", dst.Name, dstErr)
+	}
+	return nil
+}
+
+// SearchReplaceArgs builds the WP-CLI arguments that replace a string in
+// every table, printing the number of replacements
+func SearchReplaceArgs(from, to string) string {
+	return fmt.Sprintf("search-replace %s %s --all-tables --skip-columns=guid --format=count",
+		ShellQuote(from), ShellQuote(to))
+}
+
+// SiteResponseCommand builds the remote command that requests a site's home
+// page from the local Nginx and prints the HTTP status code
+func SiteResponseCommand(site models.Site) string {
+	return fmt.Sprintf("curl -s -o /dev/null -w '%%{http_code}' --max-time 20 -H %s http://127.0.0.1/",
+		ShellQuote("Host: "+site.PrimaryDomain))
+}
+
+// CheckSiteResponse interprets the output of SiteResponseCommand. Redirects
+// count as working: WordPress sends plain HTTP requests to https.
+func CheckSiteResponse(output string) error {
+	code, err := strconv.Atoi(strings.TrimSpace(output))
+	if err != nil {
+		return fmt.Errorf("unexpected response check output: %q", strings.TrimSpace(output))
+	}
+	if code < 200 || code >= 400 {
+		return fmt.Errorf("site answered with HTTP %d", code)
+	}
+	return nil
+}
+
+// SetWPConfigDB points a site's wp-config.php at the given database. Each
+// value is sent on stdin, so the password never appears in a command line
+// or shell history on the server.
+func SetWPConfigDB(server models.Server, site models.Site, db models.Database, password string) error {
+	for _, constant := range []struct{ name, value string }{
+		{"DB_NAME", db.Name},
+		{"DB_USER", db.User},
+		{"DB_PASSWORD", password},
+		{"DB_HOST", db.Host},
+	} {
+		command := WPCLICommand(site, fmt.Sprintf(`config set %s "$(cat)" --type=constant --quiet`, constant.name))
+		if err := StreamSSHCommand(server, command, strings.NewReader(constant.value), io.Discard); err != nil {
+			return fmt.Errorf("failed to set %s: %w", constant.name, err)
+		}
+	}
+	return nil
+}
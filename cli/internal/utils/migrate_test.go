@@ -0,0 +1,129 @@
+package utils
+
+import (
+	"io"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/wordsail/cli/pkg/models"
+	"golang.org/x/crypto/ssh"
+)
+
+func TestSiteTransferCommands(t *testing.T) {
+	site := models.Site{SiteID: "examplecom", PrimaryDomain: "example.com"}
+
+	archive, err := SiteArchiveCommand(site, nil, true)
+	if err != nil {
+		t.Fatalf("SiteArchiveCommand() error = %v", err)
+	}
+	if want := "sudo -n tar -czf - -C '/sites/example.com' '--anchored' '--exclude=./files/wp-content/cache' '--no-anchored' '--exclude=*.log' ./files"; archive != want {
+		t.Errorf("SiteArchiveCommand() = %s, want %s", archive, want)
+	}
+	archive, err = SiteArchiveCommand(site, []string{"/wp-content/uploads/2019/"}, false)
+	if err != nil {
+		t.Fatalf("SiteArchiveCommand() error = %v", err)
+	}
+	if want := "sudo -n tar -czf - -C '/sites/example.com' '--anchored' '--exclude=./files/wp-content/uploads/2019' '--no-anchored' ./files"; archive != want {
+		t.Errorf("SiteArchiveCommand() = %s, want %s", archive, want)
+	}
+	if _, err := SiteArchiveCommand(site, []string{""}, true); err == nil {
+		t.Error("SiteArchiveCommand() with an empty pattern should fail")
+	}
+	extract := SiteExtractCommand(site)
+	for _, want := range []string{"tar -xzf - -C '/sites/example.com'", "chown -R 'examplecom:examplecom' '/sites/example.com'/files"} {
+		if !strings.Contains(extract, want) {
+			t.Errorf("SiteExtractCommand() = %s, missing %q", extract, want)
+		}
+	}
+
+	if got, want := SearchReplaceArgs("203.0.113.10", "203.0.113.11"), "search-replace '203.0.113.10' '203.0.113.11' --all-tables --skip-columns=guid --format=count"; got != want {
+		t.Errorf("SearchReplaceArgs() = %s, want %s", got, want)
+	}
+	if got := SiteResponseCommand(site); !strings.Contains(got, "-H 'Host: example.com' http://127.0.0.1/") {
+		t.Errorf("SiteResponseCommand() = %s", got)
+	}
+}
+
+func TestSiteExtrasPlaybooks(t *testing.T) {
+	site := models.Site{
+		SiteID:        "examplecom",
+		PrimaryDomain: "example.com",
+		PHPVersion:    "8.2",
+		Domains: []models.Domain{
+			{Domain: "example.com"},
+			{Domain: "www.example.com", RedirectTo: "example.com"},
+			{Domain: "shop.example.com"},
+		},
+		PHPExtensions: []string{"imagick"},
+	}
+
+	want := []PlaybookRun{
+		{Playbook: "playbooks/domain_management.yml", ExtraVars: map[string]interface{}{
+			"operation": "add_domain", "domain": "www.example.com", "site_id": "examplecom"}},
+		{Playbook: "playbooks/domain_management.yml", ExtraVars: map[string]interface{}{
+			"operation": "add_domain", "domain": "shop.example.com", "site_id": "examplecom"}},
+		{Playbook: "playbooks/domain_management.yml", ExtraVars: map[string]interface{}{
+			"operation": "redirect_domain", "domain": "www.example.com", "redirect_to": "example.com"}},
+		{Playbook: "playbooks/php_extension.yml", ExtraVars: map[string]interface{}{
+			"php_extension": "imagick", "php_extension_state": "enabled", "php_version": "8.2"}},
+	}
+	if got := SiteExtrasPlaybooks(site); !reflect.DeepEqual(got, want) {
+		t.Errorf("SiteExtrasPlaybooks() = %+v, want %+v", got, want)
+	}
+
+	single := models.Site{SiteID: "blog", PrimaryDomain: "blog.example.com", Domains: []models.Domain{{Domain: "blog.example.com"}}}
+	if got := SiteExtrasPlaybooks(single); len(got) != 0 {
+		t.Errorf("SiteExtrasPlaybooks() for a single-domain site = %+v, want none", got)
+	}
+}
+
+func TestCheckSiteResponse(t *testing.T) {
+	tests := []struct {
+		output  string
+		wantErr bool
+	}{
+		{"200", false},
+		{"301\n", false},
+		{"404", true},
+		{"502", true},
+		{"000", true},
+		{"curl: (7) Failed to connect", true},
+	}
+	for _, tt := range tests {
+		if err := CheckSiteResponse(tt.output); (err != nil) != tt.wantErr {
+			t.Errorf("CheckSiteResponse(%q) error = %v, wantErr %v", tt.output, err, tt.wantErr)
+		}
+	}
+}
+
+func TestSetWPConfigDBSendsValuesOnStdin(t *testing.T) {
+	var mu sync.Mutex
+	set := map[string]string{}
+	server := testSSHServer(t, func(command string, channel ssh.Channel) uint32 {
+		if strings.Contains(command, "hunter2") {
+			t.Errorf("password in command line: %s", command)
+		}
+		fields := strings.Fields(command)
+		value, _ := io.ReadAll(channel)
+		mu.Lock()
+		defer mu.Unlock()
+		for i, field := range fields {
+			if field == "set" && i+1 < len(fields) {
+				set[fields[i+1]] = string(value)
+			}
+		}
+		return 0
+	})
+	site := models.Site{SiteID: "examplecom", PrimaryDomain: "example.com"}
+	db := models.Database{Name: "shop", User: "shop", Host: "localhost"}
+
+	if err := SetWPConfigDB(server, site, db, "hunter2"); err != nil {
+		t.Fatalf("SetWPConfigDB() error = %v", err)
+	}
+	want := map[string]string{"DB_NAME": "shop", "DB_USER": "shop", "DB_PASSWORD": "hunter2", "DB_HOST": "localhost"}
+	if !reflect.DeepEqual(set, want) {
+		t.Errorf("constants set = %v, want %v", set, want)
+	}
+}
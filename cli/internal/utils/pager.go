@@ -0,0 +1,70 @@
+package utils
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+
+	"golang.org/x/term"
+)
+
+// PagerEnabled lets Page use a pager; --no-pager turns it off
+var PagerEnabled = true
+
+// defaultPager is used when $PAGER is not set. -R keeps the colors.
+var defaultPager = []string{"less", "-R"}
+
+// Page prints output, through $PAGER (less by default) when stdin and
+// stdout are terminals and the output is taller than the terminal. Piped
+// output, --no-pager and a pager that cannot be started print it directly.
+func Page(output string) {
+	pager := pagerCommand(os.Getenv("PAGER"))
+	if !PagerEnabled || pager == nil || !needsPager(output) {
+		fmt.Print(output)
+		return
+	}
+
+	cmd := exec.Command(pager[0], pager[1:]...)
+	cmd.Stdin = strings.NewReader(output)
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	// A pager that ran already showed the output, even if it failed
+	var exitErr *exec.ExitError
+	if err := cmd.Run(); err != nil && !errors.As(err, &exitErr) {
+		fmt.Print(output)
+	}
+}
+
+// pagerCommand splits $PAGER into a command, defaulting to less. An empty
+// result ("cat" or a blank $PAGER) means output is printed directly.
+func pagerCommand(env string) []string {
+	if env == "" {
+		return defaultPager
+	}
+	fields := strings.Fields(env)
+	if len(fields) == 0 || fields[0] == "cat" {
+		return nil
+	}
+	return fields
+}
+
+// needsPager reports whether output is taller than the terminal, which must
+// be on both stdin (for the pager's keys) and stdout
+func needsPager(output string) bool {
+	if !term.IsTerminal(int(os.Stdin.Fd())) {
+		return false
+	}
+	_, height, err := term.GetSize(int(os.Stdout.Fd()))
+	if err != nil {
+		return false
+	}
+	return exceedsHeight(output, height)
+}
+
+// exceedsHeight reports whether output has more lines than fit in height
+// rows, leaving one for the shell prompt
+func exceedsHeight(output string, height int) bool {
+	return strings.Count(output, "\n") > height-1
+}
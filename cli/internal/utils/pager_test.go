@@ -0,0 +1,48 @@
+package utils
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestPagerCommand(t *testing.T) {
+	tests := []struct {
+		env  string
+		want []string
+	}{
+		{"", []string{"less", "-R"}},
+		{"more", []string{"more"}},
+		{"less -S -R", []string{"less", "-S", "-R"}},
+		{"cat", nil},
+		{"   ", nil},
+	}
+	for _, tt := range tests {
+		if got := pagerCommand(tt.env); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("pagerCommand(%q) = %q, want %q", tt.env, got, tt.want)
+		}
+	}
+}
+
+func TestExceedsHeight(t *testing.T) {
+	output := strings.Repeat("row\n", 24)
+	if exceedsHeight(output, 25) {
+		t.Error("24 lines exceed a 25-row terminal")
+	}
+	if !exceedsHeight(output, 24) {
+		t.Error("24 lines fit a 24-row terminal, which also needs a row for the prompt")
+	}
+}
+
+func TestWriteTable(t *testing.T) {
+	var b strings.Builder
+	writeTable(&b, []string{"NAME", "SITES"}, [][]string{{"web1", "3"}}, []int{4, 5})
+	want := "┌──────────────┐\n" +
+		"│ NAME │ SITES │\n" +
+		"├──────┼───────┤\n" +
+		"│ web1 │ 3     │\n" +
+		"└──────────────┘\n"
+	if got := b.String(); got != want {
+		t.Errorf("writeTable() =\n%s\nwant\n%s", got, want)
+	}
+}
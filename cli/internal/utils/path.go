@@ -0,0 +1,41 @@
+package utils
+
+import (
+	"fmt"
+	"os"
+	"os/user"
+	"path/filepath"
+	"strings"
+)
+
+// ExpandPath expands environment variables ($VAR and ${VAR}) and a leading
+// ~ or ~user the way a shell would. Environment variables are expanded
+// first, so a variable holding a ~ path is expanded too.
+func ExpandPath(p string) (string, error) {
+	p = os.ExpandEnv(p)
+	if !strings.HasPrefix(p, "~") {
+		return p, nil
+	}
+
+	name, rest := p[1:], ""
+	if i := strings.IndexRune(name, '/'); i >= 0 {
+		name, rest = name[:i], name[i:]
+	}
+
+	var homeDir string
+	if name == "" {
+		dir, err := os.UserHomeDir()
+		if err != nil {
+			return "", fmt.Errorf("failed to expand home directory: %w", err)
+		}
+		homeDir = dir
+	} else {
+		u, err := user.Lookup(name)
+		if err != nil {
+			return "", fmt.Errorf("failed to expand ~%s: %w", name, err)
+		}
+		homeDir = u.HomeDir
+	}
+
+	return filepath.Join(homeDir, rest), nil
+}
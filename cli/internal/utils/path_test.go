@@ -0,0 +1,50 @@
+package utils
+
+import (
+	"os/user"
+	"path/filepath"
+	"testing"
+)
+
+func TestExpandPath(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("KEY_DIR", "/srv/keys")
+	t.Setenv("TILDE_DIR", "~/.ssh")
+
+	current, err := user.Current()
+	if err != nil {
+		t.Skipf("cannot look up current user: %v", err)
+	}
+
+	tests := []struct {
+		name    string
+		input   string
+		want    string
+		wantErr bool
+	}{
+		{"absolute", "/etc/wordsail", "/etc/wordsail", false},
+		{"relative", "keys/id_ed25519", "keys/id_ed25519", false},
+		{"empty", "", "", false},
+		{"tilde", "~", home, false},
+		{"tilde slash", "~/.ssh/id_ed25519", filepath.Join(home, ".ssh/id_ed25519"), false},
+		{"home var", "$HOME/.ssh/id_ed25519", filepath.Join(home, ".ssh/id_ed25519"), false},
+		{"braced var", "${KEY_DIR}/id_ed25519", "/srv/keys/id_ed25519", false},
+		{"var holding tilde", "$TILDE_DIR/id_ed25519", filepath.Join(home, ".ssh/id_ed25519"), false},
+		{"tilde user", "~" + current.Username + "/.ssh", filepath.Join(current.HomeDir, ".ssh"), false},
+		{"tilde not leading", "/keys/~/id", "/keys/~/id", false},
+		{"unknown user", "~no-such-wordsail-user/.ssh", "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ExpandPath(tt.input)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ExpandPath(%q) error = %v, wantErr %v", tt.input, err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("ExpandPath(%q) = %q, want %q", tt.input, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,114 @@
+package utils
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/wordsail/cli/pkg/models"
+)
+
+// KnownPHPExtensions are the extensions `site php-ext` can manage. Each name
+// is the Ubuntu package suffix (php8.3-<name>), the module name passed to
+// phpenmod and the name `php -m` reports.
+var KnownPHPExtensions = []string{
+	"apcu",
+	"bcmath",
+	"bz2",
+	"curl",
+	"gd",
+	"gmp",
+	"igbinary",
+	"imagick",
+	"imap",
+	"intl",
+	"ldap",
+	"mbstring",
+	"memcached",
+	"mongodb",
+	"msgpack",
+	"pgsql",
+	"redis",
+	"soap",
+	"sqlite3",
+	"tidy",
+	"xmlrpc",
+	"yaml",
+	"zip",
+}
+
+// ValidatePHPExtension checks that an extension is one of KnownPHPExtensions
+func ValidatePHPExtension(val interface{}) error {
+	name, ok := val.(string)
+	if !ok {
+		return fmt.Errorf("invalid extension type")
+	}
+
+	for _, known := range KnownPHPExtensions {
+		if name == known {
+			return nil
+		}
+	}
+	return fmt.Errorf("unknown PHP extension '%s' (supported: %s)", name, strings.Join(KnownPHPExtensions, ", "))
+}
+
+// ParsePHPModules parses `php -m` output into a sorted, lowercased list of
+// module names. Section headers and blank lines are skipped, and modules
+// listed under both [PHP Modules] and [Zend Modules] appear once.
+func ParsePHPModules(output string) []string {
+	seen := make(map[string]bool)
+	modules := []string{}
+	for _, line := range strings.Split(output, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "[") {
+			continue
+		}
+		name := strings.ToLower(line)
+		if seen[name] {
+			continue
+		}
+		seen[name] = true
+		modules = append(modules, name)
+	}
+	sort.Strings(modules)
+	return modules
+}
+
+// HasPHPModule reports whether ext is in a list returned by ParsePHPModules
+func HasPHPModule(modules []string, ext string) bool {
+	ext = strings.ToLower(ext)
+	for _, m := range modules {
+		if m == ext {
+			return true
+		}
+	}
+	return false
+}
+
+// SitesUsingPHPExtension returns the IDs of the server's sites that have ext
+// enabled, other than exceptSiteID
+func SitesUsingPHPExtension(server *models.Server, ext, exceptSiteID string) []string {
+	var sites []string
+	for _, site := range server.Sites {
+		if site.SiteID == exceptSiteID {
+			continue
+		}
+		for _, e := range site.PHPExtensions {
+			if e == ext {
+				sites = append(sites, site.SiteID)
+				break
+			}
+		}
+	}
+	return sites
+}
+
+// LoadedPHPModules lists the modules loaded by the given PHP version on the
+// server. An empty version uses the default php binary.
+func LoadedPHPModules(server models.Server, phpVersion string) ([]string, error) {
+	output, err := RunSSHCommand(server, "php"+phpVersion+" -m")
+	if err != nil {
+		return nil, fmt.Errorf("failed to list PHP modules: %s", firstLine(output, err))
+	}
+	return ParsePHPModules(output), nil
+}
@@ -0,0 +1,84 @@
+package utils
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/wordsail/cli/pkg/models"
+)
+
+func TestValidatePHPExtension(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   interface{}
+		wantErr bool
+	}{
+		{"imagick", "imagick", false},
+		{"intl", "intl", false},
+		{"redis", "redis", false},
+		{"soap", "soap", false},
+		{"unknown", "leftpad", true},
+		{"versioned package name", "php8.3-imagick", true},
+		{"wrong case", "Imagick", true},
+		{"shell injection", "redis; rm -rf /", true},
+		{"empty", "", true},
+		{"invalid type", 42, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidatePHPExtension(tt.input)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ValidatePHPExtension(%v) error = %v, wantErr %v", tt.input, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestParsePHPModules(t *testing.T) {
+	output := `[PHP Modules]
+calendar
+Core
+curl
+imagick
+intl
+mysqli
+redis
+Zend OPcache
+
+[Zend Modules]
+Zend OPcache
+
+`
+	want := []string{"calendar", "core", "curl", "imagick", "intl", "mysqli", "redis", "zend opcache"}
+	got := ParsePHPModules(output)
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("ParsePHPModules() = %v, want %v", got, want)
+	}
+
+	if !HasPHPModule(got, "imagick") || !HasPHPModule(got, "Redis") {
+		t.Error("HasPHPModule() missed a loaded module")
+	}
+	if HasPHPModule(got, "soap") {
+		t.Error("HasPHPModule() reported a module that is not loaded")
+	}
+
+	if got := ParsePHPModules(""); len(got) != 0 {
+		t.Errorf("ParsePHPModules(\"\") = %v, want empty", got)
+	}
+}
+
+func TestSitesUsingPHPExtension(t *testing.T) {
+	server := &models.Server{Sites: []models.Site{
+		{SiteID: "blog", PHPExtensions: []string{"imagick", "intl"}},
+		{SiteID: "shop", PHPExtensions: []string{"imagick", "soap"}},
+		{SiteID: "news"},
+	}}
+
+	if got := SitesUsingPHPExtension(server, "imagick", "blog"); !reflect.DeepEqual(got, []string{"shop"}) {
+		t.Errorf("SitesUsingPHPExtension(imagick) = %v, want [shop]", got)
+	}
+	if got := SitesUsingPHPExtension(server, "intl", "blog"); len(got) != 0 {
+		t.Errorf("SitesUsingPHPExtension(intl) = %v, want none", got)
+	}
+}
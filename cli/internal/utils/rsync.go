@@ -0,0 +1,82 @@
+package utils
+
+import (
+	"fmt"
+	"strings"
+)
+
+// DefaultRsyncExcludes are skipped when copying site files unless disabled
+// with --no-default-excludes. The cache pattern is anchored to the WordPress
+// root so libraries with a cache directory (vendor/psr/cache) are copied.
+var DefaultRsyncExcludes = []string{
+	"/wp-content/cache/",
+	"*.log",
+}
+
+// RsyncExcludeArgs builds rsync --exclude arguments from user patterns.
+// Patterns use rsync filter syntax: a trailing "/" matches directories only,
+// a leading "/" anchors to the transfer root, and "*", "**" and "?" are wildcards.
+// Defaults are prepended when useDefaults is true; duplicates are dropped.
+func RsyncExcludeArgs(patterns []string, useDefaults bool) ([]string, error) {
+	all, err := excludePatterns(patterns, useDefaults)
+	if err != nil {
+		return nil, err
+	}
+	args := make([]string, 0, len(all))
+	for _, pattern := range all {
+		args = append(args, "--exclude="+pattern)
+	}
+	return args, nil
+}
+
+// TarExcludeArgs builds GNU tar arguments that apply the same patterns as
+// RsyncExcludeArgs to an archive of root (e.g. "./files"). tar has no
+// directory-only patterns, so a trailing "/" is dropped and the pattern
+// also matches files of that name; a leading "/" anchors the pattern to
+// root. The arguments must come before the archived path.
+func TarExcludeArgs(root string, patterns []string, useDefaults bool) ([]string, error) {
+	all, err := excludePatterns(patterns, useDefaults)
+	if err != nil {
+		return nil, err
+	}
+	args := make([]string, 0, len(all))
+	for _, pattern := range all {
+		pattern = strings.TrimRight(pattern, "/")
+		if pattern == "" {
+			return nil, fmt.Errorf("exclude pattern must not be only '/'")
+		}
+		if anchored, ok := strings.CutPrefix(pattern, "/"); ok {
+			// --anchored applies to the excludes after it, so switch it
+			// back off for the next pattern
+			args = append(args, "--anchored", "--exclude="+strings.TrimRight(root, "/")+"/"+anchored, "--no-anchored")
+			continue
+		}
+		args = append(args, "--exclude="+pattern)
+	}
+	return args, nil
+}
+
+// excludePatterns trims the user patterns, prepends the defaults when
+// useDefaults is true and drops duplicates
+func excludePatterns(patterns []string, useDefaults bool) ([]string, error) {
+	var all []string
+	if useDefaults {
+		all = append(all, DefaultRsyncExcludes...)
+	}
+	all = append(all, patterns...)
+
+	seen := make(map[string]bool)
+	unique := make([]string, 0, len(all))
+	for _, pattern := range all {
+		pattern = strings.TrimSpace(pattern)
+		if pattern == "" {
+			return nil, fmt.Errorf("exclude pattern must not be empty")
+		}
+		if seen[pattern] {
+			continue
+		}
+		seen[pattern] = true
+		unique = append(unique, pattern)
+	}
+	return unique, nil
+}
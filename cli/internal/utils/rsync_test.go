@@ -0,0 +1,164 @@
+package utils
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+)
+
+func TestRsyncExcludeArgs(t *testing.T) {
+	tests := []struct {
+		name        string
+		patterns    []string
+		useDefaults bool
+		want        []string
+		wantErr     bool
+	}{
+		{"defaults only", nil, true, []string{"--exclude=/wp-content/cache/", "--exclude=*.log"}, false},
+		{"defaults disabled", nil, false, []string{}, false},
+		{"user patterns with defaults", []string{"wp-content/uploads/2019/"}, true,
+			[]string{"--exclude=/wp-content/cache/", "--exclude=*.log", "--exclude=wp-content/uploads/2019/"}, false},
+		{"user patterns without defaults", []string{"*.zip", "/backups/"}, false,
+			[]string{"--exclude=*.zip", "--exclude=/backups/"}, false},
+		{"duplicate of default dropped", []string{"/wp-content/cache/"}, true, []string{"--exclude=/wp-content/cache/", "--exclude=*.log"}, false},
+		{"whitespace trimmed", []string{"  *.tmp "}, false, []string{"--exclude=*.tmp"}, false},
+		{"invalid - empty pattern", []string{""}, true, nil, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := RsyncExcludeArgs(tt.patterns, tt.useDefaults)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("RsyncExcludeArgs() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("RsyncExcludeArgs() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTarExcludeArgs(t *testing.T) {
+	tests := []struct {
+		name        string
+		patterns    []string
+		useDefaults bool
+		want        []string
+		wantErr     bool
+	}{
+		{"defaults only", nil, true,
+			[]string{"--anchored", "--exclude=./files/wp-content/cache", "--no-anchored", "--exclude=*.log"}, false},
+		{"defaults disabled", nil, false, []string{}, false},
+		{"unanchored path", []string{"wp-content/uploads/2019/"}, false,
+			[]string{"--exclude=wp-content/uploads/2019"}, false},
+		{"anchored to the root", []string{"/backups/", "*.zip"}, false,
+			[]string{"--anchored", "--exclude=./files/backups", "--no-anchored", "--exclude=*.zip"}, false},
+		{"duplicate of default dropped", []string{"*.log"}, true,
+			[]string{"--anchored", "--exclude=./files/wp-content/cache", "--no-anchored", "--exclude=*.log"}, false},
+		{"invalid - only a slash", []string{"/"}, false, nil, true},
+		{"invalid - empty pattern", []string{" "}, true, nil, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := TarExcludeArgs("./files", tt.patterns, tt.useDefaults)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("TarExcludeArgs() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("TarExcludeArgs() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+// defaultExcludesTree lays out a site's files directory with a page cache
+// and a plugin library that has its own cache directory
+func defaultExcludesTree(t *testing.T) string {
+	t.Helper()
+	root := t.TempDir()
+	for _, name := range []string{
+		"files/index.php",
+		"files/wp-content/cache/page.html",
+		"files/wp-content/debug.log",
+		"files/wp-content/plugins/x/vendor/psr/cache/CacheItemInterface.php",
+	} {
+		path := filepath.Join(root, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, nil, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return root
+}
+
+// copiedFiles lists the regular files under dir, slash-separated
+func copiedFiles(t *testing.T, dir string) []string {
+	t.Helper()
+	var files []string
+	err := filepath.WalkDir(dir, func(path string, d os.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+		rel, _ := filepath.Rel(dir, path)
+		files = append(files, filepath.ToSlash(rel))
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	sort.Strings(files)
+	return files
+}
+
+func TestDefaultExcludesKeepLibraryCaches(t *testing.T) {
+	want := []string{"index.php", "wp-content/plugins/x/vendor/psr/cache/CacheItemInterface.php"}
+
+	t.Run("tar", func(t *testing.T) {
+		if out, err := exec.Command("tar", "--version").Output(); err != nil || !strings.Contains(string(out), "GNU tar") {
+			t.Skip("GNU tar not available")
+		}
+		root := defaultExcludesTree(t)
+		args, err := TarExcludeArgs("./files", nil, true)
+		if err != nil {
+			t.Fatal(err)
+		}
+		archive := filepath.Join(t.TempDir(), "files.tar")
+		create := append(append([]string{"-cf", archive, "-C", root}, args...), "./files")
+		if out, err := exec.Command("tar", create...).CombinedOutput(); err != nil {
+			t.Fatalf("tar -c: %v\n%s", err, out)
+		}
+		dest := t.TempDir()
+		if out, err := exec.Command("tar", "-xf", archive, "-C", dest).CombinedOutput(); err != nil {
+			t.Fatalf("tar -x: %v\n%s", err, out)
+		}
+		if got := copiedFiles(t, filepath.Join(dest, "files")); !reflect.DeepEqual(got, want) {
+			t.Errorf("copied %v, want %v", got, want)
+		}
+	})
+
+	t.Run("rsync", func(t *testing.T) {
+		if _, err := exec.LookPath("rsync"); err != nil {
+			t.Skip("rsync not available")
+		}
+		root := defaultExcludesTree(t)
+		args, err := RsyncExcludeArgs(nil, true)
+		if err != nil {
+			t.Fatal(err)
+		}
+		dest := t.TempDir()
+		command := append(append([]string{"-a"}, args...), filepath.Join(root, "files")+"/", dest+"/")
+		if out, err := exec.Command("rsync", command...).CombinedOutput(); err != nil {
+			t.Fatalf("rsync: %v\n%s", err, out)
+		}
+		if got := copiedFiles(t, dest); !reflect.DeepEqual(got, want) {
+			t.Errorf("copied %v, want %v", got, want)
+		}
+	})
+}
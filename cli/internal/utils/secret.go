@@ -0,0 +1,22 @@
+package utils
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// ReadSecret reads a secret passed on stdin: the first line, without its line
+// ending. Leading and trailing spaces are kept, as they may be part of it.
+func ReadSecret(r io.Reader) (string, error) {
+	line, err := bufio.NewReader(r).ReadString('\n')
+	if err != nil && err != io.EOF {
+		return "", fmt.Errorf("failed to read from stdin: %w", err)
+	}
+	secret := strings.TrimRight(line, "\r\n")
+	if secret == "" {
+		return "", fmt.Errorf("no value on stdin")
+	}
+	return secret, nil
+}
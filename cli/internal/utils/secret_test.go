@@ -0,0 +1,30 @@
+package utils
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestReadSecret(t *testing.T) {
+	tests := []struct {
+		input   string
+		want    string
+		wantErr bool
+	}{
+		{input: "S3cret!Passw0rd\n", want: "S3cret!Passw0rd"},
+		{input: "S3cret!Passw0rd\r\nignored\n", want: "S3cret!Passw0rd"},
+		{input: " padded ", want: " padded "},
+		{input: "", wantErr: true},
+		{input: "\n", wantErr: true},
+	}
+	for _, tt := range tests {
+		got, err := ReadSecret(strings.NewReader(tt.input))
+		if (err != nil) != tt.wantErr {
+			t.Errorf("ReadSecret(%q) error = %v, wantErr %v", tt.input, err, tt.wantErr)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("ReadSecret(%q) = %q, want %q", tt.input, got, tt.want)
+		}
+	}
+}
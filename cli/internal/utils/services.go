@@ -0,0 +1,85 @@
+package utils
+
+import (
+	"fmt"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/wordsail/cli/pkg/models"
+)
+
+// ServiceState is the systemd state of a unit on a server
+type ServiceState struct {
+	Name  string
+	State string // "active", "inactive", "failed", ... or "missing" if not installed
+}
+
+// Active reports whether the service is running
+func (s ServiceState) Active() bool {
+	return s.State == "active"
+}
+
+// CheckServices queries systemd for each unit pattern (e.g. "nginx",
+// "php*-fpm") and returns one state per matching unit. Patterns that match
+// no installed unit are reported as "missing".
+func CheckServices(server models.Server, patterns []string) ([]ServiceState, error) {
+	units := make([]string, len(patterns))
+	for i, pattern := range patterns {
+		units[i] = fmt.Sprintf("'%s.service'", pattern)
+	}
+
+	command := fmt.Sprintf(
+		`for u in $(systemctl list-units --type=service --all --no-legend --plain %s | awk '{print $1}'); do echo "$u $(systemctl is-active "$u")"; done`,
+		strings.Join(units, " "))
+
+	output, err := RunSSHCommand(server, command)
+	if err != nil {
+		return nil, err
+	}
+
+	return parseServiceStates(patterns, output), nil
+}
+
+// parseServiceStates matches "unit state" lines against the requested patterns
+func parseServiceStates(patterns []string, output string) []ServiceState {
+	var states []ServiceState
+	for _, pattern := range patterns {
+		found := false
+		for _, line := range strings.Split(output, "\n") {
+			fields := strings.Fields(line)
+			if len(fields) < 2 {
+				continue
+			}
+			name := strings.TrimSuffix(fields[0], ".service")
+			if ok, _ := path.Match(pattern, name); ok {
+				states = append(states, ServiceState{Name: name, State: fields[1]})
+				found = true
+			}
+		}
+		if !found {
+			states = append(states, ServiceState{Name: pattern, State: "missing"})
+		}
+	}
+	return states
+}
+
+// WaitForReachability polls probe every interval until its result matches
+// up (nil error means reachable) or timeout elapses. It returns the time the
+// wanted state was first observed.
+func WaitForReachability(probe func() error, up bool, interval, timeout time.Duration) (time.Time, error) {
+	deadline := time.Now().Add(timeout)
+	for {
+		err := probe()
+		if (err == nil) == up {
+			return time.Now(), nil
+		}
+		if time.Now().Add(interval).After(deadline) {
+			if up {
+				return time.Time{}, fmt.Errorf("server not reachable after %s: %w", timeout, err)
+			}
+			return time.Time{}, fmt.Errorf("server still reachable after %s", timeout)
+		}
+		time.Sleep(interval)
+	}
+}
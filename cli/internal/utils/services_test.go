@@ -0,0 +1,83 @@
+package utils
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestParseServiceStates(t *testing.T) {
+	output := "nginx.service active\nmariadb.service active\nphp8.3-fpm.service failed\n"
+
+	tests := []struct {
+		name     string
+		patterns []string
+		want     []ServiceState
+	}{
+		{
+			name:     "exact names",
+			patterns: []string{"nginx", "mariadb"},
+			want:     []ServiceState{{"nginx", "active"}, {"mariadb", "active"}},
+		},
+		{
+			name:     "glob pattern",
+			patterns: []string{"php*-fpm"},
+			want:     []ServiceState{{"php8.3-fpm", "failed"}},
+		},
+		{
+			name:     "missing unit",
+			patterns: []string{"mysql"},
+			want:     []ServiceState{{"mysql", "missing"}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := parseServiceStates(tt.patterns, output)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("parseServiceStates() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWaitForReachability(t *testing.T) {
+	errDown := errors.New("connection refused")
+
+	tests := []struct {
+		name    string
+		results []error // probe results in order; the last one repeats
+		up      bool
+		wantErr bool
+		wantN   int
+	}{
+		{"already up", []error{nil}, true, false, 1},
+		{"comes back", []error{errDown, errDown, nil}, true, false, 3},
+		{"never comes back", []error{errDown}, true, true, 0},
+		{"goes down", []error{nil, errDown}, false, false, 2},
+		{"never goes down", []error{nil}, false, true, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			calls := 0
+			probe := func() error {
+				i := calls
+				if i >= len(tt.results) {
+					i = len(tt.results) - 1
+				}
+				calls++
+				return tt.results[i]
+			}
+
+			_, err := WaitForReachability(probe, tt.up, time.Millisecond, 20*time.Millisecond)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("WaitForReachability() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && calls != tt.wantN {
+				t.Errorf("probe called %d times, want %d", calls, tt.wantN)
+			}
+		})
+	}
+}
@@ -0,0 +1,120 @@
+package utils
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/wordsail/cli/pkg/models"
+)
+
+// SiteSortKeys are the values accepted by site list --sort
+var SiteSortKeys = []string{"domain", "server", "created", "ssl-expiry"}
+
+// ServerSite is a site together with the name of the server it is on
+type ServerSite struct {
+	Server string
+	Site   models.Site
+}
+
+// ListServerSites returns every site in config order, optionally limited to
+// one server
+func ListServerSites(servers []models.Server, serverName string) []ServerSite {
+	sites := []ServerSite{}
+	for _, server := range servers {
+		if serverName != "" && server.Name != serverName {
+			continue
+		}
+		for _, site := range server.Sites {
+			sites = append(sites, ServerSite{Server: server.Name, Site: site})
+		}
+	}
+	return sites
+}
+
+// EarliestSSLExpiry returns the soonest recorded certificate expiry among a
+// site's SSL-enabled domains, or nil if none is recorded
+func EarliestSSLExpiry(site models.Site) *time.Time {
+	var earliest *time.Time
+	for _, d := range site.Domains {
+		if !d.SSLEnabled || d.SSLExpiresAt == nil {
+			continue
+		}
+		if earliest == nil || d.SSLExpiresAt.Before(*earliest) {
+			earliest = d.SSLExpiresAt
+		}
+	}
+	return earliest
+}
+
+// FilterExpiringSites keeps the sites whose earliest certificate expires
+// within days of now. Certificates that have already expired are kept.
+func FilterExpiringSites(sites []ServerSite, days int, now time.Time) []ServerSite {
+	cutoff := now.AddDate(0, 0, days)
+	expiring := []ServerSite{}
+	for _, s := range sites {
+		if expiry := EarliestSSLExpiry(s.Site); expiry != nil && !expiry.After(cutoff) {
+			expiring = append(expiring, s)
+		}
+	}
+	return expiring
+}
+
+// ParseDateFilter parses the date of a --since or --before filter, either
+// RFC3339 or a plain 2006-01-02 date, which means midnight local time
+func ParseDateFilter(value string) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+	t, err := time.ParseInLocation("2006-01-02", value, time.Local)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid date '%s': use 2006-01-02 or RFC3339 (2006-01-02T15:04:05Z07:00)", value)
+	}
+	return t, nil
+}
+
+// FilterSitesCreated keeps the sites created at or after since and before
+// before; a zero time leaves that side open. Sites with no recorded
+// creation date never match.
+func FilterSitesCreated(sites []ServerSite, since, before time.Time) []ServerSite {
+	created := []ServerSite{}
+	for _, s := range sites {
+		at := s.Site.CreatedAt
+		if at.IsZero() || at.Before(since) || (!before.IsZero() && !at.Before(before)) {
+			continue
+		}
+		created = append(created, s)
+	}
+	return created
+}
+
+// SortServerSites sorts sites in place by one of SiteSortKeys. The sort is
+// stable, so sites that compare equal keep their config order. Sites with no
+// recorded expiry sort last for ssl-expiry.
+func SortServerSites(sites []ServerSite, key string) error {
+	var less func(a, b ServerSite) bool
+	switch key {
+	case "domain":
+		less = func(a, b ServerSite) bool {
+			return strings.ToLower(a.Site.PrimaryDomain) < strings.ToLower(b.Site.PrimaryDomain)
+		}
+	case "server":
+		less = func(a, b ServerSite) bool { return a.Server < b.Server }
+	case "created":
+		less = func(a, b ServerSite) bool { return a.Site.CreatedAt.Before(b.Site.CreatedAt) }
+	case "ssl-expiry":
+		less = func(a, b ServerSite) bool {
+			ea, eb := EarliestSSLExpiry(a.Site), EarliestSSLExpiry(b.Site)
+			if ea == nil || eb == nil {
+				return ea != nil && eb == nil
+			}
+			return ea.Before(*eb)
+		}
+	default:
+		return fmt.Errorf("invalid sort key '%s': use one of %s", key, strings.Join(SiteSortKeys, ", "))
+	}
+
+	sort.SliceStable(sites, func(i, j int) bool { return less(sites[i], sites[j]) })
+	return nil
+}
@@ -0,0 +1,147 @@
+package utils
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/wordsail/cli/pkg/models"
+)
+
+func siteIDs(sites []ServerSite) []string {
+	ids := make([]string, len(sites))
+	for i, s := range sites {
+		ids[i] = s.Site.SiteID
+	}
+	return ids
+}
+
+func TestEarliestSSLExpiry(t *testing.T) {
+	now := time.Date(2026, 3, 10, 12, 0, 0, 0, time.UTC)
+	soon := now.AddDate(0, 0, 5)
+	later := now.AddDate(0, 2, 0)
+
+	site := models.Site{Domains: []models.Domain{
+		{Domain: "a.com", SSLEnabled: true, SSLExpiresAt: &later},
+		{Domain: "www.a.com", SSLEnabled: true, SSLExpiresAt: &soon},
+		{Domain: "old.a.com", SSLExpiresAt: &now},
+	}}
+	if got := EarliestSSLExpiry(site); got == nil || !got.Equal(soon) {
+		t.Errorf("EarliestSSLExpiry() = %v, want %v", got, soon)
+	}
+	if got := EarliestSSLExpiry(models.Site{}); got != nil {
+		t.Errorf("EarliestSSLExpiry() of a site without SSL = %v, want nil", got)
+	}
+}
+
+func TestFilterAndSortServerSites(t *testing.T) {
+	now := time.Date(2026, 3, 10, 12, 0, 0, 0, time.UTC)
+	expired := now.AddDate(0, 0, -2)
+	inWeek := now.AddDate(0, 0, 7)
+	inMonths := now.AddDate(0, 3, 0)
+
+	servers := []models.Server{
+		{
+			Name: "web2",
+			Sites: []models.Site{
+				{SiteID: "shop", PrimaryDomain: "shop.com", CreatedAt: now.AddDate(0, 0, -1),
+					Domains: []models.Domain{{Domain: "shop.com", SSLEnabled: true, SSLExpiresAt: &inMonths}}},
+				{SiteID: "blog", PrimaryDomain: "Blog.com", CreatedAt: now.AddDate(0, 0, -30)},
+			},
+		},
+		{
+			Name: "web1",
+			Sites: []models.Site{
+				{SiteID: "docs", PrimaryDomain: "docs.com", CreatedAt: now.AddDate(0, 0, -10),
+					Domains: []models.Domain{{Domain: "docs.com", SSLEnabled: true, SSLExpiresAt: &inWeek}}},
+				{SiteID: "api", PrimaryDomain: "api.com", CreatedAt: now.AddDate(0, 0, -5),
+					Domains: []models.Domain{{Domain: "api.com", SSLEnabled: true, SSLExpiresAt: &expired}}},
+			},
+		},
+	}
+
+	if got := siteIDs(ListServerSites(servers, "web1")); !reflect.DeepEqual(got, []string{"docs", "api"}) {
+		t.Errorf("ListServerSites(web1) = %v", got)
+	}
+
+	sortTests := []struct {
+		key  string
+		want []string
+	}{
+		{"domain", []string{"api", "blog", "docs", "shop"}},
+		{"server", []string{"docs", "api", "shop", "blog"}},
+		{"created", []string{"blog", "docs", "api", "shop"}},
+		{"ssl-expiry", []string{"api", "docs", "shop", "blog"}},
+	}
+	for _, tt := range sortTests {
+		t.Run("sort by "+tt.key, func(t *testing.T) {
+			sites := ListServerSites(servers, "")
+			if err := SortServerSites(sites, tt.key); err != nil {
+				t.Fatalf("SortServerSites() error = %v", err)
+			}
+			if got := siteIDs(sites); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("SortServerSites(%s) = %v, want %v", tt.key, got, tt.want)
+			}
+		})
+	}
+
+	if err := SortServerSites(ListServerSites(servers, ""), "size"); err == nil {
+		t.Error("SortServerSites() with an unknown key should fail")
+	}
+
+	expiring := FilterExpiringSites(ListServerSites(servers, ""), 7, now)
+	if got := siteIDs(expiring); !reflect.DeepEqual(got, []string{"docs", "api"}) {
+		t.Errorf("FilterExpiringSites(7) = %v, want [docs api]", got)
+	}
+	if got := FilterExpiringSites(ListServerSites(servers, ""), 0, now); len(got) != 1 {
+		t.Errorf("FilterExpiringSites(0) kept %d site(s), want only the expired one", len(got))
+	}
+
+	createdTests := []struct {
+		name          string
+		since, before time.Time
+		want          []string
+	}{
+		{"since", now.AddDate(0, 0, -7), time.Time{}, []string{"shop", "api"}},
+		{"before", time.Time{}, now.AddDate(0, 0, -5), []string{"blog", "docs"}},
+		{"between", now.AddDate(0, 0, -10), now.AddDate(0, 0, -5), []string{"docs"}},
+		{"open", time.Time{}, time.Time{}, []string{"shop", "blog", "docs", "api"}},
+	}
+	for _, tt := range createdTests {
+		t.Run("created "+tt.name, func(t *testing.T) {
+			got := siteIDs(FilterSitesCreated(ListServerSites(servers, ""), tt.since, tt.before))
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("FilterSitesCreated() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+	undated := []ServerSite{{Server: "web1", Site: models.Site{SiteID: "old"}}}
+	if got := FilterSitesCreated(undated, time.Time{}, now); len(got) != 0 {
+		t.Errorf("FilterSitesCreated() kept a site without a creation date")
+	}
+}
+
+func TestParseDateFilter(t *testing.T) {
+	tests := []struct {
+		value   string
+		want    time.Time
+		wantErr bool
+	}{
+		{value: "2026-03-10", want: time.Date(2026, 3, 10, 0, 0, 0, 0, time.Local)},
+		{value: "2026-03-10T08:30:00Z", want: time.Date(2026, 3, 10, 8, 30, 0, 0, time.UTC)},
+		{value: "2026-03-10T08:30:00+02:00", want: time.Date(2026, 3, 10, 6, 30, 0, 0, time.UTC)},
+		{value: "10/03/2026", wantErr: true},
+		{value: "last week", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			got, err := ParseDateFilter(tt.value)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseDateFilter() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && !got.Equal(tt.want) {
+				t.Errorf("ParseDateFilter() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,140 @@
+package utils
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/wordsail/cli/pkg/models"
+)
+
+// expiryDriftTolerance is how far the served certificate's expiry may be from
+// the one stored in the config before it is reported
+const expiryDriftTolerance = 24 * time.Hour
+
+// DomainVerification is the outcome of requesting one domain from outside
+// the server. Problems fail the check; Warnings do not.
+type DomainVerification struct {
+	Domain        string     `json:"domain"`
+	URL           string     `json:"url"`
+	StatusCode    int        `json:"status_code,omitempty"`
+	Location      string     `json:"location,omitempty"`
+	CertExpiresAt *time.Time `json:"cert_expires_at,omitempty"`
+	Problems      []string   `json:"problems,omitempty"`
+	Warnings      []string   `json:"warnings,omitempty"`
+}
+
+// Passed reports whether the domain answered and its certificate is valid
+func (v DomainVerification) Passed() bool {
+	return len(v.Problems) == 0
+}
+
+// NewVerifyClient returns the HTTP client used by VerifyDomain. It does not
+// follow redirects, so each domain's own answer is checked, and leaves
+// certificate verification to VerifyDomain so it can say what is wrong.
+func NewVerifyClient(timeout time.Duration) *http.Client {
+	return &http.Client{
+		Timeout: timeout,
+		Transport: &http.Transport{
+			Proxy:           http.ProxyFromEnvironment,
+			TLSClientConfig: &tls.Config{InsecureSkipVerify: true},
+		},
+		CheckRedirect: func(*http.Request, []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+}
+
+// VerifyDomain requests a domain over HTTPS (HTTP if SSL is not enabled for
+// it) and checks the status code and the served certificate: that it is for
+// the domain, not expired, chains to a trusted root (roots, or the system
+// roots if nil), and matches the expiry stored in the config. A 503 is
+// expected while the site is in maintenance mode.
+func VerifyDomain(ctx context.Context, client *http.Client, domain models.Domain, maintenance bool, roots *x509.CertPool) DomainVerification {
+	scheme := "https"
+	if !domain.SSLEnabled {
+		scheme = "http"
+	}
+	v := DomainVerification{Domain: domain.Domain, URL: fmt.Sprintf("%s://%s/", scheme, domain.Domain)}
+	if !domain.SSLEnabled {
+		v.Warnings = append(v.Warnings, "SSL is not enabled; checked over HTTP")
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, v.URL, nil)
+	if err != nil {
+		v.Problems = append(v.Problems, err.Error())
+		return v
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		v.Problems = append(v.Problems, fmt.Sprintf("request failed: %v", unwrapURLError(err)))
+		return v
+	}
+	resp.Body.Close()
+
+	v.StatusCode = resp.StatusCode
+	v.Location = resp.Header.Get("Location")
+	switch {
+	case resp.StatusCode == http.StatusServiceUnavailable && maintenance:
+		v.Warnings = append(v.Warnings, "site is in maintenance mode")
+	case resp.StatusCode >= 400:
+		v.Problems = append(v.Problems, fmt.Sprintf("HTTP %d %s", resp.StatusCode, http.StatusText(resp.StatusCode)))
+	}
+
+	if resp.TLS != nil {
+		checkCertificate(&v, domain, resp.TLS.PeerCertificates, roots, time.Now())
+	}
+	return v
+}
+
+// checkCertificate adds the problems and warnings of a served certificate
+// chain to v
+func checkCertificate(v *DomainVerification, domain models.Domain, chain []*x509.Certificate, roots *x509.CertPool, now time.Time) {
+	if len(chain) == 0 {
+		v.Problems = append(v.Problems, "no certificate served")
+		return
+	}
+	leaf := chain[0]
+	expires := leaf.NotAfter
+	v.CertExpiresAt = &expires
+
+	if err := leaf.VerifyHostname(domain.Domain); err != nil {
+		v.Problems = append(v.Problems, fmt.Sprintf("certificate does not match: %v", err))
+	}
+	expired := now.After(leaf.NotAfter)
+	if expired {
+		v.Problems = append(v.Problems, fmt.Sprintf("certificate expired on %s", leaf.NotAfter.Format("2006-01-02")))
+	}
+
+	intermediates := x509.NewCertPool()
+	for _, cert := range chain[1:] {
+		intermediates.AddCert(cert)
+	}
+	_, err := leaf.Verify(x509.VerifyOptions{Roots: roots, Intermediates: intermediates, CurrentTime: now})
+	var invalid x509.CertificateInvalidError
+	if err != nil && !(expired && errors.As(err, &invalid) && invalid.Reason == x509.Expired) {
+		v.Problems = append(v.Problems, fmt.Sprintf("certificate chain is not trusted: %v", err))
+	}
+
+	if domain.SSLExpiresAt != nil {
+		drift := leaf.NotAfter.Sub(*domain.SSLExpiresAt)
+		if drift > expiryDriftTolerance || drift < -expiryDriftTolerance {
+			v.Warnings = append(v.Warnings, fmt.Sprintf("config records expiry %s but the served certificate expires %s",
+				domain.SSLExpiresAt.Format("2006-01-02"), leaf.NotAfter.Format("2006-01-02")))
+		}
+	}
+}
+
+// unwrapURLError drops the "Get <url>:" prefix net/http adds to errors
+func unwrapURLError(err error) error {
+	var urlErr *url.Error
+	if errors.As(err, &urlErr) {
+		return urlErr.Err
+	}
+	return err
+}
@@ -0,0 +1,101 @@
+package utils
+
+import (
+	"context"
+	"crypto/x509"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/wordsail/cli/pkg/models"
+)
+
+func TestVerifyDomain(t *testing.T) {
+	status := http.StatusOK
+	ts := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(status)
+	}))
+	defer ts.Close()
+
+	// Send every request to the test server, whatever the domain
+	client := NewVerifyClient(5 * time.Second)
+	client.Transport.(*http.Transport).DialContext = func(ctx context.Context, network, _ string) (net.Conn, error) {
+		return (&net.Dialer{}).DialContext(ctx, network, ts.Listener.Addr().String())
+	}
+	roots := x509.NewCertPool()
+	roots.AddCert(ts.Certificate())
+	served := ts.Certificate().NotAfter
+	stale := served.AddDate(0, -3, 0)
+
+	tests := []struct {
+		name        string
+		domain      models.Domain
+		status      int
+		maintenance bool
+		roots       *x509.CertPool
+		wantPassed  bool
+		wantProblem string
+		wantWarning string
+	}{
+		{name: "valid", domain: models.Domain{Domain: "example.com", SSLEnabled: true, SSLExpiresAt: &served},
+			status: http.StatusOK, roots: roots, wantPassed: true},
+		{name: "redirect", domain: models.Domain{Domain: "example.com", SSLEnabled: true},
+			status: http.StatusMovedPermanently, roots: roots, wantPassed: true},
+		{name: "server error", domain: models.Domain{Domain: "example.com", SSLEnabled: true},
+			status: http.StatusBadGateway, roots: roots, wantProblem: "HTTP 502"},
+		{name: "maintenance", domain: models.Domain{Domain: "example.com", SSLEnabled: true},
+			status: http.StatusServiceUnavailable, maintenance: true, roots: roots, wantPassed: true, wantWarning: "maintenance"},
+		{name: "untrusted chain", domain: models.Domain{Domain: "example.com", SSLEnabled: true},
+			status: http.StatusOK, wantProblem: "not trusted"},
+		{name: "other domain", domain: models.Domain{Domain: "shop.test", SSLEnabled: true},
+			status: http.StatusOK, roots: roots, wantProblem: "does not match"},
+		{name: "stale stored expiry", domain: models.Domain{Domain: "example.com", SSLEnabled: true, SSLExpiresAt: &stale},
+			status: http.StatusOK, roots: roots, wantPassed: true, wantWarning: "config records expiry"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			status = tt.status
+			got := VerifyDomain(context.Background(), client, tt.domain, tt.maintenance, tt.roots)
+			if got.Passed() != tt.wantPassed {
+				t.Errorf("Passed() = %v, want %v (problems %v)", got.Passed(), tt.wantPassed, got.Problems)
+			}
+			if got.StatusCode != tt.status {
+				t.Errorf("StatusCode = %d, want %d", got.StatusCode, tt.status)
+			}
+			if tt.wantProblem != "" && !containsText(got.Problems, tt.wantProblem) {
+				t.Errorf("Problems = %v, want one containing %q", got.Problems, tt.wantProblem)
+			}
+			if tt.wantWarning != "" && !containsText(got.Warnings, tt.wantWarning) {
+				t.Errorf("Warnings = %v, want one containing %q", got.Warnings, tt.wantWarning)
+			}
+		})
+	}
+
+	t.Run("expired", func(t *testing.T) {
+		var v DomainVerification
+		checkCertificate(&v, models.Domain{Domain: "example.com"}, []*x509.Certificate{ts.Certificate()}, roots, served.AddDate(0, 0, 1))
+		if len(v.Problems) != 1 || !strings.Contains(v.Problems[0], "expired") {
+			t.Errorf("Problems = %v, want only the expiry", v.Problems)
+		}
+	})
+
+	t.Run("unreachable", func(t *testing.T) {
+		ts.Close()
+		got := VerifyDomain(context.Background(), client, models.Domain{Domain: "example.com", SSLEnabled: true}, false, roots)
+		if got.Passed() || !containsText(got.Problems, "request failed") {
+			t.Errorf("Problems = %v, want a failed request", got.Problems)
+		}
+	})
+}
+
+func containsText(lines []string, text string) bool {
+	for _, line := range lines {
+		if strings.Contains(line, text) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,171 @@
+package utils
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/wordsail/cli/pkg/models"
+)
+
+// Smoke test vhost: a throwaway Nginx server on a loopback port with a
+// static file and a PHP script, served through the default PHP-FPM pool
+const (
+	smokeTestRoot   = "/var/www/wordsail-smoke"
+	smokeTestVhost  = "/etc/nginx/sites-enabled/wordsail-smoke"
+	smokeTestListen = "127.0.0.1:8089"
+
+	smokeStaticMarker = "wordsail-smoke-static"
+	smokePHPMarker    = "wordsail-smoke-php-42"
+)
+
+// Smoke test check names, in the order they run
+const (
+	SmokeCheckSetup    = "test vhost setup"
+	SmokeCheckNginx    = "nginx serves static file"
+	SmokeCheckPHP      = "php executes script"
+	SmokeCheckDatabase = "database accepts connection"
+)
+
+// SmokeCheck is the outcome of one smoke test check
+type SmokeCheck struct {
+	Name   string `json:"name"`
+	Passed bool   `json:"passed"`
+	Detail string `json:"detail,omitempty"`
+}
+
+// SmokeTestResult aggregates the checks of a post-provision smoke test
+type SmokeTestResult struct {
+	Checks       []SmokeCheck `json:"checks"`
+	CleanupError string       `json:"cleanup_error,omitempty"`
+}
+
+// Passed reports whether every check passed and the test vhost was removed
+func (r SmokeTestResult) Passed() bool {
+	return len(r.FailedChecks()) == 0 && r.CleanupError == ""
+}
+
+// FailedChecks returns the names of the checks that did not pass
+func (r SmokeTestResult) FailedChecks() []string {
+	var failed []string
+	for _, check := range r.Checks {
+		if !check.Passed {
+			failed = append(failed, check.Name)
+		}
+	}
+	return failed
+}
+
+// Err summarizes a failed smoke test as an error, or returns nil
+func (r SmokeTestResult) Err() error {
+	if r.Passed() {
+		return nil
+	}
+	if failed := r.FailedChecks(); len(failed) > 0 {
+		return fmt.Errorf("smoke test failed: %s", strings.Join(failed, ", "))
+	}
+	return fmt.Errorf("smoke test cleanup failed: %s", r.CleanupError)
+}
+
+// RunSmokeTest verifies that Nginx, PHP and the database work end-to-end on a
+// freshly provisioned server. The test vhost is always removed afterwards.
+func RunSmokeTest(server models.Server) SmokeTestResult {
+	return runSmokeTest(func(command string) (string, error) {
+		return RunSSHCommand(server, command)
+	})
+}
+
+// runSmokeTest runs the checks with the given remote command runner
+func runSmokeTest(run func(command string) (string, error)) SmokeTestResult {
+	var result SmokeTestResult
+
+	_, setupErr := run(sudoShell(smokeSetupScript()))
+	result.Checks = append(result.Checks, smokeCheck(SmokeCheckSetup, "", setupErr))
+
+	if setupErr != nil {
+		result.Checks = append(result.Checks,
+			SmokeCheck{Name: SmokeCheckNginx, Detail: "skipped: setup failed"},
+			SmokeCheck{Name: SmokeCheckPHP, Detail: "skipped: setup failed"})
+	} else {
+		output, err := run(fmt.Sprintf("curl -fsS --max-time 10 http://%s/index.html", smokeTestListen))
+		result.Checks = append(result.Checks, expectOutput(SmokeCheckNginx, output, err, smokeStaticMarker))
+
+		output, err = run(fmt.Sprintf("curl -fsS --max-time 10 http://%s/test.php", smokeTestListen))
+		result.Checks = append(result.Checks, expectOutput(SmokeCheckPHP, output, err, smokePHPMarker))
+	}
+
+	output, err := run("sudo -n mysql -NBe 'SELECT 1'")
+	result.Checks = append(result.Checks, expectOutput(SmokeCheckDatabase, output, err, "1"))
+
+	// Tear down even when setup only got part of the way
+	if output, err := run(sudoShell(smokeCleanupScript())); err != nil {
+		result.CleanupError = firstLine(output, err)
+	}
+
+	return result
+}
+
+// smokeSetupScript writes the test files and vhost and reloads Nginx
+func smokeSetupScript() string {
+	vhost := fmt.Sprintf(`server {
+	listen %s;
+	server_name _;
+	root %s;
+	location ~ \.php$ {
+		include fastcgi.conf;
+		fastcgi_pass unix:$sock;
+	}
+}`, smokeTestListen, smokeTestRoot)
+
+	return strings.Join([]string{
+		"set -e",
+		"sock=$(ls /run/php/php*-fpm.sock | head -n 1)",
+		`[ -n "$sock" ] || { echo "no PHP-FPM socket found"; exit 1; }`,
+		"mkdir -p " + smokeTestRoot,
+		fmt.Sprintf("echo %s > %s/index.html", smokeStaticMarker, smokeTestRoot),
+		fmt.Sprintf(`echo "<?php echo 'wordsail-smoke-php-' . (6 * 7);" > %s/test.php`, smokeTestRoot),
+		fmt.Sprintf("cat > %s <<EOF\n%s\nEOF", smokeTestVhost, vhost),
+		"nginx -t",
+		"systemctl reload nginx",
+	}, "\n")
+}
+
+// smokeCleanupScript removes the test vhost and files and reloads Nginx
+func smokeCleanupScript() string {
+	return strings.Join([]string{
+		"rm -f " + smokeTestVhost,
+		"rm -rf " + smokeTestRoot,
+		"nginx -t && systemctl reload nginx",
+	}, "\n")
+}
+
+// sudoShell runs a script through sh as root
+func sudoShell(script string) string {
+	return "sudo -n sh -c " + ShellQuote(script)
+}
+
+// expectOutput builds a check that passes when the command succeeded and its
+// output contains want
+func expectOutput(name, output string, err error, want string) SmokeCheck {
+	if err == nil && !strings.Contains(output, want) {
+		err = fmt.Errorf("unexpected response: %s", strings.TrimSpace(output))
+	}
+	return smokeCheck(name, output, err)
+}
+
+// smokeCheck builds a check from a command error
+func smokeCheck(name, output string, err error) SmokeCheck {
+	if err != nil {
+		return SmokeCheck{Name: name, Detail: firstLine(output, err)}
+	}
+	return SmokeCheck{Name: name, Passed: true}
+}
+
+// firstLine returns the first line of a failed command's output, falling
+// back to the error itself
+func firstLine(output string, err error) string {
+	line, _, _ := strings.Cut(strings.TrimSpace(output), "\n")
+	if line == "" {
+		return err.Error()
+	}
+	return line
+}
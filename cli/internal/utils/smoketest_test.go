@@ -0,0 +1,97 @@
+package utils
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// fakeSmokeRunner answers smoke test commands; fail lists the checks whose
+// command should fail
+func fakeSmokeRunner(fail map[string]bool, calls *[]string) func(string) (string, error) {
+	return func(command string) (string, error) {
+		*calls = append(*calls, command)
+		switch {
+		case strings.Contains(command, "nginx -t\n"):
+			if fail[SmokeCheckSetup] {
+				return "nginx: configuration file test failed\n", errors.New("exit status 1")
+			}
+			return "", nil
+		case strings.Contains(command, "index.html") && strings.HasPrefix(command, "curl"):
+			if fail[SmokeCheckNginx] {
+				return "curl: (7) Failed to connect\n", errors.New("exit status 7")
+			}
+			return smokeStaticMarker + "\n", nil
+		case strings.Contains(command, "test.php"):
+			if fail[SmokeCheckPHP] {
+				// PHP not executed: the raw source is returned
+				return "<?php echo 'wordsail-smoke-php-' . (6 * 7);\n", nil
+			}
+			return smokePHPMarker, nil
+		case strings.Contains(command, "mysql"):
+			if fail[SmokeCheckDatabase] {
+				return "ERROR 2002 (HY000): Can't connect\n", errors.New("exit status 1")
+			}
+			return "1\n", nil
+		case strings.Contains(command, "rm -rf"):
+			if fail["cleanup"] {
+				return "", errors.New("exit status 1")
+			}
+			return "", nil
+		}
+		return "", errors.New("unexpected command")
+	}
+}
+
+func TestRunSmokeTestAggregation(t *testing.T) {
+	tests := []struct {
+		name       string
+		fail       map[string]bool
+		wantFailed []string
+		wantPassed bool
+	}{
+		{"all pass", nil, nil, true},
+		{"php not executed", map[string]bool{SmokeCheckPHP: true}, []string{SmokeCheckPHP}, false},
+		{"database down", map[string]bool{SmokeCheckDatabase: true}, []string{SmokeCheckDatabase}, false},
+		{
+			"setup fails",
+			map[string]bool{SmokeCheckSetup: true},
+			[]string{SmokeCheckSetup, SmokeCheckNginx, SmokeCheckPHP},
+			false,
+		},
+		{"cleanup fails", map[string]bool{"cleanup": true}, nil, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var calls []string
+			result := runSmokeTest(fakeSmokeRunner(tt.fail, &calls))
+
+			if len(result.Checks) != 4 {
+				t.Fatalf("got %d checks, want 4", len(result.Checks))
+			}
+			if got := result.FailedChecks(); !reflect.DeepEqual(got, tt.wantFailed) {
+				t.Errorf("FailedChecks() = %v, want %v", got, tt.wantFailed)
+			}
+			if result.Passed() != tt.wantPassed {
+				t.Errorf("Passed() = %v, want %v", result.Passed(), tt.wantPassed)
+			}
+			if (result.Err() == nil) != tt.wantPassed {
+				t.Errorf("Err() = %v", result.Err())
+			}
+		})
+	}
+}
+
+func TestRunSmokeTestAlwaysCleansUp(t *testing.T) {
+	for _, failing := range []string{"", SmokeCheckSetup, SmokeCheckNginx, SmokeCheckDatabase} {
+		var calls []string
+		runSmokeTest(fakeSmokeRunner(map[string]bool{failing: true}, &calls))
+
+		last := calls[len(calls)-1]
+		if !strings.Contains(last, "rm -f "+smokeTestVhost) || !strings.Contains(last, "rm -rf "+smokeTestRoot) {
+			t.Errorf("failing %q: last command = %q, want cleanup", failing, last)
+		}
+	}
+}
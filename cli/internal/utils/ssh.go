@@ -1,80 +1,326 @@
 package utils
 
 import (
+	"bytes"
+	"context"
+	"crypto/x509"
+	"errors"
 	"fmt"
+	"io"
 	"net"
 	"os"
 	"path/filepath"
 	"strings"
+	"sync"
 	"time"
 
 	"golang.org/x/crypto/ssh"
+	"golang.org/x/crypto/ssh/agent"
 	"golang.org/x/crypto/ssh/knownhosts"
+	"github.com/wordsail/cli/internal/exit"
 	"github.com/wordsail/cli/pkg/models"
 )
 
-// TestSSHConnection tests SSH connectivity to a server
-func TestSSHConnection(server models.Server) error {
-	// Expand home directory in key file path
-	keyFile := server.SSH.KeyFile
-	if strings.HasPrefix(keyFile, "~") {
-		homeDir, err := os.UserHomeDir()
+// PassphrasePrompt is called to unlock passphrase-protected key files. It is
+// nil in non-interactive runs, in which case encrypted keys are rejected with
+// a hint to use ssh-agent instead.
+var PassphrasePrompt func(keyFile string) ([]byte, error)
+
+// signerCache keeps unlocked keys so retries don't ask for the passphrase again
+var (
+	signerCache   = make(map[string]ssh.Signer)
+	signerCacheMu sync.Mutex
+)
+
+// DefaultSSHTimeout is how long SSH checks wait to connect and for their
+// command to finish, unless --ssh-timeout says otherwise
+const DefaultSSHTimeout = 10 * time.Second
+
+// TestSSHConnection tests SSH connectivity to a server. The timeout applies
+// to connecting and to running the test command.
+func TestSSHConnection(server models.Server, timeout time.Duration) error {
+	// Test command execution
+	output, err := RunSSHCommandWithTimeout(server, "echo 'wordsail-test'", timeout)
+	if err != nil {
+		return err
+	}
+
+	if strings.TrimSpace(output) != "wordsail-test" {
+		return exit.Errorf(exit.SSH, "unexpected test output: %s", output)
+	}
+
+	return nil
+}
+
+// TestSSHSudo checks that the server's SSH user can run commands with
+// passwordless sudo, as Ansible's become requires
+func TestSSHSudo(server models.Server, timeout time.Duration) error {
+	output, err := RunSSHCommandWithTimeout(server, "sudo -n true && echo 'wordsail-sudo'", timeout)
+	if err != nil {
+		return exit.New(exit.SSH, fmt.Errorf("passwordless sudo failed for %s: %w", server.SSH.User, err))
+	}
+	if strings.TrimSpace(output) != "wordsail-sudo" {
+		return exit.Errorf(exit.SSH, "unexpected sudo test output: %s", output)
+	}
+	return nil
+}
+
+// RunSSHCommand runs a single command on the server and returns its combined
+// output. Connecting is limited to DefaultSSHTimeout; the command itself may
+// run as long as it needs. Failures to connect or log in carry the SSH exit
+// code; a command that runs but fails does not.
+func RunSSHCommand(server models.Server, command string) (string, error) {
+	return runSSHCommand(server, command, DefaultSSHTimeout, 0)
+}
+
+// RunSSHCommandWithTimeout is RunSSHCommand with timeout applied both to
+// connecting and to running the command. A command that does not finish in
+// time is abandoned and reported as an SSH failure.
+func RunSSHCommandWithTimeout(server models.Server, command string, timeout time.Duration) (string, error) {
+	return runSSHCommand(server, command, timeout, timeout)
+}
+
+// runSSHCommand connects within dialTimeout and, if commandTimeout is
+// positive, gives up on the command after that long
+func runSSHCommand(server models.Server, command string, dialTimeout, commandTimeout time.Duration) (string, error) {
+	client, cleanup, err := connectSSH(server, dialTimeout)
+	if err != nil {
+		return "", err
+	}
+	defer cleanup()
+	addr := server.SSHAddress()
+
+	// Create session
+	session, err := client.NewSession()
+	if err != nil {
+		return "", exit.New(exit.SSH, fmt.Errorf("failed to create SSH session: %w", err))
+	}
+	defer session.Close()
+
+	if commandTimeout <= 0 {
+		output, err := session.CombinedOutput(command)
 		if err != nil {
-			return fmt.Errorf("failed to expand home directory: %w", err)
+			return string(output), fmt.Errorf("command failed: %w", err)
 		}
-		keyFile = filepath.Join(homeDir, keyFile[1:])
+		return string(output), nil
 	}
 
-	// Read SSH private key
-	key, err := os.ReadFile(keyFile)
+	type result struct {
+		output []byte
+		err    error
+	}
+	done := make(chan result, 1)
+	go func() {
+		output, err := session.CombinedOutput(command)
+		done <- result{output, err}
+	}()
+
+	timer := time.NewTimer(commandTimeout)
+	defer timer.Stop()
+	select {
+	case r := <-done:
+		if r.err != nil {
+			return string(r.output), fmt.Errorf("command failed: %w", r.err)
+		}
+		return string(r.output), nil
+	case <-timer.C:
+		// Closing the connection unblocks the session goroutine
+		client.Close()
+		return "", exit.Errorf(exit.SSH, "command on %s timed out after %s", addr, commandTimeout)
+	}
+}
+
+// StreamSSHCommand runs a command on the server with stdin and stdout
+// connected to the given reader and writer, for transfers too large to
+// buffer. Connecting is limited to DefaultSSHTimeout; the command may run as
+// long as it needs. Remote stderr is returned in the error if it fails.
+func StreamSSHCommand(server models.Server, command string, stdin io.Reader, stdout io.Writer) error {
+	return StreamSSHCommandContext(context.Background(), server, command, stdin, stdout)
+}
+
+// StreamSSHCommandContext is StreamSSHCommand for commands that run until
+// they are stopped, like tail -F. When ctx is done the remote command is
+// sent SIGTERM, the connection is closed and ctx.Err() is returned.
+func StreamSSHCommandContext(ctx context.Context, server models.Server, command string, stdin io.Reader, stdout io.Writer) error {
+	client, cleanup, err := connectSSH(server, DefaultSSHTimeout)
 	if err != nil {
-		return fmt.Errorf("failed to read SSH key file %s: %w", keyFile, err)
+		return err
 	}
+	defer cleanup()
 
-	// Parse private key
-	signer, err := ssh.ParsePrivateKey(key)
+	session, err := client.NewSession()
+	if err != nil {
+		return exit.New(exit.SSH, fmt.Errorf("failed to create SSH session: %w", err))
+	}
+	defer session.Close()
+
+	var stderr bytes.Buffer
+	session.Stdin = stdin
+	session.Stdout = stdout
+	session.Stderr = &stderr
+	if err := session.Start(command); err != nil {
+		return fmt.Errorf("command failed: %w", err)
+	}
+
+	done := make(chan error, 1)
+	go func() { done <- session.Wait() }()
+
+	select {
+	case err := <-done:
+		if err != nil {
+			if msg := strings.TrimSpace(stderr.String()); msg != "" {
+				return fmt.Errorf("command failed: %s", msg)
+			}
+			return fmt.Errorf("command failed: %w", err)
+		}
+		return nil
+	case <-ctx.Done():
+		// Not every server honours signals; closing the connection
+		// (deferred) ends the session either way
+		session.Signal(ssh.SIGTERM)
+		return ctx.Err()
+	}
+}
+
+// connectSSH opens a client connection to the server within dialTimeout.
+// The returned cleanup func closes the connection and the agent socket.
+func connectSSH(server models.Server, dialTimeout time.Duration) (*ssh.Client, func(), error) {
+	authMethods, cleanupAuth, err := sshAuthMethods(server.SSH)
 	if err != nil {
-		return fmt.Errorf("failed to parse SSH private key: %w", err)
+		return nil, nil, exit.New(exit.SSH, err)
 	}
 
 	// Configure SSH client with TOFU host key verification
 	// This validates against known_hosts if the file exists and the host is known,
 	// or automatically accepts and saves unknown host keys
 	config := &ssh.ClientConfig{
-		User: server.SSH.User,
-		Auth: []ssh.AuthMethod{
-			ssh.PublicKeys(signer),
-		},
+		User:            server.SSH.User,
+		Auth:            authMethods,
 		HostKeyCallback: trustOnFirstUseCallback(),
-		Timeout:         10 * time.Second,
+		Timeout:         dialTimeout,
 	}
 
-	// Connect to server
-	addr := fmt.Sprintf("%s:%d", server.IP, server.SSH.Port)
-	client, err := ssh.Dial("tcp", addr, config)
+	// Connect to server (through the bastion if one is configured)
+	client, err := dialSSH(server.SSH, server.SSHAddress(), config)
 	if err != nil {
-		return fmt.Errorf("SSH connection failed to %s: %w", addr, err)
+		cleanupAuth()
+		return nil, nil, exit.New(exit.SSH, err)
 	}
-	defer client.Close()
+	return client, func() {
+		client.Close()
+		cleanupAuth()
+	}, nil
+}
 
-	// Create session
-	session, err := client.NewSession()
+// sshAuthMethods returns the auth methods for a server: the ssh-agent when
+// use_agent is set or no key file is configured, otherwise the key file.
+// The returned cleanup func closes the agent connection.
+func sshAuthMethods(sshCfg models.SSHConfig) ([]ssh.AuthMethod, func(), error) {
+	if sshCfg.UseAgent || sshCfg.KeyFile == "" {
+		socket := os.Getenv("SSH_AUTH_SOCK")
+		if socket == "" {
+			return nil, nil, fmt.Errorf("ssh-agent authentication requested but SSH_AUTH_SOCK is not set")
+		}
+		conn, err := net.Dial("unix", socket)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to connect to ssh-agent: %w", err)
+		}
+		agentClient := agent.NewClient(conn)
+		return []ssh.AuthMethod{ssh.PublicKeysCallback(agentClient.Signers)}, func() { conn.Close() }, nil
+	}
+
+	signer, err := loadSigner(sshCfg.KeyFile)
 	if err != nil {
-		return fmt.Errorf("failed to create SSH session: %w", err)
+		return nil, nil, err
 	}
-	defer session.Close()
+	return []ssh.AuthMethod{ssh.PublicKeys(signer)}, func() {}, nil
+}
 
-	// Test command execution
-	output, err := session.CombinedOutput("echo 'wordsail-test'")
+// loadSigner reads and parses a private key file, asking for the passphrase
+// through PassphrasePrompt when the key is encrypted
+func loadSigner(keyFile string) (ssh.Signer, error) {
+	keyFile, err := ExpandPath(keyFile)
 	if err != nil {
-		return fmt.Errorf("test command failed: %w", err)
+		return nil, err
 	}
 
-	if strings.TrimSpace(string(output)) != "wordsail-test" {
-		return fmt.Errorf("unexpected test output: %s", output)
+	signerCacheMu.Lock()
+	defer signerCacheMu.Unlock()
+	if signer, ok := signerCache[keyFile]; ok {
+		return signer, nil
 	}
 
-	return nil
+	// Read SSH private key
+	key, err := os.ReadFile(keyFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read SSH key file %s: %w", keyFile, err)
+	}
+
+	// Parse private key
+	signer, err := ssh.ParsePrivateKey(key)
+	var missingErr *ssh.PassphraseMissingError
+	if errors.As(err, &missingErr) {
+		if PassphrasePrompt == nil {
+			return nil, fmt.Errorf("SSH key %s is passphrase-protected: add it to ssh-agent (ssh-add %s) and use --use-agent", keyFile, keyFile)
+		}
+		passphrase, promptErr := PassphrasePrompt(keyFile)
+		if promptErr != nil {
+			return nil, promptErr
+		}
+		signer, err = ssh.ParsePrivateKeyWithPassphrase(key, passphrase)
+		if errors.Is(err, x509.IncorrectPasswordError) {
+			return nil, fmt.Errorf("incorrect passphrase for SSH key %s", keyFile)
+		}
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse SSH private key: %w", err)
+	}
+
+	signerCache[keyFile] = signer
+	return signer, nil
+}
+
+// dialSSH connects to addr directly, or via the configured jump host by
+// opening a tunnelled TCP connection from the bastion to the target
+func dialSSH(sshCfg models.SSHConfig, addr string, config *ssh.ClientConfig) (*ssh.Client, error) {
+	if !sshCfg.HasJumpHost() {
+		client, err := ssh.Dial("tcp", addr, config)
+		if err != nil {
+			return nil, fmt.Errorf("SSH connection failed to %s: %w", addr, err)
+		}
+		return client, nil
+	}
+
+	jumpConfig := *config
+	jumpConfig.User = sshCfg.JumpLogin()
+	jumpAddr := sshCfg.JumpAddress()
+
+	bastion, err := ssh.Dial("tcp", jumpAddr, &jumpConfig)
+	if err != nil {
+		return nil, fmt.Errorf("SSH connection failed to jump host %s: %w", jumpAddr, err)
+	}
+
+	conn, err := bastion.Dial("tcp", addr)
+	if err != nil {
+		bastion.Close()
+		return nil, fmt.Errorf("jump host %s could not reach %s: %w", jumpAddr, addr, err)
+	}
+
+	clientConn, chans, reqs, err := ssh.NewClientConn(conn, addr, config)
+	if err != nil {
+		conn.Close()
+		bastion.Close()
+		return nil, fmt.Errorf("SSH connection failed to %s via %s: %w", addr, jumpAddr, err)
+	}
+
+	client := ssh.NewClient(clientConn, chans, reqs)
+	// Tear down the bastion connection together with the tunnelled client
+	go func() {
+		client.Wait()
+		bastion.Close()
+	}()
+
+	return client, nil
 }
 
 // getHostKeyCallback returns a host key callback using the user's known_hosts file
@@ -0,0 +1,311 @@
+package utils
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/pem"
+	"errors"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/wordsail/cli/internal/exit"
+	"github.com/wordsail/cli/pkg/models"
+	"golang.org/x/crypto/ssh"
+)
+
+func writeEncryptedKey(t *testing.T, passphrase string) string {
+	t.Helper()
+	_, priv, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	block, err := ssh.MarshalPrivateKeyWithPassphrase(priv, "", []byte(passphrase))
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(t.TempDir(), "id_ed25519")
+	if err := os.WriteFile(path, pem.EncodeToMemory(block), 0600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestLoadSignerPassphrase(t *testing.T) {
+	tests := []struct {
+		name    string
+		prompt  func(string) ([]byte, error)
+		wantErr string
+	}{
+		{"no prompt available", nil, "ssh-add"},
+		{"correct passphrase", func(string) ([]byte, error) { return []byte("s3cret"), nil }, ""},
+		{"wrong passphrase", func(string) ([]byte, error) { return []byte("nope"), nil }, "incorrect passphrase"},
+		{"prompt cancelled", func(string) ([]byte, error) { return nil, errors.New("interrupt") }, "interrupt"},
+	}
+
+	original := PassphrasePrompt
+	defer func() { PassphrasePrompt = original }()
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			keyFile := writeEncryptedKey(t, "s3cret")
+			PassphrasePrompt = tt.prompt
+
+			signer, err := loadSigner(keyFile)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatalf("loadSigner() error = %v", err)
+				}
+				if signer == nil {
+					t.Fatal("loadSigner() returned nil signer")
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("loadSigner() error = %v, want it to contain %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestLoadSignerCachesUnlockedKey(t *testing.T) {
+	original := PassphrasePrompt
+	defer func() { PassphrasePrompt = original }()
+
+	keyFile := writeEncryptedKey(t, "s3cret")
+	prompts := 0
+	PassphrasePrompt = func(string) ([]byte, error) {
+		prompts++
+		return []byte("s3cret"), nil
+	}
+
+	for i := 0; i < 3; i++ {
+		if _, err := loadSigner(keyFile); err != nil {
+			t.Fatalf("loadSigner() error = %v", err)
+		}
+	}
+	if prompts != 1 {
+		t.Errorf("passphrase prompted %d times, want 1", prompts)
+	}
+}
+
+func TestSSHAuthMethodsAgentWithoutSocket(t *testing.T) {
+	t.Setenv("SSH_AUTH_SOCK", "")
+
+	_, _, err := sshAuthMethods(models.SSHConfig{User: "root", Port: 22, UseAgent: true})
+	if err == nil || !strings.Contains(err.Error(), "SSH_AUTH_SOCK") {
+		t.Errorf("sshAuthMethods() error = %v, want SSH_AUTH_SOCK error", err)
+	}
+}
+
+// serveOneExec accepts a single SSH connection on listener and answers one
+// exec request with reply
+func serveOneExec(t *testing.T, listener net.Listener, clientKey ssh.PublicKey, reply string) {
+	t.Helper()
+	serveOneSlowExec(t, listener, clientKey, reply, 0)
+}
+
+// serveOneSlowExec is serveOneExec that waits delay before replying
+func serveOneSlowExec(t *testing.T, listener net.Listener, clientKey ssh.PublicKey, reply string, delay time.Duration) {
+	t.Helper()
+	serveExec(t, listener, clientKey, func(_ string, channel ssh.Channel) uint32 {
+		time.Sleep(delay)
+		channel.Write([]byte(reply))
+		return 0
+	})
+}
+
+// serveExec accepts SSH connections on listener until it is closed and
+// answers every exec request with handle, which gets the command and the
+// session channel (stdin, stdout and Stderr()) and returns the exit status
+func serveExec(t *testing.T, listener net.Listener, clientKey ssh.PublicKey, handle func(command string, channel ssh.Channel) uint32) {
+	t.Helper()
+	_, hostPriv, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	hostSigner, err := ssh.NewSignerFromKey(hostPriv)
+	if err != nil {
+		t.Fatal(err)
+	}
+	config := &ssh.ServerConfig{
+		PublicKeyCallback: func(_ ssh.ConnMetadata, key ssh.PublicKey) (*ssh.Permissions, error) {
+			if bytes.Equal(key.Marshal(), clientKey.Marshal()) {
+				return nil, nil
+			}
+			return nil, errors.New("unknown key")
+		},
+	}
+	config.AddHostKey(hostSigner)
+
+	serve := func(conn net.Conn) {
+		defer conn.Close()
+		_, chans, reqs, err := ssh.NewServerConn(conn, config)
+		if err != nil {
+			return
+		}
+		go ssh.DiscardRequests(reqs)
+		for newChan := range chans {
+			channel, requests, err := newChan.Accept()
+			if err != nil {
+				return
+			}
+			for req := range requests {
+				if req.Type != "exec" {
+					req.Reply(false, nil)
+					continue
+				}
+				var exec struct{ Command string }
+				ssh.Unmarshal(req.Payload, &exec)
+				req.Reply(true, nil)
+				status := handle(exec.Command, channel)
+				channel.SendRequest("exit-status", false, ssh.Marshal(struct{ Status uint32 }{status}))
+				channel.Close()
+			}
+		}
+	}
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go serve(conn)
+		}
+	}()
+}
+
+// testSSHServer starts serveExec on a loopback port and returns a server
+// that logs in to it
+func testSSHServer(t *testing.T, handle func(command string, channel ssh.Channel) uint32) models.Server {
+	t.Helper()
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { listener.Close() })
+
+	// Keep the TOFU known_hosts write inside the test
+	t.Setenv("HOME", t.TempDir())
+
+	keyPath := filepath.Join(t.TempDir(), "id_ed25519")
+	if _, err := GenerateSSHKeyPair(keyPath, "", false); err != nil {
+		t.Fatal(err)
+	}
+	pubBytes, err := os.ReadFile(keyPath + ".pub")
+	if err != nil {
+		t.Fatal(err)
+	}
+	clientKey, _, _, _, err := ssh.ParseAuthorizedKey(pubBytes)
+	if err != nil {
+		t.Fatal(err)
+	}
+	serveExec(t, listener, clientKey, handle)
+
+	return models.Server{
+		Name:     "test",
+		Hostname: "127.0.0.1",
+		SSH: models.SSHConfig{
+			User:    "root",
+			Port:    listener.Addr().(*net.TCPAddr).Port,
+			KeyFile: keyPath,
+		},
+	}
+}
+
+func TestRunSSHCommandIPv6(t *testing.T) {
+	listener, err := net.Listen("tcp", "[::1]:0")
+	if err != nil {
+		t.Skipf("IPv6 loopback unavailable: %v", err)
+	}
+	defer listener.Close()
+
+	// Keep the TOFU known_hosts write inside the test
+	t.Setenv("HOME", t.TempDir())
+
+	keyPath := filepath.Join(t.TempDir(), "id_ed25519")
+	if _, err := GenerateSSHKeyPair(keyPath, "", false); err != nil {
+		t.Fatal(err)
+	}
+	pubBytes, err := os.ReadFile(keyPath + ".pub")
+	if err != nil {
+		t.Fatal(err)
+	}
+	clientKey, _, _, _, err := ssh.ParseAuthorizedKey(pubBytes)
+	if err != nil {
+		t.Fatal(err)
+	}
+	serveOneExec(t, listener, clientKey, "hello from ::1\n")
+
+	server := models.Server{
+		Name:     "v6",
+		Hostname: "::1",
+		IP:       "::1",
+		SSH: models.SSHConfig{
+			User:    "root",
+			Port:    listener.Addr().(*net.TCPAddr).Port,
+			KeyFile: keyPath,
+		},
+	}
+	if want := "[::1]:"; !strings.HasPrefix(server.SSHAddress(), want) {
+		t.Fatalf("SSHAddress() = %q, want prefix %q", server.SSHAddress(), want)
+	}
+
+	output, err := RunSSHCommand(server, "echo hello")
+	if err != nil {
+		t.Fatalf("RunSSHCommand() over IPv6 error = %v", err)
+	}
+	if output != "hello from ::1\n" {
+		t.Errorf("RunSSHCommand() = %q", output)
+	}
+}
+
+func TestRunSSHCommandWithTimeout(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer listener.Close()
+
+	t.Setenv("HOME", t.TempDir())
+
+	keyPath := filepath.Join(t.TempDir(), "id_ed25519")
+	if _, err := GenerateSSHKeyPair(keyPath, "", false); err != nil {
+		t.Fatal(err)
+	}
+	pubBytes, err := os.ReadFile(keyPath + ".pub")
+	if err != nil {
+		t.Fatal(err)
+	}
+	clientKey, _, _, _, err := ssh.ParseAuthorizedKey(pubBytes)
+	if err != nil {
+		t.Fatal(err)
+	}
+	serveOneSlowExec(t, listener, clientKey, "late\n", 5*time.Second)
+
+	server := models.Server{
+		Name:     "slow",
+		Hostname: "127.0.0.1",
+		SSH: models.SSHConfig{
+			User:    "root",
+			Port:    listener.Addr().(*net.TCPAddr).Port,
+			KeyFile: keyPath,
+		},
+	}
+
+	start := time.Now()
+	_, err = RunSSHCommandWithTimeout(server, "echo late", 300*time.Millisecond)
+	if err == nil || !strings.Contains(err.Error(), "timed out") {
+		t.Fatalf("RunSSHCommandWithTimeout() error = %v, want a timeout", err)
+	}
+	if code := exit.CodeOf(err); code != exit.SSH {
+		t.Errorf("exit code = %d, want %d", code, exit.SSH)
+	}
+	if elapsed := time.Since(start); elapsed > 3*time.Second {
+		t.Errorf("RunSSHCommandWithTimeout() returned after %s, want about the timeout", elapsed)
+	}
+}
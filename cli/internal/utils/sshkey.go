@@ -0,0 +1,112 @@
+package utils
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/pem"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"golang.org/x/crypto/ssh"
+)
+
+// DefaultSSHKeyName is the file name used for keys generated by wordsail
+const DefaultSSHKeyName = "wordsail_ed25519"
+
+// DefaultSSHKeyPath returns the path of the generated private key (~/.ssh/wordsail_ed25519)
+func DefaultSSHKeyPath() (string, error) {
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to get user home directory: %w", err)
+	}
+	return filepath.Join(homeDir, ".ssh", DefaultSSHKeyName), nil
+}
+
+// SSHKeyExists reports whether either half of the keypair already exists
+func SSHKeyExists(privateKeyPath string) bool {
+	if _, err := os.Stat(privateKeyPath); err == nil {
+		return true
+	}
+	if _, err := os.Stat(privateKeyPath + ".pub"); err == nil {
+		return true
+	}
+	return false
+}
+
+// GenerateSSHKeyPair generates an ed25519 keypair in OpenSSH format.
+// The private key is written with 0600 permissions and the public key to
+// privateKeyPath+".pub" with 0644. Existing files are only replaced when
+// overwrite is true. Returns the public key path.
+func GenerateSSHKeyPair(privateKeyPath, comment string, overwrite bool) (string, error) {
+	publicKeyPath := privateKeyPath + ".pub"
+
+	if !overwrite && SSHKeyExists(privateKeyPath) {
+		return "", fmt.Errorf("SSH key already exists at %s", privateKeyPath)
+	}
+
+	pub, priv, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		return "", fmt.Errorf("failed to generate ed25519 key: %w", err)
+	}
+
+	block, err := ssh.MarshalPrivateKey(priv, comment)
+	if err != nil {
+		return "", fmt.Errorf("failed to encode private key: %w", err)
+	}
+
+	sshPub, err := ssh.NewPublicKey(pub)
+	if err != nil {
+		return "", fmt.Errorf("failed to encode public key: %w", err)
+	}
+	authorizedKey := ssh.MarshalAuthorizedKey(sshPub)
+	if comment != "" {
+		// MarshalAuthorizedKey ends with a newline; insert the comment before it
+		authorizedKey = append(authorizedKey[:len(authorizedKey)-1], []byte(" "+comment+"\n")...)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(privateKeyPath), 0700); err != nil {
+		return "", fmt.Errorf("failed to create SSH directory: %w", err)
+	}
+
+	// Remove old files first so the new permissions always apply
+	if overwrite {
+		os.Remove(privateKeyPath)
+		os.Remove(publicKeyPath)
+	}
+
+	if err := os.WriteFile(privateKeyPath, pem.EncodeToMemory(block), 0600); err != nil {
+		return "", fmt.Errorf("failed to write private key: %w", err)
+	}
+	if err := os.WriteFile(publicKeyPath, authorizedKey, 0644); err != nil {
+		return "", fmt.Errorf("failed to write public key: %w", err)
+	}
+
+	return publicKeyPath, nil
+}
+
+// CheckSSHKeyFile verifies that a private key file exists before it is saved
+// to config. It returns a warning, rather than an error, when the file is
+// readable by group or others, since OpenSSH refuses such keys.
+func CheckSSHKeyFile(keyFile string) (string, error) {
+	path, err := ExpandPath(keyFile)
+	if err != nil {
+		return "", err
+	}
+
+	info, err := os.Stat(path)
+	if os.IsNotExist(err) {
+		return "", fmt.Errorf("SSH key file not found: %s", path)
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to check SSH key file %s: %w", path, err)
+	}
+	if info.IsDir() {
+		return "", fmt.Errorf("SSH key file %s is a directory", path)
+	}
+
+	if perm := info.Mode().Perm(); perm&0077 != 0 {
+		return fmt.Sprintf("SSH key file %s has permissions %04o; SSH requires 0600 or 0400 (run: chmod 600 %s)", path, perm, path), nil
+	}
+	return "", nil
+}
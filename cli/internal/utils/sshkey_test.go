@@ -0,0 +1,131 @@
+package utils
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"golang.org/x/crypto/ssh"
+)
+
+func TestGenerateSSHKeyPair(t *testing.T) {
+	dir := t.TempDir()
+	keyPath := filepath.Join(dir, ".ssh", DefaultSSHKeyName)
+
+	pubPath, err := GenerateSSHKeyPair(keyPath, "wordsail", false)
+	if err != nil {
+		t.Fatalf("GenerateSSHKeyPair() error = %v", err)
+	}
+	if pubPath != keyPath+".pub" {
+		t.Errorf("GenerateSSHKeyPair() pubPath = %s, want %s", pubPath, keyPath+".pub")
+	}
+
+	// Check permissions
+	privInfo, err := os.Stat(keyPath)
+	if err != nil {
+		t.Fatalf("private key not written: %v", err)
+	}
+	if privInfo.Mode().Perm() != 0600 {
+		t.Errorf("private key mode = %o, want 600", privInfo.Mode().Perm())
+	}
+	pubInfo, err := os.Stat(pubPath)
+	if err != nil {
+		t.Fatalf("public key not written: %v", err)
+	}
+	if pubInfo.Mode().Perm() != 0644 {
+		t.Errorf("public key mode = %o, want 644", pubInfo.Mode().Perm())
+	}
+
+	// Private key parses and matches the public key
+	privData, _ := os.ReadFile(keyPath)
+	signer, err := ssh.ParsePrivateKey(privData)
+	if err != nil {
+		t.Fatalf("generated private key does not parse: %v", err)
+	}
+	pubData, _ := os.ReadFile(pubPath)
+	pub, comment, _, _, err := ssh.ParseAuthorizedKey(pubData)
+	if err != nil {
+		t.Fatalf("generated public key does not parse: %v", err)
+	}
+	if pub.Type() != ssh.KeyAlgoED25519 {
+		t.Errorf("public key type = %s, want %s", pub.Type(), ssh.KeyAlgoED25519)
+	}
+	if comment != "wordsail" {
+		t.Errorf("public key comment = %q, want %q", comment, "wordsail")
+	}
+	if !bytes.Equal(signer.PublicKey().Marshal(), pub.Marshal()) {
+		t.Error("public key does not match private key")
+	}
+}
+
+func TestGenerateSSHKeyPairOverwrite(t *testing.T) {
+	keyPath := filepath.Join(t.TempDir(), DefaultSSHKeyName)
+
+	if _, err := GenerateSSHKeyPair(keyPath, "", false); err != nil {
+		t.Fatalf("GenerateSSHKeyPair() error = %v", err)
+	}
+	original, _ := os.ReadFile(keyPath)
+
+	// Refuses to overwrite by default
+	if _, err := GenerateSSHKeyPair(keyPath, "", false); err == nil {
+		t.Error("GenerateSSHKeyPair() overwrote an existing key without overwrite=true")
+	}
+	unchanged, _ := os.ReadFile(keyPath)
+	if !bytes.Equal(original, unchanged) {
+		t.Error("existing key was modified after refused overwrite")
+	}
+
+	// Replaces the key when confirmed
+	if _, err := GenerateSSHKeyPair(keyPath, "", true); err != nil {
+		t.Fatalf("GenerateSSHKeyPair(overwrite) error = %v", err)
+	}
+	replaced, _ := os.ReadFile(keyPath)
+	if bytes.Equal(original, replaced) {
+		t.Error("key was not replaced with overwrite=true")
+	}
+}
+
+func TestCheckSSHKeyFile(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name string, mode os.FileMode) string {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, []byte("key"), mode); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.Chmod(path, mode); err != nil {
+			t.Fatal(err)
+		}
+		return path
+	}
+
+	tests := []struct {
+		name        string
+		path        string
+		wantErr     bool
+		wantWarning bool
+	}{
+		{"owner only", write("id_0600", 0600), false, false},
+		{"read only", write("id_0400", 0400), false, false},
+		{"group readable", write("id_0640", 0640), false, true},
+		{"world readable", write("id_0644", 0644), false, true},
+		{"missing", filepath.Join(dir, "missing"), true, false},
+		{"directory", dir, true, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			warning, err := CheckSSHKeyFile(tt.path)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("CheckSSHKeyFile() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if (warning != "") != tt.wantWarning {
+				t.Errorf("CheckSSHKeyFile() warning = %q, wantWarning %v", warning, tt.wantWarning)
+			}
+		})
+	}
+
+	if _, err := CheckSSHKeyFile(filepath.Join(dir, "missing")); err == nil || err.Error() != "SSH key file not found: "+filepath.Join(dir, "missing") {
+		t.Errorf("unexpected error for missing key: %v", err)
+	}
+}
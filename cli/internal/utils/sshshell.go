@@ -0,0 +1,43 @@
+package utils
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/wordsail/cli/pkg/models"
+)
+
+// SSHShellArgs returns the arguments for the system ssh binary that open an
+// interactive shell on a server, with the key path expanded and any jump
+// host routed through. With a site the shell starts in the site's directory.
+func SSHShellArgs(server models.Server, site *models.Site) ([]string, error) {
+	sshCfg := server.SSH
+	identity := []string{}
+	if sshCfg.KeyFile != "" && !sshCfg.UseAgent {
+		keyFile, err := ExpandPath(sshCfg.KeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("invalid SSH key path: %w", err)
+		}
+		identity = []string{"-i", keyFile}
+	}
+
+	args := append([]string{}, identity...)
+	args = append(args, "-p", strconv.Itoa(sshCfg.Port))
+	if sshCfg.HasJumpHost() {
+		// ProxyCommand, as for Ansible, so the identity file also applies
+		// to the bastion
+		proxy := "ssh -W [%h]:%p -q"
+		if len(identity) > 0 {
+			proxy += " -i " + ShellQuote(identity[1])
+		}
+		proxy += fmt.Sprintf(" -p %d %s@%s", sshCfg.JumpPortOrDefault(), sshCfg.JumpLogin(), sshCfg.JumpHost)
+		args = append(args, "-o", "ProxyCommand="+proxy)
+	}
+
+	if site == nil {
+		return append(args, sshCfg.User+"@"+server.Address()), nil
+	}
+	// A command makes ssh skip the terminal unless -t asks for one
+	return append(args, "-t", sshCfg.User+"@"+server.Address(),
+		fmt.Sprintf("cd %s && exec \"$SHELL\" -l", ShellQuote(siteHome(*site)))), nil
+}
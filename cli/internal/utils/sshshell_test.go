@@ -0,0 +1,53 @@
+package utils
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/wordsail/cli/pkg/models"
+)
+
+func TestSSHShellArgs(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	key := filepath.Join(home, ".ssh", "id_ed25519")
+
+	server := models.Server{
+		Name:     "web1",
+		Hostname: "web1.example.com",
+		IP:       "203.0.113.10",
+		SSH:      models.SSHConfig{User: "admin", Port: 2222, KeyFile: "~/.ssh/id_ed25519"},
+	}
+
+	got, err := SSHShellArgs(server, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"-i", key, "-p", "2222", "admin@203.0.113.10"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("SSHShellArgs() = %q, want %q", got, want)
+	}
+
+	site := &models.Site{SiteID: "examplecom", PrimaryDomain: "example.com"}
+	got, err = SSHShellArgs(server, site)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want = []string{"-i", key, "-p", "2222", "-t", "admin@203.0.113.10", `cd '/sites/example.com' && exec "$SHELL" -l`}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("SSHShellArgs(site) = %q, want %q", got, want)
+	}
+
+	bastioned := server
+	bastioned.SSH.UseAgent = true
+	bastioned.SSH.JumpHost = "bastion.example.com"
+	got, err = SSHShellArgs(bastioned, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want = []string{"-p", "2222", "-o", "ProxyCommand=ssh -W [%h]:%p -q -p 22 admin@bastion.example.com", "admin@203.0.113.10"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("SSHShellArgs(jump host) = %q, want %q", got, want)
+	}
+}
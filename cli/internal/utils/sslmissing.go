@@ -0,0 +1,37 @@
+package utils
+
+import "github.com/wordsail/cli/pkg/models"
+
+// MissingSSL is a domain that has no certificate recorded
+type MissingSSL struct {
+	Server string `json:"server"`
+	SiteID string `json:"site_id"`
+	Domain string `json:"domain"`
+}
+
+// FindMissingSSL returns every domain without SSL, optionally limited to one
+// server, in config order. Disabled sites are left out: their domains aren't
+// served, so the ACME challenge cannot succeed.
+func FindMissingSSL(servers []models.Server, serverName string) []MissingSSL {
+	missing := []MissingSSL{}
+	for _, server := range servers {
+		if serverName != "" && server.Name != serverName {
+			continue
+		}
+		for _, site := range server.Sites {
+			if !site.IsEnabled() {
+				continue
+			}
+			for _, d := range site.Domains {
+				if !d.SSLEnabled {
+					missing = append(missing, MissingSSL{
+						Server: server.Name,
+						SiteID: site.SiteID,
+						Domain: d.Domain,
+					})
+				}
+			}
+		}
+	}
+	return missing
+}
@@ -0,0 +1,50 @@
+package utils
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/wordsail/cli/pkg/models"
+)
+
+func TestFindMissingSSL(t *testing.T) {
+	disabled := false
+	servers := []models.Server{
+		{
+			Name: "web1",
+			Sites: []models.Site{
+				{SiteID: "blog", Domains: []models.Domain{
+					{Domain: "blog.com", SSLEnabled: true},
+					{Domain: "www.blog.com"},
+				}},
+				{SiteID: "old", Enabled: &disabled, Domains: []models.Domain{
+					{Domain: "old.com"},
+				}},
+			},
+		},
+		{
+			Name: "web2",
+			Sites: []models.Site{
+				{SiteID: "news", Domains: []models.Domain{
+					{Domain: "news.com"},
+				}},
+			},
+		},
+	}
+
+	want := []MissingSSL{
+		{Server: "web1", SiteID: "blog", Domain: "www.blog.com"},
+		{Server: "web2", SiteID: "news", Domain: "news.com"},
+	}
+	if got := FindMissingSSL(servers, ""); !reflect.DeepEqual(got, want) {
+		t.Errorf("FindMissingSSL() = %+v, want %+v", got, want)
+	}
+
+	if got := FindMissingSSL(servers, "web2"); !reflect.DeepEqual(got, want[1:]) {
+		t.Errorf("FindMissingSSL(web2) = %+v, want news.com only", got)
+	}
+
+	if got := FindMissingSSL(servers, "web3"); got == nil || len(got) != 0 {
+		t.Errorf("FindMissingSSL(unknown) = %#v, want an empty list", got)
+	}
+}
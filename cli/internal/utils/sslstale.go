@@ -0,0 +1,121 @@
+package utils
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/wordsail/cli/pkg/models"
+)
+
+// StaleSSL is a domain whose stored certificate expiry is in the past. The
+// certificate was most likely renewed on the server without the config
+// being updated.
+type StaleSSL struct {
+	Server    string    `json:"server"`
+	SiteID    string    `json:"site_id"`
+	Domain    string    `json:"domain"`
+	ExpiresAt time.Time `json:"ssl_expires_at"`
+}
+
+// IsSSLStale reports whether a domain has SSL enabled but a recorded expiry
+// that has already passed
+func IsSSLStale(d models.Domain, now time.Time) bool {
+	return d.SSLEnabled && d.SSLExpiresAt != nil && d.SSLExpiresAt.Before(now)
+}
+
+// StaleSSLDomains returns the domains of a list whose stored expiry has passed
+func StaleSSLDomains(domains []models.Domain, now time.Time) []models.Domain {
+	stale := []models.Domain{}
+	for _, d := range domains {
+		if IsSSLStale(d, now) {
+			stale = append(stale, d)
+		}
+	}
+	return stale
+}
+
+// FindStaleSSL returns every domain with a stale stored expiry, optionally
+// limited to one server
+func FindStaleSSL(servers []models.Server, serverName string, now time.Time) []StaleSSL {
+	stale := []StaleSSL{}
+	for _, server := range servers {
+		if serverName != "" && server.Name != serverName {
+			continue
+		}
+		for _, site := range server.Sites {
+			for _, d := range StaleSSLDomains(site.Domains, now) {
+				stale = append(stale, StaleSSL{
+					Server:    server.Name,
+					SiteID:    site.SiteID,
+					Domain:    d.Domain,
+					ExpiresAt: *d.SSLExpiresAt,
+				})
+			}
+		}
+	}
+	return stale
+}
+
+// SSLExpiryChange is a domain whose stored certificate expiry differs from
+// the live certificate on its server. From is nil if none was stored.
+type SSLExpiryChange struct {
+	Server string     `json:"server"`
+	SiteID string     `json:"site_id"`
+	Domain string     `json:"domain"`
+	From   *time.Time `json:"from,omitempty"`
+	To     time.Time  `json:"to"`
+}
+
+// SSLExpiryDrift compares the stored expiry of each SSL-enabled domain of a
+// server with the live one returned by readExpiry. It returns the domains
+// that differ, and an error for each domain whose certificate could not be
+// read; those are left out of the changes.
+func SSLExpiryDrift(server models.Server, readExpiry func(domain string) (*time.Time, error)) ([]SSLExpiryChange, []error) {
+	changes := []SSLExpiryChange{}
+	var errs []error
+	for _, site := range server.Sites {
+		for _, d := range site.Domains {
+			if !d.SSLEnabled {
+				continue
+			}
+			live, err := readExpiry(d.Domain)
+			if err != nil {
+				errs = append(errs, err)
+				continue
+			}
+			if d.SSLExpiresAt != nil && d.SSLExpiresAt.Equal(*live) {
+				continue
+			}
+			changes = append(changes, SSLExpiryChange{
+				Server: server.Name,
+				SiteID: site.SiteID,
+				Domain: d.Domain,
+				From:   d.SSLExpiresAt,
+				To:     *live,
+			})
+		}
+	}
+	return changes, errs
+}
+
+// ReadCertExpiry reads the expiry of the live Let's Encrypt certificate for
+// domain from the server
+func ReadCertExpiry(server models.Server, domain string) (*time.Time, error) {
+	certPath := fmt.Sprintf("/etc/letsencrypt/live/%s/cert.pem", domain)
+	output, err := RunSSHCommand(server, "sudo -n openssl x509 -enddate -noout -in "+ShellQuote(certPath))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read certificate for %s: %s", domain, firstLine(output, err))
+	}
+	return parseCertEndDate(output)
+}
+
+// parseCertEndDate parses `openssl x509 -enddate` output
+// ("notAfter=Mar 15 12:00:00 2024 GMT"), reporting output that is not a date
+func parseCertEndDate(output string) (*time.Time, error) {
+	expiresAt := ParseSSLExpiry(output)
+	if expiresAt == nil {
+		return nil, fmt.Errorf("unexpected openssl output: %s", strings.TrimSpace(output))
+	}
+	return expiresAt, nil
+}
@@ -0,0 +1,148 @@
+package utils
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/wordsail/cli/pkg/models"
+)
+
+func TestIsSSLStale(t *testing.T) {
+	now := time.Date(2026, 3, 10, 12, 0, 0, 0, time.UTC)
+	past := now.Add(-time.Hour)
+	future := now.AddDate(0, 1, 0)
+
+	tests := []struct {
+		name   string
+		domain models.Domain
+		want   bool
+	}{
+		{"expired", models.Domain{Domain: "a.com", SSLEnabled: true, SSLExpiresAt: &past}, true},
+		{"valid", models.Domain{Domain: "a.com", SSLEnabled: true, SSLExpiresAt: &future}, false},
+		{"no expiry recorded", models.Domain{Domain: "a.com", SSLEnabled: true}, false},
+		{"ssl disabled", models.Domain{Domain: "a.com", SSLExpiresAt: &past}, false},
+		{"expires exactly now", models.Domain{Domain: "a.com", SSLEnabled: true, SSLExpiresAt: &now}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := IsSSLStale(tt.domain, now); got != tt.want {
+				t.Errorf("IsSSLStale() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFindStaleSSL(t *testing.T) {
+	now := time.Date(2026, 3, 10, 12, 0, 0, 0, time.UTC)
+	past := now.AddDate(0, 0, -3)
+	future := now.AddDate(0, 2, 0)
+
+	servers := []models.Server{
+		{
+			Name: "web1",
+			Sites: []models.Site{
+				{SiteID: "blog", Domains: []models.Domain{
+					{Domain: "blog.com", SSLEnabled: true, SSLExpiresAt: &past},
+					{Domain: "www.blog.com", SSLEnabled: true, SSLExpiresAt: &future},
+				}},
+				{SiteID: "shop", Domains: []models.Domain{
+					{Domain: "shop.com"},
+				}},
+			},
+		},
+		{
+			Name: "web2",
+			Sites: []models.Site{
+				{SiteID: "news", Domains: []models.Domain{
+					{Domain: "news.com", SSLEnabled: true, SSLExpiresAt: &past},
+				}},
+			},
+		},
+	}
+
+	all := FindStaleSSL(servers, "", now)
+	if len(all) != 2 {
+		t.Fatalf("FindStaleSSL() = %+v, want 2 entries", all)
+	}
+	if all[0].Server != "web1" || all[0].SiteID != "blog" || all[0].Domain != "blog.com" || !all[0].ExpiresAt.Equal(past) {
+		t.Errorf("first entry = %+v", all[0])
+	}
+
+	filtered := FindStaleSSL(servers, "web2", now)
+	if len(filtered) != 1 || filtered[0].Domain != "news.com" {
+		t.Errorf("FindStaleSSL(web2) = %+v, want news.com only", filtered)
+	}
+
+	if got := FindStaleSSL(servers, "web3", now); got == nil || len(got) != 0 {
+		t.Errorf("FindStaleSSL(unknown) = %#v, want an empty list", got)
+	}
+
+	domains := StaleSSLDomains(servers[0].Sites[0].Domains, now)
+	if len(domains) != 1 || domains[0].Domain != "blog.com" {
+		t.Errorf("StaleSSLDomains() = %+v, want blog.com only", domains)
+	}
+}
+
+func TestSSLExpiryDrift(t *testing.T) {
+	stored := time.Date(2026, 3, 10, 12, 0, 0, 0, time.UTC)
+	renewed := stored.AddDate(0, 2, 0)
+
+	server := models.Server{
+		Name: "web1",
+		Sites: []models.Site{
+			{SiteID: "blog", Domains: []models.Domain{
+				{Domain: "blog.com", SSLEnabled: true, SSLExpiresAt: &stored},
+				{Domain: "www.blog.com", SSLEnabled: true, SSLExpiresAt: &renewed},
+				{Domain: "old.blog.com"},
+			}},
+			{SiteID: "shop", Domains: []models.Domain{
+				{Domain: "shop.com", SSLEnabled: true},
+				{Domain: "broken.shop.com", SSLEnabled: true, SSLExpiresAt: &stored},
+			}},
+		},
+	}
+
+	var read []string
+	changes, errs := SSLExpiryDrift(server, func(domain string) (*time.Time, error) {
+		read = append(read, domain)
+		if domain == "broken.shop.com" {
+			return nil, errors.New("no certificate")
+		}
+		live := renewed
+		return &live, nil
+	})
+
+	if want := []string{"blog.com", "www.blog.com", "shop.com", "broken.shop.com"}; !reflect.DeepEqual(read, want) {
+		t.Errorf("read certificates of %v, want %v", read, want)
+	}
+	if len(errs) != 1 {
+		t.Errorf("errors = %v, want one for broken.shop.com", errs)
+	}
+	if len(changes) != 2 {
+		t.Fatalf("SSLExpiryDrift() = %+v, want blog.com and shop.com", changes)
+	}
+	if c := changes[0]; c.Server != "web1" || c.SiteID != "blog" || c.Domain != "blog.com" ||
+		c.From == nil || !c.From.Equal(stored) || !c.To.Equal(renewed) {
+		t.Errorf("first change = %+v", c)
+	}
+	if c := changes[1]; c.Domain != "shop.com" || c.From != nil || !c.To.Equal(renewed) {
+		t.Errorf("second change = %+v, want shop.com with no stored expiry", c)
+	}
+}
+
+func TestParseCertEndDate(t *testing.T) {
+	got, err := parseCertEndDate("notAfter=Jun  8 09:30:00 2026 GMT\n")
+	if err != nil {
+		t.Fatalf("parseCertEndDate() error = %v", err)
+	}
+	if want := time.Date(2026, 6, 8, 9, 30, 0, 0, time.UTC); !got.Equal(want) {
+		t.Errorf("parseCertEndDate() = %v, want %v", got, want)
+	}
+
+	if _, err := parseCertEndDate("Could not open file"); err == nil {
+		t.Error("expected an error for unparseable output")
+	}
+}
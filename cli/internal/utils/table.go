@@ -2,11 +2,114 @@ package utils
 
 import (
 	"fmt"
+	"io"
+	"os"
 	"strings"
+	"unicode/utf8"
+
+	"golang.org/x/term"
 )
 
-// PrintTableWithBorders prints a table with borders
+// minAutoColWidth is the narrowest PrintTable shrinks a column to
+const minAutoColWidth = 6
+
+// PrintTable prints a table with borders, sizing each column to its widest
+// cell. When stdout is a terminal the table is kept within its width by
+// shrinking the widest columns and truncating their cells with an ellipsis.
+func PrintTable(headers []string, rows [][]string) {
+	colWidths := AutoColumnWidths(headers, rows, TerminalWidth())
+	fittedHeaders := make([]string, len(headers))
+	for i, header := range headers {
+		fittedHeaders[i] = truncateCell(header, colWidths[i])
+	}
+	fitted := make([][]string, len(rows))
+	for i, row := range rows {
+		fitted[i] = make([]string, len(row))
+		for j, cell := range row {
+			fitted[i][j] = truncateCell(cell, colWidths[j])
+		}
+	}
+	PrintTableWithBorders(fittedHeaders, fitted, colWidths)
+}
+
+// TerminalWidth returns the width of the terminal on stdout, or 0 when
+// stdout is not a terminal
+func TerminalWidth() int {
+	width, _, err := term.GetSize(int(os.Stdout.Fd()))
+	if err != nil {
+		return 0
+	}
+	return width
+}
+
+// AutoColumnWidths returns the width of each column's widest visible cell.
+// If maxWidth is positive and the bordered table would be wider, the widest
+// columns are narrowed until it fits or every column is at its minimum.
+func AutoColumnWidths(headers []string, rows [][]string, maxWidth int) []int {
+	widths := make([]int, len(headers))
+	for i, header := range headers {
+		widths[i] = visibleLen(header)
+	}
+	for _, row := range rows {
+		for i, cell := range row {
+			if i < len(widths) && visibleLen(cell) > widths[i] {
+				widths[i] = visibleLen(cell)
+			}
+		}
+	}
+	if maxWidth <= 0 {
+		return widths
+	}
+
+	// Each column adds " │ " and the table adds its outer borders
+	total := 1
+	for _, w := range widths {
+		total += w + 3
+	}
+	for total > maxWidth {
+		widest := -1
+		for i, w := range widths {
+			if w > minAutoColWidth && (widest < 0 || w > widths[widest]) {
+				widest = i
+			}
+		}
+		if widest < 0 {
+			break
+		}
+		widths[widest]--
+		total--
+	}
+	return widths
+}
+
+// truncateCell shortens a cell to width visible characters, ending it with
+// an ellipsis. Truncated cells lose their color.
+func truncateCell(cell string, width int) string {
+	if visibleLen(cell) <= width {
+		return cell
+	}
+	runes := []rune(stripANSI(cell))
+	if width <= 1 {
+		return string(runes[:width])
+	}
+	return string(runes[:width-1]) + "…"
+}
+
+// visibleLen returns the number of characters a cell takes on screen
+func visibleLen(s string) int {
+	return utf8.RuneCountInString(stripANSI(s))
+}
+
+// PrintTableWithBorders prints a table with borders, through the pager when
+// it is taller than the terminal
 func PrintTableWithBorders(headers []string, rows [][]string, colWidths []int) {
+	var b strings.Builder
+	writeTable(&b, headers, rows, colWidths)
+	Page(b.String())
+}
+
+// writeTable writes a table with borders to w
+func writeTable(w io.Writer, headers []string, rows [][]string, colWidths []int) {
 	// Calculate total width
 	totalWidth := 0
 	for _, w := range colWidths {
@@ -15,56 +118,57 @@ func PrintTableWithBorders(headers []string, rows [][]string, colWidths []int) {
 	totalWidth += 1 // for final "|"
 
 	// Top border
-	fmt.Println("┌" + strings.Repeat("─", totalWidth-2) + "┐")
+	fmt.Fprintln(w, "┌"+strings.Repeat("─", totalWidth-2)+"┐")
 
 	// Headers
-	fmt.Print("│ ")
+	fmt.Fprint(w, "│ ")
 	for i, header := range headers {
-		fmt.Printf("%-*s", colWidths[i], header)
+		fmt.Fprintf(w, "%-*s", colWidths[i], header)
 		if i < len(headers)-1 {
-			fmt.Print(" │ ")
+			fmt.Fprint(w, " │ ")
 		}
 	}
-	fmt.Println(" │")
+	fmt.Fprintln(w, " │")
 
 	// Header separator
-	fmt.Print("├")
+	fmt.Fprint(w, "├")
 	for i := range headers {
-		fmt.Print(strings.Repeat("─", colWidths[i]+2))
+		fmt.Fprint(w, strings.Repeat("─", colWidths[i]+2))
 		if i < len(headers)-1 {
-			fmt.Print("┼")
+			fmt.Fprint(w, "┼")
 		}
 	}
-	fmt.Println("┤")
+	fmt.Fprintln(w, "┤")
 
 	// Rows
 	for _, row := range rows {
-		fmt.Print("│ ")
+		fmt.Fprint(w, "│ ")
 		for i, cell := range row {
-			// Handle colored text - don't count ANSI codes in width
+			// Handle colored text - don't count ANSI codes in width. With
+			// color disabled (--no-color, $NO_COLOR or no terminal) cells
+			// are plain text and take the simple path below.
 			displayWidth := colWidths[i]
 			// If cell contains ANSI codes, adjust padding
 			if strings.Contains(cell, "\033[") {
 				// Count visible characters (excluding ANSI codes)
-				visibleLen := len(stripANSI(cell))
-				padding := colWidths[i] - visibleLen
-				fmt.Print(cell)
+				padding := colWidths[i] - visibleLen(cell)
+				fmt.Fprint(w, cell)
 				if padding > 0 {
-					fmt.Print(strings.Repeat(" ", padding))
+					fmt.Fprint(w, strings.Repeat(" ", padding))
 				}
 			} else {
-				fmt.Printf("%-*s", displayWidth, cell)
+				fmt.Fprintf(w, "%-*s", displayWidth, cell)
 			}
 
 			if i < len(row)-1 {
-				fmt.Print(" │ ")
+				fmt.Fprint(w, " │ ")
 			}
 		}
-		fmt.Println(" │")
+		fmt.Fprintln(w, " │")
 	}
 
 	// Bottom border
-	fmt.Println("└" + strings.Repeat("─", totalWidth-2) + "┘")
+	fmt.Fprintln(w, "└"+strings.Repeat("─", totalWidth-2)+"┘")
 }
 
 // stripANSI removes ANSI color codes from a string for length calculation
@@ -0,0 +1,54 @@
+package utils
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestAutoColumnWidths(t *testing.T) {
+	headers := []string{"NAME", "DOMAIN", "STATUS"}
+	rows := [][]string{
+		{"web1", "a-very-long-domain-name.example.com", "\033[32mprovisioned\033[0m"},
+		{"production-2", "short.io", "error"},
+	}
+
+	tests := []struct {
+		name     string
+		maxWidth int
+		want     []int
+	}{
+		{"no limit", 0, []int{12, 35, 11}},
+		{"fits", 80, []int{12, 35, 11}},
+		{"shrinks widest column", 50, []int{12, 17, 11}},
+		{"stops at minimum widths", 10, []int{6, 6, 6}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := AutoColumnWidths(headers, rows, tt.maxWidth); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("AutoColumnWidths() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTruncateCell(t *testing.T) {
+	tests := []struct {
+		cell  string
+		width int
+		want  string
+	}{
+		{"example.com", 20, "example.com"},
+		{"example.com", 11, "example.com"},
+		{"example.com", 8, "example…"},
+		{"\033[32mprovisioned\033[0m", 11, "\033[32mprovisioned\033[0m"},
+		{"\033[32mprovisioned\033[0m", 6, "provi…"},
+		{"héllo wörld", 6, "héllo…"},
+	}
+
+	for _, tt := range tests {
+		if got := truncateCell(tt.cell, tt.width); got != tt.want {
+			t.Errorf("truncateCell(%q, %d) = %q, want %q", tt.cell, tt.width, got, tt.want)
+		}
+	}
+}
@@ -59,6 +59,28 @@ func ValidateEmail(val interface{}) error {
 	return nil
 }
 
+// DefaultPHPVersion is the PHP version installed at provisioning and used
+// for new sites unless another is chosen
+const DefaultPHPVersion = "8.3"
+
+// SupportedPHPVersions are the PHP versions a site can be created with
+var SupportedPHPVersions = []string{"7.4", "8.0", "8.1", "8.2", "8.3", "8.4"}
+
+// ValidatePHPVersion validates that a PHP version is one of SupportedPHPVersions
+func ValidatePHPVersion(val interface{}) error {
+	version, ok := val.(string)
+	if !ok {
+		return fmt.Errorf("invalid PHP version type")
+	}
+
+	for _, v := range SupportedPHPVersions {
+		if version == v {
+			return nil
+		}
+	}
+	return fmt.Errorf("unsupported PHP version '%s' (must be one of: %s)", version, strings.Join(SupportedPHPVersions, ", "))
+}
+
 // ValidatePasswordStrength validates password complexity
 // Requires: minimum 12 characters, at least one uppercase, one lowercase,
 // one number, and one special character
@@ -127,6 +149,89 @@ func ValidateIP(val interface{}) error {
 	return nil
 }
 
+// hostnameLabel matches one DNS label (letters, digits and inner hyphens)
+var hostnameLabel = regexp.MustCompile(`^[a-zA-Z0-9]([a-zA-Z0-9\-]{0,61}[a-zA-Z0-9])?$`)
+
+// ValidateHostname validates a host name such as server1.example.com. A
+// trailing dot is allowed; an all-numeric last label is not, so malformed IP
+// addresses are not mistaken for host names.
+func ValidateHostname(val interface{}) error {
+	str, ok := val.(string)
+	if !ok {
+		return fmt.Errorf("invalid type")
+	}
+
+	host := strings.TrimSuffix(str, ".")
+	if host == "" || len(host) > 253 {
+		return fmt.Errorf("invalid hostname format")
+	}
+
+	labels := strings.Split(host, ".")
+	for _, label := range labels {
+		if !hostnameLabel.MatchString(label) {
+			return fmt.Errorf("invalid hostname format")
+		}
+	}
+	if strings.Trim(labels[len(labels)-1], "0123456789") == "" {
+		return fmt.Errorf("invalid hostname format")
+	}
+
+	return nil
+}
+
+// ValidateServerName checks that a server name is hostname-safe: a single
+// DNS label of letters, digits and inner hyphens. Server names end up in
+// inventory file names and Ansible host names.
+func ValidateServerName(val interface{}) error {
+	name, ok := val.(string)
+	if !ok {
+		return fmt.Errorf("invalid server name type")
+	}
+	if !hostnameLabel.MatchString(name) {
+		return fmt.Errorf("invalid server name '%s': use up to 63 letters, digits and hyphens, not starting or ending with a hyphen", name)
+	}
+	return nil
+}
+
+// ValidateHostnameOrIP accepts either an IP address or a host name
+func ValidateHostnameOrIP(val interface{}) error {
+	if ValidateIP(val) == nil {
+		return nil
+	}
+	if err := ValidateHostname(val); err != nil {
+		return fmt.Errorf("must be an IP address or hostname (e.g., 203.0.113.10 or server1.example.com)")
+	}
+	return nil
+}
+
+// ValidateIPv6 validates an IPv6 address (IPv4 and IPv4-mapped addresses are rejected)
+func ValidateIPv6(val interface{}) error {
+	str, ok := val.(string)
+	if !ok {
+		return fmt.Errorf("invalid type")
+	}
+
+	ip := net.ParseIP(str)
+	if ip == nil {
+		return fmt.Errorf("invalid IP address format")
+	}
+	if ip.To4() != nil {
+		return fmt.Errorf("%s is not an IPv6 address", str)
+	}
+
+	return nil
+}
+
+// NormalizeIPv6 returns the canonical (compressed, lowercase) form of an IPv6
+// address so it compares equal to what DNS lookups return
+func NormalizeIPv6(addr string) string {
+	ip := net.ParseIP(addr)
+	if ip == nil {
+		return addr
+	}
+	return ip.String()
+}
+
 // ValidatePort validates a port number (1-65535)
 func ValidatePort(val interface{}) error {
 	port, ok := val.(int)
@@ -140,3 +245,52 @@ func ValidatePort(val interface{}) error {
 
 	return nil
 }
+
+// pythonInterpreterRegex matches an absolute interpreter path such as
+// /usr/bin/python3.11 or /opt/venv/bin/python
+var pythonInterpreterRegex = regexp.MustCompile(`^/[a-zA-Z0-9_\-./]+$`)
+
+// ValidatePythonInterpreter validates a remote Python interpreter for
+// ansible_python_interpreter: an absolute path, or one of Ansible's
+// discovery modes (auto, auto_silent, auto_legacy, auto_legacy_silent)
+func ValidatePythonInterpreter(val interface{}) error {
+	str, ok := val.(string)
+	if !ok {
+		return fmt.Errorf("invalid interpreter type")
+	}
+
+	switch str {
+	case "auto", "auto_silent", "auto_legacy", "auto_legacy_silent":
+		return nil
+	}
+	if !pythonInterpreterRegex.MatchString(str) {
+		return fmt.Errorf("python interpreter '%s' must be an absolute path (e.g. /usr/bin/python3) or 'auto'", str)
+	}
+	return nil
+}
+
+// ValidateAnsibleTags validates a comma-separated list of Ansible tags
+// (e.g. "security" or "nginx,php")
+func ValidateAnsibleTags(val interface{}) error {
+	str, ok := val.(string)
+	if !ok {
+		return fmt.Errorf("invalid tags type")
+	}
+
+	if strings.TrimSpace(str) == "" {
+		return fmt.Errorf("tags must not be empty")
+	}
+
+	tagRegex := regexp.MustCompile(`^[a-zA-Z0-9_\-.]+$`)
+	for _, tag := range strings.Split(str, ",") {
+		tag = strings.TrimSpace(tag)
+		if tag == "" {
+			return fmt.Errorf("tags must be comma-separated without empty entries")
+		}
+		if !tagRegex.MatchString(tag) {
+			return fmt.Errorf("invalid tag '%s' (letters, numbers, '_', '-' and '.' only)", tag)
+		}
+	}
+
+	return nil
+}
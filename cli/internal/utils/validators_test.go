@@ -1,6 +1,7 @@
 package utils
 
 import (
+	"strings"
 	"testing"
 )
 
@@ -122,6 +123,31 @@ func TestValidatePasswordStrength(t *testing.T) {
 	}
 }
 
+func TestValidatePHPVersion(t *testing.T) {
+	tests := []struct {
+		name    string
+		version interface{}
+		wantErr bool
+	}{
+		{"default", DefaultPHPVersion, false},
+		{"legacy", "7.4", false},
+		{"newest", "8.4", false},
+		{"unsupported", "5.6", true},
+		{"patch version", "8.3.1", true},
+		{"empty", "", true},
+		{"invalid type", 8.3, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidatePHPVersion(tt.version)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ValidatePHPVersion() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
 func TestValidateIP(t *testing.T) {
 	tests := []struct {
 		name    string
@@ -151,6 +177,115 @@ func TestValidateIP(t *testing.T) {
 	}
 }
 
+func TestValidateServerName(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   interface{}
+		wantErr bool
+	}{
+		{"simple", "web1", false},
+		{"hyphenated", "web-prod-1", false},
+		{"invalid - underscore", "web_1", true},
+		{"invalid - dot", "web.example.com", true},
+		{"invalid - space", "web 1", true},
+		{"invalid - path", "../web", true},
+		{"invalid - starts with hyphen", "-web", true},
+		{"invalid - ends with hyphen", "web-", true},
+		{"invalid - too long", strings.Repeat("a", 64), true},
+		{"invalid - empty", "", true},
+		{"invalid type", 123, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateServerName(tt.input)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ValidateServerName() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestValidateHostnameOrIP(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   interface{}
+		wantErr bool
+	}{
+		{"ipv4", "203.0.113.10", false},
+		{"ipv6", "2001:db8::10", false},
+		{"fqdn", "server1.example.com", false},
+		{"fqdn with trailing dot", "server1.example.com.", false},
+		{"single label", "web1", false},
+		{"hyphenated", "web-1.eu-west.example.com", false},
+		{"invalid - malformed ip", "203.0.113.256", true},
+		{"invalid - numeric labels only", "1.2.3", true},
+		{"invalid - starts with hyphen", "-web.example.com", true},
+		{"invalid - double dots", "web..example.com", true},
+		{"invalid - underscore", "web_1.example.com", true},
+		{"invalid - user@host", "root@example.com", true},
+		{"invalid - host:port", "example.com:22", true},
+		{"invalid - empty", "", true},
+		{"invalid type", 123, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateHostnameOrIP(tt.input)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ValidateHostnameOrIP() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestValidateIPv6(t *testing.T) {
+	tests := []struct {
+		name    string
+		ip      interface{}
+		wantErr bool
+	}{
+		{"valid full", "2001:0db8:85a3:0000:0000:8a2e:0370:7334", false},
+		{"valid compressed", "2001:db8::1", false},
+		{"valid loopback", "::1", false},
+		{"invalid - IPv4", "192.168.1.1", true},
+		{"invalid - IPv4-mapped", "::ffff:192.168.1.1", true},
+		{"invalid - garbage", "2001:db8::zz", true},
+		{"invalid - empty", "", true},
+		{"invalid type", 123, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateIPv6(tt.ip)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ValidateIPv6() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestNormalizeIPv6(t *testing.T) {
+	tests := []struct {
+		name string
+		addr string
+		want string
+	}{
+		{"full form", "2001:0db8:0000:0000:0000:0000:0000:0001", "2001:db8::1"},
+		{"uppercase", "2001:DB8::A", "2001:db8::a"},
+		{"already canonical", "2001:db8::1", "2001:db8::1"},
+		{"invalid left alone", "not-an-ip", "not-an-ip"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := NormalizeIPv6(tt.addr); got != tt.want {
+				t.Errorf("NormalizeIPv6(%q) = %q, want %q", tt.addr, got, tt.want)
+			}
+		})
+	}
+}
+
 func TestValidatePort(t *testing.T) {
 	tests := []struct {
 		name    string
@@ -177,3 +312,59 @@ func TestValidatePort(t *testing.T) {
 		})
 	}
 }
+
+func TestValidateAnsibleTags(t *testing.T) {
+	tests := []struct {
+		name    string
+		tags    interface{}
+		wantErr bool
+	}{
+		{"valid single tag", "security", false},
+		{"valid multiple tags", "nginx,php", false},
+		{"valid with spaces around commas", "nginx, php", false},
+		{"valid with underscore and hyphen", "wp_core,ssl-renew", false},
+		{"invalid - empty", "", true},
+		{"invalid - whitespace only", "   ", true},
+		{"invalid - trailing comma", "nginx,", true},
+		{"invalid - double comma", "nginx,,php", true},
+		{"invalid - space inside tag", "nginx php", true},
+		{"invalid - shell characters", "nginx;rm", true},
+		{"invalid type", 123, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateAnsibleTags(tt.tags)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ValidateAnsibleTags() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestValidatePythonInterpreter(t *testing.T) {
+	tests := []struct {
+		name        string
+		interpreter interface{}
+		wantErr     bool
+	}{
+		{"valid default", "/usr/bin/python3", false},
+		{"valid versioned", "/usr/bin/python3.11", false},
+		{"valid venv", "/opt/ansible-venv/bin/python", false},
+		{"valid discovery mode", "auto_silent", false},
+		{"invalid - relative path", "python3", true},
+		{"invalid - empty", "", true},
+		{"invalid - space", "/usr/bin/env python3", true},
+		{"invalid - extra inventory var", "/usr/bin/python3\nansible_user=root", true},
+		{"invalid type", 3, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidatePythonInterpreter(tt.interpreter)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ValidatePythonInterpreter() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
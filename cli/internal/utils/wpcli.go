@@ -0,0 +1,252 @@
+package utils
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/wordsail/cli/pkg/models"
+)
+
+// WordPress component types reported by update checks
+const (
+	WPComponentCore   = "core"
+	WPComponentPlugin = "plugin"
+	WPComponentTheme  = "theme"
+)
+
+// WPUpdate describes an available (or applied) update for one component
+type WPUpdate struct {
+	Type    string `json:"type"`
+	Name    string `json:"name"`
+	Current string `json:"current"`
+	New     string `json:"new"`
+}
+
+// WPVersions maps "type:name" to the installed version
+type WPVersions map[string]string
+
+// SitePath returns the document root of a site
+func SitePath(site models.Site) string {
+	return fmt.Sprintf("/sites/%s/files", site.PrimaryDomain)
+}
+
+// WPCLICommand builds a WP-CLI command that runs as the site user
+func WPCLICommand(site models.Site, args string) string {
+	return fmt.Sprintf("sudo -n -u %s -- wp --path=%s %s",
+		ShellQuote(site.SiteID), ShellQuote(SitePath(site)), args)
+}
+
+// RunWPCLI runs a WP-CLI command on the server as the site user
+func RunWPCLI(server models.Server, site models.Site, args string) (string, error) {
+	output, err := RunSSHCommand(server, WPCLICommand(site, args))
+	if err != nil {
+		return output, fmt.Errorf("wp %s: %w", strings.Fields(args)[0], err)
+	}
+	return output, nil
+}
+
+// CheckWPUpdates lists available updates for the given component types
+// (WPComponentCore, WPComponentPlugin, WPComponentTheme)
+func CheckWPUpdates(server models.Server, site models.Site, components []string) ([]WPUpdate, error) {
+	var updates []WPUpdate
+
+	for _, kind := range components {
+		if kind == WPComponentCore {
+			current, err := RunWPCLI(server, site, "core version")
+			if err != nil {
+				return nil, err
+			}
+			output, err := RunWPCLI(server, site, "core check-update --format=json")
+			if err != nil {
+				return nil, err
+			}
+			coreUpdates, err := parseCoreUpdates(strings.TrimSpace(current), output)
+			if err != nil {
+				return nil, err
+			}
+			updates = append(updates, coreUpdates...)
+			continue
+		}
+
+		output, err := RunWPCLI(server, site, kind+" list --update=available --fields=name,version,update_version --format=json")
+		if err != nil {
+			return nil, err
+		}
+		extUpdates, err := parseExtensionUpdates(kind, output)
+		if err != nil {
+			return nil, err
+		}
+		updates = append(updates, extUpdates...)
+	}
+
+	return updates, nil
+}
+
+// ApplyWPUpdates updates the given component types
+func ApplyWPUpdates(server models.Server, site models.Site, components []string) error {
+	for _, kind := range components {
+		commands := []string{kind + " update --all"}
+		if kind == WPComponentCore {
+			// Run database migrations right after a core update
+			commands = []string{"core update", "core update-db"}
+		}
+		for _, args := range commands {
+			if _, err := RunWPCLI(server, site, args); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// WPInstalledVersions returns the installed core, plugin and theme versions
+func WPInstalledVersions(server models.Server, site models.Site) (WPVersions, error) {
+	versions := WPVersions{}
+
+	current, err := RunWPCLI(server, site, "core version")
+	if err != nil {
+		return nil, err
+	}
+	versions[WPComponentCore+":wordpress"] = strings.TrimSpace(current)
+
+	for _, kind := range []string{WPComponentPlugin, WPComponentTheme} {
+		output, err := RunWPCLI(server, site, kind+" list --fields=name,version --format=json")
+		if err != nil {
+			return nil, err
+		}
+		var items []struct {
+			Name    string `json:"name"`
+			Version string `json:"version"`
+		}
+		if err := json.Unmarshal([]byte(jsonPayload(output)), &items); err != nil {
+			return nil, fmt.Errorf("failed to parse %s list: %w", kind, err)
+		}
+		for _, item := range items {
+			versions[kind+":"+item.Name] = item.Version
+		}
+	}
+
+	return versions, nil
+}
+
+// DiffWPVersions returns the components whose version changed, sorted by type and name
+func DiffWPVersions(before, after WPVersions) []WPUpdate {
+	var changed []WPUpdate
+	for key, newVersion := range after {
+		oldVersion, ok := before[key]
+		if !ok || oldVersion == newVersion {
+			continue
+		}
+		kind, name, _ := strings.Cut(key, ":")
+		changed = append(changed, WPUpdate{Type: kind, Name: name, Current: oldVersion, New: newVersion})
+	}
+
+	sort.Slice(changed, func(i, j int) bool {
+		if changed[i].Type != changed[j].Type {
+			return changed[i].Type < changed[j].Type
+		}
+		return changed[i].Name < changed[j].Name
+	})
+	return changed
+}
+
+// SiteTemplateCommands returns the WP-CLI commands that apply a site
+// template: install and activate the plugins, then the theme, then set the
+// options in name order
+func SiteTemplateCommands(tmpl models.SiteTemplate) []string {
+	var commands []string
+	if len(tmpl.Plugins) > 0 {
+		quoted := make([]string, len(tmpl.Plugins))
+		for i, plugin := range tmpl.Plugins {
+			quoted[i] = ShellQuote(plugin)
+		}
+		commands = append(commands, "plugin install "+strings.Join(quoted, " ")+" --activate")
+	}
+	if tmpl.Theme != "" {
+		commands = append(commands, "theme install "+ShellQuote(tmpl.Theme)+" --activate")
+	}
+
+	names := make([]string, 0, len(tmpl.Options))
+	for name := range tmpl.Options {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		commands = append(commands, "option update "+ShellQuote(name)+" "+ShellQuote(tmpl.Options[name]))
+	}
+	return commands
+}
+
+// ApplySiteTemplate runs a site template's WP-CLI commands as the site user
+func ApplySiteTemplate(server models.Server, site models.Site, tmpl models.SiteTemplate) error {
+	for _, args := range SiteTemplateCommands(tmpl) {
+		if _, err := RunWPCLI(server, site, args); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// parseCoreUpdates parses `wp core check-update --format=json`, which prints
+// nothing (or a success message) when WordPress is up to date
+func parseCoreUpdates(current, output string) ([]WPUpdate, error) {
+	payload := jsonPayload(output)
+	if payload == "" {
+		return nil, nil
+	}
+
+	var items []struct {
+		Version    string `json:"version"`
+		UpdateType string `json:"update_type"`
+	}
+	if err := json.Unmarshal([]byte(payload), &items); err != nil {
+		return nil, fmt.Errorf("failed to parse core update check: %w", err)
+	}
+	if len(items) == 0 {
+		return nil, nil
+	}
+
+	// WP-CLI lists the newest release first
+	return []WPUpdate{{Type: WPComponentCore, Name: "wordpress", Current: current, New: items[0].Version}}, nil
+}
+
+// parseExtensionUpdates parses `wp plugin|theme list --update=available --format=json`
+func parseExtensionUpdates(kind, output string) ([]WPUpdate, error) {
+	payload := jsonPayload(output)
+	if payload == "" {
+		return nil, nil
+	}
+
+	var items []struct {
+		Name          string `json:"name"`
+		Version       string `json:"version"`
+		UpdateVersion string `json:"update_version"`
+	}
+	if err := json.Unmarshal([]byte(payload), &items); err != nil {
+		return nil, fmt.Errorf("failed to parse %s update list: %w", kind, err)
+	}
+
+	updates := make([]WPUpdate, 0, len(items))
+	for _, item := range items {
+		updates = append(updates, WPUpdate{Type: kind, Name: item.Name, Current: item.Version, New: item.UpdateVersion})
+	}
+	return updates, nil
+}
+
+// jsonPayload returns the JSON array in WP-CLI output, skipping any
+// warnings or success messages printed around it
+func jsonPayload(output string) string {
+	start := strings.Index(output, "[")
+	end := strings.LastIndex(output, "]")
+	if start < 0 || end < start {
+		return ""
+	}
+	return output[start : end+1]
+}
+
+// ShellQuote wraps a value in single quotes for a remote shell
+func ShellQuote(value string) string {
+	return "'" + strings.ReplaceAll(value, "'", `'\''`) + "'"
+}
@@ -0,0 +1,93 @@
+package utils
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/wordsail/cli/pkg/models"
+)
+
+func TestWPCLICommand(t *testing.T) {
+	site := models.Site{SiteID: "example", PrimaryDomain: "example.com"}
+	got := WPCLICommand(site, "core version")
+	want := "sudo -n -u 'example' -- wp --path='/sites/example.com/files' core version"
+	if got != want {
+		t.Errorf("WPCLICommand() = %q, want %q", got, want)
+	}
+}
+
+func TestParseCoreUpdates(t *testing.T) {
+	output := `[{"version":"6.5.3","update_type":"minor","package_url":"https://downloads.wordpress.org/release/wordpress-6.5.3.zip"},{"version":"6.6","update_type":"major","package_url":"x"}]`
+	got, err := parseCoreUpdates("6.5.2", output)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := []WPUpdate{{Type: WPComponentCore, Name: "wordpress", Current: "6.5.2", New: "6.5.3"}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("parseCoreUpdates() = %+v, want %+v", got, want)
+	}
+
+	for _, upToDate := range []string{"", "Success: WordPress is at the latest version.\n", "[]"} {
+		got, err := parseCoreUpdates("6.6", upToDate)
+		if err != nil || len(got) != 0 {
+			t.Errorf("parseCoreUpdates(%q) = %+v, %v; want no updates", upToDate, got, err)
+		}
+	}
+}
+
+func TestParseExtensionUpdates(t *testing.T) {
+	output := "Warning: some plugin notice\n" +
+		`[{"name":"akismet","version":"5.3","update_version":"5.3.2"},{"name":"woocommerce","version":"8.9.1","update_version":"9.0.0"}]`
+
+	got, err := parseExtensionUpdates(WPComponentPlugin, output)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := []WPUpdate{
+		{Type: WPComponentPlugin, Name: "akismet", Current: "5.3", New: "5.3.2"},
+		{Type: WPComponentPlugin, Name: "woocommerce", Current: "8.9.1", New: "9.0.0"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("parseExtensionUpdates() = %+v, want %+v", got, want)
+	}
+
+	if _, err := parseExtensionUpdates(WPComponentTheme, "[not json]"); err == nil {
+		t.Error("expected error for malformed JSON")
+	}
+}
+
+func TestDiffWPVersions(t *testing.T) {
+	before := WPVersions{"core:wordpress": "6.5.2", "plugin:akismet": "5.3", "plugin:hello": "1.7", "theme:twentyfour": "1.1"}
+	after := WPVersions{"core:wordpress": "6.5.3", "plugin:akismet": "5.3.2", "plugin:hello": "1.7", "theme:twentyfour": "1.2", "plugin:new": "1.0"}
+
+	got := DiffWPVersions(before, after)
+	want := []WPUpdate{
+		{Type: "core", Name: "wordpress", Current: "6.5.2", New: "6.5.3"},
+		{Type: "plugin", Name: "akismet", Current: "5.3", New: "5.3.2"},
+		{Type: "theme", Name: "twentyfour", Current: "1.1", New: "1.2"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("DiffWPVersions() = %+v, want %+v", got, want)
+	}
+}
+
+func TestSiteTemplateCommands(t *testing.T) {
+	tmpl := models.SiteTemplate{
+		Plugins: []string{"wordfence", "wp-mail-smtp"},
+		Theme:   "astra",
+		Options: map[string]string{"timezone_string": "Europe/Berlin", "blogdescription": "Ed's site"},
+	}
+	want := []string{
+		"plugin install 'wordfence' 'wp-mail-smtp' --activate",
+		"theme install 'astra' --activate",
+		`option update 'blogdescription' 'Ed'\''s site'`,
+		"option update 'timezone_string' 'Europe/Berlin'",
+	}
+	if got := SiteTemplateCommands(tmpl); !reflect.DeepEqual(got, want) {
+		t.Errorf("SiteTemplateCommands() = %q, want %q", got, want)
+	}
+
+	if got := SiteTemplateCommands(models.SiteTemplate{Theme: "astra"}); len(got) != 1 {
+		t.Errorf("SiteTemplateCommands() for a theme-only template = %q", got)
+	}
+}
@@ -0,0 +1,132 @@
+package utils
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/wordsail/cli/pkg/models"
+)
+
+// WordPressRoles lists the roles available on a default WordPress install
+var WordPressRoles = []string{"administrator", "editor", "author", "contributor", "subscriber"}
+
+// wpLoginRegex matches the characters WordPress allows in a username
+var wpLoginRegex = regexp.MustCompile(`^[a-zA-Z0-9 _.@-]+$`)
+
+// ValidateWPRole validates that a role is one of the default WordPress roles
+func ValidateWPRole(val interface{}) error {
+	role, ok := val.(string)
+	if !ok {
+		return fmt.Errorf("invalid role type")
+	}
+
+	for _, r := range WordPressRoles {
+		if role == r {
+			return nil
+		}
+	}
+	return fmt.Errorf("invalid role '%s' (must be one of: %s)", role, strings.Join(WordPressRoles, ", "))
+}
+
+// ValidateWPLogin validates a WordPress username
+func ValidateWPLogin(val interface{}) error {
+	login, ok := val.(string)
+	if !ok {
+		return fmt.Errorf("invalid username type")
+	}
+
+	if len(login) == 0 || len(login) > 60 {
+		return fmt.Errorf("username must be 1-60 characters")
+	}
+	if !wpLoginRegex.MatchString(login) {
+		return fmt.Errorf("username may only contain letters, numbers, spaces and _ . @ -")
+	}
+	return nil
+}
+
+// ParseSiteUser parses a "user:email:role" spec into a site user
+func ParseSiteUser(spec string) (models.SiteUser, error) {
+	parts := strings.Split(spec, ":")
+	if len(parts) != 3 {
+		return models.SiteUser{}, fmt.Errorf("invalid user '%s' (expected user:email:role)", spec)
+	}
+
+	user := models.SiteUser{
+		Login: strings.TrimSpace(parts[0]),
+		Email: strings.TrimSpace(parts[1]),
+		Role:  strings.ToLower(strings.TrimSpace(parts[2])),
+	}
+
+	if err := ValidateWPLogin(user.Login); err != nil {
+		return models.SiteUser{}, fmt.Errorf("invalid user '%s': %w", spec, err)
+	}
+	if err := ValidateEmail(user.Email); err != nil {
+		return models.SiteUser{}, fmt.Errorf("invalid user '%s': %w", spec, err)
+	}
+	if err := ValidateWPRole(user.Role); err != nil {
+		return models.SiteUser{}, fmt.Errorf("invalid user '%s': %w", spec, err)
+	}
+	return user, nil
+}
+
+// ParseSiteUsers parses repeated "user:email:role" specs, rejecting logins or
+// emails that collide with each other or with the admin account
+func ParseSiteUsers(specs []string, adminUser, adminEmail string) ([]models.SiteUser, error) {
+	logins := map[string]bool{strings.ToLower(adminUser): true}
+	emails := map[string]bool{strings.ToLower(adminEmail): true}
+
+	users := make([]models.SiteUser, 0, len(specs))
+	for _, spec := range specs {
+		user, err := ParseSiteUser(spec)
+		if err != nil {
+			return nil, err
+		}
+
+		login := strings.ToLower(user.Login)
+		email := strings.ToLower(user.Email)
+		if logins[login] {
+			return nil, fmt.Errorf("duplicate username '%s'", user.Login)
+		}
+		if emails[email] {
+			return nil, fmt.Errorf("duplicate email '%s'", user.Email)
+		}
+		logins[login] = true
+		emails[email] = true
+
+		users = append(users, user)
+	}
+	return users, nil
+}
+
+// wpLocaleRegex matches WordPress locale codes such as de, en_GB or de_DE_formal
+var wpLocaleRegex = regexp.MustCompile(`^[a-z]{2,3}(_[A-Z]{2})?(_[a-z]+)?$`)
+
+// ValidateWPLocale validates a WordPress locale code
+func ValidateWPLocale(val interface{}) error {
+	locale, ok := val.(string)
+	if !ok {
+		return fmt.Errorf("invalid locale type")
+	}
+
+	if !wpLocaleRegex.MatchString(locale) {
+		return fmt.Errorf("invalid locale '%s' (e.g., en_US, de_DE, fr_FR)", locale)
+	}
+	return nil
+}
+
+// wpPasswordResetArgs builds the WP-CLI arguments that set a user's password
+// without emailing the user
+func wpPasswordResetArgs(login, password string) string {
+	return fmt.Sprintf("user update %s --user_pass=%s --skip-email", ShellQuote(login), ShellQuote(password))
+}
+
+// ResetWPUserPassword sets a WordPress user's password with WP-CLI as the
+// site user
+func ResetWPUserPassword(server models.Server, site models.Site, login, password string) error {
+	if err := ValidateWPLogin(login); err != nil {
+		return err
+	}
+	_, err := RunWPCLI(server, site, wpPasswordResetArgs(login, password))
+	return err
+}
@@ -0,0 +1,98 @@
+package utils
+
+import (
+	"testing"
+
+	"github.com/wordsail/cli/pkg/models"
+)
+
+func TestParseSiteUser(t *testing.T) {
+	tests := []struct {
+		name    string
+		spec    string
+		want    models.SiteUser
+		wantErr bool
+	}{
+		{"editor", "jane:jane@example.com:editor", models.SiteUser{Login: "jane", Email: "jane@example.com", Role: "editor"}, false},
+		{"role is case-insensitive", "dev:dev@agency.io:Administrator", models.SiteUser{Login: "dev", Email: "dev@agency.io", Role: "administrator"}, false},
+		{"whitespace trimmed", " bob : bob@example.com : subscriber ", models.SiteUser{Login: "bob", Email: "bob@example.com", Role: "subscriber"}, false},
+		{"missing role", "jane:jane@example.com", models.SiteUser{}, true},
+		{"too many parts", "jane:jane@example.com:editor:extra", models.SiteUser{}, true},
+		{"empty login", ":jane@example.com:editor", models.SiteUser{}, true},
+		{"invalid login chars", "jane!:jane@example.com:editor", models.SiteUser{}, true},
+		{"invalid email", "jane:not-an-email:editor", models.SiteUser{}, true},
+		{"unknown role", "jane:jane@example.com:owner", models.SiteUser{}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseSiteUser(tt.spec)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseSiteUser(%q) error = %v, wantErr %v", tt.spec, err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("ParseSiteUser(%q) = %+v, want %+v", tt.spec, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestValidateWPRole(t *testing.T) {
+	for _, role := range WordPressRoles {
+		if err := ValidateWPRole(role); err != nil {
+			t.Errorf("ValidateWPRole(%q) unexpected error: %v", role, err)
+		}
+	}
+
+	for _, role := range []interface{}{"", "Editor", "super-admin", "shop_manager", 1} {
+		if err := ValidateWPRole(role); err == nil {
+			t.Errorf("ValidateWPRole(%v) expected error", role)
+		}
+	}
+}
+
+func TestParseSiteUsers(t *testing.T) {
+	users, err := ParseSiteUsers([]string{
+		"client:client@example.com:editor",
+		"dev:dev@agency.io:administrator",
+	}, "admin", "admin@example.com")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(users) != 2 || users[1].Login != "dev" {
+		t.Errorf("unexpected users: %+v", users)
+	}
+
+	dupes := [][]string{
+		{"Admin:other@example.com:editor"},
+		{"client:ADMIN@example.com:editor"},
+		{"a:a@example.com:editor", "a:b@example.com:author"},
+	}
+	for _, specs := range dupes {
+		if _, err := ParseSiteUsers(specs, "admin", "admin@example.com"); err == nil {
+			t.Errorf("ParseSiteUsers(%v) expected duplicate error", specs)
+		}
+	}
+}
+
+func TestValidateWPLocale(t *testing.T) {
+	for _, locale := range []string{"en_US", "de_DE", "de_DE_formal", "fi", "ast"} {
+		if err := ValidateWPLocale(locale); err != nil {
+			t.Errorf("ValidateWPLocale(%q) unexpected error: %v", locale, err)
+		}
+	}
+
+	for _, locale := range []string{"", "EN_us", "en-US", "english"} {
+		if err := ValidateWPLocale(locale); err == nil {
+			t.Errorf("ValidateWPLocale(%q) expected error", locale)
+		}
+	}
+}
+
+func TestWPPasswordResetArgs(t *testing.T) {
+	got := wpPasswordResetArgs("site admin", "p'ss$word")
+	want := `user update 'site admin' --user_pass='p'\''ss$word' --skip-email`
+	if got != want {
+		t.Errorf("wpPasswordResetArgs() = %q, want %q", got, want)
+	}
+}
@@ -8,4 +8,7 @@ type Domain struct {
 	SSLEnabled    bool       `yaml:"ssl_enabled"`
 	SSLIssuedAt   *time.Time `yaml:"ssl_issued_at,omitempty"`
 	SSLExpiresAt  *time.Time `yaml:"ssl_expires_at,omitempty"`
+
+	// RedirectTo is the domain this one 301-redirects to, if any
+	RedirectTo string `yaml:"redirect_to,omitempty"`
 }
@@ -1,12 +1,50 @@
 package models
 
-import "time"
+import (
+	"net"
+	"strconv"
+	"time"
+)
 
 // SSHConfig holds SSH connection details for a server
 type SSHConfig struct {
 	User    string `yaml:"user" validate:"required"`
 	Port    int    `yaml:"port" validate:"required,min=1,max=65535"`
-	KeyFile string `yaml:"key_file" validate:"required"`
+	KeyFile string `yaml:"key_file" validate:"required_without=UseAgent"`
+
+	// Authenticate with the keys held by ssh-agent (SSH_AUTH_SOCK)
+	UseAgent bool `yaml:"use_agent,omitempty"`
+
+	// Optional bastion the connection is tunnelled through
+	JumpHost string `yaml:"jump_host,omitempty"`
+	JumpUser string `yaml:"jump_user,omitempty"`
+	JumpPort int    `yaml:"jump_port,omitempty" validate:"omitempty,min=1,max=65535"`
+}
+
+// HasJumpHost reports whether connections are routed through a bastion
+func (c SSHConfig) HasJumpHost() bool {
+	return c.JumpHost != ""
+}
+
+// JumpLogin returns the bastion user, defaulting to the server's SSH user
+func (c SSHConfig) JumpLogin() string {
+	if c.JumpUser != "" {
+		return c.JumpUser
+	}
+	return c.User
+}
+
+// JumpPortOrDefault returns the bastion SSH port, defaulting to 22
+func (c SSHConfig) JumpPortOrDefault() int {
+	if c.JumpPort != 0 {
+		return c.JumpPort
+	}
+	return 22
+}
+
+// JumpAddress returns the bastion's host:port
+func (c SSHConfig) JumpAddress() string {
+	return net.JoinHostPort(c.JumpHost, strconv.Itoa(c.JumpPortOrDefault()))
 }
 
 // ServerCredentials holds server-specific credentials
@@ -14,14 +52,68 @@ type ServerCredentials struct {
 	MySQLWordsailbotPassword string `yaml:"mysql_wordsailbot_password,omitempty"`
 }
 
+// DatabaseEngine records which database server was installed during provisioning
+type DatabaseEngine struct {
+	Engine  string `yaml:"engine,omitempty" json:"engine,omitempty"`
+	Version string `yaml:"version,omitempty" json:"version,omitempty"`
+}
+
 // Server represents a managed server
 type Server struct {
-	Name          string             `yaml:"name" validate:"required"`
-	Hostname      string             `yaml:"hostname" validate:"required"`
-	IP            string             `yaml:"ip" validate:"required,ip"`
-	SSH           SSHConfig          `yaml:"ssh"`
-	Credentials   ServerCredentials  `yaml:"credentials,omitempty"`
-	Status        string             `yaml:"status" validate:"oneof=provisioned unprovisioned error"`
-	ProvisionedAt *time.Time         `yaml:"provisioned_at,omitempty"`
-	Sites         []Site             `yaml:"sites,omitempty"`
+	Name              string            `yaml:"name" validate:"required"`
+	Hostname          string            `yaml:"hostname" validate:"required"`
+	IP                string            `yaml:"ip,omitempty" validate:"omitempty,ip"`
+	IPv6              string            `yaml:"ipv6,omitempty" validate:"omitempty,ipv6"`
+	SSH               SSHConfig         `yaml:"ssh"`
+	Credentials       ServerCredentials `yaml:"credentials,omitempty"`
+	Database          DatabaseEngine    `yaml:"database,omitempty"`
+	Status            string            `yaml:"status" validate:"oneof=provisioned unprovisioned error"`
+	RootLoginDisabled bool              `yaml:"root_login_disabled,omitempty"`
+	ProvisionedAt     *time.Time        `yaml:"provisioned_at,omitempty"`
+	ProvisionHash     string            `yaml:"provision_hash,omitempty"`
+	ProvisionHistory  []ProvisionRun    `yaml:"provision_history,omitempty"`
+	Sites             []Site            `yaml:"sites,omitempty"`
+
+	// PythonInterpreter overrides ansible.python_interpreter for this server
+	PythonInterpreter string `yaml:"python_interpreter,omitempty"`
+}
+
+// ProvisionRun records one run of the provision playbook, newest last in
+// Server.ProvisionHistory
+type ProvisionRun struct {
+	StartedAt       time.Time `yaml:"started_at" json:"started_at"`
+	DurationSeconds int       `yaml:"duration_seconds" json:"duration_seconds"`
+	Success         bool      `yaml:"success" json:"success"`
+	Ok              int       `yaml:"ok" json:"ok"`
+	Changed         int       `yaml:"changed" json:"changed"`
+	Failed          int       `yaml:"failed" json:"failed"`
+	Error           string    `yaml:"error,omitempty" json:"error,omitempty"`
+
+	// LogPath is the file holding the playbook output of the run
+	LogPath string `yaml:"log_path,omitempty" json:"log_path,omitempty"`
+}
+
+// Address returns the address used to connect to the server: its IP, or its
+// hostname when it was registered by DNS name
+func (s Server) Address() string {
+	if s.IP != "" {
+		return s.IP
+	}
+	return s.Hostname
+}
+
+// SSHAddress returns the server's host:port for SSH, with IPv6 literals
+// in brackets
+func (s Server) SSHAddress() string {
+	return net.JoinHostPort(s.Address(), strconv.Itoa(s.SSH.Port))
+}
+
+// SetAddress records addr as both hostname and IP when it is an IP address,
+// or as the hostname alone (clearing the IP) when it is a DNS name
+func (s *Server) SetAddress(addr string) {
+	s.Hostname = addr
+	s.IP = ""
+	if net.ParseIP(addr) != nil {
+		s.IP = addr
+	}
 }
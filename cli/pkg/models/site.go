@@ -11,6 +11,14 @@ type Database struct {
 	Name string `yaml:"name" validate:"required"`
 	User string `yaml:"user" validate:"required"`
 	Host string `yaml:"host" validate:"required"`
+
+	// Adopted is set when the site was wired up to a pre-existing database
+	Adopted bool `yaml:"adopted,omitempty"`
+
+	// EncryptedPassword is set once 'site db rotate-password' has run. It is
+	// encrypted with ~/.wordsail/secret.key and never included in JSON output.
+	EncryptedPassword string     `yaml:"encrypted_password,omitempty" json:"-"`
+	PasswordRotatedAt *time.Time `yaml:"password_rotated_at,omitempty"`
 }
 
 // Metadata holds additional site information
@@ -19,33 +27,61 @@ type Metadata struct {
 	LastBackup    *time.Time `yaml:"last_backup,omitempty"`
 }
 
+// SiteUser is an additional WordPress user created alongside the admin
+type SiteUser struct {
+	Login string `yaml:"login" validate:"required"`
+	Email string `yaml:"email" validate:"required,email"`
+	Role  string `yaml:"role" validate:"required"`
+}
+
 // Site represents a WordPress site on a server
 type Site struct {
-	SiteID        string    `yaml:"site_id" validate:"required,alphanum"`
-	PrimaryDomain string    `yaml:"primary_domain" validate:"required,fqdn"`
-	CreatedAt     time.Time `yaml:"created_at"`
-	AdminUser     string    `yaml:"admin_user" validate:"required"`
-	AdminEmail    string    `yaml:"admin_email" validate:"required,email"`
-	Domains       []Domain  `yaml:"domains"`
-	Database      Database  `yaml:"database"`
-	PHPVersion    string    `yaml:"php_version"`
-	Metadata      Metadata  `yaml:"metadata"`
-	Notes         string    `yaml:"notes,omitempty"`
+	SiteID        string     `yaml:"site_id" validate:"required,alphanum"`
+	PrimaryDomain string     `yaml:"primary_domain" validate:"required,fqdn"`
+	CreatedAt     time.Time  `yaml:"created_at"`
+	AdminUser     string     `yaml:"admin_user" validate:"required_unless=NoWordPress true"`
+	AdminEmail    string     `yaml:"admin_email" validate:"required_unless=NoWordPress true,omitempty,email"`
+	Users         []SiteUser `yaml:"users,omitempty" validate:"omitempty,dive"`
+	Domains       []Domain   `yaml:"domains"`
+	Database      Database   `yaml:"database"`
+	PHPVersion    string     `yaml:"php_version"`
+	Metadata      Metadata   `yaml:"metadata"`
+	Notes         string     `yaml:"notes,omitempty"`
+
+	// MaintenanceMode is set while Nginx serves a 503 maintenance page
+	MaintenanceMode bool `yaml:"maintenance_mode,omitempty"`
+
+	// Enabled is false while the site's Nginx vhosts are disabled ('site
+	// disable'). Unset means enabled; see IsEnabled.
+	Enabled *bool `yaml:"enabled,omitempty"`
+
+	// PHPExtensions are the optional PHP extensions this site needs
+	PHPExtensions []string `yaml:"php_extensions,omitempty"`
+
+	// NoWordPress is set for bare sites (Nginx, PHP and a database only),
+	// which have no WordPress admin account
+	NoWordPress bool `yaml:"no_wordpress,omitempty"`
 }
 
 // rawSite is used for YAML unmarshalling with backwards compatibility
 type rawSite struct {
-	SiteID        string    `yaml:"site_id"`
-	SystemName    string    `yaml:"system_name"` // Legacy field for backwards compatibility
-	PrimaryDomain string    `yaml:"primary_domain"`
-	CreatedAt     time.Time `yaml:"created_at"`
-	AdminUser     string    `yaml:"admin_user"`
-	AdminEmail    string    `yaml:"admin_email"`
-	Domains       []Domain  `yaml:"domains"`
-	Database      Database  `yaml:"database"`
-	PHPVersion    string    `yaml:"php_version"`
-	Metadata      Metadata  `yaml:"metadata"`
-	Notes         string    `yaml:"notes,omitempty"`
+	SiteID        string     `yaml:"site_id"`
+	SystemName    string     `yaml:"system_name"` // Legacy field for backwards compatibility
+	PrimaryDomain string     `yaml:"primary_domain"`
+	CreatedAt     time.Time  `yaml:"created_at"`
+	AdminUser     string     `yaml:"admin_user"`
+	AdminEmail    string     `yaml:"admin_email"`
+	Users         []SiteUser `yaml:"users,omitempty"`
+	Domains       []Domain   `yaml:"domains"`
+	Database      Database   `yaml:"database"`
+	PHPVersion    string     `yaml:"php_version"`
+	Metadata      Metadata   `yaml:"metadata"`
+	Notes         string     `yaml:"notes,omitempty"`
+
+	MaintenanceMode bool     `yaml:"maintenance_mode,omitempty"`
+	Enabled         *bool    `yaml:"enabled,omitempty"`
+	PHPExtensions   []string `yaml:"php_extensions,omitempty"`
+	NoWordPress     bool     `yaml:"no_wordpress,omitempty"`
 }
 
 // UnmarshalYAML implements custom unmarshalling for backwards compatibility
@@ -67,11 +103,30 @@ func (s *Site) UnmarshalYAML(value *yaml.Node) error {
 	s.CreatedAt = raw.CreatedAt
 	s.AdminUser = raw.AdminUser
 	s.AdminEmail = raw.AdminEmail
+	s.Users = raw.Users
 	s.Domains = raw.Domains
 	s.Database = raw.Database
 	s.PHPVersion = raw.PHPVersion
 	s.Metadata = raw.Metadata
 	s.Notes = raw.Notes
+	s.MaintenanceMode = raw.MaintenanceMode
+	s.Enabled = raw.Enabled
+	s.PHPExtensions = raw.PHPExtensions
+	s.NoWordPress = raw.NoWordPress
 
 	return nil
 }
+
+// IsEnabled reports whether Nginx serves the site, which is the case unless
+// it was disabled
+func (s Site) IsEnabled() bool {
+	return s.Enabled == nil || *s.Enabled
+}
+
+// SiteTemplate is a reusable WordPress setup applied after a site is created
+type SiteTemplate struct {
+	Name    string            `yaml:"-" json:"name"`
+	Plugins []string          `yaml:"plugins,omitempty" json:"plugins,omitempty"`
+	Theme   string            `yaml:"theme,omitempty" json:"theme,omitempty"`
+	Options map[string]string `yaml:"options,omitempty" json:"options,omitempty"`
+}